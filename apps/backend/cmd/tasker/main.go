@@ -27,11 +27,18 @@ func main() {
 	}
 
 	// Initialize New Relic logger service
-	loggerService := logger.NewLoggerService(cfg.Observability)
+	loggerService, err := logger.NewLoggerService(cfg.Observability)
+	if err != nil {
+		panic("failed to initialize observability: " + err.Error())
+	}
 	defer loggerService.Shutdown()
 
 	log := logger.NewLoggerWithService(cfg.Observability, loggerService)
 
+	if err := loggerService.InitError(); err != nil {
+		log.Warn().Err(err).Msg("continuing without New Relic telemetry")
+	}
+
 	if cfg.Primary.Env != "local" {
 		if err := database.Migrate(context.Background(), &log, cfg); err != nil {
 			log.Fatal().Err(err).Msg("failed to migrate database")
@@ -71,6 +78,9 @@ func main() {
 	<-ctx.Done()
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultContextTimeout*time.Second)
 
+	// Open list snapshots hold pool connections and would stall closing the pool
+	repos.Snapshot.CloseAll()
+
 	if err = srv.Shutdown(ctx); err != nil {
 		log.Fatal().Err(err).Msg("server forced to shutdown")
 	}
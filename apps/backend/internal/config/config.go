@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
@@ -21,6 +22,12 @@ type Config struct {
 	Observability *ObservabilityConfig `koanf:"observability"`
 	AWS           AWSConfig            `koanf:"aws" validate:"required"`
 	Cron          *CronConfig          `koanf:"cron"`
+	Todo          *TodoConfig          `koanf:"todo"`
+	Notifications *NotificationsConfig `koanf:"notifications"`
+	Transcription *TranscriptionConfig `koanf:"transcription"`
+	Category      *CategoryConfig      `koanf:"category"`
+	Dashboard     *DashboardConfig     `koanf:"dashboard"`
+	Webhook       *WebhookConfig       `koanf:"webhook"`
 }
 
 type Primary struct {
@@ -33,6 +40,53 @@ type ServerConfig struct {
 	WriteTimeout       int      `koanf:"write_timeout" validate:"required"`
 	IdleTimeout        int      `koanf:"idle_timeout" validate:"required"`
 	CORSAllowedOrigins []string `koanf:"cors_allowed_origins" validate:"required"`
+	// MaxInFlight is how many API requests are served at once before further
+	// ones are refused with 503. Unset, it is InFlightPerConnection times the
+	// database pool size.
+	MaxInFlight int `koanf:"max_in_flight" validate:"omitempty,min=1"`
+	// InFlightRetryAfter is what refused requests are told to wait before retrying
+	InFlightRetryAfter time.Duration `koanf:"in_flight_retry_after" validate:"omitempty,min=1s"`
+	// IdempotencyKeyTTL is how long the response to a request sent with an
+	// Idempotency-Key is kept for replaying to retries
+	IdempotencyKeyTTL time.Duration `koanf:"idempotency_key_ttl" validate:"omitempty,min=1m"`
+}
+
+const (
+	// InFlightPerConnection leaves room for requests that are between queries
+	// or don't touch the database while still bounding the queue for a connection
+	InFlightPerConnection     = 4
+	DefaultInFlightRetryAfter = time.Second
+	DefaultIdempotencyKeyTTL  = 24 * time.Hour
+)
+
+// GetMaxInFlight returns the in-flight request limit, derived from the
+// database pool size when it isn't set
+func (c ServerConfig) GetMaxInFlight(poolSize int) int {
+	if c.MaxInFlight > 0 {
+		return c.MaxInFlight
+	}
+	return max(poolSize, 1) * InFlightPerConnection
+}
+
+// GetInFlightRetryAfter returns the Retry-After given to refused requests, falling back to the default
+func (c ServerConfig) GetInFlightRetryAfter() time.Duration {
+	if c.InFlightRetryAfter <= 0 {
+		return DefaultInFlightRetryAfter
+	}
+	return c.InFlightRetryAfter
+}
+
+// GetIdempotencyKeyTTL returns how long idempotent responses are kept, falling back to the default
+func (c ServerConfig) GetIdempotencyKeyTTL() time.Duration {
+	if c.IdempotencyKeyTTL <= 0 {
+		return DefaultIdempotencyKeyTTL
+	}
+	return c.IdempotencyKeyTTL
+}
+
+// GetRequestTimeout returns the longest a request can take to be served
+func (c ServerConfig) GetRequestTimeout() time.Duration {
+	return time.Duration(c.WriteTimeout) * time.Second
 }
 
 type DatabaseConfig struct {
@@ -46,7 +100,50 @@ type DatabaseConfig struct {
 	MaxIdleConns    int    `koanf:"max_idle_conns" validate:"required"`
 	ConnMaxLifetime int    `koanf:"conn_max_lifetime" validate:"required"`
 	ConnMaxIdleTime int    `koanf:"conn_max_idle_time" validate:"required"`
+	// StatementTimeout is how long Postgres lets a single statement run on a
+	// pool connection before cancelling it
+	StatementTimeout time.Duration `koanf:"statement_timeout"`
+	// IdleInTransactionTimeout is how long a pool connection may sit idle
+	// inside an open transaction before Postgres terminates the session
+	IdleInTransactionTimeout time.Duration `koanf:"idle_in_transaction_timeout"`
+	// TenantIsolation runs the queries of authenticated requests as TenantRole
+	// with app.user_id set to the caller, so row-level security policies apply
+	// on top of the user_id filters
+	TenantIsolation bool   `koanf:"tenant_isolation"`
+	TenantRole      string `koanf:"tenant_role"`
+}
+
+const (
+	DefaultStatementTimeout         = 30 * time.Second
+	DefaultIdleInTransactionTimeout = time.Minute
+	DefaultTenantRole               = "tasker_tenant"
+)
+
+// GetStatementTimeout returns the server-side statement timeout, falling back to the default
+func (c *DatabaseConfig) GetStatementTimeout() time.Duration {
+	if c.StatementTimeout <= 0 {
+		return DefaultStatementTimeout
+	}
+	return c.StatementTimeout
+}
+
+// GetIdleInTransactionTimeout returns the idle-in-transaction timeout, falling back to the default
+func (c *DatabaseConfig) GetIdleInTransactionTimeout() time.Duration {
+	if c.IdleInTransactionTimeout <= 0 {
+		return DefaultIdleInTransactionTimeout
+	}
+	return c.IdleInTransactionTimeout
 }
+
+// GetTenantRole returns the role tenant requests run as, falling back to the
+// role created by the migrations
+func (c *DatabaseConfig) GetTenantRole() string {
+	if c.TenantRole == "" {
+		return DefaultTenantRole
+	}
+	return c.TenantRole
+}
+
 type RedisConfig struct {
 	Address  string `koanf:"address" validate:"required"`
 	Password string `koanf:"password"`
@@ -57,7 +154,56 @@ type IntegrationConfig struct {
 }
 
 type AuthConfig struct {
-	SecretKey string `koanf:"secret_key" validate:"required"`
+	SecretKey        string        `koanf:"secret_key" validate:"required"`
+	AdminUserIDs     []string      `koanf:"admin_user_ids"`
+	ImpersonationTTL time.Duration `koanf:"impersonation_ttl"`
+	// AccountRetention is how long a deleted account's data is kept, and can
+	// be recovered, before it is purged
+	AccountRetention time.Duration `koanf:"account_retention"`
+	// AccountStatusCacheTTL is how long an instance remembers whether an
+	// account is deleted, so deleting or recovering it takes up to this long
+	// to apply to requests
+	AccountStatusCacheTTL time.Duration `koanf:"account_status_cache_ttl"`
+}
+
+const (
+	DefaultImpersonationTTL      = 15 * time.Minute
+	DefaultAccountRetention      = 30 * 24 * time.Hour
+	DefaultAccountStatusCacheTTL = 30 * time.Second
+)
+
+// GetImpersonationTTL returns the lifetime of impersonation tokens, falling back to the default
+func (c *AuthConfig) GetImpersonationTTL() time.Duration {
+	if c.ImpersonationTTL <= 0 {
+		return DefaultImpersonationTTL
+	}
+	return c.ImpersonationTTL
+}
+
+// GetAccountRetention returns how long deleted accounts are kept, falling back to the default
+func (c *AuthConfig) GetAccountRetention() time.Duration {
+	if c.AccountRetention <= 0 {
+		return DefaultAccountRetention
+	}
+	return c.AccountRetention
+}
+
+// GetAccountStatusCacheTTL returns how long account deletion checks are cached, falling back to the default
+func (c *AuthConfig) GetAccountStatusCacheTTL() time.Duration {
+	if c.AccountStatusCacheTTL <= 0 {
+		return DefaultAccountStatusCacheTTL
+	}
+	return c.AccountStatusCacheTTL
+}
+
+// IsAdmin reports whether the given user id is configured as an admin
+func (c *AuthConfig) IsAdmin(userID string) bool {
+	for _, id := range c.AdminUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
 }
 
 type AWSConfig struct {
@@ -73,6 +219,15 @@ type CronConfig struct {
 	BatchSize                   int `koanf:"batch_size"`
 	ReminderHours               int `koanf:"reminder_hours"`
 	MaxTodosPerUserNotification int `koanf:"max_todos_per_user_notification"`
+	// AutoActivateEnabled turns on promoting draft todos to active as their
+	// due date approaches
+	AutoActivateEnabled bool `koanf:"auto_activate_enabled"`
+	// AutoActivateLeadHours is how far ahead of its due date a draft is promoted
+	AutoActivateLeadHours int `koanf:"auto_activate_lead_hours" validate:"omitempty,min=1"`
+	// ReminderWindow is how often the reminder jobs are scheduled. A reminder
+	// is enqueued at most once per window, so a run that is restarted partway
+	// through doesn't repeat the reminders it already dispatched.
+	ReminderWindow time.Duration `koanf:"reminder_window"`
 }
 
 func DefaultCronConfig() *CronConfig {
@@ -81,9 +236,376 @@ func DefaultCronConfig() *CronConfig {
 		BatchSize:                   100,
 		ReminderHours:               24,
 		MaxTodosPerUserNotification: 10,
+		AutoActivateLeadHours:       24,
+		ReminderWindow:              time.Hour,
 	}
 }
 
+// GetReminderWindow returns the reminder jobs' schedule window, falling back to the default
+func (c *CronConfig) GetReminderWindow() time.Duration {
+	if c == nil || c.ReminderWindow <= 0 {
+		return DefaultCronConfig().ReminderWindow
+	}
+	return c.ReminderWindow
+}
+
+// GetAutoActivateLeadHours returns the auto-activate lead time, falling back to the default
+func (c *CronConfig) GetAutoActivateLeadHours() int {
+	if c == nil || c.AutoActivateLeadHours <= 0 {
+		return DefaultCronConfig().AutoActivateLeadHours
+	}
+	return c.AutoActivateLeadHours
+}
+
+// NotificationsConfig controls how queued notification emails are sent
+type NotificationsConfig struct {
+	// SendRatePerMinute caps how many emails are sent per minute
+	SendRatePerMinute int `koanf:"send_rate_per_minute" validate:"omitempty,min=1"`
+	// BatchSize is the most emails sent in a single API call
+	BatchSize int `koanf:"batch_size" validate:"omitempty,min=1,max=100"`
+	// FlushInterval is how long a partial batch waits for more emails
+	FlushInterval time.Duration `koanf:"flush_interval"`
+	// DedupeWindow is how long the same notification is suppressed after it is sent
+	DedupeWindow time.Duration `koanf:"dedupe_window"`
+}
+
+func DefaultNotificationsConfig() *NotificationsConfig {
+	return &NotificationsConfig{
+		SendRatePerMinute: 120,
+		BatchSize:         50,
+		FlushInterval:     2 * time.Second,
+		DedupeWindow:      12 * time.Hour,
+	}
+}
+
+// TranscriptionConfig controls background transcription of audio attachments
+type TranscriptionConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Provider names the transcription backend. Only the mock provider, which
+	// returns a placeholder transcript, is built in.
+	Provider string `koanf:"provider" validate:"omitempty,oneof=mock"`
+}
+
+func DefaultTranscriptionConfig() *TranscriptionConfig {
+	return &TranscriptionConfig{
+		Provider: "mock",
+	}
+}
+
+// IsEnabled reports whether audio attachments should be transcribed
+func (c *TranscriptionConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// CategoryConfig controls checks on category writes
+type CategoryConfig struct {
+	// ContrastCheckEnabled warns when a category color is hard to see against
+	// ContrastBackground
+	ContrastCheckEnabled bool `koanf:"contrast_check_enabled"`
+	// ContrastBackground is the UI background category colors are shown on
+	ContrastBackground string `koanf:"contrast_background" validate:"omitempty,hexcolor"`
+	// MinContrastRatio is the lowest acceptable WCAG contrast ratio (1-21)
+	MinContrastRatio float64 `koanf:"min_contrast_ratio" validate:"omitempty,min=1,max=21"`
+}
+
+const (
+	DefaultContrastBackground = "#ffffff"
+	DefaultMinContrastRatio   = 3.0
+)
+
+func DefaultCategoryConfig() *CategoryConfig {
+	return &CategoryConfig{
+		ContrastBackground: DefaultContrastBackground,
+		MinContrastRatio:   DefaultMinContrastRatio,
+	}
+}
+
+// IsContrastCheckEnabled reports whether category colors are checked for contrast
+func (c *CategoryConfig) IsContrastCheckEnabled() bool {
+	return c != nil && c.ContrastCheckEnabled
+}
+
+// GetContrastBackground returns the background colors are checked against, falling back to the default
+func (c *CategoryConfig) GetContrastBackground() string {
+	if c == nil || c.ContrastBackground == "" {
+		return DefaultContrastBackground
+	}
+	return c.ContrastBackground
+}
+
+// GetMinContrastRatio returns the minimum contrast ratio, falling back to the default
+func (c *CategoryConfig) GetMinContrastRatio() float64 {
+	if c == nil || c.MinContrastRatio <= 0 {
+		return DefaultMinContrastRatio
+	}
+	return c.MinContrastRatio
+}
+
+// DashboardConfig controls how long the dashboard waits for each of its
+// sections
+type DashboardConfig struct {
+	// SectionTimeout is how long a section may take before the dashboard is
+	// returned without it
+	SectionTimeout time.Duration `koanf:"section_timeout"`
+	// SectionTimeouts overrides SectionTimeout for the sections it names
+	SectionTimeouts map[string]time.Duration `koanf:"section_timeouts"`
+}
+
+const DefaultDashboardSectionTimeout = 2 * time.Second
+
+func DefaultDashboardConfig() *DashboardConfig {
+	return &DashboardConfig{
+		SectionTimeout: DefaultDashboardSectionTimeout,
+	}
+}
+
+// GetSectionTimeout returns how long section may take, falling back to
+// SectionTimeout and then the default
+func (c *DashboardConfig) GetSectionTimeout(section string) time.Duration {
+	if c == nil {
+		return DefaultDashboardSectionTimeout
+	}
+	if timeout, ok := c.SectionTimeouts[section]; ok && timeout > 0 {
+		return timeout
+	}
+	if c.SectionTimeout <= 0 {
+		return DefaultDashboardSectionTimeout
+	}
+	return c.SectionTimeout
+}
+
+// WebhookConfig controls how webhook deliveries are sent
+type WebhookConfig struct {
+	// Timeout is how long an endpoint has to respond to a delivery
+	Timeout time.Duration `koanf:"timeout" validate:"omitempty,min=1s,max=1m"`
+	// MaxAttempts is how many times a delivery is tried before it's marked
+	// failed. Retries back off exponentially.
+	MaxAttempts int `koanf:"max_attempts" validate:"omitempty,min=1,max=20"`
+	// AllowPrivateNetworks lets webhooks point at loopback and private
+	// addresses, for local development only
+	AllowPrivateNetworks bool `koanf:"allow_private_networks"`
+}
+
+const (
+	DefaultWebhookTimeout     = 10 * time.Second
+	DefaultWebhookMaxAttempts = 6
+)
+
+func DefaultWebhookConfig() *WebhookConfig {
+	return &WebhookConfig{
+		Timeout:     DefaultWebhookTimeout,
+		MaxAttempts: DefaultWebhookMaxAttempts,
+	}
+}
+
+// GetTimeout returns the delivery timeout, falling back to the default
+func (c *WebhookConfig) GetTimeout() time.Duration {
+	if c == nil || c.Timeout <= 0 {
+		return DefaultWebhookTimeout
+	}
+	return c.Timeout
+}
+
+// GetMaxAttempts returns how many times a delivery is tried, falling back to the default
+func (c *WebhookConfig) GetMaxAttempts() int {
+	if c == nil || c.MaxAttempts <= 0 {
+		return DefaultWebhookMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// IsPrivateNetworkAllowed reports whether webhooks may target internal addresses
+func (c *WebhookConfig) IsPrivateNetworkAllowed() bool {
+	return c != nil && c.AllowPrivateNetworks
+}
+
+type TodoConfig struct {
+	// DuplicateTitleThreshold is the pg_trgm similarity (0-1) at which a title
+	// in the same category is reported as a likely duplicate
+	DuplicateTitleThreshold float64 `koanf:"duplicate_title_threshold" validate:"omitempty,gt=0,lte=1"`
+	// InboxEnabled files todos created without a category into the user's
+	// Inbox category. Defaults to on.
+	InboxEnabled *bool `koanf:"inbox_enabled"`
+	// InlineChildrenLimit caps how many subtasks are inlined when fetching a
+	// single todo; the rest are paged through the children endpoint
+	InlineChildrenLimit int `koanf:"inline_children_limit" validate:"omitempty,min=1"`
+	// StatusTransitions overrides which statuses a todo may move to, keyed by
+	// the status it moves from. Statuses left out keep the default rules.
+	StatusTransitions map[string][]string `koanf:"status_transitions" validate:"omitempty,dive,keys,oneof=draft active completed archived,endkeys,dive,oneof=draft active completed archived"`
+	// CycleTimeOutlierCap is the longest a todo may have taken to complete and
+	// still count toward cycle-time analytics when outliers are excluded
+	CycleTimeOutlierCap time.Duration `koanf:"cycle_time_outlier_cap" validate:"omitempty,min=1h"`
+	// MaxConcurrentUploads is how many attachment uploads a user may have in
+	// flight at once; further uploads are refused until one finishes
+	MaxConcurrentUploads int `koanf:"max_concurrent_uploads" validate:"omitempty,min=1"`
+	// SnapshotTTL is how long a listing snapshot stays open. Each open snapshot
+	// holds a database connection, so keep it short.
+	SnapshotTTL time.Duration `koanf:"snapshot_ttl" validate:"omitempty,min=10s,max=1h"`
+	// MaxOpenSnapshots is how many listing snapshots one server instance
+	// keeps open at once; further ones are refused with 503. Unset, it is the
+	// database pool size divided by SnapshotPoolShare.
+	MaxOpenSnapshots int `koanf:"max_open_snapshots" validate:"omitempty,min=1"`
+	// StrictListHydration fails a todo listing when any row's subtasks,
+	// comments or attachments can't be decoded. By default such rows are
+	// skipped and counted instead.
+	StrictListHydration bool `koanf:"strict_list_hydration"`
+	// BulkBatchSize is how many todos a bulk operation changes per
+	// transaction, so a long ID list doesn't hold locks on all of them at once
+	BulkBatchSize int `koanf:"bulk_batch_size" validate:"omitempty,min=1"`
+	// BulkMaxIDs is the most todos one bulk request may list
+	BulkMaxIDs int `koanf:"bulk_max_ids" validate:"omitempty,min=1"`
+	// MaxChildren is the most direct subtasks a todo may have
+	MaxChildren int `koanf:"max_children" validate:"omitempty,min=1"`
+	// TrashRetention is how long deleted todos stay in the trash before
+	// they are purged
+	TrashRetention time.Duration `koanf:"trash_retention"`
+	// UndoWindow is how long the undo token handed out with an edit or delete
+	// stays valid
+	UndoWindow time.Duration `koanf:"undo_window" validate:"omitempty,min=5s,max=1h"`
+}
+
+const (
+	DefaultDuplicateTitleThreshold = 0.6
+	DefaultInlineChildrenLimit     = 20
+	DefaultCycleTimeOutlierCap     = 90 * 24 * time.Hour
+	DefaultMaxConcurrentUploads    = 3
+	DefaultSnapshotTTL             = 5 * time.Minute
+	DefaultBulkBatchSize           = 100
+	DefaultBulkMaxIDs              = 1000
+	DefaultMaxChildren             = 100
+	DefaultTrashRetention          = 30 * 24 * time.Hour
+	DefaultUndoWindow              = 30 * time.Second
+)
+
+// SnapshotPoolShare keeps listing snapshots, which each hold a connection for
+// their whole TTL, to a small part of the database pool
+const SnapshotPoolShare = 10
+
+func DefaultTodoConfig() *TodoConfig {
+	return &TodoConfig{
+		DuplicateTitleThreshold: DefaultDuplicateTitleThreshold,
+		InlineChildrenLimit:     DefaultInlineChildrenLimit,
+		CycleTimeOutlierCap:     DefaultCycleTimeOutlierCap,
+		MaxConcurrentUploads:    DefaultMaxConcurrentUploads,
+		SnapshotTTL:             DefaultSnapshotTTL,
+		BulkBatchSize:           DefaultBulkBatchSize,
+		BulkMaxIDs:              DefaultBulkMaxIDs,
+		MaxChildren:             DefaultMaxChildren,
+		TrashRetention:          DefaultTrashRetention,
+		UndoWindow:              DefaultUndoWindow,
+	}
+}
+
+// GetDuplicateTitleThreshold returns the configured similarity threshold, falling back to the default
+func (c *TodoConfig) GetDuplicateTitleThreshold() float64 {
+	if c == nil || c.DuplicateTitleThreshold <= 0 {
+		return DefaultDuplicateTitleThreshold
+	}
+	return c.DuplicateTitleThreshold
+}
+
+// GetInlineChildrenLimit returns how many subtasks a fetched todo inlines, falling back to the default
+func (c *TodoConfig) GetInlineChildrenLimit() int {
+	if c == nil || c.InlineChildrenLimit <= 0 {
+		return DefaultInlineChildrenLimit
+	}
+	return c.InlineChildrenLimit
+}
+
+// GetCycleTimeOutlierCap returns the cycle-time outlier cap, falling back to the default
+func (c *TodoConfig) GetCycleTimeOutlierCap() time.Duration {
+	if c == nil || c.CycleTimeOutlierCap <= 0 {
+		return DefaultCycleTimeOutlierCap
+	}
+	return c.CycleTimeOutlierCap
+}
+
+// GetMaxConcurrentUploads returns the per-user in-flight upload limit, falling back to the default
+func (c *TodoConfig) GetMaxConcurrentUploads() int {
+	if c == nil || c.MaxConcurrentUploads <= 0 {
+		return DefaultMaxConcurrentUploads
+	}
+	return c.MaxConcurrentUploads
+}
+
+// GetSnapshotTTL returns how long listing snapshots stay open, falling back to the default
+func (c *TodoConfig) GetSnapshotTTL() time.Duration {
+	if c == nil || c.SnapshotTTL <= 0 {
+		return DefaultSnapshotTTL
+	}
+	return c.SnapshotTTL
+}
+
+// GetMaxOpenSnapshots returns how many listing snapshots may be open at
+// once, derived from the database pool size when it isn't set
+func (c *TodoConfig) GetMaxOpenSnapshots(poolSize int) int {
+	if c != nil && c.MaxOpenSnapshots > 0 {
+		return c.MaxOpenSnapshots
+	}
+	return max(poolSize/SnapshotPoolShare, 1)
+}
+
+// GetBulkBatchSize returns how many todos a bulk operation changes per transaction, falling back to the default
+func (c *TodoConfig) GetBulkBatchSize() int {
+	if c == nil || c.BulkBatchSize <= 0 {
+		return DefaultBulkBatchSize
+	}
+	return c.BulkBatchSize
+}
+
+// GetBulkMaxIDs returns the most todos one bulk request may list, falling back to the default
+func (c *TodoConfig) GetBulkMaxIDs() int {
+	if c == nil || c.BulkMaxIDs <= 0 {
+		return DefaultBulkMaxIDs
+	}
+	return c.BulkMaxIDs
+}
+
+// GetMaxChildren returns the most direct subtasks a todo may have, falling back to the default
+func (c *TodoConfig) GetMaxChildren() int {
+	if c == nil || c.MaxChildren <= 0 {
+		return DefaultMaxChildren
+	}
+	return c.MaxChildren
+}
+
+// GetTrashRetention returns how long deleted todos are kept, falling back to the default
+func (c *TodoConfig) GetTrashRetention() time.Duration {
+	if c == nil || c.TrashRetention <= 0 {
+		return DefaultTrashRetention
+	}
+	return c.TrashRetention
+}
+
+// GetUndoWindow returns how long an undo token stays valid, falling back to the default
+func (c *TodoConfig) GetUndoWindow() time.Duration {
+	if c == nil || c.UndoWindow <= 0 {
+		return DefaultUndoWindow
+	}
+	return c.UndoWindow
+}
+
+// IsStrictListHydration reports whether one unreadable row fails the whole listing
+func (c *TodoConfig) IsStrictListHydration() bool {
+	return c != nil && c.StrictListHydration
+}
+
+// GetStatusTransitions returns the configured status transition overrides, if any
+func (c *TodoConfig) GetStatusTransitions() map[string][]string {
+	if c == nil {
+		return nil
+	}
+	return c.StatusTransitions
+}
+
+// IsInboxEnabled reports whether uncategorized todos go to the Inbox, defaulting to true
+func (c *TodoConfig) IsInboxEnabled() bool {
+	if c == nil || c.InboxEnabled == nil {
+		return true
+	}
+	return *c.InboxEnabled
+}
+
 func parseMapString(value string) (map[string]string, bool) {
 	if !strings.HasPrefix(value, "map[") || !strings.HasSuffix(value, "]") {
 		return nil, false
@@ -219,5 +741,29 @@ func LoadConfig() (*Config, error) {
 		mainConfig.Cron = DefaultCronConfig()
 	}
 
+	if mainConfig.Todo == nil {
+		mainConfig.Todo = DefaultTodoConfig()
+	}
+
+	if mainConfig.Notifications == nil {
+		mainConfig.Notifications = DefaultNotificationsConfig()
+	}
+
+	if mainConfig.Transcription == nil {
+		mainConfig.Transcription = DefaultTranscriptionConfig()
+	}
+
+	if mainConfig.Category == nil {
+		mainConfig.Category = DefaultCategoryConfig()
+	}
+
+	if mainConfig.Dashboard == nil {
+		mainConfig.Dashboard = DefaultDashboardConfig()
+	}
+
+	if mainConfig.Webhook == nil {
+		mainConfig.Webhook = DefaultWebhookConfig()
+	}
+
 	return mainConfig, nil
 }
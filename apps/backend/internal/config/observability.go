@@ -20,10 +20,13 @@ type LoggingConfig struct {
 }
 
 type NewRelicConfig struct {
-	LicenseKey                string `koanf:"license_key" validate:"required"`
+	LicenseKey                string `koanf:"license_key"`
 	AppLogForwardingEnabled   bool   `koanf:"app_log_forwarding_enabled"`
 	DistributedTracingEnabled bool   `koanf:"distributed_tracing_enabled"`
 	DebugLogging              bool   `koanf:"debug_logging"`
+	// Required fails startup when the agent can't be initialized or reach the
+	// collector, instead of continuing without telemetry
+	Required bool `koanf:"required"`
 }
 
 type HealthChecksConfig struct {
@@ -70,6 +73,10 @@ func (c *ObservabilityConfig) Validate() error {
 		return fmt.Errorf("invalid logging level: %s (must be one of: debug, info, warn, error)", c.Logging.Level)
 	}
 
+	if c.NewRelic.Required && c.NewRelic.LicenseKey == "" {
+		return fmt.Errorf("new_relic license_key is required when new_relic required is set")
+	}
+
 	// Validate slow query threshold
 	if c.Logging.SlowQueryThreshold < 0 {
 		return fmt.Errorf("logging slow_query_threshold must be non-negative")
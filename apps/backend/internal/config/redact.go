@@ -0,0 +1,32 @@
+package config
+
+// RedactedValue replaces secrets in a redacted config
+const RedactedValue = "***"
+
+// Redacted returns a copy of the config that is safe to show operators, with
+// every credential replaced by RedactedValue. Secrets that aren't set stay
+// empty, so a missing one is still visible.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.Auth.SecretKey = redact(c.Auth.SecretKey)
+	redacted.Redis.Password = redact(c.Redis.Password)
+	redacted.Integration.ResendAPIKey = redact(c.Integration.ResendAPIKey)
+	redacted.AWS.SecretAccessKey = redact(c.AWS.SecretAccessKey)
+
+	if c.Observability != nil {
+		observability := *c.Observability
+		observability.NewRelic.LicenseKey = redact(c.Observability.NewRelic.LicenseKey)
+		redacted.Observability = &observability
+	}
+
+	return &redacted
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
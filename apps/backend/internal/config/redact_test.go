@@ -0,0 +1,67 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Redacted(t *testing.T) {
+	secrets := []string{"db-password", "auth-secret", "redis-password", "resend-key", "aws-secret", "nr-license"}
+
+	cfg := &config.Config{
+		Primary:     config.Primary{Env: "production"},
+		Database:    config.DatabaseConfig{Host: "db.internal", Password: "db-password"},
+		Auth:        config.AuthConfig{SecretKey: "auth-secret"},
+		Redis:       config.RedisConfig{Address: "redis:6379", Password: "redis-password"},
+		Integration: config.IntegrationConfig{ResendAPIKey: "resend-key"},
+		AWS: config.AWSConfig{
+			Region:          "eu-west-1",
+			AccessKeyID:     "AKIA123",
+			SecretAccessKey: "aws-secret",
+			UploadBucket:    "tasker-uploads",
+		},
+		Observability: &config.ObservabilityConfig{
+			NewRelic: config.NewRelicConfig{LicenseKey: "nr-license"},
+		},
+	}
+
+	redacted := cfg.Redacted()
+
+	t.Run("every secret is replaced", func(t *testing.T) {
+		assert.Equal(t, config.RedactedValue, redacted.Database.Password)
+		assert.Equal(t, config.RedactedValue, redacted.Auth.SecretKey)
+		assert.Equal(t, config.RedactedValue, redacted.Redis.Password)
+		assert.Equal(t, config.RedactedValue, redacted.Integration.ResendAPIKey)
+		assert.Equal(t, config.RedactedValue, redacted.AWS.SecretAccessKey)
+		assert.Equal(t, config.RedactedValue, redacted.Observability.NewRelic.LicenseKey)
+
+		body, err := json.Marshal(redacted)
+		require.NoError(t, err)
+		for _, secret := range secrets {
+			assert.NotContains(t, string(body), secret)
+		}
+	})
+
+	t.Run("everything else is kept", func(t *testing.T) {
+		assert.Equal(t, "production", redacted.Primary.Env)
+		assert.Equal(t, "db.internal", redacted.Database.Host)
+		assert.Equal(t, "redis:6379", redacted.Redis.Address)
+		assert.Equal(t, "tasker-uploads", redacted.AWS.UploadBucket)
+		assert.Equal(t, "AKIA123", redacted.AWS.AccessKeyID)
+	})
+
+	t.Run("the loaded config is untouched", func(t *testing.T) {
+		assert.Equal(t, "db-password", cfg.Database.Password)
+		assert.Equal(t, "nr-license", cfg.Observability.NewRelic.LicenseKey)
+	})
+
+	t.Run("unset secrets stay empty", func(t *testing.T) {
+		empty := (&config.Config{}).Redacted()
+		assert.Empty(t, empty.Database.Password)
+		assert.Nil(t, empty.Observability)
+	})
+}
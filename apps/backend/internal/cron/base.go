@@ -27,9 +27,16 @@ func NewJobContext() (*JobContext, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	loggerService := logger.NewLoggerService(cfg.Observability)
+	loggerService, err := logger.NewLoggerService(cfg.Observability)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize observability: %w", err)
+	}
 	loggerInstance := logger.NewLoggerWithService(cfg.Observability, loggerService)
 
+	if err := loggerService.InitError(); err != nil {
+		loggerInstance.Warn().Err(err).Msg("continuing without New Relic telemetry")
+	}
+
 	db, err := database.New(cfg, &loggerInstance, loggerService)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
@@ -75,9 +82,7 @@ func (c *JobContext) Close() {
 	if c.JobClient != nil {
 		c.JobClient.Close()
 	}
-	if c.LoggerService != nil {
-		c.LoggerService.Shutdown()
-	}
+	c.LoggerService.Shutdown()
 }
 
 func initJobClient(cfg *config.Config) (*asynq.Client, error) {
@@ -2,11 +2,18 @@ package cron
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
 	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/preference"
 	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/model/webhook"
 )
 
 type DueDateRemindersJob struct{}
@@ -20,64 +27,25 @@ func (j *DueDateRemindersJob) Description() string {
 }
 
 func (j *DueDateRemindersJob) Run(ctx context.Context, jobCtx *JobContext) error {
-	todos, err := jobCtx.Repositories.Todo.GetTodosDueInHours(
-		ctx,
-		jobCtx.Config.Cron.ReminderHours,
-		jobCtx.Config.Cron.BatchSize,
-	)
+	notifications, total, err := collectNotifications(ctx, jobCtx, func(limit, offset int) ([]todo.Todo, error) {
+		return jobCtx.Repositories.Todo.GetTodosDueInHours(ctx, jobCtx.Config.Cron.ReminderHours, limit, offset)
+	})
 	if err != nil {
 		return err
 	}
 
 	jobCtx.Server.Logger.Info().
-		Int("todo_count", len(todos)).
+		Int("todo_count", total).
+		Int("user_count", len(notifications)).
 		Int("hours", jobCtx.Config.Cron.ReminderHours).
 		Msg("Found todos due soon")
 
-	userTodos := make(map[string][]string)
-	enqueuedCount := 0
-
-	for _, todo := range todos {
-		if len(userTodos[todo.UserID]) < jobCtx.Config.Cron.MaxTodosPerUserNotification {
-			userTodos[todo.UserID] = append(userTodos[todo.UserID], todo.Title)
-		}
-
-		reminderTask := &job.ReminderEmailTask{
-			UserID:    todo.UserID,
-			TodoID:    todo.ID,
-			TodoTitle: todo.Title,
-			DueDate:   *todo.DueDate,
-			TaskType:  "due_date_reminder",
-		}
-
-		err := job.EnqueueReminderEmail(jobCtx.JobClient, reminderTask)
-		if err != nil {
-			jobCtx.Server.Logger.Error().
-				Err(err).
-				Str("todo_id", todo.ID.String()).
-				Str("user_id", todo.UserID).
-				Msg("Failed to enqueue reminder email")
-			continue
-		}
-
-		enqueuedCount++
-		jobCtx.Server.Logger.Info().
-			Str("todo_id", todo.ID.String()).
-			Str("todo_title", todo.Title).
-			Str("user_id", todo.UserID).
-			Msg("Enqueued reminder for todo")
-	}
+	enqueuedCount := enqueueNotifications(ctx, jobCtx, notifications, job.ReminderTypeDueDate)
 
 	jobCtx.Server.Logger.Info().
 		Int("enqueued_count", enqueuedCount).
-		Int("total_todos", len(todos)).
+		Int("total_users", len(notifications)).
 		Msg("Due date reminder emails enqueued")
-	for userID, titles := range userTodos {
-		jobCtx.Server.Logger.Info().
-			Str("user_id", userID).
-			Int("reminder_count", len(titles)).
-			Msg("User reminders enqueued")
-	}
 
 	return nil
 }
@@ -95,61 +63,117 @@ func (j *OverdueNotificationsJob) Description() string {
 }
 
 func (j *OverdueNotificationsJob) Run(ctx context.Context, jobCtx *JobContext) error {
-	todos, err := jobCtx.Repositories.Todo.GetOverdueTodos(ctx, jobCtx.Config.Cron.BatchSize)
+	notifications, total, err := collectNotifications(ctx, jobCtx, func(limit, offset int) ([]todo.Todo, error) {
+		return jobCtx.Repositories.Todo.GetOverdueTodos(ctx, limit, offset)
+	})
 	if err != nil {
 		return err
 	}
 
 	jobCtx.Server.Logger.Info().
-		Int("todo_count", len(todos)).
+		Int("todo_count", total).
+		Int("user_count", len(notifications)).
 		Msg("Found overdue todos")
 
-	userTodos := make(map[string][]string)
-	enqueuedCount := 0
+	enqueuedCount := enqueueNotifications(ctx, jobCtx, notifications, job.ReminderTypeOverdue)
+
+	jobCtx.Server.Logger.Info().
+		Int("enqueued_count", enqueuedCount).
+		Int("total_users", len(notifications)).
+		Msg("Overdue notifications enqueued")
 
-	for _, todo := range todos {
-		if len(userTodos[todo.UserID]) < jobCtx.Config.Cron.MaxTodosPerUserNotification {
-			userTodos[todo.UserID] = append(userTodos[todo.UserID], todo.Title)
+	return nil
+}
+
+// collectNotifications pages through every matching todo in BatchSize chunks
+// and builds the capped per-user notifications from the full result set.
+func collectNotifications(ctx context.Context, jobCtx *JobContext,
+	fetch func(limit, offset int) ([]todo.Todo, error),
+) ([]job.UserNotification, int, error) {
+	batchSize := jobCtx.Config.Cron.BatchSize
+	builder := job.NewNotificationBuilder(jobCtx.Config.Cron.MaxTodosPerUserNotification, time.Now(),
+		func(userID string) *preference.Preferences {
+			prefs, err := jobCtx.Repositories.Preference.GetPreferences(ctx, userID)
+			if err != nil {
+				jobCtx.Server.Logger.Warn().
+					Err(err).
+					Str("user_id", userID).
+					Msg("Failed to fetch preferences, ordering reminders in UTC")
+				return nil
+			}
+			return prefs
+		})
+
+	total := 0
+	for offset := 0; batchSize > 0; offset += batchSize {
+		todos, err := fetch(batchSize, offset)
+		if err != nil {
+			return nil, 0, err
 		}
 
-		overdueTask := &job.ReminderEmailTask{
-			UserID:    todo.UserID,
-			TodoID:    todo.ID,
-			TodoTitle: todo.Title,
-			DueDate:   *todo.DueDate,
-			TaskType:  "overdue_notification",
+		builder.Add(todos...)
+		total += len(todos)
+
+		if len(todos) < batchSize {
+			break
 		}
+	}
+
+	return builder.Build(), total, nil
+}
+
+// enqueueNotifications sends one email task per user for their most urgent
+// todo, listing the rest of the selection and the count that was cut off.
+// Reminders already dispatched in the current window, by an earlier attempt
+// at this run, are skipped.
+func enqueueNotifications(ctx context.Context, jobCtx *JobContext, notifications []job.UserNotification,
+	taskType string,
+) int {
+	dispatcher := job.NewReminderDispatcher(job.NewRedisDeduper(jobCtx.Server.Redis),
+		jobCtx.Config.Cron.GetReminderWindow(),
+		func(task *job.ReminderEmailTask) error {
+			return job.EnqueueReminderEmail(jobCtx.JobClient, task)
+		},
+	)
+	now := time.Now()
 
-		err := job.EnqueueReminderEmail(jobCtx.JobClient, overdueTask)
+	enqueuedCount := 0
+	for _, notification := range notifications {
+		task := job.NewReminderEmailTask(notification, taskType)
+		if task == nil {
+			continue
+		}
+
+		dispatched, err := dispatcher.Dispatch(ctx, task, now)
 		if err != nil {
 			jobCtx.Server.Logger.Error().
 				Err(err).
-				Str("todo_id", todo.ID.String()).
-				Str("user_id", todo.UserID).
-				Msg("Failed to enqueue overdue notification")
+				Str("todo_id", task.TodoID.String()).
+				Str("user_id", notification.UserID).
+				Str("task_type", taskType).
+				Msg("Failed to enqueue reminder email")
 			continue
 		}
 
-		enqueuedCount++
-		jobCtx.Server.Logger.Info().
-			Str("todo_id", todo.ID.String()).
-			Str("todo_title", todo.Title).
-			Str("user_id", todo.UserID).
-			Msg("Enqueued overdue notification")
-	}
+		if !dispatched {
+			jobCtx.Server.Logger.Info().
+				Str("todo_id", task.TodoID.String()).
+				Str("user_id", notification.UserID).
+				Str("task_type", taskType).
+				Msg("Reminder already dispatched in this window")
+			continue
+		}
 
-	jobCtx.Server.Logger.Info().
-		Int("enqueued_count", enqueuedCount).
-		Int("total_todos", len(todos)).
-		Msg("Overdue notifications enqueued")
-	for userID, titles := range userTodos {
+		enqueuedCount++
 		jobCtx.Server.Logger.Info().
-			Str("user_id", userID).
-			Int("overdue_count", len(titles)).
-			Msg("User overdue todos enqueued")
+			Str("user_id", notification.UserID).
+			Str("task_type", taskType).
+			Int("todo_count", len(notification.Todos)).
+			Int("more_count", notification.MoreCount).
+			Msg("Enqueued reminder for user")
 	}
 
-	return nil
+	return enqueuedCount
 }
 
 // ------------
@@ -197,16 +221,7 @@ func (j *WeeklyReportsJob) Run(ctx context.Context, jobCtx *JobContext) error {
 			overdueTodos = []todo.PopulatedTodo{}
 		}
 
-		weeklyReportTask := &job.WeeklyReportEmailTask{
-			UserID:         userStats.UserID,
-			WeekStart:      weekAgo,
-			WeekEnd:        now,
-			CompletedCount: userStats.CompletedCount,
-			ActiveCount:    userStats.ActiveCount,
-			OverdueCount:   userStats.OverdueCount,
-			CompletedTodos: completedTodos,
-			OverdueTodos:   overdueTodos,
-		}
+		weeklyReportTask := job.NewWeeklyReportEmailTask(&userStats, weekAgo, now, completedTodos, overdueTodos)
 
 		err = job.EnqueueWeeklyReportEmail(jobCtx.JobClient, weeklyReportTask)
 		if err != nil {
@@ -275,11 +290,15 @@ func (j *AutoArchiveJob) Run(ctx context.Context, jobCtx *JobContext) error {
 		userTodos[todo.UserID]++
 	}
 
-	err = jobCtx.Repositories.Todo.ArchiveTodos(ctx, todoIDs)
+	archived, err := jobCtx.Repositories.Todo.ArchiveTodos(ctx, todoIDs)
 	if err != nil {
 		return err
 	}
 
+	for _, change := range archived {
+		recordSystemChange(ctx, jobCtx, change)
+	}
+
 	jobCtx.Server.Logger.Info().
 		Int("archived_count", len(todoIDs)).
 		Msg("Successfully archived todos")
@@ -293,3 +312,344 @@ func (j *AutoArchiveJob) Run(ctx context.Context, jobCtx *JobContext) error {
 
 	return nil
 }
+
+// --------
+
+type AutoActivateJob struct{}
+
+func (j *AutoActivateJob) Name() string {
+	return "auto-activate"
+}
+
+func (j *AutoActivateJob) Description() string {
+	return "Promote draft todos to active as their due date approaches"
+}
+
+func (j *AutoActivateJob) Run(ctx context.Context, jobCtx *JobContext) error {
+	if !jobCtx.Config.Cron.AutoActivateEnabled {
+		jobCtx.Server.Logger.Info().Msg("Auto-activate is disabled")
+		return nil
+	}
+
+	// The job follows the same transition rules as status changes made
+	// through the API
+	transitions := todo.DefaultStatusTransitions().WithOverrides(jobCtx.Config.Todo.GetStatusTransitions())
+	if !transitions.Allows(todo.StatusDraft, todo.StatusActive) {
+		jobCtx.Server.Logger.Info().Msg("Draft todos may not move to active, skipping auto-activate")
+		return nil
+	}
+
+	leadHours := jobCtx.Config.Cron.GetAutoActivateLeadHours()
+	deadline := time.Now().Add(time.Duration(leadHours) * time.Hour)
+	batchSize := jobCtx.Config.Cron.BatchSize
+
+	jobCtx.Server.Logger.Info().
+		Time("deadline", deadline).
+		Int("lead_hours", leadHours).
+		Msg("Searching for draft todos due soon")
+
+	activatedCount := 0
+	for batchSize > 0 {
+		todos, err := jobCtx.Repositories.Todo.ActivateDraftsDueBy(ctx, deadline, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range todos {
+			draft := todos[i]
+			draft.Status = todo.StatusDraft
+			recordSystemChange(ctx, jobCtx, todo.Change{Before: draft, After: todos[i]})
+		}
+		activatedCount += len(todos)
+
+		if len(todos) < batchSize {
+			break
+		}
+	}
+
+	jobCtx.Server.Logger.Info().
+		Int("activated_count", activatedCount).
+		Msg("Draft todos activated")
+
+	return nil
+}
+
+// recordSystemChange logs a change a job made to a todo in its activity
+// history, attributed to the system, and signals it the way a change made
+// through the API is: to waiting change feeds, live listeners and subscribed
+// webhooks
+func recordSystemChange(ctx context.Context, jobCtx *JobContext, change todo.Change) {
+	changes := activity.Diff(activity.SnapshotTodo(&change.Before), activity.SnapshotTodo(&change.After))
+	if len(changes) == 0 {
+		return
+	}
+
+	changed := &change.After
+	entry, err := jobCtx.Repositories.Activity.CreateActivity(ctx, &activity.Activity{
+		TodoID:  changed.ID,
+		UserID:  changed.UserID,
+		ActorID: activity.SystemActorID,
+		Action:  activity.ActionFor(changes),
+		Changes: changes,
+	})
+	if err != nil {
+		jobCtx.Server.Logger.Error().
+			Err(err).
+			Str("todo_id", changed.ID.String()).
+			Str("user_id", changed.UserID).
+			Msg("Failed to record todo change")
+		return
+	}
+
+	if err := changefeed.NewRedisNotifier(jobCtx.Server.Redis).Publish(ctx, changed.UserID); err != nil {
+		jobCtx.Server.Logger.Warn().
+			Err(err).
+			Str("user_id", changed.UserID).
+			Msg("Failed to signal todo change")
+	}
+
+	event, err := changefeed.NewEvent(changefeed.EventTodoPrefix+string(entry.Action), changed.ID.String(), entry)
+	if err == nil {
+		err = changefeed.NewRedisBroadcaster(jobCtx.Server.Redis).Broadcast(ctx, changed.UserID, event)
+	}
+	if err != nil {
+		jobCtx.Server.Logger.Warn().
+			Err(err).
+			Str("user_id", changed.UserID).
+			Msg("Failed to broadcast todo change")
+	}
+
+	for _, event := range webhook.TodoEvents(entry) {
+		dispatchWebhook(ctx, jobCtx, changed.UserID, event, entry)
+	}
+}
+
+// dispatchWebhook queues the event for each of the user's webhooks subscribed
+// to it. Like dispatching from the API, failures are only logged.
+func dispatchWebhook(ctx context.Context, jobCtx *JobContext, userID string, event string, data any) {
+	logger := jobCtx.Server.Logger.With().Str("user_id", userID).Str("webhook_event", event).Logger()
+
+	hooks, err := jobCtx.Repositories.Webhook.GetSubscribedWebhooks(ctx, userID, event)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to fetch subscribed webhooks")
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to encode webhook payload")
+		return
+	}
+
+	for i := range hooks {
+		delivery, err := jobCtx.Repositories.Webhook.CreateDelivery(ctx, &hooks[i], &webhook.Payload{
+			ID:        uuid.New(),
+			Event:     event,
+			CreatedAt: time.Now(),
+			Data:      encoded,
+		})
+		if err != nil {
+			logger.Warn().Err(err).Str("webhook_id", hooks[i].ID.String()).Msg("Failed to create webhook delivery")
+			continue
+		}
+
+		task := &job.DeliverWebhookTask{DeliveryID: delivery.ID}
+		if err := job.EnqueueDeliverWebhook(jobCtx.JobClient, task, jobCtx.Config.Webhook.GetMaxAttempts()); err != nil {
+			logger.Warn().Err(err).Str("delivery_id", delivery.ID.String()).Msg("Failed to enqueue webhook delivery")
+		}
+	}
+}
+
+// --------
+
+type InboxZeroStreakJob struct{}
+
+func (j *InboxZeroStreakJob) Name() string {
+	return "inbox-zero-streaks"
+}
+
+func (j *InboxZeroStreakJob) Description() string {
+	return "Update each user's streak of days ended with nothing overdue"
+}
+
+// Run judges the day that most recently ended in each user's timezone, so it
+// is meant to run hourly; users whose day was already judged are skipped
+func (j *InboxZeroStreakJob) Run(ctx context.Context, jobCtx *JobContext) error {
+	days, err := jobCtx.Repositories.Streak.GetEndedDays(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cleanCount := 0
+	for _, day := range days {
+		s, err := jobCtx.Repositories.Streak.RecordDay(ctx, day)
+		if err != nil {
+			jobCtx.Server.Logger.Error().
+				Err(err).
+				Str("user_id", day.UserID).
+				Time("day", day.Day).
+				Msg("Failed to record inbox zero day")
+			continue
+		}
+
+		if day.Clean {
+			cleanCount++
+		}
+
+		jobCtx.Server.Logger.Debug().
+			Str("user_id", day.UserID).
+			Time("day", day.Day).
+			Bool("clean", day.Clean).
+			Int("current_streak", s.CurrentStreak).
+			Msg("Inbox zero day recorded")
+	}
+
+	jobCtx.Server.Logger.Info().
+		Int("user_count", len(days)).
+		Int("clean_count", cleanCount).
+		Msg("Inbox zero streaks updated")
+
+	return nil
+}
+
+// --------
+
+type AccountPurgeJob struct{}
+
+func (j *AccountPurgeJob) Name() string {
+	return "account-purge"
+}
+
+func (j *AccountPurgeJob) Description() string {
+	return "Permanently remove the data of accounts deleted longer ago than the retention window"
+}
+
+// Run deletes an account's stored attachments before its rows, so a failed
+// run never leaves objects nothing points at. Accounts whose objects can't
+// all be deleted are left for the next run.
+func (j *AccountPurgeJob) Run(ctx context.Context, jobCtx *JobContext) error {
+	now := time.Now()
+
+	deletions, err := jobCtx.Repositories.Account.GetAccountsDueForPurge(ctx, now, account.PurgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(deletions) == 0 {
+		jobCtx.Server.Logger.Info().Msg("No deleted accounts due for purging")
+		return nil
+	}
+
+	awsClient, err := aws.NewAWS(jobCtx.Server)
+	if err != nil {
+		return err
+	}
+
+	purgedCount := 0
+	for _, deletion := range deletions {
+		logger := jobCtx.Server.Logger.With().Str("user_id", deletion.UserID).Logger()
+
+		keys, err := jobCtx.Repositories.Account.GetAccountAttachmentKeys(ctx, deletion.UserID)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to list attachments of deleted account")
+			continue
+		}
+
+		failed := 0
+		for _, key := range keys {
+			if err := awsClient.S3.DeleteObject(ctx, jobCtx.Config.AWS.UploadBucket, key); err != nil {
+				logger.Error().Err(err).Str("key", key).Msg("Failed to delete attachment of deleted account")
+				failed++
+			}
+		}
+		if failed > 0 {
+			continue
+		}
+
+		purged, err := jobCtx.Repositories.Account.PurgeAccount(ctx, deletion.UserID, now)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to purge deleted account")
+			continue
+		}
+
+		if purged {
+			purgedCount++
+			logger.Info().
+				Int("attachment_count", len(keys)).
+				Time("deleted_at", deletion.CreatedAt).
+				Msg("Deleted account purged")
+		}
+	}
+
+	jobCtx.Server.Logger.Info().
+		Int("due_count", len(deletions)).
+		Int("purged_count", purgedCount).
+		Msg("Deleted accounts purged")
+
+	return nil
+}
+
+// --------
+
+type TrashPurgeJob struct{}
+
+func (j *TrashPurgeJob) Name() string {
+	return "trash-purge"
+}
+
+func (j *TrashPurgeJob) Description() string {
+	return "Permanently remove todos that have been in the trash longer than the retention window"
+}
+
+// Run purges each trashed todo with its subtasks, then removes their stored
+// attachments. An attachment that can't be removed is logged and left behind.
+func (j *TrashPurgeJob) Run(ctx context.Context, jobCtx *JobContext) error {
+	cutoff := time.Now().Add(-jobCtx.Config.Todo.GetTrashRetention())
+
+	trashed, err := jobCtx.Repositories.Todo.GetTrashDueForPurge(ctx, cutoff, todo.TrashPurgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(trashed) == 0 {
+		jobCtx.Server.Logger.Info().Msg("No trashed todos due for purging")
+		return nil
+	}
+
+	awsClient, err := aws.NewAWS(jobCtx.Server)
+	if err != nil {
+		return err
+	}
+
+	purgedCount := 0
+	for _, t := range trashed {
+		logger := jobCtx.Server.Logger.With().
+			Str("user_id", t.UserID).
+			Str("todo_id", t.ID.String()).
+			Logger()
+
+		keys, err := jobCtx.Repositories.Todo.PurgeTodo(ctx, t.UserID, t.ID)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to purge trashed todo")
+			continue
+		}
+		purgedCount++
+
+		for _, key := range keys {
+			if err := awsClient.S3.DeleteObject(ctx, jobCtx.Config.AWS.UploadBucket, key); err != nil {
+				logger.Error().Err(err).Str("key", key).Msg("Failed to delete attachment of purged todo")
+			}
+		}
+	}
+
+	jobCtx.Server.Logger.Info().
+		Int("due_count", len(trashed)).
+		Int("purged_count", purgedCount).
+		Time("cutoff", cutoff).
+		Msg("Trashed todos purged")
+
+	return nil
+}
@@ -18,6 +18,10 @@ func NewJobRegistry() *JobRegistry {
 	registry.Register(&OverdueNotificationsJob{})
 	registry.Register(&WeeklyReportsJob{})
 	registry.Register(&AutoArchiveJob{})
+	registry.Register(&AutoActivateJob{})
+	registry.Register(&InboxZeroStreakJob{})
+	registry.Register(&AccountPurgeJob{})
+	registry.Register(&TrashPurgeJob{})
 
 	return registry
 }
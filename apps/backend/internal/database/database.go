@@ -13,14 +13,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/tracelog"
 	"github.com/newrelic/go-agent/v3/integrations/nrpgx5"
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/rs/zerolog"
 	"github.com/sriniously/tasker/internal/config"
 	loggerConfig "github.com/sriniously/tasker/internal/logger"
+	"github.com/sriniously/tasker/internal/model/todo"
 )
 
 type Database struct {
-	Pool *pgxpool.Pool
-	log  *zerolog.Logger
+	Pool    *pgxpool.Pool
+	Monitor *PoolMonitor
+	log     *zerolog.Logger
 }
 
 // multiTracer allows chaining multiple tracers
@@ -71,6 +74,10 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig
 		return nil, fmt.Errorf("failed to parse pgx pool config: %w", err)
 	}
 
+	for name, value := range SessionParams(&cfg.Database) {
+		pgxPoolConfig.ConnConfig.RuntimeParams[name] = value
+	}
+
 	// Add New Relic PostgreSQL instrumentation
 	if loggerService != nil && loggerService.GetApplication() != nil {
 		pgxPoolConfig.ConnConfig.Tracer = nrpgx5.NewTracer()
@@ -97,6 +104,28 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig
 		}
 	}
 
+	if cfg.Database.TenantIsolation {
+		role := cfg.Database.GetTenantRole()
+		pgxPoolConfig.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+			if err := ApplyTenant(ctx, conn, role); err != nil {
+				// Dropping the connection is safer than handing it out with
+				// the previous request's identity
+				logger.Error().Err(err).Str("role", role).Msg("failed to apply tenant to database connection")
+				return false
+			}
+			return true
+		}
+	}
+
+	// Metadata edited outside the API is repaired while scanning rather than
+	// failing the query; make sure each repair shows up in the logs
+	todo.ReportRepairedMetadata = func(raw []byte, problems []string) {
+		logger.Warn().
+			Str("metadata", string(raw)).
+			Strs("problems", problems).
+			Msg("repaired malformed todo metadata")
+	}
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), pgxPoolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pgx pool: %w", err)
@@ -115,11 +144,39 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig
 
 	logger.Info().Msg("connected to the database")
 
+	if cfg.Observability != nil && cfg.Observability.HealthChecks.Enabled {
+		var nrApp *newrelic.Application
+		if loggerService != nil {
+			nrApp = loggerService.GetApplication()
+		}
+		database.Monitor = NewPoolMonitor(&pgxPinger{pool: pool}, cfg.Observability.HealthChecks, logger, nrApp)
+		database.Monitor.Start()
+	}
+
 	return database, nil
 }
 
+// SessionParams are the Postgres settings every pool connection starts with.
+// The timeouts are enforced by the server, so a stuck statement or abandoned
+// transaction is cleaned up even when the client never cancels it.
+func SessionParams(cfg *config.DatabaseConfig) map[string]string {
+	return map[string]string{
+		"statement_timeout":                   strconv.FormatInt(cfg.GetStatementTimeout().Milliseconds(), 10),
+		"idle_in_transaction_session_timeout": strconv.FormatInt(cfg.GetIdleInTransactionTimeout().Milliseconds(), 10),
+	}
+}
+
 func (db *Database) Close() error {
 	db.log.Info().Msg("closing database connection pool")
+	db.Monitor.Stop()
 	db.Pool.Close()
 	return nil
 }
+
+// PoolStats reports the current connection pool state
+func (db *Database) PoolStats() PoolStats {
+	if db.Monitor != nil {
+		return db.Monitor.Stats()
+	}
+	return (&pgxPinger{pool: db.Pool}).Stat()
+}
@@ -0,0 +1,153 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/database"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionParams(t *testing.T) {
+	t.Run("defaults apply when unset", func(t *testing.T) {
+		params := database.SessionParams(&config.DatabaseConfig{})
+
+		assert.Equal(t, "30000", params["statement_timeout"])
+		assert.Equal(t, "60000", params["idle_in_transaction_session_timeout"])
+	})
+
+	t.Run("configured timeouts are sent in milliseconds", func(t *testing.T) {
+		params := database.SessionParams(&config.DatabaseConfig{
+			StatementTimeout:         1500 * time.Millisecond,
+			IdleInTransactionTimeout: 2 * time.Minute,
+		})
+
+		assert.Equal(t, "1500", params["statement_timeout"])
+		assert.Equal(t, "120000", params["idle_in_transaction_session_timeout"])
+	})
+}
+
+func TestNew_StatementTimeout(t *testing.T) {
+	testDB, cleanup := testing_pkg.SetupTestDB(t)
+	defer cleanup()
+
+	cfg := *testDB.Config
+	cfg.Database.StatementTimeout = 200 * time.Millisecond
+
+	logger := zerolog.Nop()
+	db, err := database.New(&cfg, &logger, nil)
+	require.NoError(t, err)
+	defer db.Pool.Close()
+
+	ctx := context.Background()
+
+	t.Run("slow statement is cancelled by the server", func(t *testing.T) {
+		started := time.Now()
+		_, err := db.Pool.Exec(ctx, "SELECT pg_sleep(5)")
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "57014", pgErr.Code, "query_canceled")
+		assert.Less(t, time.Since(started), 5*time.Second)
+	})
+
+	t.Run("statements within the limit run", func(t *testing.T) {
+		_, err := db.Pool.Exec(ctx, "SELECT pg_sleep(0.05)")
+		require.NoError(t, err)
+	})
+}
+
+func TestNew_TenantIsolation(t *testing.T) {
+	testDB, cleanup := testing_pkg.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	alice, bob, carol := "user_alice", "user_bob", "user_carol"
+
+	// Seed through the owner connection, which row-level security doesn't apply to
+	var orgID string
+	require.NoError(t, testDB.Pool.QueryRow(ctx,
+		`INSERT INTO organizations (name, created_by) VALUES ('Acme', $1) RETURNING id`, carol).Scan(&orgID))
+	_, err := testDB.Pool.Exec(ctx,
+		`INSERT INTO organization_members (org_id, user_id) VALUES ($1, $2), ($1, $3)`, orgID, alice, carol)
+	require.NoError(t, err)
+	_, err = testDB.Pool.Exec(ctx, `INSERT INTO todo_categories (user_id, name) VALUES ($1, 'Private')`, bob)
+	require.NoError(t, err)
+	_, err = testDB.Pool.Exec(ctx, `
+		INSERT INTO todos (user_id, title, org_id)
+		VALUES ($1, 'Alice todo', NULL), ($2, 'Bob todo', NULL), ($3, 'Org todo', $4)`,
+		alice, bob, carol, orgID)
+	require.NoError(t, err)
+
+	cfg := *testDB.Config
+	cfg.Database.TenantIsolation = true
+
+	logger := zerolog.Nop()
+	db, err := database.New(&cfg, &logger, nil)
+	require.NoError(t, err)
+	defer db.Pool.Close()
+
+	titles := func(t *testing.T, ctx context.Context) []string {
+		t.Helper()
+		// Deliberately without a user_id filter
+		rows, err := db.Pool.Query(ctx, `SELECT title FROM todos ORDER BY title`)
+		require.NoError(t, err)
+		collected, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		return collected
+	}
+
+	t.Run("a tenant only sees its own and its organizations' todos", func(t *testing.T) {
+		tenantCtx := database.WithTenant(ctx, alice)
+		assert.Equal(t, []string{"Alice todo", "Org todo"}, titles(t, tenantCtx))
+
+		var categories int
+		require.NoError(t, db.Pool.QueryRow(tenantCtx, `SELECT COUNT(*) FROM todo_categories`).Scan(&categories))
+		assert.Zero(t, categories)
+	})
+
+	t.Run("a tenant can't write rows for another user", func(t *testing.T) {
+		_, err := db.Pool.Exec(database.WithTenant(ctx, alice),
+			`INSERT INTO todos (user_id, title) VALUES ($1, 'Sneaky')`, bob)
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "42501", pgErr.Code, "insufficient_privilege")
+	})
+
+	t.Run("the identity doesn't leak to the next user of the connection", func(t *testing.T) {
+		assert.Equal(t, []string{"Bob todo"}, titles(t, database.WithTenant(ctx, bob)))
+		assert.Len(t, titles(t, ctx), 3)
+		assert.Len(t, titles(t, database.WithTenant(ctx, "")), 3)
+	})
+
+	t.Run("a tenant only sees organizations it belongs to", func(t *testing.T) {
+		var visible int
+		require.NoError(t, db.Pool.QueryRow(database.WithTenant(ctx, alice),
+			`SELECT COUNT(*) FROM organization_members`).Scan(&visible))
+		assert.Equal(t, 2, visible)
+
+		require.NoError(t, db.Pool.QueryRow(database.WithTenant(ctx, bob),
+			`SELECT COUNT(*) FROM organizations`).Scan(&visible))
+		assert.Zero(t, visible)
+	})
+
+	t.Run("every table has row-level security", func(t *testing.T) {
+		// The migrator's bookkeeping holds no tenant data
+		rows, err := testDB.Pool.Query(ctx, `
+			SELECT tablename FROM pg_tables
+			WHERE schemaname = 'public' AND NOT rowsecurity AND tablename != 'schema_version'
+			ORDER BY tablename`)
+		require.NoError(t, err)
+		unprotected, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		assert.Empty(t, unprotected, "enable row-level security and add policies in the migration creating the table")
+	})
+}
@@ -0,0 +1,16 @@
+CREATE TABLE impersonation_audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    impersonator_id TEXT NOT NULL,
+    target_user_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    method TEXT,
+    path TEXT,
+    request_id TEXT,
+    allowed BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX idx_impersonation_audit_logs_impersonator_id ON impersonation_audit_logs(impersonator_id);
+CREATE INDEX idx_impersonation_audit_logs_target_user_id ON impersonation_audit_logs(target_user_id);
+CREATE INDEX idx_impersonation_audit_logs_created_at ON impersonation_audit_logs(created_at);
@@ -0,0 +1,49 @@
+CREATE TABLE user_preferences (
+    user_id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    timezone TEXT NOT NULL DEFAULT 'UTC'
+);
+
+CREATE TRIGGER set_updated_at_user_preferences
+    BEFORE UPDATE ON user_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+ALTER TABLE todos ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Resolves a user's preferred timezone, defaulting to UTC
+CREATE OR REPLACE FUNCTION user_timezone(p_user_id TEXT)
+    RETURNS TEXT
+    LANGUAGE sql
+    STABLE
+    AS $$
+    SELECT
+        COALESCE(
+            (
+                SELECT
+                    timezone
+                FROM
+                    user_preferences
+                WHERE
+                    user_id = p_user_id
+            ),
+            'UTC'
+        );
+$$;
+
+-- Timed todos pass their due date at the exact instant; all-day todos only
+-- once the calendar day they fall on has ended in the given timezone
+CREATE OR REPLACE FUNCTION todo_due_passed(p_due_date TIMESTAMPTZ, p_all_day BOOLEAN, p_timezone TEXT)
+    RETURNS BOOLEAN
+    LANGUAGE sql
+    STABLE
+    AS $$
+    SELECT
+        p_due_date IS NOT NULL
+        AND CASE
+            WHEN p_all_day THEN (p_due_date AT TIME ZONE p_timezone)::DATE < (NOW() AT TIME ZONE p_timezone)::DATE
+            ELSE p_due_date < NOW()
+        END;
+$$;
@@ -0,0 +1,14 @@
+-- History is kept after a todo is deleted, so todo_id is not a foreign key
+CREATE TABLE todo_activities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGSERIAL NOT NULL,
+
+    todo_id UUID NOT NULL,
+    user_id TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('created', 'updated', 'status_changed', 'deleted')),
+    changes JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_todo_activities_todo_id_seq ON todo_activities(todo_id, seq);
@@ -0,0 +1,2 @@
+-- Account-wide activity views page through a user's history newest first
+CREATE INDEX idx_todo_activities_user_id_created_at ON todo_activities(user_id, created_at DESC, seq DESC);
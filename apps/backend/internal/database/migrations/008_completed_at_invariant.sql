@@ -0,0 +1,33 @@
+-- Backstop for the status/completed_at invariant the repository maintains:
+-- completed todos always have completed_at, draft and active todos never do.
+-- Archived todos keep whatever completed_at they had.
+UPDATE todos
+SET
+    completed_at = updated_at
+WHERE
+    status = 'completed'
+    AND completed_at IS NULL;
+
+UPDATE todos
+SET
+    completed_at = NULL
+WHERE
+    status IN ('draft', 'active')
+    AND completed_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION trigger_sync_completed_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'completed' AND NEW.completed_at IS NULL THEN
+        NEW.completed_at = CURRENT_TIMESTAMP;
+    ELSIF NEW.status IN ('draft', 'active') THEN
+        NEW.completed_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sync_completed_at_todos
+    BEFORE INSERT OR UPDATE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_sync_completed_at();
@@ -0,0 +1,7 @@
+-- Each user gets at most one Inbox, created lazily for uncategorized todos
+ALTER TABLE todo_categories
+ADD COLUMN is_inbox BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX todo_categories_unique_inbox ON todo_categories(user_id)
+WHERE
+    is_inbox;
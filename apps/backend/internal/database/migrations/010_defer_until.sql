@@ -0,0 +1,7 @@
+-- Deferred todos stay hidden from the default list until defer_until passes
+ALTER TABLE todos
+ADD COLUMN defer_until TIMESTAMPTZ;
+
+CREATE INDEX idx_todos_user_id_defer_until ON todos(user_id, defer_until)
+WHERE
+    defer_until IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Links a todo to the one whose completion spawned it, so chains are traceable
+ALTER TABLE todos
+ADD COLUMN follow_up_of UUID REFERENCES todos ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_follow_up_of ON todos(follow_up_of);
@@ -0,0 +1,38 @@
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    name TEXT NOT NULL,
+    created_by TEXT NOT NULL
+);
+
+CREATE TRIGGER set_updated_at_organizations
+    BEFORE UPDATE ON organizations
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+CREATE TABLE organization_members (
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    org_id UUID NOT NULL REFERENCES organizations ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user_id ON organization_members(user_id);
+
+CREATE TRIGGER set_updated_at_organization_members
+    BEFORE UPDATE ON organization_members
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Todos without an org_id are personal and visible only to their owner
+ALTER TABLE todos
+ADD COLUMN org_id UUID REFERENCES organizations ON DELETE CASCADE;
+
+CREATE INDEX idx_todos_org_id ON todos(org_id)
+WHERE
+    org_id IS NOT NULL;
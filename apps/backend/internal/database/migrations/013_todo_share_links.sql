@@ -0,0 +1,15 @@
+-- A share link grants read-only access to one todo until it is revoked.
+-- The link id is the signed share token's jti.
+CREATE TABLE todo_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    todo_id UUID NOT NULL REFERENCES todos ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    revoked_at TIMESTAMP(3) WITH TIME ZONE
+);
+
+-- At most one live link per todo
+CREATE UNIQUE INDEX idx_todo_share_links_todo_id_active ON todo_share_links(todo_id)
+WHERE
+    revoked_at IS NULL;
@@ -0,0 +1,4 @@
+-- Audio attachments are transcribed in the background; the text is matched by todo search
+ALTER TABLE todo_attachments
+ADD COLUMN transcript TEXT,
+ADD COLUMN transcribed_at TIMESTAMP(3) WITH TIME ZONE;
@@ -0,0 +1,85 @@
+-- Per-user status counts kept in step with todos so the stats endpoint doesn't
+-- scan every todo a user owns on each dashboard load. The trigger runs in the
+-- writing transaction, so the summary is never stale once a write commits; the
+-- cost is an extra row update per todo write, and concurrent writes by the same
+-- user serialise on that user's summary row.
+--
+-- Overdue depends on the current time, so it can't be maintained on write. It
+-- is still counted live, helped by the partial index below, which only covers
+-- unfinished todos with a due date.
+CREATE TABLE todo_stats_summary (
+    user_id TEXT PRIMARY KEY,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    total INTEGER NOT NULL DEFAULT 0,
+    draft INTEGER NOT NULL DEFAULT 0,
+    active INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    archived INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE OR REPLACE FUNCTION todo_stats_summary_apply(p_user_id TEXT, p_status TEXT, p_delta INTEGER)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO todo_stats_summary (user_id, total, draft, active, completed, archived)
+    VALUES (
+        p_user_id,
+        p_delta,
+        CASE WHEN p_status = 'draft' THEN p_delta ELSE 0 END,
+        CASE WHEN p_status = 'active' THEN p_delta ELSE 0 END,
+        CASE WHEN p_status = 'completed' THEN p_delta ELSE 0 END,
+        CASE WHEN p_status = 'archived' THEN p_delta ELSE 0 END
+    )
+    ON CONFLICT (user_id) DO UPDATE
+    SET
+        total = todo_stats_summary.total + EXCLUDED.total,
+        draft = todo_stats_summary.draft + EXCLUDED.draft,
+        active = todo_stats_summary.active + EXCLUDED.active,
+        completed = todo_stats_summary.completed + EXCLUDED.completed,
+        archived = todo_stats_summary.archived + EXCLUDED.archived,
+        updated_at = CURRENT_TIMESTAMP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION trigger_maintain_todo_stats_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.user_id = NEW.user_id AND OLD.status = NEW.status THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM todo_stats_summary_apply(OLD.user_id, OLD.status, -1);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM todo_stats_summary_apply(NEW.user_id, NEW.status, 1);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER maintain_todo_stats_summary
+    AFTER INSERT OR UPDATE OR DELETE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_maintain_todo_stats_summary();
+
+-- Backfill existing users from a single scan
+INSERT INTO todo_stats_summary (user_id, total, draft, active, completed, archived)
+SELECT
+    user_id,
+    COUNT(*),
+    COUNT(*) FILTER (WHERE status = 'draft'),
+    COUNT(*) FILTER (WHERE status = 'active'),
+    COUNT(*) FILTER (WHERE status = 'completed'),
+    COUNT(*) FILTER (WHERE status = 'archived')
+FROM
+    todos
+GROUP BY
+    user_id;
+
+CREATE INDEX idx_todos_user_unfinished_due ON todos(user_id, due_date)
+WHERE
+    due_date IS NOT NULL
+    AND status != 'completed';
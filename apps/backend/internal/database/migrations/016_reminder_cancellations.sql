@@ -0,0 +1,11 @@
+-- Reminders aren't stored; the cron jobs derive them from each todo's due date.
+-- A cancellation suppresses one kind of reminder for the due date it was made
+-- against, so moving the due date schedules the reminder again.
+CREATE TABLE todo_reminder_cancellations (
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    todo_id UUID NOT NULL REFERENCES todos ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('due_soon', 'overdue')),
+    due_date TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (todo_id, kind)
+);
@@ -0,0 +1,24 @@
+-- The streak job judges each user's most recently ended calendar day, in the
+-- user's timezone, as clean when nothing was overdue at its end. One row per
+-- judged day keeps the job idempotent and the history inspectable.
+CREATE TABLE user_clean_days (
+    user_id TEXT NOT NULL,
+    day DATE NOT NULL,
+    clean BOOLEAN NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE TABLE user_streaks (
+    user_id TEXT PRIMARY KEY,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    longest_streak INTEGER NOT NULL DEFAULT 0,
+    last_day DATE
+);
+
+CREATE TRIGGER set_updated_at_user_streaks
+    BEFORE UPDATE ON user_streaks
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,8 @@
+-- Bumping a todo only moves its updated_at, and is recorded as its own action
+ALTER TABLE todo_activities
+DROP CONSTRAINT todo_activities_action_check;
+
+ALTER TABLE todo_activities
+ADD CONSTRAINT todo_activities_action_check CHECK (
+    action IN ('created', 'updated', 'status_changed', 'deleted', 'bumped')
+);
@@ -0,0 +1,80 @@
+-- Row-level security as defense in depth on top of the user_id filters every
+-- query already carries. The role the app connects as owns the tables and so
+-- bypasses these policies; a request is only held to them when
+-- TASKER_DATABASE.TENANT_ISOLATION switches its connection to the
+-- tasker_tenant role with app.user_id set to the caller.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tasker_tenant') THEN
+        CREATE ROLE tasker_tenant NOLOGIN;
+    END IF;
+
+    EXECUTE format('GRANT tasker_tenant TO %I', CURRENT_USER);
+EXCEPTION
+    WHEN insufficient_privilege THEN
+        RAISE NOTICE 'cannot create or grant role tasker_tenant, tenant isolation will be unavailable';
+END
+$$;
+
+CREATE OR REPLACE FUNCTION tenant_user_id() RETURNS TEXT AS $$
+    SELECT NULLIF(current_setting('app.user_id', true), '');
+$$ LANGUAGE sql STABLE;
+
+-- Runs as the table owner so the todos policy can look up memberships
+-- without organization_members needing a policy of its own
+CREATE OR REPLACE FUNCTION tenant_org_ids() RETURNS SETOF UUID AS $$
+    SELECT org_id FROM organization_members WHERE user_id = tenant_user_id();
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+ALTER TABLE todos ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_categories ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_activities ENABLE ROW LEVEL SECURITY;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tasker_tenant') THEN
+        RETURN;
+    END IF;
+
+    GRANT USAGE ON SCHEMA public TO tasker_tenant;
+    GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO tasker_tenant;
+    GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO tasker_tenant;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO tasker_tenant;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO tasker_tenant;
+
+    -- A user's own todos plus those of organizations they belong to
+    CREATE POLICY tenant_todos ON todos TO tasker_tenant
+        USING (user_id = tenant_user_id() OR org_id IN (SELECT tenant_org_ids()))
+        WITH CHECK (user_id = tenant_user_id() OR org_id IN (SELECT tenant_org_ids()));
+
+    -- Categories of visible todos stay readable so organization todos keep
+    -- their category, but only a user's own can be changed
+    CREATE POLICY tenant_categories_read ON todo_categories FOR SELECT TO tasker_tenant
+        USING (
+            user_id = tenant_user_id()
+            OR EXISTS (SELECT 1 FROM todos t WHERE t.category_id = todo_categories.id)
+        );
+    CREATE POLICY tenant_categories_insert ON todo_categories FOR INSERT TO tasker_tenant
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_categories_update ON todo_categories FOR UPDATE TO tasker_tenant
+        USING (user_id = tenant_user_id());
+    CREATE POLICY tenant_categories_delete ON todo_categories FOR DELETE TO tasker_tenant
+        USING (user_id = tenant_user_id());
+
+    -- Comments, attachments and activity follow the visibility of their todo
+    CREATE POLICY tenant_comments ON todo_comments TO tasker_tenant
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_comments.todo_id))
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_attachments ON todo_attachments TO tasker_tenant
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_attachments.todo_id))
+        WITH CHECK (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_attachments.todo_id));
+    CREATE POLICY tenant_activities ON todo_activities TO tasker_tenant
+        USING (
+            user_id = tenant_user_id()
+            OR EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_activities.todo_id)
+        )
+        WITH CHECK (user_id = tenant_user_id() OR actor_id = tenant_user_id());
+END
+$$;
@@ -0,0 +1,13 @@
+-- A checklist is a list of {id, text, done} steps stored on the todo itself.
+-- Items are changed in place by id so concurrent edits to different items
+-- don't overwrite each other. Progress is derived so every read returns it.
+ALTER TABLE todos
+ADD COLUMN checklist JSONB NOT NULL DEFAULT '[]'::JSONB CHECK (jsonb_typeof(checklist) = 'array');
+
+ALTER TABLE todos
+ADD COLUMN checklist_total INTEGER GENERATED ALWAYS AS (jsonb_array_length(checklist)) STORED;
+
+ALTER TABLE todos
+ADD COLUMN checklist_done INTEGER GENERATED ALWAYS AS (
+    jsonb_array_length(jsonb_path_query_array(checklist, '$[*] ? (@.done == true)'))
+) STORED;
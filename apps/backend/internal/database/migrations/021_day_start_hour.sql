@@ -0,0 +1,55 @@
+-- Night owls can have their day roll over at a later hour than midnight. A
+-- moment belongs to the day it falls in once the day start hour is taken off,
+-- so with a 3am start 1am still counts towards the previous day.
+ALTER TABLE user_preferences
+ADD COLUMN day_start_hour INTEGER NOT NULL DEFAULT 0 CHECK (day_start_hour BETWEEN 0 AND 23);
+
+-- Resolves the hour a user's day starts at, defaulting to midnight
+CREATE OR REPLACE FUNCTION user_day_start_hour(p_user_id TEXT)
+    RETURNS INTEGER
+    LANGUAGE sql
+    STABLE
+    AS $$
+    SELECT
+        COALESCE(
+            (
+                SELECT
+                    day_start_hour
+                FROM
+                    user_preferences
+                WHERE
+                    user_id = p_user_id
+            ),
+            0
+        );
+$$;
+
+-- The day p_ts counts towards in the given timezone and day start hour
+CREATE OR REPLACE FUNCTION local_day(p_ts TIMESTAMPTZ, p_timezone TEXT, p_day_start_hour INTEGER)
+    RETURNS DATE
+    LANGUAGE sql
+    STABLE
+    AS $$
+    SELECT
+        ((p_ts AT TIME ZONE p_timezone) - make_interval(hours => p_day_start_hour))::DATE;
+$$;
+
+-- All-day todos pass their due date once the user's day for that calendar
+-- date is over, which with a later day start is after midnight
+CREATE OR REPLACE FUNCTION todo_due_passed(p_due_date TIMESTAMPTZ, p_all_day BOOLEAN, p_timezone TEXT,
+    p_day_start_hour INTEGER)
+    RETURNS BOOLEAN
+    LANGUAGE sql
+    STABLE
+    AS $$
+    SELECT
+        p_due_date IS NOT NULL
+        AND CASE
+            WHEN p_all_day THEN (p_due_date AT TIME ZONE p_timezone)::DATE < local_day(NOW(), p_timezone, p_day_start_hour)
+            ELSE p_due_date < NOW()
+        END;
+$$;
+
+-- Every caller passes the day start hour now; dropping the overload from 005
+-- keeps a call that leaves it out from quietly ignoring the user's setting
+DROP FUNCTION todo_due_passed(TIMESTAMPTZ, BOOLEAN, TEXT);
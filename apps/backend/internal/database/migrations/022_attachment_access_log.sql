@@ -0,0 +1,16 @@
+-- Who was handed a download link for which attachment. Rows outlive the
+-- attachment and its todo, so there are no foreign keys.
+CREATE TABLE attachment_access_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id TEXT NOT NULL,
+    todo_id UUID NOT NULL,
+    attachment_id UUID NOT NULL,
+    ip TEXT,
+    request_id TEXT
+);
+
+CREATE INDEX idx_attachment_access_log_attachment_id ON attachment_access_log(attachment_id, created_at DESC);
+CREATE INDEX idx_attachment_access_log_user_id ON attachment_access_log(user_id, created_at DESC);
+CREATE INDEX idx_attachment_access_log_created_at ON attachment_access_log(created_at);
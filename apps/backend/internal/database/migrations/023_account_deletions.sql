@@ -0,0 +1,15 @@
+-- A deleted account keeps its data until purge_after so an accidental
+-- deletion can be undone. The row outlives the purge, which keeps the account
+-- locked out and records when its data went.
+CREATE TABLE account_deletions (
+    user_id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    deleted_by TEXT NOT NULL,
+    purge_after TIMESTAMPTZ NOT NULL,
+    purged_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_account_deletions_purge_after ON account_deletions(purge_after)
+WHERE
+    purged_at IS NULL;
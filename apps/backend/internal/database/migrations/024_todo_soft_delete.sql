@@ -0,0 +1,40 @@
+-- Deleted todos go to the trash, where they can be restored until they are
+-- purged. A todo is deleted together with its subtasks, which share its
+-- deleted_at so that restoring it brings back exactly what went with it.
+ALTER TABLE todos
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_todos_user_id_deleted_at ON todos(user_id, deleted_at DESC)
+WHERE
+    deleted_at IS NOT NULL;
+
+-- Todos in the trash don't count towards the summary
+CREATE OR REPLACE FUNCTION trigger_maintain_todo_stats_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.user_id = NEW.user_id
+        AND OLD.status = NEW.status
+        AND (OLD.deleted_at IS NULL) = (NEW.deleted_at IS NULL) THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.deleted_at IS NULL THEN
+        PERFORM todo_stats_summary_apply(OLD.user_id, OLD.status, -1);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.deleted_at IS NULL THEN
+        PERFORM todo_stats_summary_apply(NEW.user_id, NEW.status, 1);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE todo_activities
+DROP CONSTRAINT todo_activities_action_check;
+
+ALTER TABLE todo_activities
+ADD CONSTRAINT todo_activities_action_check CHECK (
+    action IN ('created', 'updated', 'status_changed', 'deleted', 'bumped', 'restored')
+);
@@ -0,0 +1,3 @@
+-- Cursor pagination of the todo list seeks on (created_at, id) instead of
+-- skipping rows with OFFSET
+CREATE INDEX idx_todos_user_id_created_at_id ON todos(user_id, created_at DESC, id DESC);
@@ -0,0 +1,90 @@
+-- Tags written to todo metadata are mirrored into tags and todo_tags so they
+-- can be listed, counted and filtered on without unpacking every todo's
+-- metadata. Metadata stays what the API reads and writes; the trigger below
+-- keeps the tables in step within the writing transaction, whichever path
+-- wrote the todo. A tag belongs to the owner of the todos carrying it and is
+-- matched regardless of letter case, keeping the first spelling seen.
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_tags_user_id_name ON tags(user_id, LOWER(name));
+
+CREATE TRIGGER set_updated_at_tags
+    BEFORE UPDATE ON tags
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+CREATE TABLE todo_tags (
+    todo_id UUID NOT NULL REFERENCES todos ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags(tag_id);
+
+CREATE OR REPLACE FUNCTION sync_todo_tags(p_todo_id UUID, p_user_id TEXT, p_metadata JSONB)
+RETURNS VOID AS $$
+BEGIN
+    DELETE FROM todo_tags WHERE todo_id = p_todo_id;
+
+    IF jsonb_typeof(p_metadata->'tags') IS DISTINCT FROM 'array' THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO tags (user_id, name)
+    SELECT DISTINCT ON (LOWER(e.tag))
+        p_user_id,
+        e.tag
+    FROM
+        jsonb_array_elements_text(p_metadata->'tags') WITH ORDINALITY AS e (tag, position)
+    WHERE
+        e.tag != ''
+    ORDER BY
+        LOWER(e.tag),
+        e.position
+    ON CONFLICT (user_id, LOWER(name)) DO NOTHING;
+
+    INSERT INTO todo_tags (todo_id, tag_id)
+    SELECT DISTINCT
+        p_todo_id,
+        tg.id
+    FROM
+        jsonb_array_elements_text(p_metadata->'tags') AS e (tag)
+        JOIN tags tg ON tg.user_id = p_user_id
+        AND LOWER(tg.name) = LOWER(e.tag);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION trigger_sync_todo_tags()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.user_id = NEW.user_id
+        AND OLD.metadata IS NOT DISTINCT FROM NEW.metadata THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM sync_todo_tags(NEW.id, NEW.user_id, NEW.metadata);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sync_todo_tags
+    AFTER INSERT OR UPDATE OF metadata, user_id ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_sync_todo_tags();
+
+-- Backfill from the tags already in metadata
+SELECT
+    sync_todo_tags(id, user_id, metadata)
+FROM
+    todos
+WHERE
+    jsonb_typeof(metadata->'tags') = 'array';
@@ -0,0 +1,15 @@
+-- A todo is blocked by each todo it depends on until that todo is completed
+-- or archived. Links never form a cycle; the repository checks before adding
+-- one. Both todos belong to the same user.
+CREATE TABLE todo_dependencies (
+    todo_id UUID NOT NULL REFERENCES todos ON DELETE CASCADE,
+    blocked_by_id UUID NOT NULL REFERENCES todos ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id TEXT NOT NULL,
+
+    PRIMARY KEY (todo_id, blocked_by_id),
+    CHECK (todo_id != blocked_by_id)
+);
+
+CREATE INDEX idx_todo_dependencies_blocked_by_id ON todo_dependencies(blocked_by_id);
@@ -0,0 +1,20 @@
+-- A template is a saved todo tree that new todos are created from. The tree
+-- is kept whole in root: titles and descriptions may hold {{variable}}
+-- placeholders, and due dates are stored as offsets from the template's start.
+CREATE TABLE todo_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    root JSONB NOT NULL
+);
+
+CREATE UNIQUE INDEX todo_templates_unique_name ON todo_templates(user_id, LOWER(name));
+
+CREATE TRIGGER set_updated_at_todo_templates
+    BEFORE UPDATE ON todo_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
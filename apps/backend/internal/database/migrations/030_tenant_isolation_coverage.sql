@@ -0,0 +1,103 @@
+-- Extends the row-level security from 019 to every other table holding a
+-- user's or an organization's data. Tables created from here on enable it
+-- and add their policies in the migration that creates them.
+--
+-- Functions run from triggers or queries on behalf of the owner of a todo,
+-- who in an organization can be someone other than the caller, run as the
+-- table owner so the caller's policies don't stop them
+ALTER FUNCTION user_timezone(TEXT) SECURITY DEFINER SET search_path = public;
+ALTER FUNCTION user_day_start_hour(TEXT) SECURITY DEFINER SET search_path = public;
+ALTER FUNCTION todo_stats_summary_apply(TEXT, TEXT, INTEGER) SECURITY DEFINER SET search_path = public;
+ALTER FUNCTION sync_todo_tags(UUID, TEXT, JSONB) SECURITY DEFINER SET search_path = public;
+
+-- Organizations they created stay visible to their creator while the owner
+-- membership is being added
+CREATE OR REPLACE FUNCTION tenant_created_org_ids() RETURNS SETOF UUID AS $$
+    SELECT id FROM organizations WHERE created_by = tenant_user_id();
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+ALTER TABLE user_preferences ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_stats_summary ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_clean_days ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_streaks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_share_links ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_reminder_cancellations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachment_access_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE account_deletions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_dependencies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+-- Only admin tooling, which runs without a tenant, reads or writes the
+-- impersonation audit log, so tenants get no policy and see nothing
+ALTER TABLE impersonation_audit_logs ENABLE ROW LEVEL SECURITY;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tasker_tenant') THEN
+        RETURN;
+    END IF;
+
+    -- Rows keyed by the user they belong to
+    CREATE POLICY tenant_user_preferences ON user_preferences TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_todo_stats_summary ON todo_stats_summary TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_user_clean_days ON user_clean_days TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_user_streaks ON user_streaks TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_share_links ON todo_share_links TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_attachment_access_log ON attachment_access_log TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_account_deletions ON account_deletions TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_tags ON tags TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_dependencies ON todo_dependencies TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_templates ON todo_templates TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_webhooks ON webhooks TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+    CREATE POLICY tenant_webhook_deliveries ON webhook_deliveries TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+
+    -- Rows hanging off a todo follow the visibility of the todo
+    CREATE POLICY tenant_reminder_cancellations ON todo_reminder_cancellations TO tasker_tenant
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_reminder_cancellations.todo_id))
+        WITH CHECK (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_reminder_cancellations.todo_id));
+    CREATE POLICY tenant_todo_tags ON todo_tags TO tasker_tenant
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_tags.todo_id))
+        WITH CHECK (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_tags.todo_id));
+
+    -- Members see their organizations and each other; which of them may
+    -- change an organization or its members is up to the role checks in the API
+    CREATE POLICY tenant_organizations ON organizations TO tasker_tenant
+        USING (id IN (SELECT tenant_org_ids()) OR created_by = tenant_user_id())
+        WITH CHECK (id IN (SELECT tenant_org_ids()) OR created_by = tenant_user_id());
+    CREATE POLICY tenant_organization_members ON organization_members TO tasker_tenant
+        USING (org_id IN (SELECT tenant_org_ids()))
+        WITH CHECK (
+            org_id IN (SELECT tenant_org_ids())
+            OR org_id IN (SELECT tenant_created_org_ids())
+        );
+END
+$$;
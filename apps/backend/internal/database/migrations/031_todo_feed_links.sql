@@ -0,0 +1,28 @@
+-- A feed link lets feed readers fetch a user's completed todos feed until it
+-- is revoked or replaced. The link id is the signed feed token's jti.
+CREATE TABLE todo_feed_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id TEXT NOT NULL,
+    revoked_at TIMESTAMP(3) WITH TIME ZONE
+);
+
+-- At most one live link per user
+CREATE UNIQUE INDEX idx_todo_feed_links_user_id_active ON todo_feed_links(user_id)
+WHERE
+    revoked_at IS NULL;
+
+ALTER TABLE todo_feed_links ENABLE ROW LEVEL SECURITY;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tasker_tenant') THEN
+        RETURN;
+    END IF;
+
+    CREATE POLICY tenant_feed_links ON todo_feed_links TO tasker_tenant
+        USING (user_id = tenant_user_id())
+        WITH CHECK (user_id = tenant_user_id());
+END
+$$;
@@ -0,0 +1,8 @@
+-- Handing a todo to another user is recorded as its own action
+ALTER TABLE todo_activities
+DROP CONSTRAINT todo_activities_action_check;
+
+ALTER TABLE todo_activities
+ADD CONSTRAINT todo_activities_action_check CHECK (
+    action IN ('created', 'updated', 'status_changed', 'deleted', 'bumped', 'restored', 'reassigned')
+);
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+)
+
+// PoolStats is a snapshot of the connection pool surfaced on the readiness endpoint
+type PoolStats struct {
+	Total         int32     `json:"total"`
+	InUse         int32     `json:"in_use"`
+	Idle          int32     `json:"idle"`
+	Max           int32     `json:"max"`
+	Recycled      int64     `json:"recycled"`
+	LastCheckedAt time.Time `json:"last_checked_at,omitempty"`
+}
+
+// PoolConn is a single idle connection acquired for a health ping
+type PoolConn interface {
+	Ping(ctx context.Context) error
+	// Release returns a healthy connection to the pool
+	Release()
+	// Recycle removes a broken connection from the pool and closes it
+	Recycle(ctx context.Context)
+}
+
+// Pinger abstracts the pool so the monitor can be exercised without a database
+type Pinger interface {
+	AcquireAllIdle(ctx context.Context) []PoolConn
+	Stat() PoolStats
+}
+
+// PoolCheckResult describes the outcome of a single monitor pass
+type PoolCheckResult struct {
+	Checked  int
+	Recycled int
+}
+
+// PoolMonitor periodically pings idle pool connections and recycles the ones
+// that fail, so stale connections left behind by a failover are replaced
+// before a request picks them up.
+type PoolMonitor struct {
+	pool     Pinger
+	interval time.Duration
+	timeout  time.Duration
+	logger   *zerolog.Logger
+	nrApp    *newrelic.Application
+
+	mu            sync.RWMutex
+	recycled      int64
+	lastCheckedAt time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewPoolMonitor(pool Pinger, cfg config.HealthChecksConfig, logger *zerolog.Logger,
+	nrApp *newrelic.Application,
+) *PoolMonitor {
+	return &PoolMonitor{
+		pool:     pool,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		logger:   logger,
+		nrApp:    nrApp,
+	}
+}
+
+// Start runs health passes on the configured interval until Stop is called
+func (m *PoolMonitor) Start() {
+	if m == nil || m.stop != nil || m.interval <= 0 {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.Check(context.Background())
+			}
+		}
+	}()
+
+	m.logger.Info().
+		Dur("interval", m.interval).
+		Dur("timeout", m.timeout).
+		Msg("database pool health monitor started")
+}
+
+func (m *PoolMonitor) Stop() {
+	if m == nil || m.stop == nil {
+		return
+	}
+
+	close(m.stop)
+	<-m.done
+	m.stop = nil
+}
+
+// Check pings every idle connection once, recycling those that fail
+func (m *PoolMonitor) Check(ctx context.Context) PoolCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	result := PoolCheckResult{}
+
+	for _, conn := range m.pool.AcquireAllIdle(ctx) {
+		result.Checked++
+
+		if err := conn.Ping(ctx); err != nil {
+			m.logger.Warn().Err(err).Msg("recycling database connection that failed health ping")
+			conn.Recycle(ctx)
+			result.Recycled++
+			continue
+		}
+
+		conn.Release()
+	}
+
+	m.mu.Lock()
+	m.recycled += int64(result.Recycled)
+	m.lastCheckedAt = time.Now().UTC()
+	m.mu.Unlock()
+
+	if result.Recycled > 0 {
+		stats := m.Stats()
+		m.logger.Error().
+			Int("checked", result.Checked).
+			Int("recycled", result.Recycled).
+			Int32("total", stats.Total).
+			Int32("in_use", stats.InUse).
+			Int32("idle", stats.Idle).
+			Msg("database pool health check found unhealthy connections")
+
+		if m.nrApp != nil {
+			m.nrApp.RecordCustomEvent("DatabasePoolUnhealthy", map[string]interface{}{
+				"checked":  result.Checked,
+				"recycled": result.Recycled,
+				"total":    stats.Total,
+				"in_use":   stats.InUse,
+				"idle":     stats.Idle,
+			})
+		}
+	}
+
+	return result
+}
+
+// Stats returns the current pool state along with monitor counters
+func (m *PoolMonitor) Stats() PoolStats {
+	stats := m.pool.Stat()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats.Recycled = m.recycled
+	stats.LastCheckedAt = m.lastCheckedAt
+
+	return stats
+}
+
+// pgxPinger adapts a pgxpool.Pool to the Pinger interface
+type pgxPinger struct {
+	pool *pgxpool.Pool
+}
+
+func (p *pgxPinger) AcquireAllIdle(ctx context.Context) []PoolConn {
+	idle := p.pool.AcquireAllIdle(ctx)
+	conns := make([]PoolConn, 0, len(idle))
+	for _, conn := range idle {
+		conns = append(conns, &pgxPoolConn{conn: conn})
+	}
+	return conns
+}
+
+func (p *pgxPinger) Stat() PoolStats {
+	stat := p.pool.Stat()
+	return PoolStats{
+		Total: stat.TotalConns(),
+		InUse: stat.AcquiredConns(),
+		Idle:  stat.IdleConns(),
+		Max:   stat.MaxConns(),
+	}
+}
+
+type pgxPoolConn struct {
+	conn *pgxpool.Conn
+}
+
+func (c *pgxPoolConn) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *pgxPoolConn) Release() {
+	c.conn.Release()
+}
+
+func (c *pgxPoolConn) Recycle(ctx context.Context) {
+	_ = c.conn.Hijack().Close(ctx)
+}
@@ -0,0 +1,121 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConn struct {
+	pool     *fakePool
+	pingErr  error
+	released bool
+	recycled bool
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	return c.pingErr
+}
+
+func (c *fakeConn) Release() {
+	c.released = true
+}
+
+func (c *fakeConn) Recycle(ctx context.Context) {
+	c.recycled = true
+	c.pool.total--
+}
+
+type fakePool struct {
+	conns []*fakeConn
+	total int32
+	inUse int32
+}
+
+func (p *fakePool) AcquireAllIdle(ctx context.Context) []database.PoolConn {
+	conns := make([]database.PoolConn, 0, len(p.conns))
+	for _, c := range p.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (p *fakePool) Stat() database.PoolStats {
+	return database.PoolStats{
+		Total: p.total,
+		InUse: p.inUse,
+		Idle:  p.total - p.inUse,
+		Max:   10,
+	}
+}
+
+func newFakePool(pingErrs ...error) *fakePool {
+	pool := &fakePool{inUse: 1}
+	for _, err := range pingErrs {
+		pool.conns = append(pool.conns, &fakeConn{pool: pool, pingErr: err})
+	}
+	pool.total = int32(len(pingErrs)) + pool.inUse
+	return pool
+}
+
+func newTestMonitor(pool database.Pinger) *database.PoolMonitor {
+	logger := zerolog.Nop()
+	return database.NewPoolMonitor(pool, config.HealthChecksConfig{
+		Enabled:  true,
+		Interval: time.Second,
+		Timeout:  time.Second,
+	}, &logger, nil)
+}
+
+func TestPoolMonitor_Check(t *testing.T) {
+	t.Run("failing ping recycles the connection", func(t *testing.T) {
+		pool := newFakePool(nil, errors.New("connection reset by peer"), nil)
+		monitor := newTestMonitor(pool)
+
+		result := monitor.Check(context.Background())
+
+		assert.Equal(t, 3, result.Checked)
+		assert.Equal(t, 1, result.Recycled)
+		assert.True(t, pool.conns[1].recycled)
+		assert.False(t, pool.conns[1].released)
+		assert.True(t, pool.conns[0].released)
+		assert.True(t, pool.conns[2].released)
+	})
+
+	t.Run("healthy pool recycles nothing", func(t *testing.T) {
+		pool := newFakePool(nil, nil)
+		monitor := newTestMonitor(pool)
+
+		result := monitor.Check(context.Background())
+
+		assert.Equal(t, 2, result.Checked)
+		assert.Equal(t, 0, result.Recycled)
+	})
+}
+
+func TestPoolMonitor_Stats(t *testing.T) {
+	pool := newFakePool(nil, errors.New("broken pipe"), errors.New("broken pipe"))
+	monitor := newTestMonitor(pool)
+
+	before := monitor.Stats()
+	assert.Equal(t, int32(4), before.Total)
+	assert.Equal(t, int32(1), before.InUse)
+	assert.Equal(t, int32(3), before.Idle)
+	assert.Equal(t, int64(0), before.Recycled)
+	assert.True(t, before.LastCheckedAt.IsZero())
+
+	monitor.Check(context.Background())
+
+	after := monitor.Stats()
+	assert.Equal(t, int32(2), after.Total)
+	assert.Equal(t, int32(1), after.InUse)
+	assert.Equal(t, int32(1), after.Idle)
+	assert.Equal(t, int64(2), after.Recycled)
+	assert.False(t, after.LastCheckedAt.IsZero())
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type tenantKey struct{}
+
+// WithTenant marks the queries made with ctx as made on behalf of userID. An
+// empty userID clears the tenant, for work that spans users such as admin
+// tooling.
+func WithTenant(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, userID)
+}
+
+// TenantFromContext returns the user the queries made with ctx run for
+func TenantFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(tenantKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// ApplyTenant points the connection at the tenant in ctx: it switches to role
+// and sets app.user_id, which the row-level security policies read. Without a
+// tenant the connection goes back to the role it logged in as. Both are
+// session settings rather than SET LOCAL, since most reads don't open a
+// transaction, so this runs on every acquire and nothing carries over from
+// the previous user of the connection.
+func ApplyTenant(ctx context.Context, conn *pgx.Conn, role string) error {
+	userID, ok := TenantFromContext(ctx)
+	if !ok {
+		role = "none"
+	}
+
+	_, err := conn.Exec(ctx, "SELECT set_config('role', $1, false), set_config('app.user_id', $2, false)",
+		role, userID)
+	return err
+}
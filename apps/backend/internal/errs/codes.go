@@ -0,0 +1,58 @@
+package errs
+
+// Code is a machine-readable error identifier returned to clients
+type Code string
+
+// Generic codes derived from the HTTP status
+const (
+	CodeBadRequest            Code = "BAD_REQUEST"
+	CodeUnauthorized          Code = "UNAUTHORIZED"
+	CodeForbidden             Code = "FORBIDDEN"
+	CodeNotFound              Code = "NOT_FOUND"
+	CodeConflict              Code = "CONFLICT"
+	CodeRequestEntityTooLarge Code = "REQUEST_ENTITY_TOO_LARGE"
+	CodeTooManyRequests       Code = "TOO_MANY_REQUESTS"
+	CodeInternalServerError   Code = "INTERNAL_SERVER_ERROR"
+	CodeServiceUnavailable    Code = "SERVICE_UNAVAILABLE"
+)
+
+// Domain specific codes
+const (
+	CodeTodoNotFound          Code = "TODO_NOT_FOUND"
+	CodeAttachmentNotFound    Code = "ATTACHMENT_NOT_FOUND"
+	CodeActivityNotFound      Code = "ACTIVITY_NOT_FOUND"
+	CodeInvalidStatus         Code = "INVALID_STATUS"
+	CodeInvalidPriority       Code = "INVALID_PRIORITY"
+	CodeCircularReference     Code = "CIRCULAR_REFERENCE"
+	CodeMaxDepthExceeded      Code = "MAX_DEPTH_EXCEEDED"
+	CodeInboxNotDeletable     Code = "INBOX_NOT_DELETABLE"
+	CodeMemberNotFound        Code = "MEMBER_NOT_FOUND"
+	CodeInvalidTransition     Code = "INVALID_TRANSITION"
+	CodeInvalidRecurrence     Code = "INVALID_RECURRENCE"
+	CodeCategoryNotFound      Code = "CATEGORY_NOT_FOUND"
+	CodeSnapshotExpired       Code = "SNAPSHOT_EXPIRED"
+	CodeReminderNotFound      Code = "REMINDER_NOT_FOUND"
+	CodeInvalidField          Code = "INVALID_FIELD"
+	CodeTooManyTodos          Code = "TOO_MANY_TODOS"
+	CodeChecklistItemNotFound Code = "CHECKLIST_ITEM_NOT_FOUND"
+	CodeChecklistFull         Code = "CHECKLIST_FULL"
+	CodeInvalidImport         Code = "INVALID_IMPORT"
+	CodeMaxChildrenExceeded   Code = "MAX_CHILDREN_EXCEEDED"
+	CodeAccountDeleted        Code = "ACCOUNT_DELETED"
+	CodeRecoveryWindowPassed  Code = "RECOVERY_WINDOW_PASSED"
+	CodeParentInTrash         Code = "PARENT_IN_TRASH"
+	CodeTagNotFound           Code = "TAG_NOT_FOUND"
+	CodeDependencyNotFound    Code = "DEPENDENCY_NOT_FOUND"
+	CodeInvalidPosition       Code = "INVALID_POSITION"
+	CodeTemplateNotFound      Code = "TEMPLATE_NOT_FOUND"
+	CodeMissingVariables      Code = "MISSING_VARIABLES"
+	CodeInvalidUndoToken      Code = "INVALID_UNDO_TOKEN"
+	CodeUndoExpired           Code = "UNDO_EXPIRED"
+	CodeUndoSuperseded        Code = "UNDO_SUPERSEDED"
+	CodeWebhookNotFound       Code = "WEBHOOK_NOT_FOUND"
+	CodeTooManyWebhooks       Code = "TOO_MANY_WEBHOOKS"
+	CodeInvalidWebhookURL     Code = "INVALID_WEBHOOK_URL"
+	CodeInvalidIdempotencyKey Code = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused  Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInUse   Code = "IDEMPOTENCY_KEY_IN_USE"
+)
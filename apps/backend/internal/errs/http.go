@@ -7,6 +7,9 @@ import (
 type FieldError struct {
 	Field string `json:"field"`
 	Error string `json:"error"`
+	// Rule is the validation rule the field broke, e.g. "required" or "max",
+	// when the error came from struct validation
+	Rule string `json:"rule,omitempty"`
 }
 
 type ActionType string
@@ -22,7 +25,7 @@ type Action struct {
 }
 
 type HTTPError struct {
-	Code     string `json:"code"`
+	Code     Code   `json:"code"`
 	Message  string `json:"message"`
 	Status   int    `json:"status"`
 	Override bool   `json:"override"`
@@ -53,6 +56,45 @@ func (e *HTTPError) WithMessage(message string) *HTTPError {
 	}
 }
 
+// ErrorResponse is the JSON envelope every error response is serialized into
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code     Code          `json:"code"`
+	Message  string        `json:"message"`
+	Status   int           `json:"status"`
+	Override bool          `json:"override"`
+	Details  *ErrorDetails `json:"details,omitempty"`
+}
+
+type ErrorDetails struct {
+	// field level errors
+	Fields []FieldError `json:"fields,omitempty"`
+	// action to be taken
+	Action *Action `json:"action,omitempty"`
+}
+
+// Envelope wraps the error in the response body clients receive
+func (e *HTTPError) Envelope() ErrorResponse {
+	body := ErrorBody{
+		Code:     e.Code,
+		Message:  e.Message,
+		Status:   e.Status,
+		Override: e.Override,
+	}
+
+	if len(e.Errors) > 0 || e.Action != nil {
+		body.Details = &ErrorDetails{
+			Fields: e.Errors,
+			Action: e.Action,
+		}
+	}
+
+	return ErrorResponse{Error: body}
+}
+
 func MakeUpperCaseWithUnderscores(str string) string {
 	return strings.ToUpper(strings.ReplaceAll(str, " ", "_"))
 }
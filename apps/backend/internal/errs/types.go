@@ -6,7 +6,7 @@ import (
 
 func NewUnauthorizedError(message string, override bool) *HTTPError {
 	return &HTTPError{
-		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusUnauthorized)),
+		Code:     CodeUnauthorized,
 		Message:  message,
 		Status:   http.StatusUnauthorized,
 		Override: override,
@@ -15,15 +15,15 @@ func NewUnauthorizedError(message string, override bool) *HTTPError {
 
 func NewForbiddenError(message string, override bool) *HTTPError {
 	return &HTTPError{
-		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusForbidden)),
+		Code:     CodeForbidden,
 		Message:  message,
 		Status:   http.StatusForbidden,
 		Override: override,
 	}
 }
 
-func NewBadRequestError(message string, override bool, code *string, errors []FieldError, action *Action) *HTTPError {
-	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusBadRequest))
+func NewBadRequestError(message string, override bool, code *Code, errors []FieldError, action *Action) *HTTPError {
+	formattedCode := CodeBadRequest
 
 	if code != nil {
 		formattedCode = *code
@@ -39,8 +39,8 @@ func NewBadRequestError(message string, override bool, code *string, errors []Fi
 	}
 }
 
-func NewNotFoundError(message string, override bool, code *string) *HTTPError {
-	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusNotFound))
+func NewNotFoundError(message string, override bool, code *Code) *HTTPError {
+	formattedCode := CodeNotFound
 
 	if code != nil {
 		formattedCode = *code
@@ -54,9 +54,51 @@ func NewNotFoundError(message string, override bool, code *string) *HTTPError {
 	}
 }
 
+func NewConflictError(message string, override bool, code *Code) *HTTPError {
+	formattedCode := CodeConflict
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusConflict,
+		Override: override,
+	}
+}
+
+func NewRequestEntityTooLargeError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     CodeRequestEntityTooLarge,
+		Message:  message,
+		Status:   http.StatusRequestEntityTooLarge,
+		Override: override,
+	}
+}
+
+func NewTooManyRequestsError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     CodeTooManyRequests,
+		Message:  message,
+		Status:   http.StatusTooManyRequests,
+		Override: override,
+	}
+}
+
+func NewServiceUnavailableError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     CodeServiceUnavailable,
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: override,
+	}
+}
+
 func NewInternalServerError() *HTTPError {
 	return &HTTPError{
-		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusInternalServerError)),
+		Code:     CodeInternalServerError,
 		Message:  http.StatusText(http.StatusInternalServerError),
 		Status:   http.StatusInternalServerError,
 		Override: false,
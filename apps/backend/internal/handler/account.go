@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type AccountHandler struct {
+	Handler
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(s *server.Server, accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		Handler:        NewHandler(s),
+		accountService: accountService,
+	}
+}
+
+func (h *AccountHandler) DeleteAccount(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *account.DeleteAccountPayload) (*account.Deletion, error) {
+			userID := middleware.GetUserID(c)
+			return h.accountService.DeleteAccount(c, userID)
+		},
+		http.StatusAccepted,
+		&account.DeleteAccountPayload{},
+	)(c)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type ActivityHandler struct {
+	Handler
+	activityService *service.ActivityService
+}
+
+func NewActivityHandler(s *server.Server, activityService *service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		Handler:         NewHandler(s),
+		activityService: activityService,
+	}
+}
+
+func (h *ActivityHandler) GetActivities(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *activity.GetActivitiesQuery) (*model.PaginatedResponse[activity.Activity], error) {
+			userID := middleware.GetUserID(c)
+			return h.activityService.GetActivities(c, userID, query)
+		},
+		http.StatusOK,
+		&activity.GetActivitiesQuery{},
+	)(c)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type AdminHandler struct {
+	Handler
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(s *server.Server, adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		Handler:      NewHandler(s),
+		adminService: adminService,
+	}
+}
+
+func (h *AdminHandler) ImpersonateUser(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *admin.ImpersonateUserPayload) (*admin.ImpersonationToken, error) {
+			adminID := middleware.GetUserID(c)
+			return h.adminService.ImpersonateUser(c, adminID, payload.UserID)
+		},
+		http.StatusCreated,
+		&admin.ImpersonateUserPayload{},
+	)(c)
+}
+
+func (h *AdminHandler) ReassignUser(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *admin.ReassignUserPayload) (*admin.ReassignResult, error) {
+			adminID := middleware.GetUserID(c)
+			return h.adminService.ReassignUser(c, adminID, payload)
+		},
+		http.StatusOK,
+		&admin.ReassignUserPayload{},
+	)(c)
+}
+
+func (h *AdminHandler) RecoverAccount(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *admin.RecoverAccountPayload) (*account.Deletion, error) {
+			adminID := middleware.GetUserID(c)
+			return h.adminService.RecoverAccount(c, adminID, payload)
+		},
+		http.StatusOK,
+		&admin.RecoverAccountPayload{},
+	)(c)
+}
+
+func (h *AdminHandler) GetConfig(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *admin.GetConfigPayload) (*config.Config, error) {
+			adminID := middleware.GetUserID(c)
+			return h.adminService.GetEffectiveConfig(c, adminID), nil
+		},
+		http.StatusOK,
+		&admin.GetConfigPayload{},
+	)(c)
+}
+
+func (h *AdminHandler) GetAttachmentAccessLogs(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *admin.GetAttachmentAccessLogsQuery) (*model.PaginatedResponse[admin.AttachmentAccessLog], error) {
+			adminID := middleware.GetUserID(c)
+			return h.adminService.GetAttachmentAccessLogs(c, adminID, query)
+		},
+		http.StatusOK,
+		&admin.GetAttachmentAccessLogsQuery{},
+	)(c)
+}
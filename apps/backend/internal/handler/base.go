@@ -220,6 +220,30 @@ func Handle[Req validation.Validatable, Res any](
 	}
 }
 
+// BlobResponseHandler handles raw responses rendered inline, such as feeds
+type BlobResponseHandler struct {
+	status      int
+	contentType string
+}
+
+func (h BlobResponseHandler) Handle(c echo.Context, result interface{}) error {
+	data := result.([]byte)
+	return c.Blob(h.status, h.contentType, data)
+}
+
+func (h BlobResponseHandler) GetOperation() string {
+	return "handler_blob"
+}
+
+func (h BlobResponseHandler) AddAttributes(txn *newrelic.Transaction, result interface{}) {
+	if txn != nil {
+		txn.AddAttribute("blob.content_type", h.contentType)
+		if data, ok := result.([]byte); ok {
+			txn.AddAttribute("blob.size_bytes", len(data))
+		}
+	}
+}
+
 func HandleFile[Req validation.Validatable](
 	h Handler,
 	handler HandlerFunc[Req, []byte],
@@ -239,6 +263,24 @@ func HandleFile[Req validation.Validatable](
 	}
 }
 
+// HandleBlob wraps a handler that returns a raw body served inline with the given content type
+func HandleBlob[Req validation.Validatable](
+	h Handler,
+	handler HandlerFunc[Req, []byte],
+	status int,
+	req Req,
+	contentType string,
+) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			return handler(c, req)
+		}, BlobResponseHandler{
+			status:      status,
+			contentType: contentType,
+		})
+	}
+}
+
 // HandleNoContent wraps a handler with validation, error handling, logging, metrics, and tracing for endpoints that don't return content
 func HandleNoContent[Req validation.Validatable](
 	h Handler,
@@ -26,7 +26,7 @@ func NewCategoryHandler(s *server.Server, categoryService *service.CategoryServi
 func (h *CategoryHandler) CreateCategory(c echo.Context) error {
 	return Handle(
 		h.Handler,
-		func(c echo.Context, payload *category.CreateCategoryPayload) (*category.Category, error) {
+		func(c echo.Context, payload *category.CreateCategoryPayload) (*category.CategoryWithWarnings, error) {
 			userID := middleware.GetUserID(c)
 			return h.categoryService.CreateCategory(c, userID, payload)
 		},
@@ -52,7 +52,7 @@ func (h *CategoryHandler) GetCategories(c echo.Context) error {
 func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	return Handle(
 		h.Handler,
-		func(c echo.Context, payload *category.UpdateCategoryPayload) (*category.Category, error) {
+		func(c echo.Context, payload *category.UpdateCategoryPayload) (*category.CategoryWithWarnings, error) {
 			userID := middleware.GetUserID(c)
 			return h.categoryService.UpdateCategory(c, userID, payload.ID, payload)
 		},
@@ -72,3 +72,63 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 		&category.DeleteCategoryPayload{},
 	)(c)
 }
+
+func (h *CategoryHandler) ArchiveCategoryTodos(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *category.ArchiveCategoryTodosPayload) (*category.ArchiveCategoryTodosResponse, error) {
+			userID := middleware.GetUserID(c)
+			return h.categoryService.ArchiveCategoryTodos(c, userID, payload.ID, payload.OnlyCompleted)
+		},
+		http.StatusOK,
+		&category.ArchiveCategoryTodosPayload{},
+	)(c)
+}
+
+func (h *CategoryHandler) ReplaceTag(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *category.ReplaceTagPayload) (*category.ReplaceTagResponse, error) {
+			userID := middleware.GetUserID(c)
+			return h.categoryService.ReplaceTag(c, userID, payload)
+		},
+		http.StatusOK,
+		&category.ReplaceTagPayload{},
+	)(c)
+}
+
+func (h *CategoryHandler) RestoreGrouping(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *category.RestoreGroupingPayload) (*category.RestoreGroupingResponse, error) {
+			userID := middleware.GetUserID(c)
+			return h.categoryService.RestoreGrouping(c, userID, payload.CategoryID)
+		},
+		http.StatusCreated,
+		&category.RestoreGroupingPayload{},
+	)(c)
+}
+
+func (h *CategoryHandler) MergeCategories(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *category.MergeCategoriesPayload) (*category.MergeCategoriesResponse, error) {
+			userID := middleware.GetUserID(c)
+			return h.categoryService.MergeCategories(c, userID, payload.ID, payload.TargetID)
+		},
+		http.StatusOK,
+		&category.MergeCategoriesPayload{},
+	)(c)
+}
+
+func (h *CategoryHandler) GetProgressTrend(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *category.GetProgressTrendQuery) (*category.ProgressTrend, error) {
+			userID := middleware.GetUserID(c)
+			return h.categoryService.GetProgressTrend(c, userID, query)
+		},
+		http.StatusOK,
+		&category.GetProgressTrendQuery{},
+	)(c)
+}
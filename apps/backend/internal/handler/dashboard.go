@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type DashboardHandler struct {
+	Handler
+	dashboardService *service.DashboardService
+}
+
+func NewDashboardHandler(s *server.Server, dashboardService *service.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		Handler:          NewHandler(s),
+		dashboardService: dashboardService,
+	}
+}
+
+func (h *DashboardHandler) GetDashboard(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *dashboard.GetDashboardPayload) (*dashboard.Dashboard, error) {
+			userID := middleware.GetUserID(c)
+			return h.dashboardService.GetDashboard(c, userID)
+		},
+		http.StatusOK,
+		&dashboard.GetDashboardPayload{},
+	)(c)
+}
+
+func (h *DashboardHandler) GetWeeklyReview(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *dashboard.GetWeeklyReviewQuery) (*dashboard.WeeklyReview, error) {
+			userID := middleware.GetUserID(c)
+			return h.dashboardService.GetWeeklyReview(c, userID, query)
+		},
+		http.StatusOK,
+		&dashboard.GetWeeklyReviewQuery{},
+	)(c)
+}
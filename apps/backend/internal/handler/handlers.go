@@ -6,19 +6,43 @@ import (
 )
 
 type Handlers struct {
-	Health   *HealthHandler
-	OpenAPI  *OpenAPIHandler
-	Todo     *TodoHandler
-	Comment  *CommentHandler
-	Category *CategoryHandler
+	Health       *HealthHandler
+	OpenAPI      *OpenAPIHandler
+	Todo         *TodoHandler
+	Comment      *CommentHandler
+	Category     *CategoryHandler
+	Admin        *AdminHandler
+	Preference   *PreferenceHandler
+	Activity     *ActivityHandler
+	Organization *OrganizationHandler
+	Notification *NotificationHandler
+	Streak       *StreakHandler
+	Dashboard    *DashboardHandler
+	Account      *AccountHandler
+	Tag          *TagHandler
+	Template     *TemplateHandler
+	Realtime     *RealtimeHandler
+	Webhook      *WebhookHandler
 }
 
 func NewHandlers(s *server.Server, services *service.Services) *Handlers {
 	return &Handlers{
-		Health:   NewHealthHandler(s),
-		OpenAPI:  NewOpenAPIHandler(s),
-		Todo:     NewTodoHandler(s, services.Todo),
-		Category: NewCategoryHandler(s, services.Category),
-		Comment:  NewCommentHandler(s, services.Comment),
+		Health:       NewHealthHandler(s, services.AWS.S3),
+		OpenAPI:      NewOpenAPIHandler(s),
+		Todo:         NewTodoHandler(s, services.Todo),
+		Category:     NewCategoryHandler(s, services.Category),
+		Comment:      NewCommentHandler(s, services.Comment),
+		Admin:        NewAdminHandler(s, services.Admin),
+		Preference:   NewPreferenceHandler(s, services.Preference),
+		Activity:     NewActivityHandler(s, services.Activity),
+		Organization: NewOrganizationHandler(s, services.Organization),
+		Notification: NewNotificationHandler(s, services.Notification),
+		Streak:       NewStreakHandler(s, services.Streak),
+		Dashboard:    NewDashboardHandler(s, services.Dashboard),
+		Account:      NewAccountHandler(s, services.Account),
+		Tag:          NewTagHandler(s, services.Tag),
+		Template:     NewTemplateHandler(s, services.Template),
+		Realtime:     NewRealtimeHandler(s, services.Realtime),
+		Webhook:      NewWebhookHandler(s, services.Webhook),
 	}
 }
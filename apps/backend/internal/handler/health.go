@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/sriniously/tasker/internal/middleware"
@@ -12,16 +13,34 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// HealthCheckStorage is the name that enables the attachment storage check
+// in HealthChecksConfig.Checks
+const HealthCheckStorage = "storage"
+
+// StorageChecker reports whether the attachment storage backend is reachable
+type StorageChecker interface {
+	CheckStorage(ctx context.Context) error
+}
+
 type HealthHandler struct {
 	Handler
+	storage StorageChecker
 }
 
-func NewHealthHandler(s *server.Server) *HealthHandler {
+func NewHealthHandler(s *server.Server, storage StorageChecker) *HealthHandler {
 	return &HealthHandler{
 		Handler: NewHandler(s),
+		storage: storage,
 	}
 }
 
+// storageCheckEnabled reports whether the storage check is configured
+func (h *HealthHandler) storageCheckEnabled() bool {
+	observability := h.server.Config.Observability
+	return h.storage != nil && observability != nil &&
+		slices.Contains(observability.HealthChecks.Checks, HealthCheckStorage)
+}
+
 func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	start := time.Now()
 	logger := middleware.GetLogger(c).With().
@@ -38,6 +57,20 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	checks := response["checks"].(map[string]interface{})
 	isHealthy := true
 
+	// Storage is checked alongside the other checks under its own timeout, so
+	// a slow or misconfigured bucket can't hold them up
+	var storageResult chan error
+	var storageStart time.Time
+	if h.storageCheckEnabled() {
+		storageResult = make(chan error, 1)
+		storageStart = time.Now()
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), h.server.Config.Observability.HealthChecks.Timeout)
+			defer cancel()
+			storageResult <- h.storage.CheckStorage(ctx)
+		}()
+	}
+
 	// Check database connectivity
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -48,23 +81,23 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 			"status":        "unhealthy",
 			"response_time": time.Since(dbStart).String(),
 			"error":         err.Error(),
+			"pool":          h.server.DB.PoolStats(),
 		}
 		isHealthy = false
 		logger.Error().Err(err).Dur("response_time", time.Since(dbStart)).Msg("database health check failed")
-		if h.server.LoggerService != nil && h.server.LoggerService.GetApplication() != nil {
-			h.server.LoggerService.GetApplication().RecordCustomEvent(
-				"HealthCheckError", map[string]interface{}{
-					"check_type":       "database",
-					"operation":        "health_check",
-					"error_type":       "database_unhealthy",
-					"response_time_ms": time.Since(dbStart).Milliseconds(),
-					"error_message":    err.Error(),
-				})
-		}
+		h.server.LoggerService.RecordCustomEvent(
+			"HealthCheckError", map[string]interface{}{
+				"check_type":       "database",
+				"operation":        "health_check",
+				"error_type":       "database_unhealthy",
+				"response_time_ms": time.Since(dbStart).Milliseconds(),
+				"error_message":    err.Error(),
+			})
 	} else {
 		checks["database"] = map[string]interface{}{
 			"status":        "healthy",
 			"response_time": time.Since(dbStart).String(),
+			"pool":          h.server.DB.PoolStats(),
 		}
 		logger.Info().Dur("response_time", time.Since(dbStart)).Msg("database health check passed")
 	}
@@ -84,16 +117,14 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 				"error":         err.Error(),
 			}
 			logger.Error().Err(err).Dur("response_time", time.Since(redisStart)).Msg("redis health check failed")
-			if h.server.LoggerService != nil && h.server.LoggerService.GetApplication() != nil {
-				h.server.LoggerService.GetApplication().RecordCustomEvent(
-					"HealthCheckError", map[string]interface{}{
-						"check_type":       "redis",
-						"operation":        "health_check",
-						"error_type":       "redis_unhealthy",
-						"response_time_ms": time.Since(redisStart).Milliseconds(),
-						"error_message":    err.Error(),
-					})
-			}
+			h.server.LoggerService.RecordCustomEvent(
+				"HealthCheckError", map[string]interface{}{
+					"check_type":       "redis",
+					"operation":        "health_check",
+					"error_type":       "redis_unhealthy",
+					"response_time_ms": time.Since(redisStart).Milliseconds(),
+					"error_message":    err.Error(),
+				})
 		} else {
 			checks["redis"] = map[string]interface{}{
 				"status":        "healthy",
@@ -103,21 +134,46 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 		}
 	}
 
+	// Check attachment storage
+	if storageResult != nil {
+		if err := <-storageResult; err != nil {
+			checks[HealthCheckStorage] = map[string]interface{}{
+				"status":        "unhealthy",
+				"response_time": time.Since(storageStart).String(),
+				"error":         err.Error(),
+			}
+			isHealthy = false
+			logger.Error().Err(err).Dur("response_time", time.Since(storageStart)).Msg("storage health check failed")
+			h.server.LoggerService.RecordCustomEvent(
+				"HealthCheckError", map[string]interface{}{
+					"check_type":       "storage",
+					"operation":        "health_check",
+					"error_type":       "storage_unhealthy",
+					"response_time_ms": time.Since(storageStart).Milliseconds(),
+					"error_message":    err.Error(),
+				})
+		} else {
+			checks[HealthCheckStorage] = map[string]interface{}{
+				"status":        "healthy",
+				"response_time": time.Since(storageStart).String(),
+			}
+			logger.Info().Dur("response_time", time.Since(storageStart)).Msg("storage health check passed")
+		}
+	}
+
 	// Set overall status
 	if !isHealthy {
 		response["status"] = "unhealthy"
 		logger.Warn().
 			Dur("total_duration", time.Since(start)).
 			Msg("health check failed")
-		if h.server.LoggerService != nil && h.server.LoggerService.GetApplication() != nil {
-			h.server.LoggerService.GetApplication().RecordCustomEvent(
-				"HealthCheckError", map[string]interface{}{
-					"check_type":        "overall",
-					"operation":         "health_check",
-					"error_type":        "overall_unhealthy",
-					"total_duration_ms": time.Since(start).Milliseconds(),
-				})
-		}
+		h.server.LoggerService.RecordCustomEvent(
+			"HealthCheckError", map[string]interface{}{
+				"check_type":        "overall",
+				"operation":         "health_check",
+				"error_type":        "overall_unhealthy",
+				"total_duration_ms": time.Since(start).Milliseconds(),
+			})
 		return c.JSON(http.StatusServiceUnavailable, response)
 	}
 
@@ -128,15 +184,13 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	err := c.JSON(http.StatusOK, response)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to write JSON response")
-		if h.server.LoggerService != nil && h.server.LoggerService.GetApplication() != nil {
-			h.server.LoggerService.GetApplication().RecordCustomEvent(
-				"HealthCheckError", map[string]interface{}{
-					"check_type":    "response",
-					"operation":     "health_check",
-					"error_type":    "json_response_error",
-					"error_message": err.Error(),
-				})
-		}
+		h.server.LoggerService.RecordCustomEvent(
+			"HealthCheckError", map[string]interface{}{
+				"check_type":    "response",
+				"operation":     "health_check",
+				"error_type":    "json_response_error",
+				"error_message": err.Error(),
+			})
 		return fmt.Errorf("failed to write JSON response: %w", err)
 	}
 
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/notification"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type NotificationHandler struct {
+	Handler
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(s *server.Server, notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		Handler:             NewHandler(s),
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) PreviewNotification(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *notification.PreviewPayload) (*notification.Preview, error) {
+			userID := middleware.GetUserID(c)
+			return h.notificationService.Preview(c, userID, payload)
+		},
+		http.StatusOK,
+		&notification.PreviewPayload{},
+	)(c)
+}
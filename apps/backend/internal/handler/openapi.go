@@ -1,25 +1,48 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/openapi"
 	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/validation"
 
 	"github.com/labstack/echo/v4"
 )
 
 type OpenAPIHandler struct {
 	Handler
+	publicRoutes []string
+	operations   map[string]Operation
+
+	specOnce sync.Once
+	spec     []byte
+	specErr  error
 }
 
 func NewOpenAPIHandler(s *server.Server) *OpenAPIHandler {
 	return &OpenAPIHandler{
-		Handler: NewHandler(s),
+		Handler:    NewHandler(s),
+		operations: map[string]Operation{},
 	}
 }
 
+// SetPublicRoutes lists the routes, as "METHOD /path" relative to the API
+// prefix, that are documented as needing no session token
+func (h *OpenAPIHandler) SetPublicRoutes(routes []string) {
+	h.publicRoutes = routes
+}
+
 func (h *OpenAPIHandler) ServeOpenAPIUI(c echo.Context) error {
 	templateBytes, err := os.ReadFile("static/openapi.html")
 	c.Response().Header().Set("Cache-Control", "no-cache")
@@ -36,3 +59,314 @@ func (h *OpenAPIHandler) ServeOpenAPIUI(c echo.Context) error {
 
 	return nil
 }
+
+// ServeSpec serves the OpenAPI document for the routes registered beside it
+// and described through Tag. It's generated on the first request, once
+// they're all in place, and kept for the life of the server.
+func (h *OpenAPIHandler) ServeSpec(c echo.Context) error {
+	h.specOnce.Do(func() {
+		prefix := strings.TrimSuffix(c.Path(), "/openapi.json")
+		h.spec, h.specErr = json.Marshal(GenerateOpenAPI(c.Echo().Routes(), prefix, h.publicRoutes, h.operations))
+	})
+	if h.specErr != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", h.specErr)
+	}
+
+	return c.JSONBlob(http.StatusOK, h.spec)
+}
+
+// documentedResponse is what a route answers with, as far as the API
+// document is concerned. binary marks a downloaded file.
+type documentedResponse struct {
+	status      int
+	contentType string
+	body        reflect.Type
+	binary      bool
+}
+
+// Operation is what the API document says about a route: the handler method
+// serving it, which names the operation, the payload it binds and the
+// response it answers with. Routes are described where they're registered,
+// with the Describe function matching the Handle wrapper their handler uses.
+type Operation struct {
+	name     string
+	tag      string
+	request  reflect.Type
+	response documentedResponse
+}
+
+// Describe describes a route served through Handle or HandleVersioned
+func Describe[Req validation.Validatable, Res any](name string, status int) Operation {
+	return Operation{
+		name:     name,
+		request:  reflect.TypeFor[Req](),
+		response: documentedResponse{status: status, contentType: echo.MIMEApplicationJSON, body: reflect.TypeFor[Res]()},
+	}
+}
+
+// DescribeNoContent describes a route served through HandleNoContent
+func DescribeNoContent[Req validation.Validatable](name string, status int) Operation {
+	return Operation{
+		name:     name,
+		request:  reflect.TypeFor[Req](),
+		response: documentedResponse{status: status},
+	}
+}
+
+// DescribeFile describes a route served through HandleFile
+func DescribeFile[Req validation.Validatable](name string, status int, contentType string) Operation {
+	return Operation{
+		name:     name,
+		request:  reflect.TypeFor[Req](),
+		response: documentedResponse{status: status, contentType: contentType, binary: true},
+	}
+}
+
+// DescribeBlob describes a route served through HandleBlob
+func DescribeBlob[Req validation.Validatable](name string, status int, contentType string) Operation {
+	return Operation{
+		name:     name,
+		request:  reflect.TypeFor[Req](),
+		response: documentedResponse{status: status, contentType: contentType},
+	}
+}
+
+// DescribeRaw describes a route whose handler reads the request and writes
+// its response itself
+func DescribeRaw(name string, status int, contentType string) Operation {
+	return Operation{
+		name:     name,
+		response: documentedResponse{status: status, contentType: contentType},
+	}
+}
+
+// RouteDocs documents routes under one tag of the API document
+type RouteDocs struct {
+	openapi *OpenAPIHandler
+	tag     string
+}
+
+// Tag returns the documenter for the routes of one part of the API, named
+// after the handler serving them
+func (h *OpenAPIHandler) Tag(tag string) *RouteDocs {
+	return &RouteDocs{openapi: h, tag: tag}
+}
+
+// Add documents the route just registered. Routes must all be added before
+// the document is first served.
+func (d *RouteDocs) Add(route *echo.Route, operation Operation) {
+	operation.tag = d.tag
+	d.openapi.operations[route.Method+" "+route.Path] = operation
+}
+
+const bearerAuth = "bearerAuth"
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// GenerateOpenAPI documents the routes under prefix with the operations
+// they were described with, keyed by method and path. Routes in public are
+// documented as taking no session token.
+func GenerateOpenAPI(routes []*echo.Route, prefix string, public []string,
+	operations map[string]Operation,
+) *openapi.Document {
+	schemas := openapi.NewSchemas()
+	errorSchema := schemas.Of(reflect.TypeFor[errs.HTTPError]())
+
+	doc := &openapi.Document{
+		OpenAPI:  openapi.Version,
+		Info:     openapi.Info{Title: "Tasker API", Version: "1.0.0"},
+		Servers:  []openapi.Server{{URL: prefix}},
+		Paths:    map[string]*openapi.PathItem{},
+		Security: []openapi.SecurityRequirement{{bearerAuth: {}}},
+	}
+
+	// Sorted, shortest paths first, so operation IDs go to the most direct
+	// route claiming them and don't change between runs
+	routes = slices.Clone(routes)
+	slices.SortFunc(routes, func(a, b *echo.Route) int {
+		if depth := strings.Count(a.Path, "/") - strings.Count(b.Path, "/"); depth != 0 {
+			return depth
+		}
+		if a.Path != b.Path {
+			return strings.Compare(a.Path, b.Path)
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	operationIDs := map[string]bool{}
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, prefix)
+		if !ok || !isDocumentedMethod(route.Method) {
+			continue
+		}
+
+		described, ok := operations[route.Method+" "+route.Path]
+		if !ok {
+			continue
+		}
+
+		operation := describeOperation(schemas, route, described)
+		operation.Responses["default"] = &openapi.Response{
+			Description: "Error",
+			Content:     map[string]*openapi.MediaType{echo.MIMEApplicationJSON: {Schema: errorSchema}},
+		}
+
+		operation.OperationID = uniqueOperationID(operationIDs, path, described.name)
+		if slices.Contains(public, route.Method+" "+path) {
+			operation.Security = &[]openapi.SecurityRequirement{}
+		}
+
+		documentedPath := pathParam.ReplaceAllString(path, "{$1}")
+		if doc.Paths[documentedPath] == nil {
+			doc.Paths[documentedPath] = &openapi.PathItem{}
+		}
+		(*doc.Paths[documentedPath])[strings.ToLower(route.Method)] = operation
+	}
+
+	doc.Components = openapi.Components{
+		Schemas: schemas.Components(),
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+
+	return doc
+}
+
+func isDocumentedMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// describeOperation builds the documented operation for a route from what
+// it was described with
+func describeOperation(schemas *openapi.Schemas, route *echo.Route, described Operation) *openapi.Operation {
+	operation := &openapi.Operation{
+		Summary:   summarize(described.name),
+		Tags:      []string{described.tag},
+		Responses: map[string]*openapi.Response{},
+	}
+
+	var request openapi.Request
+	if described.request != nil {
+		request = schemas.Request(described.request)
+	}
+
+	// Path parameters come from the route, since some, like an organization
+	// ID, are read by middleware rather than the payload
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		parameter := openapi.Parameter{Name: match[1], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+		for _, bound := range request.Parameters {
+			if bound.In == "path" && bound.Name == match[1] {
+				parameter.Schema = bound.Schema
+			}
+		}
+		operation.Parameters = append(operation.Parameters, parameter)
+	}
+	for _, parameter := range request.Parameters {
+		if parameter.In != "path" {
+			operation.Parameters = append(operation.Parameters, parameter)
+		}
+	}
+
+	if route.Method != http.MethodGet {
+		switch {
+		case request.Form != nil:
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{echo.MIMEMultipartForm: {Schema: request.Form}},
+			}
+		case request.Body != nil:
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{echo.MIMEApplicationJSON: {Schema: request.Body}},
+			}
+		}
+	}
+
+	response := described.response
+	documented := &openapi.Response{Description: http.StatusText(response.status)}
+	switch {
+	case response.body != nil:
+		documented.Content = map[string]*openapi.MediaType{response.contentType: {Schema: schemas.Of(response.body)}}
+	case response.binary:
+		documented.Content = map[string]*openapi.MediaType{
+			response.contentType: {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+		}
+	case response.contentType != "":
+		documented.Content = map[string]*openapi.MediaType{response.contentType: {Schema: &openapi.Schema{Type: "string"}}}
+	}
+	operation.Responses[fmt.Sprint(response.status)] = documented
+
+	return operation
+}
+
+// uniqueOperationID names the operation after its handler method, e.g.
+// createTodo. A method serving several routes is told apart by the first
+// segment of the later routes' paths, e.g. organizationsCreateTodo.
+func uniqueOperationID(taken map[string]bool, path string, method string) string {
+	base := lowerFirst(method)
+	if base == "" {
+		base = "operation"
+	}
+
+	id := base
+	if taken[id] {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		id = lowerFirst(toCamel(segment)) + method
+	}
+	for i := 2; taken[id]; i++ {
+		id = fmt.Sprintf("%s%d", base, i)
+	}
+
+	taken[id] = true
+	return id
+}
+
+// summarize turns a method name into a sentence, e.g. CreateTodo into
+// "Create todo"
+func summarize(method string) string {
+	var words []string
+	start := 0
+	for i, r := range method {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(method[i-1])) {
+			words = append(words, strings.ToLower(method[start:i]))
+			start = i
+		}
+	}
+	words = append(words, strings.ToLower(method[start:]))
+
+	summary := strings.Join(words, " ")
+	if summary == "" {
+		return ""
+	}
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+func toCamel(segment string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range segment {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
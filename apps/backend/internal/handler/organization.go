@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type OrganizationHandler struct {
+	Handler
+	orgService *service.OrganizationService
+}
+
+func NewOrganizationHandler(s *server.Server, orgService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		Handler:    NewHandler(s),
+		orgService: orgService,
+	}
+}
+
+func (h *OrganizationHandler) CreateOrganization(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *organization.CreateOrganizationPayload) (*organization.OrganizationWithRole, error) {
+			userID := middleware.GetUserID(c)
+			return h.orgService.CreateOrganization(c, userID, payload)
+		},
+		http.StatusCreated,
+		&organization.CreateOrganizationPayload{},
+	)(c)
+}
+
+func (h *OrganizationHandler) GetOrganizations(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *organization.GetOrganizationsPayload) ([]organization.OrganizationWithRole, error) {
+			userID := middleware.GetUserID(c)
+			return h.orgService.GetOrganizations(c, userID)
+		},
+		http.StatusOK,
+		&organization.GetOrganizationsPayload{},
+	)(c)
+}
+
+func (h *OrganizationHandler) GetMembers(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *organization.GetMembersPayload) ([]organization.Membership, error) {
+			return h.orgService.GetMembers(c, middleware.GetOrgMembership(c))
+		},
+		http.StatusOK,
+		&organization.GetMembersPayload{},
+	)(c)
+}
+
+func (h *OrganizationHandler) AddMember(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *organization.AddMemberPayload) (*organization.Membership, error) {
+			return h.orgService.AddMember(c, middleware.GetOrgMembership(c), payload)
+		},
+		http.StatusOK,
+		&organization.AddMemberPayload{},
+	)(c)
+}
+
+func (h *OrganizationHandler) RemoveMember(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *organization.RemoveMemberPayload) error {
+			return h.orgService.RemoveMember(c, middleware.GetOrgMembership(c), payload.UserID)
+		},
+		http.StatusNoContent,
+		&organization.RemoveMemberPayload{},
+	)(c)
+}
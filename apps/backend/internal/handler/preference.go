@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type PreferenceHandler struct {
+	Handler
+	preferenceService *service.PreferenceService
+}
+
+func NewPreferenceHandler(s *server.Server, preferenceService *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{
+		Handler:           NewHandler(s),
+		preferenceService: preferenceService,
+	}
+}
+
+func (h *PreferenceHandler) GetPreferences(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *preference.GetPreferencesPayload) (*preference.Preferences, error) {
+			userID := middleware.GetUserID(c)
+			return h.preferenceService.GetPreferences(c, userID)
+		},
+		http.StatusOK,
+		&preference.GetPreferencesPayload{},
+	)(c)
+}
+
+func (h *PreferenceHandler) UpdatePreferences(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *preference.UpdatePreferencesPayload) (*preference.Preferences, error) {
+			userID := middleware.GetUserID(c)
+			return h.preferenceService.UpdatePreferences(c, userID, payload)
+		},
+		http.StatusOK,
+		&preference.UpdatePreferencesPayload{},
+	)(c)
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// heartbeatInterval keeps idle connections from being closed by proxies
+	heartbeatInterval = 30 * time.Second
+	// eventWriteTimeout is how long a client may take to accept one event
+	// before the connection is dropped
+	eventWriteTimeout = 10 * time.Second
+	eventHeartbeat    = "heartbeat"
+	// eventReset tells a resuming client that events it missed are no
+	// longer kept, so it should refetch its state
+	eventReset        = "reset"
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+type RealtimeHandler struct {
+	Handler
+	realtimeService *service.RealtimeService
+}
+
+func NewRealtimeHandler(s *server.Server, realtimeService *service.RealtimeService) *RealtimeHandler {
+	return &RealtimeHandler{
+		Handler:         NewHandler(s),
+		realtimeService: realtimeService,
+	}
+}
+
+// WebSocket upgrades the request and streams the user's todo, comment and
+// category change events as JSON messages until either side hangs up.
+// Messages from the client are read and ignored.
+func (h *RealtimeHandler) WebSocket(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	logger := middleware.GetLogger(c)
+
+	// Listen before upgrading so a failure is still an ordinary error
+	// response, and nothing broadcast during the handshake is missed
+	events, stop, err := h.realtimeService.Listen(c, userID)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			// The server's read and write timeouts still apply to the
+			// hijacked connection; writes get a deadline of their own below
+			if err := conn.SetDeadline(time.Time{}); err != nil {
+				return
+			}
+
+			logger.Info().Str("user_id", userID).Msg("websocket connected")
+			h.stream(conn, events)
+			logger.Info().Str("user_id", userID).Msg("websocket disconnected")
+		},
+	}.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
+
+// Events streams the same change events as WebSocket as Server-Sent Events,
+// for clients that can't use WebSockets. A client reconnecting with
+// Last-Event-ID first gets the events it missed, or a reset event when they
+// are no longer kept.
+func (h *RealtimeHandler) Events(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+
+	// Listen before replaying so nothing falls in the gap between the two;
+	// events in both are sent once
+	events, stop, err := h.realtimeService.Listen(c, userID)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	var missed []changefeed.Event
+	complete := true
+	if lastEventID := c.Request().Header.Get(lastEventIDHeader); lastEventID != "" {
+		missed, complete, err = h.realtimeService.Replay(c, userID, lastEventID)
+		if err != nil {
+			return err
+		}
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// The server's write timeout would cut the stream off, so each write
+	// gets a deadline of its own
+	controller := http.NewResponseController(res)
+	send := func(frame []byte) error {
+		err := controller.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if _, err := res.Write(frame); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	sent := make(map[string]struct{}, len(missed))
+	if !complete {
+		missed = []changefeed.Event{{Type: eventReset, OccurredAt: time.Now()}}
+	}
+	for _, event := range missed {
+		if err := send(sseFrame(event)); err != nil {
+			return nil
+		}
+		sent[event.ID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var frame []byte
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if _, ok := sent[event.ID]; ok {
+				continue
+			}
+			frame = sseFrame(event)
+		case <-heartbeat.C:
+			frame = []byte(": " + eventHeartbeat + "\n\n")
+		}
+
+		if err := send(frame); err != nil {
+			return nil
+		}
+	}
+}
+
+// sseFrame encodes an event as one Server-Sent Events message. Events carry
+// their type in the data, so clients read them all from onmessage.
+func sseFrame(event changefeed.Event) []byte {
+	data, err := json.Marshal(event)
+	if err != nil {
+		data = []byte("{}")
+	}
+
+	var frame bytes.Buffer
+	if event.ID != "" {
+		frame.WriteString("id: " + event.ID + "\n")
+	}
+	frame.WriteString("data: ")
+	frame.Write(data)
+	frame.WriteString("\n\n")
+	return frame.Bytes()
+}
+
+func (h *RealtimeHandler) stream(conn *websocket.Conn, events <-chan changefeed.Event) {
+	// Reading is how a closed connection is noticed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(conn, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var event changefeed.Event
+		select {
+		case <-closed:
+			return
+		case received, ok := <-events:
+			if !ok {
+				return
+			}
+			event = received
+		case now := <-heartbeat.C:
+			event = changefeed.Event{Type: eventHeartbeat, OccurredAt: now}
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout)); err != nil {
+			return
+		}
+		if err := websocket.JSON.Send(conn, event); err != nil {
+			return
+		}
+	}
+}
+
+// checkOrigin accepts browsers on the allowed CORS origins, and clients that
+// send no Origin at all
+func (h *RealtimeHandler) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return nil
+	}
+
+	allowed := h.server.Config.Server.CORSAllowedOrigins
+	if !slices.Contains(allowed, "*") && !slices.Contains(allowed, origin.Scheme+"://"+origin.Host) {
+		return fmt.Errorf("origin %s is not allowed", origin.String())
+	}
+
+	config.Origin = origin
+	return nil
+}
@@ -0,0 +1,155 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func newRealtimeTestServer(t *testing.T) (*httptest.Server, *changefeed.MemoryBroadcaster) {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	s := &server.Server{
+		Logger: &logger,
+		Config: &config.Config{
+			Server: config.ServerConfig{CORSAllowedOrigins: []string{"https://app.example.com"}},
+		},
+	}
+	events := changefeed.NewMemoryBroadcaster()
+	h := handler.NewRealtimeHandler(s, service.NewRealtimeService(s, events))
+
+	asUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(middleware.UserIDKey, "user-1")
+			return next(c)
+		}
+	}
+
+	e := echo.New()
+	e.GET("/ws", h.WebSocket, asUser)
+	e.GET("/events", h.Events, asUser)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	return srv, events
+}
+
+func TestRealtimeHandler_WebSocket(t *testing.T) {
+	srv, events := newRealtimeTestServer(t)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	t.Run("streams the user's events", func(t *testing.T) {
+		conn, err := websocket.Dial(url, "", "https://app.example.com")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		event := changefeed.Event{Type: changefeed.EventCommentAdded, EntityID: "comment-1"}
+		require.NoError(t, events.Broadcast(context.Background(), "user-1", event))
+		require.NoError(t, events.Broadcast(context.Background(), "user-2", changefeed.Event{
+			Type: changefeed.EventCommentAdded, EntityID: "comment-2",
+		}))
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var received changefeed.Event
+		require.NoError(t, websocket.JSON.Receive(conn, &received))
+		assert.Equal(t, event.Type, received.Type)
+		assert.Equal(t, event.EntityID, received.EntityID)
+	})
+
+	t.Run("refuses other origins", func(t *testing.T) {
+		_, err := websocket.Dial(url, "", "https://evil.example.com")
+		assert.Error(t, err)
+	})
+}
+
+func TestRealtimeHandler_Events(t *testing.T) {
+	srv, events := newRealtimeTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"comment-1", "comment-2"} {
+		require.NoError(t, events.Broadcast(ctx, "user-1", changefeed.Event{
+			Type: changefeed.EventCommentAdded, EntityID: id,
+		}))
+	}
+	kept, _, err := events.Replay(ctx, "user-1", "")
+	require.NoError(t, err)
+
+	open := func(t *testing.T, lastEventID string) *bufio.Reader {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+
+		return bufio.NewReader(res.Body)
+	}
+
+	next := func(t *testing.T, r *bufio.Reader) (string, changefeed.Event) {
+		t.Helper()
+
+		var id string
+		var event changefeed.Event
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+
+			switch {
+			case line == "":
+				return id, event
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			}
+		}
+	}
+
+	t.Run("replays what was missed, then streams live", func(t *testing.T) {
+		r := open(t, kept[0].ID)
+
+		id, event := next(t, r)
+		assert.Equal(t, kept[1].ID, id)
+		assert.Equal(t, "comment-2", event.EntityID)
+
+		require.NoError(t, events.Broadcast(ctx, "user-1", changefeed.Event{
+			Type: changefeed.EventCommentDeleted, EntityID: "comment-1",
+		}))
+
+		_, event = next(t, r)
+		assert.Equal(t, changefeed.EventCommentDeleted, event.Type)
+	})
+
+	t.Run("resets when the missed events are gone", func(t *testing.T) {
+		r := open(t, "expired")
+
+		id, event := next(t, r)
+		assert.Empty(t, id)
+		assert.Equal(t, "reset", event.Type)
+	})
+}
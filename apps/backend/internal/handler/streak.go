@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type StreakHandler struct {
+	Handler
+	streakService *service.StreakService
+}
+
+func NewStreakHandler(s *server.Server, streakService *service.StreakService) *StreakHandler {
+	return &StreakHandler{
+		Handler:       NewHandler(s),
+		streakService: streakService,
+	}
+}
+
+func (h *StreakHandler) GetStreak(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *streak.GetStreakPayload) (*streak.Streak, error) {
+			userID := middleware.GetUserID(c)
+			return h.streakService.GetStreak(c, userID)
+		},
+		http.StatusOK,
+		&streak.GetStreakPayload{},
+	)(c)
+}
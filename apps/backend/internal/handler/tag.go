@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/tag"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type TagHandler struct {
+	Handler
+	tagService *service.TagService
+}
+
+func NewTagHandler(s *server.Server, tagService *service.TagService) *TagHandler {
+	return &TagHandler{
+		Handler:    NewHandler(s),
+		tagService: tagService,
+	}
+}
+
+func (h *TagHandler) GetTags(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *tag.GetTagsQuery) ([]tag.Tag, error) {
+			userID := middleware.GetUserID(c)
+			return h.tagService.GetTags(c, userID, query)
+		},
+		http.StatusOK,
+		&tag.GetTagsQuery{},
+	)(c)
+}
+
+func (h *TagHandler) RenameTag(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *tag.RenameTagPayload) (*tag.RenameTagResponse, error) {
+			userID := middleware.GetUserID(c)
+			return h.tagService.RenameTag(c, userID, payload.ID, payload.Name)
+		},
+		http.StatusOK,
+		&tag.RenameTagPayload{},
+	)(c)
+}
+
+func (h *TagHandler) DeleteTag(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *tag.DeleteTagPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.tagService.DeleteTag(c, userID, payload.ID)
+		},
+		http.StatusNoContent,
+		&tag.DeleteTagPayload{},
+	)(c)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/sriniously/tasker/internal/service"
+)
+
+type TemplateHandler struct {
+	Handler
+	templateService *service.TemplateService
+}
+
+func NewTemplateHandler(s *server.Server, templateService *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		Handler:         NewHandler(s),
+		templateService: templateService,
+	}
+}
+
+func (h *TemplateHandler) CreateTemplate(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *template.CreateTemplatePayload) (*template.Template, error) {
+			userID := middleware.GetUserID(c)
+			return h.templateService.CreateTemplate(c, userID, payload)
+		},
+		http.StatusCreated,
+		&template.CreateTemplatePayload{},
+	)(c)
+}
+
+func (h *TemplateHandler) GetTemplates(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *template.GetTemplatesQuery) ([]template.Template, error) {
+			userID := middleware.GetUserID(c)
+			return h.templateService.GetTemplates(c, userID, query)
+		},
+		http.StatusOK,
+		&template.GetTemplatesQuery{},
+	)(c)
+}
+
+func (h *TemplateHandler) GetTemplateByID(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *template.GetTemplatePayload) (*template.Template, error) {
+			userID := middleware.GetUserID(c)
+			return h.templateService.GetTemplateByID(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&template.GetTemplatePayload{},
+	)(c)
+}
+
+func (h *TemplateHandler) DeleteTemplate(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *template.DeleteTemplatePayload) error {
+			userID := middleware.GetUserID(c)
+			return h.templateService.DeleteTemplate(c, userID, payload.ID)
+		},
+		http.StatusNoContent,
+		&template.DeleteTemplatePayload{},
+	)(c)
+}
+
+func (h *TemplateHandler) InstantiateTemplate(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *template.InstantiateTemplatePayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.templateService.InstantiateTemplate(c, userID, payload)
+		},
+		http.StatusCreated,
+		&template.InstantiateTemplatePayload{},
+	)(c)
+}
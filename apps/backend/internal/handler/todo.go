@@ -5,8 +5,10 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/feed"
 	"github.com/sriniously/tasker/internal/middleware"
 	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
 	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/server"
 	"github.com/sriniously/tasker/internal/service"
@@ -27,8 +29,11 @@ func NewTodoHandler(s *server.Server, todoService *service.TodoService) *TodoHan
 func (h *TodoHandler) CreateTodo(c echo.Context) error {
 	return Handle(
 		h.Handler,
-		func(c echo.Context, payload *todo.CreateTodoPayload) (*todo.Todo, error) {
+		func(c echo.Context, payload *todo.CreateTodoPayload) (*todo.TodoWithWarnings, error) {
 			userID := middleware.GetUserID(c)
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.CreateOrgTodo(c, userID, membership, payload)
+			}
 			return h.todoService.CreateTodo(c, userID, payload)
 		},
 		http.StatusCreated,
@@ -36,10 +41,61 @@ func (h *TodoHandler) CreateTodo(c echo.Context) error {
 	)(c)
 }
 
+func (h *TodoHandler) QuickAddTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.QuickAddTodoPayload) (*todo.QuickAddResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.QuickAddTodo(c, userID, payload)
+		},
+		http.StatusCreated,
+		&todo.QuickAddTodoPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) PromoteTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.PromoteTodoPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.PromoteToTopLevel(c, userID, payload.ID, *payload.KeepChildren)
+		},
+		http.StatusOK,
+		&todo.PromoteTodoPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) CopyAsFresh(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.CopyFreshPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CopyAsFresh(c, userID, payload)
+		},
+		http.StatusCreated,
+		&todo.CopyFreshPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BumpTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BumpTodoPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BumpTodo(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&todo.BumpTodoPayload{},
+	)(c)
+}
+
 func (h *TodoHandler) GetTodoByID(c echo.Context) error {
 	return Handle(
 		h.Handler,
 		func(c echo.Context, payload *todo.GetTodoByIDPayload) (*todo.PopulatedTodo, error) {
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.GetOrgTodoByID(c, membership, payload.ID)
+			}
 			userID := middleware.GetUserID(c)
 			return h.todoService.GetTodoByID(c, userID, payload.ID)
 		},
@@ -48,23 +104,137 @@ func (h *TodoHandler) GetTodoByID(c echo.Context) error {
 	)(c)
 }
 
+func (h *TodoHandler) GetChildren(c echo.Context) error {
+	return HandleVersioned(
+		h.Handler,
+		func(c echo.Context, query *todo.GetChildrenQuery) (*model.PaginatedResponse[todo.Todo], error) {
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.GetOrgChildren(c, membership, query)
+			}
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetChildren(c, userID, query)
+		},
+		http.StatusOK,
+		&todo.GetChildrenQuery{},
+		Serializers[*model.PaginatedResponse[todo.Todo]]{
+			APIVersionV2: func(result *model.PaginatedResponse[todo.Todo]) any {
+				return result.ToV2()
+			},
+		},
+	)(c)
+}
+
 func (h *TodoHandler) GetTodos(c echo.Context) error {
-	return Handle(
+	return HandleVersioned(
 		h.Handler,
 		func(c echo.Context, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.GetOrgTodos(c, membership, query)
+			}
 			userID := middleware.GetUserID(c)
 			return h.todoService.GetTodos(c, userID, query)
 		},
 		http.StatusOK,
 		&todo.GetTodosQuery{},
+		Serializers[*model.PaginatedResponse[todo.PopulatedTodo]]{
+			APIVersionV2: func(result *model.PaginatedResponse[todo.PopulatedTodo]) any {
+				return result.ToV2()
+			},
+		},
+	)(c)
+}
+
+func (h *TodoHandler) HasOverdue(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.HasOverduePayload) (*todo.OverdueIndicator, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.HasOverdue(c, userID)
+		},
+		http.StatusOK,
+		&todo.HasOverduePayload{},
+	)(c)
+}
+
+func (h *TodoHandler) GetOverdueBuckets(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetOverdueBucketsQuery) (*todo.OverdueBuckets, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetOverdueBuckets(c, userID, *query.IncludeIDs)
+		},
+		http.StatusOK,
+		&todo.GetOverdueBucketsQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetDeferredTodos(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.GetDeferredTodosPayload) ([]todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetDeferredTodos(c, userID)
+		},
+		http.StatusOK,
+		&todo.GetDeferredTodosPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) GetStaleTodos(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetStaleTodosQuery) ([]todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetStaleTodos(c, userID, *query.Days)
+		},
+		http.StatusOK,
+		&todo.GetStaleTodosQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetIncompleteTodos(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetIncompleteTodosQuery) ([]todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetIncompleteTodos(c, userID, query.Fields)
+		},
+		http.StatusOK,
+		&todo.GetIncompleteTodosQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) PreviewRecurrence(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.RecurrencePreviewPayload) (*todo.RecurrencePreview, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.PreviewRecurrence(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.RecurrencePreviewPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) ValidateRecurrence(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.ValidateRecurrencePayload) (*todo.RecurrenceValidation, error) {
+			return h.todoService.ValidateRecurrence(c, payload)
+		},
+		http.StatusOK,
+		&todo.ValidateRecurrencePayload{},
 	)(c)
 }
 
 func (h *TodoHandler) UpdateTodo(c echo.Context) error {
 	return Handle(
 		h.Handler,
-		func(c echo.Context, payload *todo.UpdateTodoPayload) (*todo.Todo, error) {
+		func(c echo.Context, payload *todo.UpdateTodoPayload) (*todo.TodoWithWarnings, error) {
 			userID := middleware.GetUserID(c)
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.UpdateOrgTodo(c, userID, membership, payload)
+			}
 			return h.todoService.UpdateTodo(c, userID, payload)
 		},
 		http.StatusOK,
@@ -72,11 +242,26 @@ func (h *TodoHandler) UpdateTodo(c echo.Context) error {
 	)(c)
 }
 
+func (h *TodoHandler) CompleteWithFollowUp(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.CompleteWithFollowUpPayload) (*todo.CompleteWithFollowUpResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CompleteWithFollowUp(c, userID, payload)
+		},
+		http.StatusCreated,
+		&todo.CompleteWithFollowUpPayload{},
+	)(c)
+}
+
 func (h *TodoHandler) DeleteTodo(c echo.Context) error {
 	return HandleNoContent(
 		h.Handler,
 		func(c echo.Context, payload *todo.DeleteTodoPayload) error {
 			userID := middleware.GetUserID(c)
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.DeleteOrgTodo(c, userID, membership, payload.ID)
+			}
 			return h.todoService.DeleteTodo(c, userID, payload.ID)
 		},
 		http.StatusNoContent,
@@ -84,6 +269,186 @@ func (h *TodoHandler) DeleteTodo(c echo.Context) error {
 	)(c)
 }
 
+func (h *TodoHandler) GetTrash(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetTrashQuery) (*model.PaginatedResponse[todo.Todo], error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetTrash(c, userID, query)
+		},
+		http.StatusOK,
+		&todo.GetTrashQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) RestoreTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.RestoreTodoPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.RestoreTodo(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&todo.RestoreTodoPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) UndoTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.UndoTodoPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.UndoTodo(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.UndoTodoPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) PurgeTodo(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.PurgeTodoPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.PurgeTodo(c, userID, payload.ID)
+		},
+		http.StatusNoContent,
+		&todo.PurgeTodoPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkReparent(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkReparentPayload) (*todo.BulkUpdateResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkReparent(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkReparentPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkUpdateStatus(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkUpdateStatusPayload) (*todo.BulkUpdateResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkUpdateStatus(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkUpdateStatusPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkByFilter(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkByFilterPayload) (*todo.BulkByFilterResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkByFilter(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkByFilterPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkDelete(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkDeletePayload) (*todo.BulkDeleteResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkDelete(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkDeletePayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkArchive(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkArchivePayload) (*todo.BulkArchiveResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkArchive(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkArchivePayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkUnarchive(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkArchivePayload) (*todo.BulkArchiveResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkUnarchive(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkArchivePayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkReopen(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkReopenPayload) (*todo.BulkUpdateResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkReopen(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkReopenPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) BulkSetPriority(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.BulkSetPriorityPayload) (*todo.BulkUpdateResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.BulkSetPriority(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.BulkSetPriorityPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) CreateListSnapshot(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.CreateListSnapshotPayload) (*todo.ListSnapshot, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CreateListSnapshot(c, userID)
+		},
+		http.StatusCreated,
+		&todo.CreateListSnapshotPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) DeleteListSnapshot(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.DeleteListSnapshotPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.DeleteListSnapshot(c, userID, payload.Token)
+		},
+		http.StatusNoContent,
+		&todo.DeleteListSnapshotPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) SnoozeOverdue(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.SnoozeOverduePayload) (*todo.SnoozeOverdueResult, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.SnoozeOverdue(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.SnoozeOverduePayload{},
+	)(c)
+}
+
 func (h *TodoHandler) GetTodoStats(c echo.Context) error {
 	return Handle(
 		h.Handler,
@@ -96,6 +461,290 @@ func (h *TodoHandler) GetTodoStats(c echo.Context) error {
 	)(c)
 }
 
+// CountTodos accepts the same filters as GetTodos. Pagination is validated
+// but has no effect on the count.
+func (h *TodoHandler) CountTodos(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetTodosQuery) (*todo.TodoCount, error) {
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.CountOrgTodos(c, membership, query)
+			}
+			userID := middleware.GetUserID(c)
+			return h.todoService.CountTodos(c, userID, query)
+		},
+		http.StatusOK,
+		&todo.GetTodosQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetFilteredTodoStats(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetTodosQuery) (*todo.TodoStats, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetFilteredTodoStats(c, userID, query)
+		},
+		http.StatusOK,
+		&todo.GetTodosQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetCycleTimeStats(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *todo.GetCycleTimeQuery) (*todo.CycleTimeStats, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetCycleTimeStats(c, userID, *query.ExcludeOutliers)
+		},
+		http.StatusOK,
+		&todo.GetCycleTimeQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetTodoActivity(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *activity.GetTodoActivityQuery) (*model.PaginatedResponse[activity.Activity], error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetTodoActivity(c, userID, query)
+		},
+		http.StatusOK,
+		&activity.GetTodoActivityQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetTodoDiff(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *activity.GetTodoDiffQuery) (*activity.TodoDiff, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetTodoDiff(c, userID, query.TodoID, query.From)
+		},
+		http.StatusOK,
+		&activity.GetTodoDiffQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetChangeFeed(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *activity.GetChangeFeedQuery) (*activity.ChangeFeed, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetChangeFeed(c, userID, query)
+		},
+		http.StatusOK,
+		&activity.GetChangeFeedQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetChangesSince(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, query *activity.GetChangesSinceQuery) (*activity.ChangeSummary, error) {
+			if membership := middleware.GetOrgMembership(c); membership != nil {
+				return h.todoService.GetOrgChangesSince(c, membership, query.TodoID, *query.Since)
+			}
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetChangesSince(c, userID, query.TodoID, *query.Since)
+		},
+		http.StatusOK,
+		&activity.GetChangesSinceQuery{},
+	)(c)
+}
+
+func (h *TodoHandler) GetTodoReminders(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.GetTodoRemindersPayload) ([]todo.Reminder, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetTodoReminders(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&todo.GetTodoRemindersPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) CancelTodoReminder(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.CancelTodoReminderPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CancelTodoReminder(c, userID, payload.ID, payload.ReminderID)
+		},
+		http.StatusNoContent,
+		&todo.CancelTodoReminderPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) AddChecklistItem(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.AddChecklistItemPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.AddChecklistItem(c, userID, payload)
+		},
+		http.StatusCreated,
+		&todo.AddChecklistItemPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) UpdateChecklistItem(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.UpdateChecklistItemPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.UpdateChecklistItem(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.UpdateChecklistItemPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) RemoveChecklistItem(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.DeleteChecklistItemPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.RemoveChecklistItem(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.DeleteChecklistItemPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) CreateFeedToken(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.CreateFeedTokenPayload) (*todo.FeedToken, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CreateFeedToken(c, userID)
+		},
+		http.StatusCreated,
+		&todo.CreateFeedTokenPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) RevokeFeedToken(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.RevokeFeedTokenPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.RevokeFeedToken(c, userID)
+		},
+		http.StatusNoContent,
+		&todo.RevokeFeedTokenPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) GetCompletedFeed(c echo.Context) error {
+	return HandleBlob(
+		h.Handler,
+		func(c echo.Context, query *todo.GetCompletedFeedQuery) ([]byte, error) {
+			return h.todoService.GetCompletedFeed(c, query.Token)
+		},
+		http.StatusOK,
+		&todo.GetCompletedFeedQuery{},
+		feed.AtomContentType,
+	)(c)
+}
+
+func (h *TodoHandler) ExportTodoMarkdown(c echo.Context) error {
+	return HandleBlob(
+		h.Handler,
+		func(c echo.Context, payload *todo.ExportTodoMarkdownPayload) ([]byte, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.ExportTodoMarkdown(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&todo.ExportTodoMarkdownPayload{},
+		todo.MarkdownContentType,
+	)(c)
+}
+
+func (h *TodoHandler) CreateShareLink(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.CreateShareLinkPayload) (*todo.ShareLinkToken, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.CreateShareLink(c, userID, payload.ID)
+		},
+		http.StatusCreated,
+		&todo.CreateShareLinkPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) RevokeShareLink(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.RevokeShareLinkPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.RevokeShareLink(c, userID, payload.ID)
+		},
+		http.StatusNoContent,
+		&todo.RevokeShareLinkPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) SetPosition(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.SetPositionPayload) (*todo.Todo, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.SetPosition(c, userID, payload)
+		},
+		http.StatusOK,
+		&todo.SetPositionPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) GetDependencies(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.GetDependenciesPayload) (*todo.Dependencies, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.GetDependencies(c, userID, payload.ID)
+		},
+		http.StatusOK,
+		&todo.GetDependenciesPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) AddDependency(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.AddDependencyPayload) (*todo.Dependencies, error) {
+			userID := middleware.GetUserID(c)
+			return h.todoService.AddDependency(c, userID, payload)
+		},
+		http.StatusCreated,
+		&todo.AddDependencyPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) RemoveDependency(c echo.Context) error {
+	return HandleNoContent(
+		h.Handler,
+		func(c echo.Context, payload *todo.RemoveDependencyPayload) error {
+			userID := middleware.GetUserID(c)
+			return h.todoService.RemoveDependency(c, userID, payload.ID, payload.OtherID)
+		},
+		http.StatusNoContent,
+		&todo.RemoveDependencyPayload{},
+	)(c)
+}
+
+func (h *TodoHandler) GetSharedTodo(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.GetSharedTodoPayload) (*todo.SharedTodo, error) {
+			return h.todoService.GetSharedTodo(c, payload.Token)
+		},
+		http.StatusOK,
+		&todo.GetSharedTodoPayload{},
+	)(c)
+}
+
 func (h *TodoHandler) UploadTodoAttachment(c echo.Context) error {
 	return Handle(
 		h.Handler,
@@ -123,6 +772,24 @@ func (h *TodoHandler) UploadTodoAttachment(c echo.Context) error {
 	)(c)
 }
 
+func (h *TodoHandler) ImportICS(c echo.Context) error {
+	return Handle(
+		h.Handler,
+		func(c echo.Context, payload *todo.ImportICSPayload) (*todo.ImportResult, error) {
+			userID := middleware.GetUserID(c)
+
+			file, err := c.FormFile("file")
+			if err != nil {
+				return nil, errs.NewBadRequestError("no file found", false, nil, nil, nil)
+			}
+
+			return h.todoService.ImportICS(c, userID, payload, file)
+		},
+		http.StatusOK,
+		&todo.ImportICSPayload{},
+	)(c)
+}
+
 func (h *TodoHandler) DeleteTodoAttachment(c echo.Context) error {
 	return HandleNoContent(
 		h.Handler,
@@ -138,18 +805,13 @@ func (h *TodoHandler) DeleteTodoAttachment(c echo.Context) error {
 func (h *TodoHandler) GetAttachmentPresignedURL(c echo.Context) error {
 	return Handle(
 		h.Handler,
-		func(c echo.Context, payload *todo.GetAttachmentPresignedURLPayload) (*struct {
-			URL string `json:"url"`
-		}, error,
-		) {
+		func(c echo.Context, payload *todo.GetAttachmentPresignedURLPayload) (*todo.AttachmentURL, error) {
 			userID := middleware.GetUserID(c)
 			url, err := h.todoService.GetAttachmentPresignedURL(c, userID, payload.TodoID, payload.AttachmentID)
 			if err != nil {
 				return nil, err
 			}
-			return &struct {
-				URL string `json:"url"`
-			}{URL: url}, nil
+			return &todo.AttachmentURL{URL: url}, nil
 		},
 		http.StatusOK,
 		&todo.GetAttachmentPresignedURLPayload{},
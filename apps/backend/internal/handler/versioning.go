@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/sriniously/tasker/internal/validation"
+)
+
+// APIVersion selects the response shape a client asked for through Accept
+type APIVersion int
+
+const (
+	APIVersionV1 APIVersion = 1
+	APIVersionV2 APIVersion = 2
+
+	DefaultAPIVersion = APIVersionV1
+)
+
+const vendorMediaTypePrefix = "application/vnd.tasker.v"
+
+// MediaType returns the vendor media type for the version, e.g. application/vnd.tasker.v2+json
+func (v APIVersion) MediaType() string {
+	return fmt.Sprintf("%s%d+json", vendorMediaTypePrefix, v)
+}
+
+// NegotiateVersion picks the first known version requested in an Accept
+// header. Anything else, including an unknown version, gets the default.
+func NegotiateVersion(accept string) APIVersion {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.HasPrefix(mediaType, vendorMediaTypePrefix) {
+			continue
+		}
+
+		var version int
+		if _, err := fmt.Sscanf(mediaType, vendorMediaTypePrefix+"%d+json", &version); err != nil {
+			continue
+		}
+
+		switch APIVersion(version) {
+		case APIVersionV1, APIVersionV2:
+			return APIVersion(version)
+		}
+	}
+
+	return DefaultAPIVersion
+}
+
+// Serializers maps a version to the function shaping a result for it.
+// Versions without an entry get the result as-is, which is the v1 shape.
+type Serializers[Res any] map[APIVersion]func(Res) any
+
+// VersionedJSONResponseHandler serializes JSON responses in the version negotiated from Accept
+type VersionedJSONResponseHandler struct {
+	status      int
+	serializers map[APIVersion]func(any) any
+}
+
+func (h VersionedJSONResponseHandler) Handle(c echo.Context, result interface{}) error {
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	version := NegotiateVersion(accept)
+
+	body := result
+	if serialize, ok := h.serializers[version]; ok {
+		body = serialize(result)
+	}
+
+	header := c.Response().Header()
+	header.Add(echo.HeaderVary, echo.HeaderAccept)
+	// Clients that didn't ask for a vendor type keep getting plain JSON
+	if strings.Contains(accept, vendorMediaTypePrefix) {
+		header.Set(echo.HeaderContentType, version.MediaType())
+	}
+
+	return c.JSON(h.status, body)
+}
+
+func (h VersionedJSONResponseHandler) GetOperation() string {
+	return "handler"
+}
+
+func (h VersionedJSONResponseHandler) AddAttributes(txn *newrelic.Transaction, result interface{}) {
+	// http.status_code is already set by tracing middleware
+}
+
+// HandleVersioned is Handle for endpoints whose response shape depends on the
+// API version requested through the Accept header.
+func HandleVersioned[Req validation.Validatable, Res any](
+	h Handler,
+	handler HandlerFunc[Req, Res],
+	status int,
+	req Req,
+	serializers Serializers[Res],
+) echo.HandlerFunc {
+	erased := make(map[APIVersion]func(any) any, len(serializers))
+	for version, serialize := range serializers {
+		erased[version] = func(result any) any {
+			return serialize(result.(Res))
+		}
+	}
+
+	return func(c echo.Context) error {
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			return handler(c, req)
+		}, VersionedJSONResponseHandler{
+			status:      status,
+			serializers: erased,
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listPayload struct{}
+
+func (p *listPayload) Validate() error {
+	return nil
+}
+
+func serveVersioned(t *testing.T, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	h := handler.NewHandler(&server.Server{Logger: &logger})
+
+	endpoint := handler.HandleVersioned(
+		h,
+		func(c echo.Context, payload *listPayload) (*model.PaginatedResponse[string], error) {
+			return &model.PaginatedResponse[string]{
+				Data:       []string{"a", "b"},
+				Page:       1,
+				Limit:      2,
+				Total:      3,
+				TotalPages: 2,
+			}, nil
+		},
+		http.StatusOK,
+		&listPayload{},
+		handler.Serializers[*model.PaginatedResponse[string]]{
+			handler.APIVersionV2: func(result *model.PaginatedResponse[string]) any {
+				return result.ToV2()
+			},
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+	if accept != "" {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, endpoint(echo.New().NewContext(req, rec)))
+
+	return rec
+}
+
+func TestHandleVersioned(t *testing.T) {
+	t.Run("v1 keeps the flat pagination shape", func(t *testing.T) {
+		rec := serveVersioned(t, "application/vnd.tasker.v1+json")
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.EqualValues(t, 3, body["total"])
+		assert.NotContains(t, body, "pagination")
+		assert.Equal(t, "application/vnd.tasker.v1+json", rec.Header().Get(echo.HeaderContentType))
+	})
+
+	t.Run("v2 nests pagination", func(t *testing.T) {
+		rec := serveVersioned(t, "application/vnd.tasker.v2+json")
+
+		var body model.PaginatedResponseV2[string]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, []string{"a", "b"}, body.Data)
+		assert.Equal(t, 3, body.Pagination.Total)
+		assert.Equal(t, 2, body.Pagination.TotalPages)
+		assert.Equal(t, "application/vnd.tasker.v2+json", rec.Header().Get(echo.HeaderContentType))
+	})
+
+	t.Run("unknown version falls back to v1", func(t *testing.T) {
+		rec := serveVersioned(t, "application/vnd.tasker.v9+json")
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.EqualValues(t, 3, body["total"])
+		assert.Equal(t, "application/vnd.tasker.v1+json", rec.Header().Get(echo.HeaderContentType))
+	})
+
+	t.Run("plain json accept gets v1 as application/json", func(t *testing.T) {
+		rec := serveVersioned(t, "application/json")
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.EqualValues(t, 3, body["total"])
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+	})
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	assert.Equal(t, handler.APIVersionV1, handler.NegotiateVersion(""))
+	assert.Equal(t, handler.APIVersionV2, handler.NegotiateVersion("text/html, application/vnd.tasker.v2+json;q=0.9"))
+	assert.Equal(t, handler.APIVersionV1, handler.NegotiateVersion("application/vnd.tasker.vx+json"))
+}
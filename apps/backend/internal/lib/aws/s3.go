@@ -48,9 +48,15 @@ func (s *S3Client) UploadFile(ctx context.Context, bucket string, fileName strin
 }
 
 func (s *S3Client) CreatePresignedUrl(ctx context.Context, bucket string, objectKey string) (string, error) {
-	presignClient := s3.NewPresignClient(s.client)
+	return s.CreatePresignedUrlWithExpiry(ctx, bucket, objectKey, time.Minute*60)
+}
 
-	expiration := time.Minute * 60
+// CreatePresignedUrlWithExpiry presigns a download that stops working after
+// expiration, for links handed to people outside the account
+func (s *S3Client) CreatePresignedUrlWithExpiry(ctx context.Context, bucket string, objectKey string,
+	expiration time.Duration,
+) (string, error) {
+	presignClient := s3.NewPresignClient(s.client)
 
 	presignedUrl, err := presignClient.PresignGetObject(ctx,
 		&s3.GetObjectInput{
@@ -65,6 +71,19 @@ func (s *S3Client) CreatePresignedUrl(ctx context.Context, bucket string, object
 	return presignedUrl.URL, nil
 }
 
+// GetObject opens the object for reading. The caller closes the body.
+func (s *S3Client) GetObject(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	return output.Body, nil
+}
+
 func (s *S3Client) DeleteObject(ctx context.Context, bucket string, key string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
@@ -76,3 +95,18 @@ func (s *S3Client) DeleteObject(ctx context.Context, bucket string, key string)
 
 	return nil
 }
+
+// CheckStorage heads the upload bucket, which fails when the bucket is
+// missing, unreachable or the credentials are denied access to it
+func (s *S3Client) CheckStorage(ctx context.Context) error {
+	bucket := s.server.Config.AWS.UploadBucket
+
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to head bucket %s: %w", bucket, err)
+	}
+
+	return nil
+}
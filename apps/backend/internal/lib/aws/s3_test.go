@@ -0,0 +1,86 @@
+package aws_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestS3Client(endpoint string) *aws.S3Client {
+	s := &server.Server{
+		Config: &config.Config{
+			AWS: config.AWSConfig{UploadBucket: "uploads"},
+		},
+	}
+
+	cfg := awssdk.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		BaseEndpoint: awssdk.String(endpoint),
+		// Fail on the first error instead of backing off between retries
+		RetryMaxAttempts: 1,
+	}
+
+	return aws.NewS3Client(s, cfg)
+}
+
+func TestS3Client_CheckStorage(t *testing.T) {
+	t.Run("reachable bucket is healthy", func(t *testing.T) {
+		var path string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.Host + r.URL.Path
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		err := newTestS3Client(srv.URL).CheckStorage(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, path, "uploads")
+	})
+
+	t.Run("denied bucket is unhealthy", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer srv.Close()
+
+		err := newTestS3Client(srv.URL).CheckStorage(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("unreachable bucket is unhealthy", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		endpoint := srv.URL
+		srv.Close()
+
+		err := newTestS3Client(endpoint).CheckStorage(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("hanging bucket gives up at the deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := newTestS3Client(srv.URL).CheckStorage(ctx)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
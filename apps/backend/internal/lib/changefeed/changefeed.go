@@ -0,0 +1,111 @@
+package changefeed
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Notifier tells requests waiting on a user's changes that something changed.
+// It only signals; the changes themselves are read from the activity log.
+type Notifier interface {
+	// Publish signals everyone subscribed to userID
+	Publish(ctx context.Context, userID string) error
+	// Subscribe returns a channel that receives after each Publish for userID
+	// from the moment Subscribe returns, and a function that ends the
+	// subscription. Signals arriving while one is pending are merged.
+	Subscribe(ctx context.Context, userID string) (<-chan struct{}, func(), error)
+}
+
+// RedisNotifier signals over Redis pub/sub so every server instance sees the
+// changes made through the others
+type RedisNotifier struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisNotifier(client *redis.Client) *RedisNotifier {
+	return &RedisNotifier{
+		client: client,
+		prefix: "tasker:changes:",
+	}
+}
+
+func (n *RedisNotifier) Publish(ctx context.Context, userID string) error {
+	return n.client.Publish(ctx, n.prefix+userID, "changed").Err()
+}
+
+func (n *RedisNotifier) Subscribe(ctx context.Context, userID string) (<-chan struct{}, func(), error) {
+	pubsub := n.client.Subscribe(ctx, n.prefix+userID)
+
+	// Wait for the confirmation so a publish right after Subscribe returns
+	// isn't missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+
+	signals := make(chan struct{}, 1)
+	messages := pubsub.Channel()
+	go func() {
+		for range messages {
+			notify(signals)
+		}
+	}()
+
+	return signals, func() { _ = pubsub.Close() }, nil
+}
+
+// MemoryNotifier signals within the process, for a single instance or tests
+type MemoryNotifier struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{
+		subscribers: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+func (n *MemoryNotifier) Publish(ctx context.Context, userID string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for signals := range n.subscribers[userID] {
+		notify(signals)
+	}
+	return nil
+}
+
+func (n *MemoryNotifier) Subscribe(ctx context.Context, userID string) (<-chan struct{}, func(), error) {
+	signals := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	if n.subscribers[userID] == nil {
+		n.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	n.subscribers[userID][signals] = struct{}{}
+	n.mu.Unlock()
+
+	unsubscribe := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		delete(n.subscribers[userID], signals)
+		if len(n.subscribers[userID]) == 0 {
+			delete(n.subscribers, userID)
+		}
+	}
+
+	return signals, unsubscribe, nil
+}
+
+// notify leaves a signal unless one is already waiting to be received
+func notify(signals chan struct{}) {
+	select {
+	case signals <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,53 @@
+package changefeed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publish signals only the user's subscribers", func(t *testing.T) {
+		n := changefeed.NewMemoryNotifier()
+		mine, unsubscribeMine, err := n.Subscribe(ctx, "user-1")
+		require.NoError(t, err)
+		defer unsubscribeMine()
+		theirs, unsubscribeTheirs, err := n.Subscribe(ctx, "user-2")
+		require.NoError(t, err)
+		defer unsubscribeTheirs()
+
+		require.NoError(t, n.Publish(ctx, "user-1"))
+
+		assert.Len(t, mine, 1)
+		assert.Empty(t, theirs)
+	})
+
+	t.Run("signals pending together are merged", func(t *testing.T) {
+		n := changefeed.NewMemoryNotifier()
+		signals, unsubscribe, err := n.Subscribe(ctx, "user-1")
+		require.NoError(t, err)
+		defer unsubscribe()
+
+		require.NoError(t, n.Publish(ctx, "user-1"))
+		require.NoError(t, n.Publish(ctx, "user-1"))
+
+		<-signals
+		assert.Empty(t, signals)
+	})
+
+	t.Run("nothing is signalled after unsubscribing", func(t *testing.T) {
+		n := changefeed.NewMemoryNotifier()
+		signals, unsubscribe, err := n.Subscribe(ctx, "user-1")
+		require.NoError(t, err)
+		unsubscribe()
+
+		require.NoError(t, n.Publish(ctx, "user-1"))
+
+		assert.Empty(t, signals)
+	})
+}
@@ -0,0 +1,277 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// EventTodoPrefix starts the type of every todo event; the activity
+	// action follows, as in todo.created or todo.status_changed
+	EventTodoPrefix      = "todo."
+	EventCommentAdded    = "comment.added"
+	EventCommentUpdated  = "comment.updated"
+	EventCommentDeleted  = "comment.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+const (
+	// listenerBuffer is how many events a listener may fall behind by before
+	// further events to it are dropped
+	listenerBuffer = 64
+	// replayLength is how many of a user's latest events are kept so a
+	// reconnecting client can catch up on what it missed
+	replayLength = 200
+	// replayTTL is how long a user's kept events outlive the last of them
+	replayTTL = 10 * time.Minute
+)
+
+// Event is a change pushed to a user's open real-time connections
+type Event struct {
+	// ID orders the user's events; it's assigned when the event is broadcast
+	ID         string          `json:"id,omitempty"`
+	Type       string          `json:"type"`
+	EntityID   string          `json:"entityId"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	OccurredAt time.Time       `json:"occurredAt"`
+}
+
+// NewEvent encodes data as the event's payload, stamped with the current time
+func NewEvent(eventType string, entityID string, data any) (Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:       eventType,
+		EntityID:   entityID,
+		Data:       encoded,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// Broadcaster fans a user's events out to every connection the user has
+// open. Unlike Notifier it carries the change itself.
+type Broadcaster interface {
+	// Broadcast assigns the event an ID, keeps it for replay and sends it to
+	// everyone listening for userID
+	Broadcast(ctx context.Context, userID string, event Event) error
+	// Listen returns a channel that receives the events broadcast for userID
+	// from the moment Listen returns, and a function that stops listening. A
+	// listener that falls too far behind misses events.
+	Listen(ctx context.Context, userID string) (<-chan Event, func(), error)
+	// Replay returns the kept events broadcast for userID after the one with
+	// the given ID, oldest first. complete is false when that event is no
+	// longer kept, so some events in between may be missing.
+	Replay(ctx context.Context, userID string, afterID string) (events []Event, complete bool, err error)
+}
+
+// RedisBroadcaster fans events out over Redis pub/sub so connections held
+// by any server instance see the changes made through the others
+type RedisBroadcaster struct {
+	client    *redis.Client
+	prefix    string
+	logPrefix string
+}
+
+func NewRedisBroadcaster(client *redis.Client) *RedisBroadcaster {
+	return &RedisBroadcaster{
+		client:    client,
+		prefix:    "tasker:events:",
+		logPrefix: "tasker:events:log:",
+	}
+}
+
+// Broadcast keeps the event in a capped Redis stream, whose entry ID becomes
+// the event's ID, before publishing it
+func (b *RedisBroadcaster) Broadcast(ctx context.Context, userID string, event Event) error {
+	event.ID = ""
+	kept, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	logKey := b.logPrefix + userID
+	var added *redis.StringCmd
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: logKey,
+			MaxLen: replayLength,
+			Approx: true,
+			Values: map[string]any{"event": kept},
+		})
+		pipe.Expire(ctx, logKey, replayTTL)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	event.ID = added.Val()
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.prefix+userID, encoded).Err()
+}
+
+func (b *RedisBroadcaster) Replay(ctx context.Context, userID string, afterID string) ([]Event, bool, error) {
+	if !streamID.MatchString(afterID) {
+		return []Event{}, false, nil
+	}
+
+	// The range includes afterID itself, which shows it's still kept
+	entries, err := b.client.XRange(ctx, b.logPrefix+userID, afterID, "+").Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := len(entries) > 0 && entries[0].ID == afterID
+	if complete {
+		entries = entries[1:]
+	}
+
+	events := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		if event, ok := decodeEntry(entry); ok {
+			events = append(events, event)
+		}
+	}
+	return events, complete, nil
+}
+
+// streamID matches a Redis stream entry ID
+var streamID = regexp.MustCompile(`^\d+-\d+$`)
+
+func decodeEntry(entry redis.XMessage) (Event, bool) {
+	raw, ok := entry.Values["event"].(string)
+	if !ok {
+		return Event{}, false
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		return Event{}, false
+	}
+	event.ID = entry.ID
+	return event, true
+}
+
+func (b *RedisBroadcaster) Listen(ctx context.Context, userID string) (<-chan Event, func(), error) {
+	pubsub := b.client.Subscribe(ctx, b.prefix+userID)
+
+	// Wait for the confirmation so an event broadcast right after Listen
+	// returns isn't missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan Event, listenerBuffer)
+	messages := pubsub.Channel()
+	go func() {
+		defer close(events)
+		for message := range messages {
+			var event Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				continue
+			}
+			deliver(events, event)
+		}
+	}()
+
+	return events, func() { _ = pubsub.Close() }, nil
+}
+
+// MemoryBroadcaster fans events out within the process, for a single
+// instance or tests
+type MemoryBroadcaster struct {
+	mu        sync.Mutex
+	seq       int64
+	listeners map[string]map[chan Event]struct{}
+	kept      map[string][]Event
+}
+
+func NewMemoryBroadcaster() *MemoryBroadcaster {
+	return &MemoryBroadcaster{
+		listeners: make(map[string]map[chan Event]struct{}),
+		kept:      make(map[string][]Event),
+	}
+}
+
+func (b *MemoryBroadcaster) Broadcast(ctx context.Context, userID string, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = strconv.FormatInt(b.seq, 10)
+
+	kept := append(b.kept[userID], event)
+	if len(kept) > replayLength {
+		kept = kept[len(kept)-replayLength:]
+	}
+	b.kept[userID] = kept
+
+	for events := range b.listeners[userID] {
+		deliver(events, event)
+	}
+	return nil
+}
+
+func (b *MemoryBroadcaster) Replay(ctx context.Context, userID string, afterID string) ([]Event, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.kept[userID]
+	for i, event := range kept {
+		if event.ID == afterID {
+			return slices.Clone(kept[i+1:]), true, nil
+		}
+	}
+	return slices.Clone(kept), false, nil
+}
+
+func (b *MemoryBroadcaster) Listen(ctx context.Context, userID string) (<-chan Event, func(), error) {
+	events := make(chan Event, listenerBuffer)
+
+	b.mu.Lock()
+	if b.listeners[userID] == nil {
+		b.listeners[userID] = make(map[chan Event]struct{})
+	}
+	b.listeners[userID][events] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.listeners[userID], events)
+			if len(b.listeners[userID]) == 0 {
+				delete(b.listeners, userID)
+			}
+			close(events)
+		})
+	}
+
+	return events, stop, nil
+}
+
+// deliver queues the event unless the listener's buffer is full
+func deliver(events chan Event, event Event) {
+	select {
+	case events <- event:
+	default:
+	}
+}
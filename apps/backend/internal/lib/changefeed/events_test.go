@@ -0,0 +1,94 @@
+package changefeed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBroadcaster(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("broadcast reaches only the user's listeners", func(t *testing.T) {
+		b := changefeed.NewMemoryBroadcaster()
+		mine, stopMine, err := b.Listen(ctx, "user-1")
+		require.NoError(t, err)
+		defer stopMine()
+		theirs, stopTheirs, err := b.Listen(ctx, "user-2")
+		require.NoError(t, err)
+		defer stopTheirs()
+
+		event := changefeed.Event{Type: changefeed.EventCommentAdded, EntityID: "comment-1"}
+		require.NoError(t, b.Broadcast(ctx, "user-1", event))
+
+		require.Len(t, mine, 1)
+		received := <-mine
+		assert.Equal(t, event.EntityID, received.EntityID)
+		assert.NotEmpty(t, received.ID)
+		assert.Empty(t, theirs)
+	})
+
+	t.Run("a listener that falls behind misses events", func(t *testing.T) {
+		b := changefeed.NewMemoryBroadcaster()
+		events, stop, err := b.Listen(ctx, "user-1")
+		require.NoError(t, err)
+		defer stop()
+
+		for range cap(events) + 10 {
+			require.NoError(t, b.Broadcast(ctx, "user-1", changefeed.Event{Type: changefeed.EventCategoryUpdated}))
+		}
+
+		assert.Len(t, events, cap(events))
+	})
+
+	t.Run("stopping closes the channel", func(t *testing.T) {
+		b := changefeed.NewMemoryBroadcaster()
+		events, stop, err := b.Listen(ctx, "user-1")
+		require.NoError(t, err)
+		stop()
+		stop()
+
+		require.NoError(t, b.Broadcast(ctx, "user-1", changefeed.Event{Type: changefeed.EventCategoryDeleted}))
+
+		_, open := <-events
+		assert.False(t, open)
+	})
+}
+
+func TestMemoryBroadcaster_Replay(t *testing.T) {
+	ctx := context.Background()
+	b := changefeed.NewMemoryBroadcaster()
+
+	for range 3 {
+		require.NoError(t, b.Broadcast(ctx, "user-1", changefeed.Event{Type: changefeed.EventCategoryCreated}))
+	}
+	require.NoError(t, b.Broadcast(ctx, "user-2", changefeed.Event{Type: changefeed.EventCategoryCreated}))
+
+	all, complete, err := b.Replay(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.False(t, complete)
+	require.Len(t, all, 3)
+
+	t.Run("returns the events after the given one", func(t *testing.T) {
+		events, complete, err := b.Replay(ctx, "user-1", all[0].ID)
+		require.NoError(t, err)
+		assert.True(t, complete)
+		assert.Equal(t, all[1:], events)
+	})
+
+	t.Run("nothing after the latest event", func(t *testing.T) {
+		events, complete, err := b.Replay(ctx, "user-1", all[2].ID)
+		require.NoError(t, err)
+		assert.True(t, complete)
+		assert.Empty(t, events)
+	})
+
+	t.Run("an event no longer kept is incomplete", func(t *testing.T) {
+		_, complete, err := b.Replay(ctx, "user-1", "unknown")
+		require.NoError(t, err)
+		assert.False(t, complete)
+	})
+}
@@ -0,0 +1,70 @@
+// Package color computes WCAG contrast between hex colors.
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinUIContrast is the WCAG 2.1 minimum contrast for graphical objects and
+// user interface components
+const MinUIContrast = 3.0
+
+// ParseHex parses #rgb, #rgba, #rrggbb or #rrggbbaa into its red, green and
+// blue channels. Any alpha channel is ignored.
+func ParseHex(hex string) (r, g, b uint8, err error) {
+	digits := strings.TrimPrefix(hex, "#")
+
+	switch len(digits) {
+	case 3, 4:
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	case 6, 8:
+		digits = digits[:6]
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q: %w", hex, err)
+	}
+
+	return uint8(value >> 16), uint8(value >> 8), uint8(value), nil
+}
+
+// RelativeLuminance is the WCAG relative luminance of an sRGB color, from 0
+// for black to 1 for white
+func RelativeLuminance(r, g, b uint8) float64 {
+	linear := func(channel uint8) float64 {
+		c := float64(channel) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two hex colors, from
+// 1 for identical colors to 21 for black on white
+func ContrastRatio(foreground, background string) (float64, error) {
+	fr, fg, fb, err := ParseHex(foreground)
+	if err != nil {
+		return 0, err
+	}
+	br, bg, bb, err := ParseHex(background)
+	if err != nil {
+		return 0, err
+	}
+
+	lighter := RelativeLuminance(fr, fg, fb)
+	darker := RelativeLuminance(br, bg, bb)
+	if darker > lighter {
+		lighter, darker = darker, lighter
+	}
+
+	return (lighter + 0.05) / (darker + 0.05), nil
+}
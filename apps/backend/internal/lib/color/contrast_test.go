@@ -0,0 +1,47 @@
+package color_test
+
+import (
+	"testing"
+
+	"github.com/sriniously/tasker/internal/lib/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrastRatio(t *testing.T) {
+	t.Run("black on white is the maximum", func(t *testing.T) {
+		ratio, err := color.ContrastRatio("#000000", "#ffffff")
+		require.NoError(t, err)
+		assert.InDelta(t, 21.0, ratio, 0.001)
+	})
+
+	t.Run("order of colors does not matter", func(t *testing.T) {
+		a, err := color.ContrastRatio("#6b7280", "#ffffff")
+		require.NoError(t, err)
+		b, err := color.ContrastRatio("#ffffff", "#6b7280")
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+		assert.InDelta(t, 4.83, a, 0.01)
+	})
+
+	t.Run("near-white color is low contrast on white", func(t *testing.T) {
+		ratio, err := color.ContrastRatio("#fafafa", "#ffffff")
+		require.NoError(t, err)
+		assert.Less(t, ratio, color.MinUIContrast)
+	})
+
+	t.Run("short and alpha forms parse", func(t *testing.T) {
+		short, err := color.ContrastRatio("#f00", "#fff")
+		require.NoError(t, err)
+		long, err := color.ContrastRatio("#ff0000cc", "#ffffff")
+		require.NoError(t, err)
+		assert.Equal(t, short, long)
+	})
+
+	t.Run("invalid colors are rejected", func(t *testing.T) {
+		_, err := color.ContrastRatio("red", "#ffffff")
+		assert.Error(t, err)
+		_, err = color.ContrastRatio("#ffffff", "#gggggg")
+		assert.Error(t, err)
+	})
+}
@@ -2,6 +2,7 @@ package email
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 
@@ -16,6 +17,14 @@ type Client struct {
 	logger *zerolog.Logger
 }
 
+// Message is a rendered email ready to be sent
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
 func NewClient(cfg *config.Config, logger *zerolog.Logger) *Client {
 	return &Client{
 		client: resend.NewClient(cfg.Integration.ResendAPIKey),
@@ -23,30 +32,71 @@ func NewClient(cfg *config.Config, logger *zerolog.Logger) *Client {
 	}
 }
 
-func (c *Client) SendEmail(to, subject string, templateName Template, data map[string]any) error {
+// Render executes the named template into a message without sending it
+func Render(to, subject string, templateName Template, data map[string]any) (*Message, error) {
 	tmplPath := fmt.Sprintf("%s/%s.html", "templates/emails", templateName)
 
 	tmpl, err := template.ParseFiles(tmplPath)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse email template %s", templateName)
+		return nil, errors.Wrapf(err, "failed to parse email template %s", templateName)
 	}
 
 	var body bytes.Buffer
 	if err := tmpl.Execute(&body, data); err != nil {
-		return errors.Wrapf(err, "failed to execute email template %s", templateName)
+		return nil, errors.Wrapf(err, "failed to execute email template %s", templateName)
 	}
 
-	params := &resend.SendEmailRequest{
-		From:    fmt.Sprintf("%s <%s>", "Boilerplate", "onboarding@resend.dev"),
-		To:      []string{to},
+	return &Message{
+		To:      to,
 		Subject: subject,
-		Html:    body.String(),
+		HTML:    body.String(),
+		Text:    PlainText(body.String()),
+	}, nil
+}
+
+func (c *Client) SendEmail(to, subject string, templateName Template, data map[string]any) error {
+	msg, err := Render(to, subject, templateName, data)
+	if err != nil {
+		return err
 	}
 
-	_, err = c.client.Emails.Send(params)
+	return c.Send(msg)
+}
+
+func (c *Client) Send(msg *Message) error {
+	_, err := c.client.Emails.Send(sendRequest(msg))
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	return nil
 }
+
+// SendBatch sends several rendered messages in a single API call
+func (c *Client) SendBatch(ctx context.Context, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	params := make([]*resend.SendEmailRequest, 0, len(messages))
+	for i := range messages {
+		params = append(params, sendRequest(&messages[i]))
+	}
+
+	_, err := c.client.Batch.SendWithContext(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to send batch of %d emails: %w", len(messages), err)
+	}
+
+	return nil
+}
+
+func sendRequest(msg *Message) *resend.SendEmailRequest {
+	return &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", "Boilerplate", "onboarding@resend.dev"),
+		To:      []string{msg.To},
+		Subject: msg.Subject,
+		Html:    msg.HTML,
+		Text:    msg.Text,
+	}
+}
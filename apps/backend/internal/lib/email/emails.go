@@ -21,15 +21,31 @@ func (c *Client) SendWelcomeEmail(to, firstName string) error {
 	)
 }
 
-func (c *Client) SendDueDateReminderEmail(to, todoTitle string, todoID uuid.UUID, dueDate time.Time) error {
+func (c *Client) SendDueDateReminderEmail(to, todoTitle string, todoID uuid.UUID, dueDate time.Time,
+	otherTodos []string, moreCount int,
+) error {
+	msg, err := DueDateReminderMessage(to, todoTitle, todoID, dueDate, otherTodos, moreCount)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(msg)
+}
+
+// DueDateReminderMessage renders the reminder for a todo that is due soon
+func DueDateReminderMessage(to, todoTitle string, todoID uuid.UUID, dueDate time.Time,
+	otherTodos []string, moreCount int,
+) (*Message, error) {
 	data := map[string]interface{}{
 		"TodoTitle":    todoTitle,
 		"TodoID":       todoID.String(),
 		"DueDate":      dueDate.Format("Monday, January 2, 2006 at 3:04 PM"),
 		"DaysUntilDue": int(dueDate.Sub(time.Now()).Hours() / 24),
+		"OtherTodos":   otherTodos,
+		"MoreCount":    moreCount,
 	}
 
-	return c.SendEmail(
+	return Render(
 		to,
 		fmt.Sprintf("Reminder: '%s' is due soon", todoTitle),
 		TemplateDueDateReminder,
@@ -37,15 +53,31 @@ func (c *Client) SendDueDateReminderEmail(to, todoTitle string, todoID uuid.UUID
 	)
 }
 
-func (c *Client) SendOverdueNotificationEmail(to, todoTitle string, todoID uuid.UUID, dueDate time.Time) error {
+func (c *Client) SendOverdueNotificationEmail(to, todoTitle string, todoID uuid.UUID, dueDate time.Time,
+	otherTodos []string, moreCount int,
+) error {
+	msg, err := OverdueNotificationMessage(to, todoTitle, todoID, dueDate, otherTodos, moreCount)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(msg)
+}
+
+// OverdueNotificationMessage renders the notification for an overdue todo
+func OverdueNotificationMessage(to, todoTitle string, todoID uuid.UUID, dueDate time.Time,
+	otherTodos []string, moreCount int,
+) (*Message, error) {
 	data := map[string]interface{}{
 		"TodoTitle":   todoTitle,
 		"TodoID":      todoID.String(),
 		"DueDate":     dueDate.Format("Monday, January 2, 2006 at 3:04 PM"),
 		"DaysOverdue": int(time.Now().Sub(dueDate).Hours() / 24),
+		"OtherTodos":  otherTodos,
+		"MoreCount":   moreCount,
 	}
 
-	return c.SendEmail(
+	return Render(
 		to,
 		fmt.Sprintf("Overdue: '%s' needs your attention", todoTitle),
 		TemplateOverdueNotification,
@@ -56,6 +88,19 @@ func (c *Client) SendOverdueNotificationEmail(to, todoTitle string, todoID uuid.
 func (c *Client) SendWeeklyReportEmail(to string, weekStart, weekEnd time.Time,
 	completedCount, activeCount, overdueCount int, completedTodos, overdueTodos []todo.PopulatedTodo,
 ) error {
+	msg, err := WeeklyReportMessage(to, weekStart, weekEnd, completedCount, activeCount, overdueCount,
+		completedTodos, overdueTodos)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(msg)
+}
+
+// WeeklyReportMessage renders the weekly productivity report
+func WeeklyReportMessage(to string, weekStart, weekEnd time.Time,
+	completedCount, activeCount, overdueCount int, completedTodos, overdueTodos []todo.PopulatedTodo,
+) (*Message, error) {
 	data := map[string]interface{}{
 		"WeekStart":      weekStart.Format("January 2, 2006"),
 		"WeekEnd":        weekEnd.Format("January 2, 2006"),
@@ -68,7 +113,7 @@ func (c *Client) SendWeeklyReportEmail(to string, weekStart, weekEnd time.Time,
 		"HasOverdue":     overdueCount > 0,
 	}
 
-	return c.SendEmail(
+	return Render(
 		to,
 		fmt.Sprintf("Your Weekly Productivity Report (%s - %s)",
 			weekStart.Format("Jan 2"), weekEnd.Format("Jan 2")),
@@ -76,3 +121,14 @@ func (c *Client) SendWeeklyReportEmail(to string, weekStart, weekEnd time.Time,
 		data,
 	)
 }
+
+// NothingDueMessage renders the reminder variant for a user with nothing due
+// or overdue. The cron jobs never send it; it fills the notification preview.
+func NothingDueMessage(to string) (*Message, error) {
+	return Render(
+		to,
+		"You're all caught up",
+		TemplateNothingDue,
+		map[string]any{},
+	)
+}
@@ -0,0 +1,36 @@
+package email
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headPattern    = regexp.MustCompile(`(?is)<head.*?</head>|<style.*?</style>|<!--.*?-->`)
+	breakPattern   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|h[1-6]|tr|li|div|table)>|<hr[^>]*>`)
+	tagPattern     = regexp.MustCompile(`<[^>]*>`)
+	spacePattern   = regexp.MustCompile(`\s+`)
+	invisibleRunes = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\u200e", "", "\u200f", "", "\ufeff", "", "\u00a0", " ")
+)
+
+// PlainText derives the text body of an email from its rendered HTML, keeping
+// one line per block element and dropping markup and preheader padding.
+func PlainText(body string) string {
+	body = headPattern.ReplaceAllString(body, "")
+	// Source line breaks are formatting only; block elements decide the lines
+	body = spacePattern.ReplaceAllString(body, " ")
+	body = breakPattern.ReplaceAllString(body, "\n")
+	body = tagPattern.ReplaceAllString(body, "")
+	body = invisibleRunes.Replace(html.UnescapeString(body))
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,61 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const (
+	AtomNamespace   = "http://www.w3.org/2005/Atom"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+)
+
+// Atom is an RFC 4287 feed document
+type Atom struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *AtomPerson `xml:"author,omitempty"`
+	Links   []AtomLink  `xml:"link,omitempty"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type AtomEntry struct {
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Updated   string    `xml:"updated"`
+	Published string    `xml:"published,omitempty"`
+	Summary   *AtomText `xml:"summary,omitempty"`
+}
+
+// Time formats a timestamp the way Atom date constructs require
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Render serialises the feed with an XML declaration
+func (a *Atom) Render() ([]byte, error) {
+	body, err := xml.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal atom feed: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,64 @@
+package feed_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/lib/feed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtom_Render(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	atom := &feed.Atom{
+		ID:      "urn:example:feed",
+		Title:   "Completed todos",
+		Updated: feed.Time(now),
+		Author:  &feed.AtomPerson{Name: "Tasker"},
+		Entries: []feed.AtomEntry{
+			{
+				ID:      "urn:uuid:1",
+				Title:   "Ship <release>",
+				Updated: feed.Time(now),
+				Summary: &feed.AtomText{Type: "text", Body: "notes & more"},
+			},
+			{
+				ID:      "urn:uuid:2",
+				Title:   "Write changelog",
+				Updated: feed.Time(now.Add(-time.Hour)),
+			},
+		},
+	}
+
+	body, err := atom.Render()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(body), xml.Header))
+
+	var parsed struct {
+		XMLName xml.Name
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+			Summary string `xml:"summary"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &parsed))
+
+	assert.Equal(t, feed.AtomNamespace, parsed.XMLName.Space)
+	assert.Equal(t, "feed", parsed.XMLName.Local)
+	assert.Equal(t, "urn:example:feed", parsed.ID)
+	assert.Equal(t, "2025-03-10T12:00:00Z", parsed.Updated)
+
+	require.Len(t, parsed.Entries, 2)
+	assert.Equal(t, "Ship <release>", parsed.Entries[0].Title)
+	assert.Equal(t, "notes & more", parsed.Entries[0].Summary)
+	assert.Equal(t, "2025-03-10T11:00:00Z", parsed.Entries[1].Updated)
+}
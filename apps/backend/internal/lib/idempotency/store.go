@@ -0,0 +1,152 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Record is what is kept for an idempotency key: the fingerprint of the
+// request it was first sent with and, once that request was served, the
+// response to replay for it
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	// Status is zero while the first request is still being served
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Completed reports whether the record holds a response to replay
+func (r *Record) Completed() bool {
+	return r.Status != 0
+}
+
+// Store keeps the records of idempotency keys
+type Store interface {
+	// Reserve stores record under key for ttl unless the key is taken, in
+	// which case the record already stored is returned instead
+	Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error)
+	// Save replaces the record under key, keeping it for ttl
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release forgets key so the request can be sent again
+	Release(ctx context.Context, key string) error
+}
+
+// RedisStore keeps records in Redis so retries reaching any server instance
+// see them
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: "tasker:idempotency:",
+	}
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key can expire between the two commands, so try once more before
+	// giving up
+	for range 2 {
+		reserved, err := s.client.SetNX(ctx, s.prefix+key, value, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		stored, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var existing Record
+		if err := json.Unmarshal(stored, &existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	return nil, errors.New("idempotency key expired while being reserved")
+}
+
+func (s *RedisStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// MemoryStore keeps records in process, for a single instance or tests
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     now,
+	}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		existing := entry.record
+		return &existing, nil
+	}
+
+	s.entries[key] = memoryEntry{record: *record, expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{record: *record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
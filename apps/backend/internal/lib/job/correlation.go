@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// Correlation ties a task to the HTTP request that enqueued it. Tasks embed it
+// in their payload so the job's logs carry the same request ID as the request
+// that caused them.
+type Correlation struct {
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// withLogger returns the job logger tagged with the originating request, and a
+// context carrying it for anything the job calls into. Tasks enqueued outside
+// a request, such as from cron, get the job logger unchanged.
+func (c Correlation) withLogger(ctx context.Context, base *zerolog.Logger) (context.Context, *zerolog.Logger) {
+	if c.RequestID == "" {
+		return ctx, base
+	}
+
+	logger := base.With().Str("request_id", c.RequestID).Logger()
+	return logger.WithContext(ctx), &logger
+}
@@ -0,0 +1,101 @@
+package job_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTranscriber struct {
+	ctx context.Context
+}
+
+func (f *fakeTranscriber) TranscribeAttachment(ctx context.Context, todoID, attachmentID uuid.UUID) error {
+	f.ctx = ctx
+	return nil
+}
+
+// runTranscription processes the task the way the job server would and
+// returns the request IDs of the log lines it wrote
+func runTranscription(t *testing.T, asynqTask *asynq.Task) ([]string, *fakeTranscriber) {
+	t.Helper()
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs)
+	transcriber := &fakeTranscriber{}
+
+	jobs := job.NewJobService(&logger, &config.Config{Redis: config.RedisConfig{Address: "localhost:6379"}})
+	defer jobs.Client.Close()
+	jobs.SetTranscriber(transcriber)
+
+	require.NoError(t, jobs.Handler().ProcessTask(context.Background(), asynqTask))
+
+	var requestIDs []string
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var line struct {
+			RequestID string `json:"request_id"`
+		}
+		require.NoError(t, decoder.Decode(&line))
+		requestIDs = append(requestIDs, line.RequestID)
+	}
+	require.NotEmpty(t, requestIDs)
+
+	return requestIDs, transcriber
+}
+
+func TestTaskCorrelation(t *testing.T) {
+	t.Run("job enqueued during a request logs the request ID", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos/1/attachments", nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-123")
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var asynqTask *asynq.Task
+		err := middleware.RequestID()(func(c echo.Context) error {
+			task := &job.TranscribeAttachmentTask{TodoID: uuid.New(), AttachmentID: uuid.New()}
+			task.RequestID = middleware.GetRequestID(c)
+
+			var err error
+			asynqTask, err = task.Task()
+			return err
+		})(c)
+		require.NoError(t, err)
+
+		requestIDs, transcriber := runTranscription(t, asynqTask)
+		for _, id := range requestIDs {
+			assert.Equal(t, "req-123", id)
+		}
+
+		// The transcriber gets the correlated logger too
+		var logs bytes.Buffer
+		transcriberLogger := zerolog.Ctx(transcriber.ctx).Output(&logs)
+		transcriberLogger.Info().Msg("transcribing")
+		assert.Contains(t, logs.String(), `"request_id":"req-123"`)
+	})
+
+	t.Run("job enqueued outside a request logs without one", func(t *testing.T) {
+		task := &job.TranscribeAttachmentTask{TodoID: uuid.New(), AttachmentID: uuid.New()}
+		asynqTask, err := task.Task()
+		require.NoError(t, err)
+		assert.NotContains(t, string(asynqTask.Payload()), "request_id")
+
+		requestIDs, _ := runTranscription(t, asynqTask)
+		for _, id := range requestIDs {
+			assert.Empty(t, id)
+		}
+	})
+}
@@ -2,10 +2,12 @@ package job
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/sriniously/tasker/internal/lib/email"
 	"github.com/sriniously/tasker/internal/model/todo"
 )
 
@@ -35,12 +37,66 @@ func NewWelcomeEmailTask(to, firstName string) (*asynq.Task, error) {
 		asynq.Timeout(30*time.Second)), nil
 }
 
+const (
+	ReminderTypeDueDate = "due_date_reminder"
+	ReminderTypeOverdue = "overdue_notification"
+)
+
+// ReminderEmailTask notifies a user about their most urgent todo, listing the
+// next few by title and how many more were left out.
 type ReminderEmailTask struct {
-	UserID    string    `json:"user_id"`
-	TodoID    uuid.UUID `json:"todo_id"`
-	TodoTitle string    `json:"todo_title"`
-	DueDate   time.Time `json:"due_date"`
-	TaskType  string    `json:"task_type"` // "due_date_reminder" or "overdue_notification"
+	UserID     string    `json:"user_id"`
+	TodoID     uuid.UUID `json:"todo_id"`
+	TodoTitle  string    `json:"todo_title"`
+	DueDate    time.Time `json:"due_date"`
+	TaskType   string    `json:"task_type"` // ReminderTypeDueDate or ReminderTypeOverdue
+	OtherTodos []string  `json:"other_todos,omitempty"`
+	MoreCount  int       `json:"more_count"`
+}
+
+// NewReminderEmailTask builds the reminder for a user's notification, headed by
+// its most urgent todo. It returns nil when the notification has no todos.
+func NewReminderEmailTask(notification UserNotification, taskType string) *ReminderEmailTask {
+	if len(notification.Todos) == 0 {
+		return nil
+	}
+
+	first := notification.Todos[0]
+	others := make([]string, 0, len(notification.Todos)-1)
+	for _, item := range notification.Todos[1:] {
+		others = append(others, item.Title)
+	}
+
+	return &ReminderEmailTask{
+		UserID:     notification.UserID,
+		TodoID:     first.ID,
+		TodoTitle:  first.Title,
+		DueDate:    *first.DueDate,
+		TaskType:   taskType,
+		OtherTodos: others,
+		MoreCount:  notification.MoreCount,
+	}
+}
+
+// RenderReminderEmail renders the email sent for a reminder task. The worker
+// and the notification preview both go through it so they cannot disagree.
+func RenderReminderEmail(to string, task *ReminderEmailTask) (*email.Message, error) {
+	switch task.TaskType {
+	case ReminderTypeDueDate:
+		return email.DueDateReminderMessage(to, task.TodoTitle, task.TodoID, task.DueDate,
+			task.OtherTodos, task.MoreCount)
+	case ReminderTypeOverdue:
+		return email.OverdueNotificationMessage(to, task.TodoTitle, task.TodoID, task.DueDate,
+			task.OtherTodos, task.MoreCount)
+	default:
+		return nil, fmt.Errorf("unknown reminder task type: %s", task.TaskType)
+	}
+}
+
+// DedupeKey identifies the reminder for one user, todo and threshold so it is
+// emailed at most once per dedupe window
+func (t *ReminderEmailTask) DedupeKey() string {
+	return fmt.Sprintf("reminder:%s:%s:%s", t.UserID, t.TodoID.String(), t.TaskType)
 }
 
 func EnqueueReminderEmail(client *asynq.Client, task *ReminderEmailTask) error {
@@ -69,6 +125,34 @@ type WeeklyReportEmailTask struct {
 	OverdueTodos   []todo.PopulatedTodo `json:"overdue_todos"`
 }
 
+// NewWeeklyReportEmailTask builds the weekly report for a user's stats over
+// the week ending at weekEnd
+func NewWeeklyReportEmailTask(stats *todo.UserWeeklyStats, weekStart, weekEnd time.Time,
+	completedTodos, overdueTodos []todo.PopulatedTodo,
+) *WeeklyReportEmailTask {
+	return &WeeklyReportEmailTask{
+		UserID:         stats.UserID,
+		WeekStart:      weekStart,
+		WeekEnd:        weekEnd,
+		CompletedCount: stats.CompletedCount,
+		ActiveCount:    stats.ActiveCount,
+		OverdueCount:   stats.OverdueCount,
+		CompletedTodos: completedTodos,
+		OverdueTodos:   overdueTodos,
+	}
+}
+
+// RenderWeeklyReportEmail renders the email sent for a weekly report task
+func RenderWeeklyReportEmail(to string, task *WeeklyReportEmailTask) (*email.Message, error) {
+	return email.WeeklyReportMessage(to, task.WeekStart, task.WeekEnd, task.CompletedCount,
+		task.ActiveCount, task.OverdueCount, task.CompletedTodos, task.OverdueTodos)
+}
+
+// DedupeKey identifies the user's report for the week
+func (t *WeeklyReportEmailTask) DedupeKey() string {
+	return fmt.Sprintf("weekly_report:%s:%s", t.UserID, t.WeekStart.Format("2006-01-02"))
+}
+
 func EnqueueWeeklyReportEmail(client *asynq.Client, task *WeeklyReportEmailTask) error {
 	payload, err := json.Marshal(task)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/sriniously/tasker/internal/config"
 	"github.com/sriniously/tasker/internal/lib/email"
@@ -13,6 +14,15 @@ import (
 
 func (j *JobService) InitHandlers(config *config.Config, logger *zerolog.Logger) {
 	j.emailClient = email.NewClient(config, logger)
+
+	// Notification emails share dedupe state through Redis so every worker
+	// suppresses the same repeats
+	j.redis = redis.NewClient(&redis.Options{
+		Addr:     config.Redis.Address,
+		Password: config.Redis.Password,
+		DB:       0,
+	})
+	j.sendQueue = NewSendQueue(j.emailClient, NewRedisDeduper(j.redis), config.Notifications, logger)
 }
 
 func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task) error {
@@ -69,25 +79,12 @@ func (j *JobService) handleReminderEmailTask(ctx context.Context, t *asynq.Task)
 		return fmt.Errorf("failed to resolve user email for user %s: %w", p.UserID, err)
 	}
 
-	switch p.TaskType {
-	case "due_date_reminder":
-		err = j.emailClient.SendDueDateReminderEmail(
-			userEmail,
-			p.TodoTitle,
-			p.TodoID,
-			p.DueDate,
-		)
-	case "overdue_notification":
-		err = j.emailClient.SendOverdueNotificationEmail(
-			userEmail,
-			p.TodoTitle,
-			p.TodoID,
-			p.DueDate,
-		)
-	default:
-		return fmt.Errorf("unknown reminder task type: %s", p.TaskType)
+	msg, err := RenderReminderEmail(userEmail, &p)
+	if err != nil {
+		return err
 	}
 
+	sent, err := j.sendQueue.Send(ctx, p.DedupeKey(), *msg)
 	if err != nil {
 		j.logger.Error().
 			Str("type", p.TaskType).
@@ -102,7 +99,8 @@ func (j *JobService) handleReminderEmailTask(ctx context.Context, t *asynq.Task)
 		Str("type", p.TaskType).
 		Str("user_id", p.UserID).
 		Str("todo_id", p.TodoID.String()).
-		Msg("Successfully sent reminder email")
+		Bool("duplicate", !sent).
+		Msg("Successfully processed reminder email")
 	return nil
 }
 
@@ -130,16 +128,12 @@ func (j *JobService) handleWeeklyReportEmailTask(ctx context.Context, t *asynq.T
 		return fmt.Errorf("failed to resolve user email for user %s: %w", p.UserID, err)
 	}
 
-	err = j.emailClient.SendWeeklyReportEmail(
-		userEmail,
-		p.WeekStart,
-		p.WeekEnd,
-		p.CompletedCount,
-		p.ActiveCount,
-		p.OverdueCount,
-		p.CompletedTodos,
-		p.OverdueTodos,
-	)
+	msg, err := RenderWeeklyReportEmail(userEmail, &p)
+	if err != nil {
+		return err
+	}
+
+	sent, err := j.sendQueue.Send(ctx, p.DedupeKey(), *msg)
 	if err != nil {
 		j.logger.Error().
 			Str("type", "weekly_report").
@@ -152,6 +146,82 @@ func (j *JobService) handleWeeklyReportEmailTask(ctx context.Context, t *asynq.T
 	j.logger.Info().
 		Str("type", "weekly_report").
 		Str("user_id", p.UserID).
-		Msg("Successfully sent weekly report email")
+		Bool("duplicate", !sent).
+		Msg("Successfully processed weekly report email")
+	return nil
+}
+
+func (j *JobService) handleTranscribeAttachmentTask(ctx context.Context, t *asynq.Task) error {
+	var p TranscribeAttachmentTask
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal transcribe attachment payload: %w", err)
+	}
+
+	if j.transcriber == nil {
+		return fmt.Errorf("no transcriber configured for attachment %s", p.AttachmentID)
+	}
+
+	ctx, logger := p.withLogger(ctx, j.logger)
+
+	logger.Info().
+		Str("type", "transcribe_attachment").
+		Str("todo_id", p.TodoID.String()).
+		Str("attachment_id", p.AttachmentID.String()).
+		Msg("Processing transcribe attachment task")
+
+	if err := j.transcriber.TranscribeAttachment(ctx, p.TodoID, p.AttachmentID); err != nil {
+		logger.Error().
+			Str("type", "transcribe_attachment").
+			Str("attachment_id", p.AttachmentID.String()).
+			Err(err).
+			Msg("Failed to transcribe attachment")
+		return err
+	}
+
+	logger.Info().
+		Str("type", "transcribe_attachment").
+		Str("attachment_id", p.AttachmentID.String()).
+		Msg("Successfully transcribed attachment")
+	return nil
+}
+
+func (j *JobService) handleDeliverWebhookTask(ctx context.Context, t *asynq.Task) error {
+	var p DeliverWebhookTask
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal deliver webhook payload: %w", err)
+	}
+
+	if j.webhooks == nil {
+		return fmt.Errorf("no webhook deliverer configured for delivery %s", p.DeliveryID)
+	}
+
+	ctx, logger := p.withLogger(ctx, j.logger)
+
+	// Outside the job server, as in tests, there's no retry count and the
+	// attempt is the only one
+	retried, hasRetried := asynq.GetRetryCount(ctx)
+	maxRetry, hasMaxRetry := asynq.GetMaxRetry(ctx)
+	final := !hasRetried || !hasMaxRetry || retried >= maxRetry
+
+	logger.Info().
+		Str("type", "deliver_webhook").
+		Str("delivery_id", p.DeliveryID.String()).
+		Int("attempt", retried+1).
+		Msg("Processing deliver webhook task")
+
+	if err := j.webhooks.DeliverWebhook(ctx, p.DeliveryID, final); err != nil {
+		logger.Error().
+			Str("type", "deliver_webhook").
+			Str("delivery_id", p.DeliveryID.String()).
+			Bool("final", final).
+			Err(err).
+			Msg("Failed to deliver webhook")
+		return err
+	}
+
+	logger.Info().
+		Str("type", "deliver_webhook").
+		Str("delivery_id", p.DeliveryID.String()).
+		Msg("Successfully delivered webhook")
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/sriniously/tasker/internal/config"
 	"github.com/sriniously/tasker/internal/lib/email"
@@ -14,7 +15,11 @@ type JobService struct {
 	server      *asynq.Server
 	logger      *zerolog.Logger
 	authService AuthServiceInterface
+	transcriber TranscriberInterface
+	webhooks    WebhookDelivererInterface
 	emailClient *email.Client
+	sendQueue   *SendQueue
+	redis       *redis.Client
 }
 
 type AuthServiceInterface interface {
@@ -53,15 +58,32 @@ func (j *JobService) SetAuthService(authService AuthServiceInterface) {
 	j.authService = authService
 }
 
-func (j *JobService) Start() error {
-	// Register task handlers
+func (j *JobService) SetTranscriber(transcriber TranscriberInterface) {
+	j.transcriber = transcriber
+}
+
+func (j *JobService) SetWebhookDeliverer(webhooks WebhookDelivererInterface) {
+	j.webhooks = webhooks
+}
+
+// Handler routes each task type to its handler
+func (j *JobService) Handler() asynq.Handler {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TaskWelcome, j.handleWelcomeEmailTask)
 	mux.HandleFunc(TaskReminderEmail, j.handleReminderEmailTask)
 	mux.HandleFunc(TaskWeeklyReportEmail, j.handleWeeklyReportEmailTask)
+	mux.HandleFunc(TaskTranscribeAttachment, j.handleTranscribeAttachmentTask)
+	mux.HandleFunc(TaskDeliverWebhook, j.handleDeliverWebhookTask)
+	return mux
+}
+
+func (j *JobService) Start() error {
+	if j.sendQueue != nil {
+		j.sendQueue.Start()
+	}
 
 	j.logger.Info().Msg("Starting background job server")
-	if err := j.server.Start(mux); err != nil {
+	if err := j.server.Start(j.Handler()); err != nil {
 		return err
 	}
 
@@ -71,5 +93,11 @@ func (j *JobService) Start() error {
 func (j *JobService) Stop() {
 	j.logger.Info().Msg("Stopping background job server")
 	j.server.Shutdown()
+	if j.sendQueue != nil {
+		j.sendQueue.Stop()
+	}
+	if j.redis != nil {
+		j.redis.Close()
+	}
 	j.Client.Close()
 }
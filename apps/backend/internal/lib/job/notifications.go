@@ -0,0 +1,182 @@
+package job
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/email"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// UserNotification is the capped set of todos included in one user's notification
+type UserNotification struct {
+	UserID string
+	Todos  []todo.Todo
+	// MoreCount is how many further todos matched but were left out by the cap
+	MoreCount int
+}
+
+// PreferencesLookup returns a user's preferences, or nil when they can't be
+// loaded, in which case the user's day is taken as UTC starting at midnight
+type PreferencesLookup func(userID string) *preference.Preferences
+
+// NotificationBuilder collects todos across any number of batches and keeps
+// the most urgent maxPerUser todos for each user. Because urgency is a total
+// order, the selection does not depend on how the todos were batched. Whether
+// an all-day todo is overdue is judged in its user's own day; each user's
+// preferences are looked up once, on their first todo.
+type NotificationBuilder struct {
+	maxPerUser    int
+	now           time.Time
+	preferencesOf PreferencesLookup
+	days          map[string]userDay
+	selected      map[string][]todo.Todo
+	totals        map[string]int
+	seen          map[uuid.UUID]struct{}
+}
+
+func NewNotificationBuilder(maxPerUser int, now time.Time, preferencesOf PreferencesLookup) *NotificationBuilder {
+	return &NotificationBuilder{
+		maxPerUser:    maxPerUser,
+		now:           now,
+		preferencesOf: preferencesOf,
+		days:          make(map[string]userDay),
+		selected:      make(map[string][]todo.Todo),
+		totals:        make(map[string]int),
+		seen:          make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Add folds a batch of todos into the per-user selections. Todos already seen
+// in an earlier batch are ignored so overlapping pages are not double counted.
+func (b *NotificationBuilder) Add(todos ...todo.Todo) {
+	for _, item := range todos {
+		if item.DueDate == nil {
+			continue
+		}
+		if _, ok := b.seen[item.ID]; ok {
+			continue
+		}
+		b.seen[item.ID] = struct{}{}
+
+		day, ok := b.days[item.UserID]
+		if !ok {
+			day = b.dayOf(item.UserID)
+			b.days[item.UserID] = day
+		}
+
+		b.totals[item.UserID]++
+		selected := append(b.selected[item.UserID], item)
+
+		sort.SliceStable(selected, func(i, j int) bool {
+			return b.moreUrgent(&selected[i], &selected[j], day)
+		})
+
+		if b.maxPerUser > 0 && len(selected) > b.maxPerUser {
+			selected = selected[:b.maxPerUser]
+		}
+		b.selected[item.UserID] = selected
+	}
+}
+
+// Build returns one notification per user, ordered by user ID
+func (b *NotificationBuilder) Build() []UserNotification {
+	notifications := make([]UserNotification, 0, len(b.selected))
+	for userID, todos := range b.selected {
+		notifications = append(notifications, UserNotification{
+			UserID:    userID,
+			Todos:     todos,
+			MoreCount: b.totals[userID] - len(todos),
+		})
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].UserID < notifications[j].UserID
+	})
+
+	return notifications
+}
+
+// RenderReminderPreview renders the reminder the cron jobs would send a user
+// for these todos, built exactly as the jobs build it. Overdue todos take
+// precedence over ones due soon, and a user with neither gets the nothing-due
+// variant, which the jobs never send.
+func RenderReminderPreview(to string, overdue, dueSoon []todo.Todo, maxPerUser int,
+	now time.Time, prefs *preference.Preferences,
+) (*email.Message, error) {
+	candidates := []struct {
+		todos    []todo.Todo
+		taskType string
+	}{
+		{overdue, ReminderTypeOverdue},
+		{dueSoon, ReminderTypeDueDate},
+	}
+
+	for _, candidate := range candidates {
+		builder := NewNotificationBuilder(maxPerUser, now, func(string) *preference.Preferences {
+			return prefs
+		})
+		builder.Add(candidate.todos...)
+
+		for _, notification := range builder.Build() {
+			if task := NewReminderEmailTask(notification, candidate.taskType); task != nil {
+				return RenderReminderEmail(to, task)
+			}
+		}
+	}
+
+	return email.NothingDueMessage(to)
+}
+
+// userDay is where and when a user's day starts
+type userDay struct {
+	loc          *time.Location
+	dayStartHour int
+}
+
+func (b *NotificationBuilder) dayOf(userID string) userDay {
+	var prefs *preference.Preferences
+	if b.preferencesOf != nil {
+		prefs = b.preferencesOf(userID)
+	}
+
+	day := userDay{loc: prefs.Location()}
+	if prefs != nil {
+		day.dayStartHour = prefs.DayStartHour
+	}
+	return day
+}
+
+// moreUrgent orders todos overdue in the user's day first, then by soonest due
+// date, breaking ties by priority and finally by ID so the order is always
+// deterministic.
+func (b *NotificationBuilder) moreUrgent(x, y *todo.Todo, day userDay) bool {
+	xOverdue := x.IsOverdueAt(b.now, day.loc, day.dayStartHour)
+	yOverdue := y.IsOverdueAt(b.now, day.loc, day.dayStartHour)
+	if xOverdue != yOverdue {
+		return xOverdue
+	}
+
+	if !x.DueDate.Equal(*y.DueDate) {
+		return x.DueDate.Before(*y.DueDate)
+	}
+
+	if xRank, yRank := priorityRank(x.Priority), priorityRank(y.Priority); xRank != yRank {
+		return xRank < yRank
+	}
+
+	return x.ID.String() < y.ID.String()
+}
+
+func priorityRank(p todo.Priority) int {
+	switch p {
+	case todo.PriorityHigh:
+		return 0
+	case todo.PriorityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,174 @@
+package job_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dueTodo(userID string, due time.Time, priority todo.Priority) todo.Todo {
+	return todo.Todo{
+		UserID:   userID,
+		Title:    "todo " + due.Format(time.RFC3339),
+		Status:   todo.StatusActive,
+		Priority: priority,
+		DueDate:  &due,
+		Base:     model.Base{BaseWithId: model.BaseWithId{ID: uuid.New()}},
+	}
+}
+
+func TestNotificationBuilder(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	busyUser := "user_busy"
+	quietUser := "user_quiet"
+
+	// 12 todos for the busy user: 3 overdue and 9 due over the next hours
+	var todos []todo.Todo
+	for i := 1; i <= 3; i++ {
+		todos = append(todos, dueTodo(busyUser, now.Add(-time.Duration(i)*time.Hour), todo.PriorityLow))
+	}
+	for i := 1; i <= 9; i++ {
+		todos = append(todos, dueTodo(busyUser, now.Add(time.Duration(i)*time.Hour), todo.PriorityMedium))
+	}
+	todos = append(todos, dueTodo(quietUser, now.Add(time.Hour), todo.PriorityHigh))
+
+	build := func(batchSize int) []job.UserNotification {
+		builder := job.NewNotificationBuilder(5, now, nil)
+		for start := 0; start < len(todos); start += batchSize {
+			end := min(start+batchSize, len(todos))
+			builder.Add(todos[start:end]...)
+		}
+		return builder.Build()
+	}
+
+	t.Run("caps at N with an accurate more count", func(t *testing.T) {
+		notifications := build(100)
+		require.Len(t, notifications, 2)
+
+		busy := notifications[0]
+		assert.Equal(t, busyUser, busy.UserID)
+		require.Len(t, busy.Todos, 5)
+		assert.Equal(t, 7, busy.MoreCount)
+
+		// Overdue first, most overdue leading, then soonest due
+		assert.Equal(t, todos[2].ID, busy.Todos[0].ID)
+		assert.Equal(t, todos[1].ID, busy.Todos[1].ID)
+		assert.Equal(t, todos[0].ID, busy.Todos[2].ID)
+		assert.Equal(t, todos[3].ID, busy.Todos[3].ID)
+		assert.Equal(t, todos[4].ID, busy.Todos[4].ID)
+
+		quiet := notifications[1]
+		assert.Equal(t, quietUser, quiet.UserID)
+		assert.Len(t, quiet.Todos, 1)
+		assert.Equal(t, 0, quiet.MoreCount)
+	})
+
+	t.Run("selection does not depend on batch size", func(t *testing.T) {
+		expected := build(100)
+		for _, batchSize := range []int{1, 2, 3, 7} {
+			assert.Equal(t, expected, build(batchSize), "batch size %d", batchSize)
+		}
+	})
+
+	t.Run("todos repeated across batches are counted once", func(t *testing.T) {
+		builder := job.NewNotificationBuilder(5, now, nil)
+		builder.Add(todos...)
+		builder.Add(todos[:4]...)
+
+		notifications := builder.Build()
+		require.Len(t, notifications, 2)
+		assert.Equal(t, 7, notifications[0].MoreCount)
+	})
+
+	t.Run("ties on due date break by priority then ID", func(t *testing.T) {
+		due := now.Add(time.Hour)
+		low := dueTodo(busyUser, due, todo.PriorityLow)
+		high := dueTodo(busyUser, due, todo.PriorityHigh)
+
+		builder := job.NewNotificationBuilder(1, now, nil)
+		builder.Add(low, high)
+
+		notifications := builder.Build()
+		require.Len(t, notifications, 1)
+		assert.Equal(t, high.ID, notifications[0].Todos[0].ID)
+		assert.Equal(t, 1, notifications[0].MoreCount)
+	})
+
+	t.Run("all-day todos are overdue once the user's day is over", func(t *testing.T) {
+		// 02:00 UTC, before a user whose day starts at 04:00 has finished
+		// yesterday
+		earlyMorning := time.Date(2025, time.March, 10, 2, 0, 0, 0, time.UTC)
+		allDay := dueTodo(busyUser, time.Date(2025, time.March, 9, 0, 0, 0, 0, time.UTC), todo.PriorityHigh)
+		allDay.AllDay = true
+		late := dueTodo(busyUser, earlyMorning.Add(-time.Hour), todo.PriorityLow)
+
+		first := func(preferencesOf job.PreferencesLookup) uuid.UUID {
+			builder := job.NewNotificationBuilder(1, earlyMorning, preferencesOf)
+			builder.Add(allDay, late)
+
+			notifications := builder.Build()
+			require.Len(t, notifications, 1)
+			return notifications[0].Todos[0].ID
+		}
+
+		assert.Equal(t, allDay.ID, first(nil))
+		assert.Equal(t, late.ID, first(func(userID string) *preference.Preferences {
+			return &preference.Preferences{UserID: userID, Timezone: "UTC", DayStartHour: 4}
+		}))
+	})
+}
+
+func TestRenderReminderPreview(t *testing.T) {
+	// Templates are loaded relative to the backend root, as in the server
+	t.Chdir("../../..")
+
+	now := time.Now()
+	userID := "user_preview"
+
+	t.Run("overdue todos are listed in the overdue notification", func(t *testing.T) {
+		overdue := dueTodo(userID, now.Add(-48*time.Hour), todo.PriorityHigh)
+		overdue.Title = "File quarterly taxes"
+		alsoOverdue := dueTodo(userID, now.Add(-24*time.Hour), todo.PriorityLow)
+		alsoOverdue.Title = "Renew passport"
+		dueSoon := dueTodo(userID, now.Add(2*time.Hour), todo.PriorityMedium)
+		dueSoon.Title = "Call the plumber"
+
+		msg, err := job.RenderReminderPreview("", []todo.Todo{alsoOverdue, overdue}, []todo.Todo{dueSoon}, 5, now, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Overdue: 'File quarterly taxes' needs your attention", msg.Subject)
+		for _, body := range []string{msg.HTML, msg.Text} {
+			assert.Contains(t, body, "File quarterly taxes")
+			assert.Contains(t, body, "Renew passport")
+			assert.NotContains(t, body, "Call the plumber")
+		}
+		assert.NotContains(t, msg.Text, "<")
+	})
+
+	t.Run("todos due soon get the due date reminder", func(t *testing.T) {
+		dueSoon := dueTodo(userID, now.Add(2*time.Hour), todo.PriorityMedium)
+		dueSoon.Title = "Call the plumber"
+
+		msg, err := job.RenderReminderPreview("", nil, []todo.Todo{dueSoon}, 5, now, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Reminder: 'Call the plumber' is due soon", msg.Subject)
+		assert.Contains(t, msg.Text, "Call the plumber")
+	})
+
+	t.Run("nothing due renders the empty state", func(t *testing.T) {
+		msg, err := job.RenderReminderPreview("", nil, nil, 5, now, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "You're all caught up", msg.Subject)
+		assert.Contains(t, msg.Text, "Nothing is due or overdue right now.")
+		assert.Contains(t, msg.HTML, "Nothing is due or overdue right now.")
+	})
+}
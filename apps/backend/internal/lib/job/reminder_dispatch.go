@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReminderDispatcher enqueues reminder tasks at most once per todo, threshold
+// and scheduling window. The cron jobs go through it so a run restarted
+// partway through its batch skips the reminders the first attempt already
+// dispatched.
+type ReminderDispatcher struct {
+	deduper Deduper
+	window  time.Duration
+	enqueue func(task *ReminderEmailTask) error
+}
+
+func NewReminderDispatcher(deduper Deduper, window time.Duration,
+	enqueue func(task *ReminderEmailTask) error,
+) *ReminderDispatcher {
+	return &ReminderDispatcher{
+		deduper: deduper,
+		window:  window,
+		enqueue: enqueue,
+	}
+}
+
+// DispatchKey identifies the reminder for a todo and threshold within the
+// window starting at windowStart
+func (t *ReminderEmailTask) DispatchKey(windowStart time.Time) string {
+	return fmt.Sprintf("reminder:dispatch:%s:%s:%d", t.TodoID.String(), t.TaskType, windowStart.Unix())
+}
+
+// Dispatch claims the task's marker for the window holding now, then enqueues
+// it. It returns false without enqueueing when the marker was already
+// claimed, and releases the marker when enqueueing fails so a later run can
+// retry.
+func (d *ReminderDispatcher) Dispatch(ctx context.Context, task *ReminderEmailTask, now time.Time) (bool, error) {
+	key := task.DispatchKey(now.Truncate(d.window))
+
+	claimed, err := d.deduper.Claim(ctx, key, d.window)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim dispatch key %s: %w", key, err)
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	if err := d.enqueue(task); err != nil {
+		if releaseErr := d.deduper.Release(ctx, key); releaseErr != nil {
+			return false, fmt.Errorf("%w (and failed to release dispatch key %s: %v)", err, key, releaseErr)
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderDispatcher(t *testing.T) {
+	ctx := context.Background()
+	runAt := time.Date(2025, time.March, 10, 9, 0, 5, 0, time.UTC)
+
+	tasks := make([]*job.ReminderEmailTask, 4)
+	for i := range tasks {
+		tasks[i] = &job.ReminderEmailTask{
+			UserID:   uuid.New().String(),
+			TodoID:   uuid.New(),
+			TaskType: job.ReminderTypeDueDate,
+		}
+	}
+
+	newDispatcher := func(now *time.Time, enqueued *[]uuid.UUID, fail func(*job.ReminderEmailTask) bool) *job.ReminderDispatcher {
+		deduper := job.NewMemoryDeduper(func() time.Time { return *now })
+		return job.NewReminderDispatcher(deduper, time.Hour, func(task *job.ReminderEmailTask) error {
+			if fail != nil && fail(task) {
+				return errors.New("redis unavailable")
+			}
+			*enqueued = append(*enqueued, task.TodoID)
+			return nil
+		})
+	}
+
+	t.Run("a restarted run skips reminders already dispatched", func(t *testing.T) {
+		now := runAt
+		var enqueued []uuid.UUID
+		dispatcher := newDispatcher(&now, &enqueued, nil)
+
+		// The first attempt dies after dispatching half the batch
+		for _, task := range tasks[:2] {
+			dispatched, err := dispatcher.Dispatch(ctx, task, now)
+			require.NoError(t, err)
+			assert.True(t, dispatched)
+		}
+
+		// The restart re-runs the whole batch a few minutes later
+		now = runAt.Add(10 * time.Minute)
+		for i, task := range tasks {
+			dispatched, err := dispatcher.Dispatch(ctx, task, now)
+			require.NoError(t, err)
+			assert.Equal(t, i >= 2, dispatched, "task %d", i)
+		}
+
+		assert.Equal(t, []uuid.UUID{tasks[0].TodoID, tasks[1].TodoID, tasks[2].TodoID, tasks[3].TodoID}, enqueued)
+	})
+
+	t.Run("the next window dispatches again", func(t *testing.T) {
+		now := runAt
+		var enqueued []uuid.UUID
+		dispatcher := newDispatcher(&now, &enqueued, nil)
+
+		_, err := dispatcher.Dispatch(ctx, tasks[0], now)
+		require.NoError(t, err)
+
+		now = runAt.Add(time.Hour)
+		dispatched, err := dispatcher.Dispatch(ctx, tasks[0], now)
+		require.NoError(t, err)
+		assert.True(t, dispatched)
+		assert.Len(t, enqueued, 2)
+	})
+
+	t.Run("thresholds are dispatched independently", func(t *testing.T) {
+		now := runAt
+		var enqueued []uuid.UUID
+		dispatcher := newDispatcher(&now, &enqueued, nil)
+
+		overdue := *tasks[0]
+		overdue.TaskType = job.ReminderTypeOverdue
+
+		for _, task := range []*job.ReminderEmailTask{tasks[0], &overdue} {
+			dispatched, err := dispatcher.Dispatch(ctx, task, now)
+			require.NoError(t, err)
+			assert.True(t, dispatched)
+		}
+	})
+
+	t.Run("a failed enqueue is retried by the restarted run", func(t *testing.T) {
+		now := runAt
+		var enqueued []uuid.UUID
+		failing := true
+		dispatcher := newDispatcher(&now, &enqueued, func(*job.ReminderEmailTask) bool { return failing })
+
+		dispatched, err := dispatcher.Dispatch(ctx, tasks[0], now)
+		assert.Error(t, err)
+		assert.False(t, dispatched)
+
+		failing = false
+		dispatched, err = dispatcher.Dispatch(ctx, tasks[0], now)
+		require.NoError(t, err)
+		assert.True(t, dispatched)
+		assert.Equal(t, []uuid.UUID{tasks[0].TodoID}, enqueued)
+	})
+}
@@ -0,0 +1,311 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/email"
+	"golang.org/x/time/rate"
+)
+
+var ErrSendQueueStopped = errors.New("send queue is stopped")
+
+// BatchSender delivers a batch of rendered emails in one call
+type BatchSender interface {
+	SendBatch(ctx context.Context, messages []email.Message) error
+}
+
+// Deduper remembers which notifications were sent recently
+type Deduper interface {
+	// Claim records key for window and reports false if it was already claimed
+	Claim(ctx context.Context, key string, window time.Duration) (bool, error)
+	// Release forgets key so a failed send can be retried
+	Release(ctx context.Context, key string) error
+}
+
+type sendRequest struct {
+	key     string
+	message email.Message
+	result  chan error
+}
+
+// SendQueue batches outgoing notification emails and throttles them to the
+// configured per-minute rate. A notification whose dedupe key was already
+// sent within the window is dropped instead of being emailed again.
+type SendQueue struct {
+	sender        BatchSender
+	deduper       Deduper
+	limiter       *rate.Limiter
+	batchSize     int
+	flushInterval time.Duration
+	dedupeWindow  time.Duration
+	logger        *zerolog.Logger
+
+	requests chan *sendRequest
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+}
+
+func NewSendQueue(sender BatchSender, deduper Deduper, cfg *config.NotificationsConfig,
+	logger *zerolog.Logger,
+) *SendQueue {
+	defaults := config.DefaultNotificationsConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+
+	perMinute := cfg.SendRatePerMinute
+	if perMinute <= 0 {
+		perMinute = defaults.SendRatePerMinute
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaults.BatchSize
+	}
+	// A batch can never be larger than what the rate allows in a burst
+	if batchSize > perMinute {
+		batchSize = perMinute
+	}
+
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaults.FlushInterval
+	}
+
+	dedupeWindow := cfg.DedupeWindow
+	if dedupeWindow <= 0 {
+		dedupeWindow = defaults.DedupeWindow
+	}
+
+	return &SendQueue{
+		sender:        sender,
+		deduper:       deduper,
+		limiter:       rate.NewLimiter(rate.Limit(float64(perMinute)/60), batchSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		dedupeWindow:  dedupeWindow,
+		logger:        logger,
+		requests:      make(chan *sendRequest),
+	}
+}
+
+// Start runs the batching loop until Stop is called
+func (q *SendQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stop != nil {
+		return
+	}
+
+	q.stop = make(chan struct{})
+	q.done = make(chan struct{})
+
+	go q.run(q.stop, q.done)
+}
+
+// Stop sends whatever is pending and waits for the loop to exit
+func (q *SendQueue) Stop() {
+	q.mu.Lock()
+	stop, done := q.stop, q.done
+	q.stop = nil
+	q.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// Send queues message and blocks until the batch carrying it has been sent.
+// It returns false without sending when key was already sent within the
+// dedupe window.
+func (q *SendQueue) Send(ctx context.Context, key string, message email.Message) (bool, error) {
+	claimed, err := q.deduper.Claim(ctx, key, q.dedupeWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim dedupe key %s: %w", key, err)
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	q.mu.Lock()
+	stop := q.stop
+	q.mu.Unlock()
+
+	req := &sendRequest{
+		key:     key,
+		message: message,
+		result:  make(chan error, 1),
+	}
+
+	release := func() {
+		if err := q.deduper.Release(context.Background(), key); err != nil {
+			q.logger.Warn().Err(err).Str("dedupe_key", key).Msg("failed to release dedupe key")
+		}
+	}
+
+	if stop == nil {
+		release()
+		return false, ErrSendQueueStopped
+	}
+
+	select {
+	case q.requests <- req:
+	case <-stop:
+		release()
+		return false, ErrSendQueueStopped
+	case <-ctx.Done():
+		release()
+		return false, ctx.Err()
+	}
+
+	select {
+	case err := <-req.result:
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (q *SendQueue) run(stop, done chan struct{}) {
+	defer close(done)
+
+	var (
+		pending []*sendRequest
+		timer   *time.Timer
+		flushC  <-chan time.Time
+	)
+
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, flushC = nil, nil
+		}
+		if len(pending) > 0 {
+			q.flush(pending)
+			pending = nil
+		}
+	}
+
+	for {
+		select {
+		case req := <-q.requests:
+			pending = append(pending, req)
+			if len(pending) >= q.batchSize {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(q.flushInterval)
+				flushC = timer.C
+			}
+		case <-flushC:
+			timer, flushC = nil, nil
+			flush()
+		case <-stop:
+			flush()
+			return
+		}
+	}
+}
+
+// flush waits for the rate limiter to allow the whole batch and sends it,
+// releasing the dedupe keys if the send fails so the emails can be retried
+func (q *SendQueue) flush(batch []*sendRequest) {
+	ctx := context.Background()
+
+	err := q.limiter.WaitN(ctx, len(batch))
+	if err == nil {
+		messages := make([]email.Message, 0, len(batch))
+		for _, req := range batch {
+			messages = append(messages, req.message)
+		}
+		err = q.sender.SendBatch(ctx, messages)
+	}
+
+	if err != nil {
+		q.logger.Error().Err(err).Int("batch_size", len(batch)).Msg("failed to send notification batch")
+		for _, req := range batch {
+			if releaseErr := q.deduper.Release(ctx, req.key); releaseErr != nil {
+				q.logger.Warn().Err(releaseErr).Str("dedupe_key", req.key).Msg("failed to release dedupe key")
+			}
+		}
+	} else {
+		q.logger.Info().Int("batch_size", len(batch)).Msg("sent notification batch")
+	}
+
+	for _, req := range batch {
+		req.result <- err
+	}
+}
+
+// RedisDeduper keeps dedupe keys in Redis so every worker shares them
+type RedisDeduper struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisDeduper(client *redis.Client) *RedisDeduper {
+	return &RedisDeduper{
+		client: client,
+		prefix: "tasker:notification:sent:",
+	}
+}
+
+func (d *RedisDeduper) Claim(ctx context.Context, key string, window time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, d.prefix+key, 1, window).Result()
+}
+
+func (d *RedisDeduper) Release(ctx context.Context, key string) error {
+	return d.client.Del(ctx, d.prefix+key).Err()
+}
+
+// MemoryDeduper keeps dedupe keys in process, for a single worker or tests
+type MemoryDeduper struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+func NewMemoryDeduper(now func() time.Time) *MemoryDeduper {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryDeduper{
+		expires: make(map[string]time.Time),
+		now:     now,
+	}
+}
+
+func (d *MemoryDeduper) Claim(ctx context.Context, key string, window time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if expiresAt, ok := d.expires[key]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	d.expires[key] = now.Add(window)
+	return true, nil
+}
+
+func (d *MemoryDeduper) Release(ctx context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.expires, key)
+	return nil
+}
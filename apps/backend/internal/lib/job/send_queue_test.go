@@ -0,0 +1,198 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/email"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBatchSender struct {
+	mu      sync.Mutex
+	batches [][]email.Message
+	sentAt  []time.Time
+	err     error
+}
+
+func (f *fakeBatchSender) SendBatch(ctx context.Context, messages []email.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches = append(f.batches, messages)
+	f.sentAt = append(f.sentAt, time.Now())
+	return f.err
+}
+
+func (f *fakeBatchSender) messageCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, batch := range f.batches {
+		count += len(batch)
+	}
+	return count
+}
+
+func newTestSendQueue(sender job.BatchSender, cfg *config.NotificationsConfig) *job.SendQueue {
+	logger := zerolog.Nop()
+	q := job.NewSendQueue(sender, job.NewMemoryDeduper(nil), cfg, &logger)
+	q.Start()
+	return q
+}
+
+func TestSendQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("duplicate reminders within the window collapse to one send", func(t *testing.T) {
+		sender := &fakeBatchSender{}
+		q := newTestSendQueue(sender, &config.NotificationsConfig{
+			SendRatePerMinute: 6000,
+			BatchSize:         10,
+			FlushInterval:     20 * time.Millisecond,
+			DedupeWindow:      time.Hour,
+		})
+		defer q.Stop()
+
+		task := &job.ReminderEmailTask{
+			UserID:   "user_1",
+			TodoID:   uuid.New(),
+			TaskType: "due_date_reminder",
+		}
+		msg := email.Message{To: "user@example.com", Subject: "Reminder"}
+
+		var wg sync.WaitGroup
+		results := make([]bool, 3)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sent, err := q.Send(ctx, task.DedupeKey(), msg)
+				assert.NoError(t, err)
+				results[i] = sent
+			}(i)
+		}
+		wg.Wait()
+
+		sentCount := 0
+		for _, sent := range results {
+			if sent {
+				sentCount++
+			}
+		}
+		assert.Equal(t, 1, sentCount)
+		assert.Equal(t, 1, sender.messageCount())
+
+		sent, err := q.Send(ctx, task.DedupeKey(), msg)
+		require.NoError(t, err)
+		assert.False(t, sent)
+
+		// A different threshold for the same todo is a separate notification
+		overdue := *task
+		overdue.TaskType = "overdue_notification"
+		sent, err = q.Send(ctx, overdue.DedupeKey(), msg)
+		require.NoError(t, err)
+		assert.True(t, sent)
+		assert.Equal(t, 2, sender.messageCount())
+	})
+
+	t.Run("pending emails are sent together in one batch", func(t *testing.T) {
+		sender := &fakeBatchSender{}
+		q := newTestSendQueue(sender, &config.NotificationsConfig{
+			SendRatePerMinute: 6000,
+			BatchSize:         3,
+			FlushInterval:     time.Minute,
+			DedupeWindow:      time.Hour,
+		})
+		defer q.Stop()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := q.Send(ctx, uuid.NewString(), email.Message{To: "user@example.com"})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		require.Len(t, sender.batches, 1)
+		assert.Len(t, sender.batches[0], 3)
+	})
+
+	t.Run("rate limit spaces out sends", func(t *testing.T) {
+		sender := &fakeBatchSender{}
+		// 1200 per minute allows one email every 50ms
+		q := newTestSendQueue(sender, &config.NotificationsConfig{
+			SendRatePerMinute: 1200,
+			BatchSize:         1,
+			FlushInterval:     time.Millisecond,
+			DedupeWindow:      time.Hour,
+		})
+		defer q.Stop()
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			sent, err := q.Send(ctx, uuid.NewString(), email.Message{To: "user@example.com"})
+			require.NoError(t, err)
+			require.True(t, sent)
+		}
+
+		require.Len(t, sender.sentAt, 4)
+		assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+		for i := 1; i < len(sender.sentAt); i++ {
+			assert.GreaterOrEqual(t, sender.sentAt[i].Sub(sender.sentAt[i-1]), 40*time.Millisecond)
+		}
+	})
+
+	t.Run("failed send releases the dedupe key for a retry", func(t *testing.T) {
+		sender := &fakeBatchSender{err: errors.New("resend unavailable")}
+		q := newTestSendQueue(sender, &config.NotificationsConfig{
+			SendRatePerMinute: 6000,
+			BatchSize:         1,
+			FlushInterval:     time.Millisecond,
+			DedupeWindow:      time.Hour,
+		})
+		defer q.Stop()
+
+		_, err := q.Send(ctx, "reminder:retry", email.Message{To: "user@example.com"})
+		require.Error(t, err)
+
+		sender.mu.Lock()
+		sender.err = nil
+		sender.mu.Unlock()
+
+		sent, err := q.Send(ctx, "reminder:retry", email.Message{To: "user@example.com"})
+		require.NoError(t, err)
+		assert.True(t, sent)
+	})
+}
+
+func TestMemoryDeduper(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	deduper := job.NewMemoryDeduper(func() time.Time { return now })
+
+	claimed, err := deduper.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = deduper.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	now = now.Add(time.Hour)
+	claimed, err = deduper.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,60 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+const TaskTranscribeAttachment = "attachment:transcribe"
+
+// TranscriberInterface transcribes a stored attachment and saves the text
+type TranscriberInterface interface {
+	TranscribeAttachment(ctx context.Context, todoID, attachmentID uuid.UUID) error
+}
+
+type TranscribeAttachmentTask struct {
+	Correlation
+	TodoID       uuid.UUID `json:"todo_id"`
+	AttachmentID uuid.UUID `json:"attachment_id"`
+}
+
+// NewTranscribeAttachmentTask returns the transcription task for an audio
+// attachment, or nil for any other kind of file
+func NewTranscribeAttachmentTask(attachment *todo.TodoAttachment) *TranscribeAttachmentTask {
+	if attachment == nil || !attachment.IsAudio() {
+		return nil
+	}
+
+	return &TranscribeAttachmentTask{
+		TodoID:       attachment.TodoID,
+		AttachmentID: attachment.ID,
+	}
+}
+
+// Task encodes the transcription as an asynq task on the low priority queue
+func (t *TranscribeAttachmentTask) Task() (*asynq.Task, error) {
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTranscribeAttachment, payload,
+		asynq.MaxRetry(3),
+		asynq.Queue("low"),
+		asynq.Timeout(5*time.Minute)), nil
+}
+
+func EnqueueTranscribeAttachment(client *asynq.Client, task *TranscribeAttachmentTask) error {
+	asynqTask, err := task.Task()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Enqueue(asynqTask)
+	return err
+}
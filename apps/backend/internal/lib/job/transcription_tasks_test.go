@@ -0,0 +1,47 @@
+package job_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTranscribeAttachmentTask(t *testing.T) {
+	attachment := func(mimeType string) *todo.TodoAttachment {
+		return &todo.TodoAttachment{
+			Base:     model.Base{BaseWithId: model.BaseWithId{ID: uuid.New()}},
+			TodoID:   uuid.New(),
+			MimeType: &mimeType,
+		}
+	}
+
+	t.Run("audio upload enqueues a transcription task", func(t *testing.T) {
+		for _, mimeType := range []string{"audio/mpeg", "audio/wave", "application/ogg"} {
+			voiceMemo := attachment(mimeType)
+
+			task := job.NewTranscribeAttachmentTask(voiceMemo)
+			require.NotNil(t, task, mimeType)
+
+			asynqTask, err := task.Task()
+			require.NoError(t, err)
+			assert.Equal(t, job.TaskTranscribeAttachment, asynqTask.Type())
+
+			var payload job.TranscribeAttachmentTask
+			require.NoError(t, json.Unmarshal(asynqTask.Payload(), &payload))
+			assert.Equal(t, voiceMemo.TodoID, payload.TodoID)
+			assert.Equal(t, voiceMemo.ID, payload.AttachmentID)
+		}
+	})
+
+	t.Run("other files are not transcribed", func(t *testing.T) {
+		assert.Nil(t, job.NewTranscribeAttachmentTask(attachment("application/pdf")))
+		assert.Nil(t, job.NewTranscribeAttachmentTask(attachment("image/png")))
+		assert.Nil(t, job.NewTranscribeAttachmentTask(&todo.TodoAttachment{}))
+	})
+}
@@ -0,0 +1,47 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const TaskDeliverWebhook = "webhook:deliver"
+
+// WebhookDelivererInterface posts a recorded webhook delivery once. final is
+// set on the last attempt, after which a failure is not retried.
+type WebhookDelivererInterface interface {
+	DeliverWebhook(ctx context.Context, deliveryID uuid.UUID, final bool) error
+}
+
+type DeliverWebhookTask struct {
+	Correlation
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// Task encodes the delivery as an asynq task tried up to maxAttempts times,
+// asynq backing off exponentially between them
+func (t *DeliverWebhookTask) Task(maxAttempts int) (*asynq.Task, error) {
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskDeliverWebhook, payload,
+		asynq.MaxRetry(max(maxAttempts-1, 0)),
+		asynq.Queue("default"),
+		asynq.Timeout(time.Minute)), nil
+}
+
+func EnqueueDeliverWebhook(client *asynq.Client, task *DeliverWebhookTask, maxAttempts int) error {
+	asynqTask, err := task.Task(maxAttempts)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Enqueue(asynqTask)
+	return err
+}
@@ -0,0 +1,52 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhookDeliverer struct {
+	deliveryID uuid.UUID
+	final      bool
+}
+
+func (f *fakeWebhookDeliverer) DeliverWebhook(ctx context.Context, deliveryID uuid.UUID, final bool) error {
+	f.deliveryID = deliveryID
+	f.final = final
+	return nil
+}
+
+func TestDeliverWebhookTask(t *testing.T) {
+	task := &job.DeliverWebhookTask{DeliveryID: uuid.New()}
+	task.RequestID = "req-123"
+
+	asynqTask, err := task.Task(6)
+	require.NoError(t, err)
+	assert.Equal(t, job.TaskDeliverWebhook, asynqTask.Type())
+
+	var payload job.DeliverWebhookTask
+	require.NoError(t, json.Unmarshal(asynqTask.Payload(), &payload))
+	assert.Equal(t, task.DeliveryID, payload.DeliveryID)
+	assert.Equal(t, "req-123", payload.RequestID)
+
+	t.Run("an attempt outside the job server is the last", func(t *testing.T) {
+		logger := zerolog.Nop()
+		deliverer := &fakeWebhookDeliverer{}
+
+		jobs := job.NewJobService(&logger, &config.Config{Redis: config.RedisConfig{Address: "localhost:6379"}})
+		defer jobs.Client.Close()
+		jobs.SetWebhookDeliverer(deliverer)
+
+		require.NoError(t, jobs.Handler().ProcessTask(context.Background(), asynqTask))
+		assert.Equal(t, task.DeliveryID, deliverer.deliveryID)
+		assert.True(t, deliverer.final)
+	})
+}
@@ -0,0 +1,381 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// MultipartFiles is implemented by payloads whose handler reads uploaded
+// files besides the bound form fields, naming the file fields
+type MultipartFiles interface {
+	MultipartFiles() []string
+}
+
+// Schemas turns Go types into schemas. Named struct types become components,
+// so each is described once and recursive types terminate.
+type Schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// Components returns the component schemas collected so far
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+// Of returns the schema of t as encoding/json writes it
+func (s *Schemas) Of(t reflect.Type) *Schema {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		schema := s.Of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.Of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.Of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, jsonName)
+		}
+		return s.component(t, func() *Schema { return s.object(t, jsonName) })
+	default:
+		return &Schema{}
+	}
+}
+
+// component registers the named type's schema, built by build, and returns a
+// reference to it
+func (s *Schemas) component(t reflect.Type, build func() *Schema) *Schema {
+	if name, ok := s.names[t]; ok {
+		return Ref(name)
+	}
+
+	name := componentName(t)
+	for i := 2; s.components[name] != nil; i++ {
+		name = componentName(t) + strconv.Itoa(i)
+	}
+
+	// Registered before it's built so a type that contains itself refers back
+	s.names[t] = name
+	s.components[name] = &Schema{}
+	*s.components[name] = *build()
+
+	return Ref(name)
+}
+
+var importPath = regexp.MustCompile(`[\w.\-]+/`)
+
+// componentName names a type after its package and type name, e.g.
+// todo.Todo, with type arguments joined by underscores
+func componentName(t reflect.Type) string {
+	name := t.String()
+	if open := strings.Index(t.Name(), "["); open >= 0 {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = pkg + "." + importPath.ReplaceAllString(t.Name(), "")
+	}
+
+	return strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "", " ", "").Replace(name)
+}
+
+// field is a struct field reached through any embedded structs
+type field struct {
+	reflect.StructField
+	name string
+}
+
+// fields lists t's fields the way encoding/json sees them: embedded structs
+// are flattened into their parent, and a field shadows deeper ones of the
+// same name. nameOf returns a field's name under the tag being read, or ""
+// when the field isn't included.
+func fields(t reflect.Type, nameOf func(reflect.StructField) string) []field {
+	var result []field
+	seen := map[string]bool{}
+
+	current := []reflect.Type{t}
+	for len(current) > 0 {
+		var next []reflect.Type
+		var level []field
+
+		for _, st := range current {
+			for i := range st.NumField() {
+				f := st.Field(i)
+
+				embedded := f.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				if f.Anonymous && embedded.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+					next = append(next, embedded)
+					continue
+				}
+
+				if !f.IsExported() {
+					continue
+				}
+
+				if name := nameOf(f); name != "" && !seen[name] {
+					level = append(level, field{StructField: f, name: name})
+				}
+			}
+		}
+
+		for _, f := range level {
+			seen[f.name] = true
+		}
+		result = append(result, level...)
+		current = next
+	}
+
+	return result
+}
+
+// jsonName is the name encoding/json writes the field under
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// taggedName is the field's name under tag, for fields that have one
+func taggedName(tag string) func(reflect.StructField) string {
+	return func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	}
+}
+
+// object describes the struct's fields named by nameOf as an object
+func (s *Schemas) object(t reflect.Type, nameOf func(reflect.StructField) string) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for _, f := range fields(t, nameOf) {
+		property, required := s.property(f.StructField)
+		if strings.Contains(f.Tag.Get("json"), ",string") {
+			property = &Schema{Type: "string"}
+		}
+
+		schema.Properties[f.name] = property
+		if required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+
+	return schema
+}
+
+// property returns a field's schema narrowed by its validate tag, and
+// whether the tag requires it
+func (s *Schemas) property(f reflect.StructField) (*Schema, bool) {
+	schema := s.Of(f.Type)
+	required := false
+
+	target := schema
+	inKeys := false
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch {
+		case name == "keys":
+			inKeys = true
+		case name == "endkeys":
+			inKeys = false
+		case inKeys || strings.Contains(rule, "|"):
+		case name == "dive":
+			target = target.Items
+			if target == nil {
+				// Maps dive into their values
+				target = schema.AdditionalProperties
+			}
+			if target == nil {
+				return schema, required
+			}
+		case name == "required" && target == schema:
+			required = true
+		default:
+			constrain(target, name, param)
+		}
+	}
+
+	return schema, required
+}
+
+// constrain applies a validation rule to the schema, ignoring rules OpenAPI
+// can't express
+func constrain(schema *Schema, rule string, param string) {
+	if schema.Ref != "" {
+		return
+	}
+
+	number, err := strconv.ParseFloat(param, 64)
+	isNumber := err == nil
+
+	switch rule {
+	case "min", "max", "len", "gte", "lte", "gt", "lt":
+		if !isNumber {
+			return
+		}
+
+		switch schema.Type {
+		case "string":
+			length := int(number)
+			if rule == "min" || rule == "len" || rule == "gte" {
+				schema.MinLength = &length
+			}
+			if rule == "max" || rule == "len" || rule == "lte" {
+				schema.MaxLength = &length
+			}
+		case "array":
+			count := int(number)
+			if rule == "min" || rule == "len" || rule == "gte" {
+				schema.MinItems = &count
+			}
+			if rule == "max" || rule == "len" || rule == "lte" {
+				schema.MaxItems = &count
+			}
+		case "integer", "number":
+			// Integers make strict bounds inclusive ones
+			if schema.Type == "integer" && rule == "gt" {
+				number, rule = number+1, "min"
+			}
+			if schema.Type == "integer" && rule == "lt" {
+				number, rule = number-1, "max"
+			}
+			if rule == "min" || rule == "len" || rule == "gte" {
+				schema.Minimum = &number
+			}
+			if rule == "max" || rule == "len" || rule == "lte" {
+				schema.Maximum = &number
+			}
+		}
+	case "oneof":
+		for _, value := range strings.Fields(param) {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil &&
+				(schema.Type == "integer" || schema.Type == "number") {
+				schema.Enum = append(schema.Enum, parsed)
+			} else {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+	case "unique":
+		schema.UniqueItems = schema.Type == "array"
+	case "email":
+		schema.Format = "email"
+	case "url", "http_url", "uri":
+		schema.Format = "uri"
+	case "uuid", "uuid4":
+		schema.Format = "uuid"
+	}
+}
+
+// Request is a payload type split into where each of its fields is bound from
+type Request struct {
+	Parameters []Parameter
+	// Body describes the JSON fields, and is nil when there are none
+	Body *Schema
+	// Form describes the multipart fields and files, and is nil when there
+	// are none
+	Form *Schema
+}
+
+// Request describes the payload type t binds requests into
+func (s *Schemas) Request(t reflect.Type) Request {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var request Request
+	if t.Kind() != reflect.Struct {
+		return request
+	}
+
+	for _, in := range []string{"path", "query", "header"} {
+		tag := map[string]string{"path": "param", "query": "query", "header": "header"}[in]
+		for _, f := range fields(t, taggedName(tag)) {
+			schema, required := s.property(f.StructField)
+			request.Parameters = append(request.Parameters, Parameter{
+				Name:     f.name,
+				In:       in,
+				Required: required || in == "path",
+				Schema:   schema,
+			})
+		}
+	}
+
+	if len(fields(t, taggedName("json"))) > 0 {
+		request.Body = s.component(t, func() *Schema { return s.object(t, taggedName("json")) })
+	}
+
+	form := s.object(t, taggedName("form"))
+	if files, ok := reflect.New(t).Interface().(MultipartFiles); ok {
+		for _, name := range files.MultipartFiles() {
+			form.Properties[name] = &Schema{Type: "string", Format: "binary"}
+			form.Required = append(form.Required, name)
+		}
+	}
+	if len(form.Properties) > 0 {
+		request.Form = form
+	}
+
+	return request
+}
@@ -0,0 +1,129 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/openapi"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	model.Base
+	Name     string          `json:"name"`
+	Note     *string         `json:"note,omitempty"`
+	Children []node          `json:"children"`
+	Extra    json.RawMessage `json:"extra"`
+	Hidden   string          `json:"-"`
+	internal string
+}
+
+type createNodePayload struct {
+	ParentID uuid.UUID `param:"id" validate:"required,uuid"`
+	DryRun   *bool     `query:"dryRun"`
+	Name     string    `json:"name" validate:"required,min=1,max=100"`
+	Kind     string    `json:"kind" validate:"omitempty,oneof=leaf branch"`
+	Tags     []string  `json:"tags" validate:"omitempty,max=5,unique,dive,min=2"`
+	Weight   int       `json:"weight" validate:"gt=0,lte=10"`
+}
+
+type uploadPayload struct {
+	Label string `form:"label" validate:"required"`
+}
+
+func (p *uploadPayload) MultipartFiles() []string {
+	return []string{"file"}
+}
+
+func TestSchemas_Of(t *testing.T) {
+	schemas := openapi.NewSchemas()
+
+	ref := schemas.Of(reflect.TypeFor[node]())
+	assert.Equal(t, "#/components/schemas/openapi_test.node", ref.Ref)
+
+	schema := schemas.Components()["openapi_test.node"]
+	require.NotNil(t, schema)
+
+	t.Run("embedded fields are flattened", func(t *testing.T) {
+		assert.Equal(t, &openapi.Schema{Type: "string", Format: "uuid"}, schema.Properties["id"])
+		assert.Equal(t, &openapi.Schema{Type: "string", Format: "date-time"}, schema.Properties["createdAt"])
+	})
+
+	t.Run("fields are described as encoding/json writes them", func(t *testing.T) {
+		assert.True(t, schema.Properties["note"].Nullable)
+		assert.Equal(t, &openapi.Schema{}, schema.Properties["extra"])
+		assert.NotContains(t, schema.Properties, "Hidden")
+		assert.NotContains(t, schema.Properties, "internal")
+	})
+
+	t.Run("recursive types refer back to themselves", func(t *testing.T) {
+		assert.Equal(t, ref, schema.Properties["children"].Items)
+	})
+
+	t.Run("generic types are named after their arguments", func(t *testing.T) {
+		page := schemas.Of(reflect.TypeFor[model.PaginatedResponse[node]]())
+		assert.Equal(t, "#/components/schemas/model.PaginatedResponse_openapi_test.node", page.Ref)
+	})
+
+	t.Run("times are date-times", func(t *testing.T) {
+		assert.Equal(t, "date-time", schemas.Of(reflect.TypeFor[*time.Time]()).Format)
+	})
+}
+
+func TestSchemas_Request(t *testing.T) {
+	t.Run("fields are split by where they're bound from", func(t *testing.T) {
+		schemas := openapi.NewSchemas()
+		request := schemas.Request(reflect.TypeFor[*createNodePayload]())
+
+		require.Len(t, request.Parameters, 2)
+		assert.Equal(t, "id", request.Parameters[0].Name)
+		assert.Equal(t, "path", request.Parameters[0].In)
+		assert.True(t, request.Parameters[0].Required)
+		assert.Equal(t, "dryRun", request.Parameters[1].Name)
+		assert.Equal(t, "query", request.Parameters[1].In)
+		assert.False(t, request.Parameters[1].Required)
+
+		require.NotNil(t, request.Body)
+		body := schemas.Components()["openapi_test.createNodePayload"]
+		require.NotNil(t, body)
+		assert.Len(t, body.Properties, 4)
+		assert.Equal(t, []string{"name"}, body.Required)
+		assert.Nil(t, request.Form)
+	})
+
+	t.Run("validation rules become constraints", func(t *testing.T) {
+		schemas := openapi.NewSchemas()
+		schemas.Request(reflect.TypeFor[*createNodePayload]())
+		body := schemas.Components()["openapi_test.createNodePayload"]
+
+		name := body.Properties["name"]
+		assert.Equal(t, 1, *name.MinLength)
+		assert.Equal(t, 100, *name.MaxLength)
+
+		assert.Equal(t, []any{"leaf", "branch"}, body.Properties["kind"].Enum)
+
+		tags := body.Properties["tags"]
+		assert.Equal(t, 5, *tags.MaxItems)
+		assert.True(t, tags.UniqueItems)
+		assert.Equal(t, 2, *tags.Items.MinLength)
+
+		weight := body.Properties["weight"]
+		assert.Equal(t, 1.0, *weight.Minimum)
+		assert.Equal(t, 10.0, *weight.Maximum)
+	})
+
+	t.Run("uploads are multipart forms with their files", func(t *testing.T) {
+		request := openapi.NewSchemas().Request(reflect.TypeFor[*uploadPayload]())
+
+		require.NotNil(t, request.Form)
+		assert.Nil(t, request.Body)
+		assert.Equal(t, "string", request.Form.Properties["label"].Type)
+		assert.Equal(t, "binary", request.Form.Properties["file"].Format)
+		assert.ElementsMatch(t, []string{"label", "file"}, request.Form.Required)
+	})
+}
@@ -0,0 +1,62 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/lib/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "test-secret"
+
+	t.Run("valid token round trips", func(t *testing.T) {
+		raw, err := token.Sign(secret, token.Claims{
+			Subject:   "user_target",
+			Actor:     "user_admin",
+			Purpose:   token.PurposeImpersonation,
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		})
+		require.NoError(t, err)
+
+		claims, err := token.Verify(secret, raw, token.PurposeImpersonation)
+		require.NoError(t, err)
+		assert.Equal(t, "user_target", claims.Subject)
+		assert.Equal(t, "user_admin", claims.Actor)
+	})
+
+	t.Run("wrong secret is rejected", func(t *testing.T) {
+		raw, err := token.Sign(secret, token.Claims{Subject: "user", Purpose: token.PurposeImpersonation})
+		require.NoError(t, err)
+
+		_, err = token.Verify("other-secret", raw, token.PurposeImpersonation)
+		assert.ErrorIs(t, err, token.ErrInvalidToken)
+	})
+
+	t.Run("wrong purpose is rejected", func(t *testing.T) {
+		raw, err := token.Sign(secret, token.Claims{Subject: "user", Purpose: "other"})
+		require.NoError(t, err)
+
+		_, err = token.Verify(secret, raw, token.PurposeImpersonation)
+		assert.ErrorIs(t, err, token.ErrInvalidToken)
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		raw, err := token.Sign(secret, token.Claims{
+			Subject:   "user",
+			Purpose:   token.PurposeImpersonation,
+			ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+		})
+		require.NoError(t, err)
+
+		_, err = token.Verify(secret, raw, token.PurposeImpersonation)
+		assert.ErrorIs(t, err, token.ErrExpiredToken)
+	})
+
+	t.Run("malformed token is rejected", func(t *testing.T) {
+		_, err := token.Verify(secret, "not-a-token", token.PurposeImpersonation)
+		assert.ErrorIs(t, err, token.ErrInvalidToken)
+	})
+}
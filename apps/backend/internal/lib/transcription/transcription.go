@@ -0,0 +1,50 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sriniously/tasker/internal/config"
+)
+
+const ProviderMock = "mock"
+
+// Provider turns recorded audio into text
+type Provider interface {
+	Transcribe(ctx context.Context, audio io.Reader, mimeType string) (string, error)
+}
+
+// NewProvider returns the provider named in the config, defaulting to the mock
+func NewProvider(cfg *config.TranscriptionConfig) (Provider, error) {
+	name := ProviderMock
+	if cfg != nil && cfg.Provider != "" {
+		name = cfg.Provider
+	}
+
+	switch name {
+	case ProviderMock:
+		return &MockProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown transcription provider %q", name)
+	}
+}
+
+// MockProvider stands in for a real speech-to-text service. It returns
+// Transcript when set, otherwise a placeholder describing the audio.
+type MockProvider struct {
+	Transcript string
+}
+
+func (p *MockProvider) Transcribe(ctx context.Context, audio io.Reader, mimeType string) (string, error) {
+	size, err := io.Copy(io.Discard, audio)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	if p.Transcript != "" {
+		return p.Transcript, nil
+	}
+
+	return fmt.Sprintf("[mock transcript of %d bytes of %s]", size, mimeType), nil
+}
@@ -0,0 +1,24 @@
+package transcription_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/lib/transcription"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	provider, err := transcription.NewProvider(nil)
+	require.NoError(t, err)
+
+	transcript, err := provider.Transcribe(context.Background(), strings.NewReader("RIFF...."), "audio/wave")
+	require.NoError(t, err)
+	assert.Equal(t, "[mock transcript of 8 bytes of audio/wave]", transcript)
+
+	_, err = transcription.NewProvider(&config.TranscriptionConfig{Provider: "whisper"})
+	assert.Error(t, err)
+}
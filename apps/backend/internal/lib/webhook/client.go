@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for an endpoint on a loopback, private or
+// otherwise internal address
+var ErrPrivateAddress = errors.New("webhook endpoint is on a private network address")
+
+// NewClient returns the HTTP client webhooks are posted with. Redirects are
+// not followed, and unless allowPrivate is set connections to internal
+// addresses are refused, so a webhook can't be pointed at the backend's own
+// network.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || IsPrivate(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// IsPrivate reports whether ip is one webhooks may not be sent to
+func IsPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// CheckURL rejects endpoints that aren't http(s), and unless allowPrivate is
+// set, ones naming localhost or an internal IP. Host names are checked again
+// when connecting, once they've been resolved.
+func CheckURL(raw string, allowPrivate bool) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return errors.New("webhook URL must use http or https")
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return errors.New("webhook URL must have a host")
+	}
+
+	if allowPrivate {
+		return nil
+	}
+
+	if host == "localhost" {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && IsPrivate(ip) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
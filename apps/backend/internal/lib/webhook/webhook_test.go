@@ -0,0 +1,97 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/lib/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"todo.created"}`)
+	header := webhook.Sign("whsec_test", now, body)
+
+	assert.True(t, strings.HasPrefix(header, "t=1777636800,v1="))
+
+	t.Run("accepts the signed body", func(t *testing.T) {
+		assert.NoError(t, webhook.Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Minute)))
+	})
+
+	t.Run("rejects a changed body or another secret", func(t *testing.T) {
+		assert.ErrorIs(t, webhook.Verify("whsec_test", header, []byte(`{}`), 5*time.Minute, now),
+			webhook.ErrInvalidSignature)
+		assert.ErrorIs(t, webhook.Verify("whsec_other", header, body, 5*time.Minute, now),
+			webhook.ErrInvalidSignature)
+		assert.ErrorIs(t, webhook.Verify("whsec_test", "v1=abc", body, 5*time.Minute, now),
+			webhook.ErrInvalidSignature)
+	})
+
+	t.Run("rejects an old signature", func(t *testing.T) {
+		assert.ErrorIs(t, webhook.Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Hour)),
+			webhook.ErrStaleSignature)
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+	second, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.NotEqual(t, first, second)
+}
+
+func TestCheckURL(t *testing.T) {
+	for raw, wantErr := range map[string]bool{
+		"https://hooks.example.com/tasker": false,
+		"http://hooks.example.com":         false,
+		"ftp://hooks.example.com":          true,
+		"https://localhost:8080/hook":      true,
+		"https://127.0.0.1/hook":           true,
+		"https://10.0.0.5/hook":            true,
+		"https://169.254.169.254/latest":   true,
+		"https://[::1]/hook":               true,
+	} {
+		err := webhook.CheckURL(raw, false)
+		assert.Equal(t, wantErr, err != nil, raw)
+	}
+
+	assert.NoError(t, webhook.CheckURL("http://localhost:8080/hook", true))
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	t.Run("refuses private addresses", func(t *testing.T) {
+		_, err := webhook.NewClient(time.Second, false).Get(srv.URL)
+		assert.ErrorIs(t, err, webhook.ErrPrivateAddress)
+	})
+
+	t.Run("reaches private addresses when allowed", func(t *testing.T) {
+		res, err := webhook.NewClient(time.Second, true).Get(srv.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+
+	t.Run("doesn't follow redirects", func(t *testing.T) {
+		res, err := webhook.NewClient(time.Second, true).Get(srv.URL + "/redirect")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+	})
+}
@@ -14,17 +14,32 @@ import (
 	"github.com/sriniously/tasker/internal/config"
 )
 
-// LoggerService manages New Relic integration and logger creation
+// requiredConnectTimeout bounds how long startup waits for the collector when
+// New Relic is marked as required
+const requiredConnectTimeout = 10 * time.Second
+
+// LoggerService manages New Relic integration and logger creation. Its
+// methods are safe on a nil service and on one without a New Relic app, in
+// which case telemetry calls are no-ops.
 type LoggerService struct {
-	nrApp *newrelic.Application
+	nrApp   *newrelic.Application
+	initErr error
 }
 
-// NewLoggerService creates a new logger service with New Relic integration
-func NewLoggerService(cfg *config.ObservabilityConfig) *LoggerService {
+// NewLoggerService creates a new logger service with New Relic integration.
+// Telemetry is best-effort: when the agent can't be initialized the service
+// carries on without it and the cause is kept for InitError, unless
+// new_relic.required is set, in which case the error is returned instead. The
+// agent connects in the background, so an unreachable collector never blocks
+// startup unless New Relic is required.
+func NewLoggerService(cfg *config.ObservabilityConfig) (*LoggerService, error) {
 	service := &LoggerService{}
 
 	if cfg.NewRelic.LicenseKey == "" {
-		return service
+		if cfg.NewRelic.Required {
+			return nil, fmt.Errorf("new relic is required but no license key is configured")
+		}
+		return service, nil
 	}
 
 	var configOptions []newrelic.ConfigOption
@@ -42,25 +57,57 @@ func NewLoggerService(cfg *config.ObservabilityConfig) *LoggerService {
 
 	app, err := newrelic.NewApplication(configOptions...)
 	if err != nil {
-		return service
+		if cfg.NewRelic.Required {
+			return nil, fmt.Errorf("failed to initialize new relic: %w", err)
+		}
+		service.initErr = fmt.Errorf("failed to initialize new relic: %w", err)
+		return service, nil
+	}
+
+	if cfg.NewRelic.Required {
+		if err := app.WaitForConnection(requiredConnectTimeout); err != nil {
+			app.Shutdown(time.Second)
+			return nil, fmt.Errorf("failed to connect to new relic: %w", err)
+		}
 	}
 
 	service.nrApp = app
-	return service
+	return service, nil
+}
+
+// InitError returns why New Relic could not be initialized, or nil when it
+// was initialized or deliberately left unconfigured
+func (ls *LoggerService) InitError() error {
+	if ls == nil {
+		return nil
+	}
+	return ls.initErr
 }
 
 // Shutdown shuts down New Relic
 func (ls *LoggerService) Shutdown() {
-	if ls.nrApp != nil {
+	if ls != nil && ls.nrApp != nil {
 		ls.nrApp.Shutdown(10 * time.Second)
 	}
 }
 
-// GetApplication returns the New Relic application instance
+// GetApplication returns the New Relic application instance, or nil when
+// telemetry is unavailable
 func (ls *LoggerService) GetApplication() *newrelic.Application {
+	if ls == nil {
+		return nil
+	}
 	return ls.nrApp
 }
 
+// RecordCustomEvent records a custom event when New Relic is available and
+// does nothing otherwise
+func (ls *LoggerService) RecordCustomEvent(eventType string, params map[string]interface{}) {
+	if app := ls.GetApplication(); app != nil {
+		app.RecordCustomEvent(eventType, params)
+	}
+}
+
 // NewLoggerWithService creates a logger with full config and logger service
 func NewLoggerWithService(cfg *config.ObservabilityConfig, loggerService *LoggerService) zerolog.Logger {
 	var logLevel zerolog.Level
@@ -92,8 +139,8 @@ func NewLoggerWithService(cfg *config.ObservabilityConfig, loggerService *Logger
 		baseWriter = os.Stdout
 
 		// Wrap with New Relic zerologWriter for log forwarding in production
-		if loggerService != nil && loggerService.nrApp != nil {
-			nrWriter := zerologWriter.New(baseWriter, loggerService.nrApp)
+		if app := loggerService.GetApplication(); app != nil {
+			nrWriter := zerologWriter.New(baseWriter, app)
 			writer = nrWriter
 		} else {
 			writer = baseWriter
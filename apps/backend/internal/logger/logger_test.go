@@ -0,0 +1,69 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func observabilityConfig(licenseKey string, required bool) *config.ObservabilityConfig {
+	cfg := config.DefaultObservabilityConfig()
+	cfg.Environment = "production"
+	cfg.NewRelic.LicenseKey = licenseKey
+	cfg.NewRelic.Required = required
+	return cfg
+}
+
+func TestNewLoggerService(t *testing.T) {
+	t.Run("invalid license key degrades to no-op telemetry", func(t *testing.T) {
+		cfg := observabilityConfig("not-a-valid-license", false)
+
+		service, err := logger.NewLoggerService(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, service)
+
+		assert.Nil(t, service.GetApplication())
+		assert.Error(t, service.InitError())
+
+		assert.NotPanics(t, func() {
+			service.RecordCustomEvent("TestEvent", map[string]interface{}{"key": "value"})
+			log := logger.NewLoggerWithService(cfg, service)
+			log.Info().Msg("logging without new relic")
+			service.Shutdown()
+		})
+	})
+
+	t.Run("missing license key disables telemetry without a warning", func(t *testing.T) {
+		service, err := logger.NewLoggerService(observabilityConfig("", false))
+		require.NoError(t, err)
+
+		assert.Nil(t, service.GetApplication())
+		assert.NoError(t, service.InitError())
+	})
+
+	t.Run("required new relic fails on an invalid license key", func(t *testing.T) {
+		service, err := logger.NewLoggerService(observabilityConfig("not-a-valid-license", true))
+		require.Error(t, err)
+		assert.Nil(t, service)
+	})
+
+	t.Run("required new relic fails without a license key", func(t *testing.T) {
+		_, err := logger.NewLoggerService(observabilityConfig("", true))
+		require.Error(t, err)
+		assert.Error(t, observabilityConfig("", true).Validate())
+	})
+}
+
+func TestLoggerService_NilIsNoOp(t *testing.T) {
+	var service *logger.LoggerService
+
+	assert.NotPanics(t, func() {
+		assert.Nil(t, service.GetApplication())
+		assert.NoError(t, service.InitError())
+		service.RecordCustomEvent("TestEvent", nil)
+		service.Shutdown()
+	})
+}
@@ -1,24 +1,134 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
 	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/database"
 	"github.com/sriniously/tasker/internal/errs"
 	"github.com/sriniously/tasker/internal/server"
 )
 
+// AccountStatusLookup reports whether a user's account has been deleted
+type AccountStatusLookup interface {
+	IsAccountDeleted(ctx context.Context, userID string) (bool, error)
+}
+
+// maxCachedAccountStatuses bounds how many accounts CachedAccountStatus
+// remembers; when full, expired entries are dropped, and if none have
+// expired it starts over
+const maxCachedAccountStatuses = 10000
+
+// CachedAccountStatus remembers what lookup said about an account for ttl,
+// so authenticated requests don't each cost a query. Deleting or recovering
+// an account takes up to ttl to be seen.
+type CachedAccountStatus struct {
+	lookup AccountStatusLookup
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]accountStatus
+}
+
+type accountStatus struct {
+	deleted   bool
+	expiresAt time.Time
+}
+
+func NewCachedAccountStatus(lookup AccountStatusLookup, ttl time.Duration,
+	now func() time.Time,
+) *CachedAccountStatus {
+	if now == nil {
+		now = time.Now
+	}
+	return &CachedAccountStatus{
+		lookup:  lookup,
+		ttl:     ttl,
+		now:     now,
+		entries: make(map[string]accountStatus),
+	}
+}
+
+func (s *CachedAccountStatus) IsAccountDeleted(ctx context.Context, userID string) (bool, error) {
+	s.mu.Lock()
+	status, ok := s.entries[userID]
+	s.mu.Unlock()
+
+	if ok && s.now().Before(status.expiresAt) {
+		return status.deleted, nil
+	}
+
+	deleted, err := s.lookup.IsAccountDeleted(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.entries) >= maxCachedAccountStatuses {
+		for id, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, id)
+			}
+		}
+		if len(s.entries) >= maxCachedAccountStatuses {
+			clear(s.entries)
+		}
+	}
+	s.entries[userID] = accountStatus{deleted: deleted, expiresAt: now.Add(s.ttl)}
+
+	return deleted, nil
+}
+
 type AuthMiddleware struct {
-	server *server.Server
+	server        *server.Server
+	impersonation *ImpersonationMiddleware
+	accounts      AccountStatusLookup
 }
 
-func NewAuthMiddleware(s *server.Server) *AuthMiddleware {
+func NewAuthMiddleware(s *server.Server, impersonation *ImpersonationMiddleware,
+	accounts AccountStatusLookup,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		server: s,
+		server:        s,
+		impersonation: impersonation,
+		accounts:      accounts,
+	}
+}
+
+// AccessTokenParam is the query parameter a session token may be passed in
+// where the client can't set the Authorization header
+const AccessTokenParam = "access_token"
+
+// TokenFromQuery moves a session token in the access_token query parameter
+// into the Authorization header, and out of the URL so it isn't logged. It
+// must run before RequireAuth, and only on routes that need it.
+func (auth *AuthMiddleware) TokenFromQuery(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		query := req.URL.Query()
+		if !query.Has(AccessTokenParam) {
+			return next(c)
+		}
+
+		if req.Header.Get(echo.HeaderAuthorization) == "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+query.Get(AccessTokenParam))
+		}
+
+		query.Del(AccessTokenParam)
+		req.URL.RawQuery = query.Encode()
+		req.RequestURI = req.URL.RequestURI()
+
+		return next(c)
 	}
 }
 
@@ -31,12 +141,9 @@ func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
 
-				response := map[string]string{
-					"code":     "UNAUTHORIZED",
-					"message":  "Unauthorized",
-					"override": "false",
-					"status":   "401",
-				}
+				// Written here rather than by the error handler, so it's put in
+				// the same envelope by hand
+				response := errs.NewUnauthorizedError("Unauthorized", false).Envelope()
 
 				if err := json.NewEncoder(w).Encode(response); err != nil {
 					auth.server.Logger.Error().Err(err).Str("function", "RequireAuth").Dur(
@@ -69,6 +176,88 @@ func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc
 			Dur("duration", time.Since(start)).
 			Msg("user authenticated successfully")
 
-		return next(c)
+		handler := auth.RejectDeletedAccounts(scopeToTenant(next))
+		if auth.impersonation != nil {
+			return auth.impersonation.Impersonate(handler)(c)
+		}
+
+		return handler(c)
 	})
 }
+
+// RejectDeletedAccounts refuses requests from a deleted account, or made while
+// impersonating one, so its data stays out of reach until it is recovered or
+// purged. It must run after authentication.
+func (auth *AuthMiddleware) RejectDeletedAccounts(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if auth.accounts == nil {
+			return next(c)
+		}
+
+		userID := GetUserID(c)
+		deleted, err := auth.accounts.IsAccountDeleted(c.Request().Context(), userID)
+		if err != nil {
+			auth.server.Logger.Error().
+				Err(err).
+				Str("function", "RejectDeletedAccounts").
+				Str("user_id", userID).
+				Str("request_id", GetRequestID(c)).
+				Msg("failed to check account deletion")
+			return err
+		}
+
+		if deleted {
+			auth.server.Logger.Warn().
+				Str("function", "RejectDeletedAccounts").
+				Str("user_id", userID).
+				Str("request_id", GetRequestID(c)).
+				Msg("deleted account attempted a request")
+
+			err := errs.NewForbiddenError("This account has been deleted", false)
+			err.Code = errs.CodeAccountDeleted
+			return err
+		}
+
+		return next(c)
+	}
+}
+
+// scopeToTenant runs the request's queries on behalf of the effective user,
+// after impersonation has been resolved. It only has an effect when tenant
+// isolation is enabled for the database.
+func scopeToTenant(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := database.WithTenant(c.Request().Context(), GetUserID(c))
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
+
+// RequireAdmin restricts a route to the users configured in ADMIN_USER_IDS.
+// Clerk organization roles don't count: anyone can create an organization
+// and become its admin. Impersonated sessions never pass, even when the
+// impersonator is an admin.
+func (auth *AuthMiddleware) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if GetImpersonatorID(c) != "" {
+			return errs.NewForbiddenError("Admin routes are not available while impersonating", false)
+		}
+
+		userID := GetUserID(c)
+
+		if !auth.server.Config.Auth.IsAdmin(userID) {
+			auth.server.Logger.Warn().
+				Str("function", "RequireAdmin").
+				Str("user_id", userID).
+				Str("request_id", GetRequestID(c)).
+				Msg("non-admin user attempted to access admin route")
+			return errs.NewForbiddenError("Admin access required", false)
+		}
+
+		// Admin tooling works across users, so it isn't held to one tenant
+		ctx := database.WithTenant(c.Request().Context(), "")
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// CacheNoStore keeps responses out of every cache. Mutations and errors use it.
+	CacheNoStore = "no-store"
+	// CacheRevalidate lets the browser keep a copy but check it on every use,
+	// for responses that change often
+	CacheRevalidate = "private, max-age=0, must-revalidate"
+	// CacheShortLived lets the browser reuse a response for a minute, for
+	// responses that rarely change within a session
+	CacheShortLived = "private, max-age=60"
+)
+
+// CachePolicies maps "METHOD /route/path" to the Cache-Control value the
+// response is sent with. Reads not listed here get no header; writes are
+// always CacheNoStore.
+var CachePolicies = map[string]string{
+	"GET /api/v1/categories":             CacheShortLived,
+	"GET /api/v1/todos/stats":            CacheShortLived,
+	"GET /api/v1/todos/stats/filtered":   CacheShortLived,
+	"GET /api/v1/todos/stats/cycle-time": CacheShortLived,
+	"GET /api/v1/todos":                  CacheRevalidate,
+	"GET /api/v1/todos/count":            CacheRevalidate,
+	"GET /api/v1/todos/:id":              CacheRevalidate,
+	"GET /api/v1/todos/:id/children":     CacheRevalidate,
+	"GET /api/v1/todos/trash":            CacheRevalidate,
+	"GET /api/v1/admin/config":           CacheNoStore,
+}
+
+// CachePolicy returns the Cache-Control value for a request to the route path,
+// or "" when the response should be left alone
+func CachePolicy(method, path string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return CachePolicies[http.MethodGet+" "+path]
+	case http.MethodOptions:
+		return ""
+	default:
+		return CacheNoStore
+	}
+}
+
+// CacheControl sets the Cache-Control header from CachePolicies. It must run
+// after routing so the matched route path is known. Error responses are never
+// cached, and a handler may still set its own header.
+func (global *GlobalMiddlewares) CacheControl() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			policy := CachePolicy(c.Request().Method, c.Path())
+			if policy == "" {
+				return next(c)
+			}
+
+			res := c.Response()
+			res.Header().Set(echo.HeaderCacheControl, policy)
+			res.Before(func() {
+				if res.Status >= http.StatusBadRequest {
+					res.Header().Set(echo.HeaderCacheControl, CacheNoStore)
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
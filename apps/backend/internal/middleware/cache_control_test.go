@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheControlRouter() *echo.Echo {
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	e := echo.New()
+	e.Use(middleware.NewGlobalMiddlewares(&server.Server{}).CacheControl())
+
+	e.GET("/api/v1/categories", ok)
+	e.POST("/api/v1/categories", ok)
+	e.GET("/api/v1/todos", ok)
+	e.POST("/api/v1/todos", ok)
+	e.GET("/api/v1/todos/stats", ok)
+	e.GET("/api/v1/todos/stats/filtered", ok)
+	e.GET("/api/v1/todos/stats/cycle-time", ok)
+	e.GET("/api/v1/todos/:id", func(c echo.Context) error {
+		if c.Param("id") == "missing" {
+			return c.JSON(http.StatusNotFound, errs.NewNotFoundError("todo not found", false, nil))
+		}
+		return ok(c)
+	})
+	e.PATCH("/api/v1/todos/:id", ok)
+	e.DELETE("/api/v1/todos/:id", ok)
+	e.GET("/api/v1/todos/:id/export.md", ok)
+
+	return e
+}
+
+func TestCacheControl(t *testing.T) {
+	e := newCacheControlRouter()
+
+	tests := []struct {
+		method string
+		target string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/categories", middleware.CacheShortLived},
+		{http.MethodGet, "/api/v1/todos/stats", middleware.CacheShortLived},
+		{http.MethodGet, "/api/v1/todos/stats/filtered", middleware.CacheShortLived},
+		{http.MethodGet, "/api/v1/todos/stats/cycle-time", middleware.CacheShortLived},
+		{http.MethodGet, "/api/v1/todos", middleware.CacheRevalidate},
+		{http.MethodGet, "/api/v1/todos/0b9e8a4c-5d1f-4a63-9b3e-2f7c1d8e6a10", middleware.CacheRevalidate},
+		{http.MethodPost, "/api/v1/categories", middleware.CacheNoStore},
+		{http.MethodPost, "/api/v1/todos", middleware.CacheNoStore},
+		{http.MethodPatch, "/api/v1/todos/0b9e8a4c-5d1f-4a63-9b3e-2f7c1d8e6a10", middleware.CacheNoStore},
+		{http.MethodDelete, "/api/v1/todos/0b9e8a4c-5d1f-4a63-9b3e-2f7c1d8e6a10", middleware.CacheNoStore},
+		{http.MethodGet, "/api/v1/todos/missing", middleware.CacheNoStore},
+		{http.MethodGet, "/api/v1/todos/0b9e8a4c-5d1f-4a63-9b3e-2f7c1d8e6a10/export.md", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.want, rec.Header().Get(echo.HeaderCacheControl))
+		})
+	}
+}
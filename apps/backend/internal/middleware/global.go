@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/activity"
 	"github.com/sriniously/tasker/internal/server"
 	"github.com/sriniously/tasker/internal/sqlerr"
 )
@@ -24,7 +25,8 @@ func NewGlobalMiddlewares(s *server.Server) *GlobalMiddlewares {
 
 func (global *GlobalMiddlewares) CORS() echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: global.server.Config.Server.CORSAllowedOrigins,
+		AllowOrigins:  global.server.Config.Server.CORSAllowedOrigins,
+		ExposeHeaders: []string{activity.UndoTokenHeader, IdempotentReplayedHeader},
 	})
 }
 
@@ -122,7 +124,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 	// Now process the possibly converted error
 	var echoErr *echo.HTTPError
 	var status int
-	var code string
+	var code errs.Code
 	var message string
 	var fieldErrors []errs.FieldError
 	var action *errs.Action
@@ -137,7 +139,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 
 	case errors.As(err, &echoErr):
 		status = echoErr.Code
-		code = errs.MakeUpperCaseWithUnderscores(http.StatusText(status))
+		code = errs.Code(errs.MakeUpperCaseWithUnderscores(http.StatusText(status)))
 		if msg, ok := echoErr.Message.(string); ok {
 			message = msg
 		} else {
@@ -146,8 +148,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 
 	default:
 		status = http.StatusInternalServerError
-		code = errs.MakeUpperCaseWithUnderscores(
-			http.StatusText(http.StatusInternalServerError))
+		code = errs.CodeInternalServerError
 		message = http.StatusText(http.StatusInternalServerError)
 	}
 
@@ -158,17 +159,18 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 	logger.Error().Stack().
 		Err(originalErr).
 		Int("status", status).
-		Str("error_code", code).
+		Str("error_code", string(code)).
 		Msg(message)
 
 	if !c.Response().Committed {
-		_ = c.JSON(status, errs.HTTPError{
+		response := &errs.HTTPError{
 			Code:     code,
 			Message:  message,
 			Status:   status,
 			Override: httpErr != nil && httpErr.Override,
 			Errors:   fieldErrors,
 			Action:   action,
-		})
+		}
+		_ = c.JSON(status, response.Envelope())
 	}
 }
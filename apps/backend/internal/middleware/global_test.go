@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func handleGlobalError(t *testing.T, err error) (*httptest.ResponseRecorder, errs.ErrorResponse) {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	m := middleware.NewGlobalMiddlewares(&server.Server{Logger: &logger})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	m.GlobalErrorHandler(err, c)
+
+	var response errs.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec, response
+}
+
+func TestGlobalErrorHandler(t *testing.T) {
+	t.Run("not found error is wrapped in the envelope", func(t *testing.T) {
+		code := errs.CodeTodoNotFound
+		rec, response := handleGlobalError(t, errs.NewNotFoundError("todo not found", false, &code))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, errs.CodeTodoNotFound, response.Error.Code)
+		assert.Equal(t, "todo not found", response.Error.Message)
+		assert.Equal(t, http.StatusNotFound, response.Error.Status)
+		assert.Nil(t, response.Error.Details)
+	})
+
+	t.Run("bad request error carries field details", func(t *testing.T) {
+		rec, response := handleGlobalError(t, errs.NewBadRequestError("Validation failed", true, nil,
+			[]errs.FieldError{{Field: "title", Error: "is required"}}, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, errs.CodeBadRequest, response.Error.Code)
+		assert.Equal(t, "Validation failed", response.Error.Message)
+		assert.Equal(t, http.StatusBadRequest, response.Error.Status)
+		require.NotNil(t, response.Error.Details)
+		require.Len(t, response.Error.Details.Fields, 1)
+		assert.Equal(t, "title", response.Error.Details.Fields[0].Field)
+	})
+
+	t.Run("unknown route maps to generic not found", func(t *testing.T) {
+		rec, response := handleGlobalError(t, echo.ErrNotFound)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, errs.CodeNotFound, response.Error.Code)
+	})
+
+	t.Run("envelope uses the documented keys", func(t *testing.T) {
+		rec, _ := handleGlobalError(t, errs.NewBadRequestError("bad", false, nil,
+			[]errs.FieldError{{Field: "title", Error: "is required"}}, nil))
+
+		var raw map[string]map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &raw))
+		require.Contains(t, raw, "error")
+		assert.Contains(t, raw["error"], "code")
+		assert.Contains(t, raw["error"], "message")
+		assert.Contains(t, raw["error"], "details")
+	})
+}
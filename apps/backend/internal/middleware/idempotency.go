@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/idempotency"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentRequestSize bounds the request bodies read for the
+	// fingerprint
+	maxIdempotentRequestSize = 1 << 20
+	// maxIdempotentResponseSize bounds the responses kept for replaying;
+	// larger ones are served without being kept
+	maxIdempotentResponseSize = 1 << 20
+	// defaultIdempotencyReservation holds a key for a request being served
+	// when the server has no write timeout to go by
+	defaultIdempotencyReservation = time.Minute
+)
+
+// IdempotencyMiddleware serves a retried mutating request with the response
+// its first attempt got, so clients can safely resend requests whose
+// response they never saw. Keys are scoped to the user sending them.
+type IdempotencyMiddleware struct {
+	server      *server.Server
+	store       idempotency.Store
+	ttl         time.Duration
+	reservation time.Duration
+}
+
+func NewIdempotencyMiddleware(s *server.Server, store idempotency.Store, ttl time.Duration,
+	reservation time.Duration,
+) *IdempotencyMiddleware {
+	if reservation <= 0 {
+		reservation = defaultIdempotencyReservation
+	}
+	return &IdempotencyMiddleware{
+		server:      s,
+		store:       store,
+		ttl:         ttl,
+		reservation: reservation,
+	}
+}
+
+// Idempotent records the response to a POST, PUT, PATCH or DELETE sent with
+// an Idempotency-Key header and replays it when the same request is sent
+// with the key again. Reusing a key for a different request is refused, as
+// is a retry arriving while the first attempt is still being served, and a
+// body too large to fingerprint. Failed requests are not kept so they can be
+// retried. It must run after RequireAuth.
+func (m *IdempotencyMiddleware) Idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(IdempotencyKeyHeader)
+		// Uploads are left out: their bodies are too large to buffer for
+		// the fingerprint
+		if key == "" || !isMutating(c.Request().Method) ||
+			strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+			return next(c)
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			code := errs.CodeInvalidIdempotencyKey
+			return errs.NewBadRequestError("Idempotency-Key must be at most 255 characters", true, &code, nil, nil)
+		}
+
+		logger := GetLogger(c)
+
+		fingerprint, err := fingerprintRequest(c)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return errs.NewRequestEntityTooLargeError(
+					"Requests sent with an Idempotency-Key must be at most 1 MiB", true)
+			}
+			return errs.NewBadRequestError("failed to read request body", false, nil, nil, nil)
+		}
+
+		storeKey := GetUserID(c) + ":" + key
+		// The key is forgotten even if the client went away mid-request
+		ctx := context.WithoutCancel(c.Request().Context())
+
+		existing, err := m.store.Reserve(ctx, storeKey, &idempotency.Record{Fingerprint: fingerprint}, m.reservation)
+		if err != nil {
+			// Serving the request unprotected beats refusing it
+			logger.Error().Err(err).Msg("failed to reserve idempotency key")
+			return next(c)
+		}
+		if existing != nil {
+			return m.replay(c, existing, fingerprint)
+		}
+
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := m.store.Release(ctx, storeKey); err != nil {
+				logger.Error().Err(err).Msg("failed to release idempotency key")
+			}
+		}()
+
+		before := c.Response().Header().Clone()
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+		defer func() { c.Response().Writer = recorder.ResponseWriter }()
+
+		if err := next(c); err != nil {
+			return err
+		}
+
+		status := c.Response().Status
+		if !c.Response().Committed || status >= http.StatusInternalServerError || recorder.overflow {
+			return nil
+		}
+
+		record := &idempotency.Record{
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      addedHeaders(before, c.Response().Header()),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := m.store.Save(ctx, storeKey, record, m.ttl); err != nil {
+			logger.Error().Err(err).Msg("failed to save idempotent response")
+			return nil
+		}
+		saved = true
+
+		return nil
+	}
+}
+
+func (m *IdempotencyMiddleware) replay(c echo.Context, record *idempotency.Record, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		code := errs.CodeIdempotencyKeyReused
+		return errs.NewBadRequestError("Idempotency-Key was already used for a different request", true,
+			&code, nil, nil)
+	}
+
+	if !record.Completed() {
+		code := errs.CodeIdempotencyKeyInUse
+		c.Response().Header().Set("Retry-After", "1")
+		return errs.NewConflictError("A request with this Idempotency-Key is still being served", true, &code)
+	}
+
+	GetLogger(c).Info().
+		Str("event", "idempotent_response_replayed").
+		Int("status", record.Status).
+		Msg("Replayed response for retried request")
+
+	header := c.Response().Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+
+	c.Response().WriteHeader(record.Status)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// fingerprintRequest hashes what the request asks for, leaving the body in
+// place for the handler. Bodies over maxIdempotentRequestSize fail with an
+// *http.MaxBytesError rather than being buffered.
+func fingerprintRequest(c echo.Context) (string, error) {
+	req := c.Request()
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxIdempotentRequestSize))
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	// The organization header switches whose todos the request acts on
+	for _, part := range []string{req.Method, req.URL.RequestURI(), req.Header.Get(OrganizationHeader)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// addedHeaders returns the headers the handler set, leaving out those the
+// middlewares before it set for every request
+func addedHeaders(before, after http.Header) http.Header {
+	added := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			added[name] = values
+		}
+	}
+	return added
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > maxIdempotentResponseSize {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
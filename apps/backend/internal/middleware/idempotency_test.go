@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/idempotency"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	store := idempotency.NewMemoryStore(nil)
+	m := middleware.NewIdempotencyMiddleware(nil, store, time.Hour, time.Minute)
+	logger := zerolog.Nop()
+
+	created := 0
+	createTodo := func(c echo.Context) error {
+		created++
+		c.Response().Header().Set("X-Undo-Token", "undo_1")
+		return c.JSON(http.StatusCreated, map[string]int{"number": created})
+	}
+
+	send := func(userID, key, body string, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middleware.UserIDKey, userID)
+		c.Set(middleware.LoggerKey, &logger)
+		return rec, m.Idempotent(handler)(c)
+	}
+
+	assertErrorCode := func(t *testing.T, err error, status int, code errs.Code) {
+		t.Helper()
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, status, httpErr.Status)
+		assert.Equal(t, code, httpErr.Code)
+	}
+
+	t.Run("retry replays the first response", func(t *testing.T) {
+		first, err := send("user_1", "key_1", `{"title":"Buy milk"}`, createTodo)
+		require.NoError(t, err)
+		retry, err := send("user_1", "key_1", `{"title":"Buy milk"}`, createTodo)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, created)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "undo_1", retry.Header().Get("X-Undo-Token"))
+		assert.Equal(t, echo.MIMEApplicationJSON, retry.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("key reused for a different request is refused", func(t *testing.T) {
+		_, err := send("user_1", "key_1", `{"title":"Buy bread"}`, createTodo)
+		assertErrorCode(t, err, http.StatusBadRequest, errs.CodeIdempotencyKeyReused)
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("keys are scoped to the user", func(t *testing.T) {
+		_, err := send("user_2", "key_1", `{"title":"Buy milk"}`, createTodo)
+		require.NoError(t, err)
+		assert.Equal(t, 2, created)
+	})
+
+	t.Run("retry while the first attempt is served conflicts", func(t *testing.T) {
+		_, err := send("user_1", "key_2", `{}`, func(c echo.Context) error {
+			_, err := send("user_1", "key_2", `{}`, createTodo)
+			assertErrorCode(t, err, http.StatusConflict, errs.CodeIdempotencyKeyInUse)
+			return c.NoContent(http.StatusNoContent)
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("failed requests can be retried", func(t *testing.T) {
+		_, err := send("user_1", "key_3", `{}`, func(c echo.Context) error {
+			return errs.NewInternalServerError()
+		})
+		require.Error(t, err)
+
+		rec, err := send("user_1", "key_3", `{}`, createTodo)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("requests without a key are not recorded", func(t *testing.T) {
+		before := created
+		for range 2 {
+			_, err := send("user_1", "", `{"title":"Buy milk"}`, createTodo)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, before+2, created)
+	})
+
+	t.Run("overlong key is refused", func(t *testing.T) {
+		_, err := send("user_1", strings.Repeat("k", 256), `{}`, createTodo)
+		assertErrorCode(t, err, http.StatusBadRequest, errs.CodeInvalidIdempotencyKey)
+	})
+
+	t.Run("oversized body is refused", func(t *testing.T) {
+		before := created
+		body := `{"title":"` + strings.Repeat("a", 1<<20) + `"}`
+		_, err := send("user_1", "key_4", body, createTodo)
+		assertErrorCode(t, err, http.StatusRequestEntityTooLarge, errs.CodeRequestEntityTooLarge)
+		assert.Equal(t, before, created)
+	})
+
+	t.Run("recorded responses expire", func(t *testing.T) {
+		now := time.Now()
+		store := idempotency.NewMemoryStore(func() time.Time { return now })
+		ctx := context.Background()
+
+		require.NoError(t, store.Save(ctx, "user_1:key", &idempotency.Record{Fingerprint: "a", Status: 201}, time.Hour))
+		existing, err := store.Reserve(ctx, "user_1:key", &idempotency.Record{Fingerprint: "a"}, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.True(t, existing.Completed())
+
+		now = now.Add(time.Hour)
+		existing, err = store.Reserve(ctx, "user_1:key", &idempotency.Record{Fingerprint: "a"}, time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+}
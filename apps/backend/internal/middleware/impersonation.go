@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/token"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+const (
+	ImpersonationHeader      = "X-Impersonation-Token"
+	ImpersonatingHeader      = "X-Impersonating-User"
+	ImpersonatorIDKey        = "impersonator_id"
+	impersonationAuditAction = admin.ImpersonationActionRequest
+)
+
+// ImpersonationAuditor persists a record for every impersonated request
+type ImpersonationAuditor interface {
+	CreateImpersonationAuditLog(ctx context.Context, entry *admin.ImpersonationAuditLog) error
+}
+
+type ImpersonationMiddleware struct {
+	server  *server.Server
+	auditor ImpersonationAuditor
+}
+
+func NewImpersonationMiddleware(s *server.Server, auditor ImpersonationAuditor) *ImpersonationMiddleware {
+	return &ImpersonationMiddleware{
+		server:  s,
+		auditor: auditor,
+	}
+}
+
+// Impersonate swaps the authenticated user for the target of a valid impersonation token.
+// It must run after authentication. Impersonated sessions are read-only and every request is audited.
+func (m *ImpersonationMiddleware) Impersonate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		raw := c.Request().Header.Get(ImpersonationHeader)
+		if raw == "" {
+			return next(c)
+		}
+
+		impersonatorID := GetUserID(c)
+		if impersonatorID == "" {
+			return errs.NewUnauthorizedError("Unauthorized", false)
+		}
+
+		claims, err := token.Verify(m.server.Config.Auth.SecretKey, raw, token.PurposeImpersonation)
+		if err != nil {
+			return errs.NewUnauthorizedError("Invalid or expired impersonation token", false)
+		}
+
+		if claims.Actor != impersonatorID {
+			return errs.NewForbiddenError("Impersonation token was issued to a different user", false)
+		}
+
+		c.Set(UserIDKey, claims.Subject)
+		c.Set(ImpersonatorIDKey, impersonatorID)
+		c.Response().Header().Set(ImpersonatingHeader, claims.Subject)
+
+		method := c.Request().Method
+		path := c.Request().URL.Path
+		requestID := GetRequestID(c)
+		allowed := isReadOnlyMethod(method)
+
+		entry := &admin.ImpersonationAuditLog{
+			ImpersonatorID: impersonatorID,
+			TargetUserID:   claims.Subject,
+			Action:         impersonationAuditAction,
+			Method:         &method,
+			Path:           &path,
+			RequestID:      &requestID,
+			Allowed:        allowed,
+		}
+
+		// An impersonated request that cannot be audited is not served
+		if err := m.auditor.CreateImpersonationAuditLog(c.Request().Context(), entry); err != nil {
+			m.server.Logger.Error().
+				Err(err).
+				Str("impersonator_id", impersonatorID).
+				Str("target_user_id", claims.Subject).
+				Msg("failed to write impersonation audit log")
+			return errs.NewInternalServerError()
+		}
+
+		m.server.Logger.Info().
+			Str("impersonator_id", impersonatorID).
+			Str("target_user_id", claims.Subject).
+			Str("method", method).
+			Str("path", path).
+			Bool("allowed", allowed).
+			Msg("impersonated request")
+
+		if !allowed {
+			return errs.NewForbiddenError("Impersonation sessions are read-only", false)
+		}
+
+		return next(c)
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func GetImpersonatorID(c echo.Context) string {
+	if impersonatorID, ok := c.Get(ImpersonatorIDKey).(string); ok {
+		return impersonatorID
+	}
+	return ""
+}
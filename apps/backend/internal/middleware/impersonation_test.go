@@ -0,0 +1,124 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/token"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuditor struct {
+	entries []admin.ImpersonationAuditLog
+}
+
+func (f *fakeAuditor) CreateImpersonationAuditLog(ctx context.Context, entry *admin.ImpersonationAuditLog) error {
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+func newImpersonationTestServer() *server.Server {
+	logger := zerolog.Nop()
+	return &server.Server{
+		Logger: &logger,
+		Config: &config.Config{
+			Auth: config.AuthConfig{SecretKey: "test-secret"},
+		},
+	}
+}
+
+func signImpersonationToken(t *testing.T, adminID, targetID string) string {
+	t.Helper()
+
+	raw, err := token.Sign("test-secret", token.Claims{
+		Subject:   targetID,
+		Actor:     adminID,
+		Purpose:   token.PurposeImpersonation,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	return raw
+}
+
+func runImpersonated(t *testing.T, m *middleware.ImpersonationMiddleware, method string, raw string) (string, error) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(method, "/api/v1/todos", nil)
+	req.Header.Set(middleware.ImpersonationHeader, raw)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middleware.UserIDKey, "user_admin")
+
+	var seenUserID string
+	err := m.Impersonate(func(c echo.Context) error {
+		seenUserID = middleware.GetUserID(c)
+		return nil
+	})(c)
+
+	return seenUserID, err
+}
+
+func TestImpersonationMiddleware(t *testing.T) {
+	t.Run("read requests are scoped to the target user and audited", func(t *testing.T) {
+		auditor := &fakeAuditor{}
+		m := middleware.NewImpersonationMiddleware(newImpersonationTestServer(), auditor)
+
+		seenUserID, err := runImpersonated(t, m, http.MethodGet, signImpersonationToken(t, "user_admin", "user_target"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "user_target", seenUserID)
+		require.Len(t, auditor.entries, 1)
+		assert.Equal(t, "user_admin", auditor.entries[0].ImpersonatorID)
+		assert.Equal(t, "user_target", auditor.entries[0].TargetUserID)
+		assert.Equal(t, http.MethodGet, *auditor.entries[0].Method)
+		assert.True(t, auditor.entries[0].Allowed)
+	})
+
+	t.Run("writes are refused but still audited", func(t *testing.T) {
+		auditor := &fakeAuditor{}
+		m := middleware.NewImpersonationMiddleware(newImpersonationTestServer(), auditor)
+
+		seenUserID, err := runImpersonated(t, m, http.MethodPost, signImpersonationToken(t, "user_admin", "user_target"))
+		require.Error(t, err)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Status)
+		assert.Empty(t, seenUserID)
+		require.Len(t, auditor.entries, 1)
+		assert.False(t, auditor.entries[0].Allowed)
+	})
+
+	t.Run("token issued to another admin is rejected", func(t *testing.T) {
+		auditor := &fakeAuditor{}
+		m := middleware.NewImpersonationMiddleware(newImpersonationTestServer(), auditor)
+
+		_, err := runImpersonated(t, m, http.MethodGet, signImpersonationToken(t, "user_other_admin", "user_target"))
+		require.Error(t, err)
+		assert.Empty(t, auditor.entries)
+	})
+
+	t.Run("invalid token is rejected", func(t *testing.T) {
+		auditor := &fakeAuditor{}
+		m := middleware.NewImpersonationMiddleware(newImpersonationTestServer(), auditor)
+
+		_, err := runImpersonated(t, m, http.MethodGet, "garbage")
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.Status)
+	})
+}
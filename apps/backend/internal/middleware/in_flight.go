@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// InFlightMiddleware bounds how many requests are served at once, so a
+// traffic spike is shed with 503 instead of piling up behind the database
+// pool until requests time out. Slots are counted per server instance.
+type InFlightMiddleware struct {
+	server     *server.Server
+	slots      chan struct{}
+	retryAfter time.Duration
+}
+
+func NewInFlightMiddleware(s *server.Server, limit int, retryAfter time.Duration) *InFlightMiddleware {
+	return &InFlightMiddleware{
+		server:     s,
+		slots:      make(chan struct{}, limit),
+		retryAfter: retryAfter,
+	}
+}
+
+// Limit refuses the request with 503 and a Retry-After header while the
+// server-wide limit of requests is in flight
+func (m *InFlightMiddleware) Limit() echo.MiddlewareFunc {
+	return m.limit(m.slots, "server")
+}
+
+// LimitRoute adds a limit of its own for a route that is heavier than most,
+// counted on top of the server-wide one
+func (m *InFlightMiddleware) LimitRoute(limit int) echo.MiddlewareFunc {
+	return m.limit(make(chan struct{}, limit), "route")
+}
+
+func (m *InFlightMiddleware) limit(slots chan struct{}, scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			select {
+			case slots <- struct{}{}:
+			default:
+				GetLogger(c).Warn().
+					Str("scope", scope).
+					Int("limit", cap(slots)).
+					Str("path", c.Path()).
+					Msg("in-flight request limit reached")
+
+				seconds := int(math.Ceil(m.retryAfter.Seconds()))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				return errs.NewServiceUnavailableError("Server is busy, try again shortly", true)
+			}
+			defer func() { <-slots }()
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightMiddleware(t *testing.T) {
+	const limit = 2
+
+	m := middleware.NewInFlightMiddleware(nil, limit, 1500*time.Millisecond)
+	logger := zerolog.Nop()
+
+	serve := func(mw echo.MiddlewareFunc, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middleware.LoggerKey, &logger)
+		err := mw(handler)(c)
+		return rec, err
+	}
+
+	// Hold the first requests open until released so they stay in flight
+	release := make(chan struct{})
+	started := make(chan struct{}, limit)
+	blocking := func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	noop := func(c echo.Context) error { return nil }
+
+	global := m.Limit()
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := serve(global, blocking)
+			assert.NoError(t, err)
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	t.Run("requests over the limit are shed with 503", func(t *testing.T) {
+		rec, err := serve(global, noop)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Status)
+		assert.Equal(t, errs.CodeServiceUnavailable, httpErr.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("route limits are counted separately", func(t *testing.T) {
+		_, err := serve(m.LimitRoute(1), noop)
+		assert.NoError(t, err)
+	})
+
+	close(release)
+	wg.Wait()
+
+	t.Run("capacity frees as requests complete", func(t *testing.T) {
+		for i := 0; i < limit+1; i++ {
+			_, err := serve(global, noop)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("failed requests release their slot", func(t *testing.T) {
+		failing := func(c echo.Context) error { return errs.NewInternalServerError() }
+		for i := 0; i < limit+1; i++ {
+			_, err := serve(global, failing)
+			var httpErr *errs.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, http.StatusInternalServerError, httpErr.Status)
+		}
+		_, err := serve(global, noop)
+		assert.NoError(t, err)
+	})
+
+	t.Run("a route limit sheds on its own", func(t *testing.T) {
+		route := m.LimitRoute(1)
+		hold := make(chan struct{})
+		entered := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = serve(route, func(c echo.Context) error {
+				close(entered)
+				<-hold
+				return nil
+			})
+		}()
+		<-entered
+
+		_, err := serve(route, noop)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Status)
+
+		close(hold)
+		<-done
+		_, err = serve(route, noop)
+		assert.NoError(t, err)
+	})
+}
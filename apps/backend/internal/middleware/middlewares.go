@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/sriniously/tasker/internal/lib/idempotency"
+	"github.com/sriniously/tasker/internal/repository"
 	"github.com/sriniously/tasker/internal/server"
 )
 
@@ -11,6 +13,11 @@ type Middlewares struct {
 	ContextEnhancer *ContextEnhancer
 	Tracing         *TracingMiddleware
 	RateLimit       *RateLimitMiddleware
+	Impersonation   *ImpersonationMiddleware
+	Organization    *OrganizationMiddleware
+	UploadLimit     *UploadLimitMiddleware
+	InFlight        *InFlightMiddleware
+	Idempotency     *IdempotencyMiddleware
 }
 
 func NewMiddlewares(s *server.Server) *Middlewares {
@@ -20,11 +27,22 @@ func NewMiddlewares(s *server.Server) *Middlewares {
 		nrApp = s.LoggerService.GetApplication()
 	}
 
+	impersonation := NewImpersonationMiddleware(s, repository.NewAdminRepository(s))
+	accounts := NewCachedAccountStatus(repository.NewAccountRepository(s), s.Config.Auth.GetAccountStatusCacheTTL(), nil)
+	inFlight := NewInFlightMiddleware(s, s.Config.Server.GetMaxInFlight(s.Config.Database.MaxOpenConns),
+		s.Config.Server.GetInFlightRetryAfter())
+
 	return &Middlewares{
 		Global:          NewGlobalMiddlewares(s),
-		Auth:            NewAuthMiddleware(s),
+		Auth:            NewAuthMiddleware(s, impersonation, accounts),
 		ContextEnhancer: NewContextEnhancer(s),
 		Tracing:         NewTracingMiddleware(s, nrApp),
 		RateLimit:       NewRateLimitMiddleware(s),
+		Impersonation:   impersonation,
+		Organization:    NewOrganizationMiddleware(s, repository.NewOrganizationRepository(s)),
+		UploadLimit:     NewUploadLimitMiddleware(s, s.Config.Todo.GetMaxConcurrentUploads()),
+		InFlight:        inFlight,
+		Idempotency: NewIdempotencyMiddleware(s, idempotency.NewRedisStore(s.Redis),
+			s.Config.Server.GetIdempotencyKeyTTL(), s.Config.Server.GetRequestTimeout()),
 	}
 }
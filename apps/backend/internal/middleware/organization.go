@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+const (
+	OrganizationHeader = "X-Organization-ID"
+	OrganizationParam  = "orgId"
+	MembershipKey      = "org_membership"
+)
+
+// MembershipLookup finds a user's membership in an organization, returning
+// nil when they don't belong to it
+type MembershipLookup interface {
+	GetMembership(ctx context.Context, orgID uuid.UUID, userID string) (*organization.Membership, error)
+}
+
+type OrganizationMiddleware struct {
+	server      *server.Server
+	memberships MembershipLookup
+}
+
+func NewOrganizationMiddleware(s *server.Server, memberships MembershipLookup) *OrganizationMiddleware {
+	return &OrganizationMiddleware{
+		server:      s,
+		memberships: memberships,
+	}
+}
+
+// ResolveOrg sets the active organization from the orgId path parameter or,
+// failing that, the X-Organization-ID header. Requests naming neither stay in
+// the user's personal scope. It must run after authentication, and requests
+// for an organization the user doesn't belong to are refused.
+func (m *OrganizationMiddleware) ResolveOrg(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		raw := c.Param(OrganizationParam)
+		if raw == "" {
+			raw = c.Request().Header.Get(OrganizationHeader)
+		}
+		if raw == "" {
+			return next(c)
+		}
+
+		orgID, err := uuid.Parse(raw)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid organization ID", false, nil, nil, nil)
+		}
+
+		userID := GetUserID(c)
+		if userID == "" {
+			return errs.NewUnauthorizedError("Unauthorized", false)
+		}
+
+		membership, err := m.memberships.GetMembership(c.Request().Context(), orgID, userID)
+		if err != nil {
+			m.server.Logger.Error().
+				Err(err).
+				Str("org_id", orgID.String()).
+				Str("user_id", userID).
+				Msg("failed to resolve organization membership")
+			return errs.NewInternalServerError()
+		}
+
+		if membership == nil {
+			m.server.Logger.Warn().
+				Str("org_id", orgID.String()).
+				Str("user_id", userID).
+				Str("request_id", GetRequestID(c)).
+				Msg("non-member attempted to access organization")
+			return errs.NewForbiddenError("You are not a member of this organization", false)
+		}
+
+		c.Set(MembershipKey, membership)
+
+		return next(c)
+	}
+}
+
+// GetOrgMembership returns the caller's membership in the active
+// organization, or nil when the request is in their personal scope
+func GetOrgMembership(c echo.Context) *organization.Membership {
+	if membership, ok := c.Get(MembershipKey).(*organization.Membership); ok {
+		return membership
+	}
+	return nil
+}
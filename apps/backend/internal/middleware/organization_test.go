@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMemberships struct {
+	members map[uuid.UUID]map[string]organization.Role
+}
+
+func (f *fakeMemberships) GetMembership(ctx context.Context, orgID uuid.UUID,
+	userID string,
+) (*organization.Membership, error) {
+	role, ok := f.members[orgID][userID]
+	if !ok {
+		return nil, nil
+	}
+	return &organization.Membership{OrgID: orgID, UserID: userID, Role: role}, nil
+}
+
+func runInOrg(t *testing.T, m *middleware.OrganizationMiddleware, userID, header, param string,
+) (*organization.Membership, bool, error) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+	if header != "" {
+		req.Header.Set(middleware.OrganizationHeader, header)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middleware.UserIDKey, userID)
+	if param != "" {
+		c.SetParamNames(middleware.OrganizationParam)
+		c.SetParamValues(param)
+	}
+
+	var seen *organization.Membership
+	called := false
+	err := m.ResolveOrg(func(c echo.Context) error {
+		called = true
+		seen = middleware.GetOrgMembership(c)
+		return nil
+	})(c)
+
+	return seen, called, err
+}
+
+func TestOrganizationMiddleware(t *testing.T) {
+	orgID := uuid.New()
+	otherOrgID := uuid.New()
+	memberships := &fakeMemberships{
+		members: map[uuid.UUID]map[string]organization.Role{
+			orgID:      {"user_member": organization.RoleMember},
+			otherOrgID: {"user_member": organization.RoleViewer},
+		},
+	}
+	m := middleware.NewOrganizationMiddleware(newImpersonationTestServer(), memberships)
+
+	t.Run("requests without an organization stay personal", func(t *testing.T) {
+		seen, called, err := runInOrg(t, m, "user_member", "", "")
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Nil(t, seen)
+	})
+
+	t.Run("member resolves the organization from the header", func(t *testing.T) {
+		seen, called, err := runInOrg(t, m, "user_member", orgID.String(), "")
+		require.NoError(t, err)
+		assert.True(t, called)
+		require.NotNil(t, seen)
+		assert.Equal(t, orgID, seen.OrgID)
+		assert.Equal(t, organization.RoleMember, seen.Role)
+	})
+
+	t.Run("path parameter takes precedence over the header", func(t *testing.T) {
+		seen, _, err := runInOrg(t, m, "user_member", orgID.String(), otherOrgID.String())
+		require.NoError(t, err)
+		require.NotNil(t, seen)
+		assert.Equal(t, otherOrgID, seen.OrgID)
+		assert.Equal(t, organization.RoleViewer, seen.Role)
+	})
+
+	t.Run("non-member is denied", func(t *testing.T) {
+		seen, called, err := runInOrg(t, m, "user_outsider", orgID.String(), "")
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Status)
+		assert.False(t, called)
+		assert.Nil(t, seen)
+	})
+
+	t.Run("malformed organization ID is rejected", func(t *testing.T) {
+		_, called, err := runInOrg(t, m, "user_member", "not-a-uuid", "")
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// UploadLimitMiddleware caps how many uploads each user may have in flight at
+// once, so one client can't tie up S3 connections and memory. Slots are
+// counted per server instance.
+type UploadLimitMiddleware struct {
+	server   *server.Server
+	limit    int
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func NewUploadLimitMiddleware(s *server.Server, limit int) *UploadLimitMiddleware {
+	return &UploadLimitMiddleware{
+		server:   s,
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// LimitConcurrentUploads refuses the request with 429 while the user already
+// has the maximum number of uploads in flight. It must run after
+// authentication. The slot is released once the handler returns, whether or
+// not the upload succeeded.
+func (m *UploadLimitMiddleware) LimitConcurrentUploads(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := GetUserID(c)
+
+		if !m.acquire(userID) {
+			GetLogger(c).Warn().
+				Str("user_id", userID).
+				Int("limit", m.limit).
+				Msg("concurrent upload limit reached")
+
+			return errs.NewTooManyRequestsError("Too many uploads in progress, try again once one finishes", true)
+		}
+		defer m.release(userID)
+
+		return next(c)
+	}
+}
+
+func (m *UploadLimitMiddleware) acquire(userID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.inFlight[userID] >= m.limit {
+		return false
+	}
+	m.inFlight[userID]++
+	return true
+}
+
+func (m *UploadLimitMiddleware) release(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[userID]--
+	if m.inFlight[userID] <= 0 {
+		delete(m.inFlight, userID)
+	}
+}
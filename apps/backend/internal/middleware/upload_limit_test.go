@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadLimitMiddleware(t *testing.T) {
+	const limit = 2
+
+	m := middleware.NewUploadLimitMiddleware(nil, limit)
+	logger := zerolog.Nop()
+
+	upload := func(userID string, handler echo.HandlerFunc) error {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos/1/attachments", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set(middleware.UserIDKey, userID)
+		c.Set(middleware.LoggerKey, &logger)
+		return m.LimitConcurrentUploads(handler)(c)
+	}
+
+	// Hold the first uploads open until released so they stay in flight
+	release := make(chan struct{})
+	started := make(chan struct{}, limit)
+	blocking := func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	noop := func(c echo.Context) error { return nil }
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, upload("user_1", blocking))
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	t.Run("upload over the limit is rejected", func(t *testing.T) {
+		err := upload("user_1", noop)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusTooManyRequests, httpErr.Status)
+		assert.Equal(t, errs.CodeTooManyRequests, httpErr.Code)
+	})
+
+	t.Run("other users are not affected", func(t *testing.T) {
+		assert.NoError(t, upload("user_2", noop))
+	})
+
+	close(release)
+	wg.Wait()
+
+	t.Run("slots free up after completion", func(t *testing.T) {
+		assert.NoError(t, upload("user_1", noop))
+	})
+
+	t.Run("failed uploads release their slot", func(t *testing.T) {
+		failing := func(c echo.Context) error { return errs.NewInternalServerError() }
+		for i := 0; i < limit+1; i++ {
+			var httpErr *errs.HTTPError
+			require.ErrorAs(t, upload("user_1", failing), &httpErr)
+			assert.Equal(t, http.StatusInternalServerError, httpErr.Status)
+		}
+		assert.NoError(t, upload("user_1", noop))
+	})
+}
@@ -0,0 +1,22 @@
+package account
+
+import "time"
+
+// PurgeBatchSize is how many deleted accounts one run of the purge job handles
+const PurgeBatchSize = 50
+
+// Deletion records an account deleted by its owner. The account's data stays
+// in place, out of reach, until PurgeAfter, and can be recovered until then.
+type Deletion struct {
+	UserID    string    `json:"userId" db:"user_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	// DeletedBy is the user who asked for the deletion
+	DeletedBy  string     `json:"deletedBy" db:"deleted_by"`
+	PurgeAfter time.Time  `json:"purgeAfter" db:"purge_after"`
+	PurgedAt   *time.Time `json:"purgedAt" db:"purged_at"`
+}
+
+// IsRecoverable reports whether the account's data can still be restored at now
+func (d *Deletion) IsRecoverable(now time.Time) bool {
+	return d.PurgedAt == nil && now.Before(d.PurgeAfter)
+}
@@ -0,0 +1,42 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletion_IsRecoverable(t *testing.T) {
+	now := time.Now()
+	purged := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		deletion account.Deletion
+		want     bool
+	}{
+		{
+			name:     "within the window",
+			deletion: account.Deletion{PurgeAfter: now.Add(time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "window passed",
+			deletion: account.Deletion{PurgeAfter: now.Add(-time.Minute)},
+			want:     false,
+		},
+		{
+			name:     "already purged",
+			deletion: account.Deletion{PurgeAfter: now.Add(time.Hour), PurgedAt: &purged},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.deletion.IsRecoverable(now))
+		})
+	}
+}
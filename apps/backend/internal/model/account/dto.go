@@ -0,0 +1,9 @@
+package account
+
+// ------------------------------------------------------------
+
+type DeleteAccountPayload struct{}
+
+func (p *DeleteAccountPayload) Validate() error {
+	return nil
+}
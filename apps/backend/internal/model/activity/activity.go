@@ -0,0 +1,183 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+type Action string
+
+const (
+	ActionCreated       Action = "created"
+	ActionUpdated       Action = "updated"
+	ActionStatusChanged Action = "status_changed"
+	ActionDeleted       Action = "deleted"
+	// ActionBumped marks a todo re-surfaced without any field changing
+	ActionBumped Action = "bumped"
+	// ActionRestored marks a todo taken back out of the trash
+	ActionRestored Action = "restored"
+	// ActionReassigned marks a todo handed to another user; its only change
+	// is userId, which isn't part of the snapshot
+	ActionReassigned Action = "reassigned"
+)
+
+// SystemActorID is the actor recorded for changes made by background jobs
+const SystemActorID = "system"
+
+// FieldChange holds the JSON encoded value of a field before and after a mutation
+type FieldChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+type Changes map[string]FieldChange
+
+type Activity struct {
+	model.BaseWithId
+	model.BaseWithCreatedAt
+	Seq     int64     `json:"-" db:"seq"`
+	TodoID  uuid.UUID `json:"todoId" db:"todo_id"`
+	UserID  string    `json:"userId" db:"user_id"`
+	ActorID string    `json:"actorId" db:"actor_id"`
+	Action  Action    `json:"action" db:"action"`
+	Changes Changes   `json:"changes" db:"changes"`
+}
+
+// Snapshot is the JSON encoded value of every tracked todo field
+type Snapshot map[string]json.RawMessage
+
+// SnapshotTodo captures the user editable fields of a todo
+func SnapshotTodo(t *todo.Todo) Snapshot {
+	fields := map[string]any{
+		"title":        t.Title,
+		"description":  t.Description,
+		"status":       t.Status,
+		"priority":     t.Priority,
+		"dueDate":      t.DueDate,
+		"allDay":       t.AllDay,
+		"deferUntil":   t.DeferUntil,
+		"parentTodoId": t.ParentTodoID,
+		"categoryId":   t.CategoryID,
+		"metadata":     t.Metadata,
+		"checklist":    t.Checklist,
+		"sortOrder":    t.SortOrder,
+	}
+
+	snapshot := make(Snapshot, len(fields))
+	for field, value := range fields {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = json.RawMessage("null")
+		}
+		snapshot[field] = encoded
+	}
+
+	return snapshot
+}
+
+// Diff returns the fields whose values differ between two snapshots. A nil
+// snapshot is treated as every field being null.
+func Diff(before, after Snapshot) Changes {
+	changes := Changes{}
+
+	for field := range union(before, after) {
+		from := valueOrNull(before, field)
+		to := valueOrNull(after, field)
+		if !bytes.Equal(from, to) {
+			changes[field] = FieldChange{From: from, To: to}
+		}
+	}
+
+	return changes
+}
+
+// Reconstruct rewinds the current snapshot through the given activities,
+// which must be ordered newest first, restoring each change's previous value.
+// Changes to fields the snapshot doesn't track are skipped.
+func Reconstruct(current Snapshot, newestFirst []Activity) Snapshot {
+	state := make(Snapshot, len(current))
+	for field, value := range current {
+		state[field] = value
+	}
+
+	for _, entry := range newestFirst {
+		for field, change := range entry.Changes {
+			if _, ok := state[field]; !ok {
+				continue
+			}
+			state[field] = change.From
+		}
+	}
+
+	return state
+}
+
+type FieldDiff struct {
+	Field string          `json:"field"`
+	Then  json.RawMessage `json:"then"`
+	Now   json.RawMessage `json:"now"`
+}
+
+type TodoDiff struct {
+	TodoID       uuid.UUID   `json:"todoId"`
+	FromActivity uuid.UUID   `json:"fromActivityId"`
+	AsOf         time.Time   `json:"asOf"`
+	Then         Snapshot    `json:"then"`
+	Changes      []FieldDiff `json:"changes"`
+}
+
+// FieldDiffs lists the differences between a past and current snapshot, sorted by field name
+func FieldDiffs(then, now Snapshot) []FieldDiff {
+	changes := Diff(then, now)
+
+	diffs := make([]FieldDiff, 0, len(changes))
+	for field, change := range changes {
+		diffs = append(diffs, FieldDiff{Field: field, Then: change.From, Now: change.To})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+
+	return diffs
+}
+
+// ReassignChanges is the change recorded when a todo is handed from one user
+// to another
+func ReassignChanges(fromUserID, toUserID string) Changes {
+	from, _ := json.Marshal(fromUserID)
+	to, _ := json.Marshal(toUserID)
+	return Changes{"userId": {From: from, To: to}}
+}
+
+// ActionFor picks the activity action for an update with the given changes
+func ActionFor(changes Changes) Action {
+	if _, ok := changes["status"]; ok {
+		return ActionStatusChanged
+	}
+	return ActionUpdated
+}
+
+func union(a, b Snapshot) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
+func valueOrNull(s Snapshot, field string) json.RawMessage {
+	if value, ok := s[field]; ok && value != nil {
+		return value
+	}
+	return json.RawMessage("null")
+}
@@ -0,0 +1,176 @@
+package activity_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := &todo.Todo{Title: "Draft report", Status: todo.StatusDraft, Priority: todo.PriorityLow}
+	after := *before
+	after.Title = "Final report"
+
+	changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+
+	require.Len(t, changes, 1)
+	assert.JSONEq(t, `"Draft report"`, string(changes["title"].From))
+	assert.JSONEq(t, `"Final report"`, string(changes["title"].To))
+	assert.Equal(t, activity.ActionUpdated, activity.ActionFor(changes))
+
+	after.Status = todo.StatusActive
+	changes = activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+	assert.Equal(t, activity.ActionStatusChanged, activity.ActionFor(changes))
+}
+
+func TestDiff_ChecklistAndPosition(t *testing.T) {
+	before := &todo.Todo{Title: "Pack", Checklist: []todo.ChecklistItem{}, SortOrder: 3}
+	after := *before
+	after.Checklist = []todo.ChecklistItem{{ID: uuid.New(), Text: "Passport"}}
+	after.SortOrder = 1
+
+	changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+
+	require.Len(t, changes, 2)
+	assert.JSONEq(t, `[]`, string(changes["checklist"].From))
+	assert.JSONEq(t, `3`, string(changes["sortOrder"].From))
+	assert.JSONEq(t, `1`, string(changes["sortOrder"].To))
+	assert.Equal(t, activity.ActionUpdated, activity.ActionFor(changes))
+}
+
+func TestReconstruct(t *testing.T) {
+	todoID := uuid.New()
+
+	// Replay the lifecycle: created, renamed, then reprioritised
+	original := &todo.Todo{Title: "Draft report", Status: todo.StatusDraft, Priority: todo.PriorityLow}
+	created := activity.Activity{
+		TodoID:  todoID,
+		Action:  activity.ActionCreated,
+		Changes: activity.Diff(nil, activity.SnapshotTodo(original)),
+	}
+
+	renamed := *original
+	renamed.Title = "Final report"
+	rename := activity.Activity{
+		TodoID:  todoID,
+		Action:  activity.ActionUpdated,
+		Changes: activity.Diff(activity.SnapshotTodo(original), activity.SnapshotTodo(&renamed)),
+	}
+
+	current := renamed
+	current.Priority = todo.PriorityHigh
+	reprioritise := activity.Activity{
+		TodoID:  todoID,
+		Action:  activity.ActionUpdated,
+		Changes: activity.Diff(activity.SnapshotTodo(&renamed), activity.SnapshotTodo(&current)),
+	}
+
+	now := activity.SnapshotTodo(&current)
+
+	t.Run("as of creation", func(t *testing.T) {
+		then := activity.Reconstruct(now, []activity.Activity{reprioritise, rename})
+
+		assert.JSONEq(t, `"Draft report"`, string(then["title"]))
+		assert.JSONEq(t, `"low"`, string(then["priority"]))
+
+		diffs := activity.FieldDiffs(then, now)
+		require.Len(t, diffs, 2)
+		assert.Equal(t, "priority", diffs[0].Field)
+		assert.JSONEq(t, `"low"`, string(diffs[0].Then))
+		assert.JSONEq(t, `"high"`, string(diffs[0].Now))
+		assert.Equal(t, "title", diffs[1].Field)
+		assert.JSONEq(t, `"Draft report"`, string(diffs[1].Then))
+		assert.JSONEq(t, `"Final report"`, string(diffs[1].Now))
+	})
+
+	t.Run("as of the rename", func(t *testing.T) {
+		then := activity.Reconstruct(now, []activity.Activity{reprioritise})
+
+		diffs := activity.FieldDiffs(then, now)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, "priority", diffs[0].Field)
+	})
+
+	t.Run("as of the latest activity there is no diff", func(t *testing.T) {
+		then := activity.Reconstruct(now, nil)
+		assert.Empty(t, activity.FieldDiffs(then, now))
+	})
+
+	t.Run("untracked fields are skipped", func(t *testing.T) {
+		reassign := activity.Activity{
+			TodoID: todoID,
+			Action: activity.ActionReassigned,
+			Changes: activity.Changes{
+				"userId": {From: json.RawMessage(`"user_a"`), To: json.RawMessage(`"user_b"`)},
+			},
+		}
+
+		then := activity.Reconstruct(now, []activity.Activity{reassign})
+		assert.NotContains(t, then, "userId")
+		assert.Empty(t, activity.FieldDiffs(then, now))
+	})
+
+	t.Run("created entry records every field", func(t *testing.T) {
+		assert.Equal(t, json.RawMessage("null"), created.Changes["title"].From)
+		assert.Contains(t, created.Changes, "priority")
+	})
+}
+
+func TestChanges_Previous(t *testing.T) {
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	before := &todo.Todo{Title: "Pay rent", Status: todo.StatusActive, Priority: todo.PriorityMedium}
+	after := *before
+	after.Status = todo.StatusCompleted
+	after.DueDate = &due
+
+	changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+	previous := changes.Previous()
+
+	require.Len(t, previous, 2)
+	assert.JSONEq(t, `"active"`, string(previous["status"]))
+	assert.JSONEq(t, `null`, string(previous["dueDate"]))
+}
+
+func TestChanges_Applied(t *testing.T) {
+	before := &todo.Todo{Title: "Pay rent", Status: todo.StatusActive, Priority: todo.PriorityMedium}
+	after := *before
+	after.Status = todo.StatusCompleted
+
+	changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+
+	t.Run("the todo as the change left it", func(t *testing.T) {
+		assert.True(t, changes.Applied(activity.SnapshotTodo(&after)))
+	})
+
+	t.Run("stored values may be formatted differently", func(t *testing.T) {
+		stored := activity.Changes{"status": {From: json.RawMessage(`"active"`), To: json.RawMessage(` "completed" `)}}
+		assert.True(t, stored.Applied(activity.SnapshotTodo(&after)))
+	})
+
+	t.Run("a field changed since", func(t *testing.T) {
+		changed := after
+		changed.Status = todo.StatusActive
+		assert.False(t, changes.Applied(activity.SnapshotTodo(&changed)))
+	})
+}
+
+func TestActivity_Undoable(t *testing.T) {
+	for action, want := range map[activity.Action]bool{
+		activity.ActionCreated:       false,
+		activity.ActionUpdated:       true,
+		activity.ActionStatusChanged: true,
+		activity.ActionDeleted:       true,
+		activity.ActionBumped:        false,
+		activity.ActionRestored:      false,
+		activity.ActionReassigned:    false,
+	} {
+		entry := activity.Activity{Action: action}
+		assert.Equal(t, want, entry.Undoable(), action)
+	}
+}
@@ -0,0 +1,151 @@
+package activity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+// ------------------------------------------------------------
+
+type GetTodoDiffQuery struct {
+	TodoID uuid.UUID `param:"id" validate:"required,uuid"`
+	From   uuid.UUID `query:"from" validate:"required,uuid"`
+}
+
+func (q *GetTodoDiffQuery) Validate() error {
+	validate := validator.New()
+	return validate.Struct(q)
+}
+
+// ------------------------------------------------------------
+
+type GetChangesSinceQuery struct {
+	TodoID uuid.UUID  `param:"id" validate:"required,uuid"`
+	Since  *time.Time `query:"since" validate:"required"`
+}
+
+func (q *GetChangesSinceQuery) Validate() error {
+	validate := validator.New()
+	return validate.Struct(q)
+}
+
+// ------------------------------------------------------------
+
+// GetChangeFeedQuery long-polls the user's activity. Since is a token from an
+// earlier response; without one the feed starts from now. Wait is how long to
+// hold the request open for a change, such as "30s".
+type GetChangeFeedQuery struct {
+	Since *string `query:"since"`
+	Wait  *string `query:"wait"`
+	// Token and WaitFor are Since and Wait parsed by Validate
+	Token   *ChangeToken  `query:"-"`
+	WaitFor time.Duration `query:"-"`
+}
+
+func (q *GetChangeFeedQuery) Validate() error {
+	q.Token = nil
+	if q.Since != nil && *q.Since != "" {
+		token, err := ParseChangeToken(*q.Since)
+		if err != nil {
+			code := errs.CodeInvalidField
+			return errs.NewBadRequestError("Invalid change token", true, &code,
+				[]errs.FieldError{{Field: "since", Error: "must be a token from an earlier response"}}, nil)
+		}
+		q.Token = &token
+	}
+
+	q.WaitFor = DefaultFeedWait
+	if q.Wait != nil && *q.Wait != "" {
+		wait, err := time.ParseDuration(*q.Wait)
+		if err != nil || wait < 0 || wait > MaxFeedWait {
+			code := errs.CodeInvalidField
+			return errs.NewBadRequestError("Invalid wait", true, &code,
+				[]errs.FieldError{{Field: "wait", Error: fmt.Sprintf("must be a duration from 0s to %s", MaxFeedWait)}},
+				nil)
+		}
+		q.WaitFor = wait
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetActivitiesQuery struct {
+	Page   *int       `query:"page" validate:"omitempty,min=1"`
+	Limit  *int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	Action *Action    `query:"action" validate:"omitempty,oneof=created updated status_changed deleted bumped restored reassigned"`
+	TodoID *uuid.UUID `query:"todoId" validate:"omitempty,uuid"`
+	From   *time.Time `query:"from"`
+	To     *time.Time `query:"to"`
+}
+
+func (q *GetActivitiesQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return errs.NewBadRequestError("from must not be after to", true, nil,
+			[]errs.FieldError{{Field: "from", Error: "must not be after to"}}, nil)
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 20
+		q.Limit = &defaultLimit
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+// GetTodoActivityQuery pages through one todo's history, newest first. The
+// history stays readable after the todo is deleted.
+type GetTodoActivityQuery struct {
+	TodoID uuid.UUID `param:"id" validate:"required,uuid"`
+	Page   *int      `query:"page" validate:"omitempty,min=1"`
+	Limit  *int      `query:"limit" validate:"omitempty,min=1,max=100"`
+	Action *Action   `query:"action" validate:"omitempty,oneof=created updated status_changed deleted bumped restored reassigned"`
+}
+
+func (q *GetTodoActivityQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 20
+		q.Limit = &defaultLimit
+	}
+
+	return nil
+}
+
+// Query returns the query as a filter on the user's whole activity log
+func (q *GetTodoActivityQuery) Query() *GetActivitiesQuery {
+	return &GetActivitiesQuery{
+		Page:   q.Page,
+		Limit:  q.Limit,
+		Action: q.Action,
+		TodoID: &q.TodoID,
+	}
+}
@@ -0,0 +1,72 @@
+package activity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultFeedWait is how long a change feed request waits for a change
+	// when it doesn't say
+	DefaultFeedWait = 30 * time.Second
+	// MaxFeedWait is the longest a change feed request may wait
+	MaxFeedWait = time.Minute
+	// MaxFeedChanges is the most changes one change feed response carries;
+	// the rest follow on the next request
+	MaxFeedChanges = 100
+	// FeedSettleTime is how far behind the clock a token moves when no
+	// changes arrived, so an activity still being committed isn't skipped
+	FeedSettleTime = 2 * time.Second
+)
+
+// ChangeToken marks how far a client has read its activity: everything up to
+// and including the activity created at At with sequence number Seq.
+// Clients only ever see it encoded, as an opaque string.
+type ChangeToken struct {
+	At  time.Time
+	Seq int64
+}
+
+// TokenFor returns the token positioned just after entry
+func TokenFor(entry *Activity) ChangeToken {
+	return ChangeToken{At: entry.CreatedAt, Seq: entry.Seq}
+}
+
+// Advance returns the token moved up to now less FeedSettleTime, or t itself
+// if it is already past that. Seq is reset since no activity is created at
+// exactly that moment.
+func (t ChangeToken) Advance(now time.Time) ChangeToken {
+	settled := now.Add(-FeedSettleTime)
+	if !settled.After(t.At) {
+		return t
+	}
+	return ChangeToken{At: settled}
+}
+
+func (t ChangeToken) String() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", t.At.UnixMicro(), t.Seq))
+}
+
+// ParseChangeToken decodes a token produced by String
+func ParseChangeToken(s string) (ChangeToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ChangeToken{}, errors.New("malformed change token")
+	}
+
+	var micros, seq int64
+	if _, err := fmt.Sscanf(string(raw), "%d.%d", &micros, &seq); err != nil || seq < 0 {
+		return ChangeToken{}, errors.New("malformed change token")
+	}
+
+	return ChangeToken{At: time.UnixMicro(micros).UTC(), Seq: seq}, nil
+}
+
+// ChangeFeed is a page of the user's activity, oldest first, with the token
+// to read on from
+type ChangeFeed struct {
+	Changes []Activity `json:"changes"`
+	Token   string     `json:"token"`
+}
@@ -0,0 +1,65 @@
+package activity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeToken(t *testing.T) {
+	at := time.Date(2025, 3, 10, 9, 30, 0, 123456000, time.UTC)
+
+	t.Run("round trips through its string form", func(t *testing.T) {
+		token := activity.ChangeToken{At: at, Seq: 42}
+
+		parsed, err := activity.ParseChangeToken(token.String())
+		require.NoError(t, err)
+		assert.True(t, at.Equal(parsed.At))
+		assert.Equal(t, int64(42), parsed.Seq)
+	})
+
+	t.Run("malformed tokens are rejected", func(t *testing.T) {
+		for _, raw := range []string{"not base64!", "bm9wZQ", ""} {
+			_, err := activity.ParseChangeToken(raw)
+			assert.Error(t, err, raw)
+		}
+	})
+
+	t.Run("advance moves to the settled clock but never back", func(t *testing.T) {
+		token := activity.ChangeToken{At: at, Seq: 7}
+
+		advanced := token.Advance(at.Add(time.Minute))
+		assert.True(t, advanced.At.Equal(at.Add(time.Minute-activity.FeedSettleTime)))
+		assert.Zero(t, advanced.Seq)
+
+		assert.Equal(t, token, token.Advance(at.Add(time.Second)))
+	})
+}
+
+func TestGetChangeFeedQuery_Validate(t *testing.T) {
+	t.Run("wait defaults and is parsed", func(t *testing.T) {
+		q := &activity.GetChangeFeedQuery{}
+		require.NoError(t, q.Validate())
+		assert.Equal(t, activity.DefaultFeedWait, q.WaitFor)
+		assert.Nil(t, q.Token)
+
+		wait := "5s"
+		since := activity.ChangeToken{At: time.Now()}.String()
+		q = &activity.GetChangeFeedQuery{Wait: &wait, Since: &since}
+		require.NoError(t, q.Validate())
+		assert.Equal(t, 5*time.Second, q.WaitFor)
+		assert.NotNil(t, q.Token)
+	})
+
+	t.Run("out of range waits and bad tokens are rejected", func(t *testing.T) {
+		for _, wait := range []string{"2m", "-1s", "soon"} {
+			assert.Error(t, (&activity.GetChangeFeedQuery{Wait: &wait}).Validate(), wait)
+		}
+
+		since := "garbage"
+		assert.Error(t, (&activity.GetChangeFeedQuery{Since: &since}).Validate())
+	})
+}
@@ -0,0 +1,152 @@
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// reassignmentFields are the fields that move a todo somewhere else rather
+// than edit it
+var reassignmentFields = map[string]string{
+	"categoryId":   "category",
+	"parentTodoId": "parent todo",
+	"userId":       "owner",
+}
+
+type StatusChange struct {
+	From    todo.Status `json:"from"`
+	To      todo.Status `json:"to"`
+	ActorID string      `json:"actorId"`
+	At      time.Time   `json:"at"`
+}
+
+type Reassignment struct {
+	Field   string          `json:"field"`
+	From    json.RawMessage `json:"from"`
+	To      json.RawMessage `json:"to"`
+	ActorID string          `json:"actorId"`
+	At      time.Time       `json:"at"`
+}
+
+// ChangeSummary digests what happened to a todo since a point in time.
+// Highlights holds one readable line per kind of change, the other fields
+// the details behind them.
+type ChangeSummary struct {
+	TodoID         uuid.UUID             `json:"todoId"`
+	Since          time.Time             `json:"since"`
+	HasChanges     bool                  `json:"hasChanges"`
+	Highlights     []string              `json:"highlights"`
+	StatusChanges  []StatusChange        `json:"statusChanges"`
+	Reassignments  []Reassignment        `json:"reassignments"`
+	EditedFields   []string              `json:"editedFields"`
+	NewComments    []comment.Comment     `json:"newComments"`
+	NewAttachments []todo.TodoAttachment `json:"newAttachments"`
+}
+
+// Summarize builds the change summary for a todo from the activities
+// recorded since the given time, ordered oldest first, and the comments and
+// attachments added after it
+func Summarize(item *todo.PopulatedTodo, since time.Time, oldestFirst []Activity) *ChangeSummary {
+	summary := &ChangeSummary{
+		TodoID:         item.ID,
+		Since:          since,
+		Highlights:     []string{},
+		StatusChanges:  []StatusChange{},
+		Reassignments:  []Reassignment{},
+		EditedFields:   []string{},
+		NewComments:    []comment.Comment{},
+		NewAttachments: []todo.TodoAttachment{},
+	}
+
+	created := false
+	edited := map[string]struct{}{}
+
+	for _, entry := range oldestFirst {
+		if entry.Action == ActionCreated {
+			created = true
+			continue
+		}
+
+		for field, change := range entry.Changes {
+			switch {
+			case field == "status":
+				var from, to todo.Status
+				_ = json.Unmarshal(change.From, &from)
+				_ = json.Unmarshal(change.To, &to)
+				summary.StatusChanges = append(summary.StatusChanges, StatusChange{
+					From:    from,
+					To:      to,
+					ActorID: entry.ActorID,
+					At:      entry.CreatedAt,
+				})
+			case reassignmentFields[field] != "":
+				summary.Reassignments = append(summary.Reassignments, Reassignment{
+					Field:   field,
+					From:    change.From,
+					To:      change.To,
+					ActorID: entry.ActorID,
+					At:      entry.CreatedAt,
+				})
+			default:
+				edited[field] = struct{}{}
+			}
+		}
+	}
+
+	for field := range edited {
+		summary.EditedFields = append(summary.EditedFields, field)
+	}
+	sort.Strings(summary.EditedFields)
+
+	for _, c := range item.Comments {
+		if c.CreatedAt.After(since) {
+			summary.NewComments = append(summary.NewComments, c)
+		}
+	}
+
+	for _, a := range item.Attachments {
+		if a.CreatedAt.After(since) {
+			summary.NewAttachments = append(summary.NewAttachments, a)
+		}
+	}
+
+	if created {
+		summary.Highlights = append(summary.Highlights, "Todo was created")
+	}
+	for _, change := range summary.StatusChanges {
+		summary.Highlights = append(summary.Highlights,
+			fmt.Sprintf("Status changed from %s to %s", change.From, change.To))
+	}
+	for _, change := range summary.Reassignments {
+		summary.Highlights = append(summary.Highlights,
+			fmt.Sprintf("Moved to a different %s", reassignmentFields[change.Field]))
+	}
+	if len(summary.EditedFields) > 0 {
+		summary.Highlights = append(summary.Highlights,
+			"Edited "+strings.Join(summary.EditedFields, ", "))
+	}
+	if n := len(summary.NewComments); n > 0 {
+		summary.Highlights = append(summary.Highlights, plural(n, "new comment"))
+	}
+	if n := len(summary.NewAttachments); n > 0 {
+		summary.Highlights = append(summary.Highlights, plural(n, "new attachment"))
+	}
+
+	summary.HasChanges = len(summary.Highlights) > 0
+
+	return summary
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,88 @@
+package activity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	since := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	before := since.Add(-time.Hour)
+	after := since.Add(time.Hour)
+
+	draft := &todo.Todo{Title: "Draft report", Status: todo.StatusDraft, Priority: todo.PriorityLow}
+	active := *draft
+	active.Status = todo.StatusActive
+	categoryID := uuid.New()
+	moved := active
+	moved.CategoryID = &categoryID
+	moved.Title = "Final report"
+
+	item := &todo.PopulatedTodo{
+		Todo: moved,
+		Comments: []comment.Comment{
+			{Base: model.Base{BaseWithCreatedAt: model.BaseWithCreatedAt{CreatedAt: before}}, Content: "seen"},
+			{Base: model.Base{BaseWithCreatedAt: model.BaseWithCreatedAt{CreatedAt: after}}, Content: "new"},
+		},
+		Attachments: []todo.TodoAttachment{
+			{Base: model.Base{BaseWithCreatedAt: model.BaseWithCreatedAt{CreatedAt: before}}, Name: "old.pdf"},
+		},
+	}
+
+	t.Run("status change and comment after the visit", func(t *testing.T) {
+		changes := activity.Diff(activity.SnapshotTodo(draft), activity.SnapshotTodo(&active))
+		entries := []activity.Activity{{
+			BaseWithCreatedAt: model.BaseWithCreatedAt{CreatedAt: after},
+			ActorID:           "user_2",
+			Action:            activity.ActionFor(changes),
+			Changes:           changes,
+		}}
+
+		summary := activity.Summarize(item, since, entries)
+
+		assert.True(t, summary.HasChanges)
+		require.Len(t, summary.StatusChanges, 1)
+		assert.Equal(t, todo.StatusDraft, summary.StatusChanges[0].From)
+		assert.Equal(t, todo.StatusActive, summary.StatusChanges[0].To)
+		assert.Equal(t, "user_2", summary.StatusChanges[0].ActorID)
+		require.Len(t, summary.NewComments, 1)
+		assert.Equal(t, "new", summary.NewComments[0].Content)
+		assert.Empty(t, summary.NewAttachments)
+		assert.Empty(t, summary.Reassignments)
+		assert.Empty(t, summary.EditedFields)
+		assert.Equal(t, []string{"Status changed from draft to active", "1 new comment"}, summary.Highlights)
+	})
+
+	t.Run("moves are reassignments and other fields are edits", func(t *testing.T) {
+		changes := activity.Diff(activity.SnapshotTodo(&active), activity.SnapshotTodo(&moved))
+		entries := []activity.Activity{{
+			BaseWithCreatedAt: model.BaseWithCreatedAt{CreatedAt: after},
+			Action:            activity.ActionFor(changes),
+			Changes:           changes,
+		}}
+
+		summary := activity.Summarize(item, since, entries)
+
+		require.Len(t, summary.Reassignments, 1)
+		assert.Equal(t, "categoryId", summary.Reassignments[0].Field)
+		assert.Equal(t, []string{"title"}, summary.EditedFields)
+		assert.Contains(t, summary.Highlights, "Moved to a different category")
+		assert.Contains(t, summary.Highlights, "Edited title")
+	})
+
+	t.Run("nothing since the visit", func(t *testing.T) {
+		summary := activity.Summarize(item, after.Add(time.Minute), nil)
+
+		assert.False(t, summary.HasChanges)
+		assert.Empty(t, summary.Highlights)
+		assert.Empty(t, summary.NewComments)
+	})
+}
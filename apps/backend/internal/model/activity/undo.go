@@ -0,0 +1,59 @@
+package activity
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// UndoTokenHeader carries the token that undoes the change a response made
+const UndoTokenHeader = "X-Undo-Token"
+
+// Undoable reports whether the change recorded by the activity can be undone:
+// a field edit, a status change or a delete
+func (a *Activity) Undoable() bool {
+	switch a.Action {
+	case ActionUpdated, ActionStatusChanged, ActionDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Previous returns the value every changed field had before the change
+func (c Changes) Previous() Snapshot {
+	previous := make(Snapshot, len(c))
+	for field, change := range c {
+		if change.From == nil {
+			previous[field] = json.RawMessage("null")
+			continue
+		}
+		previous[field] = change.From
+	}
+	return previous
+}
+
+// Applied reports whether every changed field the snapshot tracks still holds
+// the value the change left it with. Values are compared decoded, since the
+// stored changes may be formatted differently from a fresh snapshot.
+func (c Changes) Applied(current Snapshot) bool {
+	for field, change := range c {
+		value, ok := current[field]
+		if !ok {
+			continue
+		}
+
+		to := change.To
+		if to == nil {
+			to = json.RawMessage("null")
+		}
+
+		var want, got any
+		if json.Unmarshal(to, &want) != nil || json.Unmarshal(value, &got) != nil {
+			return false
+		}
+		if !reflect.DeepEqual(want, got) {
+			return false
+		}
+	}
+	return true
+}
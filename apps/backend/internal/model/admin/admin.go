@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+)
+
+type ImpersonationAction string
+
+const (
+	ImpersonationActionStarted ImpersonationAction = "impersonation_started"
+	ImpersonationActionRequest ImpersonationAction = "impersonated_request"
+)
+
+type ImpersonationAuditLog struct {
+	model.BaseWithId
+	model.BaseWithCreatedAt
+	ImpersonatorID string              `json:"impersonatorId" db:"impersonator_id"`
+	TargetUserID   string              `json:"targetUserId" db:"target_user_id"`
+	Action         ImpersonationAction `json:"action" db:"action"`
+	Method         *string             `json:"method" db:"method"`
+	Path           *string             `json:"path" db:"path"`
+	RequestID      *string             `json:"requestId" db:"request_id"`
+	Allowed        bool                `json:"allowed" db:"allowed"`
+}
+
+// AttachmentAccessLog records a download link for an attachment being handed
+// out
+type AttachmentAccessLog struct {
+	model.BaseWithId
+	model.BaseWithCreatedAt
+	UserID       string    `json:"userId" db:"user_id"`
+	TodoID       uuid.UUID `json:"todoId" db:"todo_id"`
+	AttachmentID uuid.UUID `json:"attachmentId" db:"attachment_id"`
+	IP           *string   `json:"ip" db:"ip"`
+	RequestID    *string   `json:"requestId" db:"request_id"`
+}
+
+// CategoryConflict decides what happens to a category moved to a user who
+// already has one with the same name
+type CategoryConflict string
+
+const (
+	// CategoryConflictMerge files the moved todos under the target's category
+	CategoryConflictMerge CategoryConflict = "merge"
+	// CategoryConflictRename keeps the categories apart, numbering the moved one
+	CategoryConflictRename CategoryConflict = "rename"
+)
+
+// ReassignBatchSize is how many todos or comments move per transaction when
+// reassigning a user's data
+const ReassignBatchSize = 500
+
+// ReassignResult counts what moved from one user to another
+type ReassignResult struct {
+	SourceUserID      string `json:"sourceUserId"`
+	TargetUserID      string `json:"targetUserId"`
+	TodosMoved        int    `json:"todosMoved"`
+	CategoriesMoved   int    `json:"categoriesMoved"`
+	CategoriesMerged  int    `json:"categoriesMerged"`
+	CategoriesRenamed int    `json:"categoriesRenamed"`
+	CommentsMoved     int    `json:"commentsMoved"`
+}
+
+// CategoryReassignment counts the outcome of moving a user's categories
+type CategoryReassignment struct {
+	Moved   int
+	Merged  int
+	Renamed int
+}
+
+// RenameCategory numbers name as "name (2)", "name (3)" and so on until it
+// no longer clashes with a name in taken
+func RenameCategory(name string, taken map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+type ImpersonationToken struct {
+	Token        string    `json:"token"`
+	TargetUserID string    `json:"targetUserId"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	ReadOnly     bool      `json:"readOnly"`
+}
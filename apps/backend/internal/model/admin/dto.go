@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+// ------------------------------------------------------------
+
+type ImpersonateUserPayload struct {
+	UserID string `param:"userId" validate:"required,min=1"`
+}
+
+func (p *ImpersonateUserPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// ReassignUserPayload hands every personal todo of UserID to TargetUserID.
+// The categories those todos are filed under always move with them.
+type ReassignUserPayload struct {
+	UserID       string `param:"id" validate:"required,min=1"`
+	TargetUserID string `json:"targetUserId" validate:"required,min=1,nefield=UserID"`
+	// CategoryConflict defaults to merge
+	CategoryConflict *CategoryConflict `json:"categoryConflict" validate:"omitempty,oneof=merge rename"`
+	// IncludeComments also reattributes the user's comments. Defaults to
+	// false, leaving them credited to their author.
+	IncludeComments *bool `json:"includeComments"`
+}
+
+func (p *ReassignUserPayload) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	if p.CategoryConflict == nil {
+		conflict := CategoryConflictMerge
+		p.CategoryConflict = &conflict
+	}
+	if p.IncludeComments == nil {
+		includeComments := false
+		p.IncludeComments = &includeComments
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+// RecoverAccountPayload restores a deleted account within its retention window
+type RecoverAccountPayload struct {
+	UserID string `param:"id" validate:"required,min=1"`
+}
+
+func (p *RecoverAccountPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetConfigPayload struct{}
+
+func (p *GetConfigPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+// GetAttachmentAccessLogsQuery pages through the attachment access log,
+// newest first, narrowed by any of the filters
+type GetAttachmentAccessLogsQuery struct {
+	Page         *int       `query:"page" validate:"omitempty,min=1"`
+	Limit        *int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	UserID       *string    `query:"userId" validate:"omitempty,min=1"`
+	TodoID       *uuid.UUID `query:"todoId" validate:"omitempty,uuid"`
+	AttachmentID *uuid.UUID `query:"attachmentId" validate:"omitempty,uuid"`
+	From         *time.Time `query:"from"`
+	To           *time.Time `query:"to"`
+}
+
+func (q *GetAttachmentAccessLogsQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return errs.NewBadRequestError("from must not be after to", true, nil,
+			[]errs.FieldError{{Field: "from", Error: "must not be after to"}}, nil)
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 50
+		q.Limit = &defaultLimit
+	}
+
+	return nil
+}
@@ -30,4 +30,40 @@ type PaginatedResponse[T interface{}] struct {
 	Limit      int `json:"limit"`
 	Total      int `json:"total"`
 	TotalPages int `json:"totalPages"`
+	// Skipped counts items on this page left out because they couldn't be
+	// read. It is omitted when the page is complete.
+	Skipped int `json:"skipped,omitempty"`
+	// NextCursor is set on lists paged by cursor while more items follow.
+	// Such lists aren't counted, so Page, Total and TotalPages stay zero.
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+type Pagination struct {
+	Page       int     `json:"page"`
+	Limit      int     `json:"limit"`
+	Total      int     `json:"total"`
+	TotalPages int     `json:"totalPages"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+// PaginatedResponseV2 is the v2 list shape, with paging details nested under
+// pagination instead of sitting beside the data.
+type PaginatedResponseV2[T interface{}] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+	Skipped    int        `json:"skipped,omitempty"`
+}
+
+func (p *PaginatedResponse[T]) ToV2() *PaginatedResponseV2[T] {
+	return &PaginatedResponseV2[T]{
+		Data: p.Data,
+		Pagination: Pagination{
+			Page:       p.Page,
+			Limit:      p.Limit,
+			Total:      p.Total,
+			TotalPages: p.TotalPages,
+			NextCursor: p.NextCursor,
+		},
+		Skipped: p.Skipped,
+	}
 }
@@ -1,6 +1,13 @@
 package category
 
-import "github.com/sriniously/tasker/internal/model"
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/color"
+	"github.com/sriniously/tasker/internal/model"
+)
 
 type Category struct {
 	model.Base
@@ -8,4 +15,108 @@ type Category struct {
 	Name        string  `json:"name" db:"name"`
 	Color       string  `json:"color" db:"color"`
 	Description *string `json:"description" db:"description"`
+	IsInbox     bool    `json:"isInbox" db:"is_inbox"`
+}
+
+type WarningCode string
+
+const (
+	WarningLowContrast WarningCode = "LOW_CONTRAST"
+)
+
+// Warning is a non-blocking notice returned alongside a successful write
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+type CategoryWithWarnings struct {
+	Category
+	Warnings []Warning `json:"warnings"`
+}
+
+// ContrastWarnings warns when categoryColor falls below minRatio contrast
+// against background, where it would be hard to pick out
+func ContrastWarnings(categoryColor, background string, minRatio float64) []Warning {
+	ratio, err := color.ContrastRatio(categoryColor, background)
+	if err != nil || ratio >= minRatio {
+		return []Warning{}
+	}
+
+	return []Warning{{
+		Code: WarningLowContrast,
+		Message: fmt.Sprintf("Color %s has a contrast ratio of %.2f:1 against %s, below the recommended %.1f:1",
+			categoryColor, ratio, background, minRatio),
+	}}
+}
+
+// Defaults for the Inbox category provisioned on a user's first uncategorized todo
+const (
+	InboxName  = "Inbox"
+	InboxColor = "#6b7280"
+)
+
+type ArchiveCategoryTodosResponse struct {
+	CategoryID uuid.UUID `json:"categoryId"`
+	Archived   int       `json:"archived"`
+}
+
+type ReplaceTagResponse struct {
+	CategoryID uuid.UUID `json:"categoryId"`
+	Updated    int       `json:"updated"`
+}
+
+// GroupingRestoreWindow is how long after a category is deleted it can be
+// restored along with its todos
+const GroupingRestoreWindow = 30 * 24 * time.Hour
+
+type RestoreGroupingResponse struct {
+	Category   Category `json:"category"`
+	Reattached int      `json:"reattached"`
+}
+
+type MergeCategoriesResponse struct {
+	SourceID uuid.UUID `json:"sourceId"`
+	TargetID uuid.UUID `json:"targetId"`
+	Moved    int       `json:"moved"`
+}
+
+type ProgressBucket string
+
+const (
+	ProgressBucketDay   ProgressBucket = "day"
+	ProgressBucketWeek  ProgressBucket = "week"
+	ProgressBucketMonth ProgressBucket = "month"
+)
+
+// MaxProgressBuckets caps how many buckets one progress trend covers
+const MaxProgressBuckets = 366
+
+// Count is about how many buckets of this size cover from to to
+func (b ProgressBucket) Count(from, to time.Time) int {
+	switch b {
+	case ProgressBucketWeek:
+		return int(to.Sub(from).Hours()/(24*7)) + 1
+	case ProgressBucketMonth:
+		return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	default:
+		return int(to.Sub(from).Hours()/24) + 1
+	}
+}
+
+// ProgressPoint counts the category's todos created before End and how many
+// of them were completed by then. Weeks start on Monday; buckets are in UTC.
+type ProgressPoint struct {
+	Start     time.Time `json:"start" db:"bucket_start"`
+	End       time.Time `json:"end" db:"bucket_end"`
+	Total     int       `json:"total" db:"total"`
+	Completed int       `json:"completed" db:"completed"`
+}
+
+// ProgressTrend is a category's cumulative completion over time, one point
+// per bucket, for drawing a burndown
+type ProgressTrend struct {
+	CategoryID uuid.UUID       `json:"categoryId"`
+	Bucket     ProgressBucket  `json:"bucket"`
+	Points     []ProgressPoint `json:"points"`
 }
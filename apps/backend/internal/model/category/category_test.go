@@ -0,0 +1,30 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrastWarnings(t *testing.T) {
+	t.Run("near-white color on white is flagged", func(t *testing.T) {
+		warnings := category.ContrastWarnings("#fafafa", "#ffffff", 3)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, category.WarningLowContrast, warnings[0].Code)
+		assert.Contains(t, warnings[0].Message, "#fafafa")
+	})
+
+	t.Run("black on white passes", func(t *testing.T) {
+		warnings := category.ContrastWarnings("#000000", "#ffffff", 3)
+		assert.NotNil(t, warnings)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("threshold is respected", func(t *testing.T) {
+		// #767676 on white is about 4.54:1
+		assert.Empty(t, category.ContrastWarnings("#767676", "#ffffff", 4.5))
+		assert.Len(t, category.ContrastWarnings("#767676", "#ffffff", 7), 1)
+	})
+}
@@ -1,8 +1,12 @@
 package category
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
 )
 
 // ------------------------------------------------------------
@@ -78,3 +82,99 @@ func (p *DeleteCategoryPayload) Validate() error {
 	validate := validator.New()
 	return validate.Struct(p)
 }
+
+// ------------------------------------------------------------
+
+type ArchiveCategoryTodosPayload struct {
+	ID            uuid.UUID `param:"id" validate:"required,uuid"`
+	OnlyCompleted bool      `json:"onlyCompleted"`
+}
+
+func (p *ArchiveCategoryTodosPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// ReplaceTagPayload renames OldTag to NewTag on every todo in the category.
+// An empty NewTag removes the tag instead.
+type ReplaceTagPayload struct {
+	ID     uuid.UUID `param:"id" validate:"required,uuid"`
+	OldTag string    `json:"oldTag" validate:"required,min=1"`
+	NewTag string    `json:"newTag" validate:"nefield=OldTag"`
+}
+
+func (p *ReplaceTagPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// MergeCategoriesPayload moves the todos of category ID into TargetID and
+// deletes category ID
+type MergeCategoriesPayload struct {
+	ID       uuid.UUID `param:"id" validate:"required,uuid"`
+	TargetID uuid.UUID `json:"targetId" validate:"required,uuid,nefield=ID"`
+}
+
+func (p *MergeCategoriesPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// RestoreGroupingPayload names a recently deleted category to bring back
+type RestoreGroupingPayload struct {
+	CategoryID uuid.UUID `json:"categoryId" validate:"required"`
+}
+
+func (p *RestoreGroupingPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// GetProgressTrendQuery covers the last 30 days by day unless told otherwise
+type GetProgressTrendQuery struct {
+	ID     uuid.UUID       `param:"id" validate:"required,uuid"`
+	From   *time.Time      `query:"from"`
+	To     *time.Time      `query:"to"`
+	Bucket *ProgressBucket `query:"bucket" validate:"omitempty,oneof=day week month"`
+}
+
+func (q *GetProgressTrendQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.Bucket == nil {
+		bucket := ProgressBucketDay
+		q.Bucket = &bucket
+	}
+	if q.To == nil {
+		to := time.Now()
+		q.To = &to
+	}
+	if q.From == nil {
+		from := q.To.AddDate(0, 0, -30)
+		q.From = &from
+	}
+
+	if q.From.After(*q.To) {
+		return errs.NewBadRequestError("from must not be after to", true, nil,
+			[]errs.FieldError{{Field: "from", Error: "must not be after to"}}, nil)
+	}
+
+	if q.Bucket.Count(*q.From, *q.To) > MaxProgressBuckets {
+		return errs.NewBadRequestError(fmt.Sprintf("The range spans more than %d buckets", MaxProgressBuckets), true,
+			nil, []errs.FieldError{{Field: "from", Error: "use a shorter range or a larger bucket"}}, nil)
+	}
+
+	return nil
+}
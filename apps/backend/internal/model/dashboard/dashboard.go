@@ -0,0 +1,109 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// Section names a part of the dashboard that is loaded on its own
+type Section string
+
+const (
+	SectionStats   Section = "stats"
+	SectionOverdue Section = "overdue"
+	SectionStreak  Section = "streak"
+)
+
+// SectionStatus says why a section is missing from the dashboard
+type SectionStatus string
+
+const (
+	SectionTimedOut SectionStatus = "timeout"
+	SectionFailed   SectionStatus = "error"
+)
+
+type SectionError struct {
+	Status  SectionStatus `json:"status"`
+	Message string        `json:"message"`
+}
+
+// Dashboard gathers the overview sections in one response. A section that
+// failed or timed out is null, with the reason in SectionErrors.
+type Dashboard struct {
+	Stats         *todo.TodoStats          `json:"stats"`
+	Overdue       *todo.OverdueBuckets     `json:"overdue"`
+	Streak        *streak.Streak           `json:"streak"`
+	SectionErrors map[Section]SectionError `json:"sectionErrors,omitempty"`
+}
+
+// Loader fetches one section. Load should give up once ctx is done.
+type Loader struct {
+	Section Section
+	Load    func(ctx context.Context) (any, error)
+}
+
+// Result is what a Loader produced. Err and Failure are set when it didn't
+// produce a value.
+type Result struct {
+	Section Section
+	Value   any
+	Err     error
+	Failure *SectionError
+}
+
+// Compose runs the loaders concurrently, each with timeoutFor(section) to
+// finish, and returns their results in the order given. It returns as soon as
+// every section has finished or run out of time; a loader that ignores its
+// context is left running and its value discarded.
+func Compose(ctx context.Context, loaders []Loader, timeoutFor func(Section) time.Duration) []Result {
+	outcomes := make([]chan Result, len(loaders))
+
+	for i, loader := range loaders {
+		outcomes[i] = make(chan Result, 1)
+
+		go func(loader Loader, outcome chan<- Result) {
+			timeout := timeoutFor(loader.Section)
+			sectionCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan Result, 1)
+			go func() {
+				value, err := loader.Load(sectionCtx)
+				done <- Result{Section: loader.Section, Value: value, Err: err}
+			}()
+
+			var result Result
+			select {
+			case result = <-done:
+			case <-sectionCtx.Done():
+				result = Result{Section: loader.Section, Err: sectionCtx.Err()}
+			}
+
+			if result.Err != nil {
+				result.Value = nil
+				result.Failure = &SectionError{Status: SectionFailed, Message: "could not be loaded"}
+				if errors.Is(result.Err, context.DeadlineExceeded) ||
+					errors.Is(sectionCtx.Err(), context.DeadlineExceeded) {
+					result.Failure = &SectionError{
+						Status:  SectionTimedOut,
+						Message: fmt.Sprintf("did not load within %s", timeout),
+					}
+				}
+			}
+
+			outcome <- result
+		}(loader, outcomes[i])
+	}
+
+	results := make([]Result, len(loaders))
+	for i := range outcomes {
+		results[i] = <-outcomes[i]
+	}
+
+	return results
+}
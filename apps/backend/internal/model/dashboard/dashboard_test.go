@@ -0,0 +1,93 @@
+package dashboard_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	timeouts := func(slow time.Duration) func(dashboard.Section) time.Duration {
+		return func(section dashboard.Section) time.Duration {
+			if section == dashboard.SectionStreak {
+				return slow
+			}
+			return time.Second
+		}
+	}
+
+	value := func(v any) func(context.Context) (any, error) {
+		return func(context.Context) (any, error) { return v, nil }
+	}
+
+	t.Run("a slow section times out without holding up the rest", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		loaders := []dashboard.Loader{
+			{Section: dashboard.SectionStats, Load: value("stats")},
+			{Section: dashboard.SectionOverdue, Load: value("overdue")},
+			{Section: dashboard.SectionStreak, Load: func(context.Context) (any, error) {
+				// Ignores its context, as a stuck query might
+				<-release
+				return "streak", nil
+			}},
+		}
+
+		start := time.Now()
+		results := dashboard.Compose(context.Background(), loaders, timeouts(50*time.Millisecond))
+		elapsed := time.Since(start)
+
+		assert.Less(t, elapsed, 500*time.Millisecond)
+		require.Len(t, results, 3)
+
+		assert.Equal(t, "stats", results[0].Value)
+		assert.Nil(t, results[0].Failure)
+		assert.Equal(t, "overdue", results[1].Value)
+		assert.Nil(t, results[1].Failure)
+
+		assert.Equal(t, dashboard.SectionStreak, results[2].Section)
+		assert.Nil(t, results[2].Value)
+		require.NotNil(t, results[2].Failure)
+		assert.Equal(t, dashboard.SectionTimedOut, results[2].Failure.Status)
+		assert.ErrorIs(t, results[2].Err, context.DeadlineExceeded)
+	})
+
+	t.Run("a section that honors its context times out too", func(t *testing.T) {
+		loaders := []dashboard.Loader{
+			{Section: dashboard.SectionStats, Load: value("stats")},
+			{Section: dashboard.SectionStreak, Load: func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}},
+		}
+
+		results := dashboard.Compose(context.Background(), loaders, timeouts(20*time.Millisecond))
+
+		assert.Equal(t, "stats", results[0].Value)
+		require.NotNil(t, results[1].Failure)
+		assert.Equal(t, dashboard.SectionTimedOut, results[1].Failure.Status)
+	})
+
+	t.Run("a failing section is reported as an error", func(t *testing.T) {
+		loaders := []dashboard.Loader{
+			{Section: dashboard.SectionStats, Load: func(context.Context) (any, error) {
+				return "partial", errors.New("connection reset")
+			}},
+			{Section: dashboard.SectionOverdue, Load: value("overdue")},
+		}
+
+		results := dashboard.Compose(context.Background(), loaders, timeouts(time.Second))
+
+		assert.Nil(t, results[0].Value)
+		require.NotNil(t, results[0].Failure)
+		assert.Equal(t, dashboard.SectionFailed, results[0].Failure.Status)
+		assert.NotContains(t, results[0].Failure.Message, "connection reset")
+		assert.Equal(t, "overdue", results[1].Value)
+	})
+}
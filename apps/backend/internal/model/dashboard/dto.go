@@ -0,0 +1,39 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+// ------------------------------------------------------------
+
+type GetDashboardPayload struct{}
+
+func (p *GetDashboardPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+// GetWeeklyReviewQuery picks the week to review, as an ISO week such as
+// 2025-W10 or any date within it. It defaults to the current week.
+type GetWeeklyReviewQuery struct {
+	Week *string `query:"week"`
+}
+
+func (q *GetWeeklyReviewQuery) Validate() error {
+	if q.Week == nil {
+		return nil
+	}
+
+	// The format doesn't depend on the timezone the week is later read in
+	if _, err := ParseReviewWeek(*q.Week, time.UTC); err != nil {
+		code := errs.CodeInvalidField
+		return errs.NewBadRequestError("Invalid week", true, &code,
+			[]errs.FieldError{{Field: "week", Error: "must be an ISO week such as 2025-W10 or a date such as 2025-03-05"}},
+			nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// ReviewSectionLimit caps the todos listed in each section of a weekly
+// review; the section's Total still counts all of them
+const ReviewSectionLimit = 100
+
+type ReviewSection struct {
+	Total int                  `json:"total"`
+	Todos []todo.PopulatedTodo `json:"todos"`
+}
+
+// WeeklyReview gathers what a weekly review goes through. Completed and
+// Upcoming belong to the reviewed week and the one after it; Overdue and
+// NoNextAction describe the open todos as they stand when the review is
+// generated.
+type WeeklyReview struct {
+	WeekStart time.Time `json:"weekStart"`
+	WeekEnd   time.Time `json:"weekEnd"`
+	Timezone  string    `json:"timezone"`
+	// Completed are the todos completed during the week
+	Completed ReviewSection `json:"completed"`
+	// Overdue are the open todos past their due date
+	Overdue ReviewSection `json:"overdue"`
+	// Upcoming are the open todos due in the week after the reviewed one
+	Upcoming ReviewSection `json:"upcoming"`
+	// NoNextAction are the open todos with neither a due date nor subtasks
+	NoNextAction ReviewSection `json:"noNextAction"`
+}
+
+// ReviewWeek returns the bounds of the Monday to Sunday week containing day
+// in loc: midnight of its Monday and midnight of the Monday after. The week
+// is 167 or 169 hours long when it crosses a daylight saving change.
+func ReviewWeek(day time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := day.In(loc)
+	sinceMonday := (int(local.Weekday()) + 6) % 7
+
+	start := time.Date(local.Year(), local.Month(), local.Day()-sinceMonday, 0, 0, 0, 0, loc)
+	end := time.Date(local.Year(), local.Month(), local.Day()-sinceMonday+7, 0, 0, 0, 0, loc)
+	return start, end
+}
+
+// ParseReviewWeek reads a week given either as an ISO week such as 2025-W10
+// or as any date within it such as 2025-03-05, returning a day in that week
+// in loc
+func ParseReviewWeek(value string, loc *time.Location) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return day, nil
+	}
+
+	var year, week int
+	if n, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week); err != nil || n != 2 ||
+		len(value) != len("2006-W01") {
+		return time.Time{}, fmt.Errorf("week %q is neither an ISO week nor a date", value)
+	}
+
+	// January 4th always falls in the first ISO week of its year
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	day := jan4.AddDate(0, 0, (week-1)*7)
+
+	if y, w := day.ISOWeek(); y != year || w != week {
+		return time.Time{}, fmt.Errorf("year %d has no week %d", year, week)
+	}
+
+	return day, nil
+}
@@ -0,0 +1,88 @@
+package dashboard_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewWeek(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	require.NoError(t, err)
+
+	t.Run("runs from Monday to the Monday after", func(t *testing.T) {
+		start, end := dashboard.ReviewWeek(time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC), time.UTC)
+		assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), start)
+		assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("Sunday belongs to the week before", func(t *testing.T) {
+		start, _ := dashboard.ReviewWeek(time.Date(2025, 3, 9, 23, 59, 0, 0, time.UTC), time.UTC)
+		assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), start)
+	})
+
+	t.Run("the week is that of the user's timezone", func(t *testing.T) {
+		// Sunday evening in UTC is already Monday in Auckland
+		start, end := dashboard.ReviewWeek(time.Date(2025, 3, 9, 20, 0, 0, 0, time.UTC), auckland)
+		assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, auckland), start)
+		assert.Equal(t, time.Date(2025, 3, 17, 0, 0, 0, 0, auckland), end)
+	})
+
+	t.Run("bounds stay at midnight across a daylight saving change", func(t *testing.T) {
+		start, end := dashboard.ReviewWeek(time.Date(2025, 3, 5, 12, 0, 0, 0, newYork), newYork)
+		assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, newYork), start)
+		assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, newYork), end)
+		assert.Equal(t, 167*time.Hour, end.Sub(start))
+	})
+}
+
+func TestParseReviewWeek(t *testing.T) {
+	weekStart := func(t *testing.T, value string) time.Time {
+		t.Helper()
+		day, err := dashboard.ParseReviewWeek(value, time.UTC)
+		require.NoError(t, err)
+		start, _ := dashboard.ReviewWeek(day, time.UTC)
+		return start
+	}
+
+	t.Run("reads a date within the week", func(t *testing.T) {
+		assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), weekStart(t, "2025-03-08"))
+	})
+
+	t.Run("reads an ISO week", func(t *testing.T) {
+		assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), weekStart(t, "2025-W10"))
+		// The first week of 2020 starts in 2019
+		assert.Equal(t, time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC), weekStart(t, "2020-W01"))
+		assert.Equal(t, time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC), weekStart(t, "2026-W53"))
+	})
+
+	t.Run("reads the date in the given timezone", func(t *testing.T) {
+		auckland, err := time.LoadLocation("Pacific/Auckland")
+		require.NoError(t, err)
+
+		day, err := dashboard.ParseReviewWeek("2025-03-10", auckland)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, auckland), day)
+	})
+
+	t.Run("rejects weeks that don't exist and other values", func(t *testing.T) {
+		for _, value := range []string{"2025-W53", "2025-W00", "2025-W1", "2025-13-01", "last week", ""} {
+			_, err := dashboard.ParseReviewWeek(value, time.UTC)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestGetWeeklyReviewQuery_Validate(t *testing.T) {
+	week := "2025-W10"
+	require.NoError(t, (&dashboard.GetWeeklyReviewQuery{Week: &week}).Validate())
+	require.NoError(t, (&dashboard.GetWeeklyReviewQuery{}).Validate())
+
+	invalid := "2025-W60"
+	assert.Error(t, (&dashboard.GetWeeklyReviewQuery{Week: &invalid}).Validate())
+}
@@ -0,0 +1,14 @@
+package notification
+
+import "github.com/go-playground/validator/v10"
+
+// ------------------------------------------------------------
+
+type PreviewPayload struct {
+	Type string `query:"type" validate:"required,oneof=reminder digest"`
+}
+
+func (p *PreviewPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
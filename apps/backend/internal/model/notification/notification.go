@@ -0,0 +1,15 @@
+package notification
+
+const (
+	PreviewTypeReminder = "reminder"
+	PreviewTypeDigest   = "digest"
+)
+
+// Preview is a notification email rendered for the current user without
+// being sent
+type Preview struct {
+	Type    string `json:"type"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
@@ -0,0 +1,61 @@
+package organization
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ------------------------------------------------------------
+
+type CreateOrganizationPayload struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+func (p *CreateOrganizationPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetOrganizationsPayload struct{}
+
+func (p *GetOrganizationsPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetMembersPayload struct {
+	OrgID uuid.UUID `param:"orgId" validate:"required"`
+}
+
+func (p *GetMembersPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type AddMemberPayload struct {
+	OrgID  uuid.UUID `param:"orgId" validate:"required"`
+	UserID string    `json:"userId" validate:"required,min=1"`
+	Role   Role      `json:"role" validate:"required,oneof=admin member viewer"`
+}
+
+func (p *AddMemberPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type RemoveMemberPayload struct {
+	OrgID  uuid.UUID `param:"orgId" validate:"required"`
+	UserID string    `param:"userId" validate:"required,min=1"`
+}
+
+func (p *RemoveMemberPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
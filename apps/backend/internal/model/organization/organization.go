@@ -0,0 +1,59 @@
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+var Roles = []Role{RoleOwner, RoleAdmin, RoleMember, RoleViewer}
+
+func (r Role) IsValid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanWrite reports whether the role may create todos in the organization.
+// Viewers can only read.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleAdmin || r == RoleMember
+}
+
+// CanManageMembers reports whether the role may add and remove members
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+type Organization struct {
+	model.Base
+	Name      string `json:"name" db:"name"`
+	CreatedBy string `json:"createdBy" db:"created_by"`
+}
+
+type Membership struct {
+	OrgID     uuid.UUID `json:"orgId" db:"org_id"`
+	UserID    string    `json:"userId" db:"user_id"`
+	Role      Role      `json:"role" db:"role"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// OrganizationWithRole is an organization as seen by one of its members
+type OrganizationWithRole struct {
+	Organization
+	Role Role `json:"role" db:"role"`
+}
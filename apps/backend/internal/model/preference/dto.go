@@ -0,0 +1,30 @@
+package preference
+
+import (
+	// Embed the IANA database so timezone validation does not depend on the host
+	_ "time/tzdata"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ------------------------------------------------------------
+
+type GetPreferencesPayload struct{}
+
+func (p *GetPreferencesPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type UpdatePreferencesPayload struct {
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"`
+	// DayStartHour is the local hour the user's day rolls over at, for people
+	// who consider 1am to still be part of the previous day
+	DayStartHour *int `json:"dayStartHour" validate:"omitempty,min=0,max=23"`
+}
+
+func (p *UpdatePreferencesPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
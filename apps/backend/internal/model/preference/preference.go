@@ -0,0 +1,42 @@
+package preference
+
+import (
+	"time"
+
+	"github.com/sriniously/tasker/internal/model"
+)
+
+const DefaultTimezone = "UTC"
+
+type Preferences struct {
+	model.BaseWithCreatedAt
+	model.BaseWithUpdatedAt
+	UserID       string `json:"userId" db:"user_id"`
+	Timezone     string `json:"timezone" db:"timezone"`
+	DayStartHour int    `json:"dayStartHour" db:"day_start_hour"`
+}
+
+// Location returns the user's configured timezone, falling back to UTC
+// when it is unset or unknown.
+func (p *Preferences) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// DayClock returns now moved back by the user's day start hour. Its calendar
+// date in Location() is the day the user is in, so between midnight and a
+// later day start it is still the previous day's date.
+func (p *Preferences) DayClock(now time.Time) time.Time {
+	if p == nil {
+		return now
+	}
+	return now.Add(-time.Duration(p.DayStartHour) * time.Hour)
+}
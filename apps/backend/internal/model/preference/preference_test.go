@@ -0,0 +1,37 @@
+package preference_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferences_DayClock(t *testing.T) {
+	prefs := &preference.Preferences{Timezone: "UTC", DayStartHour: 3}
+
+	t.Run("before the day start it is still the previous day", func(t *testing.T) {
+		oneAM := time.Date(2025, time.March, 11, 1, 0, 0, 0, time.UTC)
+
+		today, ok := todo.ResolveDuePreset("today", prefs.DayClock(oneAM), prefs.Location())
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC), today)
+	})
+
+	t.Run("after the day start it is the new day", func(t *testing.T) {
+		fourAM := time.Date(2025, time.March, 11, 4, 0, 0, 0, time.UTC)
+
+		today, ok := todo.ResolveDuePreset("today", prefs.DayClock(fourAM), prefs.Location())
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.March, 11, 0, 0, 0, 0, time.UTC), today)
+	})
+
+	t.Run("midnight is the default", func(t *testing.T) {
+		now := time.Date(2025, time.March, 11, 1, 0, 0, 0, time.UTC)
+
+		assert.Equal(t, now, (&preference.Preferences{}).DayClock(now))
+		assert.Equal(t, now, (*preference.Preferences)(nil).DayClock(now))
+	})
+}
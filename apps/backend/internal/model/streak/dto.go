@@ -0,0 +1,9 @@
+package streak
+
+// ------------------------------------------------------------
+
+type GetStreakPayload struct{}
+
+func (p *GetStreakPayload) Validate() error {
+	return nil
+}
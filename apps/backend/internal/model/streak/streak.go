@@ -0,0 +1,52 @@
+package streak
+
+import "time"
+
+// Streak counts the consecutive days a user ended with no overdue todos, as
+// judged in the user's timezone
+type Streak struct {
+	UserID        string `json:"userId" db:"user_id"`
+	CurrentStreak int    `json:"currentStreak" db:"current_streak"`
+	LongestStreak int    `json:"longestStreak" db:"longest_streak"`
+	// LastDay is the most recent calendar day judged, if any
+	LastDay   *time.Time `json:"lastDay" db:"last_day"`
+	UpdatedAt *time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// DayResult is whether a user ended a calendar day with nothing overdue. Day
+// is the date at midnight UTC.
+type DayResult struct {
+	UserID string    `db:"user_id"`
+	Day    time.Time `db:"day"`
+	Clean  bool      `db:"clean"`
+}
+
+// Record folds the outcome of day into the streak and reports whether it
+// changed anything. Days at or before LastDay were already counted and are
+// ignored. A clean day extends the streak only when it directly follows
+// LastDay; after a gap, such as the job not running, it starts a new one.
+func (s *Streak) Record(day time.Time, clean bool) bool {
+	day = truncateDay(day)
+
+	if s.LastDay != nil && !day.After(truncateDay(*s.LastDay)) {
+		return false
+	}
+
+	switch {
+	case !clean:
+		s.CurrentStreak = 0
+	case s.LastDay != nil && truncateDay(*s.LastDay).AddDate(0, 0, 1).Equal(day):
+		s.CurrentStreak++
+	default:
+		s.CurrentStreak = 1
+	}
+
+	s.LongestStreak = max(s.LongestStreak, s.CurrentStreak)
+	s.LastDay = &day
+
+	return true
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,59 @@
+package streak_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreak_Record(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2025, time.March, n, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("consecutive clean days build the streak and a bad day resets it", func(t *testing.T) {
+		s := &streak.Streak{}
+
+		outcomes := []bool{true, true, true, false, true, true}
+		var current, longest []int
+		for i, clean := range outcomes {
+			assert.True(t, s.Record(day(i+1), clean))
+			current = append(current, s.CurrentStreak)
+			longest = append(longest, s.LongestStreak)
+		}
+
+		assert.Equal(t, []int{1, 2, 3, 0, 1, 2}, current)
+		assert.Equal(t, []int{1, 2, 3, 3, 3, 3}, longest)
+		assert.Equal(t, day(6), *s.LastDay)
+	})
+
+	t.Run("a day already counted is ignored", func(t *testing.T) {
+		s := &streak.Streak{}
+		s.Record(day(1), true)
+		s.Record(day(2), true)
+
+		assert.False(t, s.Record(day(2), false))
+		assert.False(t, s.Record(day(1), false))
+		assert.Equal(t, 2, s.CurrentStreak)
+	})
+
+	t.Run("a gap starts a new streak", func(t *testing.T) {
+		s := &streak.Streak{}
+		s.Record(day(1), true)
+		s.Record(day(2), true)
+		s.Record(day(5), true)
+
+		assert.Equal(t, 1, s.CurrentStreak)
+		assert.Equal(t, 2, s.LongestStreak)
+	})
+
+	t.Run("time of day on the recorded date doesn't matter", func(t *testing.T) {
+		s := &streak.Streak{}
+		s.Record(day(1).Add(23*time.Hour), true)
+		s.Record(day(2), true)
+
+		assert.Equal(t, 2, s.CurrentStreak)
+	})
+}
@@ -0,0 +1,56 @@
+package tag
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// ------------------------------------------------------------
+
+type GetTagsQuery struct {
+	Search *string `query:"search" validate:"omitempty,min=1"`
+	// Sort orders by name, or by usage with the most used first
+	Sort *string `query:"sort" validate:"omitempty,oneof=name usage"`
+}
+
+func (q *GetTagsQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.Sort == nil {
+		defaultSort := "name"
+		q.Sort = &defaultSort
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type RenameTagPayload struct {
+	ID   uuid.UUID `param:"id" validate:"required,uuid"`
+	Name string    `json:"name" validate:"required,min=1,max=100"`
+}
+
+func (p *RenameTagPayload) Validate() error {
+	// The new name is stored on todos, so it is cleaned like any written tag
+	p.Name = todo.NormalizeTag(p.Name)
+
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type DeleteTagPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *DeleteTagPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
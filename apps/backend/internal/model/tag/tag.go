@@ -0,0 +1,24 @@
+package tag
+
+import (
+	"github.com/sriniously/tasker/internal/model"
+)
+
+// Tag is a label carried in the metadata of a user's todos. Tags are matched
+// regardless of letter case; Name is the spelling first seen.
+type Tag struct {
+	model.Base
+	UserID string `json:"userId" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+	// UsageCount is how many of the user's todos, outside the trash, carry
+	// the tag
+	UsageCount int `json:"usageCount" db:"usage_count"`
+}
+
+// RenameTagResponse is the tag a rename left and how many todos it changed.
+// Renaming to the name of another tag merges the two into that one.
+type RenameTagResponse struct {
+	Tag     Tag  `json:"tag"`
+	Merged  bool `json:"merged"`
+	Updated int  `json:"updated"`
+}
@@ -0,0 +1,73 @@
+package template
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ------------------------------------------------------------
+
+// CreateTemplatePayload saves the todo, with its subtasks, as a template
+type CreateTemplatePayload struct {
+	TodoID      uuid.UUID `json:"todoId" validate:"required,uuid"`
+	Name        string    `json:"name" validate:"required,min=1,max=100"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+}
+
+func (p *CreateTemplatePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetTemplatesQuery struct {
+	Search *string `query:"search" validate:"omitempty,min=1"`
+}
+
+func (q *GetTemplatesQuery) Validate() error {
+	validate := validator.New()
+	return validate.Struct(q)
+}
+
+// ------------------------------------------------------------
+
+type GetTemplatePayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *GetTemplatePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type DeleteTemplatePayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *DeleteTemplatePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// InstantiateTemplatePayload creates todos from the template. Start is when
+// the earliest due todo falls due, the others keeping their spacing from it;
+// without it the todos have no due dates. Variables fill in the template's
+// placeholders.
+type InstantiateTemplatePayload struct {
+	ID         uuid.UUID         `param:"id" validate:"required,uuid"`
+	CategoryID *uuid.UUID        `json:"categoryId" validate:"omitempty,uuid"`
+	Start      *time.Time        `json:"start"`
+	Variables  map[string]string `json:"variables" validate:"omitempty,max=50,dive,max=255"`
+}
+
+func (p *InstantiateTemplatePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
@@ -0,0 +1,210 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+// DateVariable is filled in with the start date, as 2006-01-02, unless the
+// caller gives it a value
+const DateVariable = "date"
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_]*)\s*\}\}`)
+
+type Template struct {
+	model.Base
+	UserID      string  `json:"userId" db:"user_id"`
+	Name        string  `json:"name" db:"name"`
+	Description *string `json:"description" db:"description"`
+	Root        Item    `json:"root" db:"root"`
+	// Variables lists the placeholders used in the template's titles and
+	// descriptions, in order of first use
+	Variables []string `json:"variables" db:"-"`
+}
+
+// Item is a todo saved in a template, with its subtasks. DueOffsetMinutes is
+// how long after the template's start the todo falls due; the earliest due
+// todo of a saved tree starts at zero.
+type Item struct {
+	Title            string         `json:"title"`
+	Description      *string        `json:"description"`
+	Priority         todo.Priority  `json:"priority"`
+	AllDay           bool           `json:"allDay"`
+	DueOffsetMinutes *int           `json:"dueOffsetMinutes"`
+	Metadata         *todo.Metadata `json:"metadata"`
+	Checklist        []string       `json:"checklist"`
+	Children         []Item         `json:"children"`
+}
+
+// Draft is a todo about to be created from a template
+type Draft struct {
+	Payload   todo.CreateTodoPayload
+	Checklist []string
+	Children  []Draft
+}
+
+// FromTodos saves root and its subtree, shallowest first as the repository
+// returns it, as a template item. Progress isn't kept: checklist items are
+// saved unticked and the category a todo was in before isn't remembered.
+func FromTodos(root todo.Todo, subtree []todo.Todo) Item {
+	all := append([]todo.Todo{root}, subtree...)
+
+	var start *time.Time
+	for _, t := range all {
+		if t.DueDate != nil && (start == nil || t.DueDate.Before(*start)) {
+			start = t.DueDate
+		}
+	}
+
+	items := make(map[string]*Item, len(all))
+	children := make(map[string][]string, len(all))
+
+	for _, t := range all {
+		item := &Item{
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			AllDay:      t.AllDay,
+			Checklist:   []string{},
+		}
+		if t.DueDate != nil {
+			offset := int(t.DueDate.Sub(*start).Minutes())
+			item.DueOffsetMinutes = &offset
+		}
+		if t.Metadata != nil {
+			metadata := *t.Metadata
+			metadata.FormerCategory = nil
+			item.Metadata = &metadata
+		}
+		for _, step := range t.Checklist {
+			item.Checklist = append(item.Checklist, step.Text)
+		}
+
+		items[t.ID.String()] = item
+		if t.ID != root.ID && t.ParentTodoID != nil {
+			parentID := t.ParentTodoID.String()
+			children[parentID] = append(children[parentID], t.ID.String())
+		}
+	}
+
+	var build func(id string) Item
+	build = func(id string) Item {
+		item := *items[id]
+		item.Children = make([]Item, 0, len(children[id]))
+		for _, childID := range children[id] {
+			item.Children = append(item.Children, build(childID))
+		}
+		return item
+	}
+
+	return build(root.ID.String())
+}
+
+// Variables lists the placeholders used in the item's and its subtasks'
+// titles and descriptions, in order of first use
+func (i Item) Variables() []string {
+	var names []string
+
+	var walk func(item Item)
+	walk = func(item Item) {
+		texts := []string{item.Title}
+		if item.Description != nil {
+			texts = append(texts, *item.Description)
+		}
+		for _, text := range texts {
+			for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+				if !slices.Contains(names, match[1]) {
+					names = append(names, match[1])
+				}
+			}
+		}
+		for _, child := range item.Children {
+			walk(child)
+		}
+	}
+	walk(i)
+
+	return names
+}
+
+// Render turns the item into drafts of the todos to create, filling in the
+// placeholders from variables. Due dates are set from their offsets after
+// start, or left out when start is nil. Every placeholder needs a value.
+func (i Item) Render(start *time.Time, now time.Time, variables map[string]string) (*Draft, error) {
+	values := map[string]string{DateVariable: now.Format(time.DateOnly)}
+	if start != nil {
+		values[DateVariable] = start.Format(time.DateOnly)
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+
+	var missing []errs.FieldError
+	for _, name := range i.Variables() {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, errs.FieldError{
+				Field: "variables." + name,
+				Error: "is used by the template and needs a value",
+			})
+		}
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for _, fieldErr := range missing {
+			names = append(names, strings.TrimPrefix(fieldErr.Field, "variables."))
+		}
+		code := errs.CodeMissingVariables
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("Missing values for template variables: %s", strings.Join(names, ", ")),
+			true, &code, missing, nil,
+		)
+	}
+
+	expand := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			return values[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+		})
+	}
+
+	var render func(item Item) Draft
+	render = func(item Item) Draft {
+		priority := item.Priority
+		if priority == "" {
+			priority = todo.PriorityMedium
+		}
+		allDay := item.AllDay
+
+		draft := Draft{
+			Payload: todo.CreateTodoPayload{
+				Title:    expand(item.Title),
+				Priority: &priority,
+				AllDay:   &allDay,
+				Metadata: item.Metadata,
+			},
+			Checklist: item.Checklist,
+			Children:  make([]Draft, 0, len(item.Children)),
+		}
+		if item.Description != nil {
+			description := expand(*item.Description)
+			draft.Payload.Description = &description
+		}
+		if start != nil && item.DueOffsetMinutes != nil {
+			due := start.Add(time.Duration(*item.DueOffsetMinutes) * time.Minute)
+			draft.Payload.DueDate = &due
+		}
+		for _, child := range item.Children {
+			draft.Children = append(draft.Children, render(child))
+		}
+		return draft
+	}
+
+	draft := render(i)
+	return &draft, nil
+}
@@ -0,0 +1,97 @@
+package template_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTodos(t *testing.T) {
+	due := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	later := due.Add(48 * time.Hour)
+
+	root := todo.Todo{Title: "Onboard {{client}}", Priority: todo.PriorityHigh, DueDate: &later}
+	root.ID = uuid.New()
+	root.Metadata = &todo.Metadata{
+		Tags:           []string{"clients"},
+		FormerCategory: &todo.FormerCategory{Name: "Old"},
+	}
+	root.Checklist = []todo.ChecklistItem{{ID: uuid.New(), Text: "Send contract", Done: true}}
+
+	child := todo.Todo{Title: "Kickoff call", Priority: todo.PriorityMedium, DueDate: &due, ParentTodoID: &root.ID}
+	child.ID = uuid.New()
+
+	item := template.FromTodos(root, []todo.Todo{child})
+
+	assert.Equal(t, "Onboard {{client}}", item.Title)
+	assert.Equal(t, []string{"Send contract"}, item.Checklist)
+	assert.Equal(t, []string{"clients"}, item.Metadata.Tags)
+	assert.Nil(t, item.Metadata.FormerCategory)
+	require.NotNil(t, item.DueOffsetMinutes)
+	assert.Equal(t, 48*60, *item.DueOffsetMinutes)
+
+	require.Len(t, item.Children, 1)
+	assert.Equal(t, "Kickoff call", item.Children[0].Title)
+	require.NotNil(t, item.Children[0].DueOffsetMinutes)
+	assert.Equal(t, 0, *item.Children[0].DueOffsetMinutes)
+}
+
+func TestItem_Render(t *testing.T) {
+	offset, childOffset := 60, 0
+	description := "Prepared on {{date}} for {{ client }}"
+	item := template.Item{
+		Title:            "Onboard {{client}}",
+		Description:      &description,
+		DueOffsetMinutes: &offset,
+		Checklist:        []string{"Send contract"},
+		Children: []template.Item{
+			{Title: "Call {{contact}}", Priority: todo.PriorityLow, DueOffsetMinutes: &childOffset},
+		},
+	}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("lists variables in order of first use", func(t *testing.T) {
+		assert.Equal(t, []string{"client", "date", "contact"}, item.Variables())
+	})
+
+	t.Run("fills in variables and due dates", func(t *testing.T) {
+		start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+
+		draft, err := item.Render(&start, now, map[string]string{"client": "Acme", "contact": "Sam"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Onboard Acme", draft.Payload.Title)
+		assert.Equal(t, "Prepared on 2025-03-10 for Acme", *draft.Payload.Description)
+		assert.Equal(t, todo.PriorityMedium, *draft.Payload.Priority)
+		assert.Equal(t, start.Add(time.Hour), *draft.Payload.DueDate)
+		assert.Equal(t, []string{"Send contract"}, draft.Checklist)
+
+		require.Len(t, draft.Children, 1)
+		assert.Equal(t, "Call Sam", draft.Children[0].Payload.Title)
+		assert.Equal(t, todo.PriorityLow, *draft.Children[0].Payload.Priority)
+		assert.Equal(t, start, *draft.Children[0].Payload.DueDate)
+	})
+
+	t.Run("without a start there are no due dates", func(t *testing.T) {
+		draft, err := item.Render(nil, now, map[string]string{"client": "Acme", "contact": "Sam"})
+		require.NoError(t, err)
+		assert.Nil(t, draft.Payload.DueDate)
+		assert.Equal(t, "Prepared on 2025-03-01 for Acme", *draft.Payload.Description)
+	})
+
+	t.Run("missing variables are reported", func(t *testing.T) {
+		_, err := item.Render(nil, now, map[string]string{"client": "Acme"})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeMissingVariables, httpErr.Code)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "variables.contact", httpErr.Errors[0].Field)
+	})
+}
@@ -1,6 +1,11 @@
 package todo
 
 import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/sriniously/tasker/internal/model"
 )
@@ -13,4 +18,62 @@ type TodoAttachment struct {
 	DownloadKey string    `json:"downloadKey" db:"download_key"`
 	FileSize    *int64    `json:"fileSize" db:"file_size"`
 	MimeType    *string   `json:"mimeType" db:"mime_type"`
+	// Transcript is filled in by a background job for audio attachments
+	Transcript    *string    `json:"transcript" db:"transcript"`
+	TranscribedAt *time.Time `json:"transcribedAt" db:"transcribed_at"`
+	// DownloadURL is only filled in for shared views, which can't reach the
+	// authenticated download endpoint
+	DownloadURL *string `json:"downloadUrl,omitempty" db:"-"`
+}
+
+// AttachmentURL is a short-lived link for downloading an attachment
+type AttachmentURL struct {
+	URL string `json:"url"`
+}
+
+// IsAudio reports whether the attachment is an audio file that can be
+// transcribed
+func (a *TodoAttachment) IsAudio() bool {
+	if a.MimeType == nil {
+		return false
+	}
+
+	mimeType := strings.ToLower(*a.MimeType)
+	return strings.HasPrefix(mimeType, "audio/") || mimeType == "application/ogg"
+}
+
+// AttachmentKey returns the S3 key for a new upload of fileName. The random
+// prefix keeps uploads of the same name from overwriting each other; the name
+// is kept only to make the bucket readable.
+func AttachmentKey(fileName string) string {
+	return "todos/attachments/" + uuid.NewString() + "/" + path.Base(fileName)
+}
+
+// DisambiguateName returns name, or name with " (1)", " (2)" and so on added
+// before its extension when a todo already has an attachment called that, so
+// downloads saved side by side don't overwrite each other. Names are compared
+// case-insensitively, as most file systems do.
+func DisambiguateName(name string, existing []string) string {
+	taken := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		taken[strings.ToLower(e)] = true
+	}
+
+	if !taken[strings.ToLower(name)] {
+		return name
+	}
+
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if base == "" {
+		// Dotfiles like ".env" have no extension to keep
+		base, ext = name, ""
+	}
+
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
 }
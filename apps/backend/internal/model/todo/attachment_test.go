@@ -0,0 +1,43 @@
+package todo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentKey(t *testing.T) {
+	first := todo.AttachmentKey("report.pdf")
+	second := todo.AttachmentKey("report.pdf")
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasPrefix(first, "todos/attachments/"))
+	assert.True(t, strings.HasSuffix(first, "/report.pdf"))
+	assert.NotContains(t, todo.AttachmentKey("../../etc/passwd"), "..")
+}
+
+func TestDisambiguateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		want     string
+	}{
+		{"report.pdf", nil, "report.pdf"},
+		{"report.pdf", []string{"notes.txt"}, "report.pdf"},
+		{"report.pdf", []string{"report.pdf"}, "report (1).pdf"},
+		{"report.pdf", []string{"report.pdf", "report (1).pdf"}, "report (2).pdf"},
+		{"report.pdf", []string{"report.pdf", "report (2).pdf"}, "report (1).pdf"},
+		{"Report.PDF", []string{"report.pdf"}, "Report (1).PDF"},
+		{"archive.tar.gz", []string{"archive.tar.gz"}, "archive.tar (1).gz"},
+		{"README", []string{"README"}, "README (1)"},
+		{".env", []string{".env"}, ".env (1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, todo.DisambiguateName(tt.name, tt.existing))
+		})
+	}
+}
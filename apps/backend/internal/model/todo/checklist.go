@@ -0,0 +1,16 @@
+package todo
+
+import "github.com/google/uuid"
+
+// MaxChecklistItems caps a todo's checklist. Anything longer is better
+// modelled as subtasks.
+const MaxChecklistItems = 100
+
+// ChecklistItem is a step within a todo. Checklists are stored on the todo,
+// so they are much lighter than subtasks but have no status, dates or
+// comments of their own.
+type ChecklistItem struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+	Done bool      `json:"done"`
+}
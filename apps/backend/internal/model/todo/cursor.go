@@ -0,0 +1,42 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListCursor is where a keyset page of todos ended: the created_at and id of
+// its last todo. Clients only see it encoded and hand it back unchanged.
+type ListCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c ListCursor) Encode() string {
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// DecodeListCursor reads a cursor produced by Encode
+func DecodeListCursor(value string) (*ListCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is not valid base64: %w", err)
+	}
+
+	var cursor ListCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor is malformed: %w", err)
+	}
+
+	if cursor.CreatedAt.IsZero() || cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("cursor is incomplete")
+	}
+
+	return &cursor, nil
+}
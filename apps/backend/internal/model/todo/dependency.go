@@ -0,0 +1,24 @@
+package todo
+
+import (
+	"github.com/google/uuid"
+)
+
+// DependencyRelation is how the other todo in a link relates to this one
+type DependencyRelation string
+
+const (
+	// RelationBlockedBy makes this todo wait on the other
+	RelationBlockedBy DependencyRelation = "blocked_by"
+	// RelationBlocks makes the other todo wait on this one
+	RelationBlocks DependencyRelation = "blocks"
+)
+
+// Dependencies lists the todos a todo waits on and the todos waiting on it.
+// Blocked is whether any todo it waits on is still open.
+type Dependencies struct {
+	TodoID    uuid.UUID `json:"todoId"`
+	Blocked   bool      `json:"blocked"`
+	BlockedBy []Todo    `json:"blockedBy"`
+	Blocks    []Todo    `json:"blocks"`
+}
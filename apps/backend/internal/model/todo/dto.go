@@ -1,25 +1,155 @@
 package todo
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
 )
 
 // ------------------------------------------------------------
 
+// validateEnums rejects unknown status and priority values with a typed error
+// listing the allowed values, ahead of the generic struct validation.
+func validateEnums(status *Status, priority *Priority) error {
+	if status != nil && !status.IsValid() {
+		allowed := make([]string, 0, len(Statuses))
+		for _, s := range Statuses {
+			allowed = append(allowed, string(s))
+		}
+		code := errs.CodeInvalidStatus
+		return errs.NewBadRequestError(
+			fmt.Sprintf("Invalid status %q, allowed values: %s", *status, strings.Join(allowed, ", ")),
+			true, &code,
+			[]errs.FieldError{{Field: "status", Error: "must be one of: " + strings.Join(allowed, " ")}},
+			nil,
+		)
+	}
+
+	if priority != nil && !priority.IsValid() {
+		allowed := make([]string, 0, len(Priorities))
+		for _, p := range Priorities {
+			allowed = append(allowed, string(p))
+		}
+		code := errs.CodeInvalidPriority
+		return errs.NewBadRequestError(
+			fmt.Sprintf("Invalid priority %q, allowed values: %s", *priority, strings.Join(allowed, ", ")),
+			true, &code,
+			[]errs.FieldError{{Field: "priority", Error: "must be one of: " + strings.Join(allowed, " ")}},
+			nil,
+		)
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
 type CreateTodoPayload struct {
 	Title        string     `json:"title" validate:"required,min=1,max=255"`
 	Description  *string    `json:"description" validate:"omitempty,max=1000"`
 	Priority     *Priority  `json:"priority" validate:"omitempty,oneof=low medium high"`
 	DueDate      *time.Time `json:"dueDate"`
+	AllDay       *bool      `json:"allDay"`
+	DeferUntil   *time.Time `json:"deferUntil"`
 	ParentTodoID *uuid.UUID `json:"parentTodoId" validate:"omitempty,uuid"`
 	CategoryID   *uuid.UUID `json:"categoryId" validate:"omitempty,uuid"`
 	Metadata     *Metadata  `json:"metadata"`
 }
 
 func (p *CreateTodoPayload) Validate() error {
+	if err := validateEnums(nil, p.Priority); err != nil {
+		return err
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	p.Metadata.normalizeTags()
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type CompleteWithFollowUpPayload struct {
+	ID       uuid.UUID         `param:"id" validate:"required,uuid"`
+	FollowUp CreateTodoPayload `json:"followUp"`
+}
+
+func (p *CompleteWithFollowUpPayload) Validate() error {
+	validate := validator.New()
+	if err := validate.Var(p.ID, "required"); err != nil {
+		return err
+	}
+
+	return p.FollowUp.Validate()
+}
+
+// ------------------------------------------------------------
+
+type PromoteTodoPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+	// KeepChildren moves the todo's subtasks up with it. When false they are
+	// handed to its former parent instead. Defaults to true.
+	KeepChildren *bool `json:"keepChildren"`
+}
+
+func (p *PromoteTodoPayload) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	if p.KeepChildren == nil {
+		keepChildren := true
+		p.KeepChildren = &keepChildren
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type BumpTodoPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *BumpTodoPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// CopyFreshPayload copies a todo and its subtasks into CategoryID as new
+// drafts. Due dates are dropped unless ShiftDueDays is set, in which case the
+// earliest lands that many days from now and the rest keep their spacing.
+type CopyFreshPayload struct {
+	ID           uuid.UUID `param:"id" validate:"required,uuid"`
+	CategoryID   uuid.UUID `json:"categoryId" validate:"required,uuid"`
+	ShiftDueDays *int      `json:"shiftDueDays" validate:"omitempty,min=0,max=365"`
+}
+
+func (p *CopyFreshPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type QuickAddTodoPayload struct {
+	Text string `json:"text" validate:"required,min=1,max=1000"`
+}
+
+func (p *QuickAddTodoPayload) Validate() error {
 	validate := validator.New()
 	return validate.Struct(p)
 }
@@ -33,43 +163,98 @@ type UpdateTodoPayload struct {
 	Status       *Status    `json:"status" validate:"omitempty,oneof=draft active completed archived"`
 	Priority     *Priority  `json:"priority" validate:"omitempty,oneof=low medium high"`
 	DueDate      *time.Time `json:"dueDate"`
+	AllDay       *bool      `json:"allDay"`
+	DeferUntil   *time.Time `json:"deferUntil"`
 	ParentTodoID *uuid.UUID `json:"parentTodoId" validate:"omitempty,uuid"`
 	CategoryID   *uuid.UUID `json:"categoryId" validate:"omitempty,uuid"`
 	Metadata     *Metadata  `json:"metadata"`
 }
 
 func (p *UpdateTodoPayload) Validate() error {
+	if err := validateEnums(p.Status, p.Priority); err != nil {
+		return err
+	}
+
 	validate := validator.New()
-	return validate.Struct(p)
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	p.Metadata.normalizeTags()
+
+	return nil
 }
 
 // ------------------------------------------------------------
 
 type GetTodosQuery struct {
-	Page         *int       `query:"page" validate:"omitempty,min=1"`
-	Limit        *int       `query:"limit" validate:"omitempty,min=1,max=100"`
-	Sort         *string    `query:"sort" validate:"omitempty,oneof=created_at updated_at title priority due_date status"`
-	Order        *string    `query:"order" validate:"omitempty,oneof=asc desc"`
-	Search       *string    `query:"search" validate:"omitempty,min=1"`
-	Status       *Status    `query:"status" validate:"omitempty,oneof=draft active completed archived"`
-	Priority     *Priority  `query:"priority" validate:"omitempty,oneof=low medium high"`
-	CategoryID   *uuid.UUID `query:"categoryId" validate:"omitempty,uuid"`
-	ParentTodoID *uuid.UUID `query:"parentTodoId" validate:"omitempty,uuid"`
-	DueFrom      *time.Time `query:"dueFrom"`
-	DueTo        *time.Time `query:"dueTo"`
-	Overdue      *bool      `query:"overdue"`
-	Completed    *bool      `query:"completed"`
+	Page       *int       `query:"page" validate:"omitempty,min=1"`
+	Limit      *int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	Sort       *string    `query:"sort" validate:"omitempty,oneof=created_at updated_at title priority due_date status sort_order"`
+	Order      *string    `query:"order" validate:"omitempty,oneof=asc desc"`
+	Search     *string    `query:"search" validate:"omitempty,min=1"`
+	Status     *Status    `query:"status" validate:"omitempty,oneof=draft active completed archived"`
+	Priority   *Priority  `query:"priority" validate:"omitempty,oneof=low medium high"`
+	CategoryID *uuid.UUID `query:"categoryId" validate:"omitempty,uuid"`
+	// CategoryIDs matches todos in any of the listed categories, given as
+	// repeated categoryIds parameters. IncludeUncategorized adds todos
+	// without a category to the set, or on its own matches only those.
+	CategoryIDs          []uuid.UUID `query:"categoryIds" validate:"omitempty,max=50"`
+	IncludeUncategorized *bool       `query:"includeUncategorized"`
+	ParentTodoID         *uuid.UUID  `query:"parentTodoId" validate:"omitempty,uuid"`
+	DueFrom              *time.Time  `query:"dueFrom"`
+	DueTo                *time.Time  `query:"dueTo"`
+	CreatedFrom          *time.Time  `query:"createdFrom"`
+	CreatedTo            *time.Time  `query:"createdTo"`
+	Overdue              *bool       `query:"overdue"`
+	Completed            *bool       `query:"completed"`
+	CompletedFrom        *time.Time  `query:"completedFrom"`
+	CompletedTo          *time.Time  `query:"completedTo"`
+	// Open matches todos that are neither completed nor archived
+	Open        *bool `query:"open"`
+	HasDueDate  *bool `query:"hasDueDate"`
+	HasSubtasks *bool `query:"hasSubtasks"`
+	// IncludeDeferred also returns todos whose defer_until is still in the future
+	IncludeDeferred *bool `query:"includeDeferred"`
+	// Tags matches todos carrying the listed tags, given as repeated tags
+	// parameters and compared regardless of letter case. TagMatch any, the
+	// default, needs one of them and all needs every one.
+	Tags     []string `query:"tags" validate:"omitempty,max=20,dive,min=1,max=100"`
+	TagMatch *string  `query:"tagMatch" validate:"omitempty,oneof=any all"`
+	// ExcludeBlocked leaves out todos still waiting on another open todo, for
+	// lists of what can be acted on now
+	ExcludeBlocked *bool `query:"excludeBlocked"`
+	// Snapshot reads the page from an open list snapshot, so paging isn't
+	// disturbed by todos changing in between
+	Snapshot *string `query:"snapshot" validate:"omitempty,uuid"`
+	// Cursor switches to keyset pagination, which stays stable while todos
+	// are added: pass it empty for the first page, then the nextCursor of
+	// the page before. It pages by creation time and can't be combined with
+	// page or another sort.
+	Cursor *string `query:"cursor"`
 }
 
 func (q *GetTodosQuery) Validate() error {
+	if err := validateEnums(q.Status, q.Priority); err != nil {
+		return err
+	}
+
 	validate := validator.New()
 
 	if err := validate.Struct(q); err != nil {
 		return err
 	}
 
+	if err := q.validateCombinations(); err != nil {
+		return err
+	}
+
+	if err := q.validateCursor(); err != nil {
+		return err
+	}
+
 	// Set defaults for pagination
-	if q.Page == nil {
+	if q.Page == nil && !q.IsCursorMode() {
 		defaultPage := 1
 		q.Page = &defaultPage
 	}
@@ -89,6 +274,109 @@ func (q *GetTodosQuery) Validate() error {
 	return nil
 }
 
+// IsCursorMode reports whether the list is paged by cursor rather than by
+// page number
+func (q *GetTodosQuery) IsCursorMode() bool {
+	return q.Cursor != nil
+}
+
+// After returns the position the cursor pages on from, or nil for the first
+// page or when paging by page number
+func (q *GetTodosQuery) After() (*ListCursor, error) {
+	if q.Cursor == nil || *q.Cursor == "" {
+		return nil, nil
+	}
+	return DecodeListCursor(*q.Cursor)
+}
+
+func (q *GetTodosQuery) validateCursor() error {
+	if !q.IsCursorMode() {
+		return nil
+	}
+
+	var fieldErrors []errs.FieldError
+
+	if _, err := q.After(); err != nil {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "cursor", Error: "is not a valid cursor"})
+	}
+	if q.Page != nil {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "page", Error: "cannot be combined with cursor"})
+	}
+	if q.Sort != nil && *q.Sort != "created_at" {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "sort", Error: "must be created_at when paging by cursor"})
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+
+	return errs.NewBadRequestError("Invalid cursor pagination", true, nil, fieldErrors, nil)
+}
+
+// validateCombinations rejects filters that can never match together, which
+// would otherwise quietly return an empty page
+func (q *GetTodosQuery) validateCombinations() error {
+	var fieldErrors []errs.FieldError
+
+	if q.Completed != nil && q.Status != nil {
+		if *q.Completed && *q.Status != StatusCompleted {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: "completed",
+				Error: fmt.Sprintf("cannot be true when status is %s", *q.Status),
+			})
+		}
+		if !*q.Completed && *q.Status == StatusCompleted {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: "completed",
+				Error: "cannot be false when status is completed",
+			})
+		}
+	}
+
+	if q.Overdue != nil && *q.Overdue {
+		if q.Status != nil && *q.Status == StatusCompleted {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: "overdue",
+				Error: "cannot be true when status is completed",
+			})
+		}
+		if q.Completed != nil && *q.Completed {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: "overdue",
+				Error: "cannot be true when completed is true",
+			})
+		}
+	}
+
+	if q.DueFrom != nil && q.DueTo != nil && q.DueFrom.After(*q.DueTo) {
+		fieldErrors = append(fieldErrors, errs.FieldError{
+			Field: "dueFrom",
+			Error: "must not be after dueTo",
+		})
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		fieldErrors = append(fieldErrors, errs.FieldError{
+			Field: "createdFrom",
+			Error: "must not be after createdTo",
+		})
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+
+	problems := make([]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		problems = append(problems, fieldErr.Field+" "+fieldErr.Error)
+	}
+
+	return errs.NewBadRequestError(
+		"Contradictory filters: "+strings.Join(problems, "; "),
+		true, nil, fieldErrors, nil,
+	)
+}
+
 // ------------------------------------------------------------
 
 type GetTodoByIDPayload struct {
@@ -102,6 +390,89 @@ func (p *GetTodoByIDPayload) Validate() error {
 
 // ------------------------------------------------------------
 
+type ExportTodoMarkdownPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *ExportTodoMarkdownPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetChildrenQuery struct {
+	ID    uuid.UUID `param:"id" validate:"required,uuid"`
+	Page  *int      `query:"page" validate:"omitempty,min=1"`
+	Limit *int      `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (q *GetChildrenQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 20
+		q.Limit = &defaultLimit
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type RecurrencePreviewPayload struct {
+	Rule RecurrenceRule `json:"rule"`
+	// From is the moment occurrences are counted after. Defaults to now.
+	From *time.Time `json:"from"`
+	// Count defaults to 5 and is capped at MaxRecurrencePreview
+	Count *int `json:"count" validate:"omitempty,min=1"`
+}
+
+func (p *RecurrencePreviewPayload) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	if err := p.Rule.Validate(); err != nil {
+		return err
+	}
+
+	if p.Count == nil {
+		count := 5
+		p.Count = &count
+	}
+	*p.Count = min(*p.Count, MaxRecurrencePreview)
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type ValidateRecurrencePayload struct {
+	Rule RecurrenceRule `json:"rule"`
+}
+
+// Validate only checks the request's shape; problems with the rule itself are
+// part of the result rather than an error
+func (p *ValidateRecurrencePayload) Validate() error {
+	validate := validator.New()
+
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
 type DeleteTodoPayload struct {
 	ID uuid.UUID `param:"id" validate:"required,uuid"`
 }
@@ -113,45 +484,659 @@ func (p *DeleteTodoPayload) Validate() error {
 
 // ------------------------------------------------------------
 
-type GetTodoStatsPayload struct{}
+// GetTrashQuery pages through the todos in the trash, most recently deleted
+// first
+type GetTrashQuery struct {
+	Page  *int `query:"page" validate:"omitempty,min=1"`
+	Limit *int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (q *GetTrashQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 20
+		q.Limit = &defaultLimit
+	}
 
-func (p *GetTodoStatsPayload) Validate() error {
 	return nil
 }
 
 // ------------------------------------------------------------
-// Todo Attachment DTOs
+
+type RestoreTodoPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *RestoreTodoPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
 // ------------------------------------------------------------
 
-type UploadTodoAttachmentPayload struct {
-	TodoID uuid.UUID `param:"id" validate:"required,uuid"`
+type UndoTodoPayload struct {
+	ID    uuid.UUID `param:"id" validate:"required,uuid"`
+	Token string    `json:"token" validate:"required"`
 }
 
-func (p *UploadTodoAttachmentPayload) Validate() error {
+func (p *UndoTodoPayload) Validate() error {
 	validate := validator.New()
 	return validate.Struct(p)
 }
 
 // ------------------------------------------------------------
 
-type DeleteTodoAttachmentPayload struct {
-	TodoID       uuid.UUID `param:"id" validate:"required,uuid"`
-	AttachmentID uuid.UUID `param:"attachmentId" validate:"required,uuid"`
+type PurgeTodoPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
 }
 
-func (p *DeleteTodoAttachmentPayload) Validate() error {
+func (p *PurgeTodoPayload) Validate() error {
 	validate := validator.New()
 	return validate.Struct(p)
 }
 
 // ------------------------------------------------------------
 
-type GetAttachmentPresignedURLPayload struct {
-	TodoID       uuid.UUID `param:"id" validate:"required,uuid"`
-	AttachmentID uuid.UUID `param:"attachmentId" validate:"required,uuid"`
+type GetTodoStatsPayload struct{}
+
+func (p *GetTodoStatsPayload) Validate() error {
+	return nil
 }
 
-func (p *GetAttachmentPresignedURLPayload) Validate() error {
+// ------------------------------------------------------------
+
+type GetCycleTimeQuery struct {
+	// ExcludeOutliers leaves out todos that took longer than the configured
+	// cap to complete. Defaults to true.
+	ExcludeOutliers *bool `query:"excludeOutliers"`
+}
+
+func (q *GetCycleTimeQuery) Validate() error {
+	if q.ExcludeOutliers == nil {
+		excludeOutliers := true
+		q.ExcludeOutliers = &excludeOutliers
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type HasOverduePayload struct{}
+
+func (p *HasOverduePayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetOverdueBucketsQuery struct {
+	// IncludeIDs lists the todo IDs in each bucket alongside the count
+	IncludeIDs *bool `query:"includeIds"`
+}
+
+func (q *GetOverdueBucketsQuery) Validate() error {
+	if q.IncludeIDs == nil {
+		includeIDs := false
+		q.IncludeIDs = &includeIDs
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetDeferredTodosPayload struct{}
+
+func (p *GetDeferredTodosPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetStaleTodosQuery struct {
+	Days *int `query:"days" validate:"omitempty,min=1,max=365"`
+}
+
+func (q *GetStaleTodosQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.Days == nil {
+		defaultDays := 14
+		q.Days = &defaultDays
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetIncompleteTodosQuery struct {
+	// Missing is a comma-separated list of MissingFields. Defaults to all of
+	// them.
+	Missing *string `query:"missing"`
+	// Fields is Missing parsed by Validate
+	Fields []MissingField `query:"-"`
+}
+
+func (q *GetIncompleteTodosQuery) Validate() error {
+	if q.Missing == nil || strings.TrimSpace(*q.Missing) == "" {
+		q.Fields = MissingFields
+		return nil
+	}
+
+	allowed := make([]string, 0, len(MissingFields))
+	for _, f := range MissingFields {
+		allowed = append(allowed, string(f))
+	}
+
+	q.Fields = nil
+	for _, name := range strings.Split(*q.Missing, ",") {
+		field := MissingField(strings.TrimSpace(name))
+		if !field.IsValid() {
+			code := errs.CodeInvalidField
+			return errs.NewBadRequestError(
+				fmt.Sprintf("Invalid field %q, allowed values: %s", field, strings.Join(allowed, ", ")),
+				true, &code,
+				[]errs.FieldError{{Field: "missing", Error: "must be one of: " + strings.Join(allowed, " ")}},
+				nil,
+			)
+		}
+		if !slices.Contains(q.Fields, field) {
+			q.Fields = append(q.Fields, field)
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type CreateFeedTokenPayload struct{}
+
+func (p *CreateFeedTokenPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type RevokeFeedTokenPayload struct{}
+
+func (p *RevokeFeedTokenPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetCompletedFeedQuery struct {
+	Token string `query:"token" validate:"required"`
+}
+
+func (q *GetCompletedFeedQuery) Validate() error {
+	validate := validator.New()
+	return validate.Struct(q)
+}
+
+// ------------------------------------------------------------
+
+type CreateShareLinkPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *CreateShareLinkPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type RevokeShareLinkPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *RevokeShareLinkPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetSharedTodoPayload struct {
+	Token string `param:"token" validate:"required"`
+}
+
+func (p *GetSharedTodoPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type BulkReparentPayload struct {
+	TodoIDs []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+	// ParentTodoID nil promotes the todos to the root
+	ParentTodoID *uuid.UUID `json:"parentTodoId"`
+}
+
+func (p *BulkReparentPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type BulkUpdateStatusPayload struct {
+	TodoIDs []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+	Status  Status      `json:"status" validate:"required,oneof=draft active completed archived"`
+}
+
+func (p *BulkUpdateStatusPayload) Validate() error {
+	if err := validateEnums(&p.Status, nil); err != nil {
+		return err
+	}
+
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+type BulkSetPriorityPayload struct {
+	TodoIDs  []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+	Priority Priority    `json:"priority" validate:"required,oneof=low medium high"`
+}
+
+func (p *BulkSetPriorityPayload) Validate() error {
+	if err := validateEnums(nil, &p.Priority); err != nil {
+		return err
+	}
+
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// BulkArchivePayload lists the todos to archive or unarchive. Todos already
+// in the requested state are skipped rather than rejected.
+type BulkArchivePayload struct {
+	TodoIDs []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+}
+
+func (p *BulkArchivePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// BulkDeletePayload lists the todos to move to the trash. Todos that can't
+// be found are reported rather than failing the request.
+type BulkDeletePayload struct {
+	TodoIDs []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+}
+
+func (p *BulkDeletePayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// BulkReopenPayload lists completed or archived todos to rework. DueDate, when
+// set, replaces the due date of every reopened todo.
+type BulkReopenPayload struct {
+	TodoIDs []uuid.UUID `json:"todoIds" validate:"required,min=1,dive,required"`
+	DueDate *time.Time  `json:"dueDate"`
+}
+
+func (p *BulkReopenPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// BulkOperation is what a filter-driven bulk request does to the matches
+type BulkOperation string
+
+const (
+	BulkOperationComplete BulkOperation = "complete"
+	BulkOperationArchive  BulkOperation = "archive"
+	BulkOperationTag      BulkOperation = "tag"
+)
+
+// TodoFilter carries the list filters of GetTodosQuery in a request body
+type TodoFilter struct {
+	Search               *string     `json:"search" validate:"omitempty,min=1"`
+	Status               *Status     `json:"status" validate:"omitempty,oneof=draft active completed archived"`
+	Priority             *Priority   `json:"priority" validate:"omitempty,oneof=low medium high"`
+	CategoryID           *uuid.UUID  `json:"categoryId" validate:"omitempty,uuid"`
+	CategoryIDs          []uuid.UUID `json:"categoryIds" validate:"omitempty,max=50"`
+	IncludeUncategorized *bool       `json:"includeUncategorized"`
+	Tags                 []string    `json:"tags" validate:"omitempty,max=20,dive,min=1,max=100"`
+	TagMatch             *string     `json:"tagMatch" validate:"omitempty,oneof=any all"`
+	ParentTodoID         *uuid.UUID  `json:"parentTodoId" validate:"omitempty,uuid"`
+	DueFrom              *time.Time  `json:"dueFrom"`
+	DueTo                *time.Time  `json:"dueTo"`
+	CreatedFrom          *time.Time  `json:"createdFrom"`
+	CreatedTo            *time.Time  `json:"createdTo"`
+	Overdue              *bool       `json:"overdue"`
+	Completed            *bool       `json:"completed"`
+	CompletedFrom        *time.Time  `json:"completedFrom"`
+	CompletedTo          *time.Time  `json:"completedTo"`
+	Open                 *bool       `json:"open"`
+	HasDueDate           *bool       `json:"hasDueDate"`
+	HasSubtasks          *bool       `json:"hasSubtasks"`
+	IncludeDeferred      *bool       `json:"includeDeferred"`
+	ExcludeBlocked       *bool       `json:"excludeBlocked"`
+}
+
+// Query returns the filter as list query, so it matches exactly the todos
+// the list would show for it
+func (f *TodoFilter) Query() *GetTodosQuery {
+	return &GetTodosQuery{
+		Search:               f.Search,
+		Status:               f.Status,
+		Priority:             f.Priority,
+		CategoryID:           f.CategoryID,
+		CategoryIDs:          f.CategoryIDs,
+		IncludeUncategorized: f.IncludeUncategorized,
+		Tags:                 f.Tags,
+		TagMatch:             f.TagMatch,
+		ParentTodoID:         f.ParentTodoID,
+		DueFrom:              f.DueFrom,
+		DueTo:                f.DueTo,
+		CreatedFrom:          f.CreatedFrom,
+		CreatedTo:            f.CreatedTo,
+		Overdue:              f.Overdue,
+		Completed:            f.Completed,
+		CompletedFrom:        f.CompletedFrom,
+		CompletedTo:          f.CompletedTo,
+		Open:                 f.Open,
+		HasDueDate:           f.HasDueDate,
+		HasSubtasks:          f.HasSubtasks,
+		IncludeDeferred:      f.IncludeDeferred,
+		ExcludeBlocked:       f.ExcludeBlocked,
+	}
+}
+
+// BulkByFilterPayload applies Operation to every todo matching Filter. Tag is
+// the tag the tag operation adds.
+type BulkByFilterPayload struct {
+	Filter    TodoFilter    `json:"filter"`
+	Operation BulkOperation `json:"operation" validate:"required,oneof=complete archive tag"`
+	Tag       *string       `json:"tag" validate:"required_if=Operation tag"`
+}
+
+func (p *BulkByFilterPayload) Validate() error {
+	if err := validateEnums(p.Filter.Status, p.Filter.Priority); err != nil {
+		return err
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	if p.Tag != nil {
+		tag := NormalizeTag(*p.Tag)
+		if tag == "" && p.Operation == BulkOperationTag {
+			code := errs.CodeInvalidField
+			return errs.NewBadRequestError("Tag must not be blank", true, &code,
+				[]errs.FieldError{{Field: "tag", Error: "must not be blank"}}, nil)
+		}
+		p.Tag = &tag
+	}
+
+	return p.Filter.Query().validateCombinations()
+}
+
+// ------------------------------------------------------------
+
+type GetTodoRemindersPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *GetTodoRemindersPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type CancelTodoReminderPayload struct {
+	ID         uuid.UUID    `param:"id" validate:"required,uuid"`
+	ReminderID ReminderKind `param:"reminderId" validate:"required,oneof=due_soon overdue"`
+}
+
+func (p *CancelTodoReminderPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type AddChecklistItemPayload struct {
+	ID   uuid.UUID `param:"id" validate:"required,uuid"`
+	Text string    `json:"text" validate:"required,min=1,max=255"`
+}
+
+func (p *AddChecklistItemPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// UpdateChecklistItemPayload renames an item, ticks it off or both. Done is
+// set rather than flipped, so retrying a request is harmless.
+type UpdateChecklistItemPayload struct {
+	ID     uuid.UUID `param:"id" validate:"required,uuid"`
+	ItemID uuid.UUID `param:"itemId" validate:"required,uuid"`
+	Text   *string   `json:"text" validate:"omitempty,min=1,max=255"`
+	Done   *bool     `json:"done" validate:"required_without=Text"`
+}
+
+func (p *UpdateChecklistItemPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+type DeleteChecklistItemPayload struct {
+	ID     uuid.UUID `param:"id" validate:"required,uuid"`
+	ItemID uuid.UUID `param:"itemId" validate:"required,uuid"`
+}
+
+func (p *DeleteChecklistItemPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type CreateListSnapshotPayload struct{}
+
+func (p *CreateListSnapshotPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type DeleteListSnapshotPayload struct {
+	Token string `param:"token" validate:"required,uuid"`
+}
+
+func (p *DeleteListSnapshotPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// SnoozeOverduePayload moves every overdue todo to DueDate, or to 9am
+// tomorrow in the user's timezone when it is omitted
+type SnoozeOverduePayload struct {
+	DueDate *time.Time `json:"dueDate"`
+}
+
+func (p *SnoozeOverduePayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+// Todo Attachment DTOs
+// ------------------------------------------------------------
+
+type UploadTodoAttachmentPayload struct {
+	TodoID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *UploadTodoAttachmentPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// MultipartFiles names the uploaded file read besides the payload
+func (p *UploadTodoAttachmentPayload) MultipartFiles() []string {
+	return []string{"file"}
+}
+
+// ------------------------------------------------------------
+
+// ImportICSPayload holds the form fields sent along with an iCalendar file.
+// Without a category the todos go to the inbox like any other new todo.
+type ImportICSPayload struct {
+	DryRun     bool       `form:"dryRun"`
+	CategoryID *uuid.UUID `form:"categoryId" validate:"omitempty,uuid"`
+}
+
+func (p *ImportICSPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// MultipartFiles names the iCalendar file read besides the form fields
+func (p *ImportICSPayload) MultipartFiles() []string {
+	return []string{"file"}
+}
+
+// ------------------------------------------------------------
+
+type DeleteTodoAttachmentPayload struct {
+	TodoID       uuid.UUID `param:"id" validate:"required,uuid"`
+	AttachmentID uuid.UUID `param:"attachmentId" validate:"required,uuid"`
+}
+
+func (p *DeleteTodoAttachmentPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetAttachmentPresignedURLPayload struct {
+	TodoID       uuid.UUID `param:"id" validate:"required,uuid"`
+	AttachmentID uuid.UUID `param:"attachmentId" validate:"required,uuid"`
+}
+
+func (p *GetAttachmentPresignedURLPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// SetPositionPayload moves the todo among its siblings: the subtasks of its
+// parent, or for a top-level todo the top-level todos of its category. It is
+// placed at Index counting from 0, or right before or after another sibling.
+type SetPositionPayload struct {
+	ID       uuid.UUID  `param:"id" validate:"required,uuid"`
+	Index    *int       `json:"index" validate:"omitempty,min=0"`
+	BeforeID *uuid.UUID `json:"beforeId" validate:"omitempty,uuid"`
+	AfterID  *uuid.UUID `json:"afterId" validate:"omitempty,uuid"`
+}
+
+func (p *SetPositionPayload) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	given := 0
+	for _, set := range []bool{p.Index != nil, p.BeforeID != nil, p.AfterID != nil} {
+		if set {
+			given++
+		}
+	}
+
+	if given != 1 {
+		return errs.NewBadRequestError("Give exactly one of index, beforeId or afterId", true, nil,
+			[]errs.FieldError{{Field: "index", Error: "exactly one of index, beforeId or afterId is required"}}, nil)
+	}
+
+	if (p.BeforeID != nil && *p.BeforeID == p.ID) || (p.AfterID != nil && *p.AfterID == p.ID) {
+		return errs.NewBadRequestError("A todo cannot be placed next to itself", true, nil, nil, nil)
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+
+type GetDependenciesPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *GetDependenciesPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+// AddDependencyPayload links the todo to another, either waiting on it
+// (blocked_by) or holding it up (blocks)
+type AddDependencyPayload struct {
+	ID       uuid.UUID          `param:"id" validate:"required,uuid"`
+	TodoID   uuid.UUID          `json:"todoId" validate:"required,uuid"`
+	Relation DependencyRelation `json:"relation" validate:"required,oneof=blocked_by blocks"`
+}
+
+func (p *AddDependencyPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// Link returns the todo that waits and the todo it waits on
+func (p *AddDependencyPayload) Link() (todoID, blockedByID uuid.UUID) {
+	if p.Relation == RelationBlocks {
+		return p.TodoID, p.ID
+	}
+	return p.ID, p.TodoID
+}
+
+// ------------------------------------------------------------
+
+// RemoveDependencyPayload unlinks the two todos, whichever way round the
+// link goes
+type RemoveDependencyPayload struct {
+	ID      uuid.UUID `param:"id" validate:"required,uuid"`
+	OtherID uuid.UUID `param:"otherId" validate:"required,uuid"`
+}
+
+func (p *RemoveDependencyPayload) Validate() error {
 	validate := validator.New()
 	return validate.Struct(p)
 }
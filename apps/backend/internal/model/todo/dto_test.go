@@ -0,0 +1,270 @@
+package todo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bindJSON(t *testing.T, method, body string, payload validation.Validatable, params ...string) error {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	if len(params) == 2 {
+		c.SetParamNames(params[0])
+		c.SetParamValues(params[1])
+	}
+
+	return validation.BindAndValidate(c, payload)
+}
+
+func TestTodoPayload_EnumValidation(t *testing.T) {
+	todoID := "5f0c2a3e-7a1b-4b8e-9d3c-2f1e0a9b8c7d"
+
+	t.Run("unknown status is rejected with INVALID_STATUS", func(t *testing.T) {
+		err := bindJSON(t, http.MethodPatch, `{"status":"foo"}`, &todo.UpdateTodoPayload{}, "id", todoID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, errs.CodeInvalidStatus, httpErr.Code)
+		assert.Contains(t, httpErr.Message, "draft, active, completed, archived")
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "status", httpErr.Errors[0].Field)
+	})
+
+	t.Run("unknown priority is rejected with INVALID_PRIORITY", func(t *testing.T) {
+		err := bindJSON(t, http.MethodPost, `{"title":"Write report","priority":"urgent"}`, &todo.CreateTodoPayload{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, errs.CodeInvalidPriority, httpErr.Code)
+		assert.Contains(t, httpErr.Message, "low, medium, high")
+	})
+
+	t.Run("unknown priority on update is rejected", func(t *testing.T) {
+		err := bindJSON(t, http.MethodPatch, `{"priority":"urgent"}`, &todo.UpdateTodoPayload{}, "id", todoID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInvalidPriority, httpErr.Code)
+	})
+
+	t.Run("unknown priority on bulk update is rejected", func(t *testing.T) {
+		err := bindJSON(t, http.MethodPatch, `{"todoIds":["`+todoID+`"],"priority":"urgent"}`,
+			&todo.BulkSetPriorityPayload{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInvalidPriority, httpErr.Code)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "priority", httpErr.Errors[0].Field)
+	})
+
+	t.Run("known values pass", func(t *testing.T) {
+		err := bindJSON(t, http.MethodPatch, `{"status":"active","priority":"high"}`, &todo.UpdateTodoPayload{}, "id", todoID)
+		assert.NoError(t, err)
+
+		err = bindJSON(t, http.MethodPatch, `{"todoIds":["`+todoID+`"],"priority":"high"}`, &todo.BulkSetPriorityPayload{})
+		assert.NoError(t, err)
+	})
+}
+
+func bindQuery(t *testing.T, query string, payload validation.Validatable) error {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	return validation.BindAndValidate(c, payload)
+}
+
+func TestGetTodosQuery_Combinations(t *testing.T) {
+	t.Run("completed with a non-completed status is rejected", func(t *testing.T) {
+		err := bindQuery(t, "completed=true&status=draft", &todo.GetTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Contains(t, httpErr.Message, "completed cannot be true when status is draft")
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "completed", httpErr.Errors[0].Field)
+	})
+
+	t.Run("overdue completed todos are rejected", func(t *testing.T) {
+		err := bindQuery(t, "overdue=true&status=completed", &todo.GetTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, "overdue", httpErr.Errors[0].Field)
+	})
+
+	t.Run("inverted due date range is rejected", func(t *testing.T) {
+		err := bindQuery(t, "dueFrom=2025-03-10T00:00:00Z&dueTo=2025-03-01T00:00:00Z", &todo.GetTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "dueFrom", httpErr.Errors[0].Field)
+	})
+
+	t.Run("inverted created date range is rejected", func(t *testing.T) {
+		err := bindQuery(t, "createdFrom=2025-03-10T00:00:00Z&createdTo=2025-03-01T00:00:00Z", &todo.GetTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "createdFrom", httpErr.Errors[0].Field)
+	})
+
+	t.Run("consistent filters pass and get defaults", func(t *testing.T) {
+		query := &todo.GetTodosQuery{}
+		err := bindQuery(t, "completed=true&status=completed&dueFrom=2025-03-01T00:00:00Z&dueTo=2025-03-10T00:00:00Z", query)
+		require.NoError(t, err)
+		assert.Equal(t, 1, *query.Page)
+	})
+}
+
+func TestGetTodosQuery_Cursor(t *testing.T) {
+	t.Run("an empty cursor starts cursor pagination without a page", func(t *testing.T) {
+		query := &todo.GetTodosQuery{}
+		require.NoError(t, bindQuery(t, "cursor=", query))
+		assert.True(t, query.IsCursorMode())
+		assert.Nil(t, query.Page)
+
+		after, err := query.After()
+		require.NoError(t, err)
+		assert.Nil(t, after)
+	})
+
+	t.Run("a cursor reads back the position it was made from", func(t *testing.T) {
+		position := todo.ListCursor{
+			CreatedAt: time.Date(2025, 3, 5, 12, 30, 0, 123456000, time.UTC),
+			ID:        uuid.New(),
+		}
+
+		query := &todo.GetTodosQuery{}
+		require.NoError(t, bindQuery(t, "cursor="+position.Encode()+"&order=asc", query))
+
+		after, err := query.After()
+		require.NoError(t, err)
+		assert.True(t, position.CreatedAt.Equal(after.CreatedAt))
+		assert.Equal(t, position.ID, after.ID)
+	})
+
+	t.Run("page, other sorts and garbage are rejected", func(t *testing.T) {
+		err := bindQuery(t, "cursor=not-a-cursor&page=2&sort=title", &todo.GetTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+
+		var fields []string
+		for _, fieldErr := range httpErr.Errors {
+			fields = append(fields, fieldErr.Field)
+		}
+		assert.ElementsMatch(t, []string{"cursor", "page", "sort"}, fields)
+	})
+}
+
+func TestGetIncompleteTodosQuery_Missing(t *testing.T) {
+	t.Run("listed fields are parsed", func(t *testing.T) {
+		query := &todo.GetIncompleteTodosQuery{}
+		require.NoError(t, bindQuery(t, "missing=due_date,%20category,due_date", query))
+		assert.Equal(t, []todo.MissingField{todo.MissingDueDate, todo.MissingCategory}, query.Fields)
+	})
+
+	t.Run("omitted defaults to every field", func(t *testing.T) {
+		query := &todo.GetIncompleteTodosQuery{}
+		require.NoError(t, bindQuery(t, "", query))
+		assert.Equal(t, todo.MissingFields, query.Fields)
+	})
+
+	t.Run("unknown field is rejected with INVALID_FIELD", func(t *testing.T) {
+		err := bindQuery(t, "missing=due_date,title", &todo.GetIncompleteTodosQuery{})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, errs.CodeInvalidField, httpErr.Code)
+		assert.Contains(t, httpErr.Message, `"title"`)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "missing", httpErr.Errors[0].Field)
+	})
+}
+
+func TestUpdateChecklistItemPayload_Validate(t *testing.T) {
+	valid := func() *todo.UpdateChecklistItemPayload {
+		return &todo.UpdateChecklistItemPayload{ID: uuid.New(), ItemID: uuid.New()}
+	}
+
+	t.Run("needs text or done", func(t *testing.T) {
+		assert.Error(t, valid().Validate())
+	})
+
+	t.Run("either one is enough", func(t *testing.T) {
+		done := valid()
+		done.Done = new(bool)
+		assert.NoError(t, done.Validate())
+
+		text := "Renamed"
+		renamed := valid()
+		renamed.Text = &text
+		assert.NoError(t, renamed.Validate())
+	})
+
+	t.Run("empty text is rejected", func(t *testing.T) {
+		text := ""
+		p := valid()
+		p.Text = &text
+		p.Done = new(bool)
+		assert.Error(t, p.Validate())
+	})
+}
+
+func TestBulkByFilterPayload_Validate(t *testing.T) {
+	t.Run("tag operation needs a tag", func(t *testing.T) {
+		assert.Error(t, (&todo.BulkByFilterPayload{Operation: todo.BulkOperationTag}).Validate())
+
+		blank := "   "
+		assert.Error(t, (&todo.BulkByFilterPayload{Operation: todo.BulkOperationTag, Tag: &blank}).Validate())
+
+		tag := "  to   read "
+		p := &todo.BulkByFilterPayload{Operation: todo.BulkOperationTag, Tag: &tag}
+		require.NoError(t, p.Validate())
+		assert.Equal(t, "to read", *p.Tag)
+	})
+
+	t.Run("unknown operation is rejected", func(t *testing.T) {
+		assert.Error(t, (&todo.BulkByFilterPayload{Operation: "delete"}).Validate())
+	})
+
+	t.Run("contradictory filters are rejected", func(t *testing.T) {
+		completed := todo.StatusCompleted
+		overdue := true
+		p := &todo.BulkByFilterPayload{
+			Filter:    todo.TodoFilter{Status: &completed, Overdue: &overdue},
+			Operation: todo.BulkOperationArchive,
+		}
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, p.Validate(), &httpErr)
+		assert.Equal(t, "overdue", httpErr.Errors[0].Field)
+	})
+}
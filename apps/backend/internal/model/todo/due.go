@@ -0,0 +1,108 @@
+package todo
+
+import (
+	"strings"
+	"time"
+)
+
+// DuePresets lists the relative due date keywords ResolveDuePreset understands
+var DuePresets = []string{
+	"today", "tomorrow", "weekend", "next week",
+	"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+}
+
+var weekdayPresets = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ResolveDuePreset turns a relative keyword such as "tomorrow" or "friday"
+// into the start of that calendar day in loc. The result is meant to be
+// stored as an all-day due date. Weekday names always resolve to the next
+// such day after today, and "next week" resolves to the coming Monday.
+func ResolveDuePreset(preset string, now time.Time, loc *time.Location) (time.Time, bool) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	switch key := strings.ToLower(strings.TrimSpace(preset)); key {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "weekend":
+		if today.Weekday() == time.Saturday || today.Weekday() == time.Sunday {
+			return today, true
+		}
+		return nextWeekday(today, time.Saturday), true
+	case "next week", "nextweek":
+		return nextWeekday(today, time.Monday), true
+	default:
+		if weekday, ok := weekdayPresets[key]; ok {
+			return nextWeekday(today, weekday), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// nextWeekday returns the first day strictly after today falling on weekday
+func nextWeekday(today time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// EndOfDay returns when the calendar day of t in loc is over for a user whose
+// days roll over at dayStartHour, which is the following morning when it is
+// later than midnight
+func EndOfDay(t time.Time, loc *time.Location, dayStartHour int) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, dayStartHour, 0, 0, 0, loc)
+}
+
+// SnoozeHour is the local hour overdue todos move to when snoozed without an
+// explicit time
+const SnoozeHour = 9
+
+// DefaultSnoozeUntil returns SnoozeHour tomorrow in loc
+func DefaultSnoozeUntil(now time.Time, loc *time.Location) time.Time {
+	tomorrow, _ := ResolveDuePreset("tomorrow", now, loc)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), SnoozeHour, 0, 0, 0, tomorrow.Location())
+}
+
+// ShiftDueDates moves the due dates of todos so the earliest lands on start,
+// keeping the gaps between them. Todos without a due date are left alone.
+func ShiftDueDates(todos []Todo, start time.Time) {
+	var earliest *time.Time
+	for i := range todos {
+		if due := todos[i].DueDate; due != nil && (earliest == nil || due.Before(*earliest)) {
+			earliest = due
+		}
+	}
+	if earliest == nil {
+		return
+	}
+
+	offset := start.Sub(*earliest)
+	for i := range todos {
+		if todos[i].DueDate != nil {
+			shifted := todos[i].DueDate.Add(offset)
+			todos[i].DueDate = &shifted
+		}
+	}
+}
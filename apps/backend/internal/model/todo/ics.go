@@ -0,0 +1,280 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+// MaxImportFileSize caps the size of an uploaded iCalendar file
+const MaxImportFileSize = 5 << 20
+
+// ImportedTodo is a todo read from an import file, before it is saved
+type ImportedTodo struct {
+	Title       string     `json:"title"`
+	Description *string    `json:"description,omitempty"`
+	Priority    *Priority  `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"dueDate,omitempty"`
+	AllDay      bool       `json:"allDay"`
+	Status      Status     `json:"status"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// CreatePayload turns the imported todo into the payload a todo is created
+// from, placed in categoryID
+func (t *ImportedTodo) CreatePayload(categoryID *uuid.UUID) *CreateTodoPayload {
+	allDay := t.AllDay
+	return &CreateTodoPayload{
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		AllDay:      &allDay,
+		CategoryID:  categoryID,
+	}
+}
+
+// ImportSkip explains why an entry of an import file didn't become a todo.
+// Entry counts the file's VTODO and VEVENT entries from 1.
+type ImportSkip struct {
+	Entry  int    `json:"entry"`
+	UID    string `json:"uid,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type icsProperty struct {
+	params map[string]string
+	value  string
+}
+
+type icsEntry struct {
+	component string
+	props     map[string]icsProperty
+}
+
+// ParseICS reads the VTODO and VEVENT entries of an iCalendar file as todos.
+// A todo's due date comes from DUE and an event's from DTSTART. Times keep
+// their UTC or TZID zone; floating times, and times in a TZID that isn't a
+// known IANA zone, are read in loc. Dates without a time become all-day due
+// dates at the start of that day in loc. Entries that can't become a todo are
+// returned as skipped rather than failing the whole file.
+func ParseICS(data []byte, loc *time.Location) ([]ImportedTodo, []ImportSkip, error) {
+	lines := unfoldICS(string(data))
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		code := errs.CodeInvalidImport
+		return nil, nil, errs.NewBadRequestError("File is not an iCalendar file", true, &code,
+			[]errs.FieldError{{Field: "file", Error: "must start with BEGIN:VCALENDAR"}}, nil)
+	}
+
+	var (
+		todos   []ImportedTodo
+		skipped []ImportSkip
+		stack   []string
+		current *icsEntry
+		count   int
+	)
+
+	for _, line := range lines {
+		name, params, value, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			component := strings.ToUpper(strings.TrimSpace(value))
+			if current == nil && len(stack) == 1 && (component == "VTODO" || component == "VEVENT") {
+				current = &icsEntry{component: component, props: make(map[string]icsProperty)}
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if current != nil && len(stack) == 1 {
+				count++
+				imported, reason := current.toTodo(loc)
+				if reason != "" {
+					skipped = append(skipped, ImportSkip{Entry: count, UID: current.props["UID"].value, Reason: reason})
+				} else {
+					todos = append(todos, imported)
+				}
+				current = nil
+			}
+		default:
+			// Properties of nested components such as VALARM aren't the entry's
+			if current == nil || stack[len(stack)-1] != current.component {
+				continue
+			}
+			if _, seen := current.props[name]; !seen {
+				current.props[name] = icsProperty{params: params, value: value}
+			}
+		}
+	}
+
+	return todos, skipped, nil
+}
+
+// toTodo converts the entry, or says why it can't be converted
+func (e *icsEntry) toTodo(loc *time.Location) (ImportedTodo, string) {
+	imported := ImportedTodo{
+		Title:  strings.TrimSpace(unescapeICS(e.props["SUMMARY"].value)),
+		Status: StatusActive,
+	}
+
+	if imported.Title == "" {
+		return imported, "has no summary"
+	}
+	if utf8.RuneCountInString(imported.Title) > 255 {
+		return imported, "summary is longer than 255 characters"
+	}
+
+	if description := strings.TrimSpace(unescapeICS(e.props["DESCRIPTION"].value)); description != "" {
+		if utf8.RuneCountInString(description) > 1000 {
+			return imported, "description is longer than 1000 characters"
+		}
+		imported.Description = &description
+	}
+
+	dueProperty := "DUE"
+	if e.component == "VEVENT" {
+		dueProperty = "DTSTART"
+	}
+	if prop, ok := e.props[dueProperty]; ok {
+		due, allDay, err := parseICSTime(prop, loc)
+		if err != nil {
+			return imported, fmt.Sprintf("%s %q is not a valid date", dueProperty, prop.value)
+		}
+		imported.DueDate = &due
+		imported.AllDay = allDay
+	}
+
+	switch strings.ToUpper(e.props["STATUS"].value) {
+	case "COMPLETED":
+		imported.Status = StatusCompleted
+	case "CANCELLED":
+		imported.Status = StatusArchived
+	}
+	if prop, ok := e.props["COMPLETED"]; ok {
+		imported.Status = StatusCompleted
+		if completedAt, _, err := parseICSTime(prop, loc); err == nil {
+			imported.CompletedAt = &completedAt
+		}
+	}
+
+	imported.Priority = icsPriority(e.props["PRIORITY"].value)
+
+	return imported, ""
+}
+
+// icsPriority maps iCalendar's 1 (highest) to 9 (lowest) scale onto todo
+// priorities; 0 means undefined
+func icsPriority(value string) *Priority {
+	level, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || level < 1 || level > 9 {
+		return nil
+	}
+
+	priority := PriorityMedium
+	switch {
+	case level < 5:
+		priority = PriorityHigh
+	case level > 5:
+		priority = PriorityLow
+	}
+	return &priority
+}
+
+// parseICSTime reads a DATE or DATE-TIME value, reporting whether it was a
+// date without a time
+func parseICSTime(prop icsProperty, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len("20060102") {
+		date, err := time.ParseInLocation("20060102", value, loc)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	if tzid := strings.TrimPrefix(prop.params["TZID"], "/"); tzid != "" {
+		if tzLoc, err := time.LoadLocation(tzid); err == nil {
+			loc = tzLoc
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// unfoldICS splits the file into content lines, joining lines that continue
+// on the next line after a leading space or tab
+func unfoldICS(text string) []string {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// parseICSLine splits a content line such as DUE;TZID=Europe/Paris:20250310T090000
+// into its upper-cased name, parameters and value. Colons and semicolons
+// inside quoted parameter values don't count as separators.
+func parseICSLine(line string) (string, map[string]string, string, bool) {
+	var (
+		parts   []string
+		start   int
+		quoted  bool
+		valueAt = -1
+	)
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ';' && !quoted:
+			parts = append(parts, line[start:i])
+			start = i + 1
+		case r == ':' && !quoted:
+			valueAt = i
+		}
+		if valueAt >= 0 {
+			break
+		}
+	}
+	if valueAt < 0 {
+		return "", nil, "", false
+	}
+	parts = append(parts, line[start:valueAt])
+
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return strings.ToUpper(strings.TrimSpace(parts[0])), params, line[valueAt+1:], true
+}
+
+var icsUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICS(value string) string {
+	return icsUnescaper.Replace(value)
+}
@@ -0,0 +1,132 @@
+package todo_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Tasks//EN\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:first@example.com\r\n" +
+	"SUMMARY:Call the plumber\\, again\r\n" +
+	"DESCRIPTION:Ask about the leak\\nand the boiler\r\n" +
+	"DUE;TZID=America/New_York:20250310T143000\r\n" +
+	"PRIORITY:1\r\n" +
+	"STATUS:NEEDS-ACTION\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Not the todo's description\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:second@example.com\r\n" +
+	"SUMMARY:File taxes with a title long enough that the exporter fol\r\n" +
+	" ded it\r\n" +
+	"DUE;VALUE=DATE:20250415\r\n" +
+	"STATUS:COMPLETED\r\n" +
+	"COMPLETED:20250401T120000Z\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:third@example.com\r\n" +
+	"SUMMARY:Dentist\r\n" +
+	"DTSTART:20250320T090000Z\r\n" +
+	"DTEND:20250320T100000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:fourth@example.com\r\n" +
+	"DUE:20250101T090000\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	t.Run("todos and events become todos", func(t *testing.T) {
+		imported, skipped, err := todo.ParseICS([]byte(sampleICS), tokyo)
+		require.NoError(t, err)
+		require.Len(t, imported, 3)
+
+		first := imported[0]
+		assert.Equal(t, "Call the plumber, again", first.Title)
+		require.NotNil(t, first.Description)
+		assert.Equal(t, "Ask about the leak\nand the boiler", *first.Description)
+		require.NotNil(t, first.DueDate)
+		assert.True(t, first.DueDate.Equal(time.Date(2025, time.March, 10, 14, 30, 0, 0, newYork)))
+		assert.Equal(t, "2025-03-10T18:30:00Z", first.DueDate.UTC().Format(time.RFC3339))
+		assert.False(t, first.AllDay)
+		assert.Equal(t, todo.StatusActive, first.Status)
+		require.NotNil(t, first.Priority)
+		assert.Equal(t, todo.PriorityHigh, *first.Priority)
+
+		second := imported[1]
+		assert.Equal(t, "File taxes with a title long enough that the exporter folded it", second.Title)
+		require.NotNil(t, second.DueDate)
+		assert.True(t, second.DueDate.Equal(time.Date(2025, time.April, 15, 0, 0, 0, 0, tokyo)))
+		assert.True(t, second.AllDay)
+		assert.Equal(t, todo.StatusCompleted, second.Status)
+		require.NotNil(t, second.CompletedAt)
+		assert.Equal(t, time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC), *second.CompletedAt)
+		assert.Nil(t, second.Priority)
+
+		event := imported[2]
+		assert.Equal(t, "Dentist", event.Title)
+		assert.Equal(t, time.Date(2025, time.March, 20, 9, 0, 0, 0, time.UTC), *event.DueDate)
+
+		assert.Equal(t, []todo.ImportSkip{{Entry: 4, UID: "fourth@example.com", Reason: "has no summary"}}, skipped)
+	})
+
+	t.Run("floating and unknown zone times are read in the user's timezone", func(t *testing.T) {
+		ics := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VTODO\nSUMMARY:Floating\nDUE:20250310T090000\nEND:VTODO\n" +
+			"BEGIN:VTODO\nSUMMARY:Windows zone\nDUE;TZID=\"W. Europe Standard Time\":20250310T090000\nEND:VTODO\n" +
+			"END:VCALENDAR\n"
+
+		imported, skipped, err := todo.ParseICS([]byte(ics), tokyo)
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+		require.Len(t, imported, 2)
+
+		for _, item := range imported {
+			assert.True(t, item.DueDate.Equal(time.Date(2025, time.March, 10, 9, 0, 0, 0, tokyo)), item.Title)
+		}
+	})
+
+	t.Run("entries that can't become todos are skipped with a reason", func(t *testing.T) {
+		ics := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VTODO\nUID:bad-date\nSUMMARY:Bad date\nDUE:next tuesday\nEND:VTODO\n" +
+			"BEGIN:VTODO\nSUMMARY:" + strings.Repeat("x", 256) + "\nEND:VTODO\n" +
+			"BEGIN:VTODO\nSUMMARY:Cancelled\nSTATUS:CANCELLED\nPRIORITY:9\nEND:VTODO\n" +
+			"END:VCALENDAR\n"
+
+		imported, skipped, err := todo.ParseICS([]byte(ics), time.UTC)
+		require.NoError(t, err)
+
+		assert.Equal(t, []todo.ImportSkip{
+			{Entry: 1, UID: "bad-date", Reason: `DUE "next tuesday" is not a valid date`},
+			{Entry: 2, Reason: "summary is longer than 255 characters"},
+		}, skipped)
+		require.Len(t, imported, 1)
+		assert.Equal(t, todo.StatusArchived, imported[0].Status)
+		assert.Equal(t, todo.PriorityLow, *imported[0].Priority)
+	})
+
+	t.Run("files that aren't iCalendar are rejected", func(t *testing.T) {
+		_, _, err := todo.ParseICS([]byte("title,due\nBuy milk,2025-03-10\n"), time.UTC)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInvalidImport, httpErr.Code)
+	})
+}
@@ -0,0 +1,57 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MarkdownContentType = "text/markdown; charset=utf-8"
+
+// RenderMarkdown renders a todo and its descendants as a nested Markdown
+// checklist, one level of indentation per depth. Subtasks keep the order they
+// appear in descendants; ones whose parent isn't in the tree are left out.
+// Due dates are shown in loc.
+func RenderMarkdown(root *Todo, descendants []Todo, loc *time.Location) []byte {
+	children := make(map[uuid.UUID][]*Todo, len(descendants))
+	for i := range descendants {
+		if parentID := descendants[i].ParentTodoID; parentID != nil {
+			children[*parentID] = append(children[*parentID], &descendants[i])
+		}
+	}
+
+	var b strings.Builder
+	var render func(item *Todo, depth int)
+	render = func(item *Todo, depth int) {
+		writeMarkdownItem(&b, item, depth, loc)
+		for _, child := range children[item.ID] {
+			render(child, depth+1)
+		}
+	}
+	render(root, 0)
+
+	return []byte(b.String())
+}
+
+func writeMarkdownItem(b *strings.Builder, item *Todo, depth int, loc *time.Location) {
+	checkbox := "[ ]"
+	if item.Status == StatusCompleted {
+		checkbox = "[x]"
+	}
+
+	notes := []string{"priority: " + string(item.Priority)}
+	if item.DueDate != nil {
+		if item.AllDay {
+			notes = append(notes, "due: "+item.DueDate.In(loc).Format(time.DateOnly))
+		} else {
+			notes = append(notes, "due: "+item.DueDate.In(loc).Format("2006-01-02 15:04"))
+		}
+	}
+
+	// Line breaks in a title would end the list item early
+	title := strings.Join(strings.Fields(item.Title), " ")
+
+	fmt.Fprintf(b, "%s- %s %s _(%s)_\n", strings.Repeat("  ", depth), checkbox, title, strings.Join(notes, ", "))
+}
@@ -0,0 +1,58 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	newTodo := func(title string, status todo.Status, parent *todo.Todo) todo.Todo {
+		item := todo.Todo{
+			Base:     model.Base{BaseWithId: model.BaseWithId{ID: uuid.New()}},
+			Title:    title,
+			Status:   status,
+			Priority: todo.PriorityMedium,
+		}
+		if parent != nil {
+			item.ParentTodoID = &parent.ID
+		}
+		return item
+	}
+
+	due := time.Date(2025, time.March, 10, 17, 30, 0, 0, time.UTC)
+
+	root := newTodo("Launch\nwebsite", todo.StatusActive, nil)
+	root.Priority = todo.PriorityHigh
+	root.DueDate = &due
+	design := newTodo("Design", todo.StatusCompleted, &root)
+	mockups := newTodo("Mockups", todo.StatusCompleted, &design)
+	review := newTodo("Review", todo.StatusDraft, &design)
+	review.DueDate = &due
+	review.AllDay = true
+	build := newTodo("Build", todo.StatusActive, &root)
+	orphan := newTodo("Orphan", todo.StatusActive, &todo.Todo{})
+
+	t.Run("three levels render indented with checkbox states", func(t *testing.T) {
+		body := todo.RenderMarkdown(&root, []todo.Todo{design, build, mockups, review, orphan}, time.UTC)
+
+		expected := "- [ ] Launch website _(priority: high, due: 2025-03-10 17:30)_\n" +
+			"  - [x] Design _(priority: medium)_\n" +
+			"    - [x] Mockups _(priority: medium)_\n" +
+			"    - [ ] Review _(priority: medium, due: 2025-03-10)_\n" +
+			"  - [ ] Build _(priority: medium)_\n"
+		assert.Equal(t, expected, string(body))
+	})
+
+	t.Run("due dates are shown in the given location", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+
+		body := todo.RenderMarkdown(&root, nil, loc)
+
+		assert.Equal(t, "- [ ] Launch website _(priority: high, due: 2025-03-10 19:30)_\n", string(body))
+	})
+}
@@ -0,0 +1,170 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReportRepairedMetadata is called when stored metadata had to be repaired
+// while loading, with the raw value and what was wrong with it. The server
+// points it at its logger.
+var ReportRepairedMetadata = func(raw []byte, problems []string) {}
+
+// Scan reads a metadata column without failing on values written outside the
+// API. Fields of the wrong type are coerced where the intent is clear, such as
+// tags stored as a comma separated string, and dropped otherwise.
+func (m *Metadata) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*m = Metadata{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into todo metadata", src)
+	}
+
+	metadata, problems := DecodeMetadata(raw)
+	*m = metadata
+	if len(problems) > 0 {
+		ReportRepairedMetadata(raw, problems)
+	}
+
+	return nil
+}
+
+// DecodeMetadata decodes stored metadata leniently. It never fails; instead it
+// returns the fields it could read along with a description of each one it
+// had to coerce or drop.
+func DecodeMetadata(raw []byte) (Metadata, []string) {
+	var metadata Metadata
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return metadata, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return metadata, []string{"metadata is not a JSON object"}
+	}
+
+	var problems []string
+	report := func(field, problem string) {
+		problems = append(problems, field+": "+problem)
+	}
+
+	if value, ok := fields["tags"]; ok {
+		tags, problem := decodeTags(value)
+		metadata.Tags = tags
+		if problem != "" {
+			report("tags", problem)
+		}
+	}
+
+	metadata.Reminder = decodeString(fields, "reminder", report)
+	metadata.Color = decodeString(fields, "color", report)
+
+	if value, ok := fields["difficulty"]; ok && !isNull(value) {
+		difficulty, problem := decodeDifficulty(value)
+		metadata.Difficulty = difficulty
+		if problem != "" {
+			report("difficulty", problem)
+		}
+	}
+
+	if value, ok := fields["formerCategory"]; ok && !isNull(value) {
+		var former FormerCategory
+		if err := json.Unmarshal(value, &former); err != nil {
+			report("formerCategory", "unreadable, dropped")
+		} else {
+			metadata.FormerCategory = &former
+		}
+	}
+
+	return metadata, problems
+}
+
+func isNull(value json.RawMessage) bool {
+	return string(bytes.TrimSpace(value)) == "null"
+}
+
+func decodeString(fields map[string]json.RawMessage, field string, report func(field, problem string)) *string {
+	value, ok := fields[field]
+	if !ok || isNull(value) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		report(field, "not a string, dropped")
+		return nil
+	}
+
+	return &s
+}
+
+// decodeTags accepts a list of strings, a single comma separated string, or a
+// list mixing strings with numbers and booleans
+func decodeTags(value json.RawMessage) ([]string, string) {
+	if isNull(value) {
+		return nil, ""
+	}
+
+	var tags []string
+	if err := json.Unmarshal(value, &tags); err == nil {
+		return tags, ""
+	}
+
+	var joined string
+	if err := json.Unmarshal(value, &joined); err == nil {
+		return NormalizeTags(strings.Split(joined, ",")), "stored as a string, split on commas"
+	}
+
+	var items []any
+	if err := json.Unmarshal(value, &items); err != nil {
+		return nil, "not a list, dropped"
+	}
+
+	tags = make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			tags = append(tags, v)
+		case float64:
+			tags = append(tags, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			tags = append(tags, strconv.FormatBool(v))
+		}
+	}
+
+	return tags, "list holds values that are not strings, kept what could be read"
+}
+
+// decodeDifficulty accepts a whole number or a string holding one
+func decodeDifficulty(value json.RawMessage) (*int, string) {
+	var number float64
+	if err := json.Unmarshal(value, &number); err == nil {
+		if number != math.Trunc(number) {
+			return nil, "not a whole number, dropped"
+		}
+		difficulty := int(number)
+		return &difficulty, ""
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if difficulty, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			return &difficulty, "stored as a string, parsed"
+		}
+	}
+
+	return nil, "not a number, dropped"
+}
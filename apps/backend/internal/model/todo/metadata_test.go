@@ -0,0 +1,91 @@
+package todo_test
+
+import (
+	"testing"
+
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata_Scan(t *testing.T) {
+	var reported [][]string
+	original := todo.ReportRepairedMetadata
+	todo.ReportRepairedMetadata = func(raw []byte, problems []string) {
+		reported = append(reported, problems)
+	}
+	defer func() { todo.ReportRepairedMetadata = original }()
+
+	scan := func(t *testing.T, src any) todo.Metadata {
+		t.Helper()
+		reported = nil
+		var m todo.Metadata
+		require.NoError(t, m.Scan(src))
+		return m
+	}
+
+	t.Run("well formed metadata is read as is", func(t *testing.T) {
+		m := scan(t, []byte(`{"tags":["work"],"reminder":"tomorrow","color":"#ff0000","difficulty":3}`))
+
+		assert.Equal(t, []string{"work"}, m.Tags)
+		assert.Equal(t, "tomorrow", *m.Reminder)
+		assert.Equal(t, "#ff0000", *m.Color)
+		assert.Equal(t, 3, *m.Difficulty)
+		assert.Empty(t, reported)
+	})
+
+	t.Run("tags stored as a string are split", func(t *testing.T) {
+		m := scan(t, `{"tags":"work, home,,Work"}`)
+
+		assert.Equal(t, []string{"work", "home"}, m.Tags)
+		require.Len(t, reported, 1)
+		assert.Equal(t, []string{"tags: stored as a string, split on commas"}, reported[0])
+	})
+
+	t.Run("mixed tag lists keep what can be read", func(t *testing.T) {
+		m := scan(t, `{"tags":["work",5,true,{"x":1},null]}`)
+
+		assert.Equal(t, []string{"work", "5", "true"}, m.Tags)
+		assert.Len(t, reported, 1)
+	})
+
+	t.Run("fields of the wrong type are coerced or dropped", func(t *testing.T) {
+		m := scan(t, `{"tags":5,"reminder":12,"color":["red"],"difficulty":"4"}`)
+
+		assert.Nil(t, m.Tags)
+		assert.Nil(t, m.Reminder)
+		assert.Nil(t, m.Color)
+		require.NotNil(t, m.Difficulty)
+		assert.Equal(t, 4, *m.Difficulty)
+		require.Len(t, reported, 1)
+		assert.Equal(t, []string{
+			"tags: not a list, dropped",
+			"reminder: not a string, dropped",
+			"color: not a string, dropped",
+			"difficulty: stored as a string, parsed",
+		}, reported[0])
+	})
+
+	t.Run("unreadable difficulty is dropped", func(t *testing.T) {
+		assert.Nil(t, scan(t, `{"difficulty":"hard"}`).Difficulty)
+		assert.Nil(t, scan(t, `{"difficulty":2.5}`).Difficulty)
+	})
+
+	t.Run("a value that is not an object leaves metadata empty", func(t *testing.T) {
+		m := scan(t, `["work"]`)
+
+		assert.Equal(t, todo.Metadata{}, m)
+		assert.Equal(t, [][]string{{"metadata is not a JSON object"}}, reported)
+	})
+
+	t.Run("null is empty metadata and not reported", func(t *testing.T) {
+		assert.Equal(t, todo.Metadata{}, scan(t, nil))
+		assert.Equal(t, todo.Metadata{}, scan(t, "null"))
+		assert.Empty(t, reported)
+	})
+
+	t.Run("unsupported source types fail", func(t *testing.T) {
+		var m todo.Metadata
+		assert.Error(t, m.Scan(42))
+	})
+}
@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"github.com/google/uuid"
+)
+
+// Reposition returns siblings, listed in their current order, with moved
+// placed where the payload asks. An index past the end places it last.
+// ok is false when the todo to place next to isn't among the siblings.
+func Reposition(siblings []uuid.UUID, moved uuid.UUID, payload *SetPositionPayload) (ordered []uuid.UUID, ok bool) {
+	ordered = make([]uuid.UUID, 0, len(siblings))
+	for _, id := range siblings {
+		if id != moved {
+			ordered = append(ordered, id)
+		}
+	}
+
+	index := len(ordered)
+	switch {
+	case payload.Index != nil:
+		index = min(*payload.Index, len(ordered))
+	case payload.BeforeID != nil, payload.AfterID != nil:
+		anchor, offset := payload.BeforeID, 0
+		if anchor == nil {
+			anchor, offset = payload.AfterID, 1
+		}
+
+		found := false
+		for i, id := range ordered {
+			if id == *anchor {
+				index, found = i+offset, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	ordered = append(ordered, uuid.Nil)
+	copy(ordered[index+1:], ordered[index:])
+	ordered[index] = moved
+
+	return ordered, true
+}
@@ -0,0 +1,60 @@
+package todo_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReposition(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	siblings := []uuid.UUID{a, b, c, d}
+	first, third, past := 0, 2, 99
+
+	t.Run("moves to an index", func(t *testing.T) {
+		ordered, ok := todo.Reposition(siblings, d, &todo.SetPositionPayload{ID: d, Index: &first})
+		require.True(t, ok)
+		assert.Equal(t, []uuid.UUID{d, a, b, c}, ordered)
+
+		ordered, ok = todo.Reposition(siblings, a, &todo.SetPositionPayload{ID: a, Index: &third})
+		require.True(t, ok)
+		assert.Equal(t, []uuid.UUID{b, c, a, d}, ordered)
+	})
+
+	t.Run("an index past the end places it last", func(t *testing.T) {
+		ordered, ok := todo.Reposition(siblings, b, &todo.SetPositionPayload{ID: b, Index: &past})
+		require.True(t, ok)
+		assert.Equal(t, []uuid.UUID{a, c, d, b}, ordered)
+	})
+
+	t.Run("moves before or after a sibling", func(t *testing.T) {
+		ordered, ok := todo.Reposition(siblings, d, &todo.SetPositionPayload{ID: d, BeforeID: &b})
+		require.True(t, ok)
+		assert.Equal(t, []uuid.UUID{a, d, b, c}, ordered)
+
+		ordered, ok = todo.Reposition(siblings, a, &todo.SetPositionPayload{ID: a, AfterID: &c})
+		require.True(t, ok)
+		assert.Equal(t, []uuid.UUID{b, c, a, d}, ordered)
+	})
+
+	t.Run("a todo that isn't a sibling can't be placed next to", func(t *testing.T) {
+		other := uuid.New()
+		_, ok := todo.Reposition(siblings, a, &todo.SetPositionPayload{ID: a, BeforeID: &other})
+		assert.False(t, ok)
+	})
+}
+
+func TestSetPositionPayload_Validate(t *testing.T) {
+	id, other := uuid.New(), uuid.New()
+	index, negative := 1, -1
+
+	assert.NoError(t, (&todo.SetPositionPayload{ID: id, Index: &index}).Validate())
+	assert.NoError(t, (&todo.SetPositionPayload{ID: id, AfterID: &other}).Validate())
+	assert.Error(t, (&todo.SetPositionPayload{ID: id}).Validate())
+	assert.Error(t, (&todo.SetPositionPayload{ID: id, Index: &index, BeforeID: &other}).Validate())
+	assert.Error(t, (&todo.SetPositionPayload{ID: id, BeforeID: &id}).Validate())
+	assert.Error(t, (&todo.SetPositionPayload{ID: id, Index: &negative}).Validate())
+}
@@ -0,0 +1,70 @@
+package todo
+
+import (
+	"strings"
+	"time"
+)
+
+// QuickAddInterpretation is what ParseQuickAdd read out of a quick-add string
+type QuickAddInterpretation struct {
+	Title     string     `json:"title"`
+	DuePreset *string    `json:"duePreset"`
+	DueDate   *time.Time `json:"dueDate"`
+	Tags      []string   `json:"tags"`
+	Priority  *Priority  `json:"priority"`
+	Category  *string    `json:"category"`
+}
+
+// ParseQuickAdd extracts structured fields from text like
+// "Buy milk tomorrow #groceries !high @home". It recognises #tag tokens,
+// !priority, @category and the first relative due date keyword; every token
+// it does not recognise is kept, in order, as the title.
+func ParseQuickAdd(text string, now time.Time, loc *time.Location) QuickAddInterpretation {
+	result := QuickAddInterpretation{Tags: []string{}}
+	tokens := strings.Fields(text)
+	title := make([]string, 0, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		switch {
+		case len(token) > 1 && token[0] == '#':
+			result.Tags = NormalizeTags(append(result.Tags, token[1:]))
+			continue
+
+		case len(token) > 1 && token[0] == '!':
+			if priority := Priority(strings.ToLower(token[1:])); priority.IsValid() {
+				result.Priority = &priority
+				continue
+			}
+
+		case len(token) > 1 && token[0] == '@':
+			category := token[1:]
+			result.Category = &category
+			continue
+
+		case result.DueDate == nil:
+			// "next week" is the only preset spanning two words
+			if strings.EqualFold(token, "next") && i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "week") {
+				if due, ok := ResolveDuePreset("next week", now, loc); ok {
+					preset := "next week"
+					result.DuePreset, result.DueDate = &preset, &due
+					i++
+					continue
+				}
+			}
+
+			if due, ok := ResolveDuePreset(token, now, loc); ok {
+				preset := strings.ToLower(token)
+				result.DuePreset, result.DueDate = &preset, &due
+				continue
+			}
+		}
+
+		title = append(title, token)
+	}
+
+	result.Title = strings.Join(title, " ")
+
+	return result
+}
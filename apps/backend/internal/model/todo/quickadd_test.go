@@ -0,0 +1,161 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2025, time.March, 12, 15, 0, 0, 0, time.UTC)
+
+	t.Run("string without special tokens is all title", func(t *testing.T) {
+		result := todo.ParseQuickAdd("  Call the   plumber ", now, time.UTC)
+
+		assert.Equal(t, "Call the plumber", result.Title)
+		assert.Nil(t, result.DueDate)
+		assert.Nil(t, result.DuePreset)
+		assert.Nil(t, result.Priority)
+		assert.Nil(t, result.Category)
+		assert.Empty(t, result.Tags)
+	})
+
+	t.Run("relative due date", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Buy milk tomorrow", now, time.UTC)
+
+		assert.Equal(t, "Buy milk", result.Title)
+		require.NotNil(t, result.DueDate)
+		assert.Equal(t, time.Date(2025, time.March, 13, 0, 0, 0, 0, time.UTC), *result.DueDate)
+		require.NotNil(t, result.DuePreset)
+		assert.Equal(t, "tomorrow", *result.DuePreset)
+	})
+
+	t.Run("two word due date", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Plan sprint next week", now, time.UTC)
+
+		assert.Equal(t, "Plan sprint", result.Title)
+		require.NotNil(t, result.DueDate)
+		assert.Equal(t, time.Monday, result.DueDate.Weekday())
+		assert.Equal(t, 17, result.DueDate.Day())
+	})
+
+	t.Run("only the first due keyword is used", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Move friday standup today", now, time.UTC)
+
+		assert.Equal(t, "Move standup today", result.Title)
+		require.NotNil(t, result.DueDate)
+		assert.Equal(t, time.Friday, result.DueDate.Weekday())
+	})
+
+	t.Run("tags go into metadata tags", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Buy milk #groceries #errands #groceries", now, time.UTC)
+
+		assert.Equal(t, "Buy milk", result.Title)
+		assert.Equal(t, []string{"groceries", "errands"}, result.Tags)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Fix prod bug !HIGH", now, time.UTC)
+
+		assert.Equal(t, "Fix prod bug", result.Title)
+		require.NotNil(t, result.Priority)
+		assert.Equal(t, todo.PriorityHigh, *result.Priority)
+	})
+
+	t.Run("unknown priority stays in the title", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Ship it !urgent", now, time.UTC)
+
+		assert.Equal(t, "Ship it !urgent", result.Title)
+		assert.Nil(t, result.Priority)
+	})
+
+	t.Run("category", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Water plants @home", now, time.UTC)
+
+		assert.Equal(t, "Water plants", result.Title)
+		require.NotNil(t, result.Category)
+		assert.Equal(t, "home", *result.Category)
+	})
+
+	t.Run("all tokens together", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Buy milk tomorrow #groceries !high @errands", now, time.UTC)
+
+		assert.Equal(t, "Buy milk", result.Title)
+		require.NotNil(t, result.DueDate)
+		assert.Equal(t, []string{"groceries"}, result.Tags)
+		assert.Equal(t, todo.PriorityHigh, *result.Priority)
+		assert.Equal(t, "errands", *result.Category)
+	})
+
+	t.Run("lone symbols stay in the title", func(t *testing.T) {
+		result := todo.ParseQuickAdd("Reply to # and @ !", now, time.UTC)
+
+		assert.Equal(t, "Reply to # and @ !", result.Title)
+	})
+}
+
+func TestResolveDuePreset(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Friday 20:00 UTC is already Saturday in Tokyo
+	now := time.Date(2025, time.March, 14, 20, 0, 0, 0, time.UTC)
+
+	t.Run("today uses the user's calendar day", func(t *testing.T) {
+		due, ok := todo.ResolveDuePreset("today", now, tokyo)
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.March, 15, 0, 0, 0, 0, tokyo), due)
+	})
+
+	t.Run("weekday names resolve to the next occurrence", func(t *testing.T) {
+		due, ok := todo.ResolveDuePreset("Saturday", now, time.UTC)
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC), due)
+
+		due, ok = todo.ResolveDuePreset("fri", now, time.UTC)
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.March, 21, 0, 0, 0, 0, time.UTC), due)
+	})
+
+	t.Run("weekend on a saturday is today", func(t *testing.T) {
+		due, ok := todo.ResolveDuePreset("weekend", now, tokyo)
+		require.True(t, ok)
+		assert.Equal(t, 15, due.Day())
+	})
+
+	t.Run("unknown preset", func(t *testing.T) {
+		_, ok := todo.ResolveDuePreset("someday", now, time.UTC)
+		assert.False(t, ok)
+	})
+}
+
+func TestDefaultSnoozeUntil(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Friday 20:00 UTC is already Saturday in Tokyo, so tomorrow is Sunday
+	now := time.Date(2025, time.March, 14, 20, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, time.March, 16, 9, 0, 0, 0, tokyo), todo.DefaultSnoozeUntil(now, tokyo))
+	assert.Equal(t, time.Date(2025, time.March, 15, 9, 0, 0, 0, time.UTC), todo.DefaultSnoozeUntil(now, time.UTC))
+}
+
+func TestShiftDueDates(t *testing.T) {
+	first := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+	second := first.Add(48 * time.Hour)
+	todos := []todo.Todo{{DueDate: &second}, {}, {DueDate: &first}}
+
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+	todo.ShiftDueDates(todos, start)
+
+	assert.Equal(t, start.Add(48*time.Hour), *todos[0].DueDate)
+	assert.Nil(t, todos[1].DueDate)
+	assert.Equal(t, start, *todos[2].DueDate)
+
+	// The originals are untouched
+	assert.Equal(t, 6, first.Day())
+}
@@ -0,0 +1,246 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+	FrequencyYearly  Frequency = "yearly"
+)
+
+var Frequencies = []Frequency{FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly}
+
+func (f Frequency) IsValid() bool {
+	for _, frequency := range Frequencies {
+		if f == frequency {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxRecurrencePreview caps how many occurrences a single preview returns
+const MaxRecurrencePreview = 50
+
+// RecurrenceRule repeats a todo every Interval days, weeks, months or years,
+// counted from the date the rule starts at. Weekly rules may name the
+// weekdays they fall on; otherwise they keep the start date's weekday.
+// Monthly and yearly rules keep the start date's day, moving to the last day
+// of shorter months. No occurrence falls after Until.
+type RecurrenceRule struct {
+	Frequency Frequency  `json:"frequency"`
+	Interval  int        `json:"interval,omitempty"`
+	Weekdays  []string   `json:"weekdays,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// Validate reports every problem with the rule as an INVALID_RECURRENCE error
+func (r *RecurrenceRule) Validate() error {
+	var fieldErrors []errs.FieldError
+
+	if !r.Frequency.IsValid() {
+		allowed := make([]string, 0, len(Frequencies))
+		for _, f := range Frequencies {
+			allowed = append(allowed, string(f))
+		}
+		fieldErrors = append(fieldErrors, errs.FieldError{
+			Field: "frequency",
+			Error: "must be one of: " + strings.Join(allowed, " "),
+		})
+	}
+
+	if r.Interval < 0 {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "interval", Error: "must be at least 1"})
+	}
+
+	if len(r.Weekdays) > 0 && r.Frequency != FrequencyWeekly {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "weekdays", Error: "only apply to weekly rules"})
+	}
+
+	for _, day := range r.Weekdays {
+		if _, ok := weekdayPresets[strings.ToLower(day)]; !ok {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: "weekdays",
+				Error: fmt.Sprintf("%q is not a weekday", day),
+			})
+		}
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+
+	code := errs.CodeInvalidRecurrence
+	return errs.NewBadRequestError("Invalid recurrence rule", true, &code, fieldErrors, nil)
+}
+
+// CheckRecurrence validates rule without failing, returning either its
+// summary or the problems Validate found
+func CheckRecurrence(rule RecurrenceRule) RecurrenceValidation {
+	if err := rule.Validate(); err != nil {
+		var httpErr *errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return RecurrenceValidation{Errors: httpErr.Errors}
+		}
+		return RecurrenceValidation{Errors: []errs.FieldError{{Field: "rule", Error: err.Error()}}}
+	}
+
+	return RecurrenceValidation{Valid: true, Summary: rule.Describe()}
+}
+
+var frequencyUnits = map[Frequency]string{
+	FrequencyDaily:   "day",
+	FrequencyWeekly:  "week",
+	FrequencyMonthly: "month",
+	FrequencyYearly:  "year",
+}
+
+// Describe summarizes a valid rule in plain English, such as "every 2 weeks
+// on Monday and Friday until Dec 31, 2025"
+func (r *RecurrenceRule) Describe() string {
+	var b strings.Builder
+
+	unit := frequencyUnits[r.Frequency]
+	if interval := r.interval(); interval == 1 {
+		b.WriteString("every " + unit)
+	} else {
+		fmt.Fprintf(&b, "every %d %ss", interval, unit)
+	}
+
+	if len(r.Weekdays) > 0 {
+		days := r.weekdays(time.Time{})
+		names := make([]string, 0, len(days))
+		for _, day := range days {
+			names = append(names, day.String())
+		}
+		b.WriteString(" on " + joinWithAnd(names))
+	}
+
+	if r.Until != nil {
+		b.WriteString(" until " + r.Until.Format("Jan 2, 2006"))
+	}
+
+	return b.String()
+}
+
+// joinWithAnd lists items as "a", "a and b" or "a, b and c"
+func joinWithAnd(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func (r *RecurrenceRule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// PreviewRecurrence lists the next count occurrences of rule after from, at
+// from's time of day and in its location. Fewer are returned when the rule's
+// Until comes first, and count is capped at MaxRecurrencePreview.
+func PreviewRecurrence(rule RecurrenceRule, from time.Time, count int) ([]time.Time, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if count < 1 {
+		code := errs.CodeInvalidRecurrence
+		return nil, errs.NewBadRequestError("Preview count must be at least 1", true, &code,
+			[]errs.FieldError{{Field: "count", Error: "must be at least 1"}}, nil)
+	}
+	count = min(count, MaxRecurrencePreview)
+
+	occurrences := make([]time.Time, 0, count)
+	for _, next := range rule.occurrencesAfter(from) {
+		if len(occurrences) == count {
+			break
+		}
+		if rule.Until != nil && next.After(*rule.Until) {
+			break
+		}
+		occurrences = append(occurrences, next)
+	}
+
+	return occurrences, nil
+}
+
+// occurrencesAfter generates enough candidates for the largest preview. The
+// caller stops at the requested count or the rule's end.
+func (r *RecurrenceRule) occurrencesAfter(from time.Time) []time.Time {
+	interval := r.interval()
+	candidates := make([]time.Time, 0, MaxRecurrencePreview)
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		for i := 1; i <= MaxRecurrencePreview; i++ {
+			candidates = append(candidates, from.AddDate(0, 0, i*interval))
+		}
+	case FrequencyWeekly:
+		weekdays := r.weekdays(from)
+		// Weeks are counted from the Sunday starting from's week
+		weekStart := from.AddDate(0, 0, -int(from.Weekday()))
+		for week := 0; len(candidates) < MaxRecurrencePreview; week += interval {
+			for _, day := range weekdays {
+				next := weekStart.AddDate(0, 0, week*7+int(day))
+				if next.After(from) {
+					candidates = append(candidates, next)
+				}
+			}
+		}
+	case FrequencyMonthly:
+		for i := 1; i <= MaxRecurrencePreview; i++ {
+			candidates = append(candidates, addMonthsClamped(from, i*interval))
+		}
+	case FrequencyYearly:
+		for i := 1; i <= MaxRecurrencePreview; i++ {
+			candidates = append(candidates, addMonthsClamped(from, i*interval*12))
+		}
+	}
+
+	return candidates
+}
+
+// weekdays returns the rule's weekdays in calendar order, defaulting to the
+// weekday the rule starts on
+func (r *RecurrenceRule) weekdays(from time.Time) []time.Weekday {
+	if len(r.Weekdays) == 0 {
+		return []time.Weekday{from.Weekday()}
+	}
+
+	seen := make(map[time.Weekday]bool, len(r.Weekdays))
+	days := make([]time.Weekday, 0, len(r.Weekdays))
+	for _, name := range r.Weekdays {
+		day := weekdayPresets[strings.ToLower(name)]
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days
+}
+
+// addMonthsClamped moves t by months, keeping its day of the month unless the
+// target month is shorter, in which case it lands on that month's last day
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfTarget := time.Date(t.Year(), t.Month()+time.Month(months), 1,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+
+	return firstOfTarget.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
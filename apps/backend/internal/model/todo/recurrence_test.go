@@ -0,0 +1,153 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dates(t *testing.T, times []time.Time) []string {
+	t.Helper()
+
+	out := make([]string, 0, len(times))
+	for _, tm := range times {
+		out = append(out, tm.Format("2006-01-02 15:04"))
+	}
+	return out
+}
+
+func TestPreviewRecurrence(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, time.January, 15, 9, 30, 0, 0, time.UTC)
+
+	t.Run("daily every other day", func(t *testing.T) {
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyDaily, Interval: 2}
+
+		occurrences, err := todo.PreviewRecurrence(rule, from, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025-01-17 09:30", "2025-01-19 09:30", "2025-01-21 09:30"}, dates(t, occurrences))
+	})
+
+	t.Run("weekly on listed weekdays", func(t *testing.T) {
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Weekdays: []string{"friday", "mon"}}
+
+		occurrences, err := todo.PreviewRecurrence(rule, from, 4)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"2025-01-17 09:30", "2025-01-20 09:30", "2025-01-24 09:30", "2025-01-27 09:30",
+		}, dates(t, occurrences))
+	})
+
+	t.Run("weekly defaults to the start weekday", func(t *testing.T) {
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Interval: 2}
+
+		occurrences, err := todo.PreviewRecurrence(rule, from, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025-01-29 09:30", "2025-02-12 09:30"}, dates(t, occurrences))
+	})
+
+	t.Run("monthly keeps the day and clamps short months", func(t *testing.T) {
+		endOfMonth := time.Date(2025, time.January, 31, 9, 30, 0, 0, time.UTC)
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyMonthly}
+
+		occurrences, err := todo.PreviewRecurrence(rule, endOfMonth, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025-02-28 09:30", "2025-03-31 09:30", "2025-04-30 09:30"}, dates(t, occurrences))
+	})
+
+	t.Run("until truncates the preview", func(t *testing.T) {
+		until := time.Date(2025, time.January, 18, 9, 30, 0, 0, time.UTC)
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyDaily, Until: &until}
+
+		occurrences, err := todo.PreviewRecurrence(rule, from, 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025-01-16 09:30", "2025-01-17 09:30", "2025-01-18 09:30"}, dates(t, occurrences))
+	})
+
+	t.Run("count is capped", func(t *testing.T) {
+		rule := todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Weekdays: []string{"mon", "wed", "fri"}}
+
+		occurrences, err := todo.PreviewRecurrence(rule, from, 1000)
+		require.NoError(t, err)
+		assert.Len(t, occurrences, todo.MaxRecurrencePreview)
+	})
+
+	t.Run("invalid rules are rejected with INVALID_RECURRENCE", func(t *testing.T) {
+		for _, rule := range []todo.RecurrenceRule{
+			{Frequency: "hourly"},
+			{Frequency: todo.FrequencyDaily, Interval: -1},
+			{Frequency: todo.FrequencyDaily, Weekdays: []string{"monday"}},
+			{Frequency: todo.FrequencyWeekly, Weekdays: []string{"someday"}},
+		} {
+			_, err := todo.PreviewRecurrence(rule, from, 5)
+
+			var httpErr *errs.HTTPError
+			require.ErrorAs(t, err, &httpErr, "%+v", rule)
+			assert.Equal(t, errs.CodeInvalidRecurrence, httpErr.Code)
+		}
+
+		_, err := todo.PreviewRecurrence(todo.RecurrenceRule{Frequency: todo.FrequencyDaily}, from, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckRecurrence(t *testing.T) {
+	until := time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("valid rules are summarized", func(t *testing.T) {
+		tests := []struct {
+			rule     todo.RecurrenceRule
+			expected string
+		}{
+			{todo.RecurrenceRule{Frequency: todo.FrequencyDaily}, "every day"},
+			{todo.RecurrenceRule{Frequency: todo.FrequencyDaily, Interval: 3}, "every 3 days"},
+			{todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Interval: 1}, "every week"},
+			{
+				todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Interval: 2, Weekdays: []string{"mon"}, Until: &until},
+				"every 2 weeks on Monday until Dec 31, 2025",
+			},
+			{
+				todo.RecurrenceRule{Frequency: todo.FrequencyWeekly, Weekdays: []string{"fri", "Monday", "wed", "mon"}},
+				"every week on Monday, Wednesday and Friday",
+			},
+			{todo.RecurrenceRule{Frequency: todo.FrequencyMonthly, Interval: 6}, "every 6 months"},
+			{todo.RecurrenceRule{Frequency: todo.FrequencyYearly, Until: &until}, "every year until Dec 31, 2025"},
+		}
+
+		for _, tt := range tests {
+			result := todo.CheckRecurrence(tt.rule)
+
+			assert.True(t, result.Valid, tt.expected)
+			assert.Equal(t, tt.expected, result.Summary)
+			assert.Empty(t, result.Errors)
+		}
+	})
+
+	t.Run("malformed rules report each problem", func(t *testing.T) {
+		result := todo.CheckRecurrence(todo.RecurrenceRule{
+			Frequency: "hourly",
+			Interval:  -2,
+			Weekdays:  []string{"someday"},
+		})
+
+		assert.False(t, result.Valid)
+		assert.Empty(t, result.Summary)
+		assert.Equal(t, []errs.FieldError{
+			{Field: "frequency", Error: "must be one of: daily weekly monthly yearly"},
+			{Field: "interval", Error: "must be at least 1"},
+			{Field: "weekdays", Error: "only apply to weekly rules"},
+			{Field: "weekdays", Error: `"someday" is not a weekday`},
+		}, result.Errors)
+	})
+
+	t.Run("weekdays on a daily rule point at the weekdays", func(t *testing.T) {
+		result := todo.CheckRecurrence(todo.RecurrenceRule{Frequency: todo.FrequencyDaily, Weekdays: []string{"mon"}})
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, []errs.FieldError{{Field: "weekdays", Error: "only apply to weekly rules"}}, result.Errors)
+	})
+}
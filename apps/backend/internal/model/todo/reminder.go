@@ -0,0 +1,74 @@
+package todo
+
+import "time"
+
+// ReminderKind names one of the reminders the cron jobs send for a todo. It
+// doubles as the reminder's ID, since a todo has at most one of each.
+type ReminderKind string
+
+const (
+	// ReminderDueSoon is sent within the reminder window before the due date
+	ReminderDueSoon ReminderKind = "due_soon"
+	// ReminderOverdue is sent once the due date has passed
+	ReminderOverdue ReminderKind = "overdue"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderStatusPending   ReminderStatus = "pending"
+	ReminderStatusCancelled ReminderStatus = "cancelled"
+)
+
+// Reminder is a reminder the cron jobs will send for a todo. LeadHours is how
+// long before the due date it becomes eligible.
+type Reminder struct {
+	ID          ReminderKind   `json:"id"`
+	LeadHours   int            `json:"leadHours"`
+	ScheduledAt time.Time      `json:"scheduledAt"`
+	Status      ReminderStatus `json:"status"`
+}
+
+// ScheduledReminders lists the reminders still ahead for the todo at now. The
+// due-soon reminder drops off once the todo is due, while the overdue one
+// stays for as long as the todo is unfinished. cancelled maps each cancelled
+// kind to the due date it was cancelled against; a cancellation for an
+// earlier due date no longer applies.
+func ScheduledReminders(t *Todo, now time.Time, loc *time.Location, dayStartHour, leadHours int,
+	cancelled map[ReminderKind]time.Time,
+) []Reminder {
+	reminders := []Reminder{}
+	if t.DueDate == nil || t.Status == StatusCompleted || t.Status == StatusArchived {
+		return reminders
+	}
+
+	status := func(kind ReminderKind) ReminderStatus {
+		if at, ok := cancelled[kind]; ok && at.Equal(*t.DueDate) {
+			return ReminderStatusCancelled
+		}
+		return ReminderStatusPending
+	}
+
+	if now.Before(*t.DueDate) {
+		reminders = append(reminders, Reminder{
+			ID:          ReminderDueSoon,
+			LeadHours:   leadHours,
+			ScheduledAt: t.DueDate.Add(-time.Duration(leadHours) * time.Hour),
+			Status:      status(ReminderDueSoon),
+		})
+	}
+
+	// All-day todos only become overdue once their day is over
+	overdueAt := *t.DueDate
+	if t.AllDay {
+		overdueAt = EndOfDay(*t.DueDate, loc, dayStartHour)
+	}
+
+	reminders = append(reminders, Reminder{
+		ID:          ReminderOverdue,
+		ScheduledAt: overdueAt,
+		Status:      status(ReminderOverdue),
+	})
+
+	return reminders
+}
@@ -0,0 +1,70 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledReminders(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(48 * time.Hour)
+
+	t.Run("upcoming todo has both reminders pending", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, Status: todo.StatusActive}
+
+		reminders := todo.ScheduledReminders(item, now, time.UTC, 0, 24, nil)
+		require.Len(t, reminders, 2)
+
+		assert.Equal(t, todo.ReminderDueSoon, reminders[0].ID)
+		assert.Equal(t, 24, reminders[0].LeadHours)
+		assert.Equal(t, due.Add(-24*time.Hour), reminders[0].ScheduledAt)
+		assert.Equal(t, todo.ReminderStatusPending, reminders[0].Status)
+
+		assert.Equal(t, todo.ReminderOverdue, reminders[1].ID)
+		assert.Equal(t, due, reminders[1].ScheduledAt)
+		assert.Equal(t, todo.ReminderStatusPending, reminders[1].Status)
+	})
+
+	t.Run("overdue todo only has the overdue reminder", func(t *testing.T) {
+		passed := now.Add(-time.Hour)
+		item := &todo.Todo{DueDate: &passed, Status: todo.StatusActive}
+
+		reminders := todo.ScheduledReminders(item, now, time.UTC, 0, 24, nil)
+		require.Len(t, reminders, 1)
+		assert.Equal(t, todo.ReminderOverdue, reminders[0].ID)
+	})
+
+	t.Run("all-day todo is overdue at the end of its day", func(t *testing.T) {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		allDay := time.Date(2025, time.March, 12, 0, 0, 0, 0, tokyo)
+		item := &todo.Todo{DueDate: &allDay, AllDay: true, Status: todo.StatusActive}
+
+		reminders := todo.ScheduledReminders(item, now, tokyo, 0, 24, nil)
+		require.Len(t, reminders, 2)
+		assert.Equal(t, time.Date(2025, time.March, 13, 0, 0, 0, 0, tokyo), reminders[1].ScheduledAt)
+	})
+
+	t.Run("cancellation applies only to the due date it was made for", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, Status: todo.StatusActive}
+
+		reminders := todo.ScheduledReminders(item, now, time.UTC, 0, 24,
+			map[todo.ReminderKind]time.Time{todo.ReminderDueSoon: due})
+		assert.Equal(t, todo.ReminderStatusCancelled, reminders[0].Status)
+		assert.Equal(t, todo.ReminderStatusPending, reminders[1].Status)
+
+		reminders = todo.ScheduledReminders(item, now, time.UTC, 0, 24,
+			map[todo.ReminderKind]time.Time{todo.ReminderDueSoon: due.Add(-24 * time.Hour)})
+		assert.Equal(t, todo.ReminderStatusPending, reminders[0].Status)
+	})
+
+	t.Run("finished or undated todos have no reminders", func(t *testing.T) {
+		assert.Empty(t, todo.ScheduledReminders(&todo.Todo{Status: todo.StatusActive}, now, time.UTC, 0, 24, nil))
+		assert.Empty(t, todo.ScheduledReminders(&todo.Todo{DueDate: &due, Status: todo.StatusCompleted}, now, time.UTC, 0, 24, nil))
+		assert.Empty(t, todo.ScheduledReminders(&todo.Todo{DueDate: &due, Status: todo.StatusArchived}, now, time.UTC, 0, 24, nil))
+	})
+}
@@ -0,0 +1,72 @@
+package todo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/comment"
+)
+
+type ShareLink struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	TodoID    uuid.UUID  `json:"todoId" db:"todo_id"`
+	UserID    string     `json:"userId" db:"user_id"`
+	RevokedAt *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+type ShareLinkToken struct {
+	Token  string    `json:"token"`
+	URL    string    `json:"url"`
+	TodoID uuid.UUID `json:"todoId"`
+}
+
+// SharedTodo is the view of a todo handed to someone holding its share link
+type SharedTodo struct {
+	PopulatedTodo
+	ReadOnly bool `json:"readOnly"`
+}
+
+// NewSharedTodo strips everything that identifies the owner or other users,
+// or that would let the viewer reach storage directly, from a todo about to
+// be shown through a share link. Attachment download URLs are filled in
+// separately.
+func NewSharedTodo(item PopulatedTodo) *SharedTodo {
+	redact := func(t *Todo) {
+		t.UserID = ""
+		t.OrgID = nil
+		t.FollowUpOf = nil
+	}
+
+	redact(&item.Todo)
+
+	if item.Category != nil {
+		category := *item.Category
+		category.UserID = ""
+		item.Category = &category
+	}
+
+	children := make([]Todo, len(item.Children))
+	for i, child := range item.Children {
+		redact(&child)
+		children[i] = child
+	}
+	item.Children = children
+
+	comments := make([]comment.Comment, len(item.Comments))
+	for i, c := range item.Comments {
+		c.UserID = ""
+		comments[i] = c
+	}
+	item.Comments = comments
+
+	attachments := make([]TodoAttachment, len(item.Attachments))
+	for i, attachment := range item.Attachments {
+		attachment.UploadedBy = ""
+		attachment.DownloadKey = ""
+		attachments[i] = attachment
+	}
+	item.Attachments = attachments
+
+	return &SharedTodo{PopulatedTodo: item, ReadOnly: true}
+}
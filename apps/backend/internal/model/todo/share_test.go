@@ -0,0 +1,59 @@
+package todo_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSharedTodo(t *testing.T) {
+	orgID := uuid.New()
+	item := todo.PopulatedTodo{
+		Todo: todo.Todo{
+			UserID: "user_owner",
+			OrgID:  &orgID,
+			Title:  "Plan offsite",
+		},
+		Category: &category.Category{UserID: "user_owner", Name: "Work"},
+		Children: []todo.Todo{{UserID: "user_owner", Title: "Book venue"}},
+		Comments: []comment.Comment{{UserID: "user_commenter", Content: "Looks good"}},
+		Attachments: []todo.TodoAttachment{
+			{Name: "agenda.pdf", UploadedBy: "user_owner", DownloadKey: "agenda.pdf_1700000000"},
+		},
+	}
+
+	shared := todo.NewSharedTodo(item)
+
+	assert.True(t, shared.ReadOnly)
+	assert.Equal(t, "Plan offsite", shared.Title)
+	assert.Empty(t, shared.UserID)
+	assert.Nil(t, shared.OrgID)
+
+	require.NotNil(t, shared.Category)
+	assert.Equal(t, "Work", shared.Category.Name)
+	assert.Empty(t, shared.Category.UserID)
+
+	require.Len(t, shared.Children, 1)
+	assert.Equal(t, "Book venue", shared.Children[0].Title)
+	assert.Empty(t, shared.Children[0].UserID)
+
+	require.Len(t, shared.Comments, 1)
+	assert.Equal(t, "Looks good", shared.Comments[0].Content)
+	assert.Empty(t, shared.Comments[0].UserID)
+
+	require.Len(t, shared.Attachments, 1)
+	assert.Equal(t, "agenda.pdf", shared.Attachments[0].Name)
+	assert.Empty(t, shared.Attachments[0].UploadedBy)
+	assert.Empty(t, shared.Attachments[0].DownloadKey)
+
+	// The owner's copy is left untouched
+	assert.Equal(t, "user_owner", item.UserID)
+	assert.Equal(t, "user_owner", item.Category.UserID)
+	assert.Equal(t, "user_commenter", item.Comments[0].UserID)
+	assert.Equal(t, "agenda.pdf_1700000000", item.Attachments[0].DownloadKey)
+}
@@ -0,0 +1,40 @@
+package todo
+
+import "strings"
+
+// NormalizeTag trims a tag and collapses runs of whitespace inside it to a
+// single space
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// NormalizeTags cleans every tag and drops empty ones and case-insensitive
+// duplicates. The first spelling of a tag is kept, in its original position.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+
+		key := strings.ToLower(tag)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// normalizeTags cleans the tags in place, leaving metadata without tags alone
+func (m *Metadata) normalizeTags() {
+	if m == nil || m.Tags == nil {
+		return
+	}
+	m.Tags = NormalizeTags(m.Tags)
+}
@@ -0,0 +1,51 @@
+package todo_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("casing and whitespace variants collapse to one tag", func(t *testing.T) {
+		assert.Equal(t, []string{"Work"}, todo.NormalizeTags([]string{"Work", "work", " work "}))
+	})
+
+	t.Run("first spelling wins and order is stable", func(t *testing.T) {
+		tags := todo.NormalizeTags([]string{"home", "  Deep   Work ", "HOME", "errands", "deep work", ""})
+		assert.Equal(t, []string{"home", "Deep Work", "errands"}, tags)
+	})
+
+	t.Run("empty input stays empty", func(t *testing.T) {
+		assert.Empty(t, todo.NormalizeTags(nil))
+		assert.Empty(t, todo.NormalizeTags([]string{" ", "\t"}))
+	})
+}
+
+func TestTodoPayload_NormalizesTags(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		payload := &todo.CreateTodoPayload{}
+		err := bindJSON(t, http.MethodPost,
+			`{"title":"Plan","metadata":{"tags":["Work","work"," work ","q3"]}}`, payload)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Work", "q3"}, payload.Metadata.Tags)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		payload := &todo.UpdateTodoPayload{}
+		err := bindJSON(t, http.MethodPatch,
+			`{"metadata":{"tags":[" Focus time ","focus  TIME"]}}`, payload, "id", uuid.NewString())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Focus time"}, payload.Metadata.Tags)
+	})
+
+	t.Run("quick add", func(t *testing.T) {
+		parsed := todo.ParseQuickAdd("Buy milk #Groceries #groceries", time.Now(), time.UTC)
+		assert.Equal(t, []string{"Groceries"}, parsed.Tags)
+	})
+}
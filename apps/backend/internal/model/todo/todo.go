@@ -1,9 +1,11 @@
 package todo
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
 	"github.com/sriniously/tasker/internal/model"
 	"github.com/sriniously/tasker/internal/model/category"
 	"github.com/sriniously/tasker/internal/model/comment"
@@ -18,6 +20,17 @@ const (
 	StatusArchived  Status = "archived"
 )
 
+var Statuses = []Status{StatusDraft, StatusActive, StatusCompleted, StatusArchived}
+
+func (s Status) IsValid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Priority string
 
 const (
@@ -26,19 +39,88 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh}
+
+func (p Priority) IsValid() bool {
+	for _, priority := range Priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingField names a field a todo can be left without, for finding todos
+// that need tidying up
+type MissingField string
+
+const (
+	MissingDueDate     MissingField = "due_date"
+	MissingCategory    MissingField = "category"
+	MissingDescription MissingField = "description"
+)
+
+var MissingFields = []MissingField{MissingDueDate, MissingCategory, MissingDescription}
+
+func (f MissingField) IsValid() bool {
+	for _, field := range MissingFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxDepth is the deepest a todo tree may be: a root todo and its subtasks,
+// since subtasks can't have subtasks of their own.
+const MaxDepth = 2
+
+// TrashPurgeBatchSize is how many todos one run of the trash purge job removes
+const TrashPurgeBatchSize = 100
+
+// MaxChildrenExceededError rejects a change that would leave a todo with more
+// than limit direct subtasks
+func MaxChildrenExceededError(limit int) error {
+	code := errs.CodeMaxChildrenExceeded
+	return errs.NewBadRequestError(
+		fmt.Sprintf("A todo can have at most %d subtasks", limit),
+		true, &code,
+		[]errs.FieldError{{Field: "parentTodoId", Error: fmt.Sprintf("would have more than %d subtasks", limit)}},
+		nil,
+	)
+}
+
 type Todo struct {
 	model.Base
-	UserID       string     `json:"userId" db:"user_id"`
-	Title        string     `json:"title" db:"title"`
-	Description  *string    `json:"description" db:"description"`
-	Status       Status     `json:"status" db:"status"`
-	Priority     Priority   `json:"priority" db:"priority"`
-	DueDate      *time.Time `json:"dueDate" db:"due_date"`
-	CompletedAt  *time.Time `json:"completedAt" db:"completed_at"`
-	ParentTodoID *uuid.UUID `json:"parentTodoId" db:"parent_todo_id"`
-	CategoryID   *uuid.UUID `json:"categoryId" db:"category_id"`
-	Metadata     *Metadata  `json:"metadata" db:"metadata"`
-	SortOrder    int        `json:"sortOrder" db:"sort_order"`
+	UserID      string     `json:"userId" db:"user_id"`
+	OrgID       *uuid.UUID `json:"orgId" db:"org_id"`
+	Title       string     `json:"title" db:"title"`
+	Description *string    `json:"description" db:"description"`
+	Status      Status     `json:"status" db:"status"`
+	Priority    Priority   `json:"priority" db:"priority"`
+	DueDate     *time.Time `json:"dueDate" db:"due_date"`
+	AllDay      bool       `json:"allDay" db:"all_day"`
+	DeferUntil  *time.Time `json:"deferUntil" db:"defer_until"`
+	CompletedAt *time.Time `json:"completedAt" db:"completed_at"`
+	// DeletedAt is set while the todo is in the trash
+	DeletedAt    *time.Time      `json:"deletedAt,omitempty" db:"deleted_at"`
+	ParentTodoID *uuid.UUID      `json:"parentTodoId" db:"parent_todo_id"`
+	FollowUpOf   *uuid.UUID      `json:"followUpOf" db:"follow_up_of"`
+	CategoryID   *uuid.UUID      `json:"categoryId" db:"category_id"`
+	Metadata     *Metadata       `json:"metadata" db:"metadata"`
+	SortOrder    int             `json:"sortOrder" db:"sort_order"`
+	Checklist    []ChecklistItem `json:"checklist" db:"checklist"`
+	// ChecklistDone and ChecklistTotal are the checklist's progress, derived
+	// by the database
+	ChecklistDone  int `json:"checklistDone" db:"checklist_done"`
+	ChecklistTotal int `json:"checklistTotal" db:"checklist_total"`
+}
+
+// Change is a todo as it was before and after a bulk update, so the update
+// can be recorded in its activity history
+type Change struct {
+	Before Todo
+	After  Todo
 }
 
 type Metadata struct {
@@ -46,14 +128,198 @@ type Metadata struct {
 	Reminder   *string  `json:"reminder"`
 	Color      *string  `json:"color"`
 	Difficulty *int     `json:"difficulty"`
+	// FormerCategory is set when the todo's category is deleted, until the
+	// grouping is restored
+	FormerCategory *FormerCategory `json:"formerCategory,omitempty"`
+}
+
+// FormerCategory is the category a todo was in before it was deleted
+type FormerCategory struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	Description *string   `json:"description"`
+	DeletedAt   time.Time `json:"deletedAt"`
 }
 
 type PopulatedTodo struct {
 	Todo
-	Category    *category.Category `json:"category" db:"category"`
-	Children    []Todo             `json:"children" db:"children"`
-	Comments    []comment.Comment  `json:"comments" db:"comments"`
-	Attachments []TodoAttachment   `json:"attachments" db:"attachments"`
+	Category *category.Category `json:"category" db:"category"`
+	Children []Todo             `json:"children" db:"children"`
+	// ChildCount is the total number of subtasks, which may exceed the
+	// inlined Children
+	ChildCount  int               `json:"childCount" db:"child_count"`
+	Comments    []comment.Comment `json:"comments" db:"comments"`
+	Attachments []TodoAttachment  `json:"attachments" db:"attachments"`
+}
+
+type WarningCode string
+
+const (
+	WarningDuplicateTitle   WarningCode = "DUPLICATE_TITLE"
+	WarningCategoryNotFound WarningCode = "CATEGORY_NOT_FOUND"
+)
+
+// Warning is a non-blocking notice returned alongside a successful write
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+	TodoID  *uuid.UUID  `json:"todoId,omitempty"`
+}
+
+type TodoWithWarnings struct {
+	Todo
+	Warnings []Warning `json:"warnings"`
+}
+
+// FeedLink is what a feed token grants: read access to the user's completed
+// todos feed until it is revoked
+type FeedLink struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UserID    string     `json:"userId" db:"user_id"`
+	RevokedAt *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+type FeedToken struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type QuickAddResult struct {
+	Todo           TodoWithWarnings       `json:"todo"`
+	Interpretation QuickAddInterpretation `json:"interpretation"`
+}
+
+type CompleteWithFollowUpResult struct {
+	Completed Todo             `json:"completed"`
+	FollowUp  TodoWithWarnings `json:"followUp"`
+}
+
+type OverdueIndicator struct {
+	HasOverdue bool `json:"hasOverdue"`
+}
+
+type OverdueBucket string
+
+// Overdue todos are bucketed by how many calendar days, in the user's
+// timezone, have passed since their due date
+const (
+	OverdueToday       OverdueBucket = "today"
+	OverdueOneToThree  OverdueBucket = "1_3_days"
+	OverdueFourToSeven OverdueBucket = "4_7_days"
+	OverdueEightOrMore OverdueBucket = "8_plus_days"
+)
+
+var OverdueBucketOrder = []OverdueBucket{OverdueToday, OverdueOneToThree, OverdueFourToSeven, OverdueEightOrMore}
+
+type OverdueBucketSummary struct {
+	Bucket  OverdueBucket `json:"bucket" db:"bucket"`
+	Count   int           `json:"count" db:"count"`
+	TodoIDs []uuid.UUID   `json:"todoIds,omitempty" db:"todo_ids"`
+}
+
+// OverdueBuckets lists every bucket in order, including empty ones
+type OverdueBuckets struct {
+	Buckets []OverdueBucketSummary `json:"buckets"`
+	Total   int                    `json:"total"`
+}
+
+type RecurrencePreview struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// RecurrenceValidation is the result of checking a rule: a summary when it is
+// valid, or the fields that are wrong with it
+type RecurrenceValidation struct {
+	Valid   bool              `json:"valid"`
+	Summary string            `json:"summary,omitempty"`
+	Errors  []errs.FieldError `json:"errors,omitempty"`
+}
+
+// ImportResult lists the todos read from an import file. Imported counts the
+// ones created, which is zero for a dry run.
+type ImportResult struct {
+	DryRun   bool           `json:"dryRun"`
+	Imported int            `json:"imported"`
+	Todos    []ImportedTodo `json:"todos"`
+	Skipped  []ImportSkip   `json:"skipped"`
+}
+
+type BulkUpdateResult struct {
+	Updated int `json:"updated"`
+}
+
+// BulkDeleteOutcome is what a bulk delete did with one of the listed todos
+type BulkDeleteOutcome string
+
+const (
+	BulkDeleteOutcomeDeleted BulkDeleteOutcome = "deleted"
+	// BulkDeleteOutcomeNotFound covers todos that don't exist, aren't the
+	// user's or are already in the trash
+	BulkDeleteOutcomeNotFound BulkDeleteOutcome = "not_found"
+)
+
+type BulkDeleteItem struct {
+	ID      uuid.UUID         `json:"id"`
+	Outcome BulkDeleteOutcome `json:"outcome"`
+}
+
+// BulkDeleteResult reports the outcome for each listed todo, in the order
+// they were listed
+type BulkDeleteResult struct {
+	Deleted  int              `json:"deleted"`
+	NotFound int              `json:"notFound"`
+	Results  []BulkDeleteItem `json:"results"`
+}
+
+// BulkByFilterResult counts the todos a filter matched and how many of them
+// the operation changed
+type BulkByFilterResult struct {
+	Matched int `json:"matched"`
+	Updated int `json:"updated"`
+}
+
+// BulkArchiveResult counts the todos a bulk archive or unarchive changed and
+// the ones it skipped because they were already in that state
+type BulkArchiveResult struct {
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// BatchIDs splits ids into consecutive batches of at most size
+func BatchIDs(ids []uuid.UUID, size int) [][]uuid.UUID {
+	if size <= 0 {
+		size = len(ids)
+	}
+
+	batches := make([][]uuid.UUID, 0, (len(ids)+size-1)/max(size, 1))
+	for start := 0; start < len(ids); start += size {
+		batches = append(batches, ids[start:min(start+size, len(ids))])
+	}
+	return batches
+}
+
+// SnoozedTodo is a todo moved by snoozing, along with the due date it had
+type SnoozedTodo struct {
+	Todo
+	PreviousDueDate *time.Time `json:"previousDueDate" db:"previous_due_date"`
+}
+
+// ListSnapshot is a frozen view of the user's todos that paged listings can
+// read from via its token until it expires
+type ListSnapshot struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type SnoozeOverdueResult struct {
+	Updated int       `json:"updated"`
+	DueDate time.Time `json:"dueDate"`
+}
+
+type TodoCount struct {
+	Count int `json:"count"`
 }
 
 type TodoStats struct {
@@ -65,6 +331,33 @@ type TodoStats struct {
 	Overdue   int `json:"overdue"`
 }
 
+// CycleTime summarises how long completed todos took, from creation to
+// completion. Durations are in seconds.
+type CycleTime struct {
+	Count          int     `json:"count"`
+	AverageSeconds float64 `json:"averageSeconds"`
+	MedianSeconds  float64 `json:"medianSeconds"`
+}
+
+type PriorityCycleTime struct {
+	Priority Priority `json:"priority"`
+	CycleTime
+}
+
+type CategoryCycleTime struct {
+	CategoryID   *uuid.UUID `json:"categoryId"`
+	CategoryName *string    `json:"categoryName"`
+	CycleTime
+}
+
+type CycleTimeStats struct {
+	Overall    CycleTime           `json:"overall"`
+	ByPriority []PriorityCycleTime `json:"byPriority"`
+	ByCategory []CategoryCycleTime `json:"byCategory"`
+	// ExcludedOutliers counts completed todos left out for exceeding the cap
+	ExcludedOutliers int `json:"excludedOutliers"`
+}
+
 type UserWeeklyStats struct {
 	UserID         string `json:"userId" db:"user_id"`
 	CreatedCount   int    `json:"createdCount" db:"created_count"`
@@ -73,8 +366,19 @@ type UserWeeklyStats struct {
 	OverdueCount   int    `json:"overdueCount" db:"overdue_count"`
 }
 
-func (t *Todo) IsOverdue() bool {
-	return t.DueDate != nil && t.DueDate.Before(time.Now()) && t.Status != StatusCompleted
+// IsOverdueAt reports whether the todo is overdue at now. All-day todos are
+// only overdue once the calendar day of their due date has ended in loc, for
+// a user whose days roll over at dayStartHour.
+func (t *Todo) IsOverdueAt(now time.Time, loc *time.Location, dayStartHour int) bool {
+	if t.DueDate == nil || t.Status == StatusCompleted {
+		return false
+	}
+
+	if !t.AllDay {
+		return t.DueDate.Before(now)
+	}
+
+	return !now.Before(EndOfDay(*t.DueDate, loc, dayStartHour))
 }
 
 func (t *Todo) CanHaveChildren() bool {
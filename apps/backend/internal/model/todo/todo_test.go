@@ -0,0 +1,83 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodo_IsOverdueAt(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 10:00 in Tokyo on March 10th
+	due := time.Date(2025, time.March, 10, 10, 0, 0, 0, tokyo)
+
+	t.Run("timed todo is overdue at its exact time", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, Status: todo.StatusActive}
+
+		assert.False(t, item.IsOverdueAt(due.Add(-time.Millisecond), tokyo, 0))
+		assert.True(t, item.IsOverdueAt(due.Add(time.Millisecond), tokyo, 0))
+	})
+
+	t.Run("all-day todo due today is not overdue until the day ends", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, AllDay: true, Status: todo.StatusActive}
+		endOfDay := time.Date(2025, time.March, 11, 0, 0, 0, 0, tokyo)
+
+		assert.False(t, item.IsOverdueAt(due.Add(time.Hour), tokyo, 0))
+		assert.False(t, item.IsOverdueAt(endOfDay.Add(-time.Second), tokyo, 0))
+		assert.True(t, item.IsOverdueAt(endOfDay, tokyo, 0))
+	})
+
+	t.Run("all-day todo uses the user's timezone for the day boundary", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, AllDay: true, Status: todo.StatusActive}
+
+		// 23:30 UTC on March 10th is already March 11th in Tokyo
+		now := time.Date(2025, time.March, 10, 23, 30, 0, 0, time.UTC)
+
+		assert.True(t, item.IsOverdueAt(now, tokyo, 0))
+		assert.False(t, item.IsOverdueAt(now, time.UTC, 0))
+	})
+
+	t.Run("a later day start keeps an all-day todo open past midnight", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, AllDay: true, Status: todo.StatusActive}
+		oneAM := time.Date(2025, time.March, 11, 1, 0, 0, 0, tokyo)
+		fourAM := time.Date(2025, time.March, 11, 4, 0, 0, 0, tokyo)
+
+		assert.False(t, item.IsOverdueAt(oneAM, tokyo, 3))
+		assert.True(t, item.IsOverdueAt(fourAM, tokyo, 3))
+	})
+
+	t.Run("completed todo is never overdue", func(t *testing.T) {
+		item := &todo.Todo{DueDate: &due, Status: todo.StatusCompleted}
+
+		assert.False(t, item.IsOverdueAt(due.Add(48*time.Hour), tokyo, 0))
+	})
+}
+
+func TestBatchIDs(t *testing.T) {
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	t.Run("splits into batches of at most size, in order", func(t *testing.T) {
+		batches := todo.BatchIDs(ids, 2)
+		require.Len(t, batches, 3)
+		assert.Equal(t, ids[0:2], batches[0])
+		assert.Equal(t, ids[2:4], batches[1])
+		assert.Equal(t, ids[4:5], batches[2])
+	})
+
+	t.Run("a list within the size is one batch", func(t *testing.T) {
+		assert.Equal(t, [][]uuid.UUID{ids}, todo.BatchIDs(ids, 10))
+	})
+
+	t.Run("no ids means no batches", func(t *testing.T) {
+		assert.Empty(t, todo.BatchIDs(nil, 2))
+	})
+}
@@ -0,0 +1,94 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sriniously/tasker/internal/errs"
+)
+
+// StatusTransitions lists, for each status, the statuses a todo may move to
+// from it. Staying in the same status is always allowed.
+type StatusTransitions map[Status][]Status
+
+// DefaultStatusTransitions keeps todos moving forward through their lifecycle:
+// drafts have to become active (or be done) before they can be archived, and
+// archived todos come back as active rather than jumping back to draft.
+func DefaultStatusTransitions() StatusTransitions {
+	return StatusTransitions{
+		StatusDraft:     {StatusActive, StatusCompleted},
+		StatusActive:    {StatusDraft, StatusCompleted, StatusArchived},
+		StatusCompleted: {StatusActive, StatusArchived},
+		StatusArchived:  {StatusActive},
+	}
+}
+
+// WithOverrides returns a copy of the transitions where each status present in
+// overrides allows exactly the listed statuses instead of its defaults
+func (t StatusTransitions) WithOverrides(overrides map[string][]string) StatusTransitions {
+	merged := make(StatusTransitions, len(t)+len(overrides))
+	for from, to := range t {
+		merged[from] = to
+	}
+
+	for from, to := range overrides {
+		allowed := make([]Status, 0, len(to))
+		for _, status := range to {
+			allowed = append(allowed, Status(strings.TrimSpace(status)))
+		}
+		merged[Status(from)] = allowed
+	}
+
+	return merged
+}
+
+// Allows reports whether a todo in status from may be moved to status to
+func (t StatusTransitions) Allows(from, to Status) bool {
+	if from == to {
+		return true
+	}
+
+	for _, allowed := range t[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate rejects moving any of the todos to status with an
+// INVALID_TRANSITION error naming every todo that can't make the move
+func (t StatusTransitions) Validate(status Status, todos ...Todo) error {
+	var fieldErrors []errs.FieldError
+	var first *Todo
+
+	for i := range todos {
+		if t.Allows(todos[i].Status, status) {
+			continue
+		}
+		if first == nil {
+			first = &todos[i]
+		}
+		fieldErrors = append(fieldErrors, errs.FieldError{
+			Field: todos[i].ID.String(),
+			Error: fmt.Sprintf("cannot move from %s to %s", todos[i].Status, status),
+		})
+	}
+
+	if first == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("%d todos cannot be moved to %s", len(fieldErrors), status)
+	if len(fieldErrors) == 1 {
+		allowed := make([]string, 0, len(t[first.Status]))
+		for _, s := range t[first.Status] {
+			allowed = append(allowed, string(s))
+		}
+		message = fmt.Sprintf("Cannot change status from %s to %s, allowed: %s",
+			first.Status, status, strings.Join(allowed, ", "))
+	}
+
+	code := errs.CodeInvalidTransition
+	return errs.NewBadRequestError(message, true, &code, fieldErrors, nil)
+}
@@ -0,0 +1,72 @@
+package todo_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func todoInStatus(status todo.Status) todo.Todo {
+	return todo.Todo{
+		Base:   model.Base{BaseWithId: model.BaseWithId{ID: uuid.New()}},
+		Status: status,
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	transitions := todo.DefaultStatusTransitions()
+
+	t.Run("disallowed transitions are rejected with INVALID_TRANSITION", func(t *testing.T) {
+		for _, tc := range []struct{ from, to todo.Status }{
+			{todo.StatusArchived, todo.StatusDraft},
+			{todo.StatusDraft, todo.StatusArchived},
+			{todo.StatusCompleted, todo.StatusDraft},
+		} {
+			item := todoInStatus(tc.from)
+			err := transitions.Validate(tc.to, item)
+
+			var httpErr *errs.HTTPError
+			require.ErrorAs(t, err, &httpErr, "%s -> %s", tc.from, tc.to)
+			assert.Equal(t, errs.CodeInvalidTransition, httpErr.Code)
+			require.Len(t, httpErr.Errors, 1)
+			assert.Equal(t, item.ID.String(), httpErr.Errors[0].Field)
+		}
+	})
+
+	t.Run("allowed transitions pass", func(t *testing.T) {
+		for _, tc := range []struct{ from, to todo.Status }{
+			{todo.StatusDraft, todo.StatusActive},
+			{todo.StatusActive, todo.StatusCompleted},
+			{todo.StatusCompleted, todo.StatusArchived},
+			{todo.StatusArchived, todo.StatusActive},
+			{todo.StatusDraft, todo.StatusDraft},
+		} {
+			assert.NoError(t, transitions.Validate(tc.to, todoInStatus(tc.from)), "%s -> %s", tc.from, tc.to)
+		}
+	})
+
+	t.Run("every offending todo in a batch is reported", func(t *testing.T) {
+		err := transitions.Validate(todo.StatusArchived,
+			todoInStatus(todo.StatusActive), todoInStatus(todo.StatusDraft), todoInStatus(todo.StatusDraft))
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Len(t, httpErr.Errors, 2)
+	})
+
+	t.Run("config overrides replace the rules for a status", func(t *testing.T) {
+		overridden := transitions.WithOverrides(map[string][]string{
+			"archived": {"active", "draft"},
+		})
+
+		assert.True(t, overridden.Allows(todo.StatusArchived, todo.StatusDraft))
+		assert.False(t, overridden.Allows(todo.StatusDraft, todo.StatusArchived))
+		// The defaults are left untouched
+		assert.False(t, transitions.Allows(todo.StatusArchived, todo.StatusDraft))
+	})
+}
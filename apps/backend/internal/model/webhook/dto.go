@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ------------------------------------------------------------
+
+// CreateWebhookPayload registers an endpoint for the listed events. Without a
+// secret one is generated.
+type CreateWebhookPayload struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Secret      *string  `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events      []string `json:"events" validate:"required,min=1,unique,dive,oneof=todo.created todo.updated todo.completed comment.added"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+}
+
+func (p *CreateWebhookPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetWebhookPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *GetWebhookPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetWebhooksPayload struct{}
+
+func (p *GetWebhooksPayload) Validate() error {
+	return nil
+}
+
+// ------------------------------------------------------------
+
+// UpdateWebhookPayload changes the given fields. A paused webhook (active
+// false) is sent nothing until it's resumed.
+type UpdateWebhookPayload struct {
+	ID          uuid.UUID `param:"id" validate:"required,uuid"`
+	URL         *string   `json:"url" validate:"omitempty,url,max=2048"`
+	Secret      *string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events      []string  `json:"events" validate:"omitempty,min=1,unique,dive,oneof=todo.created todo.updated todo.completed comment.added"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Active      *bool     `json:"active"`
+}
+
+func (p *UpdateWebhookPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type DeleteWebhookPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *DeleteWebhookPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type TestWebhookPayload struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+func (p *TestWebhookPayload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
+
+// ------------------------------------------------------------
+
+type GetDeliveriesQuery struct {
+	ID     uuid.UUID       `param:"id" validate:"required,uuid"`
+	Page   *int            `query:"page" validate:"omitempty,min=1"`
+	Limit  *int            `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status *DeliveryStatus `query:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
+
+func (q *GetDeliveriesQuery) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	// Set defaults for pagination
+	if q.Page == nil {
+		defaultPage := 1
+		q.Page = &defaultPage
+	}
+	if q.Limit == nil {
+		defaultLimit := 20
+		q.Limit = &defaultLimit
+	}
+
+	return nil
+}
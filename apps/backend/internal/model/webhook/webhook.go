@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+const (
+	EventTodoCreated   = "todo.created"
+	EventTodoUpdated   = "todo.updated"
+	EventTodoCompleted = "todo.completed"
+	EventCommentAdded  = "comment.added"
+	// EventPing is sent by the test endpoint; webhooks can't subscribe to it
+	EventPing = "webhook.ping"
+)
+
+// Events lists the events a webhook can subscribe to
+var Events = []string{EventTodoCreated, EventTodoUpdated, EventTodoCompleted, EventCommentAdded}
+
+// MaxWebhooksPerUser caps how many webhooks a user may register
+const MaxWebhooksPerUser = 10
+
+// MaxResponseBodyLength is how much of an endpoint's response is kept in the
+// delivery log
+const MaxResponseBodyLength = 4096
+
+type Webhook struct {
+	model.Base
+	UserID string `json:"userId" db:"user_id"`
+	URL    string `json:"url" db:"url"`
+	// Secret signs the payloads; it's only shown when first set
+	Secret      string   `json:"-" db:"secret"`
+	Events      []string `json:"events" db:"events"`
+	Description *string  `json:"description" db:"description"`
+	Active      bool     `json:"active" db:"active"`
+}
+
+// WebhookWithSecret is returned when a webhook is created, the one time its
+// secret is shown
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Subscribes reports whether the webhook is sent the given event
+func (w *Webhook) Subscribes(event string) bool {
+	return w.Active && slices.Contains(w.Events, event)
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is a delivery not yet attempted, or waiting for a retry
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type Delivery struct {
+	model.Base
+	WebhookID      uuid.UUID       `json:"webhookId" db:"webhook_id"`
+	UserID         string          `json:"userId" db:"user_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus *int            `json:"responseStatus" db:"response_status"`
+	ResponseBody   *string         `json:"responseBody" db:"response_body"`
+	Error          *string         `json:"error" db:"error"`
+	DeliveredAt    *time.Time      `json:"deliveredAt" db:"delivered_at"`
+}
+
+// Payload is the JSON body posted to a webhook. ID is the delivery's, so
+// receivers can drop a retried delivery they already handled.
+type Payload struct {
+	ID        uuid.UUID       `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Attempt is the outcome of posting a delivery once. StatusCode is nil when
+// no response came back.
+type Attempt struct {
+	StatusCode *int
+	Body       *string
+	Error      *string
+}
+
+// Succeeded reports whether the endpoint answered with a 2xx status
+func (a *Attempt) Succeeded() bool {
+	return a.Error == nil && a.StatusCode != nil && *a.StatusCode >= 200 && *a.StatusCode < 300
+}
+
+// TodoEvents picks the webhook events for a recorded todo change. Completing
+// a todo, or handing it to another user, is also an update.
+func TodoEvents(entry *activity.Activity) []string {
+	switch entry.Action {
+	case activity.ActionCreated:
+		return []string{EventTodoCreated}
+	case activity.ActionUpdated, activity.ActionStatusChanged:
+		events := []string{EventTodoUpdated}
+		if change, ok := entry.Changes["status"]; ok {
+			var status todo.Status
+			if json.Unmarshal(change.To, &status) == nil && status == todo.StatusCompleted {
+				events = append(events, EventTodoCompleted)
+			}
+		}
+		return events
+	case activity.ActionReassigned:
+		return []string{EventTodoUpdated}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/model/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoEvents(t *testing.T) {
+	before := &todo.Todo{Title: "Ship release", Status: todo.StatusActive}
+
+	t.Run("creating a todo", func(t *testing.T) {
+		entry := &activity.Activity{
+			Action:  activity.ActionCreated,
+			Changes: activity.Diff(nil, activity.SnapshotTodo(before)),
+		}
+		assert.Equal(t, []string{webhook.EventTodoCreated}, webhook.TodoEvents(entry))
+	})
+
+	t.Run("completing a todo is also an update", func(t *testing.T) {
+		after := *before
+		after.Status = todo.StatusCompleted
+		changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+		entry := &activity.Activity{Action: activity.ActionFor(changes), Changes: changes}
+
+		assert.Equal(t, []string{webhook.EventTodoUpdated, webhook.EventTodoCompleted}, webhook.TodoEvents(entry))
+	})
+
+	t.Run("archiving is only an update", func(t *testing.T) {
+		after := *before
+		after.Status = todo.StatusArchived
+		changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(&after))
+		entry := &activity.Activity{Action: activity.ActionFor(changes), Changes: changes}
+
+		assert.Equal(t, []string{webhook.EventTodoUpdated}, webhook.TodoEvents(entry))
+	})
+
+	t.Run("reassigning is an update", func(t *testing.T) {
+		entry := &activity.Activity{Action: activity.ActionReassigned}
+		assert.Equal(t, []string{webhook.EventTodoUpdated}, webhook.TodoEvents(entry))
+	})
+
+	t.Run("deleting sends nothing", func(t *testing.T) {
+		entry := &activity.Activity{Action: activity.ActionDeleted}
+		assert.Empty(t, webhook.TodoEvents(entry))
+	})
+}
+
+func TestWebhook_Subscribes(t *testing.T) {
+	hook := webhook.Webhook{Events: []string{webhook.EventCommentAdded}, Active: true}
+
+	assert.True(t, hook.Subscribes(webhook.EventCommentAdded))
+	assert.False(t, hook.Subscribes(webhook.EventTodoCreated))
+
+	hook.Active = false
+	assert.False(t, hook.Subscribes(webhook.EventCommentAdded))
+}
+
+func TestAttempt_Succeeded(t *testing.T) {
+	status := func(code int) *int { return &code }
+	failure := "connection refused"
+
+	assert.True(t, (&webhook.Attempt{StatusCode: status(204)}).Succeeded())
+	assert.False(t, (&webhook.Attempt{StatusCode: status(500)}).Succeeded())
+	assert.False(t, (&webhook.Attempt{Error: &failure}).Succeeded())
+}
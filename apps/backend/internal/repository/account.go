@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type AccountRepository struct {
+	server *server.Server
+}
+
+func NewAccountRepository(server *server.Server) *AccountRepository {
+	return &AccountRepository{server: server}
+}
+
+// SoftDeleteAccount marks the account deleted, keeping its data until
+// purgeAfter. Deleting an account that is already deleted keeps the original
+// deletion and its window.
+func (r *AccountRepository) SoftDeleteAccount(ctx context.Context, userID, deletedBy string,
+	purgeAfter time.Time,
+) (*account.Deletion, error) {
+	stmt := `
+		INSERT INTO
+			account_deletions (user_id, deleted_by, purge_after)
+		VALUES
+			(@user_id, @deleted_by, @purge_after)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"deleted_by":  deletedBy,
+		"purge_after": purgeAfter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute soft delete account query for user_id=%s: %w", userID, err)
+	}
+
+	deletion, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[account.Deletion])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.GetAccountDeletion(ctx, userID)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:account_deletions for user_id=%s: %w", userID, err)
+	}
+
+	return &deletion, nil
+}
+
+// GetAccountDeletion returns the deletion of the account, or nil when it
+// hasn't been deleted
+func (r *AccountRepository) GetAccountDeletion(ctx context.Context, userID string) (*account.Deletion, error) {
+	return getAccountDeletion(ctx, r.server.DB.Pool, userID, false)
+}
+
+func getAccountDeletion(ctx context.Context, q querier, userID string, forUpdate bool) (*account.Deletion, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			account_deletions
+		WHERE
+			user_id=@user_id
+	`
+	if forUpdate {
+		stmt += " FOR UPDATE"
+	}
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get account deletion query for user_id=%s: %w", userID, err)
+	}
+
+	deletion, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[account.Deletion])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:account_deletions for user_id=%s: %w", userID, err)
+	}
+
+	return &deletion, nil
+}
+
+// IsAccountDeleted reports whether the account has been deleted, purged or not
+func (r *AccountRepository) IsAccountDeleted(ctx context.Context, userID string) (bool, error) {
+	stmt := `
+		SELECT
+			EXISTS (
+				SELECT
+					1
+				FROM
+					account_deletions
+				WHERE
+					user_id=@user_id
+			)
+	`
+
+	var deleted bool
+	err := r.server.DB.Pool.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	}).Scan(&deleted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check account deletion for user_id=%s: %w", userID, err)
+	}
+
+	return deleted, nil
+}
+
+// RecoverAccount undoes the deletion of the account, as long as its data
+// hasn't become due for purging at now
+func (r *AccountRepository) RecoverAccount(ctx context.Context, userID string, now time.Time) (*account.Deletion, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin recover account transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	deletion, err := getAccountDeletion(ctx, tx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletion == nil {
+		return nil, errs.NewNotFoundError("Account has not been deleted", false, nil)
+	}
+
+	if !deletion.IsRecoverable(now) {
+		code := errs.CodeRecoveryWindowPassed
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("Account data was due for purging at %s and can no longer be recovered",
+				deletion.PurgeAfter.Format(time.RFC3339)),
+			true, &code, nil, nil)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM account_deletions WHERE user_id=@user_id", pgx.NamedArgs{
+		"user_id": userID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete from table:account_deletions for user_id=%s: %w", userID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit recover account for user_id=%s: %w", userID, err)
+	}
+
+	return deletion, nil
+}
+
+// GetAccountsDueForPurge returns deleted accounts whose data is due for
+// purging at now, longest overdue first
+func (r *AccountRepository) GetAccountsDueForPurge(ctx context.Context, now time.Time, limit int) ([]account.Deletion, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			account_deletions
+		WHERE
+			purged_at IS NULL
+			AND purge_after<=@now
+		ORDER BY
+			purge_after ASC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"now":   now,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get accounts due for purge query: %w", err)
+	}
+
+	deletions, err := pgx.CollectRows(rows, pgx.RowToStructByName[account.Deletion])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:account_deletions: %w", err)
+	}
+
+	return deletions, nil
+}
+
+// GetAccountAttachmentKeys returns the storage keys of every attachment the
+// account's purge removes: those on its todos, except organization todos
+// that pass to another member, and those it uploaded elsewhere
+func (r *AccountRepository) GetAccountAttachmentKeys(ctx context.Context, userID string) ([]string, error) {
+	stmt := `
+		SELECT
+			a.download_key
+		FROM
+			todo_attachments a
+			JOIN todos t ON t.id=a.todo_id
+		WHERE
+			(
+				t.user_id=@user_id
+				AND NOT EXISTS (
+					SELECT
+						1
+					FROM
+						organization_members m
+					WHERE
+						m.org_id=t.org_id
+						AND m.user_id<>@user_id
+				)
+			)
+			OR a.uploaded_by=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get account attachment keys query for user_id=%s: %w", userID, err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_attachments for user_id=%s: %w", userID, err)
+	}
+
+	return keys, nil
+}
+
+// PurgeAccount removes everything the deleted account owns and marks the
+// deletion purged, in one transaction. Subtasks other users added under its
+// todos are kept and promoted to the root. Organizations outlive it:
+// ownership of those it owned alone passes to the longest-standing admin,
+// failing that member or viewer, and the todos it created in an organization
+// pass to an owner. Only organizations it leaves without members are removed
+// with their todos. It does nothing, returning false, unless the account's
+// data is due for purging at now.
+func (r *AccountRepository) PurgeAccount(ctx context.Context, userID string, now time.Time) (bool, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin purge account transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	deletion, err := getAccountDeletion(ctx, tx, userID, true)
+	if err != nil {
+		return false, err
+	}
+
+	if deletion == nil || deletion.PurgedAt != nil || now.Before(deletion.PurgeAfter) {
+		return false, nil
+	}
+
+	args := pgx.NamedArgs{"user_id": userID}
+
+	// Comments, attachments, share links and reminder cancellations on the
+	// account's todos go with them through their foreign keys
+	stmts := []struct {
+		table string
+		stmt  string
+	}{
+		{
+			table: "organization_members",
+			stmt: `
+				UPDATE organization_members m
+				SET
+					role='owner'
+				FROM
+					(
+						SELECT DISTINCT ON (c.org_id)
+							c.org_id,
+							c.user_id
+						FROM
+							organization_members c
+						WHERE
+							c.user_id<>@user_id
+							AND c.org_id IN (
+								SELECT
+									org_id
+								FROM
+									organization_members
+								WHERE
+									user_id=@user_id
+									AND role='owner'
+							)
+							AND NOT EXISTS (
+								SELECT
+									1
+								FROM
+									organization_members o
+								WHERE
+									o.org_id=c.org_id
+									AND o.user_id<>@user_id
+									AND o.role='owner'
+							)
+						ORDER BY
+							c.org_id,
+							CASE c.role
+								WHEN 'admin' THEN 0
+								WHEN 'member' THEN 1
+								ELSE 2
+							END,
+							c.created_at
+					) successor
+				WHERE
+					m.org_id=successor.org_id
+					AND m.user_id=successor.user_id
+			`,
+		},
+		{
+			table: "organizations",
+			stmt: `
+				DELETE FROM organizations o
+				WHERE
+					o.id IN (
+						SELECT
+							org_id
+						FROM
+							organization_members
+						WHERE
+							user_id=@user_id
+					)
+					AND NOT EXISTS (
+						SELECT
+							1
+						FROM
+							organization_members m
+						WHERE
+							m.org_id=o.id
+							AND m.user_id<>@user_id
+					)
+			`,
+		},
+		{
+			// Organizations left without any member take these with them
+			// below, along with the account's personal todos
+			table: "todos",
+			stmt: `
+				UPDATE todos t
+				SET
+					user_id=(
+						SELECT
+							m.user_id
+						FROM
+							organization_members m
+						WHERE
+							m.org_id=t.org_id
+							AND m.user_id<>@user_id
+						ORDER BY
+							m.role='owner' DESC,
+							m.created_at
+						LIMIT
+							1
+					)
+				WHERE
+					t.user_id=@user_id
+					AND t.org_id IS NOT NULL
+					AND EXISTS (
+						SELECT
+							1
+						FROM
+							organization_members m
+						WHERE
+							m.org_id=t.org_id
+							AND m.user_id<>@user_id
+					)
+			`,
+		},
+		{
+			table: "todos",
+			stmt: `
+				UPDATE todos
+				SET
+					parent_todo_id=NULL
+				WHERE
+					user_id<>@user_id
+					AND parent_todo_id IN (
+						SELECT
+							id
+						FROM
+							todos
+						WHERE
+							user_id=@user_id
+					)
+			`,
+		},
+		{table: "todo_comments", stmt: "DELETE FROM todo_comments WHERE user_id=@user_id"},
+		{table: "todo_attachments", stmt: "DELETE FROM todo_attachments WHERE uploaded_by=@user_id"},
+		{table: "todo_share_links", stmt: "DELETE FROM todo_share_links WHERE user_id=@user_id"},
+		{table: "todo_feed_links", stmt: "DELETE FROM todo_feed_links WHERE user_id=@user_id"},
+		{table: "todo_dependencies", stmt: "DELETE FROM todo_dependencies WHERE user_id=@user_id"},
+		{table: "todos", stmt: "DELETE FROM todos WHERE user_id=@user_id"},
+		{table: "todo_templates", stmt: "DELETE FROM todo_templates WHERE user_id=@user_id"},
+		{table: "webhooks", stmt: "DELETE FROM webhooks WHERE user_id=@user_id"},
+		{table: "tags", stmt: "DELETE FROM tags WHERE user_id=@user_id"},
+		{table: "todo_categories", stmt: "DELETE FROM todo_categories WHERE user_id=@user_id"},
+		{table: "todo_activities", stmt: "DELETE FROM todo_activities WHERE user_id=@user_id"},
+		{table: "todo_stats_summary", stmt: "DELETE FROM todo_stats_summary WHERE user_id=@user_id"},
+		{table: "user_preferences", stmt: "DELETE FROM user_preferences WHERE user_id=@user_id"},
+		{table: "user_clean_days", stmt: "DELETE FROM user_clean_days WHERE user_id=@user_id"},
+		{table: "user_streaks", stmt: "DELETE FROM user_streaks WHERE user_id=@user_id"},
+		{table: "organization_members", stmt: "DELETE FROM organization_members WHERE user_id=@user_id"},
+		{table: "account_deletions", stmt: "UPDATE account_deletions SET purged_at=@now WHERE user_id=@user_id"},
+	}
+
+	args["now"] = now
+	for _, s := range stmts {
+		if _, err := tx.Exec(ctx, s.stmt, args); err != nil {
+			return false, fmt.Errorf("failed to purge table:%s for user_id=%s: %w", s.table, userID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit purge account for user_id=%s: %w", userID, err)
+	}
+
+	return true, nil
+}
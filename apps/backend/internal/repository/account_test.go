@@ -0,0 +1,227 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRepository_SoftDeleteAndRecover(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	accountRepo := repository.NewAccountRepository(testServer)
+
+	t.Run("deleted data is hidden but recoverable within the window", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		link, err := todoRepo.CreateShareLink(ctx, userID, item.ID)
+		require.NoError(t, err)
+
+		deletion, err := accountRepo.SoftDeleteAccount(ctx, userID, userID, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, userID, deletion.DeletedBy)
+
+		deleted, err := accountRepo.IsAccountDeleted(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		_, err = todoRepo.GetSharedTodo(ctx, link.ID, item.ID)
+		require.Error(t, err, "share links of a deleted account must not resolve")
+
+		recovered, err := accountRepo.RecoverAccount(ctx, userID, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, userID, recovered.UserID)
+
+		deleted, err = accountRepo.IsAccountDeleted(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		shared, err := todoRepo.GetSharedTodo(ctx, link.ID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, item.ID, shared.ID)
+	})
+
+	t.Run("deleting again keeps the first window", func(t *testing.T) {
+		userID := uuid.New().String()
+		first, err := accountRepo.SoftDeleteAccount(ctx, userID, userID, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		again, err := accountRepo.SoftDeleteAccount(ctx, userID, userID, time.Now().Add(48*time.Hour))
+		require.NoError(t, err)
+		assert.WithinDuration(t, first.PurgeAfter, again.PurgeAfter, time.Millisecond)
+	})
+
+	t.Run("an account that wasn't deleted can't be recovered", func(t *testing.T) {
+		_, err := accountRepo.RecoverAccount(ctx, uuid.New().String(), time.Now())
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeNotFound, httpErr.Code)
+	})
+
+	t.Run("purging waits for the window", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		_, err := accountRepo.SoftDeleteAccount(ctx, userID, userID, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		purged, err := accountRepo.PurgeAccount(ctx, userID, time.Now())
+		require.NoError(t, err)
+		assert.False(t, purged)
+
+		_, err = todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestAccountRepository_PurgeAccount(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+	accountRepo := repository.NewAccountRepository(testServer)
+
+	userID := uuid.New().String()
+	otherUserID := uuid.New().String()
+
+	cat := createTestCategory(t, ctx, categoryRepo, userID, "Doomed")
+	parent := createTestTodoInCategory(t, ctx, todoRepo, userID, cat.ID)
+	_, err := todoRepo.UploadTodoAttachment(ctx, parent.ID, userID, "notes.txt_1700000000", "notes.txt", 12,
+		"text/plain")
+	require.NoError(t, err)
+
+	// Another user's subtask under the deleted account's todo outlives it
+	survivor, err := todoRepo.CreateTodo(ctx, otherUserID, &todo.CreateTodoPayload{
+		Title:        "Someone else's subtask",
+		ParentTodoID: &parent.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = accountRepo.SoftDeleteAccount(ctx, userID, userID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	t.Run("recovery is refused once the window has passed", func(t *testing.T) {
+		_, err := accountRepo.RecoverAccount(ctx, userID, time.Now())
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeRecoveryWindowPassed, httpErr.Code)
+	})
+
+	t.Run("the account is due and its data is purged", func(t *testing.T) {
+		due, err := accountRepo.GetAccountsDueForPurge(ctx, time.Now(), 100)
+		require.NoError(t, err)
+		var dueIDs []string
+		for _, d := range due {
+			dueIDs = append(dueIDs, d.UserID)
+		}
+		assert.Contains(t, dueIDs, userID)
+
+		keys, err := accountRepo.GetAccountAttachmentKeys(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"notes.txt_1700000000"}, keys)
+
+		purged, err := accountRepo.PurgeAccount(ctx, userID, time.Now())
+		require.NoError(t, err)
+		assert.True(t, purged)
+
+		_, err = todoRepo.CheckTodoExists(ctx, userID, parent.ID)
+		require.Error(t, err)
+
+		_, err = categoryRepo.GetCategoryByID(ctx, userID, cat.ID)
+		require.Error(t, err)
+
+		kept, err := todoRepo.CheckTodoExists(ctx, otherUserID, survivor.ID)
+		require.NoError(t, err)
+		assert.Nil(t, kept.ParentTodoID)
+
+		deletion, err := accountRepo.GetAccountDeletion(ctx, userID)
+		require.NoError(t, err)
+		require.NotNil(t, deletion)
+		assert.NotNil(t, deletion.PurgedAt)
+
+		// The account stays locked out after its data is gone
+		deleted, err := accountRepo.IsAccountDeleted(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		again, err := accountRepo.PurgeAccount(ctx, userID, time.Now())
+		require.NoError(t, err)
+		assert.False(t, again)
+	})
+}
+
+func TestAccountRepository_PurgeAccountInOrganizations(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	orgRepo := repository.NewOrganizationRepository(testServer)
+	accountRepo := repository.NewAccountRepository(testServer)
+
+	ownerID := uuid.New().String()
+	adminID := uuid.New().String()
+	viewerID := uuid.New().String()
+
+	team, err := orgRepo.CreateOrganization(ctx, ownerID, &organization.CreateOrganizationPayload{Name: "Team"})
+	require.NoError(t, err)
+	_, err = orgRepo.AddMember(ctx, team.ID, viewerID, organization.RoleViewer)
+	require.NoError(t, err)
+	_, err = orgRepo.AddMember(ctx, team.ID, adminID, organization.RoleAdmin)
+	require.NoError(t, err)
+	teamTodo, err := todoRepo.CreateOrgTodo(ctx, ownerID, team.ID, &todo.CreateTodoPayload{Title: "Shared"})
+	require.NoError(t, err)
+
+	solo, err := orgRepo.CreateOrganization(ctx, ownerID, &organization.CreateOrganizationPayload{Name: "Solo"})
+	require.NoError(t, err)
+	soloTodo, err := todoRepo.CreateOrgTodo(ctx, ownerID, solo.ID, &todo.CreateTodoPayload{Title: "Alone"})
+	require.NoError(t, err)
+
+	_, err = accountRepo.SoftDeleteAccount(ctx, ownerID, ownerID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	purged, err := accountRepo.PurgeAccount(ctx, ownerID, time.Now())
+	require.NoError(t, err)
+	require.True(t, purged)
+
+	t.Run("ownership passes to the admin before the viewer", func(t *testing.T) {
+		membership, err := orgRepo.GetMembership(ctx, team.ID, adminID)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, organization.RoleOwner, membership.Role)
+
+		membership, err = orgRepo.GetMembership(ctx, team.ID, viewerID)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, organization.RoleViewer, membership.Role)
+	})
+
+	t.Run("organization todos pass to the new owner", func(t *testing.T) {
+		kept, err := todoRepo.CheckOrgTodoExists(ctx, team.ID, teamTodo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, adminID, kept.UserID)
+	})
+
+	t.Run("an organization left without members is removed", func(t *testing.T) {
+		_, err := todoRepo.CheckOrgTodoExists(ctx, solo.ID, soloTodo.ID)
+		require.Error(t, err)
+
+		members, err := orgRepo.GetMembers(ctx, solo.ID)
+		require.NoError(t, err)
+		assert.Empty(t, members)
+	})
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type ActivityRepository struct {
+	server *server.Server
+}
+
+func NewActivityRepository(server *server.Server) *ActivityRepository {
+	return &ActivityRepository{server: server}
+}
+
+func (r *ActivityRepository) CreateActivity(ctx context.Context, entry *activity.Activity) (*activity.Activity, error) {
+	stmt := `
+		INSERT INTO
+			todo_activities (
+				todo_id,
+				user_id,
+				actor_id,
+				action,
+				changes
+			)
+		VALUES
+			(
+				@todo_id,
+				@user_id,
+				@actor_id,
+				@action,
+				@changes
+			)
+		RETURNING
+		*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":  entry.TodoID,
+		"user_id":  entry.UserID,
+		"actor_id": entry.ActorID,
+		"action":   entry.Action,
+		"changes":  entry.Changes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create activity query for todo_id=%s action=%s: %w",
+			entry.TodoID.String(), entry.Action, err)
+	}
+
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todo_activities for todo_id=%s: %w", entry.TodoID.String(), err)
+	}
+
+	return &created, nil
+}
+
+func (r *ActivityRepository) GetActivityByID(ctx context.Context, userID string, todoID uuid.UUID,
+	activityID uuid.UUID,
+) (*activity.Activity, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_activities
+		WHERE
+			id=@id
+			AND todo_id=@todo_id
+			AND user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"id":      activityID,
+		"todo_id": todoID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get activity query for activity_id=%s: %w", activityID.String(), err)
+	}
+
+	entry, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeActivityNotFound
+			return nil, errs.NewNotFoundError(
+				"Activity not found in this todo's history, it may predate the available history", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_activities for activity_id=%s: %w", activityID.String(), err)
+	}
+
+	return &entry, nil
+}
+
+// GetActivitiesAfter returns the activities recorded for a todo after the
+// given sequence number, newest first.
+func (r *ActivityRepository) GetActivitiesAfter(ctx context.Context, userID string, todoID uuid.UUID,
+	afterSeq int64,
+) ([]activity.Activity, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_activities
+		WHERE
+			todo_id=@todo_id
+			AND user_id=@user_id
+			AND seq>@after_seq
+		ORDER BY
+			seq DESC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":   todoID,
+		"user_id":   userID,
+		"after_seq": afterSeq,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get activities query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []activity.Activity{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todo_activities for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return entries, nil
+}
+
+// GetActivitiesSince returns every activity recorded for a todo after the
+// given time, oldest first. Org todos are edited by several members, so this
+// doesn't narrow by user and callers must check access to the todo first.
+func (r *ActivityRepository) GetActivitiesSince(ctx context.Context, todoID uuid.UUID,
+	since time.Time,
+) ([]activity.Activity, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_activities
+		WHERE
+			todo_id=@todo_id
+			AND created_at>@since
+		ORDER BY
+			seq ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"since":   since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get activities since query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_activities for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return entries, nil
+}
+
+// GetActivitiesAfterToken returns the user's activity recorded after the
+// token, oldest first and at most limit entries
+func (r *ActivityRepository) GetActivitiesAfterToken(ctx context.Context, userID string,
+	token activity.ChangeToken, limit int,
+) ([]activity.Activity, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_activities
+		WHERE
+			user_id=@user_id
+			AND (created_at, seq)>(@after::TIMESTAMPTZ, @after_seq::BIGINT)
+		ORDER BY
+			created_at ASC,
+			seq ASC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"after":     token.At,
+		"after_seq": token.Seq,
+		"limit":     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get activities after token query for user_id=%s: %w", userID, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_activities for user_id=%s: %w", userID, err)
+	}
+
+	return entries, nil
+}
+
+// GetActivities pages through the user's activity across all todos, newest
+// first, optionally narrowed by action, todo and creation date range.
+func (r *ActivityRepository) GetActivities(ctx context.Context, userID string,
+	query *activity.GetActivitiesQuery,
+) (*model.PaginatedResponse[activity.Activity], error) {
+	conditions := []string{"user_id = @user_id"}
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	if query.Action != nil {
+		conditions = append(conditions, "action = @action")
+		args["action"] = *query.Action
+	}
+
+	if query.TodoID != nil {
+		conditions = append(conditions, "todo_id = @todo_id")
+		args["todo_id"] = *query.TodoID
+	}
+
+	if query.From != nil {
+		conditions = append(conditions, "created_at >= @from")
+		args["from"] = *query.From
+	}
+
+	if query.To != nil {
+		conditions = append(conditions, "created_at <= @to")
+		args["to"] = *query.To
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	err := r.server.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM todo_activities"+where, args).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count for todo_activities user_id=%s: %w", userID, err)
+	}
+
+	stmt := "SELECT * FROM todo_activities" + where +
+		" ORDER BY created_at DESC, seq DESC LIMIT @limit OFFSET @offset"
+	args["limit"] = *query.Limit
+	args["offset"] = (*query.Page - 1) * (*query.Limit)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get activities query for user_id=%s: %w", userID, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[activity.Activity])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_activities for user_id=%s: %w", userID, err)
+	}
+
+	return &model.PaginatedResponse[activity.Activity]{
+		Data:       entries,
+		Page:       *query.Page,
+		Limit:      *query.Limit,
+		Total:      total,
+		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+	}, nil
+}
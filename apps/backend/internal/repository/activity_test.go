@@ -0,0 +1,243 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityRepository_ReconstructTodo(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	activityRepo := repository.NewActivityRepository(testServer)
+
+	userID := uuid.New().String()
+
+	created, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:    "Draft report",
+		Priority: testing_pkg.Ptr(todo.PriorityLow),
+	})
+	require.NoError(t, err)
+
+	createdEntry, err := activityRepo.CreateActivity(ctx, &activity.Activity{
+		TodoID:  created.ID,
+		UserID:  userID,
+		ActorID: userID,
+		Action:  activity.ActionCreated,
+		Changes: activity.Diff(nil, activity.SnapshotTodo(created)),
+	})
+	require.NoError(t, err)
+
+	// Apply and record two updates the way the service does
+	previous := created
+	for _, payload := range []*todo.UpdateTodoPayload{
+		{ID: created.ID, Title: testing_pkg.Ptr("Final report")},
+		{ID: created.ID, Priority: testing_pkg.Ptr(todo.PriorityHigh)},
+	} {
+		updated, err := todoRepo.UpdateTodo(ctx, userID, payload)
+		require.NoError(t, err)
+
+		changes := activity.Diff(activity.SnapshotTodo(previous), activity.SnapshotTodo(updated))
+		_, err = activityRepo.CreateActivity(ctx, &activity.Activity{
+			TodoID:  created.ID,
+			UserID:  userID,
+			ActorID: userID,
+			Action:  activity.ActionFor(changes),
+			Changes: changes,
+		})
+		require.NoError(t, err)
+		previous = updated
+	}
+
+	t.Run("replaying newer activities restores prior title and priority", func(t *testing.T) {
+		from, err := activityRepo.GetActivityByID(ctx, userID, created.ID, createdEntry.ID)
+		require.NoError(t, err)
+
+		newer, err := activityRepo.GetActivitiesAfter(ctx, userID, created.ID, from.Seq)
+		require.NoError(t, err)
+		require.Len(t, newer, 2)
+		assert.Greater(t, newer[0].Seq, newer[1].Seq)
+
+		current, err := todoRepo.CheckTodoExists(ctx, userID, created.ID)
+		require.NoError(t, err)
+
+		now := activity.SnapshotTodo(current)
+		then := activity.Reconstruct(now, newer)
+
+		diffs := activity.FieldDiffs(then, now)
+		require.Len(t, diffs, 2)
+		assert.Equal(t, "priority", diffs[0].Field)
+		assert.JSONEq(t, `"low"`, string(diffs[0].Then))
+		assert.JSONEq(t, `"high"`, string(diffs[0].Now))
+		assert.Equal(t, "title", diffs[1].Field)
+		assert.JSONEq(t, `"Draft report"`, string(diffs[1].Then))
+		assert.JSONEq(t, `"Final report"`, string(diffs[1].Now))
+	})
+
+	t.Run("activity outside the available history is not found", func(t *testing.T) {
+		_, err := activityRepo.GetActivityByID(ctx, userID, created.ID, uuid.New())
+
+		var httpErr *errs.HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, errs.CodeActivityNotFound, httpErr.Code)
+	})
+}
+
+func TestActivityRepository_GetActivities(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	activityRepo := repository.NewActivityRepository(testServer)
+
+	userID := uuid.New().String()
+	todoID := uuid.New()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	record := func(action activity.Action, createdAt time.Time) {
+		entry, err := activityRepo.CreateActivity(ctx, &activity.Activity{
+			TodoID:  todoID,
+			UserID:  userID,
+			ActorID: userID,
+			Action:  action,
+			Changes: activity.Changes{},
+		})
+		require.NoError(t, err)
+
+		_, err = testServer.DB.Pool.Exec(ctx, "UPDATE todo_activities SET created_at = $1 WHERE id = $2",
+			createdAt, entry.ID)
+		require.NoError(t, err)
+	}
+
+	record(activity.ActionCreated, base)
+	record(activity.ActionUpdated, base.Add(24*time.Hour))
+	record(activity.ActionUpdated, base.Add(48*time.Hour))
+	record(activity.ActionStatusChanged, base.Add(72*time.Hour))
+
+	// Another user's history must never be counted
+	_, err := activityRepo.CreateActivity(ctx, &activity.Activity{
+		TodoID:  todoID,
+		UserID:  uuid.New().String(),
+		ActorID: userID,
+		Action:  activity.ActionUpdated,
+		Changes: activity.Changes{},
+	})
+	require.NoError(t, err)
+
+	t.Run("filter by action type", func(t *testing.T) {
+		result, err := activityRepo.GetActivities(ctx, userID, &activity.GetActivitiesQuery{
+			Page:   testing_pkg.Ptr(1),
+			Limit:  testing_pkg.Ptr(1),
+			Action: testing_pkg.Ptr(activity.ActionUpdated),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.Total)
+		assert.Equal(t, 2, result.TotalPages)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, activity.ActionUpdated, result.Data[0].Action)
+		assert.True(t, result.Data[0].CreatedAt.Equal(base.Add(48*time.Hour)))
+	})
+
+	t.Run("filter by date range", func(t *testing.T) {
+		result, err := activityRepo.GetActivities(ctx, userID, &activity.GetActivitiesQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(20),
+			From:  testing_pkg.Ptr(base.Add(12 * time.Hour)),
+			To:    testing_pkg.Ptr(base.Add(72 * time.Hour)),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Total)
+		require.Len(t, result.Data, 3)
+		assert.Equal(t, activity.ActionStatusChanged, result.Data[0].Action)
+	})
+
+	t.Run("filter by todo", func(t *testing.T) {
+		result, err := activityRepo.GetActivities(ctx, userID, &activity.GetActivitiesQuery{
+			Page:   testing_pkg.Ptr(1),
+			Limit:  testing_pkg.Ptr(20),
+			TodoID: testing_pkg.Ptr(uuid.New()),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, result.Total)
+		assert.Empty(t, result.Data)
+	})
+}
+
+func TestActivityRepository_ChangesSince(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	activityRepo := repository.NewActivityRepository(testServer)
+	commentRepo := repository.NewCommentRepository(testServer)
+
+	userID := uuid.New().String()
+
+	created, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{Title: "Plan offsite"})
+	require.NoError(t, err)
+	_, err = activityRepo.CreateActivity(ctx, &activity.Activity{
+		TodoID:  created.ID,
+		UserID:  userID,
+		ActorID: userID,
+		Action:  activity.ActionCreated,
+		Changes: activity.Diff(nil, activity.SnapshotTodo(created)),
+	})
+	require.NoError(t, err)
+	_, err = commentRepo.AddComment(ctx, userID, created.ID, &comment.AddCommentPayload{Content: "Before the visit"})
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	updated, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+		ID:     created.ID,
+		Status: testing_pkg.Ptr(todo.StatusActive),
+	})
+	require.NoError(t, err)
+	changes := activity.Diff(activity.SnapshotTodo(created), activity.SnapshotTodo(updated))
+	_, err = activityRepo.CreateActivity(ctx, &activity.Activity{
+		TodoID:  created.ID,
+		UserID:  userID,
+		ActorID: userID,
+		Action:  activity.ActionFor(changes),
+		Changes: changes,
+	})
+	require.NoError(t, err)
+	_, err = commentRepo.AddComment(ctx, userID, created.ID, &comment.AddCommentPayload{Content: "After the visit"})
+	require.NoError(t, err)
+
+	entries, err := activityRepo.GetActivitiesSince(ctx, created.ID, since)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, activity.ActionStatusChanged, entries[0].Action)
+
+	populated, err := todoRepo.GetTodoByID(ctx, userID, created.ID)
+	require.NoError(t, err)
+
+	summary := activity.Summarize(populated, since, entries)
+	require.Len(t, summary.StatusChanges, 1)
+	assert.Equal(t, todo.StatusDraft, summary.StatusChanges[0].From)
+	assert.Equal(t, todo.StatusActive, summary.StatusChanges[0].To)
+	require.Len(t, summary.NewComments, 1)
+	assert.Equal(t, "After the visit", summary.NewComments[0].Content)
+	assert.Equal(t, []string{"Status changed from draft to active", "1 new comment"}, summary.Highlights)
+}
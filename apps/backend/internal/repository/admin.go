@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type AdminRepository struct {
+	server *server.Server
+}
+
+func NewAdminRepository(server *server.Server) *AdminRepository {
+	return &AdminRepository{server: server}
+}
+
+func (r *AdminRepository) CreateImpersonationAuditLog(ctx context.Context, entry *admin.ImpersonationAuditLog) error {
+	stmt := `
+		INSERT INTO
+			impersonation_audit_logs (
+				impersonator_id,
+				target_user_id,
+				action,
+				method,
+				path,
+				request_id,
+				allowed
+			)
+		VALUES
+			(
+				@impersonator_id,
+				@target_user_id,
+				@action,
+				@method,
+				@path,
+				@request_id,
+				@allowed
+			)
+	`
+
+	_, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"impersonator_id": entry.ImpersonatorID,
+		"target_user_id":  entry.TargetUserID,
+		"action":          entry.Action,
+		"method":          entry.Method,
+		"path":            entry.Path,
+		"request_id":      entry.RequestID,
+		"allowed":         entry.Allowed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation audit log for impersonator_id=%s target_user_id=%s: %w",
+			entry.ImpersonatorID, entry.TargetUserID, err)
+	}
+
+	return nil
+}
+
+func (r *AdminRepository) GetImpersonationAuditLogs(ctx context.Context, impersonatorID string, limit int) ([]admin.ImpersonationAuditLog, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			impersonation_audit_logs
+		WHERE
+			impersonator_id=@impersonator_id
+		ORDER BY
+			created_at DESC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"impersonator_id": impersonatorID,
+		"limit":           limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get impersonation audit logs query for impersonator_id=%s: %w", impersonatorID, err)
+	}
+
+	logs, err := pgx.CollectRows(rows, pgx.RowToStructByName[admin.ImpersonationAuditLog])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []admin.ImpersonationAuditLog{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:impersonation_audit_logs for impersonator_id=%s: %w", impersonatorID, err)
+	}
+
+	return logs, nil
+}
+
+// GetAttachmentAccessLogs pages through the attachment access log, newest
+// first
+func (r *AdminRepository) GetAttachmentAccessLogs(ctx context.Context,
+	query *admin.GetAttachmentAccessLogsQuery,
+) (*model.PaginatedResponse[admin.AttachmentAccessLog], error) {
+	conditions := []string{"TRUE"}
+	args := pgx.NamedArgs{}
+
+	if query.UserID != nil {
+		conditions = append(conditions, "user_id = @user_id")
+		args["user_id"] = *query.UserID
+	}
+
+	if query.TodoID != nil {
+		conditions = append(conditions, "todo_id = @todo_id")
+		args["todo_id"] = *query.TodoID
+	}
+
+	if query.AttachmentID != nil {
+		conditions = append(conditions, "attachment_id = @attachment_id")
+		args["attachment_id"] = *query.AttachmentID
+	}
+
+	if query.From != nil {
+		conditions = append(conditions, "created_at >= @from")
+		args["from"] = *query.From
+	}
+
+	if query.To != nil {
+		conditions = append(conditions, "created_at <= @to")
+		args["to"] = *query.To
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	err := r.server.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM attachment_access_log"+where, args).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count for attachment_access_log: %w", err)
+	}
+
+	stmt := "SELECT * FROM attachment_access_log" + where +
+		" ORDER BY created_at DESC, id DESC LIMIT @limit OFFSET @offset"
+	args["limit"] = *query.Limit
+	args["offset"] = (*query.Page - 1) * (*query.Limit)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get attachment access logs query: %w", err)
+	}
+
+	logs, err := pgx.CollectRows(rows, pgx.RowToStructByName[admin.AttachmentAccessLog])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:attachment_access_log: %w", err)
+	}
+
+	return &model.PaginatedResponse[admin.AttachmentAccessLog]{
+		Data:       logs,
+		Page:       *query.Page,
+		Limit:      *query.Limit,
+		Total:      total,
+		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+	}, nil
+}
+
+type reassignCategory struct {
+	ID      uuid.UUID `db:"id"`
+	Name    string    `db:"name"`
+	IsInbox bool      `db:"is_inbox"`
+}
+
+// ReassignCategories moves the source user's categories to the target in one
+// transaction. A category whose name the target already uses is merged into
+// the target's or renamed, as conflict says. Inboxes are always merged since
+// a user has only one. The todos moved by a merge are returned alongside.
+func (r *AdminRepository) ReassignCategories(ctx context.Context, sourceUserID, targetUserID string,
+	conflict admin.CategoryConflict,
+) (*admin.CategoryReassignment, []todo.Change, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin category reassignment transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	categoriesOf := func(userID string) ([]reassignCategory, error) {
+		rows, err := tx.Query(ctx, `
+			SELECT
+				id,
+				name,
+				is_inbox
+			FROM
+				todo_categories
+			WHERE
+				user_id=@user_id
+			ORDER BY
+				created_at ASC
+			FOR UPDATE
+		`, pgx.NamedArgs{
+			"user_id": userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute get categories query for user_id=%s: %w", userID, err)
+		}
+
+		categories, err := pgx.CollectRows(rows, pgx.RowToStructByName[reassignCategory])
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:todo_categories for user_id=%s: %w", userID, err)
+		}
+		return categories, nil
+	}
+
+	sourceCategories, err := categoriesOf(sourceUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetCategories, err := categoriesOf(targetUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taken := make(map[string]bool, len(targetCategories)+len(sourceCategories))
+	byName := make(map[string]uuid.UUID, len(targetCategories))
+	var targetInbox *uuid.UUID
+	for _, c := range targetCategories {
+		taken[c.Name] = true
+		byName[c.Name] = c.ID
+		if c.IsInbox {
+			targetInbox = &c.ID
+		}
+	}
+
+	result := &admin.CategoryReassignment{}
+	var changes []todo.Change
+	for _, c := range sourceCategories {
+		mergeInto, clashes := byName[c.Name]
+		if c.IsInbox && targetInbox != nil {
+			mergeInto, clashes = *targetInbox, true
+		}
+
+		switch {
+		case clashes && (c.IsInbox || conflict == admin.CategoryConflictMerge):
+			merged, err := updateTodos(ctx, tx, []string{"category_id=@target_category_id"},
+				"t.category_id=@source_category_id", pgx.NamedArgs{
+					"source_category_id": c.ID,
+					"target_category_id": mergeInto,
+				})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to merge category_id=%s: %w", c.ID.String(), err)
+			}
+			changes = append(changes, merged...)
+
+			_, err = tx.Exec(ctx, `DELETE FROM todo_categories WHERE id=@id`, pgx.NamedArgs{"id": c.ID})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to delete merged category_id=%s: %w", c.ID.String(), err)
+			}
+
+			result.Merged++
+			continue
+		case clashes:
+			c.Name = admin.RenameCategory(c.Name, taken)
+			result.Renamed++
+		default:
+			result.Moved++
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE todo_categories
+			SET
+				user_id=@target_user_id,
+				name=@name
+			WHERE
+				id=@id
+		`, pgx.NamedArgs{
+			"id":             c.ID,
+			"name":           c.Name,
+			"target_user_id": targetUserID,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to move category_id=%s: %w", c.ID.String(), err)
+		}
+		taken[c.Name] = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit category reassignment: %w", err)
+	}
+
+	return result, changes, nil
+}
+
+// ReassignTodos hands the source user's personal todos to the target, batchSize
+// at a time, each batch committed on its own. Each todo's activity history
+// moves with it; who made each change is kept. Organization todos stay with
+// the organization. Returns the IDs of the todos that moved, including those
+// moved before a failure.
+func (r *AdminRepository) ReassignTodos(ctx context.Context, sourceUserID, targetUserID string,
+	batchSize int,
+) ([]uuid.UUID, error) {
+	stmt := `
+		WITH
+			batch AS (
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					user_id=@source_user_id
+					AND org_id IS NULL
+				LIMIT
+					@batch_size
+				FOR UPDATE
+			),
+			moved AS (
+				UPDATE todos t
+				SET
+					user_id=@target_user_id
+				FROM
+					batch
+				WHERE
+					t.id=batch.id
+				RETURNING
+					t.id
+			),
+			history AS (
+				UPDATE todo_activities a
+				SET
+					user_id=@target_user_id
+				FROM
+					moved
+				WHERE
+					a.todo_id=moved.id
+			)
+		SELECT
+			id
+		FROM
+			moved
+	`
+
+	return r.reassignInBatches(ctx, "todos", stmt, sourceUserID, targetUserID, batchSize)
+}
+
+// ReassignComments credits the source user's comments to the target,
+// batchSize at a time. Returns how many comments moved.
+func (r *AdminRepository) ReassignComments(ctx context.Context, sourceUserID, targetUserID string,
+	batchSize int,
+) (int, error) {
+	stmt := `
+		WITH
+			batch AS (
+				SELECT
+					id
+				FROM
+					todo_comments
+				WHERE
+					user_id=@source_user_id
+				LIMIT
+					@batch_size
+				FOR UPDATE
+			),
+			moved AS (
+				UPDATE todo_comments c
+				SET
+					user_id=@target_user_id
+				FROM
+					batch
+				WHERE
+					c.id=batch.id
+				RETURNING
+					c.id
+			)
+		SELECT
+			id
+		FROM
+			moved
+	`
+
+	moved, err := r.reassignInBatches(ctx, "todo_comments", stmt, sourceUserID, targetUserID, batchSize)
+	return len(moved), err
+}
+
+// reassignInBatches runs stmt, which moves up to a batch of rows and returns
+// their IDs, until a batch comes back short. Each run is a single statement
+// and so its own transaction.
+func (r *AdminRepository) reassignInBatches(ctx context.Context, table, stmt string,
+	sourceUserID, targetUserID string, batchSize int,
+) ([]uuid.UUID, error) {
+	var moved []uuid.UUID
+	for {
+		rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+			"source_user_id": sourceUserID,
+			"target_user_id": targetUserID,
+			"batch_size":     batchSize,
+		})
+		if err != nil {
+			return moved, fmt.Errorf("failed to reassign table:%s from user_id=%s to user_id=%s: %w",
+				table, sourceUserID, targetUserID, err)
+		}
+
+		batch, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+		if err != nil {
+			return moved, fmt.Errorf("failed to reassign table:%s from user_id=%s to user_id=%s: %w",
+				table, sourceUserID, targetUserID, err)
+		}
+
+		moved = append(moved, batch...)
+		if len(batch) < batchSize {
+			return moved, nil
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRepository_CreateImpersonationAuditLog(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	adminRepo := repository.NewAdminRepository(testServer)
+
+	t.Run("audit record is written", func(t *testing.T) {
+		adminID := uuid.New().String()
+		targetID := uuid.New().String()
+
+		err := adminRepo.CreateImpersonationAuditLog(ctx, &admin.ImpersonationAuditLog{
+			ImpersonatorID: adminID,
+			TargetUserID:   targetID,
+			Action:         admin.ImpersonationActionRequest,
+			Method:         testing_pkg.Ptr("GET"),
+			Path:           testing_pkg.Ptr("/api/v1/todos"),
+			Allowed:        true,
+		})
+		require.NoError(t, err)
+
+		logs, err := adminRepo.GetImpersonationAuditLogs(ctx, adminID, 10)
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+
+		assert.Equal(t, targetID, logs[0].TargetUserID)
+		assert.Equal(t, admin.ImpersonationActionRequest, logs[0].Action)
+		assert.Equal(t, "/api/v1/todos", *logs[0].Path)
+		assert.True(t, logs[0].Allowed)
+	})
+}
+
+func TestAdminRepository_ReassignUser(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	adminRepo := repository.NewAdminRepository(testServer)
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+
+	listTodos := func(t *testing.T, userID string) []todo.PopulatedTodo {
+		t.Helper()
+
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(100),
+			Sort:  testing_pkg.Ptr("created_at"),
+			Order: testing_pkg.Ptr("asc"),
+		})
+		require.NoError(t, err)
+		return result.Data
+	}
+
+	t.Run("todos move in batches and keep their structure", func(t *testing.T) {
+		sourceID := uuid.New().String()
+		targetID := uuid.New().String()
+
+		parent := createTestTodo(t, ctx, todoRepo, sourceID)
+		child, err := todoRepo.CreateTodo(ctx, sourceID, &todo.CreateTodoPayload{
+			Title:        "Subtask",
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+		createTestTodos(t, ctx, todoRepo, sourceID, 3)
+
+		moved, err := adminRepo.ReassignTodos(ctx, sourceID, targetID, 2)
+		require.NoError(t, err)
+		assert.Len(t, moved, 5)
+		assert.Contains(t, moved, child.ID)
+
+		assert.Empty(t, listTodos(t, sourceID))
+
+		targetTodos := listTodos(t, targetID)
+		require.Len(t, targetTodos, 5)
+
+		movedChild, err := todoRepo.GetTodoByID(ctx, targetID, child.ID)
+		require.NoError(t, err)
+		require.NotNil(t, movedChild.ParentTodoID)
+		assert.Equal(t, parent.ID, *movedChild.ParentTodoID)
+
+		again, err := adminRepo.ReassignTodos(ctx, sourceID, targetID, 2)
+		require.NoError(t, err)
+		assert.Empty(t, again)
+	})
+
+	t.Run("clashing categories are merged", func(t *testing.T) {
+		sourceID := uuid.New().String()
+		targetID := uuid.New().String()
+
+		sourceWork := createTestCategory(t, ctx, categoryRepo, sourceID, "Work")
+		createTestCategory(t, ctx, categoryRepo, sourceID, "Garden")
+		targetWork := createTestCategory(t, ctx, categoryRepo, targetID, "Work")
+		item := createTestTodoInCategory(t, ctx, todoRepo, sourceID, sourceWork.ID)
+
+		result, merged, err := adminRepo.ReassignCategories(ctx, sourceID, targetID, admin.CategoryConflictMerge)
+		require.NoError(t, err)
+		assert.Equal(t, &admin.CategoryReassignment{Moved: 1, Merged: 1}, result)
+		require.Len(t, merged, 1)
+		assert.Equal(t, item.ID, merged[0].After.ID)
+		assert.Equal(t, sourceWork.ID, *merged[0].Before.CategoryID)
+		assert.Equal(t, targetWork.ID, *merged[0].After.CategoryID)
+
+		_, err = adminRepo.ReassignTodos(ctx, sourceID, targetID, admin.ReassignBatchSize)
+		require.NoError(t, err)
+
+		movedItem, err := todoRepo.GetTodoByID(ctx, targetID, item.ID)
+		require.NoError(t, err)
+		require.NotNil(t, movedItem.CategoryID)
+		assert.Equal(t, targetWork.ID, *movedItem.CategoryID)
+
+		_, err = categoryRepo.GetCategoryByID(ctx, targetID, sourceWork.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("clashing categories can be renamed instead", func(t *testing.T) {
+		sourceID := uuid.New().String()
+		targetID := uuid.New().String()
+
+		sourceWork := createTestCategory(t, ctx, categoryRepo, sourceID, "Work")
+		createTestCategory(t, ctx, categoryRepo, targetID, "Work")
+		createTestCategory(t, ctx, categoryRepo, targetID, "Work (2)")
+
+		result, merged, err := adminRepo.ReassignCategories(ctx, sourceID, targetID, admin.CategoryConflictRename)
+		require.NoError(t, err)
+		assert.Equal(t, &admin.CategoryReassignment{Renamed: 1}, result)
+		assert.Empty(t, merged)
+
+		renamed, err := categoryRepo.GetCategoryByID(ctx, targetID, sourceWork.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Work (3)", renamed.Name)
+	})
+
+	t.Run("comments move only when asked", func(t *testing.T) {
+		sourceID := uuid.New().String()
+		targetID := uuid.New().String()
+
+		item := createTestTodo(t, ctx, todoRepo, targetID)
+		_, err := testServer.DB.Pool.Exec(ctx,
+			`INSERT INTO todo_comments (todo_id, user_id, content) VALUES ($1, $2, 'Handing over')`,
+			item.ID, sourceID)
+		require.NoError(t, err)
+
+		moved, err := adminRepo.ReassignComments(ctx, sourceID, targetID, admin.ReassignBatchSize)
+		require.NoError(t, err)
+		assert.Equal(t, 1, moved)
+
+		var remaining int
+		err = testServer.DB.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM todo_comments WHERE user_id=$1`,
+			sourceID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+	})
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
 	"github.com/sriniously/tasker/internal/model"
 	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/server"
 )
 
@@ -88,6 +90,116 @@ func (r *CategoryRepository) GetCategoryByID(ctx context.Context, userID string,
 	return &categoryItem, nil
 }
 
+// GetCategoryByName looks a category up by name ignoring case, preferring an
+// exact match. It returns nil when the user has no such category.
+func (r *CategoryRepository) GetCategoryByName(ctx context.Context, userID string, name string) (*category.Category, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_categories
+		WHERE
+			user_id=@user_id
+			AND LOWER(name)=LOWER(@name)
+		ORDER BY
+			name=@name DESC
+		LIMIT
+			1
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"name":    name,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get category by name query for user_id=%s: %w", userID, err)
+	}
+
+	categoryItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[category.Category])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_categories for user_id=%s: %w", userID, err)
+	}
+
+	return &categoryItem, nil
+}
+
+// GetOrCreateInbox returns the user's Inbox, creating it on first use. If the
+// user already has a regular category named Inbox, that category becomes
+// their Inbox rather than failing on the unique name.
+func (r *CategoryRepository) GetOrCreateInbox(ctx context.Context, userID string) (*category.Category, error) {
+	stmt := `
+		WITH
+			inserted AS (
+				INSERT INTO
+					todo_categories (user_id, name, color, is_inbox)
+				VALUES
+					(@user_id, @name, @color, TRUE)
+				ON CONFLICT DO NOTHING
+				RETURNING
+					*
+			)
+		SELECT
+			*
+		FROM
+			inserted
+		UNION ALL
+		SELECT
+			*
+		FROM
+			todo_categories
+		WHERE
+			user_id=@user_id
+			AND is_inbox
+		LIMIT
+			1
+	`
+
+	args := pgx.NamedArgs{
+		"user_id": userID,
+		"name":    category.InboxName,
+		"color":   category.InboxColor,
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get or create inbox query for user_id=%s: %w", userID, err)
+	}
+
+	inbox, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[category.Category])
+	if err == nil {
+		return &inbox, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to collect row from table:todo_categories for user_id=%s: %w", userID, err)
+	}
+
+	adoptStmt := `
+		UPDATE todo_categories
+		SET
+			is_inbox = TRUE
+		WHERE
+			user_id=@user_id
+			AND name=@name
+		RETURNING
+			*
+	`
+
+	rows, err = r.server.DB.Pool.Query(ctx, adoptStmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute adopt inbox query for user_id=%s: %w", userID, err)
+	}
+
+	inbox, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[category.Category])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todo_categories for user_id=%s: %w", userID, err)
+	}
+
+	return &inbox, nil
+}
+
 func (r *CategoryRepository) GetCategories(ctx context.Context, userID string,
 	query *category.GetCategoriesQuery,
 ) (*model.PaginatedResponse[category.Category], error) {
@@ -119,7 +231,8 @@ func (r *CategoryRepository) GetCategories(ctx context.Context, userID string,
 	if query.Order != nil {
 		sortOrder = *query.Order
 	}
-	stmt += fmt.Sprintf(" ORDER BY %s %s", sortColumn, sortOrder)
+	// The Inbox is always listed first
+	stmt += fmt.Sprintf(" ORDER BY is_inbox DESC, %s %s", sortColumn, sortOrder)
 
 	// Add pagination
 	stmt += ` LIMIT @limit OFFSET @offset`
@@ -222,21 +335,262 @@ func (r *CategoryRepository) UpdateCategory(ctx context.Context, userID string,
 	return &categoryItem, nil
 }
 
-func (r *CategoryRepository) DeleteCategory(ctx context.Context, userID string, categoryID uuid.UUID) error {
-	result, err := r.server.DB.Pool.Exec(ctx, `
+// DeleteCategory deletes the category, leaving its todos uncategorized. Each
+// of them remembers the category in its metadata's formerCategory so the
+// grouping can be restored. Returns the todos that were left uncategorized.
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, userID string, categoryID uuid.UUID) ([]todo.Change, error) {
+	existing, err := r.GetCategoryByID(ctx, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.IsInbox {
+		code := errs.CodeInboxNotDeletable
+		return nil, errs.NewBadRequestError("The Inbox category cannot be deleted", true, &code, nil, nil)
+	}
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin delete category transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"id":          categoryID,
+		"user_id":     userID,
+		"name":        existing.Name,
+		"color":       existing.Color,
+		"description": existing.Description,
+	}
+
+	// Locking the category holds off todos being added to it until it's gone
+	if _, err := tx.Exec(ctx, `
+		SELECT
+			id
+		FROM
+			todo_categories
+		WHERE
+			id = @id
+			AND user_id = @user_id
+		FOR UPDATE
+	`, args); err != nil {
+		return nil, fmt.Errorf("failed to lock category_id=%s: %w", categoryID.String(), err)
+	}
+
+	orphaned, err := updateTodos(ctx, tx, []string{
+		`metadata = jsonb_set(
+			COALESCE(t.metadata, '{}'::JSONB),
+			'{formerCategory}',
+			jsonb_build_object(
+				'id', @id::UUID,
+				'name', @name::TEXT,
+				'color', @color::TEXT,
+				'description', @description::TEXT,
+				'deletedAt', NOW()
+			)
+		)`,
+		"category_id = NULL",
+	}, "t.user_id = @user_id AND t.category_id = @id", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record former category_id=%s on todos: %w", categoryID.String(), err)
+	}
+
+	result, err := tx.Exec(ctx, `
 		DELETE FROM todo_categories
-		WHERE id = @id AND user_id = @user_id
+		WHERE id = @id AND user_id = @user_id AND NOT is_inbox
+	`, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit delete category_id=%s: %w", categoryID.String(), err)
+	}
+
+	return orphaned, nil
+}
+
+// RestoreGrouping recreates a category deleted within
+// category.GroupingRestoreWindow, under its old ID, from the formerCategory
+// its todos remember, and puts back the ones that are still uncategorized.
+// Returns the category and every todo it changed, reattached or not.
+func (r *CategoryRepository) RestoreGrouping(ctx context.Context, userID string,
+	categoryID uuid.UUID,
+) (*category.Category, []todo.Change, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin restore grouping transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"id":          categoryID,
+		"user_id":     userID,
+		"window_secs": category.GroupingRestoreWindow.Seconds(),
+	}
+
+	// The latest record wins should the todos disagree
+	rows, err := tx.Query(ctx, `
+		INSERT INTO
+			todo_categories (id, user_id, name, color, description)
+		SELECT
+			@id,
+			@user_id,
+			former ->> 'name',
+			former ->> 'color',
+			former ->> 'description'
+		FROM
+			(
+				SELECT
+					metadata -> 'formerCategory' AS former
+				FROM
+					todos
+				WHERE
+					user_id = @user_id
+					AND metadata -> 'formerCategory' ->> 'id' = @id::TEXT
+					AND (metadata -> 'formerCategory' ->> 'deletedAt')::TIMESTAMPTZ >= NOW() - make_interval(secs => @window_secs)
+				ORDER BY
+					(metadata -> 'formerCategory' ->> 'deletedAt')::TIMESTAMPTZ DESC
+				LIMIT
+					1
+			) latest
+		RETURNING
+			*
+	`, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute restore category query for category_id=%s user_id=%s: %w",
+			categoryID.String(), userID, err)
+	}
+
+	restored, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[category.Category])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeCategoryNotFound
+			return nil, nil, errs.NewNotFoundError("No recently deleted category to restore", false, &code)
+		}
+		return nil, nil, fmt.Errorf("failed to collect row from table:todo_categories for category_id=%s user_id=%s: %w",
+			categoryID.String(), userID, err)
+	}
+
+	reattached, err := updateTodos(ctx, tx, []string{
+		"category_id = @id",
+		"metadata = t.metadata - 'formerCategory'",
+	}, `
+		t.user_id = @user_id
+		AND t.category_id IS NULL
+		AND t.metadata -> 'formerCategory' ->> 'id' = @id::TEXT
+	`, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reattach todos to category_id=%s: %w", categoryID.String(), err)
+	}
+
+	// Todos given another category since then keep it, but no longer
+	// remember the grouping
+	cleared, err := updateTodos(ctx, tx, []string{
+		"metadata = t.metadata - 'formerCategory'",
+	}, `
+		t.user_id = @user_id
+		AND t.metadata -> 'formerCategory' ->> 'id' = @id::TEXT
+	`, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to clear former category_id=%s from todos: %w", categoryID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit restore grouping for category_id=%s: %w", categoryID.String(), err)
+	}
+
+	return &restored, append(reattached, cleared...), nil
+}
+
+// MergeCategories moves every todo in the source category to the target and
+// deletes the source, returning the todos that were moved. Both categories
+// must belong to the user, and the Inbox can't be merged away.
+func (r *CategoryRepository) MergeCategories(ctx context.Context, userID string,
+	sourceID, targetID uuid.UUID,
+) ([]todo.Change, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin merge categories transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock both rows so neither is changed or deleted mid-merge
+	rows, err := tx.Query(ctx, `
+		SELECT
+			id,
+			is_inbox
+		FROM
+			todo_categories
+		WHERE
+			id IN (@source_id, @target_id)
+			AND user_id=@user_id
+		FOR UPDATE
 	`, pgx.NamedArgs{
-		"id":      categoryID,
-		"user_id": userID,
+		"source_id": sourceID,
+		"target_id": targetID,
+		"user_id":   userID,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to delete category: %w", err)
+		return nil, fmt.Errorf("failed to execute lock categories query for source_id=%s target_id=%s user_id=%s: %w",
+			sourceID.String(), targetID.String(), userID, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("category not found")
+	isInbox := make(map[uuid.UUID]bool, 2)
+	var (
+		id    uuid.UUID
+		inbox bool
+	)
+	_, err = pgx.ForEachRow(rows, []any{&id, &inbox}, func() error {
+		isInbox[id] = inbox
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_categories for user_id=%s: %w", userID, err)
+	}
+
+	code := errs.CodeCategoryNotFound
+	if _, ok := isInbox[sourceID]; !ok {
+		return nil, errs.NewNotFoundError("category not found", false, &code)
+	}
+	if _, ok := isInbox[targetID]; !ok {
+		return nil, errs.NewNotFoundError("target category not found", false, &code)
+	}
+	if isInbox[sourceID] {
+		code := errs.CodeInboxNotDeletable
+		return nil, errs.NewBadRequestError("The Inbox category cannot be merged into another category", true,
+			&code, nil, nil)
+	}
+
+	moved, err := updateTodos(ctx, tx, []string{"category_id=@target_id"}, "t.category_id=@source_id",
+		pgx.NamedArgs{
+			"source_id": sourceID,
+			"target_id": targetID,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move todos from category_id=%s to category_id=%s: %w",
+			sourceID.String(), targetID.String(), err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM todo_categories
+		WHERE
+			id=@id
+			AND user_id=@user_id
+	`, pgx.NamedArgs{
+		"id":      sourceID,
+		"user_id": userID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete merged category_id=%s: %w", sourceID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit merge categories for user_id=%s: %w", userID, err)
 	}
 
-	return nil
+	return moved, nil
 }
@@ -0,0 +1,253 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryRepository_Inbox(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	categoryRepo := repository.NewCategoryRepository(testServer)
+
+	t.Run("inbox is created once per user", func(t *testing.T) {
+		userID := uuid.New().String()
+
+		first, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, first.IsInbox)
+		assert.Equal(t, category.InboxName, first.Name)
+
+		second, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID)
+	})
+
+	t.Run("existing category named Inbox is adopted", func(t *testing.T) {
+		userID := uuid.New().String()
+		existing := createTestCategory(t, ctx, categoryRepo, userID, category.InboxName)
+
+		inbox, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, inbox.ID)
+		assert.True(t, inbox.IsInbox)
+	})
+
+	t.Run("inbox cannot be deleted", func(t *testing.T) {
+		userID := uuid.New().String()
+		inbox, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+
+		_, err = categoryRepo.DeleteCategory(ctx, userID, inbox.ID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInboxNotDeletable, httpErr.Code)
+
+		_, err = categoryRepo.GetCategoryByID(ctx, userID, inbox.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("inbox is listed first", func(t *testing.T) {
+		userID := uuid.New().String()
+		createTestCategory(t, ctx, categoryRepo, userID, "Errands")
+		inbox, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+
+		result, err := categoryRepo.GetCategories(ctx, userID, &category.GetCategoriesQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(10),
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Data, 2)
+		assert.Equal(t, inbox.ID, result.Data[0].ID)
+	})
+}
+
+func TestCategoryRepository_MergeCategories(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	categoryRepo := repository.NewCategoryRepository(testServer)
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	createInCategory := func(t *testing.T, userID string, categoryID uuid.UUID, title string) *todo.Todo {
+		t.Helper()
+		result, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:      title,
+			CategoryID: &categoryID,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("todos move to the target and the source is deleted", func(t *testing.T) {
+		userID := uuid.New().String()
+		source := createTestCategory(t, ctx, categoryRepo, userID, "Job")
+		target := createTestCategory(t, ctx, categoryRepo, userID, "Work")
+		first := createInCategory(t, userID, source.ID, "Write report")
+		second := createInCategory(t, userID, source.ID, "Book travel")
+		existing := createInCategory(t, userID, target.ID, "Plan sprint")
+
+		moved, err := categoryRepo.MergeCategories(ctx, userID, source.ID, target.ID)
+		require.NoError(t, err)
+		assert.Len(t, moved, 2)
+
+		for _, id := range []uuid.UUID{first.ID, second.ID, existing.ID} {
+			item, err := todoRepo.GetTodoByID(ctx, userID, id)
+			require.NoError(t, err)
+			require.NotNil(t, item.CategoryID)
+			assert.Equal(t, target.ID, *item.CategoryID)
+		}
+
+		_, err = categoryRepo.GetCategoryByID(ctx, userID, source.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("merge into a category owned by someone else is rejected", func(t *testing.T) {
+		userID := uuid.New().String()
+		source := createTestCategory(t, ctx, categoryRepo, userID, "Job")
+		foreign := createTestCategory(t, ctx, categoryRepo, uuid.New().String(), "Work")
+		item := createInCategory(t, userID, source.ID, "Write report")
+
+		_, err := categoryRepo.MergeCategories(ctx, userID, source.ID, foreign.ID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeCategoryNotFound, httpErr.Code)
+
+		// Nothing changed
+		_, err = categoryRepo.GetCategoryByID(ctx, userID, source.ID)
+		require.NoError(t, err)
+		unchanged, err := todoRepo.GetTodoByID(ctx, userID, item.ID)
+		require.NoError(t, err)
+		require.NotNil(t, unchanged.CategoryID)
+		assert.Equal(t, source.ID, *unchanged.CategoryID)
+	})
+
+	t.Run("inbox cannot be merged away", func(t *testing.T) {
+		userID := uuid.New().String()
+		inbox, err := categoryRepo.GetOrCreateInbox(ctx, userID)
+		require.NoError(t, err)
+		target := createTestCategory(t, ctx, categoryRepo, userID, "Work")
+
+		_, err = categoryRepo.MergeCategories(ctx, userID, inbox.ID, target.ID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInboxNotDeletable, httpErr.Code)
+	})
+}
+
+func TestCategoryRepository_RestoreGrouping(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	categoryRepo := repository.NewCategoryRepository(testServer)
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	createInCategory := func(t *testing.T, userID string, categoryID uuid.UUID, title string) *todo.Todo {
+		t.Helper()
+		result, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:      title,
+			CategoryID: &categoryID,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("deleted category comes back with its uncategorized todos", func(t *testing.T) {
+		userID := uuid.New().String()
+		deleted := createTestCategory(t, ctx, categoryRepo, userID, "Job")
+		other := createTestCategory(t, ctx, categoryRepo, userID, "Work")
+		first := createInCategory(t, userID, deleted.ID, "Write report")
+		moved := createInCategory(t, userID, deleted.ID, "Book travel")
+		untouched := createInCategory(t, userID, other.ID, "Plan sprint")
+
+		orphaned, err := categoryRepo.DeleteCategory(ctx, userID, deleted.ID)
+		require.NoError(t, err)
+		require.Len(t, orphaned, 2)
+		for _, change := range orphaned {
+			assert.Equal(t, deleted.ID, *change.Before.CategoryID)
+			assert.Nil(t, change.After.CategoryID)
+		}
+
+		item, err := todoRepo.GetTodoByID(ctx, userID, first.ID)
+		require.NoError(t, err)
+		assert.Nil(t, item.CategoryID)
+		require.NotNil(t, item.Metadata)
+		require.NotNil(t, item.Metadata.FormerCategory)
+		assert.Equal(t, deleted.ID, item.Metadata.FormerCategory.ID)
+		assert.Equal(t, "Job", item.Metadata.FormerCategory.Name)
+
+		item, err = todoRepo.GetTodoByID(ctx, userID, untouched.ID)
+		require.NoError(t, err)
+		if item.Metadata != nil {
+			assert.Nil(t, item.Metadata.FormerCategory)
+		}
+
+		// Filed elsewhere after the delete, so it stays put
+		_, err = todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{ID: moved.ID, CategoryID: &other.ID})
+		require.NoError(t, err)
+
+		restored, changes, err := categoryRepo.RestoreGrouping(ctx, userID, deleted.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, first.ID, changes[0].After.ID)
+		assert.Nil(t, changes[0].Before.CategoryID)
+		assert.Equal(t, moved.ID, changes[1].After.ID)
+		assert.Equal(t, other.ID, *changes[1].After.CategoryID)
+		assert.Equal(t, deleted.ID, restored.ID)
+		assert.Equal(t, "Job", restored.Name)
+		assert.Equal(t, deleted.Color, restored.Color)
+
+		item, err = todoRepo.GetTodoByID(ctx, userID, first.ID)
+		require.NoError(t, err)
+		require.NotNil(t, item.CategoryID)
+		assert.Equal(t, deleted.ID, *item.CategoryID)
+		if item.Metadata != nil {
+			assert.Nil(t, item.Metadata.FormerCategory)
+		}
+
+		item, err = todoRepo.GetTodoByID(ctx, userID, moved.ID)
+		require.NoError(t, err)
+		require.NotNil(t, item.CategoryID)
+		assert.Equal(t, other.ID, *item.CategoryID)
+		if item.Metadata != nil {
+			assert.Nil(t, item.Metadata.FormerCategory)
+		}
+
+		// The grouping can only be restored once
+		_, _, err = categoryRepo.RestoreGrouping(ctx, userID, deleted.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeCategoryNotFound, httpErr.Code)
+	})
+
+	t.Run("another user's deleted category cannot be restored", func(t *testing.T) {
+		userID := uuid.New().String()
+		deleted := createTestCategory(t, ctx, categoryRepo, userID, "Job")
+		createInCategory(t, userID, deleted.ID, "Write report")
+
+		_, err := categoryRepo.DeleteCategory(ctx, userID, deleted.ID)
+		require.NoError(t, err)
+
+		_, _, err = categoryRepo.RestoreGrouping(ctx, uuid.New().String(), deleted.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeCategoryNotFound, httpErr.Code)
+	})
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type OrganizationRepository struct {
+	server *server.Server
+}
+
+func NewOrganizationRepository(server *server.Server) *OrganizationRepository {
+	return &OrganizationRepository{server: server}
+}
+
+// CreateOrganization creates an organization with its creator as the owner
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, userID string,
+	payload *organization.CreateOrganizationPayload,
+) (*organization.OrganizationWithRole, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin create organization transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	stmt := `
+		INSERT INTO
+			organizations (name, created_by)
+		VALUES
+			(@name, @user_id)
+		RETURNING
+			*
+	`
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"name":    payload.Name,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create organization query for user_id=%s name=%s: %w",
+			userID, payload.Name, err)
+	}
+
+	org, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[organization.Organization])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:organizations for user_id=%s name=%s: %w",
+			userID, payload.Name, err)
+	}
+
+	if _, err := addMember(ctx, tx, org.ID, userID, organization.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit create organization for user_id=%s: %w", userID, err)
+	}
+
+	return &organization.OrganizationWithRole{
+		Organization: org,
+		Role:         organization.RoleOwner,
+	}, nil
+}
+
+// GetOrganizationsForUser returns every organization the user belongs to
+// along with their role in it.
+func (r *OrganizationRepository) GetOrganizationsForUser(ctx context.Context,
+	userID string,
+) ([]organization.OrganizationWithRole, error) {
+	stmt := `
+		SELECT
+			o.*,
+			m.role
+		FROM
+			organizations o
+			JOIN organization_members m ON m.org_id=o.id
+		WHERE
+			m.user_id=@user_id
+		ORDER BY
+			o.name ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get organizations query for user_id=%s: %w", userID, err)
+	}
+
+	orgs, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.OrganizationWithRole])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:organizations for user_id=%s: %w", userID, err)
+	}
+
+	return orgs, nil
+}
+
+// GetMembership returns the user's membership in the organization, or nil
+// when they are not a member.
+func (r *OrganizationRepository) GetMembership(ctx context.Context, orgID uuid.UUID,
+	userID string,
+) (*organization.Membership, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			organization_members
+		WHERE
+			org_id=@org_id
+			AND user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"org_id":  orgID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get membership query for org_id=%s user_id=%s: %w",
+			orgID.String(), userID, err)
+	}
+
+	membership, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[organization.Membership])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:organization_members for org_id=%s user_id=%s: %w",
+			orgID.String(), userID, err)
+	}
+
+	return &membership, nil
+}
+
+func (r *OrganizationRepository) GetMembers(ctx context.Context, orgID uuid.UUID) ([]organization.Membership, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			organization_members
+		WHERE
+			org_id=@org_id
+		ORDER BY
+			created_at ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"org_id": orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get members query for org_id=%s: %w", orgID.String(), err)
+	}
+
+	members, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.Membership])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:organization_members for org_id=%s: %w",
+			orgID.String(), err)
+	}
+
+	return members, nil
+}
+
+// AddMember adds a user to the organization, or changes their role if they
+// already belong to it.
+func (r *OrganizationRepository) AddMember(ctx context.Context, orgID uuid.UUID, userID string,
+	role organization.Role,
+) (*organization.Membership, error) {
+	return addMember(ctx, r.server.DB.Pool, orgID, userID, role)
+}
+
+func addMember(ctx context.Context, q querier, orgID uuid.UUID, userID string,
+	role organization.Role,
+) (*organization.Membership, error) {
+	stmt := `
+		INSERT INTO
+			organization_members (org_id, user_id, role)
+		VALUES
+			(@org_id, @user_id, @role)
+		ON CONFLICT (org_id, user_id) DO UPDATE
+		SET
+			role = EXCLUDED.role
+		RETURNING
+			*
+	`
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
+		"org_id":  orgID,
+		"user_id": userID,
+		"role":    role,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute add member query for org_id=%s user_id=%s: %w",
+			orgID.String(), userID, err)
+	}
+
+	membership, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[organization.Membership])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:organization_members for org_id=%s user_id=%s: %w",
+			orgID.String(), userID, err)
+	}
+
+	return &membership, nil
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID uuid.UUID, userID string) error {
+	stmt := `
+		DELETE FROM organization_members
+		WHERE
+			org_id=@org_id
+			AND user_id=@user_id
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"org_id":  orgID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute remove member query for org_id=%s user_id=%s: %w",
+			orgID.String(), userID, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		code := errs.CodeMemberNotFound
+		return errs.NewNotFoundError("member not found", false, &code)
+	}
+
+	return nil
+}
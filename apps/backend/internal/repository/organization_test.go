@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRepository_Scoping(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	orgRepo := repository.NewOrganizationRepository(testServer)
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	ownerID := uuid.New().String()
+	memberID := uuid.New().String()
+	outsiderID := uuid.New().String()
+
+	org, err := orgRepo.CreateOrganization(ctx, ownerID, &organization.CreateOrganizationPayload{Name: "Team"})
+	require.NoError(t, err)
+	assert.Equal(t, organization.RoleOwner, org.Role)
+
+	_, err = orgRepo.AddMember(ctx, org.ID, memberID, organization.RoleViewer)
+	require.NoError(t, err)
+
+	orgTodo, err := todoRepo.CreateOrgTodo(ctx, ownerID, org.ID, &todo.CreateTodoPayload{Title: "Shared"})
+	require.NoError(t, err)
+	require.NotNil(t, orgTodo.OrgID)
+	assert.Equal(t, org.ID, *orgTodo.OrgID)
+
+	personal := createTestTodo(t, ctx, todoRepo, ownerID)
+
+	listQuery := func() *todo.GetTodosQuery {
+		return &todo.GetTodosQuery{Page: testing_pkg.Ptr(1), Limit: testing_pkg.Ptr(20)}
+	}
+
+	t.Run("member sees organization todos", func(t *testing.T) {
+		membership, err := orgRepo.GetMembership(ctx, org.ID, memberID)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, organization.RoleViewer, membership.Role)
+
+		result, err := todoRepo.GetOrgTodos(ctx, membership.OrgID, listQuery())
+		require.NoError(t, err)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, orgTodo.ID, result.Data[0].ID)
+
+		fetched, err := todoRepo.GetOrgTodoByID(ctx, membership.OrgID, orgTodo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Shared", fetched.Title)
+
+		orgs, err := orgRepo.GetOrganizationsForUser(ctx, memberID)
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		assert.Equal(t, org.ID, orgs[0].ID)
+	})
+
+	t.Run("non-member has no membership", func(t *testing.T) {
+		membership, err := orgRepo.GetMembership(ctx, org.ID, outsiderID)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+
+		orgs, err := orgRepo.GetOrganizationsForUser(ctx, outsiderID)
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+	})
+
+	t.Run("personal todos remain private", func(t *testing.T) {
+		_, err := todoRepo.GetOrgTodoByID(ctx, org.ID, personal.ID)
+		require.Error(t, err)
+
+		ownerTodos, err := todoRepo.GetTodos(ctx, ownerID, listQuery())
+		require.NoError(t, err)
+		require.Len(t, ownerTodos.Data, 1)
+		assert.Equal(t, personal.ID, ownerTodos.Data[0].ID)
+
+		memberTodos, err := todoRepo.GetTodos(ctx, memberID, listQuery())
+		require.NoError(t, err)
+		assert.Empty(t, memberTodos.Data)
+
+		_, err = todoRepo.GetTodoByID(ctx, memberID, personal.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("removed member loses access", func(t *testing.T) {
+		require.NoError(t, orgRepo.RemoveMember(ctx, org.ID, memberID))
+
+		membership, err := orgRepo.GetMembership(ctx, org.ID, memberID)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+
+		err = orgRepo.RemoveMember(ctx, org.ID, memberID)
+		require.Error(t, err)
+	})
+}
+
+func TestOrganizationRepository_ScopedWrites(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	orgRepo := repository.NewOrganizationRepository(testServer)
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	ownerID := uuid.New().String()
+	writerID := uuid.New().String()
+
+	org, err := orgRepo.CreateOrganization(ctx, ownerID, &organization.CreateOrganizationPayload{Name: "Team"})
+	require.NoError(t, err)
+	_, err = orgRepo.AddMember(ctx, org.ID, writerID, organization.RoleMember)
+	require.NoError(t, err)
+
+	orgTodo, err := todoRepo.CreateOrgTodo(ctx, writerID, org.ID, &todo.CreateTodoPayload{Title: "Shared"})
+	require.NoError(t, err)
+
+	t.Run("members edit todos created by others", func(t *testing.T) {
+		updated, err := todoRepo.UpdateOrgTodo(ctx, org.ID, &todo.UpdateTodoPayload{
+			ID:    orgTodo.ID,
+			Title: testing_pkg.Ptr("Edited by the team"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Edited by the team", updated.Title)
+		assert.Equal(t, writerID, updated.UserID)
+	})
+
+	t.Run("organization todos are out of the creator's personal scope", func(t *testing.T) {
+		_, err := todoRepo.CheckTodoExists(ctx, writerID, orgTodo.ID)
+		require.Error(t, err)
+
+		_, err = todoRepo.UpdateTodo(ctx, writerID, &todo.UpdateTodoPayload{
+			ID:    orgTodo.ID,
+			Title: testing_pkg.Ptr("Edited after leaving"),
+		})
+		require.Error(t, err)
+
+		deleted, err := todoRepo.BulkDeleteTodos(ctx, writerID, []uuid.UUID{orgTodo.ID})
+		require.NoError(t, err)
+		assert.Empty(t, deleted)
+	})
+
+	t.Run("personal todos are out of the organization scope", func(t *testing.T) {
+		personal := createTestTodo(t, ctx, todoRepo, ownerID)
+
+		_, err := todoRepo.CheckOrgTodoExists(ctx, org.ID, personal.ID)
+		require.Error(t, err)
+
+		err = todoRepo.DeleteOrgTodo(ctx, org.ID, personal.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("members delete organization todos", func(t *testing.T) {
+		require.NoError(t, todoRepo.DeleteOrgTodo(ctx, org.ID, orgTodo.ID))
+
+		_, err := todoRepo.CheckOrgTodoExists(ctx, org.ID, orgTodo.ID)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type PreferenceRepository struct {
+	server *server.Server
+}
+
+func NewPreferenceRepository(server *server.Server) *PreferenceRepository {
+	return &PreferenceRepository{server: server}
+}
+
+// GetPreferences returns the stored preferences for a user, or defaults when
+// the user has never saved any.
+func (r *PreferenceRepository) GetPreferences(ctx context.Context, userID string) (*preference.Preferences, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			user_preferences
+		WHERE
+			user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get preferences query for user_id=%s: %w", userID, err)
+	}
+
+	prefs, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[preference.Preferences])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &preference.Preferences{
+				UserID:   userID,
+				Timezone: preference.DefaultTimezone,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:user_preferences for user_id=%s: %w", userID, err)
+	}
+
+	return &prefs, nil
+}
+
+func (r *PreferenceRepository) UpsertPreferences(ctx context.Context, userID string,
+	payload *preference.UpdatePreferencesPayload,
+) (*preference.Preferences, error) {
+	stmt := `
+		INSERT INTO
+			user_preferences (user_id, timezone, day_start_hour)
+		VALUES
+			(@user_id, COALESCE(@timezone, 'UTC'), COALESCE(@day_start_hour, 0))
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			timezone=COALESCE(@timezone, user_preferences.timezone),
+			day_start_hour=COALESCE(@day_start_hour, user_preferences.day_start_hour)
+		RETURNING
+		*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":        userID,
+		"timezone":       payload.Timezone,
+		"day_start_hour": payload.DayStartHour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute upsert preferences query for user_id=%s: %w", userID, err)
+	}
+
+	prefs, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[preference.Preferences])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:user_preferences for user_id=%s: %w", userID, err)
+	}
+
+	return &prefs, nil
+}
@@ -3,15 +3,35 @@ package repository
 import "github.com/sriniously/tasker/internal/server"
 
 type Repositories struct {
-	Todo     *TodoRepository
-	Comment  *CommentRepository
-	Category *CategoryRepository
+	Todo         *TodoRepository
+	Comment      *CommentRepository
+	Category     *CategoryRepository
+	Admin        *AdminRepository
+	Preference   *PreferenceRepository
+	Activity     *ActivityRepository
+	Organization *OrganizationRepository
+	Snapshot     *SnapshotRepository
+	Streak       *StreakRepository
+	Account      *AccountRepository
+	Tag          *TagRepository
+	Template     *TemplateRepository
+	Webhook      *WebhookRepository
 }
 
 func NewRepositories(s *server.Server) *Repositories {
 	return &Repositories{
-		Todo:     NewTodoRepository(s),
-		Comment:  NewCommentRepository(s),
-		Category: NewCategoryRepository(s),
+		Todo:         NewTodoRepository(s),
+		Comment:      NewCommentRepository(s),
+		Category:     NewCategoryRepository(s),
+		Admin:        NewAdminRepository(s),
+		Preference:   NewPreferenceRepository(s),
+		Activity:     NewActivityRepository(s),
+		Organization: NewOrganizationRepository(s),
+		Snapshot:     NewSnapshotRepository(s),
+		Streak:       NewStreakRepository(s),
+		Account:      NewAccountRepository(s),
+		Tag:          NewTagRepository(s),
+		Template:     NewTemplateRepository(s),
+		Webhook:      NewWebhookRepository(s),
 	}
 }
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// SnapshotRepository keeps exported Postgres snapshots alive so a client can
+// page through its todos against one consistent view. Each snapshot is held
+// open by a read-only repeatable-read transaction on its own connection until
+// it expires or is closed, and a user has at most one at a time. As those
+// connections come out of the shared pool, only a few snapshots may be open
+// at once.
+//
+// Snapshots live in the server instance that opened them: a token is only
+// known there, so paging through a snapshot needs the load balancer to keep
+// sending the client to that instance. Elsewhere the token reads as expired.
+type SnapshotRepository struct {
+	server *server.Server
+
+	mu     sync.Mutex
+	open   map[string]*listSnapshot
+	byUser map[string]string
+	// opening counts snapshots being opened, which already take up a place
+	opening int
+}
+
+// snapshotIdleGrace is how much longer than its TTL Postgres lets a snapshot
+// transaction sit idle before ending it
+const snapshotIdleGrace = time.Minute
+
+type listSnapshot struct {
+	userID     string
+	snapshotID string
+	tx         pgx.Tx
+	timer      *time.Timer
+}
+
+func NewSnapshotRepository(server *server.Server) *SnapshotRepository {
+	return &SnapshotRepository{
+		server: server,
+		open:   make(map[string]*listSnapshot),
+		byUser: make(map[string]string),
+	}
+}
+
+// OpenSnapshot freezes the current state of the database for the user's paged
+// reads, replacing any snapshot the user already had open. It is refused with
+// a service unavailable error while the instance has as many snapshots open
+// as it allows.
+func (r *SnapshotRepository) OpenSnapshot(ctx context.Context, userID string,
+	ttl time.Duration,
+) (*todo.ListSnapshot, error) {
+	if err := r.reserve(userID); err != nil {
+		return nil, err
+	}
+	defer r.unreserve()
+
+	// The transaction outlives the request that opened it
+	tx, err := r.server.DB.Pool.BeginTx(context.WithoutCancel(ctx), pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot transaction for user_id=%s: %w", userID, err)
+	}
+
+	// The transaction sits idle between pages for up to ttl, longer than the
+	// pool's idle-in-transaction timeout allows. Postgres still ends it a
+	// little after that in case the snapshot is never closed here.
+	idleTimeout := (ttl + snapshotIdleGrace).Milliseconds()
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL idle_in_transaction_session_timeout = %d", idleTimeout)); err != nil {
+		_ = tx.Rollback(context.Background())
+		return nil, fmt.Errorf("failed to extend idle timeout for snapshot of user_id=%s: %w", userID, err)
+	}
+
+	var snapshotID string
+	if err := tx.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&snapshotID); err != nil {
+		_ = tx.Rollback(context.Background())
+		return nil, fmt.Errorf("failed to export snapshot for user_id=%s: %w", userID, err)
+	}
+
+	token := uuid.New().String()
+	expiresAt := time.Now().Add(ttl)
+
+	r.mu.Lock()
+	if previous, ok := r.byUser[userID]; ok {
+		r.closeLocked(previous)
+	}
+	r.open[token] = &listSnapshot{
+		userID:     userID,
+		snapshotID: snapshotID,
+		tx:         tx,
+		timer:      time.AfterFunc(ttl, func() { r.CloseSnapshot(userID, token) }),
+	}
+	r.byUser[userID] = token
+	r.mu.Unlock()
+
+	return &todo.ListSnapshot{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// reserve takes a place for a snapshot the user is opening. The snapshot it
+// replaces gives up its place, so reopening is never refused.
+func (r *SnapshotRepository) reserve(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := len(r.open) + r.opening
+	if _, ok := r.byUser[userID]; ok {
+		taken--
+	}
+	if taken >= r.server.Config.Todo.GetMaxOpenSnapshots(r.server.Config.Database.MaxOpenConns) {
+		return errs.NewServiceUnavailableError("Too many snapshots are open, try again later", true)
+	}
+
+	r.opening++
+	return nil
+}
+
+func (r *SnapshotRepository) unreserve() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.opening--
+}
+
+// SnapshotID returns the exported snapshot behind the user's token, or a not
+// found error once it has expired
+func (r *SnapshotRepository) SnapshotID(userID, token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot, ok := r.open[token]
+	if !ok || snapshot.userID != userID {
+		code := errs.CodeSnapshotExpired
+		return "", errs.NewNotFoundError("Snapshot has expired, open a new one", true, &code)
+	}
+
+	return snapshot.snapshotID, nil
+}
+
+// CloseSnapshot releases the user's snapshot and its connection. Closing an
+// unknown or expired snapshot is a no-op.
+func (r *SnapshotRepository) CloseSnapshot(userID, token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if snapshot, ok := r.open[token]; ok && snapshot.userID == userID {
+		r.closeLocked(token)
+	}
+}
+
+// CloseAll releases every open snapshot. The database pool can't close while
+// snapshots hold connections, so this runs before shutdown.
+func (r *SnapshotRepository) CloseAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for token := range r.open {
+		r.closeLocked(token)
+	}
+}
+
+func (r *SnapshotRepository) closeLocked(token string) {
+	snapshot := r.open[token]
+	snapshot.timer.Stop()
+	_ = snapshot.tx.Rollback(context.Background())
+
+	delete(r.open, token)
+	if r.byUser[snapshot.userID] == token {
+		delete(r.byUser, snapshot.userID)
+	}
+}
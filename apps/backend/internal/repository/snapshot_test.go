@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRepository_PagingIsStable(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	snapshotRepo := repository.NewSnapshotRepository(testServer)
+	defer snapshotRepo.CloseAll()
+
+	userID := uuid.New().String()
+	createTestTodos(t, ctx, todoRepo, userID, 4)
+
+	snapshot, err := snapshotRepo.OpenSnapshot(ctx, userID, time.Minute)
+	require.NoError(t, err)
+	snapshotID, err := snapshotRepo.SnapshotID(userID, snapshot.Token)
+	require.NoError(t, err)
+
+	page := func(n int) *todo.GetTodosQuery {
+		return &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(n),
+			Limit: testing_pkg.Ptr(2),
+			Sort:  testing_pkg.Ptr("created_at"),
+			Order: testing_pkg.Ptr("desc"),
+		}
+	}
+
+	first, err := todoRepo.GetTodosInSnapshot(ctx, userID, snapshotID, page(1))
+	require.NoError(t, err)
+	require.Len(t, first.Data, 2)
+
+	// New todos land at the top of the live listing and would shift page 2
+	createTestTodos(t, ctx, todoRepo, userID, 2)
+
+	second, err := todoRepo.GetTodosInSnapshot(ctx, userID, snapshotID, page(2))
+	require.NoError(t, err)
+	require.Len(t, second.Data, 2)
+
+	t.Run("pages agree on the total and never repeat a todo", func(t *testing.T) {
+		assert.Equal(t, 4, first.Total)
+		assert.Equal(t, 4, second.Total)
+
+		seen := make(map[uuid.UUID]bool)
+		for _, item := range append(first.Data, second.Data...) {
+			assert.False(t, seen[item.ID], "todo %s repeated across pages", item.ID)
+			seen[item.ID] = true
+		}
+		assert.Len(t, seen, 4)
+	})
+
+	t.Run("live listing sees the new todos", func(t *testing.T) {
+		live, err := todoRepo.GetTodos(ctx, userID, page(2))
+		require.NoError(t, err)
+		assert.Equal(t, 6, live.Total)
+	})
+
+	t.Run("snapshot belongs to the user who opened it", func(t *testing.T) {
+		_, err := snapshotRepo.SnapshotID(uuid.New().String(), snapshot.Token)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeSnapshotExpired, httpErr.Code)
+	})
+
+	t.Run("closed snapshot can't be read", func(t *testing.T) {
+		snapshotRepo.CloseSnapshot(userID, snapshot.Token)
+
+		_, err := snapshotRepo.SnapshotID(userID, snapshot.Token)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeSnapshotExpired, httpErr.Code)
+
+		_, err = todoRepo.GetTodosInSnapshot(ctx, userID, snapshotID, page(1))
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeSnapshotExpired, httpErr.Code)
+	})
+}
+
+func TestSnapshotRepository_Expiry(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	snapshotRepo := repository.NewSnapshotRepository(testServer)
+	defer snapshotRepo.CloseAll()
+
+	userID := uuid.New().String()
+
+	t.Run("snapshot expires after its ttl", func(t *testing.T) {
+		snapshot, err := snapshotRepo.OpenSnapshot(ctx, userID, 50*time.Millisecond)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			_, err := snapshotRepo.SnapshotID(userID, snapshot.Token)
+			return err != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("opening a new snapshot replaces the previous one", func(t *testing.T) {
+		previous, err := snapshotRepo.OpenSnapshot(ctx, userID, time.Minute)
+		require.NoError(t, err)
+		current, err := snapshotRepo.OpenSnapshot(ctx, userID, time.Minute)
+		require.NoError(t, err)
+
+		_, err = snapshotRepo.SnapshotID(userID, previous.Token)
+		assert.Error(t, err)
+		_, err = snapshotRepo.SnapshotID(userID, current.Token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestSnapshotRepository_Limit(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	testServer.Config.Todo = &config.TodoConfig{MaxOpenSnapshots: 2}
+
+	ctx := context.Background()
+	snapshotRepo := repository.NewSnapshotRepository(testServer)
+	defer snapshotRepo.CloseAll()
+
+	alice, bob, carol := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	aliceSnapshot, err := snapshotRepo.OpenSnapshot(ctx, alice, time.Minute)
+	require.NoError(t, err)
+	_, err = snapshotRepo.OpenSnapshot(ctx, bob, time.Minute)
+	require.NoError(t, err)
+
+	t.Run("opening beyond the limit is refused", func(t *testing.T) {
+		_, err := snapshotRepo.OpenSnapshot(ctx, carol, time.Minute)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Status)
+	})
+
+	t.Run("reopening a snapshot at the limit is allowed", func(t *testing.T) {
+		reopened, err := snapshotRepo.OpenSnapshot(ctx, alice, time.Minute)
+		require.NoError(t, err)
+		aliceSnapshot = reopened
+	})
+
+	t.Run("closing a snapshot frees its place", func(t *testing.T) {
+		snapshotRepo.CloseSnapshot(alice, aliceSnapshot.Token)
+
+		_, err := snapshotRepo.OpenSnapshot(ctx, carol, time.Minute)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type StreakRepository struct {
+	server *server.Server
+}
+
+func NewStreakRepository(server *server.Server) *StreakRepository {
+	return &StreakRepository{server: server}
+}
+
+// GetStreak returns the user's streak, or an empty one when no day has been
+// judged yet
+func (r *StreakRepository) GetStreak(ctx context.Context, userID string) (*streak.Streak, error) {
+	return getStreak(ctx, r.server.DB.Pool, userID, false)
+}
+
+func getStreak(ctx context.Context, q querier, userID string, forUpdate bool) (*streak.Streak, error) {
+	stmt := `
+		SELECT
+			user_id,
+			current_streak,
+			longest_streak,
+			last_day,
+			updated_at
+		FROM
+			user_streaks
+		WHERE
+			user_id=@user_id
+	`
+	if forUpdate {
+		stmt += " FOR UPDATE"
+	}
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get streak query for user_id=%s: %w", userID, err)
+	}
+
+	s, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[streak.Streak])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &streak.Streak{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:user_streaks for user_id=%s: %w", userID, err)
+	}
+
+	return &s, nil
+}
+
+// GetEndedDays judges, for every user with todos, the day that most recently
+// ended in their timezone as of now, skipping users whose day has already
+// been recorded. Days roll over at the user's day start hour. A day is clean
+// when no todo was overdue at its end: completion is judged by completed_at,
+// so a late run still sees the day as it ended. Archived todos never count
+// against a day.
+func (r *StreakRepository) GetEndedDays(ctx context.Context, now time.Time) ([]streak.DayResult, error) {
+	stmt := `
+		WITH
+			users AS (
+				SELECT
+					user_id,
+					user_timezone(user_id) AS timezone,
+					user_day_start_hour(user_id) AS day_start_hour
+				FROM
+					(
+						SELECT DISTINCT
+							user_id
+						FROM
+							todos
+					) u
+			),
+			days AS (
+				SELECT
+					user_id,
+					timezone,
+					local_day(@now::TIMESTAMPTZ, timezone, day_start_hour) - 1 AS day,
+					(
+						local_day(@now::TIMESTAMPTZ, timezone, day_start_hour)::TIMESTAMP
+						+ make_interval(hours => day_start_hour)
+					) AT TIME ZONE timezone AS day_end
+				FROM
+					users
+			)
+		SELECT
+			d.user_id,
+			d.day,
+			NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todos t
+				WHERE
+					t.user_id = d.user_id
+					AND t.deleted_at IS NULL
+					AND t.due_date IS NOT NULL
+					AND t.status != 'archived'
+					AND t.created_at < d.day_end
+					AND (
+						t.completed_at IS NULL
+						OR t.completed_at >= d.day_end
+					)
+					AND CASE
+						WHEN t.all_day THEN (t.due_date AT TIME ZONE d.timezone)::DATE <= d.day
+						ELSE t.due_date < d.day_end
+					END
+			) AS clean
+		FROM
+			days d
+		WHERE
+			NOT EXISTS (
+				SELECT
+					1
+				FROM
+					user_clean_days c
+				WHERE
+					c.user_id = d.user_id
+					AND c.day = d.day
+			)
+		ORDER BY
+			d.user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"now": now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get ended days query: %w", err)
+	}
+
+	days, err := pgx.CollectRows(rows, pgx.RowToStructByName[streak.DayResult])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for ended days: %w", err)
+	}
+
+	return days, nil
+}
+
+// RecordDay stores the judged day and folds it into the user's streak. A day
+// that was already recorded leaves the streak as it is.
+func (r *StreakRepository) RecordDay(ctx context.Context, result streak.DayResult) (*streak.Streak, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin record day transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	insertStmt := `
+		INSERT INTO
+			user_clean_days (user_id, day, clean)
+		VALUES
+			(@user_id, @day, @clean)
+		ON CONFLICT (user_id, day) DO NOTHING
+	`
+
+	inserted, err := tx.Exec(ctx, insertStmt, pgx.NamedArgs{
+		"user_id": result.UserID,
+		"day":     result.Day,
+		"clean":   result.Clean,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record clean day for user_id=%s: %w", result.UserID, err)
+	}
+
+	current, err := getStreak(ctx, tx, result.UserID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if inserted.RowsAffected() == 0 || !current.Record(result.Day, result.Clean) {
+		return current, nil
+	}
+
+	upsertStmt := `
+		INSERT INTO
+			user_streaks (user_id, current_streak, longest_streak, last_day)
+		VALUES
+			(@user_id, @current_streak, @longest_streak, @last_day)
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			current_streak = EXCLUDED.current_streak,
+			longest_streak = EXCLUDED.longest_streak,
+			last_day = EXCLUDED.last_day
+		RETURNING
+			user_id,
+			current_streak,
+			longest_streak,
+			last_day,
+			updated_at
+	`
+
+	rows, err := tx.Query(ctx, upsertStmt, pgx.NamedArgs{
+		"user_id":        result.UserID,
+		"current_streak": current.CurrentStreak,
+		"longest_streak": current.LongestStreak,
+		"last_day":       current.LastDay,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute upsert streak query for user_id=%s: %w", result.UserID, err)
+	}
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[streak.Streak])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:user_streaks for user_id=%s: %w", result.UserID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit record day for user_id=%s: %w", result.UserID, err)
+	}
+
+	return &updated, nil
+}
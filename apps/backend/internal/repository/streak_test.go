@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreakRepository_RecordEndedDays(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	streakRepo := repository.NewStreakRepository(testServer)
+
+	now := time.Now()
+	lastWeek := now.AddDate(0, 0, -7)
+	twoDaysAgo := now.AddDate(0, 0, -2)
+	nextWeek := now.AddDate(0, 0, 7)
+
+	createAt := func(userID string, dueDate time.Time) *todo.Todo {
+		created, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Streak todo",
+			DueDate: &dueDate,
+		})
+		require.NoError(t, err)
+
+		_, err = testServer.DB.Pool.Exec(ctx, `UPDATE todos SET created_at=$1 WHERE id=$2`, lastWeek, created.ID)
+		require.NoError(t, err)
+
+		return created
+	}
+
+	cleanUser := uuid.New().String()
+	createAt(cleanUser, nextWeek)
+
+	overdueUser := uuid.New().String()
+	createAt(overdueUser, twoDaysAgo)
+
+	endedDays := func(at time.Time) map[string]streak.DayResult {
+		days, err := streakRepo.GetEndedDays(ctx, at)
+		require.NoError(t, err)
+
+		byUser := make(map[string]streak.DayResult, len(days))
+		for _, day := range days {
+			byUser[day.UserID] = day
+		}
+		return byUser
+	}
+
+	days := endedDays(now)
+	require.Contains(t, days, cleanUser)
+	require.Contains(t, days, overdueUser)
+	assert.True(t, days[cleanUser].Clean)
+	assert.False(t, days[overdueUser].Clean)
+
+	t.Run("recording a day updates the streak once", func(t *testing.T) {
+		s, err := streakRepo.RecordDay(ctx, days[cleanUser])
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.CurrentStreak)
+
+		s, err = streakRepo.RecordDay(ctx, days[cleanUser])
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.CurrentStreak)
+
+		s, err = streakRepo.RecordDay(ctx, days[overdueUser])
+		require.NoError(t, err)
+		assert.Equal(t, 0, s.CurrentStreak)
+
+		assert.NotContains(t, endedDays(now), cleanUser)
+	})
+
+	t.Run("the next clean day extends the streak", func(t *testing.T) {
+		next := endedDays(now.Add(24 * time.Hour))
+		require.Contains(t, next, cleanUser)
+
+		_, err := streakRepo.RecordDay(ctx, next[cleanUser])
+		require.NoError(t, err)
+
+		s, err := streakRepo.GetStreak(ctx, cleanUser)
+		require.NoError(t, err)
+		assert.Equal(t, 2, s.CurrentStreak)
+		assert.Equal(t, 2, s.LongestStreak)
+	})
+
+	t.Run("a user without a judged day has no streak", func(t *testing.T) {
+		s, err := streakRepo.GetStreak(ctx, uuid.New().String())
+		require.NoError(t, err)
+		assert.Zero(t, s.CurrentStreak)
+		assert.Nil(t, s.LastDay)
+	})
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/tag"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// TagRepository manages the tags mirrored from todo metadata. The tags and
+// todo_tags tables are kept in step by a trigger on todos, so renaming or
+// deleting a tag is done by rewriting the metadata of the todos carrying it.
+type TagRepository struct {
+	server *server.Server
+}
+
+func NewTagRepository(server *server.Server) *TagRepository {
+	return &TagRepository{server: server}
+}
+
+// tagColumns selects a tag with its usage count, for a tags table aliased tg
+const tagColumns = `
+	tg.*,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			todo_tags tt
+			JOIN todos t ON t.id=tt.todo_id
+		WHERE
+			tt.tag_id=tg.id
+			AND t.deleted_at IS NULL
+	) AS usage_count
+`
+
+// GetTags lists the user's tags with how many todos carry each
+func (r *TagRepository) GetTags(ctx context.Context, userID string, query *tag.GetTagsQuery) ([]tag.Tag, error) {
+	stmt := "SELECT" + tagColumns + " FROM tags tg WHERE tg.user_id=@user_id"
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	if query.Search != nil {
+		stmt += " AND tg.name ILIKE @search"
+		args["search"] = "%" + *query.Search + "%"
+	}
+
+	if query.Sort != nil && *query.Sort == "usage" {
+		stmt += " ORDER BY usage_count DESC, LOWER(tg.name) ASC"
+	} else {
+		stmt += " ORDER BY LOWER(tg.name) ASC"
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get tags query for user_id=%s: %w", userID, err)
+	}
+
+	tags, err := pgx.CollectRows(rows, pgx.RowToStructByName[tag.Tag])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:tags for user_id=%s: %w", userID, err)
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) GetTagByID(ctx context.Context, userID string, tagID uuid.UUID) (*tag.Tag, error) {
+	return getTag(ctx, r.server.DB.Pool, userID, "tg.id=@tag_id", pgx.NamedArgs{"tag_id": tagID}, false)
+}
+
+// getTag returns the user's tag matching condition, or a not found error
+func getTag(ctx context.Context, q querier, userID string, condition string, args pgx.NamedArgs,
+	forUpdate bool,
+) (*tag.Tag, error) {
+	found, err := findTag(ctx, q, userID, condition, args, forUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		code := errs.CodeTagNotFound
+		return nil, errs.NewNotFoundError("tag not found", false, &code)
+	}
+
+	return found, nil
+}
+
+// findTag returns the user's tag matching condition, or nil when there is none
+func findTag(ctx context.Context, q querier, userID string, condition string, args pgx.NamedArgs,
+	forUpdate bool,
+) (*tag.Tag, error) {
+	stmt := "SELECT" + tagColumns + " FROM tags tg WHERE tg.user_id=@user_id AND " + condition
+	if forUpdate {
+		stmt += " FOR UPDATE OF tg"
+	}
+	args["user_id"] = userID
+
+	rows, err := q.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get tag query for user_id=%s: %w", userID, err)
+	}
+
+	found, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[tag.Tag])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:tags for user_id=%s: %w", userID, err)
+	}
+
+	return &found, nil
+}
+
+// RenameTag renames the tag on every todo of the user carrying it, trash
+// included, in one transaction. When another of the user's tags already has
+// the new name, in any letter case, the two are merged into that one. The
+// todos it rewrote are returned alongside the result.
+func (r *TagRepository) RenameTag(ctx context.Context, userID string, tagID uuid.UUID, name string,
+) (*tag.RenameTagResponse, []todo.Change, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin rename tag transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	existing, err := getTag(ctx, tx, userID, "tg.id=@tag_id", pgx.NamedArgs{"tag_id": tagID}, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, err := findTag(ctx, tx, userID, "LOWER(tg.name)=LOWER(@name) AND tg.id<>@tag_id",
+		pgx.NamedArgs{"name": name, "tag_id": tagID}, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := target != nil
+
+	// Renamed first so the trigger links the rewritten todos to this tag
+	// rather than creating a new one
+	if !merged {
+		if _, err := tx.Exec(ctx, "UPDATE tags SET name=@name WHERE id=@tag_id", pgx.NamedArgs{
+			"name":   name,
+			"tag_id": tagID,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to rename tag_id=%s: %w", tagID.String(), err)
+		}
+	}
+
+	changes, err := replaceTag(ctx, tx, userID, tagID, existing.Name, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	survivorID := tagID
+	if merged {
+		if _, err := tx.Exec(ctx, "DELETE FROM tags WHERE id=@tag_id", pgx.NamedArgs{
+			"tag_id": tagID,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete merged tag_id=%s: %w", tagID.String(), err)
+		}
+		survivorID = target.ID
+	}
+
+	survivor, err := getTag(ctx, tx, userID, "tg.id=@tag_id", pgx.NamedArgs{"tag_id": survivorID}, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit rename tag for tag_id=%s: %w", tagID.String(), err)
+	}
+
+	return &tag.RenameTagResponse{
+		Tag:     *survivor,
+		Merged:  merged,
+		Updated: len(changes),
+	}, changes, nil
+}
+
+// DeleteTag removes the tag from every todo of the user carrying it, trash
+// included, and deletes it, returning the todos that changed
+func (r *TagRepository) DeleteTag(ctx context.Context, userID string, tagID uuid.UUID) ([]todo.Change, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin delete tag transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	existing, err := getTag(ctx, tx, userID, "tg.id=@tag_id", pgx.NamedArgs{"tag_id": tagID}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := replaceTag(ctx, tx, userID, tagID, existing.Name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM tags WHERE id=@tag_id", pgx.NamedArgs{
+		"tag_id": tagID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete tag_id=%s: %w", tagID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit delete tag for tag_id=%s: %w", tagID.String(), err)
+	}
+
+	return updated, nil
+}
+
+// replaceTag rewrites the metadata of the user's todos carrying the tag,
+// replacing oldTag in any letter case with newTag, or dropping it when newTag
+// is empty. Tags keep their order and a replacement a todo already carries
+// isn't duplicated.
+func replaceTag(ctx context.Context, q querier, userID string, tagID uuid.UUID, oldTag, newTag string,
+) ([]todo.Change, error) {
+	set := `
+		metadata = jsonb_set(
+			t.metadata,
+			'{tags}',
+			COALESCE(
+				(
+					SELECT
+						jsonb_agg(
+							deduped.tag
+							ORDER BY
+								deduped.position
+						)
+					FROM
+						(
+							SELECT DISTINCT
+								ON (LOWER(replaced.tag)) replaced.tag,
+								replaced.position
+							FROM
+								(
+									SELECT
+										CASE
+											WHEN LOWER(e.tag)=LOWER(@old_tag) THEN @new_tag
+											ELSE e.tag
+										END AS tag,
+										e.position
+									FROM
+										jsonb_array_elements_text(t.metadata->'tags') WITH ORDINALITY AS e (tag, position)
+								) replaced
+							WHERE
+								replaced.tag != ''
+							ORDER BY
+								LOWER(replaced.tag),
+								replaced.position
+						) deduped
+				),
+				'[]'::JSONB
+			)
+		)
+	`
+
+	where := `
+		t.user_id=@user_id
+		AND t.id IN (
+			SELECT
+				todo_id
+			FROM
+				todo_tags
+			WHERE
+				tag_id=@tag_id
+		)
+	`
+
+	changes, err := updateTodos(ctx, q, []string{set}, where, pgx.NamedArgs{
+		"user_id": userID,
+		"tag_id":  tagID,
+		"old_tag": oldTag,
+		"new_tag": newTag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace tag_id=%s on todos of user_id=%s: %w", tagID.String(), userID, err)
+	}
+
+	return changes, nil
+}
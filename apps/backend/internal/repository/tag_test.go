@@ -0,0 +1,173 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/tag"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRepository(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	tagRepo := repository.NewTagRepository(testServer)
+
+	createTagged := func(userID string, tags ...string) *todo.Todo {
+		t.Helper()
+		created, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:    "Tagged",
+			Metadata: &todo.Metadata{Tags: tags},
+		})
+		require.NoError(t, err)
+		return created
+	}
+
+	tagsOf := func(userID string, todoID uuid.UUID) []string {
+		t.Helper()
+		item, err := todoRepo.CheckTodoExists(ctx, userID, todoID)
+		require.NoError(t, err)
+		require.NotNil(t, item.Metadata)
+		return item.Metadata.Tags
+	}
+
+	tagNamed := func(userID, name string) tag.Tag {
+		t.Helper()
+		tags, err := tagRepo.GetTags(ctx, userID, &tag.GetTagsQuery{Search: &name})
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		return tags[0]
+	}
+
+	t.Run("tags are mirrored from metadata with usage counts", func(t *testing.T) {
+		userID := uuid.New().String()
+		first := createTagged(userID, "Work", "urgent")
+		createTagged(userID, "work")
+
+		sort := "usage"
+		tags, err := tagRepo.GetTags(ctx, userID, &tag.GetTagsQuery{Sort: &sort})
+		require.NoError(t, err)
+		require.Len(t, tags, 2)
+		assert.Equal(t, "Work", tags[0].Name)
+		assert.Equal(t, 2, tags[0].UsageCount)
+		assert.Equal(t, "urgent", tags[1].Name)
+		assert.Equal(t, 1, tags[1].UsageCount)
+
+		_, err = todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:       first.ID,
+			Metadata: &todo.Metadata{Tags: []string{"work"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, tagNamed(userID, "urgent").UsageCount)
+	})
+
+	t.Run("filters todos by any or all tags", func(t *testing.T) {
+		userID := uuid.New().String()
+		both := createTagged(userID, "home", "errands")
+		homeOnly := createTagged(userID, "Home")
+		createTagged(userID, "work")
+
+		page := 1
+		limit := 20
+		ids := func(result *model.PaginatedResponse[todo.PopulatedTodo]) []uuid.UUID {
+			var found []uuid.UUID
+			for _, item := range result.Data {
+				found = append(found, item.ID)
+			}
+			return found
+		}
+
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  &page,
+			Limit: &limit,
+			Tags:  []string{"HOME", "errands"},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{both.ID, homeOnly.ID}, ids(result))
+
+		all := "all"
+		result, err = todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:     &page,
+			Limit:    &limit,
+			Tags:     []string{"home", "errands"},
+			TagMatch: &all,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{both.ID}, ids(result))
+	})
+
+	t.Run("rename rewrites todo metadata", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTagged(userID, "wip", "backend")
+		wip := tagNamed(userID, "wip")
+
+		renamed, changes, err := tagRepo.RenameTag(ctx, userID, wip.ID, "in-progress")
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, []string{"wip", "backend"}, changes[0].Before.Metadata.Tags)
+		assert.False(t, renamed.Merged)
+		assert.Equal(t, wip.ID, renamed.Tag.ID)
+		assert.Equal(t, "in-progress", renamed.Tag.Name)
+		assert.Equal(t, 1, renamed.Updated)
+		assert.Equal(t, []string{"in-progress", "backend"}, tagsOf(userID, item.ID))
+	})
+
+	t.Run("rename onto an existing tag merges them", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTagged(userID, "todo", "Review")
+		other := createTagged(userID, "todo")
+		todoTag := tagNamed(userID, "todo")
+		review := tagNamed(userID, "review")
+
+		renamed, changes, err := tagRepo.RenameTag(ctx, userID, todoTag.ID, "review")
+		require.NoError(t, err)
+		assert.True(t, renamed.Merged)
+		assert.Len(t, changes, 2)
+		assert.Equal(t, review.ID, renamed.Tag.ID)
+		assert.Equal(t, 2, renamed.Tag.UsageCount)
+		assert.Equal(t, []string{"review"}, tagsOf(userID, item.ID))
+		assert.Equal(t, []string{"review"}, tagsOf(userID, other.ID))
+
+		_, err = tagRepo.GetTagByID(ctx, userID, todoTag.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTagNotFound, httpErr.Code)
+	})
+
+	t.Run("delete drops the tag from todos", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTagged(userID, "stale", "keep")
+		stale := tagNamed(userID, "stale")
+
+		updated, err := tagRepo.DeleteTag(ctx, userID, stale.ID)
+		require.NoError(t, err)
+		assert.Len(t, updated, 1)
+		assert.Equal(t, []string{"keep"}, tagsOf(userID, item.ID))
+
+		tags, err := tagRepo.GetTags(ctx, userID, &tag.GetTagsQuery{})
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		assert.Equal(t, "keep", tags[0].Name)
+	})
+
+	t.Run("tags of another user are not found", func(t *testing.T) {
+		userID := uuid.New().String()
+		createTagged(userID, "private")
+		private := tagNamed(userID, "private")
+
+		_, err := tagRepo.DeleteTag(ctx, uuid.New().String(), private.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTagNotFound, httpErr.Code)
+	})
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type TemplateRepository struct {
+	server *server.Server
+}
+
+func NewTemplateRepository(server *server.Server) *TemplateRepository {
+	return &TemplateRepository{server: server}
+}
+
+func (r *TemplateRepository) CreateTemplate(ctx context.Context, userID string, name string, description *string,
+	root template.Item,
+) (*template.Template, error) {
+	stmt := `
+		INSERT INTO
+			todo_templates (user_id, name, description, root)
+		VALUES
+			(@user_id, @name, @description, @root)
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"name":        name,
+		"description": description,
+		"root":        root,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create template query for user_id=%s name=%s: %w", userID, name, err)
+	}
+
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[template.Template])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todo_templates for user_id=%s name=%s: %w",
+			userID, name, err)
+	}
+
+	return &created, nil
+}
+
+func (r *TemplateRepository) GetTemplates(ctx context.Context, userID string,
+	query *template.GetTemplatesQuery,
+) ([]template.Template, error) {
+	stmt := "SELECT * FROM todo_templates WHERE user_id=@user_id"
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	if query.Search != nil {
+		stmt += " AND name ILIKE @search"
+		args["search"] = "%" + *query.Search + "%"
+	}
+
+	stmt += " ORDER BY LOWER(name) ASC"
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get templates query for user_id=%s: %w", userID, err)
+	}
+
+	templates, err := pgx.CollectRows(rows, pgx.RowToStructByName[template.Template])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_templates for user_id=%s: %w", userID, err)
+	}
+
+	return templates, nil
+}
+
+func (r *TemplateRepository) GetTemplateByID(ctx context.Context, userID string, templateID uuid.UUID,
+) (*template.Template, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_templates
+		WHERE
+			id=@id
+			AND user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"id":      templateID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get template by id query for template_id=%s user_id=%s: %w",
+			templateID.String(), userID, err)
+	}
+
+	found, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[template.Template])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTemplateNotFound
+			return nil, errs.NewNotFoundError("template not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_templates for template_id=%s user_id=%s: %w",
+			templateID.String(), userID, err)
+	}
+
+	return &found, nil
+}
+
+func (r *TemplateRepository) DeleteTemplate(ctx context.Context, userID string, templateID uuid.UUID) error {
+	stmt := `
+		DELETE FROM todo_templates
+		WHERE
+			id=@id
+			AND user_id=@user_id
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"id":      templateID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute delete template query for template_id=%s user_id=%s: %w",
+			templateID.String(), userID, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		code := errs.CodeTemplateNotFound
+		return errs.NewNotFoundError("template not found", false, &code)
+	}
+
+	return nil
+}
+
+// CreateTodos creates the drafted todo and its subtasks in categoryID, all in
+// one transaction, returning the top todo
+func (r *TemplateRepository) CreateTodos(ctx context.Context, userID string, draft *template.Draft,
+	categoryID *uuid.UUID,
+) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin instantiate template transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var create func(draft *template.Draft, parentID *uuid.UUID) (*todo.Todo, error)
+	create = func(draft *template.Draft, parentID *uuid.UUID) (*todo.Todo, error) {
+		payload := draft.Payload
+		payload.CategoryID = categoryID
+		payload.ParentTodoID = parentID
+
+		created, err := createTodo(ctx, tx, userID, nil, &payload, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(draft.Checklist) > 0 {
+			checklist := make([]todo.ChecklistItem, 0, len(draft.Checklist))
+			for _, text := range draft.Checklist {
+				checklist = append(checklist, todo.ChecklistItem{ID: uuid.New(), Text: text})
+			}
+
+			rows, err := tx.Query(ctx, "UPDATE todos SET checklist=@checklist WHERE id=@id RETURNING *", pgx.NamedArgs{
+				"checklist": checklist,
+				"id":        created.ID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to set checklist for todo_id=%s: %w", created.ID.String(), err)
+			}
+
+			withChecklist, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+			if err != nil {
+				return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w",
+					created.ID.String(), err)
+			}
+			created = &withChecklist
+		}
+
+		for i := range draft.Children {
+			if _, err := create(&draft.Children[i], &created.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		return created, nil
+	}
+
+	root, err := create(draft, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit instantiate template for user_id=%s: %w", userID, err)
+	}
+
+	return root, nil
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRepository(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	templateRepo := repository.NewTemplateRepository(testServer)
+	userID := uuid.New().String()
+
+	root := createTestTodo(t, ctx, todoRepo, userID)
+	_, err := todoRepo.AddChecklistItem(ctx, userID, root.ID, "Pack bags")
+	require.NoError(t, err)
+	_, err = todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:        "Book {{city}} hotel",
+		ParentTodoID: &root.ID,
+	})
+	require.NoError(t, err)
+
+	source, err := todoRepo.CheckTodoExists(ctx, userID, root.ID)
+	require.NoError(t, err)
+	subtree, err := todoRepo.GetTodoSubtree(ctx, userID, root.ID)
+	require.NoError(t, err)
+
+	created, err := templateRepo.CreateTemplate(ctx, userID, "Trip", nil, template.FromTodos(*source, subtree))
+	require.NoError(t, err)
+
+	t.Run("reads back the saved tree", func(t *testing.T) {
+		found, err := templateRepo.GetTemplateByID(ctx, userID, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Trip", found.Name)
+		assert.Equal(t, []string{"Pack bags"}, found.Root.Checklist)
+		require.Len(t, found.Root.Children, 1)
+		assert.Equal(t, []string{"city"}, found.Root.Variables())
+	})
+
+	t.Run("names are unique per user regardless of case", func(t *testing.T) {
+		_, err := templateRepo.CreateTemplate(ctx, userID, "TRIP", nil, template.Item{Title: "Other"})
+		require.Error(t, err)
+	})
+
+	t.Run("creates todos from the template", func(t *testing.T) {
+		found, err := templateRepo.GetTemplateByID(ctx, userID, created.ID)
+		require.NoError(t, err)
+		draft, err := found.Root.Render(nil, source.CreatedAt, map[string]string{"city": "Lisbon"})
+		require.NoError(t, err)
+
+		instance, err := templateRepo.CreateTodos(ctx, userID, draft, nil)
+		require.NoError(t, err)
+		assert.Equal(t, source.Title, instance.Title)
+		assert.Equal(t, todo.StatusDraft, instance.Status)
+		require.Len(t, instance.Checklist, 1)
+		assert.False(t, instance.Checklist[0].Done)
+
+		children, err := todoRepo.GetTodoSubtree(ctx, userID, instance.ID)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		assert.Equal(t, "Book Lisbon hotel", children[0].Title)
+	})
+
+	t.Run("deleted templates are gone", func(t *testing.T) {
+		require.NoError(t, templateRepo.DeleteTemplate(ctx, userID, created.ID))
+
+		_, err := templateRepo.GetTemplateByID(ctx, userID, created.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTemplateNotFound, httpErr.Code)
+	})
+}
@@ -2,50 +2,328 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sriniously/tasker/internal/errs"
 	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/model/category"
 	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/server"
 )
 
 type TodoRepository struct {
 	server *server.Server
+
+	trigramOnce      sync.Once
+	trigramAvailable bool
 }
 
 func NewTodoRepository(server *server.Server) *TodoRepository {
 	return &TodoRepository{server: server}
 }
 
+// querier is satisfied by both the pool and a transaction, so statements can
+// be shared between standalone calls and multi-step transactional ones.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// todoScope restricts a todo query to either a user's personal todos or the
+// todos shared within an organization.
+type todoScope struct {
+	condition string
+	args      pgx.NamedArgs
+	owner     string
+}
+
+// personalScope matches the user's own todos that don't belong to any
+// organization, so org todos never leak into a personal view. Like the other
+// scopes it leaves out todos in the trash.
+func personalScope(userID string) todoScope {
+	return todoScope{
+		condition: "t.user_id = @user_id AND t.org_id IS NULL AND t.deleted_at IS NULL",
+		args:      pgx.NamedArgs{"user_id": userID},
+		owner:     "user_id=" + userID,
+	}
+}
+
+// sharedScope matches the todo a live share link points at, regardless of
+// owner, so a revoked or unknown link finds nothing. Links to todos of a
+// deleted account find nothing either.
+func sharedScope(linkID uuid.UUID) todoScope {
+	return todoScope{
+		condition: `EXISTS (
+			SELECT
+				1
+			FROM
+				todo_share_links l
+			WHERE
+				l.id = @share_link_id
+				AND l.todo_id = t.id
+				AND l.revoked_at IS NULL
+		)
+		AND t.deleted_at IS NULL
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				account_deletions d
+			WHERE
+				d.user_id = t.user_id
+		)`,
+		args:  pgx.NamedArgs{"share_link_id": linkID},
+		owner: "share_link_id=" + linkID.String(),
+	}
+}
+
+// orgScope matches every todo in the organization regardless of who created it
+func orgScope(orgID uuid.UUID) todoScope {
+	return todoScope{
+		condition: "t.org_id = @org_id AND t.deleted_at IS NULL",
+		args:      pgx.NamedArgs{"org_id": orgID},
+		owner:     "org_id=" + orgID.String(),
+	}
+}
+
 func (r *TodoRepository) CreateTodo(ctx context.Context, userID string, payload *todo.CreateTodoPayload) (*todo.Todo, error) {
+	return createTodo(ctx, r.server.DB.Pool, userID, nil, payload, nil)
+}
+
+// CreateOrgTodo creates a todo owned by the organization, recording userID as
+// its creator.
+func (r *TodoRepository) CreateOrgTodo(ctx context.Context, userID string, orgID uuid.UUID,
+	payload *todo.CreateTodoPayload,
+) (*todo.Todo, error) {
+	return createTodo(ctx, r.server.DB.Pool, userID, &orgID, payload, nil)
+}
+
+// ImportTodos creates the imported todos in categoryID in one transaction, so
+// a failed import leaves nothing behind to duplicate on retry. Completed and
+// cancelled todos keep their status and completion time.
+func (r *TodoRepository) ImportTodos(ctx context.Context, userID string, imported []todo.ImportedTodo,
+	categoryID *uuid.UUID,
+) ([]todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	todos := make([]todo.Todo, 0, len(imported))
+	for _, item := range imported {
+		created, err := createTodo(ctx, tx, userID, nil, item.CreatePayload(categoryID), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Status != todo.StatusActive {
+			args := pgx.NamedArgs{
+				"todo_id": created.ID,
+				"user_id": userID,
+			}
+			stmt := "UPDATE todos SET " + strings.Join(setStatusClausesAt(args, item.Status, item.CompletedAt), ", ") + `
+				WHERE
+					id = @todo_id
+					AND user_id = @user_id
+				RETURNING
+					*
+			`
+
+			rows, err := tx.Query(ctx, stmt, args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to execute import status query for todo_id=%s: %w", created.ID.String(), err)
+			}
+
+			updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+			if err != nil {
+				return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", created.ID.String(), err)
+			}
+			created = &updated
+		}
+
+		todos = append(todos, *created)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// CompleteWithFollowUp completes a todo and creates its follow-up in one
+// transaction, so a follow-up that fails to insert leaves the todo open.
+func (r *TodoRepository) CompleteWithFollowUp(ctx context.Context, userID string, todoID uuid.UUID,
+	payload *todo.CreateTodoPayload,
+) (*todo.Todo, *todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin complete with follow-up transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	}
+	stmt := "UPDATE todos SET " + strings.Join(setStatusClauses(args, todo.StatusCompleted), ", ") +
+		" WHERE id = @todo_id AND user_id = @user_id AND org_id IS NULL AND deleted_at IS NULL RETURNING *"
+
+	rows, err := tx.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute complete todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	completed, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
+	}
+
+	// The follow-up stays in the same organization as the todo it continues
+	followUp, err := createTodo(ctx, tx, userID, completed.OrgID, payload, &completed.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit complete with follow-up for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &completed, followUp, nil
+}
+
+// PromoteToTopLevel detaches a subtask from its parent. With keepChildren its
+// subtree moves up along with it; otherwise its children are handed to the
+// former parent so they stay where they were in the hierarchy.
+func (r *TodoRepository) PromoteToTopLevel(ctx context.Context, userID string, todoID uuid.UUID,
+	keepChildren bool,
+) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin promote transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	}
+
+	lockStmt := `
+		SELECT
+			parent_todo_id
+		FROM
+			todos
+		WHERE
+			id = @todo_id
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var formerParentID *uuid.UUID
+	if err := tx.QueryRow(ctx, lockStmt, args).Scan(&formerParentID); err != nil {
+		return nil, fmt.Errorf("failed to lock row from table:todos for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
+	}
+
+	if formerParentID == nil {
+		return nil, errs.NewBadRequestError("Todo is already a top-level todo", false, nil, nil, nil)
+	}
+
+	if !keepChildren {
+		args["former_parent_id"] = formerParentID
+
+		reparentStmt := `
+			UPDATE todos
+			SET
+				parent_todo_id = @former_parent_id
+			WHERE
+				parent_todo_id = @todo_id
+				AND user_id = @user_id
+		`
+
+		if _, err := tx.Exec(ctx, reparentStmt, args); err != nil {
+			return nil, fmt.Errorf("failed to reparent children of todo_id=%s: %w", todoID.String(), err)
+		}
+	}
+
+	promoteStmt := `
+		UPDATE todos
+		SET
+			parent_todo_id = NULL
+		WHERE
+			id = @todo_id
+			AND user_id = @user_id
+		RETURNING
+			*
+	`
+
+	rows, err := tx.Query(ctx, promoteStmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute promote todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	promoted, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit promote for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &promoted, nil
+}
+
+func createTodo(ctx context.Context, q querier, userID string, orgID *uuid.UUID, payload *todo.CreateTodoPayload,
+	followUpOf *uuid.UUID,
+) (*todo.Todo, error) {
 	stmt := `
 		INSERT INTO
 			todos (
 				user_id,
+				org_id,
 				title,
 				description,
 				priority,
 				due_date,
 				parent_todo_id,
 				category_id,
-				metadata
+				metadata,
+				all_day,
+				defer_until,
+				follow_up_of
 			)
 		VALUES
 			(
 				@user_id,
+				@org_id,
 				@title,
 				@description,
 				@priority,
 				@due_date,
 				@parent_todo_id,
 				@category_id,
-				@metadata
+				@metadata,
+				@all_day,
+				@defer_until,
+				@follow_up_of
 			)
 		RETURNING
 		*
@@ -55,8 +333,14 @@ func (r *TodoRepository) CreateTodo(ctx context.Context, userID string, payload
 		priority = *payload.Priority
 	}
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+	allDay := false
+	if payload.AllDay != nil {
+		allDay = *payload.AllDay
+	}
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
 		"user_id":        userID,
+		"org_id":         orgID,
 		"title":          payload.Title,
 		"description":    payload.Description,
 		"priority":       priority,
@@ -64,6 +348,9 @@ func (r *TodoRepository) CreateTodo(ctx context.Context, userID string, payload
 		"parent_todo_id": payload.ParentTodoID,
 		"category_id":    payload.CategoryID,
 		"metadata":       payload.Metadata,
+		"all_day":        allDay,
+		"defer_until":    payload.DeferUntil,
+		"follow_up_of":   followUpOf,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute create todo query for user_id=%s title=%s: %w", userID, payload.Title, err)
@@ -78,6 +365,15 @@ func (r *TodoRepository) CreateTodo(ctx context.Context, userID string, payload
 }
 
 func (r *TodoRepository) GetTodoByID(ctx context.Context, userID string, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
+	return r.getTodoByID(ctx, personalScope(userID), todoID)
+}
+
+// GetOrgTodoByID returns a todo belonging to the organization
+func (r *TodoRepository) GetOrgTodoByID(ctx context.Context, orgID uuid.UUID, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
+	return r.getTodoByID(ctx, orgScope(orgID), todoID)
+}
+
+func (r *TodoRepository) getTodoByID(ctx context.Context, scope todoScope, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
 	stmt := `
 	SELECT
 		t.*,
@@ -86,17 +382,54 @@ func (r *TodoRepository) GetTodoByID(ctx context.Context, userID string, todoID
 			ELSE NULL
 		END AS category,
 		COALESCE(
-			jsonb_agg(
-				to_jsonb(camel (child))
-				ORDER BY
-					child.sort_order ASC,
-					child.created_at ASC
-			) FILTER (
-				WHERE
-					child.id IS NOT NULL
+			(
+				SELECT
+					jsonb_agg(
+						w.child
+						ORDER BY
+							w.sort_order ASC,
+							w.created_at ASC,
+							w.id ASC
+					)
+				FROM
+					(
+						SELECT
+							to_jsonb(camel (child)) AS child,
+							child.sort_order,
+							child.created_at,
+							child.id
+						FROM
+							todos child
+						WHERE
+							child.parent_todo_id=t.id
+							AND child.deleted_at IS NULL
+							AND (
+								child.user_id=t.user_id
+								OR child.org_id=t.org_id
+							)
+						ORDER BY
+							child.sort_order ASC,
+							child.created_at ASC,
+							child.id ASC
+						LIMIT
+							@children_limit
+					) w
 			),
 			'[]'::JSONB
 		) AS children,
+		(
+			SELECT
+				COUNT(*)
+			FROM
+				todos child
+			WHERE
+				child.parent_todo_id=t.id
+				AND child.deleted_at IS NULL
+				AND (
+					child.user_id=t.user_id
+					OR child.org_id=t.org_id
+				)
+		) AS child_count,
 		COALESCE(
 			jsonb_agg(
 				to_jsonb(camel (com))
@@ -122,184 +455,394 @@ func (r *TodoRepository) GetTodoByID(ctx context.Context, userID string, todoID
 	FROM
 		todos t
 		LEFT JOIN todo_categories c ON c.id=t.category_id
-		AND c.user_id=@user_id
-		LEFT JOIN todos child ON child.parent_todo_id=t.id
-		AND child.user_id=@user_id
+		AND c.user_id=t.user_id
 		LEFT JOIN todo_comments com ON com.todo_id=t.id
-		AND com.user_id=@user_id
+		AND com.user_id=t.user_id
 		LEFT JOIN todo_attachments att ON att.todo_id=t.id
 	WHERE
 		t.id=@id
-		AND t.user_id=@user_id
+		AND ` + scope.condition + `
 	GROUP BY
 		t.id,
 		c.id
 `
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"id":      todoID,
-		"user_id": userID,
-	})
+	args := pgx.NamedArgs{
+		"id":             todoID,
+		"children_limit": r.server.Config.Todo.GetInlineChildrenLimit(),
+	}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get todo by id query for todo_id=%s user_id=%s: %w", todoID.String(), userID, err)
+		return nil, fmt.Errorf("failed to execute get todo by id query for todo_id=%s %s: %w", todoID.String(), scope.owner, err)
 	}
 
 	todoItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.PopulatedTodo])
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w", todoID.String(), userID, err)
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s %s: %w", todoID.String(), scope.owner, err)
 	}
 
 	return &todoItem, nil
 }
 
-func (r *TodoRepository) CheckTodoExists(ctx context.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
-	stmt := `
+// GetSharedTodo returns the todo behind a live share link
+func (r *TodoRepository) GetSharedTodo(ctx context.Context, linkID uuid.UUID, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
+	return r.getTodoByID(ctx, sharedScope(linkID), todoID)
+}
+
+// CreateShareLink revokes the todo's current share link, if any, and records
+// a new one in its place
+func (r *TodoRepository) CreateShareLink(ctx context.Context, userID string, todoID uuid.UUID) (*todo.ShareLink, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin share link transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	}
+
+	revokeStmt := `
+		UPDATE todo_share_links
+		SET
+			revoked_at = CURRENT_TIMESTAMP
+		WHERE
+			todo_id = @todo_id
+			AND user_id = @user_id
+			AND revoked_at IS NULL
+	`
+
+	if _, err := tx.Exec(ctx, revokeStmt, args); err != nil {
+		return nil, fmt.Errorf("failed to revoke previous share link for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	insertStmt := `
+		INSERT INTO
+			todo_share_links (todo_id, user_id)
 		SELECT
-			*
+			id,
+			user_id
 		FROM
 			todos
 		WHERE
-			id=@id
-			AND user_id=@user_id
+			id = @todo_id
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		RETURNING
+			*
 	`
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"id":      todoID,
-		"user_id": userID,
-	})
+	rows, err := tx.Query(ctx, insertStmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if todo exists for todo_id=%s user_id=%s: %w", todoID.String(), userID, err)
+		return nil, fmt.Errorf("failed to execute create share link query for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
 	}
 
-	todoItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	link, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.ShareLink])
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w", todoID.String(), userID, err)
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
 	}
 
-	return &todoItem, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit share link for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &link, nil
 }
 
-func (r *TodoRepository) GetTodos(ctx context.Context, userID string, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+// RevokeShareLinks disables the todo's share link and reports how many links
+// were revoked
+func (r *TodoRepository) RevokeShareLinks(ctx context.Context, userID string, todoID uuid.UUID) (int, error) {
 	stmt := `
-	SELECT
-		t.*,
-		CASE
-			WHEN c.id IS NOT NULL THEN to_jsonb(camel (c))
-			ELSE NULL
-		END AS category,
-		COALESCE(
-			jsonb_agg(
-				to_jsonb(camel (child))
-				ORDER BY
-					child.sort_order ASC,
-					child.created_at ASC
-			) FILTER (
-				WHERE
-					child.id IS NOT NULL
-			),
-			'[]'::JSONB
-		) AS children,
-		COALESCE(
-			jsonb_agg(
-				to_jsonb(camel (com))
-				ORDER BY
-					com.created_at ASC
-			) FILTER (
-				WHERE
-					com.id IS NOT NULL
-			),
-			'[]'::JSONB
-		) AS comments,
-		COALESCE(
-				jsonb_agg(
-					to_jsonb(camel (att))
-					ORDER BY
-						att.created_at DESC
-				) FILTER (
-					WHERE
-						att.id IS NOT NULL
-				),
-				'[]'::JSONB
-			) AS attachments
-	FROM
-		todos t
-		LEFT JOIN todo_categories c ON c.id=t.category_id
-		AND c.user_id=@user_id
-		LEFT JOIN todos child ON child.parent_todo_id=t.id
-		AND child.user_id=@user_id
-		LEFT JOIN todo_comments com ON com.todo_id=t.id
-		AND com.user_id=@user_id
-		LEFT JOIN todo_attachments att ON att.todo_id=t.id
-`
+		UPDATE todo_share_links
+		SET
+			revoked_at = CURRENT_TIMESTAMP
+		WHERE
+			todo_id = @todo_id
+			AND user_id = @user_id
+			AND revoked_at IS NULL
+	`
 
-	args := pgx.NamedArgs{
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
 		"user_id": userID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke share links for todo_id=%s user_id=%s: %w", todoID.String(), userID, err)
 	}
-	conditions := []string{"t.user_id = @user_id"}
 
-	if query.Status != nil {
-		conditions = append(conditions, "t.status = @status")
-		args["status"] = *query.Status
-	}
+	return int(result.RowsAffected()), nil
+}
 
-	if query.Priority != nil {
-		conditions = append(conditions, "t.priority = @priority")
-		args["priority"] = *query.Priority
+// CreateFeedLink revokes the user's current feed link, if any, and records a
+// new one in its place
+func (r *TodoRepository) CreateFeedLink(ctx context.Context, userID string) (*todo.FeedLink, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin feed link transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	if query.CategoryID != nil {
-		conditions = append(conditions, "t.category_id = @category_id")
-		args["category_id"] = *query.CategoryID
+	args := pgx.NamedArgs{
+		"user_id": userID,
 	}
 
-	if query.ParentTodoID != nil {
-		conditions = append(conditions, "t.parent_todo_id = @parent_todo_id")
-		args["parent_todo_id"] = *query.ParentTodoID
-	} else {
-		// By default, only show root todos (no parent)
-		conditions = append(conditions, "t.parent_todo_id IS NULL")
-	}
+	revokeStmt := `
+		UPDATE todo_feed_links
+		SET
+			revoked_at = CURRENT_TIMESTAMP
+		WHERE
+			user_id = @user_id
+			AND revoked_at IS NULL
+	`
 
-	if query.DueFrom != nil {
-		conditions = append(conditions, "t.due_date >= @due_from")
-		args["due_from"] = *query.DueFrom
+	if _, err := tx.Exec(ctx, revokeStmt, args); err != nil {
+		return nil, fmt.Errorf("failed to revoke previous feed link for user_id=%s: %w", userID, err)
 	}
 
-	if query.DueTo != nil {
-		conditions = append(conditions, "t.due_date <= @due_to")
-		args["due_to"] = *query.DueTo
+	insertStmt := `
+		INSERT INTO
+			todo_feed_links (user_id)
+		VALUES
+			(@user_id)
+		RETURNING
+			*
+	`
+
+	rows, err := tx.Query(ctx, insertStmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create feed link query for user_id=%s: %w", userID, err)
 	}
 
-	if query.Overdue != nil && *query.Overdue {
-		conditions = append(conditions, "t.due_date < NOW() AND t.status != 'completed'")
+	link, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.FeedLink])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todo_feed_links for user_id=%s: %w", userID, err)
 	}
 
-	if query.Completed != nil {
-		if *query.Completed {
-			conditions = append(conditions, "t.status = 'completed'")
-		} else {
-			conditions = append(conditions, "t.status != 'completed'")
-		}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit feed link for user_id=%s: %w", userID, err)
 	}
 
-	if query.Search != nil {
-		conditions = append(conditions, "(t.title ILIKE @search OR t.description ILIKE @search)")
-		args["search"] = "%" + *query.Search + "%"
+	return &link, nil
+}
+
+// RevokeFeedLinks disables the user's feed link and reports how many links
+// were revoked
+func (r *TodoRepository) RevokeFeedLinks(ctx context.Context, userID string) (int, error) {
+	stmt := `
+		UPDATE todo_feed_links
+		SET
+			revoked_at = CURRENT_TIMESTAMP
+		WHERE
+			user_id = @user_id
+			AND revoked_at IS NULL
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke feed links for user_id=%s: %w", userID, err)
 	}
 
-	if len(conditions) > 0 {
-		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	return int(result.RowsAffected()), nil
+}
+
+// GetFeedLink returns the user's feed link, or nil once it has been revoked
+// or replaced
+func (r *TodoRepository) GetFeedLink(ctx context.Context, userID string, linkID uuid.UUID) (*todo.FeedLink, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_feed_links
+		WHERE
+			id = @link_id
+			AND user_id = @user_id
+			AND revoked_at IS NULL
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"link_id": linkID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get feed link query for link_id=%s user_id=%s: %w",
+			linkID.String(), userID, err)
 	}
 
-	countStmt := "SELECT COUNT(*) FROM todos t"
-	if len(conditions) > 0 {
-		countStmt += " WHERE " + strings.Join(conditions, " AND ")
+	link, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.FeedLink])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_feed_links for link_id=%s user_id=%s: %w",
+			linkID.String(), userID, err)
 	}
 
-	var total int
-	err := r.server.DB.Pool.QueryRow(ctx, countStmt, args).Scan(&total)
+	return &link, nil
+}
+
+// CheckTodoExists returns one of the user's personal todos. Todos they
+// created in an organization are only reachable through CheckOrgTodoExists,
+// so leaving the organization cuts them off.
+func (r *TodoRepository) CheckTodoExists(ctx context.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
+	return r.checkTodoExists(ctx, personalScope(userID), todoID)
+}
+
+// CheckOrgTodoExists returns an organization todo, whoever created it
+func (r *TodoRepository) CheckOrgTodoExists(ctx context.Context, orgID uuid.UUID, todoID uuid.UUID) (*todo.Todo, error) {
+	return r.checkTodoExists(ctx, orgScope(orgID), todoID)
+}
+
+func (r *TodoRepository) checkTodoExists(ctx context.Context, scope todoScope, todoID uuid.UUID) (*todo.Todo, error) {
+	stmt := `
+		SELECT
+			t.*
+		FROM
+			todos t
+		WHERE
+			t.id=@id
+			AND ` + scope.condition
+
+	args := pgx.NamedArgs{"id": todoID}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if todo exists for todo_id=%s %s: %w", todoID.String(), scope.owner, err)
+	}
+
+	todoItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s %s: %w", todoID.String(), scope.owner, err)
+	}
+
+	return &todoItem, nil
+}
+
+func (r *TodoRepository) GetTodos(ctx context.Context, userID string, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	return r.getTodos(ctx, r.server.DB.Pool, personalScope(userID), query)
+}
+
+// GetOrgTodos lists the todos shared within the organization
+func (r *TodoRepository) GetOrgTodos(ctx context.Context, orgID uuid.UUID, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	return r.getTodos(ctx, r.server.DB.Pool, orgScope(orgID), query)
+}
+
+// GetTodosInSnapshot lists the user's todos as they were when the exported
+// snapshot was taken, so every page of one iteration agrees on the total and
+// on which todos come where
+func (r *TodoRepository) GetTodosInSnapshot(ctx context.Context, userID string, snapshotID string,
+	query *todo.GetTodosQuery,
+) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	tx, err := r.server.DB.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot read transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// SET TRANSACTION SNAPSHOT takes no parameters; the ID comes from
+	// pg_export_snapshot, not the client
+	if _, err := tx.Exec(ctx, "SET TRANSACTION SNAPSHOT "+quoteLiteral(snapshotID)); err != nil {
+		// The exporting transaction ended between lookup and import
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "22023" { // invalid_parameter_value
+			code := errs.CodeSnapshotExpired
+			return nil, errs.NewNotFoundError("Snapshot has expired, open a new one", true, &code)
+		}
+		return nil, fmt.Errorf("failed to import snapshot for user_id=%s: %w", userID, err)
+	}
+
+	return r.getTodos(ctx, tx, personalScope(userID), query)
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (r *TodoRepository) getTodos(ctx context.Context, q querier, scope todoScope,
+	query *todo.GetTodosQuery,
+) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	stmt := `
+	SELECT
+		t.*,
+		CASE
+			WHEN c.id IS NOT NULL THEN to_jsonb(camel (c))
+			ELSE NULL
+		END AS category,
+		COALESCE(
+			jsonb_agg(
+				to_jsonb(camel (child))
+				ORDER BY
+					child.sort_order ASC,
+					child.created_at ASC
+			) FILTER (
+				WHERE
+					child.id IS NOT NULL
+			),
+			'[]'::JSONB
+		) AS children,
+		COUNT(DISTINCT child.id) AS child_count,
+		COALESCE(
+			jsonb_agg(
+				to_jsonb(camel (com))
+				ORDER BY
+					com.created_at ASC
+			) FILTER (
+				WHERE
+					com.id IS NOT NULL
+			),
+			'[]'::JSONB
+		) AS comments,
+		COALESCE(
+				jsonb_agg(
+					to_jsonb(camel (att))
+					ORDER BY
+						att.created_at DESC
+				) FILTER (
+					WHERE
+						att.id IS NOT NULL
+				),
+				'[]'::JSONB
+			) AS attachments
+	FROM
+		todos t
+		LEFT JOIN todo_categories c ON c.id=t.category_id
+		AND c.user_id=t.user_id
+		LEFT JOIN todos child ON child.parent_todo_id=t.id
+		AND child.deleted_at IS NULL
+		AND (
+			child.user_id=t.user_id
+			OR child.org_id=t.org_id
+		)
+		LEFT JOIN todo_comments com ON com.todo_id=t.id
+		AND com.user_id=t.user_id
+		LEFT JOIN todo_attachments att ON att.todo_id=t.id
+`
+
+	where, args := todoFilterClause(scope, query)
+	stmt += where
+
+	if query.IsCursorMode() {
+		return r.getTodosAfterCursor(ctx, q, scope, query, stmt, args)
+	}
+
+	total, err := r.countTodos(ctx, q, scope, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total count for todos user_id=%s: %w", userID, err)
+		return nil, err
 	}
 
 	stmt += " GROUP BY t.id, c.id"
@@ -319,12 +862,12 @@ func (r *TodoRepository) GetTodos(ctx context.Context, userID string, query *tod
 	args["limit"] = *query.Limit
 	args["offset"] = (*query.Page - 1) * (*query.Limit)
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	rows, err := q.Query(ctx, stmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get todos query for user_id=%s: %w", userID, err)
+		return nil, fmt.Errorf("failed to execute get todos query for %s: %w", scope.owner, err)
 	}
 
-	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.PopulatedTodo])
+	collected, err := pgx.CollectRows(rows, pgx.RowToStructByName[populatedTodoRow])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return &model.PaginatedResponse[todo.PopulatedTodo]{
@@ -335,7 +878,12 @@ func (r *TodoRepository) GetTodos(ctx context.Context, userID string, query *tod
 				TotalPages: 0,
 			}, nil
 		}
-		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+		return nil, fmt.Errorf("failed to collect rows from table:todos for %s: %w", scope.owner, err)
+	}
+
+	todos, skipped, err := r.hydrateTodoRows(scope, collected)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.PaginatedResponse[todo.PopulatedTodo]{
@@ -344,434 +892,3448 @@ func (r *TodoRepository) GetTodos(ctx context.Context, userID string, query *tod
 		Limit:      *query.Limit,
 		Total:      total,
 		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+		Skipped:    skipped,
 	}, nil
 }
 
-func (r *TodoRepository) UpdateTodo(ctx context.Context, userID string, payload *todo.UpdateTodoPayload) (*todo.Todo, error) {
-	stmt := "UPDATE todos SET "
-	args := pgx.NamedArgs{
-		"todo_id": payload.ID,
-		"user_id": userID,
+// getTodosAfterCursor finishes a list statement as a keyset page: the todos
+// created after the cursor in the requested direction, with id breaking ties.
+// One extra row is read to tell whether another page follows. The list isn't
+// counted, which is what keeps deep pages cheap.
+func (r *TodoRepository) getTodosAfterCursor(ctx context.Context, q querier, scope todoScope,
+	query *todo.GetTodosQuery, stmt string, args pgx.NamedArgs,
+) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	after, err := query.After()
+	if err != nil {
+		return nil, errs.NewBadRequestError("Invalid cursor", true, nil,
+			[]errs.FieldError{{Field: "cursor", Error: "is not a valid cursor"}}, nil)
 	}
-	setClauses := []string{}
 
-	if payload.Title != nil {
-		setClauses = append(setClauses, "title = @title")
-		args["title"] = *payload.Title
+	direction, comparison := "DESC", "<"
+	if query.Order != nil && *query.Order == "asc" {
+		direction, comparison = "ASC", ">"
 	}
 
-	if payload.Description != nil {
-		setClauses = append(setClauses, "description = @description")
-		args["description"] = *payload.Description
+	if after != nil {
+		stmt += " AND (t.created_at, t.id) " + comparison + " (@cursor_created_at, @cursor_id)"
+		args["cursor_created_at"] = after.CreatedAt
+		args["cursor_id"] = after.ID
 	}
 
-	if payload.Status != nil {
-		setClauses = append(setClauses, "status = @status")
-		args["status"] = *payload.Status
+	stmt += " GROUP BY t.id, c.id"
+	stmt += " ORDER BY t.created_at " + direction + ", t.id " + direction
+	stmt += " LIMIT @limit"
+	args["limit"] = *query.Limit + 1
 
-		// Auto-set completed_at when status changes to completed
-		if *payload.Status == todo.StatusCompleted {
-			setClauses = append(setClauses, "completed_at = @completed_at")
-			args["completed_at"] = time.Now()
-		} else if *payload.Status != todo.StatusCompleted {
-			setClauses = append(setClauses, "completed_at = NULL")
-		}
+	rows, err := q.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todos after cursor query for %s: %w", scope.owner, err)
 	}
 
-	if payload.Priority != nil {
-		setClauses = append(setClauses, "priority = @priority")
-		args["priority"] = *payload.Priority
+	collected, err := pgx.CollectRows(rows, pgx.RowToStructByName[populatedTodoRow])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for %s: %w", scope.owner, err)
 	}
 
-	if payload.DueDate != nil {
-		setClauses = append(setClauses, "due_date = @due_date")
-		args["due_date"] = *payload.DueDate
+	var nextCursor *string
+	if len(collected) > *query.Limit {
+		collected = collected[:*query.Limit]
+		last := collected[len(collected)-1]
+		next := todo.ListCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		nextCursor = &next
 	}
 
-	if payload.ParentTodoID != nil {
-		setClauses = append(setClauses, "parent_todo_id = @parent_todo_id")
-		args["parent_todo_id"] = *payload.ParentTodoID
+	todos, skipped, err := r.hydrateTodoRows(scope, collected)
+	if err != nil {
+		return nil, err
 	}
 
-	if payload.CategoryID != nil {
-		setClauses = append(setClauses, "category_id = @category_id")
-		args["category_id"] = *payload.CategoryID
-	}
+	return &model.PaginatedResponse[todo.PopulatedTodo]{
+		Data:       todos,
+		Limit:      *query.Limit,
+		Skipped:    skipped,
+		NextCursor: nextCursor,
+	}, nil
+}
 
-	if payload.Metadata != nil {
-		setClauses = append(setClauses, "metadata = @metadata")
-		args["metadata"] = payload.Metadata
-	}
+// hydrateTodoRows decodes the listed rows, skipping the ones that can't be
+// decoded unless strict list hydration is configured
+func (r *TodoRepository) hydrateTodoRows(scope todoScope, collected []populatedTodoRow,
+) ([]todo.PopulatedTodo, int, error) {
+	strict := r.server.Config.Todo.IsStrictListHydration()
+	todos := make([]todo.PopulatedTodo, 0, len(collected))
+	skipped := 0
 
-	if len(setClauses) == 0 {
-		return nil, errs.NewBadRequestError("no fields to update", false, nil, nil, nil)
+	for i := range collected {
+		populated, err := collected[i].hydrate()
+		if err != nil {
+			if strict {
+				return nil, 0, fmt.Errorf("failed to hydrate todo_id=%s for %s: %w",
+					collected[i].ID.String(), scope.owner, err)
+			}
+
+			r.server.Logger.Warn().Err(err).
+				Str("todo_id", collected[i].ID.String()).
+				Msg("skipping todo that could not be hydrated")
+			skipped++
+			continue
+		}
+		todos = append(todos, populated)
 	}
 
-	stmt += strings.Join(setClauses, ", ")
-	stmt += " WHERE id = @todo_id AND user_id = @user_id RETURNING *"
+	return todos, skipped, nil
+}
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+// populatedTodoRow is a listed todo with its aggregated JSONB columns left
+// undecoded. A decode error while scanning would abort the whole result set,
+// so they are decoded per row afterwards and a malformed row can be dropped
+// on its own.
+type populatedTodoRow struct {
+	todo.Todo
+	Category    json.RawMessage `db:"category"`
+	Children    json.RawMessage `db:"children"`
+	ChildCount  int             `db:"child_count"`
+	Comments    json.RawMessage `db:"comments"`
+	Attachments json.RawMessage `db:"attachments"`
+}
+
+func (row *populatedTodoRow) hydrate() (todo.PopulatedTodo, error) {
+	populated := todo.PopulatedTodo{
+		Todo:       row.Todo,
+		ChildCount: row.ChildCount,
 	}
 
-	updatedTodo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:todos: %w", err)
+	columns := []struct {
+		name string
+		raw  json.RawMessage
+		dest any
+	}{
+		{"category", row.Category, &populated.Category},
+		{"children", row.Children, &populated.Children},
+		{"comments", row.Comments, &populated.Comments},
+		{"attachments", row.Attachments, &populated.Attachments},
 	}
 
-	return &updatedTodo, nil
+	for _, column := range columns {
+		if len(column.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(column.raw, column.dest); err != nil {
+			return todo.PopulatedTodo{}, fmt.Errorf("failed to decode %s: %w", column.name, err)
+		}
+	}
+
+	return populated, nil
 }
 
-func (r *TodoRepository) DeleteTodo(ctx context.Context, userID string, todoID uuid.UUID) error {
-	stmt := `
-		DELETE FROM todos
-		WHERE
-			id=@todo_id
-			AND user_id=@user_id
-	`
+// CountTodos counts the todos matching the same filters GetTodos applies,
+// ignoring pagination
+func (r *TodoRepository) CountTodos(ctx context.Context, userID string, query *todo.GetTodosQuery) (int, error) {
+	return r.countTodos(ctx, r.server.DB.Pool, personalScope(userID), query)
+}
 
-	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
-		"todo_id": todoID,
-		"user_id": userID,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to execute query: %w", err)
-	}
+// CountOrgTodos counts the organization's todos matching the filters
+func (r *TodoRepository) CountOrgTodos(ctx context.Context, orgID uuid.UUID, query *todo.GetTodosQuery) (int, error) {
+	return r.countTodos(ctx, r.server.DB.Pool, orgScope(orgID), query)
+}
 
-	if result.RowsAffected() == 0 {
-		code := "TODO_NOT_FOUND"
-		return errs.NewNotFoundError("todo not found", false, &code)
+func (r *TodoRepository) countTodos(ctx context.Context, q querier, scope todoScope,
+	query *todo.GetTodosQuery,
+) (int, error) {
+	where, args := todoFilterClause(scope, query)
+
+	var total int
+	err := q.QueryRow(ctx, "SELECT COUNT(*) FROM todos t"+where, args).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get total count for todos %s: %w", scope.owner, err)
 	}
 
-	return nil
+	return total, nil
 }
 
-func (r *TodoRepository) GetTodoStats(ctx context.Context, userID string) (*todo.TodoStats, error) {
-	stmt := `
-		SELECT
-			COUNT(*) AS total,
-			COUNT(
-				CASE
-					WHEN status='draft' THEN 1
-				END
-			) AS draft,
-			COUNT(
-				CASE
-					WHEN status='active' THEN 1
-				END
-			) AS active,
-			COUNT(
-				CASE
-					WHEN status='completed' THEN 1
-				END
-			) AS completed,
-			COUNT(
-				CASE
-					WHEN status='archived' THEN 1
-				END
-			) AS archived,
-			COUNT(
-				CASE
-					WHEN due_date<NOW()
-					AND status!='completed' THEN 1
-				END
-			) AS overdue
-		FROM
-			todos
-		WHERE
-			user_id=@user_id
-	`
+// GetMatchingTodoIDs returns the IDs of the user's todos matching the list
+// filters, ignoring pagination, oldest first and at most limit of them
+func (r *TodoRepository) GetMatchingTodoIDs(ctx context.Context, userID string, query *todo.GetTodosQuery,
+	limit int,
+) ([]uuid.UUID, error) {
+	scope := personalScope(userID)
+	where, args := todoFilterClause(scope, query)
+	args["limit"] = limit
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"user_id": userID,
-	})
+	rows, err := r.server.DB.Pool.Query(ctx,
+		"SELECT t.id FROM todos t"+where+" ORDER BY t.created_at ASC, t.id ASC LIMIT @limit", args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, fmt.Errorf("failed to execute matching todo ids query for %s: %w", scope.owner, err)
 	}
 
-	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoStats])
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:todos: %w", err)
+		return nil, fmt.Errorf("failed to collect rows from table:todos for %s: %w", scope.owner, err)
 	}
 
-	return &stats, nil
+	return ids, nil
 }
 
-func (r *TodoRepository) GetTodoAttachment(
-	ctx context.Context,
-	todoID uuid.UUID,
-	attachmentID uuid.UUID,
-) (*todo.TodoAttachment, error) {
-	stmt := `
-		SELECT
+// todoFilterClause builds the WHERE clause shared by the list, count and
+// filtered stats queries so the three can never disagree on which todos match
+func todoFilterClause(scope todoScope, query *todo.GetTodosQuery) (string, pgx.NamedArgs) {
+	conditions, args := buildTodoFilters(query)
+	conditions = append([]string{scope.condition}, conditions...)
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// buildTodoFilters turns the list filters into conditions on the todos
+// table, aliased as t
+func buildTodoFilters(query *todo.GetTodosQuery) ([]string, pgx.NamedArgs) {
+	var conditions []string
+	args := pgx.NamedArgs{}
+
+	if query.Status != nil {
+		conditions = append(conditions, "t.status = @status")
+		args["status"] = *query.Status
+	}
+
+	if query.Priority != nil {
+		conditions = append(conditions, "t.priority = @priority")
+		args["priority"] = *query.Priority
+	}
+
+	if query.CategoryID != nil {
+		conditions = append(conditions, "t.category_id = @category_id")
+		args["category_id"] = *query.CategoryID
+	}
+
+	// Any of the listed categories, optionally or'd with no category at all
+	var categorySet []string
+	if len(query.CategoryIDs) > 0 {
+		categorySet = append(categorySet, "t.category_id = ANY(@category_ids::uuid[])")
+		args["category_ids"] = query.CategoryIDs
+	}
+	if query.IncludeUncategorized != nil && *query.IncludeUncategorized {
+		categorySet = append(categorySet, "t.category_id IS NULL")
+	}
+	if len(categorySet) > 0 {
+		conditions = append(conditions, "("+strings.Join(categorySet, " OR ")+")")
+	}
+
+	if tags := todo.NormalizeTags(query.Tags); len(tags) > 0 {
+		tagged := `(
+			SELECT COUNT(*) FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.todo_id = t.id AND LOWER(tg.name) = ANY(@tags::text[])
+		)`
+		if query.TagMatch != nil && *query.TagMatch == "all" {
+			conditions = append(conditions, tagged+" = cardinality(@tags::text[])")
+		} else {
+			conditions = append(conditions, tagged+" > 0")
+		}
+		for i, tag := range tags {
+			tags[i] = strings.ToLower(tag)
+		}
+		args["tags"] = tags
+	}
+
+	if query.ParentTodoID != nil {
+		conditions = append(conditions, "t.parent_todo_id = @parent_todo_id")
+		args["parent_todo_id"] = *query.ParentTodoID
+	} else {
+		// By default, only show root todos (no parent)
+		conditions = append(conditions, "t.parent_todo_id IS NULL")
+	}
+
+	if query.DueFrom != nil {
+		conditions = append(conditions, "t.due_date >= @due_from")
+		args["due_from"] = *query.DueFrom
+	}
+
+	if query.DueTo != nil {
+		conditions = append(conditions, "t.due_date <= @due_to")
+		args["due_to"] = *query.DueTo
+	}
+
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "t.created_at >= @created_from")
+		args["created_from"] = *query.CreatedFrom
+	}
+
+	if query.CreatedTo != nil {
+		conditions = append(conditions, "t.created_at <= @created_to")
+		args["created_to"] = *query.CreatedTo
+	}
+
+	if query.Overdue != nil && *query.Overdue {
+		conditions = append(conditions, "todo_due_passed(t.due_date, t.all_day, user_timezone(t.user_id), user_day_start_hour(t.user_id)) AND t.status != 'completed'")
+	}
+
+	if query.Completed != nil {
+		if *query.Completed {
+			conditions = append(conditions, "t.status = 'completed'")
+		} else {
+			conditions = append(conditions, "t.status != 'completed'")
+		}
+	}
+
+	if query.CompletedFrom != nil {
+		conditions = append(conditions, "t.completed_at >= @completed_from")
+		args["completed_from"] = *query.CompletedFrom
+	}
+
+	if query.CompletedTo != nil {
+		conditions = append(conditions, "t.completed_at <= @completed_to")
+		args["completed_to"] = *query.CompletedTo
+	}
+
+	if query.Open != nil {
+		if *query.Open {
+			conditions = append(conditions, "t.status NOT IN ('completed', 'archived')")
+		} else {
+			conditions = append(conditions, "t.status IN ('completed', 'archived')")
+		}
+	}
+
+	if query.HasDueDate != nil {
+		if *query.HasDueDate {
+			conditions = append(conditions, "t.due_date IS NOT NULL")
+		} else {
+			conditions = append(conditions, "t.due_date IS NULL")
+		}
+	}
+
+	if query.HasSubtasks != nil {
+		subtasks := "EXISTS (SELECT 1 FROM todos sub WHERE sub.parent_todo_id = t.id AND sub.deleted_at IS NULL)"
+		if *query.HasSubtasks {
+			conditions = append(conditions, subtasks)
+		} else {
+			conditions = append(conditions, "NOT "+subtasks)
+		}
+	}
+
+	if query.ExcludeBlocked != nil && *query.ExcludeBlocked {
+		conditions = append(conditions, "NOT "+blockedCondition)
+	}
+
+	// Deferred todos stay hidden until their start date unless asked for
+	if query.IncludeDeferred == nil || !*query.IncludeDeferred {
+		conditions = append(conditions, "(t.defer_until IS NULL OR t.defer_until <= NOW())")
+	}
+
+	if query.Search != nil {
+		// Transcripts of audio attachments are searched along with the todo's own text
+		conditions = append(conditions, `(
+			t.title ILIKE @search
+			OR t.description ILIKE @search
+			OR EXISTS (
+				SELECT
+					1
+				FROM
+					todo_attachments search_att
+				WHERE
+					search_att.todo_id = t.id
+					AND search_att.transcript ILIKE @search
+			)
+		)`)
+		args["search"] = "%" + *query.Search + "%"
+	}
+
+	return conditions, args
+}
+
+// GetChildren pages through every subtask of the parent todo in the same
+// order the parent inlines them
+func (r *TodoRepository) GetChildren(ctx context.Context, userID string, parentID uuid.UUID,
+	query *todo.GetChildrenQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	return r.getChildren(ctx, personalScope(userID), parentID, query)
+}
+
+// GetOrgChildren pages through the subtasks of an organization todo
+func (r *TodoRepository) GetOrgChildren(ctx context.Context, orgID uuid.UUID, parentID uuid.UUID,
+	query *todo.GetChildrenQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	return r.getChildren(ctx, orgScope(orgID), parentID, query)
+}
+
+func (r *TodoRepository) getChildren(ctx context.Context, scope todoScope, parentID uuid.UUID,
+	query *todo.GetChildrenQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	args := pgx.NamedArgs{"parent_id": parentID}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	// Grouping by the parent yields no row when the parent is not visible in
+	// this scope, which surfaces as a not found
+	countStmt := `
+		SELECT
+			COUNT(child.id)
+		FROM
+			todos t
+			LEFT JOIN todos child ON child.parent_todo_id=t.id
+			AND child.deleted_at IS NULL
+			AND (
+				child.user_id=t.user_id
+				OR child.org_id=t.org_id
+			)
+		WHERE
+			t.id=@parent_id
+			AND ` + scope.condition + `
+		GROUP BY
+			t.id
+	`
+
+	var total int
+	err := r.server.DB.Pool.QueryRow(ctx, countStmt, args).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count children from table:todos for todo_id=%s %s: %w", parentID.String(), scope.owner, err)
+	}
+
+	stmt := `
+		SELECT
+			child.*
+		FROM
+			todos t
+			JOIN todos child ON child.parent_todo_id=t.id
+			AND child.deleted_at IS NULL
+			AND (
+				child.user_id=t.user_id
+				OR child.org_id=t.org_id
+			)
+		WHERE
+			t.id=@parent_id
+			AND ` + scope.condition + `
+		ORDER BY
+			child.sort_order ASC,
+			child.created_at ASC,
+			child.id ASC
+		LIMIT
+			@limit
+		OFFSET
+			@offset
+	`
+	args["limit"] = *query.Limit
+	args["offset"] = (*query.Page - 1) * (*query.Limit)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get children query for todo_id=%s %s: %w", parentID.String(), scope.owner, err)
+	}
+
+	children, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for todo_id=%s %s: %w", parentID.String(), scope.owner, err)
+	}
+
+	return &model.PaginatedResponse[todo.Todo]{
+		Data:       children,
+		Page:       *query.Page,
+		Limit:      *query.Limit,
+		Total:      total,
+		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+	}, nil
+}
+
+// CountChildren counts the direct subtasks of the parent todo, leaving out
+// the todos in excludeIDs
+func (r *TodoRepository) CountChildren(ctx context.Context, parentID uuid.UUID, excludeIDs []uuid.UUID) (int, error) {
+	return countChildren(ctx, r.server.DB.Pool, parentID, excludeIDs)
+}
+
+func countChildren(ctx context.Context, q querier, parentID uuid.UUID, excludeIDs []uuid.UUID) (int, error) {
+	if excludeIDs == nil {
+		excludeIDs = []uuid.UUID{}
+	}
+
+	stmt := `
+		SELECT
+			COUNT(*)
+		FROM
+			todos
+		WHERE
+			parent_todo_id=@parent_id
+			AND deleted_at IS NULL
+			AND NOT id = ANY(@exclude_ids::uuid[])
+	`
+
+	var count int
+	err := q.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"parent_id":   parentID,
+		"exclude_ids": excludeIDs,
+	}).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count children from table:todos for todo_id=%s: %w", parentID.String(), err)
+	}
+
+	return count, nil
+}
+
+// GetTodoSubtree returns every descendant of the todo, shallowest first and
+// in sort order within each parent
+func (r *TodoRepository) GetTodoSubtree(ctx context.Context, userID string, todoID uuid.UUID) ([]todo.Todo, error) {
+	return getTodoSubtree(ctx, r.server.DB.Pool, userID, todoID)
+}
+
+func getTodoSubtree(ctx context.Context, q querier, userID string, todoID uuid.UUID) ([]todo.Todo, error) {
+	stmt := `
+		WITH RECURSIVE
+			subtree AS (
+				SELECT
+					t.*,
+					1 AS depth
+				FROM
+					todos t
+				WHERE
+					t.parent_todo_id=@todo_id
+					AND t.user_id=@user_id
+					AND t.deleted_at IS NULL
+				UNION ALL
+				SELECT
+					c.*,
+					s.depth + 1
+				FROM
+					todos c
+					JOIN subtree s ON c.parent_todo_id=s.id
+				WHERE
+					c.user_id=@user_id
+					AND c.deleted_at IS NULL
+					AND s.depth < @max_depth
+			)
+		SELECT
+			*
+		FROM
+			subtree
+		ORDER BY
+			depth ASC,
+			sort_order ASC,
+			created_at ASC
+	`
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":   todoID,
+		"user_id":   userID,
+		"max_depth": todo.MaxDepth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get subtree query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	type subtreeRow struct {
+		todo.Todo
+		Depth int `db:"depth"`
+	}
+
+	collected, err := pgx.CollectRows(rows, pgx.RowToStructByName[subtreeRow])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	todos := make([]todo.Todo, 0, len(collected))
+	for _, row := range collected {
+		todos = append(todos, row.Todo)
+	}
+
+	return todos, nil
+}
+
+// CopyAsFresh copies a todo and its subtasks into targetCategoryID as new
+// drafts in one transaction. Titles, descriptions, priorities, metadata and
+// the hierarchy carry over; status, completion and deferral do not. Due dates
+// are dropped unless shiftDueDates is set, in which case the earliest lands
+// that far from now and the rest keep their spacing relative to it.
+func (r *TodoRepository) CopyAsFresh(ctx context.Context, userID string, todoID uuid.UUID,
+	targetCategoryID uuid.UUID, shiftDueDates *time.Duration,
+) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin copy as fresh transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+	`
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todo to copy query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	root, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w",
+			todoID.String(), userID, err)
+	}
+
+	subtree, err := getTodoSubtree(ctx, tx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shallowest first, so every parent is copied before its children
+	source := append([]todo.Todo{root}, subtree...)
+	if shiftDueDates != nil {
+		todo.ShiftDueDates(source, time.Now().Add(*shiftDueDates))
+	}
+
+	copied := make(map[uuid.UUID]uuid.UUID, len(source))
+	var rootCopy *todo.Todo
+
+	for i := range source {
+		item := &source[i]
+
+		payload := &todo.CreateTodoPayload{
+			Title:       item.Title,
+			Description: item.Description,
+			Priority:    &item.Priority,
+			AllDay:      &item.AllDay,
+			CategoryID:  &targetCategoryID,
+			Metadata:    item.Metadata,
+		}
+		if shiftDueDates != nil {
+			payload.DueDate = item.DueDate
+		}
+		if item.ID != root.ID && item.ParentTodoID != nil {
+			parentID := copied[*item.ParentTodoID]
+			payload.ParentTodoID = &parentID
+		}
+
+		created, err := createTodo(ctx, tx, userID, nil, payload, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		copied[item.ID] = created.ID
+		if rootCopy == nil {
+			rootCopy = created
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit copy as fresh for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return rootCopy, nil
+}
+
+// GetDeferredTodos returns the todos still hidden by a future defer_until,
+// soonest to reappear first.
+func (r *TodoRepository) GetDeferredTodos(ctx context.Context, userID string) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			user_id=@user_id
+			AND deleted_at IS NULL
+			AND defer_until > NOW()
+		ORDER BY
+			defer_until ASC,
+			id ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get deferred todos query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// GetStaleTodos returns open todos that haven't been updated or had any
+// recorded activity within staleAfter, oldest first. Archived and currently
+// deferred todos are left out since they are parked on purpose.
+func (r *TodoRepository) GetStaleTodos(ctx context.Context, userID string, staleAfter time.Duration) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			t.*
+		FROM
+			todos t
+		WHERE
+			t.user_id=@user_id
+			AND t.deleted_at IS NULL
+			AND t.status IN ('draft', 'active')
+			AND t.updated_at < @cutoff
+			AND (
+				t.defer_until IS NULL
+				OR t.defer_until <= NOW()
+			)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todo_activities a
+				WHERE
+					a.todo_id=t.id
+					AND a.created_at >= @cutoff
+			)
+		ORDER BY
+			t.updated_at ASC,
+			t.id ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"cutoff":  time.Now().Add(-staleAfter),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get stale todos query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// missingFieldConditions holds the SQL matching a todo without each field
+var missingFieldConditions = map[todo.MissingField]string{
+	todo.MissingDueDate:     "t.due_date IS NULL",
+	todo.MissingCategory:    "t.category_id IS NULL",
+	todo.MissingDescription: "(t.description IS NULL OR BTRIM(t.description) = '')",
+}
+
+// GetIncompleteTodos returns open todos that lack any of the missing fields,
+// oldest first, so they can be tidied up
+func (r *TodoRepository) GetIncompleteTodos(ctx context.Context, userID string,
+	missing []todo.MissingField,
+) ([]todo.Todo, error) {
+	if len(missing) == 0 {
+		return []todo.Todo{}, nil
+	}
+
+	conditions := make([]string, 0, len(missing))
+	for _, field := range missing {
+		condition, ok := missingFieldConditions[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown missing field %q", field)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			t.*
+		FROM
+			todos t
+		WHERE
+			t.user_id=@user_id
+			AND t.deleted_at IS NULL
+			AND t.status IN ('draft', 'active')
+			AND (%s)
+		ORDER BY
+			t.created_at ASC,
+			t.id ASC
+	`, strings.Join(conditions, " OR "))
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get incomplete todos query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+func (r *TodoRepository) UpdateTodo(ctx context.Context, userID string, payload *todo.UpdateTodoPayload) (*todo.Todo, error) {
+	return r.updateTodo(ctx, personalScope(userID), payload)
+}
+
+// UpdateOrgTodo updates an organization todo, whoever created it
+func (r *TodoRepository) UpdateOrgTodo(ctx context.Context, orgID uuid.UUID, payload *todo.UpdateTodoPayload,
+) (*todo.Todo, error) {
+	return r.updateTodo(ctx, orgScope(orgID), payload)
+}
+
+func (r *TodoRepository) updateTodo(ctx context.Context, scope todoScope, payload *todo.UpdateTodoPayload,
+) (*todo.Todo, error) {
+	stmt := "UPDATE todos t SET "
+	args := pgx.NamedArgs{
+		"todo_id": payload.ID,
+	}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+	setClauses := []string{}
+
+	if payload.Title != nil {
+		setClauses = append(setClauses, "title = @title")
+		args["title"] = *payload.Title
+	}
+
+	if payload.Description != nil {
+		setClauses = append(setClauses, "description = @description")
+		args["description"] = *payload.Description
+	}
+
+	if payload.Status != nil {
+		setClauses = append(setClauses, setStatusClauses(args, *payload.Status)...)
+	}
+
+	if payload.Priority != nil {
+		setClauses = append(setClauses, "priority = @priority")
+		args["priority"] = *payload.Priority
+	}
+
+	if payload.DueDate != nil {
+		setClauses = append(setClauses, "due_date = @due_date")
+		args["due_date"] = *payload.DueDate
+	}
+
+	if payload.AllDay != nil {
+		setClauses = append(setClauses, "all_day = @all_day")
+		args["all_day"] = *payload.AllDay
+	}
+
+	if payload.DeferUntil != nil {
+		setClauses = append(setClauses, "defer_until = @defer_until")
+		args["defer_until"] = *payload.DeferUntil
+	}
+
+	if payload.ParentTodoID != nil {
+		setClauses = append(setClauses, "parent_todo_id = @parent_todo_id")
+		args["parent_todo_id"] = *payload.ParentTodoID
+	}
+
+	if payload.CategoryID != nil {
+		setClauses = append(setClauses, "category_id = @category_id")
+		args["category_id"] = *payload.CategoryID
+	}
+
+	if payload.Metadata != nil {
+		setClauses = append(setClauses, "metadata = @metadata")
+		args["metadata"] = payload.Metadata
+	}
+
+	if len(setClauses) == 0 {
+		return nil, errs.NewBadRequestError("no fields to update", false, nil, nil, nil)
+	}
+
+	stmt += strings.Join(setClauses, ", ")
+	stmt += " WHERE t.id = @todo_id AND " + scope.condition + " RETURNING t.*"
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	updatedTodo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos: %w", err)
+	}
+
+	return &updatedTodo, nil
+}
+
+// BumpTodo moves the todo's updated_at to now without changing anything else,
+// bringing it to the top of recency-sorted listings
+func (r *TodoRepository) BumpTodo(ctx context.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
+	stmt := `
+		UPDATE todos
+		SET
+			updated_at = NOW()
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute bump todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	bumped, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &bumped, nil
+}
+
+// AddChecklistItem appends a new unticked item to the todo's checklist. The
+// todo is locked while its length is checked so the cap holds under
+// concurrent adds.
+func (r *TodoRepository) AddChecklistItem(ctx context.Context, userID string, todoID uuid.UUID,
+	text string,
+) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin add checklist item transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+		"text":    text,
+	}
+
+	var total int
+	err = tx.QueryRow(ctx, `
+		SELECT
+			checklist_total
+		FROM
+			todos
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`, args).Scan(&total)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to lock todo_id=%s for checklist item: %w", todoID.String(), err)
+	}
+
+	if total >= todo.MaxChecklistItems {
+		code := errs.CodeChecklistFull
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("A checklist holds at most %d items, use subtasks for longer lists", todo.MaxChecklistItems),
+			true, &code, nil, nil)
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE todos
+		SET
+			checklist = checklist || jsonb_build_array(
+				jsonb_build_object('id', gen_random_uuid(), 'text', @text::TEXT, 'done', FALSE)
+			)
+		WHERE
+			id=@todo_id
+		RETURNING
+			*
+	`, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute add checklist item query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit checklist item for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &updated, nil
+}
+
+// UpdateChecklistItem changes the text and/or done flag of one checklist
+// item. Only that element is rewritten, and its position is looked up in the
+// row being updated, so concurrent edits to other items are kept.
+func (r *TodoRepository) UpdateChecklistItem(ctx context.Context, userID string, todoID, itemID uuid.UUID,
+	text *string, done *bool,
+) (*todo.Todo, error) {
+	patch := map[string]any{}
+	if text != nil {
+		patch["text"] = *text
+	}
+	if done != nil {
+		patch["done"] = *done
+	}
+
+	stmt := `
+		UPDATE todos
+		SET
+			checklist = (
+				SELECT
+					jsonb_set(checklist, ARRAY[(e.idx - 1)::TEXT], e.item || @patch::JSONB)
+				FROM
+					jsonb_array_elements(checklist) WITH ORDINALITY AS e (item, idx)
+				WHERE
+					e.item ->> 'id'=@item_id
+			)
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND checklist @> jsonb_build_array(jsonb_build_object('id', @item_id::TEXT))
+		RETURNING
+			*
+	`
+
+	return r.changeChecklistItem(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+		"item_id": itemID.String(),
+		"patch":   patch,
+	})
+}
+
+// RemoveChecklistItem deletes one item from the todo's checklist, by id
+func (r *TodoRepository) RemoveChecklistItem(ctx context.Context, userID string, todoID, itemID uuid.UUID,
+) (*todo.Todo, error) {
+	stmt := `
+		UPDATE todos
+		SET
+			checklist = checklist - (
+				SELECT
+					(e.idx - 1)::INTEGER
+				FROM
+					jsonb_array_elements(checklist) WITH ORDINALITY AS e (item, idx)
+				WHERE
+					e.item ->> 'id'=@item_id
+			)
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND checklist @> jsonb_build_array(jsonb_build_object('id', @item_id::TEXT))
+		RETURNING
+			*
+	`
+
+	return r.changeChecklistItem(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+		"item_id": itemID.String(),
+	})
+}
+
+// changeChecklistItem runs an update of a single checklist item. No row means
+// the item isn't on the checklist; callers check the todo exists first.
+func (r *TodoRepository) changeChecklistItem(ctx context.Context, stmt string, args pgx.NamedArgs,
+) (*todo.Todo, error) {
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute checklist item query for todo_id=%s: %w", args["todo_id"], err)
+	}
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeChecklistItemNotFound
+			return nil, errs.NewNotFoundError("checklist item not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", args["todo_id"], err)
+	}
+
+	return &updated, nil
+}
+
+// DeleteTodo moves the todo and every subtask under it, whoever added them,
+// to the trash. They share one deleted_at so RestoreTodo can bring back
+// exactly what went together.
+func (r *TodoRepository) DeleteTodo(ctx context.Context, userID string, todoID uuid.UUID) error {
+	deleted, err := r.BulkDeleteTodos(ctx, userID, []uuid.UUID{todoID})
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		code := errs.CodeTodoNotFound
+		return errs.NewNotFoundError("todo not found", false, &code)
+	}
+
+	return nil
+}
+
+// DeleteOrgTodo moves an organization todo and its subtasks to the trash,
+// whoever created them
+func (r *TodoRepository) DeleteOrgTodo(ctx context.Context, orgID uuid.UUID, todoID uuid.UUID) error {
+	deleted, err := r.bulkDeleteTodos(ctx, orgScope(orgID), []uuid.UUID{todoID})
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		code := errs.CodeTodoNotFound
+		return errs.NewNotFoundError("todo not found", false, &code)
+	}
+
+	return nil
+}
+
+// BulkDeleteTodos moves the listed personal todos of the user and their
+// subtasks to the trash in one statement and returns the listed ones it
+// deleted. Todos that don't exist, aren't the user's or are already in the
+// trash are left out. Everything deleted together shares one deleted_at, so a
+// subtask listed alongside its parent is restored with it.
+func (r *TodoRepository) BulkDeleteTodos(ctx context.Context, userID string, todoIDs []uuid.UUID,
+) ([]todo.Todo, error) {
+	return r.bulkDeleteTodos(ctx, personalScope(userID), todoIDs)
+}
+
+func (r *TodoRepository) bulkDeleteTodos(ctx context.Context, scope todoScope, todoIDs []uuid.UUID,
+) ([]todo.Todo, error) {
+	stmt := `
+		WITH RECURSIVE
+			subtree AS (
+				SELECT
+					t.id
+				FROM
+					todos t
+				WHERE
+					t.id = ANY(@todo_ids::uuid[])
+					AND ` + scope.condition + `
+				UNION
+				SELECT
+					c.id
+				FROM
+					todos c
+					JOIN subtree s ON c.parent_todo_id=s.id
+				WHERE
+					c.deleted_at IS NULL
+			)
+		UPDATE todos
+		SET
+			deleted_at=CURRENT_TIMESTAMP
+		WHERE
+			id IN (
+				SELECT
+					id
+				FROM
+					subtree
+			)
+		RETURNING
+			*
+	`
+
+	args := pgx.NamedArgs{"todo_ids": todoIDs}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute delete todos query for %s: %w", scope.owner, err)
+	}
+
+	trashed, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for %s: %w", scope.owner, err)
+	}
+
+	listed := make(map[uuid.UUID]bool, len(todoIDs))
+	for _, id := range todoIDs {
+		listed[id] = true
+	}
+
+	deleted := make([]todo.Todo, 0, len(todoIDs))
+	for _, item := range trashed {
+		if listed[item.ID] {
+			deleted = append(deleted, item)
+		}
+	}
+
+	return deleted, nil
+}
+
+// trashEntryCondition matches the todos listed in the trash: deleted ones
+// that didn't go along with a deleted parent
+const trashEntryCondition = `
+	t.user_id=@user_id
+	AND t.org_id IS NULL
+	AND t.deleted_at IS NOT NULL
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			todos p
+		WHERE
+			p.id=t.parent_todo_id
+			AND p.deleted_at=t.deleted_at
+	)
+`
+
+// GetTrash pages through the user's trash, most recently deleted first. Each
+// entry stands for itself and the subtasks deleted with it, which aren't
+// listed separately.
+func (r *TodoRepository) GetTrash(ctx context.Context, userID string, query *todo.GetTrashQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	var total int
+	err := r.server.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM todos t WHERE"+trashEntryCondition, args).
+		Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count for trash of user_id=%s: %w", userID, err)
+	}
+
+	stmt := "SELECT t.* FROM todos t WHERE" + trashEntryCondition +
+		" ORDER BY t.deleted_at DESC, t.id ASC LIMIT @limit OFFSET @offset"
+	args["limit"] = *query.Limit
+	args["offset"] = (*query.Page - 1) * (*query.Limit)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get trash query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return &model.PaginatedResponse[todo.Todo]{
+		Data:       todos,
+		Page:       *query.Page,
+		Limit:      *query.Limit,
+		Total:      total,
+		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+	}, nil
+}
+
+// getTrashedTodo locks a todo of the user that is in the trash
+func getTrashedTodo(ctx context.Context, q querier, userID string, todoID uuid.UUID) (*todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			id=@todo_id
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND deleted_at IS NOT NULL
+		FOR UPDATE
+	`
+
+	rows, err := q.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get trashed todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	trashed, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found in trash", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &trashed, nil
+}
+
+// RestoreTodo takes the todo out of the trash along with the subtasks that
+// were deleted with it. A subtask can't be restored while its parent is still
+// in the trash.
+func (r *TodoRepository) RestoreTodo(ctx context.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin restore todo transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	trashed, err := getTrashedTodo(ctx, tx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trashed.ParentTodoID != nil {
+		var parentDeleted bool
+		err := tx.QueryRow(ctx, "SELECT deleted_at IS NOT NULL FROM todos WHERE id=@parent_id FOR SHARE",
+			pgx.NamedArgs{"parent_id": *trashed.ParentTodoID}).Scan(&parentDeleted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check parent of todo_id=%s: %w", todoID.String(), err)
+		}
+
+		if parentDeleted {
+			code := errs.CodeParentInTrash
+			return nil, errs.NewBadRequestError("The parent todo is in the trash; restore it first", false,
+				&code, nil, nil)
+		}
+	}
+
+	stmt := `
+		WITH RECURSIVE
+			subtree AS (
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					id=@todo_id
+				UNION ALL
+				SELECT
+					c.id
+				FROM
+					todos c
+					JOIN subtree s ON c.parent_todo_id=s.id
+				WHERE
+					c.deleted_at=@deleted_at
+			)
+		UPDATE todos
+		SET
+			deleted_at=NULL
+		WHERE
+			id IN (
+				SELECT
+					id
+				FROM
+					subtree
+			)
+		RETURNING
+			*
+	`
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":    todoID,
+		"deleted_at": *trashed.DeletedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute restore todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	restored, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit restore todo for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	for i := range restored {
+		if restored[i].ID == todoID {
+			return &restored[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("restored todo_id=%s missing from the restored rows", todoID.String())
+}
+
+// revertColumns maps the tracked activity fields to their columns and decodes
+// the JSON recorded for them. Status is left out since it also moves
+// completed_at.
+var revertColumns = map[string]struct {
+	column string
+	decode func(json.RawMessage) (any, error)
+}{
+	"title":        {"title", decodeRecorded[string]},
+	"description":  {"description", decodeRecorded[*string]},
+	"priority":     {"priority", decodeRecorded[todo.Priority]},
+	"dueDate":      {"due_date", decodeRecorded[*time.Time]},
+	"allDay":       {"all_day", decodeRecorded[bool]},
+	"deferUntil":   {"defer_until", decodeRecorded[*time.Time]},
+	"parentTodoId": {"parent_todo_id", decodeRecorded[*uuid.UUID]},
+	"categoryId":   {"category_id", decodeRecorded[*uuid.UUID]},
+	"metadata":     {"metadata", decodeRecorded[*todo.Metadata]},
+	"checklist":    {"checklist", decodeRecorded[[]todo.ChecklistItem]},
+	"sortOrder":    {"sort_order", decodeRecorded[int]},
+}
+
+func decodeRecorded[T any](raw json.RawMessage) (any, error) {
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// RevertTodo writes the recorded values back onto the todo's fields. Fields
+// the activity log doesn't track are ignored.
+func (r *TodoRepository) RevertTodo(ctx context.Context, userID string, todoID uuid.UUID,
+	values activity.Snapshot,
+) (*todo.Todo, error) {
+	args := pgx.NamedArgs{
+		"todo_id": todoID,
+		"user_id": userID,
+	}
+	setClauses := []string{}
+
+	for field, raw := range values {
+		if field == "status" {
+			var status todo.Status
+			if err := json.Unmarshal(raw, &status); err != nil {
+				return nil, fmt.Errorf("failed to decode recorded status for todo_id=%s: %w", todoID.String(), err)
+			}
+			setClauses = append(setClauses, setStatusClauses(args, status)...)
+			continue
+		}
+
+		target, ok := revertColumns[field]
+		if !ok {
+			continue
+		}
+
+		value, err := target.decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded %s for todo_id=%s: %w", field, todoID.String(), err)
+		}
+
+		setClauses = append(setClauses, fmt.Sprintf("%s = @%s", target.column, target.column))
+		args[target.column] = value
+	}
+
+	if len(setClauses) == 0 {
+		return nil, errs.NewBadRequestError("no fields to revert", false, nil, nil, nil)
+	}
+
+	stmt := "UPDATE todos SET " + strings.Join(setClauses, ", ") +
+		" WHERE id = @todo_id AND user_id = @user_id AND org_id IS NULL AND deleted_at IS NULL RETURNING *"
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute revert todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	reverted, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &reverted, nil
+}
+
+// PurgeTodo permanently deletes a todo in the trash with everything under it,
+// returning the storage keys of the attachments that went with them so the
+// objects can be removed. Comments, attachments, share links and reminder
+// cancellations go through their foreign keys.
+func (r *TodoRepository) PurgeTodo(ctx context.Context, userID string, todoID uuid.UUID) ([]string, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin purge todo transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := getTrashedTodo(ctx, tx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	stmt := `
+		WITH RECURSIVE
+			subtree AS (
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					id=@todo_id
+				UNION ALL
+				SELECT
+					c.id
+				FROM
+					todos c
+					JOIN subtree s ON c.parent_todo_id=s.id
+			),
+			keys AS (
+				SELECT
+					a.download_key
+				FROM
+					todo_attachments a
+				WHERE
+					a.todo_id IN (
+						SELECT
+							id
+						FROM
+							subtree
+					)
+			),
+			purged AS (
+				DELETE FROM todos
+				WHERE
+					id IN (
+						SELECT
+							id
+						FROM
+							subtree
+					)
+			)
+		SELECT
+			download_key
+		FROM
+			keys
+	`
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute purge todo query for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_attachments for todo_id=%s: %w",
+			todoID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit purge todo for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return keys, nil
+}
+
+// GetTrashDueForPurge returns trash entries of every user deleted before
+// cutoff, oldest first
+func (r *TodoRepository) GetTrashDueForPurge(ctx context.Context, cutoff time.Time, limit int) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			t.*
+		FROM
+			todos t
+		WHERE
+			t.deleted_at < @cutoff
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todos p
+				WHERE
+					p.id=t.parent_todo_id
+					AND p.deleted_at=t.deleted_at
+			)
+		ORDER BY
+			t.deleted_at ASC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"cutoff": cutoff,
+		"limit":  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get trash due for purge query: %w", err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+// GetTodoStats reads the status counts from the trigger-maintained
+// todo_stats_summary row, so large accounts aren't scanned on every dashboard
+// load. The summary is updated in the same transaction as each todo write and
+// is never stale. Overdue depends on the clock and is always counted live. Users
+// without a summary row fall back to a full scan.
+func (r *TodoRepository) GetTodoStats(ctx context.Context, userID string) (*todo.TodoStats, error) {
+	stmt := `
+		SELECT
+			s.total,
+			s.draft,
+			s.active,
+			s.completed,
+			s.archived,
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					todos t
+				WHERE
+					t.user_id=s.user_id
+					AND t.deleted_at IS NULL
+					AND t.due_date IS NOT NULL
+					AND t.status!='completed'
+					AND todo_due_passed(t.due_date, t.all_day, user_timezone(t.user_id), user_day_start_hour(t.user_id))
+			) AS overdue
+		FROM
+			todo_stats_summary s
+		WHERE
+			s.user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoStats])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.scanTodoStats(ctx, userID)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_stats_summary: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// scanTodoStats counts the user's todos by status straight from the todos table
+func (r *TodoRepository) scanTodoStats(ctx context.Context, userID string) (*todo.TodoStats, error) {
+	stmt := `
+		SELECT
+			COUNT(*) AS total,
+			COUNT(
+				CASE
+					WHEN status='draft' THEN 1
+				END
+			) AS draft,
+			COUNT(
+				CASE
+					WHEN status='active' THEN 1
+				END
+			) AS active,
+			COUNT(
+				CASE
+					WHEN status='completed' THEN 1
+				END
+			) AS completed,
+			COUNT(
+				CASE
+					WHEN status='archived' THEN 1
+				END
+			) AS archived,
+			COUNT(
+				CASE
+					WHEN todo_due_passed(due_date, all_day, user_timezone(user_id), user_day_start_hour(user_id))
+					AND status!='completed' THEN 1
+				END
+			) AS overdue
+		FROM
+			todos
+		WHERE
+			user_id=@user_id
+			AND deleted_at IS NULL
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoStats])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetFilteredTodoStats breaks down by status only the todos matching the
+// list filters
+func (r *TodoRepository) GetFilteredTodoStats(ctx context.Context, userID string,
+	query *todo.GetTodosQuery,
+) (*todo.TodoStats, error) {
+	scope := personalScope(userID)
+
+	stmt := `
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (
+				WHERE
+					t.status='draft'
+			) AS draft,
+			COUNT(*) FILTER (
+				WHERE
+					t.status='active'
+			) AS active,
+			COUNT(*) FILTER (
+				WHERE
+					t.status='completed'
+			) AS completed,
+			COUNT(*) FILTER (
+				WHERE
+					t.status='archived'
+			) AS archived,
+			COUNT(*) FILTER (
+				WHERE
+					todo_due_passed(t.due_date, t.all_day, user_timezone(t.user_id), user_day_start_hour(t.user_id))
+					AND t.status!='completed'
+			) AS overdue
+		FROM
+			todos t
+	`
+
+	where, args := todoFilterClause(scope, query)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt+where, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute filtered stats query for %s: %w", scope.owner, err)
+	}
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoStats])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetCycleTimeStats measures completed_at - created_at across the user's
+// completed todos, overall and per priority and category. With a cap, todos
+// that took longer are counted as excluded instead of measured.
+func (r *TodoRepository) GetCycleTimeStats(ctx context.Context, userID string,
+	maxCycleTime *time.Duration,
+) (*todo.CycleTimeStats, error) {
+	stmt := `
+		WITH
+			cycle_times AS (
+				SELECT
+					t.priority,
+					t.category_id,
+					c.name AS category_name,
+					EXTRACT(
+						EPOCH
+						FROM
+							t.completed_at - t.created_at
+					) AS seconds
+				FROM
+					todos t
+					LEFT JOIN todo_categories c ON c.id=t.category_id
+				WHERE
+					t.user_id=@user_id
+					AND t.deleted_at IS NULL
+					AND t.status='completed'
+					AND t.completed_at IS NOT NULL
+			),
+			measured AS (
+				SELECT
+					*,
+					(
+						@max_seconds::DOUBLE PRECISION IS NULL
+						OR seconds<=@max_seconds::DOUBLE PRECISION
+					) AS kept
+				FROM
+					cycle_times
+			)
+		SELECT
+			CASE
+				WHEN GROUPING(priority)=0 THEN 'priority'
+				WHEN GROUPING(category_id, category_name)=0 THEN 'category'
+				ELSE 'overall'
+			END AS dimension,
+			priority,
+			category_id,
+			category_name,
+			COUNT(*) FILTER (
+				WHERE
+					kept
+			) AS count,
+			COALESCE(
+				AVG(seconds) FILTER (
+					WHERE
+						kept
+				),
+				0
+			)::DOUBLE PRECISION AS average_seconds,
+			COALESCE(
+				PERCENTILE_CONT(0.5) WITHIN GROUP (
+					ORDER BY
+						seconds
+				) FILTER (
+					WHERE
+						kept
+				),
+				0
+			)::DOUBLE PRECISION AS median_seconds,
+			COUNT(*) FILTER (
+				WHERE
+					NOT kept
+			) AS excluded
+		FROM
+			measured
+		GROUP BY
+			GROUPING SETS ((), (priority), (category_id, category_name))
+		ORDER BY
+			dimension,
+			priority,
+			category_name
+	`
+
+	var maxSeconds *float64
+	if maxCycleTime != nil {
+		seconds := maxCycleTime.Seconds()
+		maxSeconds = &seconds
+	}
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"max_seconds": maxSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute cycle time query for user_id=%s: %w", userID, err)
+	}
+
+	type cycleTimeRow struct {
+		Dimension      string         `db:"dimension"`
+		Priority       *todo.Priority `db:"priority"`
+		CategoryID     *uuid.UUID     `db:"category_id"`
+		CategoryName   *string        `db:"category_name"`
+		Count          int            `db:"count"`
+		AverageSeconds float64        `db:"average_seconds"`
+		MedianSeconds  float64        `db:"median_seconds"`
+		Excluded       int            `db:"excluded"`
+	}
+
+	results, err := pgx.CollectRows(rows, pgx.RowToStructByName[cycleTimeRow])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	stats := &todo.CycleTimeStats{
+		ByPriority: []todo.PriorityCycleTime{},
+		ByCategory: []todo.CategoryCycleTime{},
+	}
+
+	for _, row := range results {
+		cycleTime := todo.CycleTime{
+			Count:          row.Count,
+			AverageSeconds: row.AverageSeconds,
+			MedianSeconds:  row.MedianSeconds,
+		}
+
+		switch row.Dimension {
+		case "overall":
+			stats.Overall = cycleTime
+			stats.ExcludedOutliers = row.Excluded
+		case "priority":
+			// Groups made up entirely of outliers have nothing to report
+			if row.Count > 0 && row.Priority != nil {
+				stats.ByPriority = append(stats.ByPriority, todo.PriorityCycleTime{
+					Priority:  *row.Priority,
+					CycleTime: cycleTime,
+				})
+			}
+		case "category":
+			if row.Count > 0 {
+				stats.ByCategory = append(stats.ByCategory, todo.CategoryCycleTime{
+					CategoryID:   row.CategoryID,
+					CategoryName: row.CategoryName,
+					CycleTime:    cycleTime,
+				})
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// HasOverdue reports whether the user has at least one overdue todo. EXISTS
+// stops at the first match, and since a todo can only be overdue once its
+// due_date has passed, the plain comparison narrows the rows before the
+// timezone-aware check runs.
+func (r *TodoRepository) HasOverdue(ctx context.Context, userID string) (bool, error) {
+	stmt := `
+		SELECT
+			EXISTS (
+				SELECT
+					1
+				FROM
+					todos
+				WHERE
+					user_id=@user_id
+					AND deleted_at IS NULL
+					AND due_date < NOW()
+					AND status != 'completed'
+					AND todo_due_passed(due_date, all_day, user_timezone(@user_id), user_day_start_hour(@user_id))
+			)
+	`
+
+	var hasOverdue bool
+	err := r.server.DB.Pool.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	}).Scan(&hasOverdue)
+	if err != nil {
+		return false, fmt.Errorf("failed to check overdue todos for user_id=%s: %w", userID, err)
+	}
+
+	return hasOverdue, nil
+}
+
+// GetOverdueBuckets groups the user's overdue todos by how many days have
+// passed since they were due in timezone, listing each bucket's todo IDs most
+// overdue first. Days roll over at dayStartHour; a timed todo counts towards
+// the day it fell in, an all-day todo towards its calendar date.
+func (r *TodoRepository) GetOverdueBuckets(ctx context.Context, userID string, timezone string,
+	dayStartHour int,
+) (*todo.OverdueBuckets, error) {
+	stmt := `
+		SELECT
+			CASE
+				WHEN days_overdue<=0 THEN 'today'
+				WHEN days_overdue<=3 THEN '1_3_days'
+				WHEN days_overdue<=7 THEN '4_7_days'
+				ELSE '8_plus_days'
+			END AS bucket,
+			COUNT(*) AS count,
+			ARRAY_AGG(
+				id
+				ORDER BY
+					due_date ASC,
+					id ASC
+			) AS todo_ids
+		FROM
+			(
+				SELECT
+					id,
+					due_date,
+					local_day(NOW(), @timezone, @day_start_hour) - CASE
+						WHEN all_day THEN (due_date AT TIME ZONE @timezone)::DATE
+						ELSE local_day(due_date, @timezone, @day_start_hour)
+					END AS days_overdue
+				FROM
+					todos
+				WHERE
+					user_id=@user_id
+					AND deleted_at IS NULL
+					AND due_date IS NOT NULL
+					AND todo_due_passed(due_date, all_day, @timezone, @day_start_hour)
+					AND status NOT IN ('completed', 'archived')
+			) overdue
+		GROUP BY
+			bucket
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":        userID,
+		"timezone":       timezone,
+		"day_start_hour": dayStartHour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute overdue buckets query for user_id=%s: %w", userID, err)
+	}
+
+	found, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.OverdueBucketSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	byBucket := make(map[todo.OverdueBucket]todo.OverdueBucketSummary, len(found))
+	for _, summary := range found {
+		byBucket[summary.Bucket] = summary
+	}
+
+	buckets := &todo.OverdueBuckets{
+		Buckets: make([]todo.OverdueBucketSummary, 0, len(todo.OverdueBucketOrder)),
+	}
+	for _, bucket := range todo.OverdueBucketOrder {
+		summary, ok := byBucket[bucket]
+		if !ok {
+			summary = todo.OverdueBucketSummary{Bucket: bucket, TodoIDs: []uuid.UUID{}}
+		}
+		buckets.Buckets = append(buckets.Buckets, summary)
+		buckets.Total += summary.Count
+	}
+
+	return buckets, nil
+}
+
+func (r *TodoRepository) ArchiveCategoryTodos(ctx context.Context, userID string, categoryID uuid.UUID,
+	onlyCompleted bool,
+) ([]todo.Change, error) {
+	// Organization todos filed under the category stay as they are
+	scope := personalScope(userID)
+	args := pgx.NamedArgs{
+		"category_id": categoryID,
+	}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	where := scope.condition + `
+		AND t.category_id = @category_id
+		AND t.status != 'archived'
+	`
+
+	if onlyCompleted {
+		where += " AND t.status = 'completed'"
+	}
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin archive category todos transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	changes, err := updateTodos(ctx, tx, setStatusClauses(args, todo.StatusArchived), where, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive todos for category_id=%s user_id=%s: %w", categoryID.String(), userID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit archive todos for category_id=%s: %w", categoryID.String(), err)
+	}
+
+	return changes, nil
+}
+
+// GetCategoryProgressTrend counts, for each bucket from from to to, the todos
+// in the category created before the bucket ended and how many of them had
+// been completed by then. Buckets are aligned to UTC day, ISO week or month
+// starts, so the first one may begin before from.
+func (r *TodoRepository) GetCategoryProgressTrend(ctx context.Context, userID string, categoryID uuid.UUID,
+	from, to time.Time, bucket category.ProgressBucket,
+) ([]category.ProgressPoint, error) {
+	stmt := `
+		WITH
+			buckets AS (
+				SELECT
+					gs AT TIME ZONE 'UTC' AS bucket_start,
+					(gs + ('1 ' || @bucket)::INTERVAL) AT TIME ZONE 'UTC' AS bucket_end
+				FROM
+					generate_series(
+						date_trunc(@bucket, @from::timestamptz AT TIME ZONE 'UTC'),
+						@to::timestamptz AT TIME ZONE 'UTC',
+						('1 ' || @bucket)::INTERVAL
+					) AS gs
+			)
+		SELECT
+			b.bucket_start,
+			b.bucket_end,
+			COUNT(t.id) FILTER (
+				WHERE
+					t.created_at < b.bucket_end
+			) AS total,
+			COUNT(t.id) FILTER (
+				WHERE
+					t.created_at < b.bucket_end
+					AND t.completed_at < b.bucket_end
+			) AS completed
+		FROM
+			buckets b
+			LEFT JOIN todos t ON t.user_id = @user_id
+			AND t.category_id = @category_id
+			AND t.deleted_at IS NULL
+		GROUP BY
+			b.bucket_start,
+			b.bucket_end
+		ORDER BY
+			b.bucket_start
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"category_id": categoryID,
+		"from":        from,
+		"to":          to,
+		"bucket":      string(bucket),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute category progress trend query for category_id=%s user_id=%s: %w",
+			categoryID.String(), userID, err)
+	}
+
+	points, err := pgx.CollectRows(rows, pgx.RowToStructByName[category.ProgressPoint])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect category progress trend for category_id=%s user_id=%s: %w",
+			categoryID.String(), userID, err)
+	}
+
+	return points, nil
+}
+
+// ReplaceTagInCategory replaces oldTag with newTag in the tags of every todo
+// in the category, dropping it when newTag is empty. Tags keep their original
+// order and a replacement that already exists on a todo, in any casing, is
+// not duplicated. Returns the todos it changed.
+func (r *TodoRepository) ReplaceTagInCategory(ctx context.Context, userID string, categoryID uuid.UUID,
+	oldTag, newTag string,
+) ([]todo.Change, error) {
+	set := `
+		metadata = jsonb_set(
+			t.metadata,
+			'{tags}',
+			COALESCE(
+				(
+					SELECT
+						jsonb_agg(
+							deduped.tag
+							ORDER BY
+								deduped.position
+						)
+					FROM
+						(
+							SELECT DISTINCT
+								ON (LOWER(replaced.tag)) replaced.tag,
+								replaced.position
+							FROM
+								(
+									SELECT
+										CASE
+											WHEN e.tag=@old_tag THEN @new_tag
+											ELSE e.tag
+										END AS tag,
+										e.position
+									FROM
+										jsonb_array_elements_text(t.metadata->'tags') WITH ORDINALITY AS e (tag, position)
+								) replaced
+							WHERE
+								replaced.tag != ''
+							ORDER BY
+								LOWER(replaced.tag),
+								replaced.position
+						) deduped
+				),
+				'[]'::JSONB
+			)
+		)
+	`
+
+	// Organization todos filed under the category stay as they are
+	scope := personalScope(userID)
+	where := scope.condition + `
+		AND t.category_id=@category_id
+		AND jsonb_typeof(t.metadata->'tags')='array'
+		AND t.metadata->'tags' @> jsonb_build_array(@old_tag::TEXT)
+	`
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin replace tag transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"category_id": categoryID,
+		"old_tag":     oldTag,
+		"new_tag":     newTag,
+	}
+	for key, value := range scope.args {
+		args[key] = value
+	}
+
+	changes, err := updateTodos(ctx, tx, []string{set}, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace tag for category_id=%s user_id=%s: %w", categoryID.String(), userID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit replace tag for category_id=%s: %w", categoryID.String(), err)
+	}
+
+	return changes, nil
+}
+
+func (r *TodoRepository) GetTodoAttachment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	attachmentID uuid.UUID,
+) (*todo.TodoAttachment, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_attachments
+		WHERE
+			todo_id = @todo_id
+			AND id = @attachment_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":       todoID,
+		"attachment_id": attachmentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo attachment: %w", err)
+	}
+
+	attachment, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeAttachmentNotFound
+			return nil, errs.NewNotFoundError("attachment not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_attachments: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+// RecordAttachmentAccess adds an entry to the attachment access log
+func (r *TodoRepository) RecordAttachmentAccess(ctx context.Context, entry *admin.AttachmentAccessLog) error {
+	stmt := `
+		INSERT INTO
+			attachment_access_log (
+				user_id,
+				todo_id,
+				attachment_id,
+				ip,
+				request_id
+			)
+		VALUES
+			(
+				@user_id,
+				@todo_id,
+				@attachment_id,
+				@ip,
+				@request_id
+			)
+	`
+
+	_, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":       entry.UserID,
+		"todo_id":       entry.TodoID,
+		"attachment_id": entry.AttachmentID,
+		"ip":            entry.IP,
+		"request_id":    entry.RequestID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record access to attachment_id=%s user_id=%s: %w",
+			entry.AttachmentID.String(), entry.UserID, err)
+	}
+
+	return nil
+}
+
+func (r *TodoRepository) GetTodoAttachments(
+	ctx context.Context,
+	todoID uuid.UUID,
+) ([]todo.TodoAttachment, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todo_attachments
+		WHERE
+			todo_id = @todo_id
+		ORDER BY
+			created_at DESC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo attachments: %w", err)
+	}
+
+	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.TodoAttachment{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todo_attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *TodoRepository) DeleteTodoAttachment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	attachmentID uuid.UUID,
+) error {
+	stmt := `
+		DELETE FROM todo_attachments
+		WHERE
+			todo_id = @todo_id
+			AND id = @attachment_id
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"todo_id":       todoID,
+		"attachment_id": attachmentID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete todo attachment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		code := errs.CodeAttachmentNotFound
+		return errs.NewNotFoundError("attachment not found", false, &code)
+	}
+
+	return nil
+}
+
+// UploadTodoAttachment records an uploaded file. When the todo already has an
+// attachment called fileName, the new one is stored under a numbered name
+// such as "report (1).pdf" instead.
+func (r *TodoRepository) UploadTodoAttachment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	userID string,
+	s3Key string,
+	fileName string,
+	fileSize int64,
+	mimeType string,
+) (*todo.TodoAttachment, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin attachment transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the todo so concurrent uploads of the same name get different numbers
+	lockStmt := `
+		SELECT
+			ARRAY(
+				SELECT
+					name
+				FROM
+					todo_attachments
+				WHERE
+					todo_id = t.id
+			)
+		FROM
+			todos t
+		WHERE
+			t.id = @todo_id
+			AND t.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var existing []string
+	err = tx.QueryRow(ctx, lockStmt, pgx.NamedArgs{
+		"todo_id": todoID,
+	}).Scan(&existing)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to lock todo for attachment todo_id=%s: %w", todoID.String(), err)
+	}
+
+	stmt := `
+		INSERT INTO
+			todo_attachments (
+				todo_id,
+				name,
+				uploaded_by,
+				download_key,
+				file_size,
+				mime_type
+			)
+		VALUES
+			(
+				@todo_id,
+				@name,
+				@uploaded_by,
+				@download_key,
+				@file_size,
+				@mime_type
+			)
+		RETURNING
+			*
+	`
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":      todoID,
+		"name":         todo.DisambiguateName(fileName, existing),
+		"uploaded_by":  userID,
+		"download_key": s3Key,
+		"file_size":    fileSize,
+		"mime_type":    mimeType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo attachment for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	attachment, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todo_attachments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit attachment for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return &attachment, nil
+}
+
+// CRON REQUIREMENTS
+
+// SetAttachmentTranscript stores the transcript of an audio attachment
+func (r *TodoRepository) SetAttachmentTranscript(ctx context.Context, todoID uuid.UUID, attachmentID uuid.UUID,
+	transcript string,
+) (*todo.TodoAttachment, error) {
+	stmt := `
+		UPDATE todo_attachments
+		SET
+			transcript = @transcript,
+			transcribed_at = CURRENT_TIMESTAMP
+		WHERE
+			todo_id = @todo_id
+			AND id = @attachment_id
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id":       todoID,
+		"attachment_id": attachmentID,
+		"transcript":    transcript,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set transcript for attachment_id=%s: %w", attachmentID.String(), err)
+	}
+
+	attachment, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeAttachmentNotFound
+			return nil, errs.NewNotFoundError("attachment not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todo_attachments: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+func (r *TodoRepository) GetTodosDueInHours(ctx context.Context, hours int, limit int, offset int) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			due_date IS NOT NULL
+			AND due_date > NOW()
+			AND due_date <= NOW() + INTERVAL '%d hours'
+			AND status NOT IN ('completed', 'archived')
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todo_reminder_cancellations rc
+				WHERE
+					rc.todo_id = todos.id
+					AND rc.kind = 'due_soon'
+					AND rc.due_date = todos.due_date
+			)
+		ORDER BY
+			due_date ASC,
+			id ASC
+		LIMIT
+			%d
+		OFFSET
+			%d
+	`
+
+	query := fmt.Sprintf(stmt, hours, limit, offset)
+	rows, err := r.server.DB.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todos due in %d hours query: %w", hours, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+// GetUserTodosDueInHours mirrors GetTodosDueInHours for a single user
+func (r *TodoRepository) GetUserTodosDueInHours(ctx context.Context, userID string, hours int) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			user_id = @user_id
+			AND due_date IS NOT NULL
+			AND due_date > NOW()
+			AND due_date <= NOW() + MAKE_INTERVAL(hours => @hours)
+			AND status NOT IN ('completed', 'archived')
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todo_reminder_cancellations rc
+				WHERE
+					rc.todo_id = todos.id
+					AND rc.kind = 'due_soon'
+					AND rc.due_date = todos.due_date
+			)
+		ORDER BY
+			due_date ASC,
+			id ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"hours":   hours,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todos due in %d hours query for user_id=%s: %w", hours, userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// GetUserOverdueTodos mirrors GetOverdueTodos for a single user
+func (r *TodoRepository) GetUserOverdueTodos(ctx context.Context, userID string) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			user_id = @user_id
+			AND due_date IS NOT NULL
+			AND todo_due_passed(due_date, all_day, user_timezone(user_id), user_day_start_hour(user_id))
+			AND status NOT IN ('completed', 'archived')
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todo_reminder_cancellations rc
+				WHERE
+					rc.todo_id = todos.id
+					AND rc.kind = 'overdue'
+					AND rc.due_date = todos.due_date
+			)
+		ORDER BY
+			due_date ASC,
+			id ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get overdue todos query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// GetReminderCancellations returns the todo's cancelled reminder kinds, each
+// with the due date it was cancelled against
+func (r *TodoRepository) GetReminderCancellations(ctx context.Context,
+	todoID uuid.UUID,
+) (map[todo.ReminderKind]time.Time, error) {
+	stmt := `
+		SELECT
+			kind,
+			due_date
+		FROM
+			todo_reminder_cancellations
+		WHERE
+			todo_id = @todo_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_id": todoID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get reminder cancellations query for todo_id=%s: %w", todoID, err)
+	}
+
+	cancelled := make(map[todo.ReminderKind]time.Time)
+	var (
+		kind    todo.ReminderKind
+		dueDate time.Time
+	)
+	_, err = pgx.ForEachRow(rows, []any{&kind, &dueDate}, func() error {
+		cancelled[kind] = dueDate
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todo_reminder_cancellations for todo_id=%s: %w",
+			todoID, err)
+	}
+
+	return cancelled, nil
+}
+
+// CancelReminder stops the cron jobs sending the todo's reminder of kind for
+// as long as the todo keeps dueDate
+func (r *TodoRepository) CancelReminder(ctx context.Context, todoID uuid.UUID, kind todo.ReminderKind,
+	dueDate time.Time,
+) error {
+	stmt := `
+		INSERT INTO
+			todo_reminder_cancellations (todo_id, kind, due_date)
+		VALUES
+			(@todo_id, @kind, @due_date)
+		ON CONFLICT (todo_id, kind) DO UPDATE
+		SET
+			due_date = EXCLUDED.due_date,
+			created_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"todo_id":  todoID,
+		"kind":     kind,
+		"due_date": dueDate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel %s reminder for todo_id=%s: %w", kind, todoID, err)
+	}
+
+	return nil
+}
+
+func (r *TodoRepository) GetOverdueTodos(ctx context.Context, limit int, offset int) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			due_date IS NOT NULL
+			AND todo_due_passed(due_date, all_day, user_timezone(user_id), user_day_start_hour(user_id))
+			AND status NOT IN ('completed', 'archived')
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					todo_reminder_cancellations rc
+				WHERE
+					rc.todo_id = todos.id
+					AND rc.kind = 'overdue'
+					AND rc.due_date = todos.due_date
+			)
+		ORDER BY
+			due_date ASC,
+			id ASC
+		LIMIT
+			@limit
+		OFFSET
+			@offset
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"limit":  limit,
+		"offset": offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get overdue todos query: %w", err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+func (r *TodoRepository) GetCompletedTodosOlderThan(ctx context.Context, cutoffDate time.Time, limit int) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			status = 'completed'
+			AND deleted_at IS NULL
+			AND completed_at IS NOT NULL
+			AND completed_at < @cutoff_date
+		ORDER BY
+			completed_at ASC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"cutoff_date": cutoffDate,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get completed todos older than %s query: %w", cutoffDate.Format("2006-01-02"), err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+// ActivateDraftsDueBy moves up to limit draft todos due between now and the
+// deadline to active and returns them. Drafts already past due, or deferred
+// past now, are left alone. Locked rows are skipped so concurrent runs don't
+// block on each other. Whether drafts may become active at all is checked
+// against todo.StatusTransitions before that.
+func (r *TodoRepository) ActivateDraftsDueBy(ctx context.Context, deadline time.Time, limit int) ([]todo.Todo, error) {
+	args := pgx.NamedArgs{
+		"deadline": deadline,
+		"limit":    limit,
+	}
+
+	stmt := "UPDATE todos SET " + strings.Join(setStatusClauses(args, todo.StatusActive), ", ") + `
+		WHERE
+			id IN (
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					status = 'draft'
+					AND deleted_at IS NULL
+					AND due_date IS NOT NULL
+					AND due_date >= CURRENT_TIMESTAMP
+					AND due_date <= @deadline
+					AND (
+						defer_until IS NULL
+						OR defer_until <= CURRENT_TIMESTAMP
+					)
+				ORDER BY
+					due_date ASC
+				LIMIT
+					@limit
+				FOR UPDATE
+					SKIP LOCKED
+			)
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute activate drafts due by %s query: %w", deadline.Format(time.RFC3339), err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return todos, nil
+}
+
+func (r *TodoRepository) ArchiveTodos(ctx context.Context, todoIDs []uuid.UUID) ([]todo.Change, error) {
+	args := pgx.NamedArgs{
+		"todo_ids": todoIDs,
+	}
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin archive todos transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	changes, err := updateTodos(ctx, tx, setStatusClauses(args, todo.StatusArchived),
+		"t.id = ANY(@todo_ids::uuid[])", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive todos: %w", err)
+	}
+
+	if len(changes) != len(todoIDs) {
+		return nil, fmt.Errorf("expected to archive %d todos, but archived %d", len(todoIDs), len(changes))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit archive todos: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (r *TodoRepository) GetTodosByIDs(ctx context.Context, userID string, todoIDs []uuid.UUID) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
 			*
 		FROM
-			todo_attachments
-		WHERE
-			todo_id = @todo_id
-			AND id = @attachment_id
+			todos
+		WHERE
+			id = ANY(@todo_ids::uuid[])
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"todo_ids": todoIDs,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todos by ids query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// BulkReparent moves all the given todos under newParentID in one statement,
+// or promotes them to the root when newParentID is nil. The whole batch is
+// rejected if any todo is missing, if the move would make a todo its own
+// ancestor, if any moved subtree would end up deeper than todo.MaxDepth, or if
+// the new parent would end up with more than maxChildren direct subtasks.
+func (r *TodoRepository) BulkReparent(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	newParentID *uuid.UUID, maxChildren int,
+) (int, error) {
+	todoIDs = uniqueIDs(todoIDs)
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin bulk reparent transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"user_id":        userID,
+		"todo_ids":       todoIDs,
+		"parent_todo_id": newParentID,
+		"max_depth":      todo.MaxDepth,
+	}
+
+	// Lock the todos being moved so the checks below still hold at update time
+	lockStmt := `
+		SELECT
+			COUNT(*)
+		FROM
+			(
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					id = ANY(@todo_ids::uuid[])
+					AND user_id = @user_id
+					AND org_id IS NULL
+					AND deleted_at IS NULL
+				FOR UPDATE
+			) moved
+	`
+
+	var found int
+	if err := tx.QueryRow(ctx, lockStmt, args).Scan(&found); err != nil {
+		return 0, fmt.Errorf("failed to lock todos for bulk reparent user_id=%s: %w", userID, err)
+	}
+
+	if found != len(todoIDs) {
+		code := errs.CodeTodoNotFound
+		return 0, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(todoIDs)-found, len(todoIDs)), true, &code)
+	}
+
+	parentDepth := 0
+	if newParentID != nil {
+		// Walk up from the new parent; hitting one of the moved todos means a cycle
+		ancestorStmt := `
+			WITH RECURSIVE
+				ancestors AS (
+					SELECT
+						id,
+						parent_todo_id,
+						1 AS depth
+					FROM
+						todos
+					WHERE
+						id = @parent_todo_id
+						AND user_id = @user_id
+						AND org_id IS NULL
+						AND deleted_at IS NULL
+					UNION ALL
+					SELECT
+						t.id,
+						t.parent_todo_id,
+						a.depth + 1
+					FROM
+						todos t
+						JOIN ancestors a ON t.id = a.parent_todo_id
+					WHERE
+						t.user_id = @user_id
+						AND a.depth <= @max_depth
+				)
+			SELECT
+				COUNT(*) AS depth,
+				COUNT(*) FILTER (
+					WHERE
+						id = ANY(@todo_ids::uuid[])
+				) AS cycles
+			FROM
+				ancestors
+		`
+
+		var cycles int
+		if err := tx.QueryRow(ctx, ancestorStmt, args).Scan(&parentDepth, &cycles); err != nil {
+			return 0, fmt.Errorf("failed to check ancestors of parent_todo_id=%s: %w", newParentID.String(), err)
+		}
+
+		if parentDepth == 0 {
+			code := errs.CodeTodoNotFound
+			return 0, errs.NewNotFoundError("parent todo not found", false, &code)
+		}
+
+		if cycles > 0 {
+			code := errs.CodeCircularReference
+			return 0, errs.NewBadRequestError("A todo cannot be moved under itself or one of its subtasks",
+				true, &code, nil, nil)
+		}
+
+		// Moved todos already under the parent don't take up another place
+		siblings, err := countChildren(ctx, tx, *newParentID, todoIDs)
+		if err != nil {
+			return 0, err
+		}
+
+		if siblings+len(todoIDs) > maxChildren {
+			return 0, todo.MaxChildrenExceededError(maxChildren)
+		}
+	}
+
+	// Measure each moved subtree, ignoring branches that are themselves being moved
+	depthStmt := `
+		WITH RECURSIVE
+			subtree AS (
+				SELECT
+					id AS root_id,
+					id,
+					1 AS level
+				FROM
+					todos
+				WHERE
+					id = ANY(@todo_ids::uuid[])
+					AND user_id = @user_id
+					AND org_id IS NULL
+					AND deleted_at IS NULL
+				UNION ALL
+				SELECT
+					s.root_id,
+					c.id,
+					s.level + 1
+				FROM
+					todos c
+					JOIN subtree s ON c.parent_todo_id = s.id
+				WHERE
+					c.user_id = @user_id
+					AND NOT c.id = ANY(@todo_ids::uuid[])
+					AND s.level <= @max_depth
+			)
+		SELECT
+			root_id
+		FROM
+			subtree
+		GROUP BY
+			root_id
+		HAVING
+			MAX(level) + @parent_depth > @max_depth
 	`
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"todo_id":       todoID,
-		"attachment_id": attachmentID,
-	})
+	args["parent_depth"] = parentDepth
+
+	rows, err := tx.Query(ctx, depthStmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get todo attachment: %w", err)
+		return 0, fmt.Errorf("failed to check subtree depth for bulk reparent user_id=%s: %w", userID, err)
 	}
 
-	attachment, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	tooDeep, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			code := "ATTACHMENT_NOT_FOUND"
-			return nil, errs.NewNotFoundError("attachment not found", false, &code)
-		}
-		return nil, fmt.Errorf("failed to collect row from table:todo_attachments: %w", err)
+		return 0, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
 	}
 
-	return &attachment, nil
-}
+	if len(tooDeep) > 0 {
+		fieldErrors := make([]errs.FieldError, 0, len(tooDeep))
+		for _, id := range tooDeep {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field: id.String(),
+				Error: fmt.Sprintf("would exceed the maximum depth of %d", todo.MaxDepth),
+			})
+		}
+		code := errs.CodeMaxDepthExceeded
+		return 0, errs.NewBadRequestError(
+			fmt.Sprintf("Moving %d todos would exceed the maximum depth of %d", len(tooDeep), todo.MaxDepth),
+			true, &code, fieldErrors, nil)
+	}
 
-func (r *TodoRepository) GetTodoAttachments(
-	ctx context.Context,
-	todoID uuid.UUID,
-) ([]todo.TodoAttachment, error) {
-	stmt := `
-		SELECT
-			*
-		FROM
-			todo_attachments
+	updateStmt := `
+		UPDATE todos
+		SET
+			parent_todo_id = @parent_todo_id
 		WHERE
-			todo_id = @todo_id
-		ORDER BY
-			created_at DESC
+			id = ANY(@todo_ids::uuid[])
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
 	`
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"todo_id": todoID,
-	})
+	result, err := tx.Exec(ctx, updateStmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get todo attachments: %w", err)
+		return 0, fmt.Errorf("failed to reparent todos for user_id=%s: %w", userID, err)
 	}
 
-	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.TodoAttachment])
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []todo.TodoAttachment{}, nil
-		}
-		return nil, fmt.Errorf("failed to collect rows from table:todo_attachments: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit bulk reparent for user_id=%s: %w", userID, err)
 	}
 
-	return attachments, nil
+	return int(result.RowsAffected()), nil
 }
 
-func (r *TodoRepository) DeleteTodoAttachment(
-	ctx context.Context,
-	todoID uuid.UUID,
-	attachmentID uuid.UUID,
-) error {
-	stmt := `
-		DELETE FROM todo_attachments
+// BulkUpdateStatus moves all the given todos to status in one statement. The
+// whole batch is rejected if any of the todos doesn't belong to the user.
+func (r *TodoRepository) BulkUpdateStatus(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	status todo.Status,
+) (int, error) {
+	todoIDs = uniqueIDs(todoIDs)
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin bulk status transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"user_id":  userID,
+		"todo_ids": todoIDs,
+	}
+
+	stmt := "UPDATE todos SET " + strings.Join(setStatusClauses(args, status), ", ") + `
 		WHERE
-			todo_id = @todo_id
-			AND id = @attachment_id
+			id = ANY(@todo_ids::uuid[])
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
 	`
 
-	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
-		"todo_id":       todoID,
-		"attachment_id": attachmentID,
-	})
+	result, err := tx.Exec(ctx, stmt, args)
 	if err != nil {
-		return fmt.Errorf("failed to delete todo attachment: %w", err)
+		return 0, fmt.Errorf("failed to update status of todos for user_id=%s: %w", userID, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		code := "ATTACHMENT_NOT_FOUND"
-		return errs.NewNotFoundError("attachment not found", false, &code)
+	if updated := int(result.RowsAffected()); updated != len(todoIDs) {
+		code := errs.CodeTodoNotFound
+		return 0, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(todoIDs)-updated, len(todoIDs)), true, &code)
 	}
 
-	return nil
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit bulk status update for user_id=%s: %w", userID, err)
+	}
+
+	return len(todoIDs), nil
 }
 
-func (r *TodoRepository) UploadTodoAttachment(
-	ctx context.Context,
-	todoID uuid.UUID,
-	userID string,
-	s3Key string,
-	fileName string,
-	fileSize int64,
-	mimeType string,
-) (*todo.TodoAttachment, error) {
+// BulkArchive archives every listed todo that isn't archived already, in one
+// statement, and returns how many it archived
+func (r *TodoRepository) BulkArchive(ctx context.Context, userID string, todoIDs []uuid.UUID) (int, error) {
+	return r.bulkMoveStatus(ctx, userID, todoIDs, "status != 'archived'", todo.StatusArchived)
+}
+
+// BulkUnarchive returns every listed archived todo to active and reports how
+// many it restored. The status a todo had before it was archived isn't kept,
+// and active is the only way out of archived under the default transitions.
+func (r *TodoRepository) BulkUnarchive(ctx context.Context, userID string, todoIDs []uuid.UUID) (int, error) {
+	return r.bulkMoveStatus(ctx, userID, todoIDs, "status = 'archived'", todo.StatusActive)
+}
+
+// bulkMoveStatus moves the listed todos matching condition to status, leaving
+// the rest alone. The batch is rejected if any todo isn't the user's.
+func (r *TodoRepository) bulkMoveStatus(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	condition string, status todo.Status,
+) (int, error) {
+	todoIDs = uniqueIDs(todoIDs)
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin bulk %s transaction: %w", status, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"user_id":  userID,
+		"todo_ids": todoIDs,
+	}
+
 	stmt := `
-		INSERT INTO
-			todo_attachments (
-				todo_id,
-				name,
-				uploaded_by,
-				download_key,
-				file_size,
-				mime_type
-			)
-		VALUES
-			(
-				@todo_id,
-				@name,
-				@uploaded_by,
-				@download_key,
-				@file_size,
-				@mime_type
+		WITH
+			owned AS (
+				SELECT
+					id
+				FROM
+					todos
+				WHERE
+					id = ANY(@todo_ids::uuid[])
+					AND user_id = @user_id
+					AND org_id IS NULL
+					AND deleted_at IS NULL
+			),
+			moved AS (
+				UPDATE todos
+				SET
+					` + strings.Join(setStatusClauses(args, status), ", ") + `
+				WHERE
+					id IN (SELECT id FROM owned)
+					AND ` + condition + `
+				RETURNING
+					id
 			)
-		RETURNING
-			*
+		SELECT
+			(SELECT COUNT(*) FROM owned),
+			(SELECT COUNT(*) FROM moved)
 	`
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"todo_id":      todoID,
-		"name":         fileName,
-		"uploaded_by":  userID,
-		"download_key": s3Key,
-		"file_size":    fileSize,
-		"mime_type":    mimeType,
-	})
+	var owned, moved int
+	if err := tx.QueryRow(ctx, stmt, args).Scan(&owned, &moved); err != nil {
+		return 0, fmt.Errorf("failed to move todos to %s for user_id=%s: %w", status, userID, err)
+	}
+
+	if owned != len(todoIDs) {
+		code := errs.CodeTodoNotFound
+		return 0, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(todoIDs)-owned, len(todoIDs)), true, &code)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit bulk %s for user_id=%s: %w", status, userID, err)
+	}
+
+	return moved, nil
+}
+
+// BulkReopen moves all the given todos back to active and clears their
+// completion time in one statement. A non-nil newDue replaces their due dates
+// too. Either all of them belong to the user and are reopened, or none are.
+func (r *TodoRepository) BulkReopen(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	newDue *time.Time,
+) (int, error) {
+	todoIDs = uniqueIDs(todoIDs)
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create todo attachment for todo_id=%s: %w", todoID.String(), err)
+		return 0, fmt.Errorf("failed to begin bulk reopen transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	attachment, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.TodoAttachment])
+	args := pgx.NamedArgs{
+		"user_id":  userID,
+		"todo_ids": todoIDs,
+		"due_date": newDue,
+	}
+
+	setClauses := append(setStatusClauses(args, todo.StatusActive),
+		"due_date = COALESCE(@due_date::timestamptz, due_date)")
+
+	stmt := "UPDATE todos SET " + strings.Join(setClauses, ", ") + `
+		WHERE
+			id = ANY(@todo_ids::uuid[])
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+	`
+
+	result, err := tx.Exec(ctx, stmt, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:todo_attachments: %w", err)
+		return 0, fmt.Errorf("failed to reopen todos for user_id=%s: %w", userID, err)
 	}
 
-	return &attachment, nil
+	if updated := int(result.RowsAffected()); updated != len(todoIDs) {
+		code := errs.CodeTodoNotFound
+		return 0, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(todoIDs)-updated, len(todoIDs)), true, &code)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit bulk reopen for user_id=%s: %w", userID, err)
+	}
+
+	return len(todoIDs), nil
 }
 
-// CRON REQUIREMENTS
+// BulkSetPriority sets the priority of every listed todo in one statement.
+// Either all of them belong to the user and are updated, or none are.
+func (r *TodoRepository) BulkSetPriority(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	priority todo.Priority,
+) (int, error) {
+	todoIDs = uniqueIDs(todoIDs)
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin bulk priority transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-func (r *TodoRepository) GetTodosDueInHours(ctx context.Context, hours int, limit int) ([]todo.Todo, error) {
 	stmt := `
-		SELECT
-			*
-		FROM
-			todos
+		UPDATE todos
+		SET
+			priority = @priority
 		WHERE
-			due_date IS NOT NULL
-			AND due_date > NOW()
-			AND due_date <= NOW() + INTERVAL '%d hours'
-			AND status NOT IN ('completed', 'archived')
-		ORDER BY
-			due_date ASC
-		LIMIT
-			%d
+			id = ANY(@todo_ids::uuid[])
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
 	`
 
-	query := fmt.Sprintf(stmt, hours, limit)
-	rows, err := r.server.DB.Pool.Query(ctx, query)
+	result, err := tx.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":  userID,
+		"todo_ids": todoIDs,
+		"priority": priority,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get todos due in %d hours query: %w", hours, err)
+		return 0, fmt.Errorf("failed to update priority of todos for user_id=%s: %w", userID, err)
 	}
 
-	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []todo.Todo{}, nil
-		}
-		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	if updated := int(result.RowsAffected()); updated != len(todoIDs) {
+		code := errs.CodeTodoNotFound
+		return 0, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(todoIDs)-updated, len(todoIDs)), true, &code)
 	}
 
-	return todos, nil
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit bulk priority update for user_id=%s: %w", userID, err)
+	}
+
+	return len(todoIDs), nil
 }
 
-func (r *TodoRepository) GetOverdueTodos(ctx context.Context, limit int) ([]todo.Todo, error) {
+// BulkAddTag adds tag to every listed todo that doesn't carry it yet, in any
+// letter case, and returns how many it tagged
+func (r *TodoRepository) BulkAddTag(ctx context.Context, userID string, todoIDs []uuid.UUID,
+	tag string,
+) (int, error) {
 	stmt := `
-		SELECT
-			*
+		WITH
+			existing AS (
+				SELECT
+					id,
+					CASE
+						WHEN jsonb_typeof(metadata -> 'tags') = 'array' THEN metadata -> 'tags'
+						ELSE '[]'::JSONB
+					END AS tags
+				FROM
+					todos
+				WHERE
+					id = ANY(@todo_ids::uuid[])
+					AND user_id = @user_id
+					AND org_id IS NULL
+					AND deleted_at IS NULL
+			)
+		UPDATE todos t
+		SET
+			metadata = jsonb_set(
+				COALESCE(t.metadata, '{}'::JSONB),
+				'{tags}',
+				existing.tags || jsonb_build_array(@tag::TEXT)
+			)
 		FROM
-			todos
+			existing
 		WHERE
-			due_date IS NOT NULL
-			AND due_date < NOW()
-			AND status NOT IN ('completed', 'archived')
-		ORDER BY
-			due_date ASC
-		LIMIT
-			@limit
+			t.id = existing.id
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					jsonb_array_elements_text(existing.tags) AS e (tag)
+				WHERE
+					LOWER(e.tag) = LOWER(@tag::TEXT)
+			)
 	`
 
-	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"limit": limit,
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":  userID,
+		"todo_ids": uniqueIDs(todoIDs),
+		"tag":      tag,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get overdue todos query: %w", err)
-	}
-
-	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []todo.Todo{}, nil
-		}
-		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+		return 0, fmt.Errorf("failed to add tag to todos for user_id=%s: %w", userID, err)
 	}
 
-	return todos, nil
+	return int(result.RowsAffected()), nil
 }
 
-func (r *TodoRepository) GetCompletedTodosOlderThan(ctx context.Context, cutoffDate time.Time, limit int) ([]todo.Todo, error) {
+// SnoozeOverdue moves the due date of every overdue, unfinished todo the user
+// owns to dueDate, returning the moved todos with their previous due dates
+func (r *TodoRepository) SnoozeOverdue(ctx context.Context, userID string,
+	dueDate time.Time,
+) ([]todo.SnoozedTodo, error) {
 	stmt := `
-		SELECT
-			*
+		WITH
+			overdue AS (
+				SELECT
+					id,
+					due_date AS previous_due_date
+				FROM
+					todos
+				WHERE
+					user_id=@user_id
+					AND org_id IS NULL
+					AND deleted_at IS NULL
+					AND due_date < NOW()
+					AND status!='completed'
+					AND todo_due_passed(due_date, all_day, user_timezone(@user_id), user_day_start_hour(@user_id))
+				FOR UPDATE
+			)
+		UPDATE todos t
+		SET
+			due_date=@due_date
 		FROM
-			todos
+			overdue o
 		WHERE
-			status = 'completed'
-			AND completed_at IS NOT NULL
-			AND completed_at < @cutoff_date
-		ORDER BY
-			completed_at ASC
-		LIMIT
-			@limit
+			t.id=o.id
+		RETURNING
+			t.*,
+			o.previous_due_date
 	`
 
 	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
-		"cutoff_date": cutoffDate,
-		"limit":       limit,
+		"user_id":  userID,
+		"due_date": dueDate,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get completed todos older than %s query: %w", cutoffDate.Format("2006-01-02"), err)
+		return nil, fmt.Errorf("failed to snooze overdue todos for user_id=%s: %w", userID, err)
 	}
 
-	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	snoozed, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.SnoozedTodo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
+	}
+
+	return snoozed, nil
+}
+
+// setStatusClauses returns the SET clauses for moving todos to status and is
+// the one place that keeps completed_at in step with it: completing stamps
+// completed_at once, reopening clears it, and archiving leaves it untouched.
+// Every statement that changes status must go through here, or through
+// setStatusClausesAt. Whether a user's move is allowed is checked against
+// todo.StatusTransitions before that.
+func setStatusClauses(args pgx.NamedArgs, status todo.Status) []string {
+	return setStatusClausesAt(args, status, nil)
+}
+
+// setStatusClausesAt is setStatusClauses for a todo whose completion time is
+// already known, such as one carried over by an import. A todo without a
+// completed_at of its own takes completedAt when completed or archived.
+func setStatusClausesAt(args pgx.NamedArgs, status todo.Status, completedAt *time.Time) []string {
+	args["status"] = status
+	args["completed_at"] = completedAt
+
+	return []string{
+		"status = @status",
+		`completed_at = CASE
+			WHEN @status = 'completed' THEN COALESCE(completed_at, @completed_at::TIMESTAMPTZ, NOW())
+			WHEN @status = 'archived' THEN COALESCE(completed_at, @completed_at::TIMESTAMPTZ)
+			ELSE NULL
+		END`,
+	}
+}
+
+// updateTodos applies the SET clauses to the todos matching where, which may
+// refer to the table as t, and returns each of them as it was before and
+// after. The todos are locked first, so q must be a transaction for the two
+// to line up.
+func updateTodos(ctx context.Context, q querier, set []string, where string, args pgx.NamedArgs,
+) ([]todo.Change, error) {
+	rows, err := q.Query(ctx, "SELECT * FROM todos t WHERE "+where+" FOR UPDATE", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute lock todos query: %w", err)
+	}
+
+	before, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []todo.Todo{}, nil
-		}
 		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
 	}
+	if len(before) == 0 {
+		return []todo.Change{}, nil
+	}
 
-	return todos, nil
-}
+	ids := make([]uuid.UUID, len(before))
+	for i := range before {
+		ids[i] = before[i].ID
+	}
+	args["changed_ids"] = ids
 
-func (r *TodoRepository) ArchiveTodos(ctx context.Context, todoIDs []uuid.UUID) error {
-	stmt := `
-		UPDATE todos
-		SET
-			status = 'archived'
-		WHERE
-			id = ANY(@todo_ids::uuid[])
-	`
+	rows, err = q.Query(ctx, "UPDATE todos t SET "+strings.Join(set, ", ")+
+		" WHERE t.id = ANY(@changed_ids::uuid[]) RETURNING *", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute update todos query: %w", err)
+	}
 
-	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
-		"todo_ids": todoIDs,
-	})
+	after, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
 	if err != nil {
-		return fmt.Errorf("failed to archive todos: %w", err)
+		return nil, fmt.Errorf("failed to collect rows from table:todos: %w", err)
 	}
 
-	if result.RowsAffected() != int64(len(todoIDs)) {
-		return fmt.Errorf("expected to archive %d todos, but archived %d", len(todoIDs), result.RowsAffected())
+	byID := make(map[uuid.UUID]todo.Todo, len(after))
+	for _, item := range after {
+		byID[item.ID] = item
 	}
 
-	return nil
+	changes := make([]todo.Change, 0, len(before))
+	for _, item := range before {
+		if updated, ok := byID[item.ID]; ok {
+			changes = append(changes, todo.Change{Before: item, After: updated})
+		}
+	}
+
+	return changes, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
 }
 
 func (r *TodoRepository) GetWeeklyStatsForUsers(ctx context.Context, startDate, endDate time.Time) ([]todo.UserWeeklyStats, error) {
@@ -781,9 +4343,11 @@ func (r *TodoRepository) GetWeeklyStatsForUsers(ctx context.Context, startDate,
 			COUNT(*) FILTER (WHERE created_at >= @start_date AND created_at <= @end_date) AS created_count,
 			COUNT(*) FILTER (WHERE status = 'completed' AND completed_at >= @start_date AND completed_at <= @end_date) AS completed_count,
 			COUNT(*) FILTER (WHERE status NOT IN ('completed', 'archived')) AS active_count,
-			COUNT(*) FILTER (WHERE due_date < NOW() AND status NOT IN ('completed', 'archived')) AS overdue_count
+			COUNT(*) FILTER (WHERE todo_due_passed(due_date, all_day, user_timezone(user_id), user_day_start_hour(user_id)) AND status NOT IN ('completed', 'archived')) AS overdue_count
 		FROM
 			todos
+		WHERE
+			deleted_at IS NULL
 		GROUP BY
 			user_id
 		HAVING
@@ -809,6 +4373,42 @@ func (r *TodoRepository) GetWeeklyStatsForUsers(ctx context.Context, startDate,
 	return stats, nil
 }
 
+// GetWeeklyStatsForUser computes the weekly report counts for one user, with
+// zero counts when the user has no todos
+func (r *TodoRepository) GetWeeklyStatsForUser(ctx context.Context, userID string,
+	startDate, endDate time.Time,
+) (*todo.UserWeeklyStats, error) {
+	stmt := `
+		SELECT
+			@user_id::TEXT AS user_id,
+			COUNT(*) FILTER (WHERE created_at >= @start_date AND created_at <= @end_date) AS created_count,
+			COUNT(*) FILTER (WHERE status = 'completed' AND completed_at >= @start_date AND completed_at <= @end_date) AS completed_count,
+			COUNT(*) FILTER (WHERE status NOT IN ('completed', 'archived')) AS active_count,
+			COUNT(*) FILTER (WHERE todo_due_passed(due_date, all_day, user_timezone(user_id), user_day_start_hour(user_id)) AND status NOT IN ('completed', 'archived')) AS overdue_count
+		FROM
+			todos
+		WHERE
+			user_id = @user_id
+			AND deleted_at IS NULL
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":    userID,
+		"start_date": startDate,
+		"end_date":   endDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get weekly stats query for user_id=%s: %w", userID, err)
+	}
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.UserWeeklyStats])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return &stats, nil
+}
+
 func (r *TodoRepository) GetCompletedTodosForUser(ctx context.Context, userID string,
 	startDate, endDate time.Time,
 ) ([]todo.PopulatedTodo, error) {
@@ -830,6 +4430,7 @@ func (r *TodoRepository) GetCompletedTodosForUser(ctx context.Context, userID st
 				),
 				'[]'::JSONB
 			) AS children,
+			COUNT(DISTINCT child.id) AS child_count,
 			COALESCE(
 				jsonb_agg(
 					CASE
@@ -855,11 +4456,12 @@ func (r *TodoRepository) GetCompletedTodosForUser(ctx context.Context, userID st
 		FROM
 			todos t
 			LEFT JOIN todo_categories c ON c.id = t.category_id AND c.user_id = @user_id
-			LEFT JOIN todos child ON child.parent_todo_id = t.id AND child.user_id = @user_id
+			LEFT JOIN todos child ON child.parent_todo_id = t.id AND child.user_id = @user_id AND child.deleted_at IS NULL
 			LEFT JOIN todo_comments com ON com.todo_id = t.id AND com.user_id = @user_id
 			LEFT JOIN todo_attachments att ON att.todo_id=t.id
 		WHERE
 			t.user_id = @user_id
+			AND t.deleted_at IS NULL
 			AND t.status = 'completed'
 			AND t.completed_at >= @start_date
 			AND t.completed_at <= @end_date
@@ -909,6 +4511,7 @@ func (r *TodoRepository) GetOverdueTodosForUser(ctx context.Context, userID stri
 				),
 				'[]'::JSONB
 			) AS children,
+			COUNT(DISTINCT child.id) AS child_count,
 			COALESCE(
 				jsonb_agg(
 					CASE
@@ -934,12 +4537,13 @@ func (r *TodoRepository) GetOverdueTodosForUser(ctx context.Context, userID stri
 		FROM
 			todos t
 			LEFT JOIN todo_categories c ON c.id = t.category_id AND c.user_id = @user_id
-			LEFT JOIN todos child ON child.parent_todo_id = t.id AND child.user_id = @user_id
+			LEFT JOIN todos child ON child.parent_todo_id = t.id AND child.user_id = @user_id AND child.deleted_at IS NULL
 			LEFT JOIN todo_comments com ON com.todo_id = t.id AND com.user_id = @user_id
 			LEFT JOIN todo_attachments att ON att.todo_id=t.id
 		WHERE
 			t.user_id = @user_id
-			AND t.due_date < NOW()
+			AND t.deleted_at IS NULL
+			AND todo_due_passed(t.due_date, t.all_day, user_timezone(t.user_id), user_day_start_hour(t.user_id))
 			AND t.status NOT IN ('completed', 'archived')
 		GROUP BY
 			t.id, c.id
@@ -965,3 +4569,470 @@ func (r *TodoRepository) GetOverdueTodosForUser(ctx context.Context, userID stri
 
 	return overdueTodos, nil
 }
+
+// hasTrigramSupport reports whether the pg_trgm extension is installed. The
+// result is resolved once per repository.
+func (r *TodoRepository) hasTrigramSupport(ctx context.Context) bool {
+	r.trigramOnce.Do(func() {
+		stmt := `
+			SELECT
+				EXISTS (
+					SELECT
+						1
+					FROM
+						pg_extension
+					WHERE
+						extname='pg_trgm'
+				)
+		`
+
+		if err := r.server.DB.Pool.QueryRow(ctx, stmt).Scan(&r.trigramAvailable); err != nil {
+			r.server.Logger.Warn().Err(err).Msg("failed to detect pg_trgm, falling back to exact title matching")
+			r.trigramAvailable = false
+		}
+	})
+
+	return r.trigramAvailable
+}
+
+// FindSimilarTitleInCategory returns the closest non-archived todo in the same
+// category whose title matches case-insensitively or, when pg_trgm is
+// installed, has a trigram similarity of at least threshold. It returns nil
+// when there is no such todo.
+func (r *TodoRepository) FindSimilarTitleInCategory(ctx context.Context, userID string, categoryID uuid.UUID,
+	title string, excludeID *uuid.UUID, threshold float64,
+) (*todo.Todo, error) {
+	match := "LOWER(BTRIM(title))=LOWER(BTRIM(@title))"
+	orderBy := "created_at ASC"
+	if r.hasTrigramSupport(ctx) {
+		match = "(" + match + " OR similarity(title, @title)>=@threshold)"
+		orderBy = "similarity(title, @title) DESC, created_at ASC"
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			user_id=@user_id
+			AND category_id=@category_id
+			AND status!='archived'
+			AND deleted_at IS NULL
+			AND (
+				@exclude_id::UUID IS NULL
+				OR id!=@exclude_id::UUID
+			)
+			AND %s
+		ORDER BY
+			%s
+		LIMIT
+			1
+	`, match, orderBy)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"category_id": categoryID,
+		"title":       title,
+		"exclude_id":  excludeID,
+		"threshold":   threshold,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute similar title query for user_id=%s category_id=%s: %w", userID, categoryID.String(), err)
+	}
+
+	similar, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect row from table:todos for user_id=%s category_id=%s: %w", userID, categoryID.String(), err)
+	}
+
+	return &similar, nil
+}
+
+// GetRecentlyCompletedTodos returns todos completed since the given time, most
+// recent first. A deleted account has none.
+func (r *TodoRepository) GetRecentlyCompletedTodos(ctx context.Context, userID string, since time.Time,
+	limit int,
+) ([]todo.Todo, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			todos
+		WHERE
+			user_id=@user_id
+			AND deleted_at IS NULL
+			AND completed_at IS NOT NULL
+			AND completed_at>=@since
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					account_deletions
+				WHERE
+					user_id=@user_id
+			)
+		ORDER BY
+			completed_at DESC
+		LIMIT
+			@limit
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"since":   since,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute recently completed todos query for user_id=%s: %w", userID, err)
+	}
+
+	todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []todo.Todo{}, nil
+		}
+		return nil, fmt.Errorf("failed to collect rows from table:todos for user_id=%s: %w", userID, err)
+	}
+
+	return todos, nil
+}
+
+// blockedCondition matches todos waiting on a todo that is still open
+const blockedCondition = `EXISTS (
+	SELECT
+		1
+	FROM
+		todo_dependencies dep
+		JOIN todos blocker ON blocker.id=dep.blocked_by_id
+	WHERE
+		dep.todo_id=t.id
+		AND blocker.deleted_at IS NULL
+		AND blocker.status NOT IN ('completed', 'archived')
+)`
+
+// AddDependency makes the todo wait on blockedByID. Both todos are locked so
+// that links added at the same time can't close a cycle between them. Adding
+// a link that already exists changes nothing.
+func (r *TodoRepository) AddDependency(ctx context.Context, userID string, todoID, blockedByID uuid.UUID) error {
+	if todoID == blockedByID {
+		code := errs.CodeCircularReference
+		return errs.NewBadRequestError("A todo cannot depend on itself", true, &code, nil, nil)
+	}
+
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin add dependency transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id":       todoID,
+		"blocked_by_id": blockedByID,
+		"user_id":       userID,
+	}
+
+	lockStmt := `
+		SELECT
+			id
+		FROM
+			todos
+		WHERE
+			id IN (@todo_id, @blocked_by_id)
+			AND user_id=@user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		ORDER BY
+			id
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, lockStmt, args)
+	if err != nil {
+		return fmt.Errorf("failed to lock todos for dependency todo_id=%s blocked_by_id=%s: %w",
+			todoID.String(), blockedByID.String(), err)
+	}
+
+	locked, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return fmt.Errorf("failed to collect rows from table:todos for todo_id=%s blocked_by_id=%s: %w",
+			todoID.String(), blockedByID.String(), err)
+	}
+
+	if len(locked) < 2 {
+		code := errs.CodeTodoNotFound
+		return errs.NewNotFoundError("todo not found", false, &code)
+	}
+
+	// The link closes a cycle when the blocker already waits on the todo,
+	// directly or through others
+	cycleStmt := `
+		WITH RECURSIVE
+			waits_on AS (
+				SELECT
+					blocked_by_id AS id
+				FROM
+					todo_dependencies
+				WHERE
+					todo_id=@blocked_by_id
+				UNION
+				SELECT
+					d.blocked_by_id
+				FROM
+					todo_dependencies d
+					JOIN waits_on w ON d.todo_id=w.id
+			)
+		SELECT
+			EXISTS (
+				SELECT
+					1
+				FROM
+					waits_on
+				WHERE
+					id=@todo_id
+			)
+	`
+
+	var cycle bool
+	if err := tx.QueryRow(ctx, cycleStmt, args).Scan(&cycle); err != nil {
+		return fmt.Errorf("failed to check dependency cycle for todo_id=%s blocked_by_id=%s: %w",
+			todoID.String(), blockedByID.String(), err)
+	}
+
+	if cycle {
+		code := errs.CodeCircularReference
+		return errs.NewBadRequestError("The todo already blocks the todo it would depend on", true, &code, nil, nil)
+	}
+
+	insertStmt := `
+		INSERT INTO
+			todo_dependencies (todo_id, blocked_by_id, user_id)
+		VALUES
+			(@todo_id, @blocked_by_id, @user_id)
+		ON CONFLICT (todo_id, blocked_by_id) DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, insertStmt, args); err != nil {
+		return fmt.Errorf("failed to add dependency for todo_id=%s blocked_by_id=%s: %w",
+			todoID.String(), blockedByID.String(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit dependency for todo_id=%s: %w", todoID.String(), err)
+	}
+
+	return nil
+}
+
+// RemoveDependency unlinks the two todos, whichever of them waits on the other
+func (r *TodoRepository) RemoveDependency(ctx context.Context, userID string, todoID, otherID uuid.UUID) error {
+	stmt := `
+		DELETE FROM todo_dependencies
+		WHERE
+			user_id=@user_id
+			AND (
+				(
+					todo_id=@todo_id
+					AND blocked_by_id=@other_id
+				)
+				OR (
+					todo_id=@other_id
+					AND blocked_by_id=@todo_id
+				)
+			)
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"todo_id":  todoID,
+		"other_id": otherID,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove dependency between todo_id=%s and todo_id=%s: %w",
+			todoID.String(), otherID.String(), err)
+	}
+
+	if result.RowsAffected() == 0 {
+		code := errs.CodeDependencyNotFound
+		return errs.NewNotFoundError("dependency not found", false, &code)
+	}
+
+	return nil
+}
+
+// GetDependencies lists the todos the todo waits on and the todos waiting on
+// it, leaving out those in the trash
+func (r *TodoRepository) GetDependencies(ctx context.Context, userID string, todoID uuid.UUID) (*todo.Dependencies, error) {
+	linked := func(joinColumn, matchColumn string) ([]todo.Todo, error) {
+		stmt := `
+			SELECT
+				t.*
+			FROM
+				todo_dependencies dep
+				JOIN todos t ON t.id=dep.` + joinColumn + `
+			WHERE
+				dep.` + matchColumn + `=@todo_id
+				AND dep.user_id=@user_id
+				AND t.deleted_at IS NULL
+			ORDER BY
+				dep.created_at ASC
+		`
+
+		rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+			"todo_id": todoID,
+			"user_id": userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute get dependencies query for todo_id=%s: %w", todoID.String(), err)
+		}
+
+		todos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todo.Todo])
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:todos for todo_id=%s: %w", todoID.String(), err)
+		}
+
+		return todos, nil
+	}
+
+	blockedBy, err := linked("blocked_by_id", "todo_id")
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := linked("todo_id", "blocked_by_id")
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := false
+	for _, blocker := range blockedBy {
+		if blocker.Status != todo.StatusCompleted && blocker.Status != todo.StatusArchived {
+			blocked = true
+			break
+		}
+	}
+
+	return &todo.Dependencies{
+		TodoID:    todoID,
+		Blocked:   blocked,
+		BlockedBy: blockedBy,
+		Blocks:    blocks,
+	}, nil
+}
+
+// SetPosition moves the todo among its siblings, the subtasks of its parent
+// or the top-level todos of its category, and renumbers their sort_order
+// from 1 so that gaps left by earlier moves and deletions close up
+func (r *TodoRepository) SetPosition(ctx context.Context, userID string, payload *todo.SetPositionPayload,
+) (*todo.Todo, error) {
+	tx, err := r.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin set position transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	args := pgx.NamedArgs{
+		"todo_id": payload.ID,
+		"user_id": userID,
+	}
+
+	lockStmt := `
+		SELECT
+			parent_todo_id,
+			category_id
+		FROM
+			todos
+		WHERE
+			id = @todo_id
+			AND user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var parentID, categoryID *uuid.UUID
+	if err := tx.QueryRow(ctx, lockStmt, args).Scan(&parentID, &categoryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeTodoNotFound
+			return nil, errs.NewNotFoundError("todo not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to lock row from table:todos for todo_id=%s user_id=%s: %w",
+			payload.ID.String(), userID, err)
+	}
+
+	siblingCondition := "parent_todo_id = @parent_id"
+	args["parent_id"] = parentID
+	if parentID == nil {
+		siblingCondition = "parent_todo_id IS NULL AND category_id IS NOT DISTINCT FROM @category_id"
+		args["category_id"] = categoryID
+	}
+
+	siblingsStmt := `
+		SELECT
+			id
+		FROM
+			todos
+		WHERE
+			user_id = @user_id
+			AND org_id IS NULL
+			AND deleted_at IS NULL
+			AND ` + siblingCondition + `
+		ORDER BY
+			sort_order ASC,
+			created_at ASC
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, siblingsStmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute siblings query for todo_id=%s: %w", payload.ID.String(), err)
+	}
+
+	siblings, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:todos for todo_id=%s: %w", payload.ID.String(), err)
+	}
+
+	ordered, ok := todo.Reposition(siblings, payload.ID, payload)
+	if !ok {
+		code := errs.CodeInvalidPosition
+		return nil, errs.NewBadRequestError("The todo to place it next to isn't in the same list", true, &code,
+			nil, nil)
+	}
+
+	renumberStmt := `
+		UPDATE todos t
+		SET
+			sort_order = o.position
+		FROM
+			UNNEST(@ordered::uuid[]) WITH ORDINALITY AS o (id, position)
+		WHERE
+			t.id = o.id
+			AND t.sort_order != o.position
+	`
+
+	args["ordered"] = ordered
+	if _, err := tx.Exec(ctx, renumberStmt, args); err != nil {
+		return nil, fmt.Errorf("failed to renumber siblings of todo_id=%s: %w", payload.ID.String(), err)
+	}
+
+	rows, err = tx.Query(ctx, "SELECT * FROM todos WHERE id = @todo_id", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get todo query for todo_id=%s: %w", payload.ID.String(), err)
+	}
+
+	moved, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todo.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:todos for todo_id=%s user_id=%s: %w",
+			payload.ID.String(), userID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit set position for todo_id=%s: %w", payload.ID.String(), err)
+	}
+
+	return &moved, nil
+}
@@ -7,6 +7,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/model/preference"
 	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/repository"
 	testing_pkg "github.com/sriniously/tasker/internal/testing"
@@ -303,6 +311,249 @@ func TestTodoRepository_GetTodos(t *testing.T) {
 	})
 }
 
+func TestTodoRepository_GetTodosByCategories(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+
+	userID := uuid.New().String()
+	work := createTestCategory(t, ctx, categoryRepo, userID, "Work")
+	personal := createTestCategory(t, ctx, categoryRepo, userID, "Personal")
+	errands := createTestCategory(t, ctx, categoryRepo, userID, "Errands")
+
+	workTodo := createTestTodoInCategory(t, ctx, todoRepo, userID, work.ID)
+	personalTodo := createTestTodoInCategory(t, ctx, todoRepo, userID, personal.ID)
+	_ = createTestTodoInCategory(t, ctx, todoRepo, userID, errands.ID)
+	uncategorized := createTestTodo(t, ctx, todoRepo, userID)
+
+	page := 1
+	limit := 20
+
+	ids := func(result *model.PaginatedResponse[todo.PopulatedTodo]) []uuid.UUID {
+		var found []uuid.UUID
+		for _, item := range result.Data {
+			found = append(found, item.ID)
+		}
+		return found
+	}
+
+	t.Run("matches any of the listed categories", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:        &page,
+			Limit:       &limit,
+			CategoryIDs: []uuid.UUID{work.ID, personal.ID},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{workTodo.ID, personalTodo.ID}, ids(result))
+		assert.Equal(t, 2, result.Total)
+	})
+
+	t.Run("includes uncategorized todos in the union", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:                 &page,
+			Limit:                &limit,
+			CategoryIDs:          []uuid.UUID{work.ID, personal.ID},
+			IncludeUncategorized: testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{workTodo.ID, personalTodo.ID, uncategorized.ID}, ids(result))
+		assert.Equal(t, 3, result.Total)
+	})
+
+	t.Run("uncategorized alone matches only todos without a category", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:                 &page,
+			Limit:                &limit,
+			IncludeUncategorized: testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{uncategorized.ID}, ids(result))
+	})
+
+	t.Run("combines with the single category filter", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:                 &page,
+			Limit:                &limit,
+			CategoryID:           &work.ID,
+			CategoryIDs:          []uuid.UUID{work.ID, personal.ID},
+			IncludeUncategorized: testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{workTodo.ID}, ids(result))
+	})
+}
+
+func TestTodoRepository_GetTodosByCursor(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	created := createTestTodos(t, ctx, todoRepo, userID, 5)
+
+	// Two todos share a creation time so the id has to break the tie
+	_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET created_at = $1 WHERE id = ANY($2)",
+		created[2].CreatedAt, []uuid.UUID{created[2].ID, created[3].ID})
+	require.NoError(t, err)
+
+	page := func(t *testing.T, cursor string, order string) *model.PaginatedResponse[todo.PopulatedTodo] {
+		t.Helper()
+		limit := 2
+		query := &todo.GetTodosQuery{Cursor: &cursor, Limit: &limit, Order: &order}
+		result, err := todoRepo.GetTodos(ctx, userID, query)
+		require.NoError(t, err)
+		return result
+	}
+
+	walk := func(t *testing.T, order string, between func()) []uuid.UUID {
+		t.Helper()
+		var seen []uuid.UUID
+		cursor := ""
+		for {
+			result := page(t, cursor, order)
+			for _, item := range result.Data {
+				seen = append(seen, item.ID)
+			}
+			if result.NextCursor == nil {
+				return seen
+			}
+			cursor = *result.NextCursor
+			if between != nil {
+				between()
+			}
+		}
+	}
+
+	t.Run("pages cover every todo once in both directions", func(t *testing.T) {
+		desc := walk(t, "desc", nil)
+		asc := walk(t, "asc", nil)
+
+		require.Len(t, desc, 5)
+		assert.ElementsMatch(t, desc, asc)
+		for i := range desc {
+			assert.Equal(t, desc[i], asc[len(asc)-1-i])
+		}
+	})
+
+	t.Run("todos created while paging don't shift later pages", func(t *testing.T) {
+		before := walk(t, "desc", nil)
+		seen := walk(t, "desc", func() {
+			createTestTodo(t, ctx, todoRepo, userID)
+		})
+
+		// Newer todos land ahead of the cursor, so the pages read no
+		// todo twice and miss none of the ones listed at the start
+		assert.Equal(t, before, seen)
+	})
+
+	t.Run("cursor pages aren't counted", func(t *testing.T) {
+		result := page(t, "", "desc")
+		assert.NotNil(t, result.NextCursor)
+		assert.Zero(t, result.Total)
+		assert.Zero(t, result.Page)
+	})
+}
+
+func TestTodoRepository_GetTodosSkipsUnreadableRows(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+	broken := todos[1]
+
+	child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:        "Broken subtask",
+		ParentTodoID: &broken.ID,
+	})
+	require.NoError(t, err)
+
+	// Simulate a bad manual edit: tags must be a list of strings
+	_, err = testServer.DB.Pool.Exec(ctx, `UPDATE todos SET metadata = '{"tags": 5}' WHERE id = $1`, child.ID)
+	require.NoError(t, err)
+
+	query := &todo.GetTodosQuery{}
+	require.NoError(t, query.Validate())
+
+	t.Run("lenient mode returns the readable todos and counts the rest", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, query)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Skipped)
+		require.Len(t, result.Data, 2)
+		for _, item := range result.Data {
+			assert.NotEqual(t, broken.ID, item.ID)
+		}
+		assert.Equal(t, 3, result.Total)
+	})
+
+	t.Run("strict mode fails the listing", func(t *testing.T) {
+		testServer.Config.Todo = &config.TodoConfig{StrictListHydration: true}
+		defer func() { testServer.Config.Todo = nil }()
+
+		_, err := todoRepo.GetTodos(ctx, userID, query)
+		require.Error(t, err)
+	})
+}
+
+func TestTodoRepository_ReadsMalformedMetadata(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	var reported []string
+	original := todo.ReportRepairedMetadata
+	todo.ReportRepairedMetadata = func(raw []byte, problems []string) {
+		reported = append(reported, problems...)
+	}
+	defer func() { todo.ReportRepairedMetadata = original }()
+
+	testTodo := createTestTodo(t, ctx, todoRepo, userID)
+
+	// Simulate a bad manual edit of the top-level todo's metadata
+	_, err := testServer.DB.Pool.Exec(ctx,
+		`UPDATE todos SET metadata = '{"tags": "work, home", "difficulty": "hard", "color": 7}' WHERE id = $1`,
+		testTodo.ID)
+	require.NoError(t, err)
+
+	t.Run("a single todo loads with the fields coerced", func(t *testing.T) {
+		reported = nil
+
+		result, err := todoRepo.GetTodoByID(ctx, userID, testTodo.ID)
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Metadata)
+		assert.Equal(t, []string{"work", "home"}, result.Metadata.Tags)
+		assert.Nil(t, result.Metadata.Difficulty)
+		assert.Nil(t, result.Metadata.Color)
+		assert.Contains(t, reported, "tags: stored as a string, split on commas")
+		assert.Contains(t, reported, "difficulty: not a number, dropped")
+	})
+
+	t.Run("the listing keeps the todo instead of skipping it", func(t *testing.T) {
+		query := &todo.GetTodosQuery{}
+		require.NoError(t, query.Validate())
+
+		result, err := todoRepo.GetTodos(ctx, userID, query)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, result.Skipped)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, []string{"work", "home"}, result.Data[0].Metadata.Tags)
+	})
+}
+
 func TestTodoRepository_UpdateTodo(t *testing.T) {
 	_, testServer, cleanup := testing_pkg.SetupTest(t)
 	defer cleanup()
@@ -402,6 +653,166 @@ func TestTodoRepository_UpdateTodo(t *testing.T) {
 	})
 }
 
+func TestTodoRepository_Checklist(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	t.Run("new todos start with an empty checklist", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		assert.Empty(t, item.Checklist)
+		assert.Equal(t, 0, item.ChecklistTotal)
+	})
+
+	t.Run("ticking an item off updates the progress", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		_, err := todoRepo.AddChecklistItem(ctx, userID, item.ID, "Draft")
+		require.NoError(t, err)
+		withItems, err := todoRepo.AddChecklistItem(ctx, userID, item.ID, "Review")
+		require.NoError(t, err)
+		require.Len(t, withItems.Checklist, 2)
+		assert.Equal(t, 0, withItems.ChecklistDone)
+		assert.Equal(t, 2, withItems.ChecklistTotal)
+
+		ticked, err := todoRepo.UpdateChecklistItem(ctx, userID, item.ID, withItems.Checklist[0].ID,
+			nil, testing_pkg.Ptr(true))
+		require.NoError(t, err)
+		assert.True(t, ticked.Checklist[0].Done)
+		assert.Equal(t, "Draft", ticked.Checklist[0].Text)
+		assert.False(t, ticked.Checklist[1].Done)
+		assert.Equal(t, 1, ticked.ChecklistDone)
+
+		untick, err := todoRepo.UpdateChecklistItem(ctx, userID, item.ID, withItems.Checklist[0].ID,
+			testing_pkg.Ptr("Write draft"), testing_pkg.Ptr(false))
+		require.NoError(t, err)
+		assert.Equal(t, "Write draft", untick.Checklist[0].Text)
+		assert.Equal(t, 0, untick.ChecklistDone)
+
+		removed, err := todoRepo.RemoveChecklistItem(ctx, userID, item.ID, withItems.Checklist[0].ID)
+		require.NoError(t, err)
+		require.Len(t, removed.Checklist, 1)
+		assert.Equal(t, "Review", removed.Checklist[0].Text)
+		assert.Equal(t, 1, removed.ChecklistTotal)
+	})
+
+	t.Run("unknown items are reported as missing", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		_, err := todoRepo.UpdateChecklistItem(ctx, userID, item.ID, uuid.New(), nil, testing_pkg.Ptr(true))
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeChecklistItemNotFound, httpErr.Code)
+
+		_, err = todoRepo.RemoveChecklistItem(ctx, userID, item.ID, uuid.New())
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeChecklistItemNotFound, httpErr.Code)
+	})
+
+	t.Run("concurrent edits to different items are all kept", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		const items = 10
+		var checklist []todo.ChecklistItem
+		for i := 0; i < items; i++ {
+			updated, err := todoRepo.AddChecklistItem(ctx, userID, item.ID, fmt.Sprintf("Step %d", i+1))
+			require.NoError(t, err)
+			checklist = updated.Checklist
+		}
+
+		// Tick every item off while more items are added, all at once
+		errCh := make(chan error, items*2)
+		for _, entry := range checklist {
+			go func(itemID uuid.UUID) {
+				_, err := todoRepo.UpdateChecklistItem(ctx, userID, item.ID, itemID, nil, testing_pkg.Ptr(true))
+				errCh <- err
+			}(entry.ID)
+			go func(text string) {
+				_, err := todoRepo.AddChecklistItem(ctx, userID, item.ID, text)
+				errCh <- err
+			}(entry.Text + " follow-up")
+		}
+		for i := 0; i < items*2; i++ {
+			require.NoError(t, <-errCh)
+		}
+
+		final, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, items*2, final.ChecklistTotal)
+		assert.Equal(t, items, final.ChecklistDone)
+		for _, entry := range final.Checklist[:items] {
+			assert.True(t, entry.Done, entry.Text)
+		}
+	})
+
+	t.Run("the checklist is capped", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		_, err := testServer.DB.Pool.Exec(ctx, `
+			UPDATE todos
+			SET checklist = (
+				SELECT jsonb_agg(jsonb_build_object('id', gen_random_uuid(), 'text', 'Step', 'done', FALSE))
+				FROM generate_series(1, $2)
+			)
+			WHERE id = $1
+		`, item.ID, todo.MaxChecklistItems)
+		require.NoError(t, err)
+
+		_, err = todoRepo.AddChecklistItem(ctx, userID, item.ID, "One too many")
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeChecklistFull, httpErr.Code)
+	})
+}
+
+func TestTodoRepository_BumpTodo(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+	oldest := todos[0]
+
+	bumped, err := todoRepo.BumpTodo(ctx, userID, oldest.ID)
+	require.NoError(t, err)
+
+	t.Run("only updated_at changes", func(t *testing.T) {
+		assert.True(t, bumped.UpdatedAt.After(oldest.UpdatedAt))
+
+		before, after := *oldest, *bumped
+		before.UpdatedAt, after.UpdatedAt = time.Time{}, time.Time{}
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("bumped todo leads a recently updated listing", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(20),
+			Sort:  testing_pkg.Ptr("updated_at"),
+			Order: testing_pkg.Ptr("desc"),
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Data)
+		assert.Equal(t, oldest.ID, result.Data[0].ID)
+	})
+
+	t.Run("someone else's todo is not found", func(t *testing.T) {
+		_, err := todoRepo.BumpTodo(ctx, uuid.New().String(), oldest.ID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+	})
+}
+
 func TestTodoRepository_DeleteTodo(t *testing.T) {
 	_, testServer, cleanup := testing_pkg.SetupTest(t)
 	defer cleanup()
@@ -442,6 +853,116 @@ func TestTodoRepository_DeleteTodo(t *testing.T) {
 	})
 }
 
+func TestTodoRepository_Trash(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	parent := createTestTodo(t, ctx, todoRepo, userID)
+	child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:        "Subtask",
+		ParentTodoID: &parent.ID,
+	})
+	require.NoError(t, err)
+
+	trashIDs := func(t *testing.T) []uuid.UUID {
+		t.Helper()
+		page, limit := 1, 20
+		trash, err := todoRepo.GetTrash(ctx, userID, &todo.GetTrashQuery{Page: &page, Limit: &limit})
+		require.NoError(t, err)
+		assert.Equal(t, len(trash.Data), trash.Total)
+
+		var ids []uuid.UUID
+		for _, item := range trash.Data {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	t.Run("deleting moves the todo and its subtasks to the trash", func(t *testing.T) {
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, parent.ID))
+
+		_, err := todoRepo.GetTodoByID(ctx, userID, parent.ID)
+		require.Error(t, err)
+		_, err = todoRepo.CheckTodoExists(ctx, userID, child.ID)
+		require.Error(t, err)
+
+		// The subtask went with its parent and isn't listed on its own
+		assert.Equal(t, []uuid.UUID{parent.ID}, trashIDs(t))
+
+		err = todoRepo.DeleteTodo(ctx, userID, parent.ID)
+		assert.Contains(t, err.Error(), "todo not found")
+	})
+
+	t.Run("a subtask can't be restored before its parent", func(t *testing.T) {
+		_, err := todoRepo.RestoreTodo(ctx, userID, child.ID)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeParentInTrash, httpErr.Code)
+	})
+
+	t.Run("restoring brings back the subtasks deleted with it", func(t *testing.T) {
+		restored, err := todoRepo.RestoreTodo(ctx, userID, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parent.ID, restored.ID)
+		assert.Nil(t, restored.DeletedAt)
+
+		_, err = todoRepo.CheckTodoExists(ctx, userID, child.ID)
+		require.NoError(t, err)
+		assert.Empty(t, trashIDs(t))
+
+		_, err = todoRepo.RestoreTodo(ctx, userID, parent.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("a subtask deleted earlier stays in the trash", func(t *testing.T) {
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, child.ID))
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, parent.ID))
+		assert.ElementsMatch(t, []uuid.UUID{parent.ID, child.ID}, trashIDs(t))
+
+		_, err := todoRepo.RestoreTodo(ctx, userID, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{child.ID}, trashIDs(t))
+
+		_, err = todoRepo.RestoreTodo(ctx, userID, child.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("purging removes the todo, its subtasks and their attachments", func(t *testing.T) {
+		_, err := todoRepo.PurgeTodo(ctx, userID, parent.ID)
+		require.Error(t, err, "only todos in the trash can be purged")
+
+		_, err = todoRepo.UploadTodoAttachment(ctx, child.ID, userID, "notes.txt_1700000000", "notes.txt", 12,
+			"text/plain")
+		require.NoError(t, err)
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, parent.ID))
+
+		due, err := todoRepo.GetTrashDueForPurge(ctx, time.Now().Add(time.Minute), 100)
+		require.NoError(t, err)
+		var dueIDs []uuid.UUID
+		for _, d := range due {
+			dueIDs = append(dueIDs, d.ID)
+		}
+		assert.Contains(t, dueIDs, parent.ID)
+		assert.NotContains(t, dueIDs, child.ID)
+
+		keys, err := todoRepo.PurgeTodo(ctx, userID, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"notes.txt_1700000000"}, keys)
+		assert.Empty(t, trashIDs(t))
+
+		var remaining int
+		err = testServer.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM todos WHERE id = ANY($1)",
+			[]uuid.UUID{parent.ID, child.ID}).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+	})
+}
+
 func TestTodoRepository_GetTodoStats(t *testing.T) {
 	_, testServer, cleanup := testing_pkg.SetupTest(t)
 	defer cleanup()
@@ -474,42 +995,2242 @@ func TestTodoRepository_GetTodoStats(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, result)
 	})
-}
 
-func createTestTodo(t *testing.T, ctx context.Context, repo *repository.TodoRepository, userID string) *todo.Todo {
-	t.Helper()
+	t.Run("summary matches a live scan after mutations", func(t *testing.T) {
+		userID := uuid.New().String()
+		todos := createTestTodos(t, ctx, todoRepo, userID, 5)
 
-	dueDate := time.Now().Add(24 * time.Hour)
-	payload := &todo.CreateTodoPayload{
-		Title:       "Test Todo",
-		Description: testing_pkg.Ptr("Test todo description"),
-		Priority:    testing_pkg.Ptr(todo.PriorityHigh),
-		DueDate:     &dueDate,
-	}
+		_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{todos[0].ID}, todo.StatusCompleted)
+		require.NoError(t, err)
+		_, err = todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{todos[1].ID, todos[2].ID}, todo.StatusActive)
+		require.NoError(t, err)
+		_, err = testServer.DB.Pool.Exec(ctx,
+			"UPDATE todos SET status = 'archived', due_date = $1 WHERE id = $2", time.Now().Add(-48*time.Hour), todos[2].ID)
+		require.NoError(t, err)
+		_, err = testServer.DB.Pool.Exec(ctx,
+			"UPDATE todos SET due_date = $1 WHERE id = $2", time.Now().Add(-48*time.Hour), todos[1].ID)
+		require.NoError(t, err)
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, todos[4].ID))
 
-	result, err := repo.CreateTodo(ctx, userID, payload)
-	require.NoError(t, err)
+		summary, err := todoRepo.GetTodoStats(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.TodoStats{Total: 4, Draft: 1, Active: 1, Completed: 1, Archived: 1, Overdue: 2}, *summary)
 
-	return result
+		// Without a summary row the stats come from the live scan
+		_, err = testServer.DB.Pool.Exec(ctx, "DELETE FROM todo_stats_summary WHERE user_id = $1", userID)
+		require.NoError(t, err)
+
+		live, err := todoRepo.GetTodoStats(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, *summary, *live)
+	})
+
+	t.Run("user without todos", func(t *testing.T) {
+		result, err := todoRepo.GetTodoStats(ctx, uuid.New().String())
+		require.NoError(t, err)
+		assert.Equal(t, todo.TodoStats{}, *result)
+	})
 }
 
-func createTestTodos(t *testing.T, ctx context.Context, repo *repository.TodoRepository, userID string, count int) []*todo.Todo {
-	t.Helper()
+func TestTodoRepository_ArchiveCategoryTodos(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
 
-	todos := make([]*todo.Todo, 0, count)
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
 
-	for i := 0; i < count; i++ {
-		dueDate := time.Now().Add(time.Duration(i+1) * 24 * time.Hour)
-		payload := &todo.CreateTodoPayload{
-			Title:       fmt.Sprintf("Test Todo %d", i+1),
-			Description: testing_pkg.Ptr(fmt.Sprintf("Test todo description %d", i+1)),
-			Priority:    testing_pkg.Ptr(todo.PriorityHigh),
-			DueDate:     &dueDate,
+	userID := uuid.New().String()
+
+	t.Run("archive all todos in category", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Project A")
+		other := createTestCategory(t, ctx, categoryRepo, userID, "Project B")
+
+		for i := 0; i < 3; i++ {
+			createTestTodoInCategory(t, ctx, todoRepo, userID, project.ID)
 		}
+		otherTodo := createTestTodoInCategory(t, ctx, todoRepo, userID, other.ID)
 
-		result, err := repo.CreateTodo(ctx, userID, payload)
+		archived, err := todoRepo.ArchiveCategoryTodos(ctx, userID, project.ID, false)
 		require.NoError(t, err)
-		todos = append(todos, result)
+		require.Len(t, archived, 3)
+		for _, change := range archived {
+			assert.Equal(t, todo.StatusDraft, change.Before.Status)
+			assert.Equal(t, todo.StatusArchived, change.After.Status)
+		}
+
+		untouched, err := todoRepo.CheckTodoExists(ctx, userID, otherTodo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, untouched.Status)
+	})
+
+	t.Run("archive only completed todos in category", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Project C")
+
+		completed := createTestTodoInCategory(t, ctx, todoRepo, userID, project.ID)
+		open := createTestTodoInCategory(t, ctx, todoRepo, userID, project.ID)
+
+		status := todo.StatusCompleted
+		_, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{ID: completed.ID, Status: &status})
+		require.NoError(t, err)
+
+		changes, err := todoRepo.ArchiveCategoryTodos(ctx, userID, project.ID, true)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, completed.ID, changes[0].After.ID)
+
+		archived, err := todoRepo.CheckTodoExists(ctx, userID, completed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusArchived, archived.Status)
+
+		stillOpen, err := todoRepo.CheckTodoExists(ctx, userID, open.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, stillOpen.Status)
+	})
+
+	t.Run("organization todos in the category are untouched", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Project E")
+		org, err := repository.NewOrganizationRepository(testServer).CreateOrganization(ctx, userID,
+			&organization.CreateOrganizationPayload{Name: "Team"})
+		require.NoError(t, err)
+
+		orgTodo, err := todoRepo.CreateOrgTodo(ctx, userID, org.ID, &todo.CreateTodoPayload{
+			Title:      "Shared",
+			CategoryID: &project.ID,
+		})
+		require.NoError(t, err)
+
+		archived, err := todoRepo.ArchiveCategoryTodos(ctx, userID, project.ID, false)
+		require.NoError(t, err)
+		assert.Empty(t, archived)
+
+		untouched, err := todoRepo.CheckOrgTodoExists(ctx, org.ID, orgTodo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, untouched.Status)
+	})
+
+	t.Run("category of another user is untouched", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Project D")
+		createTestTodoInCategory(t, ctx, todoRepo, userID, project.ID)
+
+		archived, err := todoRepo.ArchiveCategoryTodos(ctx, uuid.New().String(), project.ID, false)
+		require.NoError(t, err)
+		assert.Empty(t, archived)
+	})
+}
+
+func TestTodoRepository_AllDayOverdue(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	preferenceRepo := repository.NewPreferenceRepository(testServer)
+
+	userID := uuid.New().String()
+	_, err := preferenceRepo.UpsertPreferences(ctx, userID, &preference.UpdatePreferencesPayload{
+		Timezone: testing_pkg.Ptr("Asia/Tokyo"),
+	})
+	require.NoError(t, err)
+
+	justPassed := time.Now().Add(-time.Second)
+	yesterday := time.Now().Add(-48 * time.Hour)
+
+	timed, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:   "Timed",
+		DueDate: &justPassed,
+	})
+	require.NoError(t, err)
+
+	allDayToday, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:   "All day today",
+		DueDate: &justPassed,
+		AllDay:  testing_pkg.Ptr(true),
+	})
+	require.NoError(t, err)
+	assert.True(t, allDayToday.AllDay)
+
+	allDayPast, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:   "All day yesterday",
+		DueDate: &yesterday,
+		AllDay:  testing_pkg.Ptr(true),
+	})
+	require.NoError(t, err)
+
+	result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+		Page:    testing_pkg.Ptr(1),
+		Limit:   testing_pkg.Ptr(20),
+		Overdue: testing_pkg.Ptr(true),
+	})
+	require.NoError(t, err)
+
+	ids := make([]uuid.UUID, 0, len(result.Data))
+	for _, item := range result.Data {
+		ids = append(ids, item.ID)
+	}
+	assert.Contains(t, ids, timed.ID)
+	assert.Contains(t, ids, allDayPast.ID)
+	assert.NotContains(t, ids, allDayToday.ID)
+
+	stats, err := todoRepo.GetTodoStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Overdue)
+}
+
+func TestTodoRepository_FindSimilarTitleInCategory(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+
+	userID := uuid.New().String()
+	groceries := createTestCategory(t, ctx, categoryRepo, userID, "Groceries")
+	chores := createTestCategory(t, ctx, categoryRepo, userID, "Chores")
+
+	existing, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:      "Buy milk",
+		CategoryID: &groceries.ID,
+	})
+	require.NoError(t, err)
+
+	t.Run("near-duplicate title in the same category matches", func(t *testing.T) {
+		similar, err := todoRepo.FindSimilarTitleInCategory(ctx, userID, groceries.ID, " BUY MILK ", nil, 0.6)
+		require.NoError(t, err)
+		require.NotNil(t, similar)
+		assert.Equal(t, existing.ID, similar.ID)
+	})
+
+	t.Run("distinct title does not match", func(t *testing.T) {
+		similar, err := todoRepo.FindSimilarTitleInCategory(ctx, userID, groceries.ID, "Call the plumber", nil, 0.6)
+		require.NoError(t, err)
+		assert.Nil(t, similar)
+	})
+
+	t.Run("same title in another category does not match", func(t *testing.T) {
+		similar, err := todoRepo.FindSimilarTitleInCategory(ctx, userID, chores.ID, "Buy milk", nil, 0.6)
+		require.NoError(t, err)
+		assert.Nil(t, similar)
+	})
+
+	t.Run("todo does not match itself", func(t *testing.T) {
+		similar, err := todoRepo.FindSimilarTitleInCategory(ctx, userID, groceries.ID, "Buy milk", &existing.ID, 0.6)
+		require.NoError(t, err)
+		assert.Nil(t, similar)
+	})
+}
+
+func TestTodoRepository_GetRecentlyCompletedTodos(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	todos := createTestTodos(t, ctx, todoRepo, userID, 4)
+
+	status := todo.StatusCompleted
+	completedOrder := []uuid.UUID{}
+	for _, item := range todos[:3] {
+		_, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{ID: item.ID, Status: &status})
+		require.NoError(t, err)
+		completedOrder = append(completedOrder, item.ID)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result, err := todoRepo.GetRecentlyCompletedTodos(ctx, userID, time.Now().Add(-time.Hour), 50)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	// Most recently completed first
+	assert.Equal(t, completedOrder[2], result[0].ID)
+	assert.Equal(t, completedOrder[1], result[1].ID)
+	assert.Equal(t, completedOrder[0], result[2].ID)
+
+	t.Run("todos completed before the window are excluded", func(t *testing.T) {
+		result, err := todoRepo.GetRecentlyCompletedTodos(ctx, userID, time.Now().Add(time.Hour), 50)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestTodoRepository_BulkReparent(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	t.Run("nests several todos under a new parent", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent := createTestTodo(t, ctx, todoRepo, userID)
+		todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+
+		ids := []uuid.UUID{todos[0].ID, todos[1].ID, todos[2].ID}
+		updated, err := todoRepo.BulkReparent(ctx, userID, ids, &parent.ID, config.DefaultMaxChildren)
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated)
+
+		moved, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+		require.Len(t, moved, 3)
+		for _, item := range moved {
+			require.NotNil(t, item.ParentTodoID)
+			assert.Equal(t, parent.ID, *item.ParentTodoID)
+		}
+	})
+
+	t.Run("rejects the whole batch when one move creates a cycle", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent := createTestTodo(t, ctx, todoRepo, userID)
+		child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Child Todo",
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+		loose := createTestTodo(t, ctx, todoRepo, userID)
+
+		// Moving the parent under its own child would make it its own ancestor
+		_, err = todoRepo.BulkReparent(ctx, userID, []uuid.UUID{loose.ID, parent.ID}, &child.ID, config.DefaultMaxChildren)
+		require.Error(t, err)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeCircularReference, httpErr.Code)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, loose.ID)
+		require.NoError(t, err)
+		assert.Nil(t, unchanged.ParentTodoID)
+	})
+
+	t.Run("rejects moves deeper than the maximum depth", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent := createTestTodo(t, ctx, todoRepo, userID)
+		child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Child Todo",
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+		loose := createTestTodo(t, ctx, todoRepo, userID)
+
+		_, err = todoRepo.BulkReparent(ctx, userID, []uuid.UUID{loose.ID}, &child.ID, config.DefaultMaxChildren)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeMaxDepthExceeded, httpErr.Code)
+	})
+
+	t.Run("nil parent promotes todos to root", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent := createTestTodo(t, ctx, todoRepo, userID)
+		child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Child Todo",
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+
+		updated, err := todoRepo.BulkReparent(ctx, userID, []uuid.UUID{child.ID}, nil, config.DefaultMaxChildren)
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated)
+
+		promoted, err := todoRepo.CheckTodoExists(ctx, userID, child.ID)
+		require.NoError(t, err)
+		assert.Nil(t, promoted.ParentTodoID)
+	})
+
+	t.Run("todos of another user are not found", func(t *testing.T) {
+		userID := uuid.New().String()
+		other := createTestTodo(t, ctx, todoRepo, uuid.New().String())
+
+		_, err := todoRepo.BulkReparent(ctx, userID, []uuid.UUID{other.ID}, nil, config.DefaultMaxChildren)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+	})
+}
+
+func TestTodoRepository_CompletedAtInvariant(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	complete := func(t *testing.T, userID string, todoID uuid.UUID) {
+		t.Helper()
+
+		completed, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:     todoID,
+			Status: testing_pkg.Ptr(todo.StatusCompleted),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, completed.CompletedAt)
+	}
+
+	t.Run("reopening via update clears completed_at", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		complete(t, userID, item.ID)
+
+		reopened, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:     item.ID,
+			Status: testing_pkg.Ptr(todo.StatusActive),
+		})
+		require.NoError(t, err)
+		assert.Nil(t, reopened.CompletedAt)
+	})
+
+	t.Run("reopening via bulk status clears completed_at", func(t *testing.T) {
+		userID := uuid.New().String()
+		todos := createTestTodos(t, ctx, todoRepo, userID, 2)
+		for _, item := range todos {
+			complete(t, userID, item.ID)
+		}
+
+		ids := []uuid.UUID{todos[0].ID, todos[1].ID}
+		updated, err := todoRepo.BulkUpdateStatus(ctx, userID, ids, todo.StatusActive)
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated)
+
+		reopened, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+		require.Len(t, reopened, 2)
+		for _, item := range reopened {
+			assert.Equal(t, todo.StatusActive, item.Status)
+			assert.Nil(t, item.CompletedAt)
+		}
+	})
+
+	t.Run("archiving keeps completed_at", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		complete(t, userID, item.ID)
+
+		_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{item.ID}, todo.StatusArchived)
+		require.NoError(t, err)
+
+		archived, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, archived.CompletedAt)
+	})
+
+	t.Run("database trigger backs up writes that skip the repository", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		complete(t, userID, item.ID)
+
+		_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET status = 'draft' WHERE id = $1", item.ID)
+		require.NoError(t, err)
+
+		reopened, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Nil(t, reopened.CompletedAt)
+	})
+
+	t.Run("bulk status rejects the batch when a todo is missing", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{item.ID, uuid.New()}, todo.StatusCompleted)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, unchanged.Status)
+	})
+}
+
+func TestTodoRepository_DeferUntil(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	visible := createTestTodo(t, ctx, todoRepo, userID)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	deferred, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:      "Start taxes",
+		DeferUntil: &tomorrow,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, deferred.DeferUntil)
+
+	ids := func(todos []todo.PopulatedTodo) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(todos))
+		for _, item := range todos {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	t.Run("todo deferred to tomorrow is hidden by default", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(20),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Total)
+		assert.Contains(t, ids(result.Data), visible.ID)
+		assert.NotContains(t, ids(result.Data), deferred.ID)
+	})
+
+	t.Run("include deferred flag shows it", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:            testing_pkg.Ptr(1),
+			Limit:           testing_pkg.Ptr(20),
+			IncludeDeferred: testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.Total)
+		assert.Contains(t, ids(result.Data), deferred.ID)
+	})
+
+	t.Run("deferred list holds only hidden todos", func(t *testing.T) {
+		result, err := todoRepo.GetDeferredTodos(ctx, userID)
+		require.NoError(t, err)
+
+		require.Len(t, result, 1)
+		assert.Equal(t, deferred.ID, result[0].ID)
+	})
+
+	t.Run("todo reappears once its defer date passes", func(t *testing.T) {
+		yesterday := time.Now().Add(-24 * time.Hour)
+		_, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:         deferred.ID,
+			DeferUntil: &yesterday,
+		})
+		require.NoError(t, err)
+
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(20),
+		})
+		require.NoError(t, err)
+		assert.Contains(t, ids(result.Data), deferred.ID)
+	})
+}
+
+func TestTodoRepository_GetStaleTodos(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	// backdate bypasses the updated_at trigger so a todo can look untouched
+	backdate := func(t *testing.T, todoID uuid.UUID, updatedAt time.Time) {
+		t.Helper()
+
+		tx, err := testServer.DB.Pool.Begin(ctx)
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		_, err = tx.Exec(ctx, "SET LOCAL session_replication_role = replica")
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "UPDATE todos SET updated_at = $1 WHERE id = $2", updatedAt, todoID)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+	}
+
+	userID := uuid.New().String()
+	todos := createTestTodos(t, ctx, todoRepo, userID, 4)
+	untouched, older, recent, archived := todos[0], todos[1], todos[2], todos[3]
+
+	backdate(t, untouched.ID, time.Now().Add(-20*24*time.Hour))
+	backdate(t, older.ID, time.Now().Add(-30*24*time.Hour))
+	_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET status = 'archived' WHERE id = $1", archived.ID)
+	require.NoError(t, err)
+	backdate(t, archived.ID, time.Now().Add(-30*24*time.Hour))
+
+	result, err := todoRepo.GetStaleTodos(ctx, userID, 14*24*time.Hour)
+	require.NoError(t, err)
+
+	t.Run("untouched todos appear oldest first", func(t *testing.T) {
+		require.Len(t, result, 2)
+		assert.Equal(t, older.ID, result[0].ID)
+		assert.Equal(t, untouched.ID, result[1].ID)
+	})
+
+	t.Run("recently updated and archived todos are left out", func(t *testing.T) {
+		for _, item := range result {
+			assert.NotEqual(t, recent.ID, item.ID)
+			assert.NotEqual(t, archived.ID, item.ID)
+		}
+	})
+
+	t.Run("recent activity keeps a todo fresh", func(t *testing.T) {
+		activityRepo := repository.NewActivityRepository(testServer)
+		_, err := activityRepo.CreateActivity(ctx, &activity.Activity{
+			TodoID:  untouched.ID,
+			UserID:  userID,
+			ActorID: userID,
+			Action:  activity.ActionUpdated,
+			Changes: activity.Changes{},
+		})
+		require.NoError(t, err)
+
+		stale, err := todoRepo.GetStaleTodos(ctx, userID, 14*24*time.Hour)
+		require.NoError(t, err)
+		require.Len(t, stale, 1)
+		assert.Equal(t, older.ID, stale[0].ID)
+	})
+}
+
+func TestTodoRepository_GetIncompleteTodos(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	dueDate := time.Now().Add(24 * time.Hour)
+	complete := createTestTodo(t, ctx, todoRepo, userID)
+
+	noDueDate, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:       "No due date",
+		Description: testing_pkg.Ptr("Has a description"),
+	})
+	require.NoError(t, err)
+
+	blankDescription, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:       "Blank description",
+		Description: testing_pkg.Ptr("   "),
+		DueDate:     &dueDate,
+	})
+	require.NoError(t, err)
+
+	ids := func(t *testing.T, missing ...todo.MissingField) []uuid.UUID {
+		t.Helper()
+
+		todos, err := todoRepo.GetIncompleteTodos(ctx, userID, missing)
+		require.NoError(t, err)
+
+		ids := make([]uuid.UUID, 0, len(todos))
+		for _, item := range todos {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	t.Run("missing due date returns only todos without one", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{noDueDate.ID}, ids(t, todo.MissingDueDate))
+	})
+
+	t.Run("blank descriptions count as missing", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{blankDescription.ID}, ids(t, todo.MissingDescription))
+	})
+
+	t.Run("several fields match todos missing any of them", func(t *testing.T) {
+		found := ids(t, todo.MissingDueDate, todo.MissingDescription)
+		assert.ElementsMatch(t, []uuid.UUID{noDueDate.ID, blankDescription.ID}, found)
+		assert.NotContains(t, found, complete.ID)
+	})
+
+	t.Run("unknown field is an error", func(t *testing.T) {
+		_, err := todoRepo.GetIncompleteTodos(ctx, userID, []todo.MissingField{"title"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTodoRepository_CompleteWithFollowUp(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	t.Run("completes the todo and links the follow-up", func(t *testing.T) {
+		userID := uuid.New().String()
+		original := createTestTodo(t, ctx, todoRepo, userID)
+
+		completed, followUp, err := todoRepo.CompleteWithFollowUp(ctx, userID, original.ID,
+			&todo.CreateTodoPayload{Title: "Send the report"})
+		require.NoError(t, err)
+
+		assert.Equal(t, todo.StatusCompleted, completed.Status)
+		assert.NotNil(t, completed.CompletedAt)
+		assert.Equal(t, "Send the report", followUp.Title)
+		require.NotNil(t, followUp.FollowUpOf)
+		assert.Equal(t, original.ID, *followUp.FollowUpOf)
+
+		stored, err := todoRepo.CheckTodoExists(ctx, userID, followUp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, original.ID, *stored.FollowUpOf)
+	})
+
+	t.Run("failed follow-up rolls back the completion", func(t *testing.T) {
+		userID := uuid.New().String()
+		original := createTestTodo(t, ctx, todoRepo, userID)
+		missingCategory := uuid.New()
+
+		_, _, err := todoRepo.CompleteWithFollowUp(ctx, userID, original.ID, &todo.CreateTodoPayload{
+			Title:      "Never created",
+			CategoryID: &missingCategory,
+		})
+		require.Error(t, err)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, original.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, todo.StatusCompleted, unchanged.Status)
+		assert.Nil(t, unchanged.CompletedAt)
+	})
+
+	t.Run("missing todo creates nothing", func(t *testing.T) {
+		userID := uuid.New().String()
+
+		_, _, err := todoRepo.CompleteWithFollowUp(ctx, userID, uuid.New(),
+			&todo.CreateTodoPayload{Title: "Orphan"})
+		require.Error(t, err)
+
+		result, err := todoRepo.GetTodos(ctx, userID, &todo.GetTodosQuery{
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(20),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Total)
+	})
+}
+
+func TestTodoRepository_HasOverdue(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	preferenceRepo := repository.NewPreferenceRepository(testServer)
+
+	t.Run("no overdue todos", func(t *testing.T) {
+		userID := uuid.New().String()
+		createTestTodo(t, ctx, todoRepo, userID)
+
+		passed := time.Now().Add(-time.Hour)
+		completed, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Done late",
+			DueDate: &passed,
+		})
+		require.NoError(t, err)
+		_, err = todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:     completed.ID,
+			Status: testing_pkg.Ptr(todo.StatusCompleted),
+		})
+		require.NoError(t, err)
+
+		hasOverdue, err := todoRepo.HasOverdue(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, hasOverdue)
+	})
+
+	t.Run("one overdue todo", func(t *testing.T) {
+		userID := uuid.New().String()
+		createTestTodo(t, ctx, todoRepo, userID)
+
+		passed := time.Now().Add(-time.Hour)
+		_, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Late",
+			DueDate: &passed,
+		})
+		require.NoError(t, err)
+
+		hasOverdue, err := todoRepo.HasOverdue(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, hasOverdue)
+	})
+
+	t.Run("all-day todo due today in the user's timezone is not overdue", func(t *testing.T) {
+		userID := uuid.New().String()
+		_, err := preferenceRepo.UpsertPreferences(ctx, userID, &preference.UpdatePreferencesPayload{
+			Timezone: testing_pkg.Ptr("Pacific/Kiritimati"),
+		})
+		require.NoError(t, err)
+
+		// Local midnight at UTC+14 is already in the past in UTC, so a
+		// naive due_date < NOW() check would wrongly flag it
+		kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+		require.NoError(t, err)
+		now := time.Now().In(kiritimati)
+		startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, kiritimati)
+		_, err = todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Today",
+			DueDate: &startOfToday,
+			AllDay:  testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+
+		hasOverdue, err := todoRepo.HasOverdue(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, hasOverdue)
+
+		startOfYesterday := startOfToday.AddDate(0, 0, -1)
+		_, err = todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Yesterday",
+			DueDate: &startOfYesterday,
+			AllDay:  testing_pkg.Ptr(true),
+		})
+		require.NoError(t, err)
+
+		hasOverdue, err = todoRepo.HasOverdue(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, hasOverdue)
+	})
+}
+
+func TestTodoRepository_ReplaceTagInCategory(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+	userID := uuid.New().String()
+
+	createTagged := func(categoryID uuid.UUID, tags ...string) *todo.Todo {
+		t.Helper()
+		created, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:      "Tagged",
+			CategoryID: &categoryID,
+			Metadata:   &todo.Metadata{Tags: tags},
+		})
+		require.NoError(t, err)
+		return created
+	}
+
+	tagsOf := func(todoID uuid.UUID) []string {
+		t.Helper()
+		item, err := todoRepo.CheckTodoExists(ctx, userID, todoID)
+		require.NoError(t, err)
+		require.NotNil(t, item.Metadata)
+		return item.Metadata.Tags
+	}
+
+	t.Run("replaces a tag across several todos", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Tag Project")
+		other := createTestCategory(t, ctx, categoryRepo, userID, "Tag Other")
+
+		first := createTagged(project.ID, "wip", "backend")
+		second := createTagged(project.ID, "frontend", "wip")
+		alreadyTagged := createTagged(project.ID, "in-progress", "wip")
+		untagged := createTagged(project.ID, "backend")
+		elsewhere := createTagged(other.ID, "wip")
+
+		changes, err := todoRepo.ReplaceTagInCategory(ctx, userID, project.ID, "wip", "in-progress")
+		require.NoError(t, err)
+		assert.Len(t, changes, 3)
+
+		assert.Equal(t, []string{"in-progress", "backend"}, tagsOf(first.ID))
+		assert.Equal(t, []string{"frontend", "in-progress"}, tagsOf(second.ID))
+		assert.Equal(t, []string{"in-progress"}, tagsOf(alreadyTagged.ID))
+		assert.Equal(t, []string{"backend"}, tagsOf(untagged.ID))
+		assert.Equal(t, []string{"wip"}, tagsOf(elsewhere.ID))
+	})
+
+	t.Run("removes a tag when the replacement is empty", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Tag Cleanup")
+
+		shared := createTagged(project.ID, "stale", "keep")
+		only := createTagged(project.ID, "stale")
+
+		changes, err := todoRepo.ReplaceTagInCategory(ctx, userID, project.ID, "stale", "")
+		require.NoError(t, err)
+		assert.Len(t, changes, 2)
+
+		assert.Equal(t, []string{"keep"}, tagsOf(shared.ID))
+		assert.Empty(t, tagsOf(only.ID))
+	})
+
+	t.Run("replacement already present in another casing is not duplicated", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Tag Casing")
+
+		item := createTagged(project.ID, "Review", "todo")
+
+		changes, err := todoRepo.ReplaceTagInCategory(ctx, userID, project.ID, "todo", "review")
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, []string{"Review", "todo"}, changes[0].Before.Metadata.Tags)
+
+		assert.Equal(t, []string{"Review"}, tagsOf(item.ID))
+	})
+
+	t.Run("category without the tag is untouched", func(t *testing.T) {
+		project := createTestCategory(t, ctx, categoryRepo, userID, "Tag Missing")
+		createTagged(project.ID, "keep")
+
+		changes, err := todoRepo.ReplaceTagInCategory(ctx, userID, project.ID, "absent", "other")
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestTodoRepository_GetChildren(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	testServer.Config.Todo = &config.TodoConfig{InlineChildrenLimit: 5}
+	defer func() { testServer.Config.Todo = nil }()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	parent := createTestTodo(t, ctx, todoRepo, userID)
+
+	childIDs := make([]uuid.UUID, 0, 12)
+	for i := 0; i < 12; i++ {
+		child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        fmt.Sprintf("Subtask %d", i+1),
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+		childIDs = append(childIDs, child.ID)
+
+		// Keep created_at distinct so the order is the creation order
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Run("parent inlines only the capped window", func(t *testing.T) {
+		result, err := todoRepo.GetTodoByID(ctx, userID, parent.ID)
+		require.NoError(t, err)
+
+		require.Len(t, result.Children, 5)
+		assert.Equal(t, 12, result.ChildCount)
+		for i, child := range result.Children {
+			assert.Equal(t, childIDs[i], child.ID)
+		}
+	})
+
+	t.Run("children endpoint pages through the full set", func(t *testing.T) {
+		var paged []uuid.UUID
+		for page := 1; page <= 3; page++ {
+			result, err := todoRepo.GetChildren(ctx, userID, parent.ID, &todo.GetChildrenQuery{
+				ID:    parent.ID,
+				Page:  testing_pkg.Ptr(page),
+				Limit: testing_pkg.Ptr(5),
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 12, result.Total)
+			assert.Equal(t, 3, result.TotalPages)
+			for _, child := range result.Data {
+				paged = append(paged, child.ID)
+			}
+		}
+
+		assert.Equal(t, childIDs, paged)
+	})
+
+	t.Run("todo without children has an empty page", func(t *testing.T) {
+		result, err := todoRepo.GetChildren(ctx, userID, childIDs[0], &todo.GetChildrenQuery{
+			ID:    childIDs[0],
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(5),
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Data)
+		assert.Equal(t, 0, result.Total)
+	})
+
+	t.Run("another user's todo is not found", func(t *testing.T) {
+		_, err := todoRepo.GetChildren(ctx, uuid.New().String(), parent.ID, &todo.GetChildrenQuery{
+			ID:    parent.ID,
+			Page:  testing_pkg.Ptr(1),
+			Limit: testing_pkg.Ptr(5),
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTodoRepository_PromoteToTopLevel(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	// root -> middle -> leaf
+	createChain := func(t *testing.T) (root, middle, leaf *todo.Todo) {
+		t.Helper()
+
+		root = createTestTodo(t, ctx, todoRepo, userID)
+
+		middle, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Middle",
+			ParentTodoID: &root.ID,
+		})
+		require.NoError(t, err)
+
+		leaf, err = todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Leaf",
+			ParentTodoID: &middle.ID,
+		})
+		require.NoError(t, err)
+
+		return root, middle, leaf
+	}
+
+	t.Run("keeping children moves the subtree up", func(t *testing.T) {
+		_, middle, leaf := createChain(t)
+
+		promoted, err := todoRepo.PromoteToTopLevel(ctx, userID, middle.ID, true)
+		require.NoError(t, err)
+		assert.Nil(t, promoted.ParentTodoID)
+
+		leafAfter, err := todoRepo.CheckTodoExists(ctx, userID, leaf.ID)
+		require.NoError(t, err)
+		require.NotNil(t, leafAfter.ParentTodoID)
+		assert.Equal(t, middle.ID, *leafAfter.ParentTodoID)
+	})
+
+	t.Run("releasing children hands them to the former parent", func(t *testing.T) {
+		root, middle, leaf := createChain(t)
+
+		promoted, err := todoRepo.PromoteToTopLevel(ctx, userID, middle.ID, false)
+		require.NoError(t, err)
+		assert.Nil(t, promoted.ParentTodoID)
+
+		leafAfter, err := todoRepo.CheckTodoExists(ctx, userID, leaf.ID)
+		require.NoError(t, err)
+		require.NotNil(t, leafAfter.ParentTodoID)
+		assert.Equal(t, root.ID, *leafAfter.ParentTodoID)
+
+		promotedAfter, err := todoRepo.GetTodoByID(ctx, userID, middle.ID)
+		require.NoError(t, err)
+		assert.Empty(t, promotedAfter.Children)
+	})
+
+	t.Run("top-level todo is rejected", func(t *testing.T) {
+		root, _, _ := createChain(t)
+
+		_, err := todoRepo.PromoteToTopLevel(ctx, userID, root.ID, true)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeBadRequest, httpErr.Code)
+	})
+
+	t.Run("another user's todo is not found", func(t *testing.T) {
+		_, middle, _ := createChain(t)
+
+		_, err := todoRepo.PromoteToTopLevel(ctx, uuid.New().String(), middle.ID, true)
+		assert.Error(t, err)
+	})
+}
+
+func TestTodoRepository_ShareLinks(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	t.Run("live link returns the todo with its subtasks", func(t *testing.T) {
+		parent := createTestTodo(t, ctx, todoRepo, userID)
+		_, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        "Subtask",
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+
+		link, err := todoRepo.CreateShareLink(ctx, userID, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parent.ID, link.TodoID)
+		assert.Nil(t, link.RevokedAt)
+
+		shared, err := todoRepo.GetSharedTodo(ctx, link.ID, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parent.ID, shared.ID)
+		assert.Len(t, shared.Children, 1)
+
+		view := todo.NewSharedTodo(*shared)
+		assert.True(t, view.ReadOnly)
+		assert.Empty(t, view.UserID)
+	})
+
+	t.Run("revoked link is not found", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		link, err := todoRepo.CreateShareLink(ctx, userID, item.ID)
+		require.NoError(t, err)
+
+		revoked, err := todoRepo.RevokeShareLinks(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		_, err = todoRepo.GetSharedTodo(ctx, link.ID, item.ID)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("issuing a new link revokes the previous one", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, userID)
+
+		first, err := todoRepo.CreateShareLink(ctx, userID, item.ID)
+		require.NoError(t, err)
+		second, err := todoRepo.CreateShareLink(ctx, userID, item.ID)
+		require.NoError(t, err)
+
+		_, err = todoRepo.GetSharedTodo(ctx, first.ID, item.ID)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		_, err = todoRepo.GetSharedTodo(ctx, second.ID, item.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("another user's todo can't be shared", func(t *testing.T) {
+		item := createTestTodo(t, ctx, todoRepo, uuid.New().String())
+
+		_, err := todoRepo.CreateShareLink(ctx, userID, item.ID)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestTodoRepository_FeedLinks(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	t.Run("issuing a new link revokes the previous one", func(t *testing.T) {
+		first, err := todoRepo.CreateFeedLink(ctx, userID)
+		require.NoError(t, err)
+		second, err := todoRepo.CreateFeedLink(ctx, userID)
+		require.NoError(t, err)
+
+		link, err := todoRepo.GetFeedLink(ctx, userID, first.ID)
+		require.NoError(t, err)
+		assert.Nil(t, link)
+
+		link, err = todoRepo.GetFeedLink(ctx, userID, second.ID)
+		require.NoError(t, err)
+		require.NotNil(t, link)
+		assert.Nil(t, link.RevokedAt)
+	})
+
+	t.Run("revoked link is not found", func(t *testing.T) {
+		link, err := todoRepo.CreateFeedLink(ctx, userID)
+		require.NoError(t, err)
+
+		revoked, err := todoRepo.RevokeFeedLinks(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		found, err := todoRepo.GetFeedLink(ctx, userID, link.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("a link only opens its own user's feed", func(t *testing.T) {
+		link, err := todoRepo.CreateFeedLink(ctx, userID)
+		require.NoError(t, err)
+
+		found, err := todoRepo.GetFeedLink(ctx, uuid.New().String(), link.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
+func TestTodoRepository_GetCycleTimeStats(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+	createdAt := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+
+	completeAfter := func(t *testing.T, priority todo.Priority, took time.Duration) {
+		t.Helper()
+
+		item, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:    "Timed",
+			Priority: &priority,
+		})
+		require.NoError(t, err)
+
+		_, err = testServer.DB.Pool.Exec(ctx,
+			"UPDATE todos SET status = 'completed', created_at = $1, completed_at = $2 WHERE id = $3",
+			createdAt, createdAt.Add(took), item.ID)
+		require.NoError(t, err)
+	}
+
+	completeAfter(t, todo.PriorityHigh, time.Hour)
+	completeAfter(t, todo.PriorityHigh, 3*time.Hour)
+	completeAfter(t, todo.PriorityLow, 4*time.Hour)
+	completeAfter(t, todo.PriorityLow, 200*24*time.Hour)
+
+	// Not completed, so never measured
+	active, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{Title: "Still going"})
+	require.NoError(t, err)
+	_, err = testServer.DB.Pool.Exec(ctx, "UPDATE todos SET created_at = $1 WHERE id = $2", createdAt, active.ID)
+	require.NoError(t, err)
+
+	byPriority := func(stats *todo.CycleTimeStats) map[todo.Priority]todo.CycleTime {
+		out := make(map[todo.Priority]todo.CycleTime)
+		for _, p := range stats.ByPriority {
+			out[p.Priority] = p.CycleTime
+		}
+		return out
+	}
+
+	t.Run("outliers beyond the cap are excluded", func(t *testing.T) {
+		outlierCap := 90 * 24 * time.Hour
+		stats, err := todoRepo.GetCycleTimeStats(ctx, userID, &outlierCap)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, stats.Overall.Count)
+		assert.InDelta(t, (8 * time.Hour / 3).Seconds(), stats.Overall.AverageSeconds, 1)
+		assert.InDelta(t, (3 * time.Hour).Seconds(), stats.Overall.MedianSeconds, 1)
+		assert.Equal(t, 1, stats.ExcludedOutliers)
+
+		priorities := byPriority(stats)
+		require.Len(t, priorities, 2)
+		assert.Equal(t, 2, priorities[todo.PriorityHigh].Count)
+		assert.InDelta(t, (2 * time.Hour).Seconds(), priorities[todo.PriorityHigh].AverageSeconds, 1)
+		assert.InDelta(t, (2 * time.Hour).Seconds(), priorities[todo.PriorityHigh].MedianSeconds, 1)
+		assert.Equal(t, 1, priorities[todo.PriorityLow].Count)
+		assert.InDelta(t, (4 * time.Hour).Seconds(), priorities[todo.PriorityLow].AverageSeconds, 1)
+	})
+
+	t.Run("without a cap every completed todo counts", func(t *testing.T) {
+		stats, err := todoRepo.GetCycleTimeStats(ctx, userID, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, stats.Overall.Count)
+		assert.Equal(t, 0, stats.ExcludedOutliers)
+		assert.Equal(t, 2, byPriority(stats)[todo.PriorityLow].Count)
+	})
+
+	t.Run("user without completed todos gets zeroes", func(t *testing.T) {
+		stats, err := todoRepo.GetCycleTimeStats(ctx, uuid.New().String(), nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, stats.Overall.Count)
+		assert.Empty(t, stats.ByPriority)
+		assert.Empty(t, stats.ByCategory)
+	})
+}
+
+func TestTodoRepository_ActivateDraftsDueBy(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+	now := time.Now()
+
+	createDraft := func(t *testing.T, dueDate time.Time, deferUntil *time.Time) *todo.Todo {
+		t.Helper()
+
+		item, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:      "Draft",
+			DueDate:    &dueDate,
+			DeferUntil: deferUntil,
+		})
+		require.NoError(t, err)
+		require.Equal(t, todo.StatusDraft, item.Status)
+
+		return item
+	}
+
+	dueSoon := createDraft(t, now.Add(6*time.Hour), nil)
+	dueFarOut := createDraft(t, now.Add(30*24*time.Hour), nil)
+	overdue := createDraft(t, now.Add(-6*time.Hour), nil)
+	deferred := createDraft(t, now.Add(6*time.Hour), testing_pkg.Ptr(now.Add(3*time.Hour)))
+
+	completed := createDraft(t, now.Add(6*time.Hour), nil)
+	_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET status = 'completed' WHERE id = $1", completed.ID)
+	require.NoError(t, err)
+
+	activated, err := todoRepo.ActivateDraftsDueBy(ctx, now.Add(24*time.Hour), 100)
+	require.NoError(t, err)
+
+	activatedIDs := make([]uuid.UUID, 0, len(activated))
+	for _, item := range activated {
+		assert.Equal(t, todo.StatusActive, item.Status)
+		activatedIDs = append(activatedIDs, item.ID)
+	}
+	assert.Contains(t, activatedIDs, dueSoon.ID)
+	assert.NotContains(t, activatedIDs, dueFarOut.ID)
+	assert.NotContains(t, activatedIDs, overdue.ID)
+	assert.NotContains(t, activatedIDs, deferred.ID)
+	assert.NotContains(t, activatedIDs, completed.ID)
+
+	for _, tc := range []struct {
+		item   *todo.Todo
+		status todo.Status
+	}{
+		{dueSoon, todo.StatusActive},
+		{dueFarOut, todo.StatusDraft},
+		{overdue, todo.StatusDraft},
+		{deferred, todo.StatusDraft},
+		{completed, todo.StatusCompleted},
+	} {
+		current, err := todoRepo.CheckTodoExists(ctx, userID, tc.item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tc.status, current.Status)
+	}
+
+	// Nothing left to promote on a second run
+	activated, err = todoRepo.ActivateDraftsDueBy(ctx, now.Add(24*time.Hour), 100)
+	require.NoError(t, err)
+	assert.Empty(t, activated)
+}
+
+func TestTodoRepository_AttachmentTranscriptSearch(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	item := createTestTodo(t, ctx, todoRepo, userID)
+	voiceMemo, err := todoRepo.UploadTodoAttachment(ctx, item.ID, userID, "memo.mp3_1700000000", "memo.mp3",
+		2048, "audio/mpeg")
+	require.NoError(t, err)
+	assert.Nil(t, voiceMemo.Transcript)
+
+	search := func(t *testing.T, text string) []uuid.UUID {
+		t.Helper()
+
+		query := &todo.GetTodosQuery{
+			Page:   testing_pkg.Ptr(1),
+			Limit:  testing_pkg.Ptr(20),
+			Search: &text,
+		}
+		require.NoError(t, query.Validate())
+
+		result, err := todoRepo.GetTodos(ctx, userID, query)
+		require.NoError(t, err)
+
+		ids := make([]uuid.UUID, 0, len(result.Data))
+		for _, found := range result.Data {
+			ids = append(ids, found.ID)
+		}
+		return ids
+	}
+
+	assert.NotContains(t, search(t, "quarterly budget"), item.ID)
+
+	transcribed, err := todoRepo.SetAttachmentTranscript(ctx, item.ID, voiceMemo.ID,
+		"Remember to send the quarterly budget to finance")
+	require.NoError(t, err)
+	require.NotNil(t, transcribed.Transcript)
+	assert.NotNil(t, transcribed.TranscribedAt)
+
+	assert.Contains(t, search(t, "quarterly budget"), item.ID)
+
+	_, err = todoRepo.SetAttachmentTranscript(ctx, item.ID, uuid.New(), "missing")
+	var httpErr *errs.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, errs.CodeAttachmentNotFound, httpErr.Code)
+}
+
+func TestTodoRepository_UploadDuplicateAttachmentName(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	item := createTestTodo(t, ctx, todoRepo, userID)
+	other := createTestTodo(t, ctx, todoRepo, userID)
+
+	upload := func(todoID uuid.UUID, fileName string) *todo.TodoAttachment {
+		attachment, err := todoRepo.UploadTodoAttachment(ctx, todoID, userID, todo.AttachmentKey(fileName),
+			fileName, 1024, "application/pdf")
+		require.NoError(t, err)
+		return attachment
+	}
+
+	first := upload(item.ID, "report.pdf")
+	second := upload(item.ID, "report.pdf")
+	third := upload(item.ID, "report.pdf")
+	elsewhere := upload(other.ID, "report.pdf")
+
+	assert.NotEqual(t, first.DownloadKey, second.DownloadKey)
+	assert.NotEqual(t, second.DownloadKey, third.DownloadKey)
+
+	assert.Equal(t, "report.pdf", first.Name)
+	assert.Equal(t, "report (1).pdf", second.Name)
+	assert.Equal(t, "report (2).pdf", third.Name)
+	assert.Equal(t, "report.pdf", elsewhere.Name, "names only clash within a todo")
+
+	_, err := todoRepo.UploadTodoAttachment(ctx, uuid.New(), userID, todo.AttachmentKey("x.pdf"), "x.pdf",
+		1024, "application/pdf")
+	var httpErr *errs.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+}
+
+func TestTodoRepository_BulkSetPriority(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	t.Run("sets every listed todo to high", func(t *testing.T) {
+		userID := uuid.New().String()
+		todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+		ids := []uuid.UUID{todos[0].ID, todos[1].ID, todos[2].ID}
+
+		updated, err := todoRepo.BulkSetPriority(ctx, userID, append(ids, todos[0].ID), todo.PriorityHigh)
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated)
+
+		changed, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+		require.Len(t, changed, 3)
+		for _, item := range changed {
+			assert.Equal(t, todo.PriorityHigh, item.Priority)
+		}
+	})
+
+	t.Run("rejects the batch when a todo is missing", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		other := createTestTodo(t, ctx, todoRepo, uuid.New().String())
+
+		_, err := todoRepo.BulkSetPriority(ctx, userID, []uuid.UUID{item.ID, other.ID}, todo.PriorityLow)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.PriorityHigh, unchanged.Priority)
+	})
+}
+
+func TestTodoRepository_BulkReopen(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	createCompleted := func(t *testing.T, userID string) []uuid.UUID {
+		t.Helper()
+
+		todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+		ids := []uuid.UUID{todos[0].ID, todos[1].ID, todos[2].ID}
+		_, err := todoRepo.BulkUpdateStatus(ctx, userID, ids, todo.StatusCompleted)
+		require.NoError(t, err)
+		return ids
+	}
+
+	t.Run("reopens completed todos and clears the completion time", func(t *testing.T) {
+		userID := uuid.New().String()
+		ids := createCompleted(t, userID)
+
+		before, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+
+		updated, err := todoRepo.BulkReopen(ctx, userID, ids, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated)
+
+		after, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+		require.Len(t, after, 3)
+		dueDates := make(map[uuid.UUID]*time.Time, len(before))
+		for _, item := range before {
+			dueDates[item.ID] = item.DueDate
+		}
+		for _, item := range after {
+			assert.Equal(t, todo.StatusActive, item.Status)
+			assert.Nil(t, item.CompletedAt)
+			assert.Equal(t, dueDates[item.ID], item.DueDate, "due date is kept without a new one")
+		}
+	})
+
+	t.Run("applies the new due date to every todo", func(t *testing.T) {
+		userID := uuid.New().String()
+		ids := createCompleted(t, userID)
+		due := time.Now().Add(72 * time.Hour).UTC().Truncate(time.Microsecond)
+
+		updated, err := todoRepo.BulkReopen(ctx, userID, ids, &due)
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated)
+
+		after, err := todoRepo.GetTodosByIDs(ctx, userID, ids)
+		require.NoError(t, err)
+		for _, item := range after {
+			require.NotNil(t, item.DueDate)
+			assert.True(t, due.Equal(*item.DueDate))
+			assert.Nil(t, item.CompletedAt)
+		}
+	})
+
+	t.Run("rejects the batch when a todo is missing", func(t *testing.T) {
+		userID := uuid.New().String()
+		ids := createCompleted(t, userID)
+
+		_, err := todoRepo.BulkReopen(ctx, userID, []uuid.UUID{ids[0], uuid.New()}, nil)
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, ids[0])
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusCompleted, unchanged.Status)
+		assert.NotNil(t, unchanged.CompletedAt)
+	})
+}
+
+func TestTodoRepository_BulkArchive(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	// One todo in each of active, completed and archived
+	createMixed := func(t *testing.T, userID string) (active, completed, archived *todo.Todo) {
+		t.Helper()
+
+		todos := createTestTodos(t, ctx, todoRepo, userID, 3)
+		for i, status := range []todo.Status{todo.StatusActive, todo.StatusCompleted, todo.StatusArchived} {
+			updated, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+				ID:     todos[i].ID,
+				Status: testing_pkg.Ptr(status),
+			})
+			require.NoError(t, err)
+			todos[i] = updated
+		}
+
+		return todos[0], todos[1], todos[2]
+	}
+
+	t.Run("archives everything not already archived", func(t *testing.T) {
+		userID := uuid.New().String()
+		active, completed, archived := createMixed(t, userID)
+
+		updated, err := todoRepo.BulkArchive(ctx, userID, []uuid.UUID{active.ID, completed.ID, archived.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated)
+
+		after, err := todoRepo.GetTodosByIDs(ctx, userID, []uuid.UUID{active.ID, completed.ID, archived.ID})
+		require.NoError(t, err)
+		for _, item := range after {
+			assert.Equal(t, todo.StatusArchived, item.Status)
+			if item.ID == completed.ID {
+				assert.NotNil(t, item.CompletedAt, "archiving keeps the completion time")
+			}
+		}
+	})
+
+	t.Run("unarchives only archived todos back to active", func(t *testing.T) {
+		userID := uuid.New().String()
+		active, completed, archived := createMixed(t, userID)
+
+		updated, err := todoRepo.BulkUnarchive(ctx, userID, []uuid.UUID{active.ID, completed.ID, archived.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated)
+
+		restored, err := todoRepo.CheckTodoExists(ctx, userID, archived.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusActive, restored.Status)
+		assert.Nil(t, restored.CompletedAt)
+
+		untouched, err := todoRepo.CheckTodoExists(ctx, userID, completed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusCompleted, untouched.Status)
+	})
+
+	t.Run("rejects the batch when a todo is missing", func(t *testing.T) {
+		userID := uuid.New().String()
+		item := createTestTodo(t, ctx, todoRepo, userID)
+		other := createTestTodo(t, ctx, todoRepo, uuid.New().String())
+
+		_, err := todoRepo.BulkArchive(ctx, userID, []uuid.UUID{item.ID, other.ID})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+
+		unchanged, err := todoRepo.CheckTodoExists(ctx, userID, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, unchanged.Status)
+	})
+}
+
+func TestTodoRepository_GetOverdueBuckets(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+	now := time.Now().UTC()
+
+	createDue := func(t *testing.T, dueDate time.Time) uuid.UUID {
+		t.Helper()
+
+		item, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Overdue",
+			DueDate: &dueDate,
+		})
+		require.NoError(t, err)
+		return item.ID
+	}
+
+	// Halfway through the elapsed part of today, so it's past due but still
+	// today whenever the test runs
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dueToday := createDue(t, midnight.Add(now.Sub(midnight)/2))
+	twoDays := createDue(t, now.AddDate(0, 0, -2))
+	threeDays := createDue(t, now.AddDate(0, 0, -3))
+	fiveDays := createDue(t, now.AddDate(0, 0, -5))
+	tenDays := createDue(t, now.AddDate(0, 0, -10))
+	createDue(t, now.Add(24*time.Hour))
+
+	completed := createDue(t, now.AddDate(0, 0, -2))
+	_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET status = 'completed' WHERE id = $1", completed)
+	require.NoError(t, err)
+
+	buckets, err := todoRepo.GetOverdueBuckets(ctx, userID, "UTC", 0)
+	require.NoError(t, err)
+
+	require.Len(t, buckets.Buckets, 4)
+	assert.Equal(t, 5, buckets.Total)
+
+	expected := map[todo.OverdueBucket][]uuid.UUID{
+		todo.OverdueToday:       {dueToday},
+		todo.OverdueOneToThree:  {threeDays, twoDays},
+		todo.OverdueFourToSeven: {fiveDays},
+		todo.OverdueEightOrMore: {tenDays},
+	}
+	for i, summary := range buckets.Buckets {
+		assert.Equal(t, todo.OverdueBucketOrder[i], summary.Bucket)
+		assert.Equal(t, len(expected[summary.Bucket]), summary.Count, summary.Bucket)
+		assert.Equal(t, expected[summary.Bucket], summary.TodoIDs, summary.Bucket)
+	}
+
+	t.Run("user without overdue todos gets empty buckets", func(t *testing.T) {
+		buckets, err := todoRepo.GetOverdueBuckets(ctx, uuid.New().String(), "UTC", 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, buckets.Total)
+		require.Len(t, buckets.Buckets, 4)
+		for _, summary := range buckets.Buckets {
+			assert.Zero(t, summary.Count)
+			assert.Empty(t, summary.TodoIDs)
+		}
+	})
+
+	t.Run("day start hour moves the today boundary", func(t *testing.T) {
+		// A zone where it's around noon, so 1am and 4am local have both passed
+		offset := 12 - now.Hour()
+		timezone := "Etc/GMT"
+		if offset > 0 {
+			timezone = fmt.Sprintf("Etc/GMT-%d", offset)
+		} else if offset < 0 {
+			timezone = fmt.Sprintf("Etc/GMT+%d", -offset)
+		}
+		local := now.In(time.FixedZone(timezone, offset*3600))
+		localMidnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+		otherUser := uuid.New().String()
+		createAt := func(t *testing.T, dueDate time.Time) uuid.UUID {
+			t.Helper()
+
+			item, err := todoRepo.CreateTodo(ctx, otherUser, &todo.CreateTodoPayload{
+				Title:   "Late night",
+				DueDate: &dueDate,
+			})
+			require.NoError(t, err)
+			return item.ID
+		}
+		oneAM := createAt(t, localMidnight.Add(time.Hour))
+		fourAM := createAt(t, localMidnight.Add(4*time.Hour))
+
+		buckets, err := todoRepo.GetOverdueBuckets(ctx, otherUser, timezone, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{oneAM, fourAM}, buckets.Buckets[0].TodoIDs)
+
+		buckets, err = todoRepo.GetOverdueBuckets(ctx, otherUser, timezone, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{fourAM}, buckets.Buckets[0].TodoIDs)
+		assert.Equal(t, []uuid.UUID{oneAM}, buckets.Buckets[1].TodoIDs)
+	})
+}
+
+func TestTodoRepository_FiltersMatchAcrossListCountAndStats(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	todos := createTestTodos(t, ctx, todoRepo, userID, 4)
+	_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{todos[0].ID}, todo.StatusCompleted)
+	require.NoError(t, err)
+	_, err = todoRepo.BulkSetPriority(ctx, userID, []uuid.UUID{todos[1].ID, todos[2].ID}, todo.PriorityLow)
+	require.NoError(t, err)
+
+	// Another user's todos must never leak into any of the three paths
+	createTestTodos(t, ctx, todoRepo, uuid.New().String(), 2)
+
+	tests := []struct {
+		name     string
+		query    todo.GetTodosQuery
+		expected int
+	}{
+		{name: "no filters", expected: 4},
+		{name: "completed", query: todo.GetTodosQuery{Completed: testing_pkg.Ptr(true)}, expected: 1},
+		{name: "priority", query: todo.GetTodosQuery{Priority: testing_pkg.Ptr(todo.PriorityLow)}, expected: 2},
+		{name: "search", query: todo.GetTodosQuery{Search: testing_pkg.Ptr("Todo 3")}, expected: 1},
+		{
+			name: "due range and status",
+			query: todo.GetTodosQuery{
+				Status: testing_pkg.Ptr(todo.StatusDraft),
+				DueTo:  testing_pkg.Ptr(time.Now().Add(60 * time.Hour)),
+			},
+			expected: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query := tc.query
+			// A one item page shows that only the list is paginated
+			query.Page = testing_pkg.Ptr(1)
+			query.Limit = testing_pkg.Ptr(1)
+
+			list, err := todoRepo.GetTodos(ctx, userID, &query)
+			require.NoError(t, err)
+
+			count, err := todoRepo.CountTodos(ctx, userID, &query)
+			require.NoError(t, err)
+
+			stats, err := todoRepo.GetFilteredTodoStats(ctx, userID, &query)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, list.Total)
+			assert.Equal(t, tc.expected, count)
+			assert.Equal(t, tc.expected, stats.Total)
+			assert.LessOrEqual(t, len(list.Data), 1)
+		})
+	}
+}
+
+func TestTodoRepository_CreatedRangeFilter(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	todos := createTestTodos(t, ctx, todoRepo, userID, 4)
+
+	// Spread creation over the past ten days: 10, 6, 3 and 0 days ago
+	now := time.Now()
+	for i, daysAgo := range []int{10, 6, 3, 0} {
+		_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET created_at = $1 WHERE id = $2",
+			now.AddDate(0, 0, -daysAgo), todos[i].ID)
+		require.NoError(t, err)
+	}
+
+	query := &todo.GetTodosQuery{
+		Page:        testing_pkg.Ptr(1),
+		Limit:       testing_pkg.Ptr(10),
+		Sort:        testing_pkg.Ptr("created_at"),
+		Order:       testing_pkg.Ptr("asc"),
+		CreatedFrom: testing_pkg.Ptr(now.AddDate(0, 0, -7)),
+		CreatedTo:   testing_pkg.Ptr(now.AddDate(0, 0, -1)),
+	}
+
+	t.Run("only todos created in the window are returned", func(t *testing.T) {
+		result, err := todoRepo.GetTodos(ctx, userID, query)
+		require.NoError(t, err)
+		require.Len(t, result.Data, 2)
+		assert.Equal(t, todos[1].ID, result.Data[0].ID)
+		assert.Equal(t, todos[2].ID, result.Data[1].ID)
+	})
+
+	t.Run("composes with other filters", func(t *testing.T) {
+		_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{todos[1].ID}, todo.StatusCompleted)
+		require.NoError(t, err)
+
+		filtered := *query
+		filtered.Completed = testing_pkg.Ptr(true)
+
+		result, err := todoRepo.GetTodos(ctx, userID, &filtered)
+		require.NoError(t, err)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, todos[1].ID, result.Data[0].ID)
+	})
+}
+
+func TestTodoRepository_SnoozeOverdue(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	createDue := func(t *testing.T, due time.Time) *todo.Todo {
+		t.Helper()
+		result, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Due " + due.Format(time.RFC3339),
+			DueDate: &due,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	now := time.Now()
+	lateYesterday := createDue(t, now.Add(-24*time.Hour))
+	lateLastWeek := createDue(t, now.Add(-7*24*time.Hour))
+	upcoming := createDue(t, now.Add(24*time.Hour))
+	completedLate := createDue(t, now.Add(-48*time.Hour))
+	_, err := todoRepo.BulkUpdateStatus(ctx, userID, []uuid.UUID{completedLate.ID}, todo.StatusCompleted)
+	require.NoError(t, err)
+
+	// Another user's overdue todo is left alone
+	otherLate, err := todoRepo.CreateTodo(ctx, uuid.New().String(), &todo.CreateTodoPayload{
+		Title:   "Someone else's",
+		DueDate: testing_pkg.Ptr(now.Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+
+	target := now.Add(36 * time.Hour).Truncate(time.Second)
+	snoozed, err := todoRepo.SnoozeOverdue(ctx, userID, target)
+	require.NoError(t, err)
+	require.Len(t, snoozed, 2)
+
+	previous := make(map[uuid.UUID]int64)
+	for _, item := range snoozed {
+		require.NotNil(t, item.PreviousDueDate)
+		previous[item.ID] = item.PreviousDueDate.Unix()
+		assert.Equal(t, target.Unix(), item.DueDate.Unix())
+	}
+	assert.Equal(t, lateYesterday.DueDate.Unix(), previous[lateYesterday.ID])
+	assert.Equal(t, lateLastWeek.DueDate.Unix(), previous[lateLastWeek.ID])
+
+	for _, unchanged := range []*todo.Todo{upcoming, completedLate} {
+		item, err := todoRepo.GetTodoByID(ctx, userID, unchanged.ID)
+		require.NoError(t, err)
+		assert.Equal(t, unchanged.DueDate.Unix(), item.DueDate.Unix())
+	}
+	item, err := todoRepo.GetTodoByID(ctx, otherLate.UserID, otherLate.ID)
+	require.NoError(t, err)
+	assert.Equal(t, otherLate.DueDate.Unix(), item.DueDate.Unix())
+
+	hasOverdue, err := todoRepo.HasOverdue(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, hasOverdue)
+}
+
+func TestTodoRepository_CancelReminder(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	createDue := func(t *testing.T, due time.Time) *todo.Todo {
+		t.Helper()
+		result, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:   "Due " + due.Format(time.RFC3339),
+			DueDate: &due,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	ids := func(todos []todo.Todo) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(todos))
+		for _, item := range todos {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	dueSoon := createDue(t, time.Now().Add(2*time.Hour))
+	otherDueSoon := createDue(t, time.Now().Add(3*time.Hour))
+	overdue := createDue(t, time.Now().Add(-2*time.Hour))
+
+	t.Run("scheduled reminders are pending until cancelled", func(t *testing.T) {
+		pending, err := todoRepo.GetUserTodosDueInHours(ctx, userID, 24)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{dueSoon.ID, otherDueSoon.ID}, ids(pending))
+
+		require.NoError(t, todoRepo.CancelReminder(ctx, dueSoon.ID, todo.ReminderDueSoon, *dueSoon.DueDate))
+
+		pending, err = todoRepo.GetUserTodosDueInHours(ctx, userID, 24)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{otherDueSoon.ID}, ids(pending))
+
+		cancelled, err := todoRepo.GetReminderCancellations(ctx, dueSoon.ID)
+		require.NoError(t, err)
+		require.Contains(t, cancelled, todo.ReminderDueSoon)
+		assert.Equal(t, dueSoon.DueDate.Unix(), cancelled[todo.ReminderDueSoon].Unix())
+	})
+
+	t.Run("cancelled overdue reminder is skipped by the cron query", func(t *testing.T) {
+		require.NoError(t, todoRepo.CancelReminder(ctx, overdue.ID, todo.ReminderOverdue, *overdue.DueDate))
+
+		pending, err := todoRepo.GetOverdueTodos(ctx, 1000, 0)
+		require.NoError(t, err)
+		assert.NotContains(t, ids(pending), overdue.ID)
+	})
+
+	t.Run("moving the due date schedules the reminder again", func(t *testing.T) {
+		_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET due_date = $1 WHERE id = $2",
+			time.Now().Add(4*time.Hour), dueSoon.ID)
+		require.NoError(t, err)
+
+		pending, err := todoRepo.GetUserTodosDueInHours(ctx, userID, 24)
+		require.NoError(t, err)
+		assert.Contains(t, ids(pending), dueSoon.ID)
+	})
+}
+
+func TestTodoRepository_GetTodoSubtree(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+
+	userID := uuid.New().String()
+	root := createTestTodo(t, ctx, todoRepo, userID)
+	other := createTestTodo(t, ctx, todoRepo, userID)
+
+	var subtaskIDs []uuid.UUID
+	for _, title := range []string{"First", "Second"} {
+		subtask, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        title,
+			ParentTodoID: &root.ID,
+		})
+		require.NoError(t, err)
+		subtaskIDs = append(subtaskIDs, subtask.ID)
+	}
+	_, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:        "Elsewhere",
+		ParentTodoID: &other.ID,
+	})
+	require.NoError(t, err)
+
+	subtree, err := todoRepo.GetTodoSubtree(ctx, userID, root.ID)
+	require.NoError(t, err)
+	require.Len(t, subtree, 2)
+	for i, item := range subtree {
+		assert.Equal(t, subtaskIDs[i], item.ID)
+		assert.Equal(t, root.ID, *item.ParentTodoID)
+	}
+
+	body := string(todo.RenderMarkdown(root, subtree, time.UTC))
+	assert.Contains(t, body, "\n  - [ ] First _(priority: medium)_\n  - [ ] Second _(priority: medium)_\n")
+
+	empty, err := todoRepo.GetTodoSubtree(ctx, uuid.New().String(), root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestTodoRepository_CopyAsFresh(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+	userID := uuid.New().String()
+
+	source := createTestCategory(t, ctx, categoryRepo, userID, "Source")
+	target := createTestCategory(t, ctx, categoryRepo, userID, "Target")
+
+	// A completed root with one subtask due two days after it
+	rootDue := time.Now().Add(-72 * time.Hour).Truncate(time.Microsecond)
+	subtaskDue := rootDue.Add(48 * time.Hour)
+
+	root, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:      "Launch checklist",
+		Priority:   testing_pkg.Ptr(todo.PriorityHigh),
+		DueDate:    &rootDue,
+		CategoryID: &source.ID,
+		Metadata:   &todo.Metadata{Tags: []string{"launch"}},
+	})
+	require.NoError(t, err)
+
+	subtask, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:        "Write release notes",
+		DueDate:      &subtaskDue,
+		ParentTodoID: &root.ID,
+		CategoryID:   &source.ID,
+	})
+	require.NoError(t, err)
+
+	for _, id := range []uuid.UUID{subtask.ID, root.ID} {
+		_, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+			ID:     id,
+			Status: testing_pkg.Ptr(todo.StatusCompleted),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("copy lands in the target category as drafts without due dates", func(t *testing.T) {
+		copied, err := todoRepo.CopyAsFresh(ctx, userID, root.ID, target.ID, nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, root.ID, copied.ID)
+		assert.Equal(t, "Launch checklist", copied.Title)
+		assert.Equal(t, todo.PriorityHigh, copied.Priority)
+		assert.Equal(t, todo.StatusDraft, copied.Status)
+		assert.Nil(t, copied.CompletedAt)
+		assert.Nil(t, copied.DueDate)
+		assert.Nil(t, copied.ParentTodoID)
+		require.NotNil(t, copied.CategoryID)
+		assert.Equal(t, target.ID, *copied.CategoryID)
+		require.NotNil(t, copied.Metadata)
+		assert.Equal(t, []string{"launch"}, copied.Metadata.Tags)
+
+		children, err := todoRepo.GetTodoSubtree(ctx, userID, copied.ID)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		assert.Equal(t, "Write release notes", children[0].Title)
+		assert.Equal(t, todo.StatusDraft, children[0].Status)
+		assert.Nil(t, children[0].CompletedAt)
+		assert.Nil(t, children[0].DueDate)
+		assert.Equal(t, target.ID, *children[0].CategoryID)
+
+		original, err := todoRepo.CheckTodoExists(ctx, userID, root.ID)
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusCompleted, original.Status)
+		assert.Equal(t, source.ID, *original.CategoryID)
+	})
+
+	t.Run("shifted due dates keep their spacing from now", func(t *testing.T) {
+		shift := 7 * 24 * time.Hour
+		before := time.Now()
+
+		copied, err := todoRepo.CopyAsFresh(ctx, userID, root.ID, target.ID, &shift)
+		require.NoError(t, err)
+		require.NotNil(t, copied.DueDate)
+		assert.WithinDuration(t, before.Add(shift), *copied.DueDate, time.Minute)
+
+		children, err := todoRepo.GetTodoSubtree(ctx, userID, copied.ID)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		require.NotNil(t, children[0].DueDate)
+		assert.WithinDuration(t, copied.DueDate.Add(48*time.Hour), *children[0].DueDate, time.Second)
+	})
+
+	t.Run("another user's todo is not copied", func(t *testing.T) {
+		_, err := todoRepo.CopyAsFresh(ctx, uuid.New().String(), root.ID, target.ID, nil)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestTodoRepository_Dependencies(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	assertCode := func(t *testing.T, err error, code errs.Code) {
+		t.Helper()
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, code, httpErr.Code)
+	}
+
+	t.Run("lists both sides of a link", func(t *testing.T) {
+		design := createTestTodo(t, ctx, todoRepo, userID)
+		build := createTestTodo(t, ctx, todoRepo, userID)
+
+		require.NoError(t, todoRepo.AddDependency(ctx, userID, build.ID, design.ID))
+		// Adding it again changes nothing
+		require.NoError(t, todoRepo.AddDependency(ctx, userID, build.ID, design.ID))
+
+		deps, err := todoRepo.GetDependencies(ctx, userID, build.ID)
+		require.NoError(t, err)
+		assert.True(t, deps.Blocked)
+		require.Len(t, deps.BlockedBy, 1)
+		assert.Equal(t, design.ID, deps.BlockedBy[0].ID)
+		assert.Empty(t, deps.Blocks)
+
+		deps, err = todoRepo.GetDependencies(ctx, userID, design.ID)
+		require.NoError(t, err)
+		assert.False(t, deps.Blocked)
+		require.Len(t, deps.Blocks, 1)
+		assert.Equal(t, build.ID, deps.Blocks[0].ID)
+	})
+
+	t.Run("rejects links that close a cycle", func(t *testing.T) {
+		first := createTestTodo(t, ctx, todoRepo, userID)
+		second := createTestTodo(t, ctx, todoRepo, userID)
+		third := createTestTodo(t, ctx, todoRepo, userID)
+
+		require.NoError(t, todoRepo.AddDependency(ctx, userID, second.ID, first.ID))
+		require.NoError(t, todoRepo.AddDependency(ctx, userID, third.ID, second.ID))
+
+		assertCode(t, todoRepo.AddDependency(ctx, userID, first.ID, third.ID), errs.CodeCircularReference)
+		assertCode(t, todoRepo.AddDependency(ctx, userID, first.ID, first.ID), errs.CodeCircularReference)
+	})
+
+	t.Run("todos of another user can't be linked", func(t *testing.T) {
+		mine := createTestTodo(t, ctx, todoRepo, userID)
+		theirs := createTestTodo(t, ctx, todoRepo, uuid.New().String())
+
+		assertCode(t, todoRepo.AddDependency(ctx, userID, mine.ID, theirs.ID), errs.CodeTodoNotFound)
+	})
+
+	t.Run("excludeBlocked hides todos until their blockers are done", func(t *testing.T) {
+		otherUser := uuid.New().String()
+		blocker := createTestTodo(t, ctx, todoRepo, otherUser)
+		waiting := createTestTodo(t, ctx, todoRepo, otherUser)
+		require.NoError(t, todoRepo.AddDependency(ctx, otherUser, waiting.ID, blocker.ID))
+
+		page := 1
+		limit := 20
+		excludeBlocked := true
+		query := &todo.GetTodosQuery{Page: &page, Limit: &limit, ExcludeBlocked: &excludeBlocked}
+
+		result, err := todoRepo.GetTodos(ctx, otherUser, query)
+		require.NoError(t, err)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, blocker.ID, result.Data[0].ID)
+
+		completed := todo.StatusCompleted
+		_, err = todoRepo.UpdateTodo(ctx, otherUser, &todo.UpdateTodoPayload{ID: blocker.ID, Status: &completed})
+		require.NoError(t, err)
+
+		result, err = todoRepo.GetTodos(ctx, otherUser, query)
+		require.NoError(t, err)
+		assert.Len(t, result.Data, 2)
+	})
+
+	t.Run("removes a link from either side", func(t *testing.T) {
+		first := createTestTodo(t, ctx, todoRepo, userID)
+		second := createTestTodo(t, ctx, todoRepo, userID)
+		require.NoError(t, todoRepo.AddDependency(ctx, userID, second.ID, first.ID))
+
+		require.NoError(t, todoRepo.RemoveDependency(ctx, userID, first.ID, second.ID))
+		assertCode(t, todoRepo.RemoveDependency(ctx, userID, second.ID, first.ID), errs.CodeDependencyNotFound)
+	})
+}
+
+func TestTodoRepository_SetPosition(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	parent := createTestTodo(t, ctx, todoRepo, userID)
+	children := make([]uuid.UUID, 0, 3)
+	for _, title := range []string{"First", "Second", "Third"} {
+		child, err := todoRepo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        title,
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+		children = append(children, child.ID)
+	}
+
+	order := func() []uuid.UUID {
+		t.Helper()
+		subtree, err := todoRepo.GetTodoByID(ctx, userID, parent.ID)
+		require.NoError(t, err)
+		ids := make([]uuid.UUID, 0, len(subtree.Children))
+		for _, child := range subtree.Children {
+			ids = append(ids, child.ID)
+		}
+		return ids
+	}
+
+	t.Run("moves a subtask to an index", func(t *testing.T) {
+		moved, err := todoRepo.SetPosition(ctx, userID, &todo.SetPositionPayload{
+			ID:    children[2],
+			Index: testing_pkg.Ptr(0),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, moved.SortOrder)
+		assert.Equal(t, []uuid.UUID{children[2], children[0], children[1]}, order())
+	})
+
+	t.Run("moves a subtask after a sibling", func(t *testing.T) {
+		_, err := todoRepo.SetPosition(ctx, userID, &todo.SetPositionPayload{
+			ID:      children[2],
+			AfterID: &children[1],
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{children[0], children[1], children[2]}, order())
+	})
+
+	t.Run("rejects placing next to a todo in another list", func(t *testing.T) {
+		_, err := todoRepo.SetPosition(ctx, userID, &todo.SetPositionPayload{
+			ID:       children[0],
+			BeforeID: &parent.ID,
+		})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeInvalidPosition, httpErr.Code)
+	})
+}
+
+func createTestTodo(t *testing.T, ctx context.Context, repo *repository.TodoRepository, userID string) *todo.Todo {
+	t.Helper()
+
+	dueDate := time.Now().Add(24 * time.Hour)
+	payload := &todo.CreateTodoPayload{
+		Title:       "Test Todo",
+		Description: testing_pkg.Ptr("Test todo description"),
+		Priority:    testing_pkg.Ptr(todo.PriorityHigh),
+		DueDate:     &dueDate,
+	}
+
+	result, err := repo.CreateTodo(ctx, userID, payload)
+	require.NoError(t, err)
+
+	return result
+}
+
+func createTestTodos(t *testing.T, ctx context.Context, repo *repository.TodoRepository, userID string, count int) []*todo.Todo {
+	t.Helper()
+
+	todos := make([]*todo.Todo, 0, count)
+
+	for i := 0; i < count; i++ {
+		dueDate := time.Now().Add(time.Duration(i+1) * 24 * time.Hour)
+		payload := &todo.CreateTodoPayload{
+			Title:       fmt.Sprintf("Test Todo %d", i+1),
+			Description: testing_pkg.Ptr(fmt.Sprintf("Test todo description %d", i+1)),
+			Priority:    testing_pkg.Ptr(todo.PriorityHigh),
+			DueDate:     &dueDate,
+		}
+
+		result, err := repo.CreateTodo(ctx, userID, payload)
+		require.NoError(t, err)
+		todos = append(todos, result)
 
 		// Add a small delay to ensure different timestamps
 		time.Sleep(10 * time.Millisecond)
@@ -517,3 +3238,204 @@ func createTestTodos(t *testing.T, ctx context.Context, repo *repository.TodoRep
 
 	return todos
 }
+
+func createTestCategory(t *testing.T, ctx context.Context, repo *repository.CategoryRepository, userID string,
+	name string,
+) *category.Category {
+	t.Helper()
+
+	result, err := repo.CreateCategory(ctx, userID, &category.CreateCategoryPayload{
+		Name:  name,
+		Color: "#3b82f6",
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func createTestTodoInCategory(t *testing.T, ctx context.Context, repo *repository.TodoRepository, userID string,
+	categoryID uuid.UUID,
+) *todo.Todo {
+	t.Helper()
+
+	result, err := repo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+		Title:      "Category Todo",
+		CategoryID: &categoryID,
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestTodoRepository_RevertTodo(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	original := createTestTodo(t, ctx, todoRepo, userID)
+	dueDate := time.Now().Add(72 * time.Hour)
+
+	edited, err := todoRepo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{
+		ID:      original.ID,
+		Title:   testing_pkg.Ptr("Renamed"),
+		Status:  testing_pkg.Ptr(todo.StatusCompleted),
+		DueDate: &dueDate,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, edited.CompletedAt)
+
+	changes := activity.Diff(activity.SnapshotTodo(original), activity.SnapshotTodo(edited))
+
+	t.Run("puts the changed fields back", func(t *testing.T) {
+		reverted, err := todoRepo.RevertTodo(ctx, userID, original.ID, changes.Previous())
+		require.NoError(t, err)
+
+		assert.Equal(t, original.Title, reverted.Title)
+		assert.Equal(t, original.Status, reverted.Status)
+		require.NotNil(t, reverted.DueDate)
+		assert.WithinDuration(t, *original.DueDate, *reverted.DueDate, time.Millisecond)
+		assert.Nil(t, reverted.CompletedAt)
+	})
+
+	t.Run("reports a todo in the trash as not found", func(t *testing.T) {
+		require.NoError(t, todoRepo.DeleteTodo(ctx, userID, original.ID))
+
+		_, err := todoRepo.RevertTodo(ctx, userID, original.ID, changes.Previous())
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+	})
+}
+
+func TestTodoRepository_ImportTodos(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	userID := uuid.New().String()
+
+	imported, skipped, err := todo.ParseICS([]byte("BEGIN:VCALENDAR\r\n"+
+		"BEGIN:VTODO\r\nSUMMARY:Call the plumber\r\nDESCRIPTION:About the leak\r\n"+
+		"DUE;TZID=Europe/Paris:20250310T143000\r\nPRIORITY:2\r\nEND:VTODO\r\n"+
+		"BEGIN:VTODO\r\nSUMMARY:File taxes\r\nDUE;VALUE=DATE:20250415\r\n"+
+		"STATUS:COMPLETED\r\nCOMPLETED:20250401T120000Z\r\nEND:VTODO\r\n"+
+		"END:VCALENDAR\r\n"), time.UTC)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+
+	created, err := todoRepo.ImportTodos(ctx, userID, imported, nil)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	plumber, err := todoRepo.GetTodoByID(ctx, userID, created[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Call the plumber", plumber.Title)
+	require.NotNil(t, plumber.Description)
+	assert.Equal(t, "About the leak", *plumber.Description)
+	require.NotNil(t, plumber.DueDate)
+	assert.Equal(t, time.Date(2025, time.March, 10, 13, 30, 0, 0, time.UTC), plumber.DueDate.UTC())
+	assert.False(t, plumber.AllDay)
+	assert.Equal(t, todo.PriorityHigh, plumber.Priority)
+	assert.Equal(t, todo.StatusActive, plumber.Status)
+	assert.Nil(t, plumber.CompletedAt)
+
+	taxes, err := todoRepo.GetTodoByID(ctx, userID, created[1].ID)
+	require.NoError(t, err)
+	assert.True(t, taxes.AllDay)
+	assert.Equal(t, time.Date(2025, time.April, 15, 0, 0, 0, 0, time.UTC), taxes.DueDate.UTC())
+	assert.Equal(t, todo.StatusCompleted, taxes.Status)
+	require.NotNil(t, taxes.CompletedAt)
+	assert.Equal(t, time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC), taxes.CompletedAt.UTC())
+}
+
+func TestTodoRepository_GetCategoryProgressTrend(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	todoRepo := repository.NewTodoRepository(testServer)
+	categoryRepo := repository.NewCategoryRepository(testServer)
+
+	userID := uuid.New().String()
+	project := createTestCategory(t, ctx, categoryRepo, userID, "Launch")
+	other := createTestCategory(t, ctx, categoryRepo, userID, "Other")
+
+	day := func(d int) time.Time {
+		return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+	setTimes := func(t *testing.T, id uuid.UUID, createdAt time.Time, completedAt *time.Time) {
+		t.Helper()
+
+		_, err := testServer.DB.Pool.Exec(ctx,
+			"UPDATE todos SET created_at = $2, completed_at = $3 WHERE id = $1", id, createdAt, completedAt)
+		require.NoError(t, err)
+	}
+	at := func(d, hour int) *time.Time {
+		tm := day(d).Add(time.Duration(hour) * time.Hour)
+		return &tm
+	}
+
+	// Four todos planned on the 3rd, one more added on the 5th, completed
+	// one at a time over the following days
+	todos := make([]*todo.Todo, 5)
+	for i := range todos {
+		todos[i] = createTestTodoInCategory(t, ctx, todoRepo, userID, project.ID)
+	}
+	setTimes(t, todos[0].ID, *at(3, 9), at(4, 10))
+	setTimes(t, todos[1].ID, *at(3, 9), at(5, 15))
+	setTimes(t, todos[2].ID, *at(3, 9), at(5, 23))
+	setTimes(t, todos[3].ID, *at(3, 9), nil)
+	setTimes(t, todos[4].ID, *at(5, 12), at(7, 8))
+
+	otherTodo := createTestTodoInCategory(t, ctx, todoRepo, userID, other.ID)
+	setTimes(t, otherTodo.ID, *at(3, 9), at(4, 9))
+
+	t.Run("daily buckets are cumulative", func(t *testing.T) {
+		points, err := todoRepo.GetCategoryProgressTrend(ctx, userID, project.ID, day(2), *at(7, 12),
+			category.ProgressBucketDay)
+		require.NoError(t, err)
+		require.Len(t, points, 6)
+
+		expected := []struct{ total, completed int }{
+			{0, 0}, // 2nd
+			{4, 0}, // 3rd
+			{4, 1}, // 4th
+			{5, 3}, // 5th
+			{5, 3}, // 6th
+			{5, 4}, // 7th
+		}
+		for i, point := range points {
+			assert.True(t, point.Start.Equal(day(2+i)), "bucket %d starts %s", i, point.Start)
+			assert.True(t, point.End.Equal(day(3+i)), "bucket %d ends %s", i, point.End)
+			assert.Equal(t, expected[i].total, point.Total, "total on day %d", 2+i)
+			assert.Equal(t, expected[i].completed, point.Completed, "completed on day %d", 2+i)
+		}
+	})
+
+	t.Run("weekly buckets start on Monday", func(t *testing.T) {
+		points, err := todoRepo.GetCategoryProgressTrend(ctx, userID, project.ID, day(4), day(12),
+			category.ProgressBucketWeek)
+		require.NoError(t, err)
+		require.Len(t, points, 2)
+
+		// March 3rd 2025 is a Monday
+		assert.True(t, points[0].Start.Equal(day(3)))
+		assert.Equal(t, 5, points[0].Total)
+		assert.Equal(t, 4, points[0].Completed)
+		assert.True(t, points[1].Start.Equal(day(10)))
+		assert.Equal(t, 4, points[1].Completed)
+	})
+
+	t.Run("another user's category is empty", func(t *testing.T) {
+		points, err := todoRepo.GetCategoryProgressTrend(ctx, uuid.New().String(), project.ID, day(3), day(4),
+			category.ProgressBucketDay)
+		require.NoError(t, err)
+		for _, point := range points {
+			assert.Zero(t, point.Total)
+		}
+	})
+}
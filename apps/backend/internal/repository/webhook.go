@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/webhook"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type WebhookRepository struct {
+	server *server.Server
+}
+
+func NewWebhookRepository(server *server.Server) *WebhookRepository {
+	return &WebhookRepository{server: server}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, userID string, payload *webhook.CreateWebhookPayload,
+	secret string,
+) (*webhook.Webhook, error) {
+	stmt := `
+		INSERT INTO
+			webhooks (user_id, url, secret, events, description)
+		VALUES
+			(@user_id, @url, @secret, @events, @description)
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"url":         payload.URL,
+		"secret":      secret,
+		"events":      payload.Events,
+		"description": payload.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create webhook query for user_id=%s: %w", userID, err)
+	}
+
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Webhook])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:webhooks for user_id=%s: %w", userID, err)
+	}
+
+	return &created, nil
+}
+
+func (r *WebhookRepository) CountWebhooks(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.server.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM webhooks WHERE user_id=@user_id",
+		pgx.NamedArgs{"user_id": userID}).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count webhooks for user_id=%s: %w", userID, err)
+	}
+
+	return count, nil
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context, userID string) ([]webhook.Webhook, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			webhooks
+		WHERE
+			user_id=@user_id
+		ORDER BY
+			created_at ASC
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get webhooks query for user_id=%s: %w", userID, err)
+	}
+
+	webhooks, err := pgx.CollectRows(rows, pgx.RowToStructByName[webhook.Webhook])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:webhooks for user_id=%s: %w", userID, err)
+	}
+
+	return webhooks, nil
+}
+
+// GetSubscribedWebhooks returns the user's active webhooks that are sent the event
+func (r *WebhookRepository) GetSubscribedWebhooks(ctx context.Context, userID string,
+	event string,
+) ([]webhook.Webhook, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			webhooks
+		WHERE
+			user_id=@user_id
+			AND active
+			AND @event=ANY (events)
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"event":   event,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get subscribed webhooks query for user_id=%s event=%s: %w",
+			userID, event, err)
+	}
+
+	webhooks, err := pgx.CollectRows(rows, pgx.RowToStructByName[webhook.Webhook])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:webhooks for user_id=%s: %w", userID, err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetWebhookByID(ctx context.Context, userID string, webhookID uuid.UUID,
+) (*webhook.Webhook, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			webhooks
+		WHERE
+			id=@id
+			AND user_id=@user_id
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"id":      webhookID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get webhook by id query for webhook_id=%s user_id=%s: %w",
+			webhookID.String(), userID, err)
+	}
+
+	found, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Webhook])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeWebhookNotFound
+			return nil, errs.NewNotFoundError("webhook not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:webhooks for webhook_id=%s user_id=%s: %w",
+			webhookID.String(), userID, err)
+	}
+
+	return &found, nil
+}
+
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, userID string,
+	payload *webhook.UpdateWebhookPayload,
+) (*webhook.Webhook, error) {
+	args := pgx.NamedArgs{
+		"id":      payload.ID,
+		"user_id": userID,
+	}
+	setClauses := []string{}
+
+	if payload.URL != nil {
+		setClauses = append(setClauses, "url = @url")
+		args["url"] = *payload.URL
+	}
+
+	if payload.Secret != nil {
+		setClauses = append(setClauses, "secret = @secret")
+		args["secret"] = *payload.Secret
+	}
+
+	if payload.Events != nil {
+		setClauses = append(setClauses, "events = @events")
+		args["events"] = payload.Events
+	}
+
+	if payload.Description != nil {
+		setClauses = append(setClauses, "description = @description")
+		args["description"] = *payload.Description
+	}
+
+	if payload.Active != nil {
+		setClauses = append(setClauses, "active = @active")
+		args["active"] = *payload.Active
+	}
+
+	if len(setClauses) == 0 {
+		return nil, errs.NewBadRequestError("no fields to update", false, nil, nil, nil)
+	}
+
+	stmt := "UPDATE webhooks SET " + strings.Join(setClauses, ", ") +
+		" WHERE id = @id AND user_id = @user_id RETURNING *"
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute update webhook query for webhook_id=%s: %w", payload.ID.String(), err)
+	}
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Webhook])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			code := errs.CodeWebhookNotFound
+			return nil, errs.NewNotFoundError("webhook not found", false, &code)
+		}
+		return nil, fmt.Errorf("failed to collect row from table:webhooks for webhook_id=%s: %w",
+			payload.ID.String(), err)
+	}
+
+	return &updated, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, userID string, webhookID uuid.UUID) error {
+	stmt := `
+		DELETE FROM webhooks
+		WHERE
+			id=@id
+			AND user_id=@user_id
+	`
+
+	result, err := r.server.DB.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"id":      webhookID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute delete webhook query for webhook_id=%s user_id=%s: %w",
+			webhookID.String(), userID, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		code := errs.CodeWebhookNotFound
+		return errs.NewNotFoundError("webhook not found", false, &code)
+	}
+
+	return nil
+}
+
+// CreateDelivery records a pending delivery of the payload to the webhook
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, hook *webhook.Webhook,
+	payload *webhook.Payload,
+) (*webhook.Delivery, error) {
+	stmt := `
+		INSERT INTO
+			webhook_deliveries (id, webhook_id, user_id, event, payload)
+		VALUES
+			(@id, @webhook_id, @user_id, @event, @payload)
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"id":         payload.ID,
+		"webhook_id": hook.ID,
+		"user_id":    hook.UserID,
+		"event":      payload.Event,
+		"payload":    payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create delivery query for webhook_id=%s: %w", hook.ID.String(), err)
+	}
+
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Delivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:webhook_deliveries for webhook_id=%s: %w",
+			hook.ID.String(), err)
+	}
+
+	return &created, nil
+}
+
+// GetDeliveryTarget returns a delivery with the webhook it goes to. It isn't
+// scoped to a user since the background job delivering it acts for no one.
+func (r *WebhookRepository) GetDeliveryTarget(ctx context.Context, deliveryID uuid.UUID,
+) (*webhook.Delivery, *webhook.Webhook, error) {
+	rows, err := r.server.DB.Pool.Query(ctx, "SELECT * FROM webhook_deliveries WHERE id=@id",
+		pgx.NamedArgs{"id": deliveryID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute get delivery query for delivery_id=%s: %w",
+			deliveryID.String(), err)
+	}
+
+	delivery, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Delivery])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errs.NewNotFoundError("webhook delivery not found", false, nil)
+		}
+		return nil, nil, fmt.Errorf("failed to collect row from table:webhook_deliveries for delivery_id=%s: %w",
+			deliveryID.String(), err)
+	}
+
+	hook, err := r.GetWebhookByID(ctx, delivery.UserID, delivery.WebhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &delivery, hook, nil
+}
+
+// RecordAttempt saves the outcome of an attempt. A failed attempt leaves the
+// delivery pending unless it was the last one.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, deliveryID uuid.UUID, attempt *webhook.Attempt,
+	final bool,
+) (*webhook.Delivery, error) {
+	status := webhook.DeliveryPending
+	switch {
+	case attempt.Succeeded():
+		status = webhook.DeliverySucceeded
+	case final:
+		status = webhook.DeliveryFailed
+	}
+
+	stmt := `
+		UPDATE webhook_deliveries
+		SET
+			attempts=attempts + 1,
+			status=@status,
+			response_status=@response_status,
+			response_body=@response_body,
+			error=@error,
+			delivered_at=CASE
+				WHEN @status='succeeded' THEN NOW()
+				ELSE delivered_at
+			END
+		WHERE
+			id=@id
+		RETURNING
+			*
+	`
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"id":              deliveryID,
+		"status":          status,
+		"response_status": attempt.StatusCode,
+		"response_body":   attempt.Body,
+		"error":           attempt.Error,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute record attempt query for delivery_id=%s: %w", deliveryID.String(), err)
+	}
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[webhook.Delivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:webhook_deliveries for delivery_id=%s: %w",
+			deliveryID.String(), err)
+	}
+
+	return &updated, nil
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, userID string,
+	query *webhook.GetDeliveriesQuery,
+) (*model.PaginatedResponse[webhook.Delivery], error) {
+	conditions := []string{"user_id = @user_id", "webhook_id = @webhook_id"}
+	args := pgx.NamedArgs{
+		"user_id":    userID,
+		"webhook_id": query.ID,
+	}
+
+	if query.Status != nil {
+		conditions = append(conditions, "status = @status")
+		args["status"] = *query.Status
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	err := r.server.DB.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM webhook_deliveries"+where, args).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count for webhook_deliveries webhook_id=%s: %w",
+			query.ID.String(), err)
+	}
+
+	stmt := "SELECT * FROM webhook_deliveries" + where +
+		" ORDER BY created_at DESC LIMIT @limit OFFSET @offset"
+	args["limit"] = *query.Limit
+	args["offset"] = (*query.Page - 1) * (*query.Limit)
+
+	rows, err := r.server.DB.Pool.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get deliveries query for webhook_id=%s: %w", query.ID.String(), err)
+	}
+
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[webhook.Delivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:webhook_deliveries for webhook_id=%s: %w",
+			query.ID.String(), err)
+	}
+
+	return &model.PaginatedResponse[webhook.Delivery]{
+		Data:       deliveries,
+		Page:       *query.Page,
+		Limit:      *query.Limit,
+		Total:      total,
+		TotalPages: (total + *query.Limit - 1) / *query.Limit,
+	}, nil
+}
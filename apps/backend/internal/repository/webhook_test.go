@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/webhook"
+	"github.com/sriniously/tasker/internal/repository"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	webhookRepo := repository.NewWebhookRepository(testServer)
+	userID := uuid.New().String()
+
+	hook, err := webhookRepo.CreateWebhook(ctx, userID, &webhook.CreateWebhookPayload{
+		URL:    "https://example.com/hooks",
+		Events: []string{webhook.EventTodoCreated, webhook.EventCommentAdded},
+	}, "whsec_test")
+	require.NoError(t, err)
+	assert.True(t, hook.Active)
+	assert.Equal(t, "whsec_test", hook.Secret)
+
+	t.Run("only active subscribers are sent an event", func(t *testing.T) {
+		subscribed, err := webhookRepo.GetSubscribedWebhooks(ctx, userID, webhook.EventTodoCreated)
+		require.NoError(t, err)
+		require.Len(t, subscribed, 1)
+
+		subscribed, err = webhookRepo.GetSubscribedWebhooks(ctx, userID, webhook.EventTodoUpdated)
+		require.NoError(t, err)
+		assert.Empty(t, subscribed)
+
+		paused := false
+		_, err = webhookRepo.UpdateWebhook(ctx, userID, &webhook.UpdateWebhookPayload{ID: hook.ID, Active: &paused})
+		require.NoError(t, err)
+		subscribed, err = webhookRepo.GetSubscribedWebhooks(ctx, userID, webhook.EventTodoCreated)
+		require.NoError(t, err)
+		assert.Empty(t, subscribed)
+
+		active := true
+		_, err = webhookRepo.UpdateWebhook(ctx, userID, &webhook.UpdateWebhookPayload{ID: hook.ID, Active: &active})
+		require.NoError(t, err)
+	})
+
+	t.Run("records attempts until the delivery succeeds", func(t *testing.T) {
+		payload := &webhook.Payload{ID: uuid.New(), Event: webhook.EventTodoCreated, Data: json.RawMessage(`{}`)}
+		delivery, err := webhookRepo.CreateDelivery(ctx, hook, payload)
+		require.NoError(t, err)
+		assert.Equal(t, payload.ID, delivery.ID)
+		assert.Equal(t, webhook.DeliveryPending, delivery.Status)
+
+		failure := "connection refused"
+		delivery, err = webhookRepo.RecordAttempt(ctx, delivery.ID, &webhook.Attempt{Error: &failure}, false)
+		require.NoError(t, err)
+		assert.Equal(t, webhook.DeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+
+		status := http.StatusNoContent
+		delivery, err = webhookRepo.RecordAttempt(ctx, delivery.ID, &webhook.Attempt{StatusCode: &status}, false)
+		require.NoError(t, err)
+		assert.Equal(t, webhook.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+
+		found, target, err := webhookRepo.GetDeliveryTarget(ctx, delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, delivery.ID, found.ID)
+		assert.Equal(t, hook.ID, target.ID)
+
+		page, limit := 1, 20
+		deliveries, err := webhookRepo.GetDeliveries(ctx, userID, &webhook.GetDeliveriesQuery{
+			ID: hook.ID, Page: &page, Limit: &limit,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, deliveries.Total)
+	})
+
+	t.Run("other users can't see the webhook", func(t *testing.T) {
+		_, err := webhookRepo.GetWebhookByID(ctx, uuid.New().String(), hook.ID)
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Status)
+	})
+
+	t.Run("deleting the webhook removes its deliveries", func(t *testing.T) {
+		require.NoError(t, webhookRepo.DeleteWebhook(ctx, userID, hook.ID))
+
+		count, err := webhookRepo.CountWebhooks(ctx, userID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
@@ -49,15 +49,23 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 		middlewares.ContextEnhancer.EnhanceContext(),
 		middlewares.Global.RequestLogger(),
 		middlewares.Global.Recover(),
+		middlewares.Global.CacheControl(),
 	)
 
 	// register system routes
 	registerSystemRoutes(router, h)
 
-	// register versioned routes
-	v1Router := router.Group("/api/v1")
+	// register versioned routes; only API requests count toward the in-flight
+	// limit so health checks keep answering under load
+	v1Router := router.Group("/api/v1", middlewares.InFlight.Limit())
 
 	v1.RegisterV1Routes(v1Router, h, middlewares)
 
+	// streaming connections stay open for as long as the client listens, so
+	// they're kept out of the in-flight limit
+	streamRouter := router.Group("/api/v1")
+
+	v1.RegisterStreamRoutes(streamRouter, h, middlewares)
+
 	return router
 }
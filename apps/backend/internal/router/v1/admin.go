@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/model/admin"
+)
+
+func registerAdminRoutes(r *echo.Group, h *handler.AdminHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware, docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Admin")
+
+	// Admin operations
+	adminRoutes := r.Group("/admin")
+	adminRoutes.Use(auth.RequireAuth, auth.RequireAdmin, idempotency.Idempotent)
+
+	// Support tooling
+	api.Add(adminRoutes.POST("/impersonate/:userId", h.ImpersonateUser),
+		handler.Describe[*admin.ImpersonateUserPayload, *admin.ImpersonationToken]("ImpersonateUser", http.StatusCreated))
+	api.Add(adminRoutes.GET("/config", h.GetConfig),
+		handler.Describe[*admin.GetConfigPayload, *config.Config]("GetConfig", http.StatusOK))
+
+	// Compliance
+	api.Add(adminRoutes.GET("/attachment-access", h.GetAttachmentAccessLogs),
+		handler.Describe[*admin.GetAttachmentAccessLogsQuery, *model.PaginatedResponse[admin.AttachmentAccessLog]](
+			"GetAttachmentAccessLogs", http.StatusOK))
+
+	// Offboarding
+	api.Add(adminRoutes.POST("/users/:id/reassign", h.ReassignUser),
+		handler.Describe[*admin.ReassignUserPayload, *admin.ReassignResult]("ReassignUser", http.StatusOK))
+	api.Add(adminRoutes.POST("/users/:id/recover", h.RecoverAccount),
+		handler.Describe[*admin.RecoverAccountPayload, *account.Deletion]("RecoverAccount", http.StatusOK))
+}
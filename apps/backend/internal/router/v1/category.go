@@ -1,22 +1,50 @@
 package v1
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/sriniously/tasker/internal/handler"
 	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/category"
 )
 
-func registerCategoryRoutes(r *echo.Group, h *handler.CategoryHandler, auth *middleware.AuthMiddleware) {
+func registerCategoryRoutes(r *echo.Group, h *handler.CategoryHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Category")
+
 	// Category operations
 	categories := r.Group("/categories")
-	categories.Use(auth.RequireAuth)
+	categories.Use(auth.RequireAuth, idempotency.Idempotent)
 
 	// Category collection operations
-	categories.POST("", h.CreateCategory)
-	categories.GET("", h.GetCategories)
+	api.Add(categories.POST("", h.CreateCategory),
+		handler.Describe[*category.CreateCategoryPayload, *category.CategoryWithWarnings](
+			"CreateCategory", http.StatusCreated))
+	api.Add(categories.GET("", h.GetCategories),
+		handler.Describe[*category.GetCategoriesQuery, *model.PaginatedResponse[category.Category]](
+			"GetCategories", http.StatusOK))
+	api.Add(categories.POST("/restore-grouping", h.RestoreGrouping),
+		handler.Describe[*category.RestoreGroupingPayload, *category.RestoreGroupingResponse](
+			"RestoreGrouping", http.StatusCreated))
 
 	// Individual category operations
 	dynamicCategory := categories.Group("/:id")
-	dynamicCategory.PATCH("", h.UpdateCategory)
-	dynamicCategory.DELETE("", h.DeleteCategory)
+	api.Add(dynamicCategory.PATCH("", h.UpdateCategory),
+		handler.Describe[*category.UpdateCategoryPayload, *category.CategoryWithWarnings]("UpdateCategory", http.StatusOK))
+	api.Add(dynamicCategory.DELETE("", h.DeleteCategory),
+		handler.DescribeNoContent[*category.DeleteCategoryPayload]("DeleteCategory", http.StatusNoContent))
+	api.Add(dynamicCategory.POST("/archive-todos", h.ArchiveCategoryTodos),
+		handler.Describe[*category.ArchiveCategoryTodosPayload, *category.ArchiveCategoryTodosResponse](
+			"ArchiveCategoryTodos", http.StatusOK))
+	api.Add(dynamicCategory.PATCH("/tags/replace", h.ReplaceTag),
+		handler.Describe[*category.ReplaceTagPayload, *category.ReplaceTagResponse]("ReplaceTag", http.StatusOK))
+	api.Add(dynamicCategory.POST("/merge", h.MergeCategories),
+		handler.Describe[*category.MergeCategoriesPayload, *category.MergeCategoriesResponse](
+			"MergeCategories", http.StatusOK))
+	api.Add(dynamicCategory.GET("/progress-trend", h.GetProgressTrend),
+		handler.Describe[*category.GetProgressTrendQuery, *category.ProgressTrend]("GetProgressTrend", http.StatusOK))
 }
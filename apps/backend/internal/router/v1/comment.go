@@ -1,18 +1,27 @@
 package v1
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/sriniously/tasker/internal/handler"
 	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/comment"
 )
 
-func registerCommentRoutes(r *echo.Group, h *handler.CommentHandler, auth *middleware.AuthMiddleware) {
+func registerCommentRoutes(r *echo.Group, h *handler.CommentHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware, docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Comment")
+
 	// Comment operations
 	comments := r.Group("/comments")
-	comments.Use(auth.RequireAuth)
+	comments.Use(auth.RequireAuth, idempotency.Idempotent)
 
 	// Individual comment operations
 	dynamicComment := comments.Group("/:id")
-	dynamicComment.PATCH("", h.UpdateComment)
-	dynamicComment.DELETE("", h.DeleteComment)
+	api.Add(dynamicComment.PATCH("", h.UpdateComment),
+		handler.Describe[*comment.UpdateCommentPayload, *comment.Comment]("UpdateComment", http.StatusOK))
+	api.Add(dynamicComment.DELETE("", h.DeleteComment),
+		handler.DescribeNoContent[*comment.DeleteCommentPayload]("DeleteComment", http.StatusNoContent))
 }
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/dashboard"
+)
+
+func registerDashboardRoutes(r *echo.Group, h *handler.DashboardHandler, auth *middleware.AuthMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Dashboard")
+
+	// Overview of the current user's todos, composed from sections that load
+	// independently
+	api.Add(r.GET("/dashboard", h.GetDashboard, auth.RequireAuth),
+		handler.Describe[*dashboard.GetDashboardPayload, *dashboard.Dashboard]("GetDashboard", http.StatusOK))
+}
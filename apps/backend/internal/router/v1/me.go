@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/sriniously/tasker/internal/model/notification"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/model/streak"
+)
+
+func registerMeRoutes(r *echo.Group, h *handler.PreferenceHandler, ah *handler.ActivityHandler,
+	nh *handler.NotificationHandler, sh *handler.StreakHandler, ach *handler.AccountHandler,
+	dh *handler.DashboardHandler, auth *middleware.AuthMiddleware, idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Preference")
+	activityAPI := docs.Tag("Activity")
+	notificationAPI := docs.Tag("Notification")
+	streakAPI := docs.Tag("Streak")
+	accountAPI := docs.Tag("Account")
+	dashboardAPI := docs.Tag("Dashboard")
+
+	// Current user operations
+	me := r.Group("/me")
+	me.Use(auth.RequireAuth, idempotency.Idempotent)
+
+	api.Add(me.GET("/preferences", h.GetPreferences),
+		handler.Describe[*preference.GetPreferencesPayload, *preference.Preferences]("GetPreferences", http.StatusOK))
+	api.Add(me.PATCH("/preferences", h.UpdatePreferences),
+		handler.Describe[*preference.UpdatePreferencesPayload, *preference.Preferences]("UpdatePreferences", http.StatusOK))
+
+	activityAPI.Add(me.GET("/activity", ah.GetActivities),
+		handler.Describe[*activity.GetActivitiesQuery, *model.PaginatedResponse[activity.Activity]](
+			"GetActivities", http.StatusOK))
+
+	notificationAPI.Add(me.GET("/notifications/preview", nh.PreviewNotification),
+		handler.Describe[*notification.PreviewPayload, *notification.Preview]("PreviewNotification", http.StatusOK))
+
+	streakAPI.Add(me.GET("/streak", sh.GetStreak),
+		handler.Describe[*streak.GetStreakPayload, *streak.Streak]("GetStreak", http.StatusOK))
+
+	dashboardAPI.Add(me.GET("/weekly-review", dh.GetWeeklyReview),
+		handler.Describe[*dashboard.GetWeeklyReviewQuery, *dashboard.WeeklyReview]("GetWeeklyReview", http.StatusOK))
+
+	// Recoverable by an admin until the retention window passes
+	accountAPI.Add(me.DELETE("", ach.DeleteAccount),
+		handler.Describe[*account.DeleteAccountPayload, *account.Deletion]("DeleteAccount", http.StatusAccepted))
+}
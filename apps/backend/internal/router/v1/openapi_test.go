@@ -0,0 +1,112 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/lib/openapi"
+	"github.com/sriniously/tasker/internal/middleware"
+	v1 "github.com/sriniously/tasker/internal/router/v1"
+	"github.com/sriniously/tasker/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newV1Router registers the v1 routes the way the server does. Handlers are
+// left nil: the document is built from what the routes were registered with
+// and never calls them.
+func newV1Router(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	s := &server.Server{Logger: &logger, Config: &config.Config{}}
+	middlewares := middleware.NewMiddlewares(s)
+	handlers := &handler.Handlers{OpenAPI: handler.NewOpenAPIHandler(s)}
+
+	e := echo.New()
+	v1.RegisterV1Routes(e.Group("/api/v1"), handlers, middlewares)
+	v1.RegisterStreamRoutes(e.Group("/api/v1"), handlers, middlewares)
+
+	return e
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	e := newV1Router(t)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "/api/v1", doc.Servers[0].URL)
+
+	pathParam := regexp.MustCompile(`:(\w+)`)
+	operation := func(method, path string) *openapi.Operation {
+		item := doc.Paths[pathParam.ReplaceAllString(path, "{$1}")]
+		if item == nil {
+			return nil
+		}
+		return (*item)[strings.ToLower(method)]
+	}
+
+	t.Run("every route is documented with its response", func(t *testing.T) {
+		for _, route := range e.Routes() {
+			path, ok := strings.CutPrefix(route.Path, "/api/v1")
+			if !ok || strings.HasPrefix(route.Method, "echo_") {
+				continue
+			}
+
+			op := operation(route.Method, path)
+			require.NotNil(t, op, "%s %s", route.Method, path)
+
+			for status, response := range op.Responses {
+				if status == "default" || status == "204" || status == "101" {
+					continue
+				}
+				assert.NotEmpty(t, response.Content, "%s %s has an undescribed %s response",
+					route.Method, path, status)
+			}
+		}
+	})
+
+	t.Run("public routes exist and need no token", func(t *testing.T) {
+		for _, route := range v1.PublicRoutes {
+			method, path, _ := strings.Cut(route, " ")
+			op := operation(method, path)
+			require.NotNil(t, op, route)
+			require.NotNil(t, op.Security, route)
+			assert.Empty(t, *op.Security, route)
+		}
+
+		assert.Nil(t, operation(http.MethodPost, "/todos").Security)
+	})
+
+	t.Run("operations are named after their handlers", func(t *testing.T) {
+		assert.Equal(t, "getTodos", operation(http.MethodGet, "/todos").OperationID)
+		assert.Equal(t, "organizationsGetTodos", operation(http.MethodGet, "/organizations/:orgId/todos").OperationID)
+
+		create := operation(http.MethodPost, "/templates")
+		assert.Equal(t, "createTemplate", create.OperationID)
+		assert.Equal(t, "Create template", create.Summary)
+		assert.Equal(t, "#/components/schemas/template.CreateTemplatePayload",
+			create.RequestBody.Content[echo.MIMEApplicationJSON].Schema.Ref)
+		assert.Equal(t, "#/components/schemas/template.Template",
+			create.Responses["201"].Content[echo.MIMEApplicationJSON].Schema.Ref)
+	})
+
+	t.Run("uploads are multipart", func(t *testing.T) {
+		upload := operation(http.MethodPost, "/todos/:id/attachments")
+		form := upload.RequestBody.Content[echo.MIMEMultipartForm]
+		require.NotNil(t, form)
+		assert.Equal(t, "binary", form.Schema.Properties["file"].Format)
+	})
+}
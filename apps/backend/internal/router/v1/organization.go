@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+func registerOrganizationRoutes(r *echo.Group, h *handler.OrganizationHandler, th *handler.TodoHandler,
+	auth *middleware.AuthMiddleware, org *middleware.OrganizationMiddleware,
+	idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Organization")
+	todoAPI := docs.Tag("Todo")
+
+	// Organization operations
+	orgs := r.Group("/organizations")
+	orgs.Use(auth.RequireAuth, idempotency.Idempotent)
+
+	api.Add(orgs.POST("", h.CreateOrganization),
+		handler.Describe[*organization.CreateOrganizationPayload, *organization.OrganizationWithRole](
+			"CreateOrganization", http.StatusCreated))
+	api.Add(orgs.GET("", h.GetOrganizations),
+		handler.Describe[*organization.GetOrganizationsPayload, []organization.OrganizationWithRole](
+			"GetOrganizations", http.StatusOK))
+
+	// Everything under an organization requires membership
+	dynamicOrg := orgs.Group("/:orgId", org.ResolveOrg)
+
+	// Organization members
+	orgMembers := dynamicOrg.Group("/members")
+	api.Add(orgMembers.GET("", h.GetMembers),
+		handler.Describe[*organization.GetMembersPayload, []organization.Membership]("GetMembers", http.StatusOK))
+	api.Add(orgMembers.POST("", h.AddMember),
+		handler.Describe[*organization.AddMemberPayload, *organization.Membership]("AddMember", http.StatusOK))
+	api.Add(orgMembers.DELETE("/:userId", h.RemoveMember),
+		handler.DescribeNoContent[*organization.RemoveMemberPayload]("RemoveMember", http.StatusNoContent))
+
+	// Organization todos, equivalent to the todo routes with X-Organization-ID
+	orgTodos := dynamicOrg.Group("/todos")
+	todoAPI.Add(orgTodos.POST("", th.CreateTodo),
+		handler.Describe[*todo.CreateTodoPayload, *todo.TodoWithWarnings]("CreateTodo", http.StatusCreated))
+	todoAPI.Add(orgTodos.GET("", th.GetTodos),
+		handler.Describe[*todo.GetTodosQuery, *model.PaginatedResponse[todo.PopulatedTodo]]("GetTodos", http.StatusOK))
+	todoAPI.Add(orgTodos.GET("/:id", th.GetTodoByID),
+		handler.Describe[*todo.GetTodoByIDPayload, *todo.PopulatedTodo]("GetTodoByID", http.StatusOK))
+	todoAPI.Add(orgTodos.PATCH("/:id", th.UpdateTodo),
+		handler.Describe[*todo.UpdateTodoPayload, *todo.TodoWithWarnings]("UpdateTodo", http.StatusOK))
+	todoAPI.Add(orgTodos.DELETE("/:id", th.DeleteTodo),
+		handler.DescribeNoContent[*todo.DeleteTodoPayload]("DeleteTodo", http.StatusNoContent))
+}
@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/activity"
+)
+
+func registerRealtimeRoutes(r *echo.Group, h *handler.RealtimeHandler, th *handler.TodoHandler,
+	auth *middleware.AuthMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Realtime")
+	todoAPI := docs.Tag("Todo")
+
+	// Browsers can't set headers when opening a WebSocket or EventSource, so
+	// the session token may come in the query instead
+	api.Add(r.GET("/ws", h.WebSocket, auth.TokenFromQuery, auth.RequireAuth),
+		handler.DescribeRaw("WebSocket", http.StatusSwitchingProtocols, ""))
+	// The same events as Server-Sent Events, resumable with Last-Event-ID
+	api.Add(r.GET("/events", h.Events, auth.TokenFromQuery, auth.RequireAuth),
+		handler.DescribeRaw("Events", http.StatusOK, "text/event-stream"))
+	// Long-poll alternative to streaming: held open until the user's todos
+	// change or the wait runs out
+	todoAPI.Add(r.GET("/todos/changes", th.GetChangeFeed, auth.RequireAuth),
+		handler.Describe[*activity.GetChangeFeedQuery, *activity.ChangeFeed]("GetChangeFeed", http.StatusOK))
+}
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/tag"
+)
+
+func registerTagRoutes(r *echo.Group, h *handler.TagHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware, docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Tag")
+
+	// Tag operations
+	tags := r.Group("/tags")
+	tags.Use(auth.RequireAuth, idempotency.Idempotent)
+
+	api.Add(tags.GET("", h.GetTags), handler.Describe[*tag.GetTagsQuery, []tag.Tag]("GetTags", http.StatusOK))
+
+	// Individual tag operations
+	dynamicTag := tags.Group("/:id")
+	api.Add(dynamicTag.PATCH("", h.RenameTag),
+		handler.Describe[*tag.RenameTagPayload, *tag.RenameTagResponse]("RenameTag", http.StatusOK))
+	api.Add(dynamicTag.DELETE("", h.DeleteTag),
+		handler.DescribeNoContent[*tag.DeleteTagPayload]("DeleteTag", http.StatusNoContent))
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+)
+
+func registerTemplateRoutes(r *echo.Group, h *handler.TemplateHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Template")
+
+	// Template operations
+	templates := r.Group("/templates")
+	templates.Use(auth.RequireAuth, idempotency.Idempotent)
+
+	// Template collection operations
+	api.Add(templates.POST("", h.CreateTemplate),
+		handler.Describe[*template.CreateTemplatePayload, *template.Template]("CreateTemplate", http.StatusCreated))
+	api.Add(templates.GET("", h.GetTemplates),
+		handler.Describe[*template.GetTemplatesQuery, []template.Template]("GetTemplates", http.StatusOK))
+
+	// Individual template operations
+	dynamicTemplate := templates.Group("/:id")
+	api.Add(dynamicTemplate.GET("", h.GetTemplateByID),
+		handler.Describe[*template.GetTemplatePayload, *template.Template]("GetTemplateByID", http.StatusOK))
+	api.Add(dynamicTemplate.DELETE("", h.DeleteTemplate),
+		handler.DescribeNoContent[*template.DeleteTemplatePayload]("DeleteTemplate", http.StatusNoContent))
+	api.Add(dynamicTemplate.POST("/instantiate", h.InstantiateTemplate),
+		handler.Describe[*template.InstantiateTemplatePayload, *todo.Todo]("InstantiateTemplate", http.StatusCreated))
+}
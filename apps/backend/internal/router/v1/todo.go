@@ -1,35 +1,190 @@
 package v1
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/lib/feed"
 	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/todo"
 )
 
-func registerTodoRoutes(r *echo.Group, h *handler.TodoHandler, ch *handler.CommentHandler, auth *middleware.AuthMiddleware) {
+// maxConcurrentImports is how many imports one server runs at once
+const maxConcurrentImports = 4
+
+func registerTodoRoutes(r *echo.Group, h *handler.TodoHandler, ch *handler.CommentHandler, auth *middleware.AuthMiddleware,
+	org *middleware.OrganizationMiddleware, uploads *middleware.UploadLimitMiddleware,
+	inFlight *middleware.InFlightMiddleware, idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Todo")
+	commentAPI := docs.Tag("Comment")
+
+	// Feeds authenticate with a feed token instead of the session
+	api.Add(r.GET("/todos/feed/completed.atom", h.GetCompletedFeed),
+		handler.DescribeBlob[*todo.GetCompletedFeedQuery]("GetCompletedFeed", http.StatusOK, feed.AtomContentType))
+
+	// Share links are the only credential for shared todos
+	api.Add(r.GET("/shared/todos/:token", h.GetSharedTodo),
+		handler.Describe[*todo.GetSharedTodoPayload, *todo.SharedTodo]("GetSharedTodo", http.StatusOK))
+
 	// Todo operations
 	todos := r.Group("/todos")
-	todos.Use(auth.RequireAuth)
+	// X-Organization-ID switches listing, fetching and creating todos to the
+	// organization's todos. Clients retrying a request whose response they
+	// never saw send it with the same Idempotency-Key.
+	todos.Use(auth.RequireAuth, org.ResolveOrg, idempotency.Idempotent)
 
 	// Collection operations
-	todos.POST("", h.CreateTodo)
-	todos.GET("", h.GetTodos)
-	todos.GET("/stats", h.GetTodoStats)
+	api.Add(todos.POST("", h.CreateTodo),
+		handler.Describe[*todo.CreateTodoPayload, *todo.TodoWithWarnings]("CreateTodo", http.StatusCreated))
+	api.Add(todos.POST("/quick-add", h.QuickAddTodo),
+		handler.Describe[*todo.QuickAddTodoPayload, *todo.QuickAddResult]("QuickAddTodo", http.StatusCreated))
+	api.Add(todos.GET("", h.GetTodos),
+		handler.Describe[*todo.GetTodosQuery, *model.PaginatedResponse[todo.PopulatedTodo]]("GetTodos", http.StatusOK))
+	api.Add(todos.GET("/count", h.CountTodos),
+		handler.Describe[*todo.GetTodosQuery, *todo.TodoCount]("CountTodos", http.StatusOK))
+	api.Add(todos.POST("/snapshots", h.CreateListSnapshot),
+		handler.Describe[*todo.CreateListSnapshotPayload, *todo.ListSnapshot]("CreateListSnapshot", http.StatusCreated))
+	api.Add(todos.DELETE("/snapshots/:token", h.DeleteListSnapshot),
+		handler.DescribeNoContent[*todo.DeleteListSnapshotPayload]("DeleteListSnapshot", http.StatusNoContent))
+	api.Add(todos.GET("/stats", h.GetTodoStats),
+		handler.Describe[*todo.GetTodoStatsPayload, *todo.TodoStats]("GetTodoStats", http.StatusOK))
+	api.Add(todos.GET("/stats/filtered", h.GetFilteredTodoStats),
+		handler.Describe[*todo.GetTodosQuery, *todo.TodoStats]("GetFilteredTodoStats", http.StatusOK))
+	api.Add(todos.GET("/stats/cycle-time", h.GetCycleTimeStats),
+		handler.Describe[*todo.GetCycleTimeQuery, *todo.CycleTimeStats]("GetCycleTimeStats", http.StatusOK))
+	api.Add(todos.GET("/has-overdue", h.HasOverdue),
+		handler.Describe[*todo.HasOverduePayload, *todo.OverdueIndicator]("HasOverdue", http.StatusOK))
+	api.Add(todos.GET("/overdue-buckets", h.GetOverdueBuckets),
+		handler.Describe[*todo.GetOverdueBucketsQuery, *todo.OverdueBuckets]("GetOverdueBuckets", http.StatusOK))
+	api.Add(todos.GET("/deferred", h.GetDeferredTodos),
+		handler.Describe[*todo.GetDeferredTodosPayload, []todo.Todo]("GetDeferredTodos", http.StatusOK))
+	api.Add(todos.GET("/stale", h.GetStaleTodos),
+		handler.Describe[*todo.GetStaleTodosQuery, []todo.Todo]("GetStaleTodos", http.StatusOK))
+	api.Add(todos.GET("/incomplete", h.GetIncompleteTodos),
+		handler.Describe[*todo.GetIncompleteTodosQuery, []todo.Todo]("GetIncompleteTodos", http.StatusOK))
+	// Deleted todos stay in the trash until restored or purged
+	api.Add(todos.GET("/trash", h.GetTrash),
+		handler.Describe[*todo.GetTrashQuery, *model.PaginatedResponse[todo.Todo]]("GetTrash", http.StatusOK))
+	api.Add(todos.DELETE("/trash/:id", h.PurgeTodo),
+		handler.DescribeNoContent[*todo.PurgeTodoPayload]("PurgeTodo", http.StatusNoContent))
+	// Issuing a feed token replaces the previous one
+	api.Add(todos.POST("/feed/token", h.CreateFeedToken),
+		handler.Describe[*todo.CreateFeedTokenPayload, *todo.FeedToken]("CreateFeedToken", http.StatusCreated))
+	api.Add(todos.DELETE("/feed/token", h.RevokeFeedToken),
+		handler.DescribeNoContent[*todo.RevokeFeedTokenPayload]("RevokeFeedToken", http.StatusNoContent))
+	api.Add(todos.POST("/recurrence/preview", h.PreviewRecurrence),
+		handler.Describe[*todo.RecurrencePreviewPayload, *todo.RecurrencePreview]("PreviewRecurrence", http.StatusOK))
+	api.Add(todos.POST("/recurrence/validate", h.ValidateRecurrence),
+		handler.Describe[*todo.ValidateRecurrencePayload, *todo.RecurrenceValidation]("ValidateRecurrence", http.StatusOK))
+	// Each import holds a connection for its whole transaction
+	api.Add(todos.POST("/import/ics", h.ImportICS, inFlight.LimitRoute(maxConcurrentImports)),
+		handler.Describe[*todo.ImportICSPayload, *todo.ImportResult]("ImportICS", http.StatusOK))
+
+	// Bulk operations
+	api.Add(todos.PATCH("/bulk/reparent", h.BulkReparent),
+		handler.Describe[*todo.BulkReparentPayload, *todo.BulkUpdateResult]("BulkReparent", http.StatusOK))
+	api.Add(todos.PATCH("/bulk/status", h.BulkUpdateStatus),
+		handler.Describe[*todo.BulkUpdateStatusPayload, *todo.BulkUpdateResult]("BulkUpdateStatus", http.StatusOK))
+	api.Add(todos.PATCH("/bulk/priority", h.BulkSetPriority),
+		handler.Describe[*todo.BulkSetPriorityPayload, *todo.BulkUpdateResult]("BulkSetPriority", http.StatusOK))
+	api.Add(todos.PATCH("/bulk/archive", h.BulkArchive),
+		handler.Describe[*todo.BulkArchivePayload, *todo.BulkArchiveResult]("BulkArchive", http.StatusOK))
+	api.Add(todos.PATCH("/bulk/unarchive", h.BulkUnarchive),
+		handler.Describe[*todo.BulkArchivePayload, *todo.BulkArchiveResult]("BulkUnarchive", http.StatusOK))
+	api.Add(todos.PATCH("/bulk/reopen", h.BulkReopen),
+		handler.Describe[*todo.BulkReopenPayload, *todo.BulkUpdateResult]("BulkReopen", http.StatusOK))
+	// Deletes into the trash in one transaction, reporting each listed todo
+	api.Add(todos.DELETE("/bulk", h.BulkDelete),
+		handler.Describe[*todo.BulkDeletePayload, *todo.BulkDeleteResult]("BulkDelete", http.StatusOK))
+	api.Add(todos.POST("/bulk/by-filter", h.BulkByFilter),
+		handler.Describe[*todo.BulkByFilterPayload, *todo.BulkByFilterResult]("BulkByFilter", http.StatusOK))
+	api.Add(todos.POST("/snooze-overdue", h.SnoozeOverdue),
+		handler.Describe[*todo.SnoozeOverduePayload, *todo.SnoozeOverdueResult]("SnoozeOverdue", http.StatusOK))
 
 	// Individual todo operations
 	dynamicTodo := todos.Group("/:id")
-	dynamicTodo.GET("", h.GetTodoByID)
-	dynamicTodo.PATCH("", h.UpdateTodo)
-	dynamicTodo.DELETE("", h.DeleteTodo)
+	api.Add(dynamicTodo.GET("", h.GetTodoByID),
+		handler.Describe[*todo.GetTodoByIDPayload, *todo.PopulatedTodo]("GetTodoByID", http.StatusOK))
+	api.Add(dynamicTodo.PATCH("", h.UpdateTodo),
+		handler.Describe[*todo.UpdateTodoPayload, *todo.TodoWithWarnings]("UpdateTodo", http.StatusOK))
+	api.Add(dynamicTodo.DELETE("", h.DeleteTodo),
+		handler.DescribeNoContent[*todo.DeleteTodoPayload]("DeleteTodo", http.StatusNoContent))
+	api.Add(dynamicTodo.GET("/children", h.GetChildren),
+		handler.Describe[*todo.GetChildrenQuery, *model.PaginatedResponse[todo.Todo]]("GetChildren", http.StatusOK))
+	api.Add(dynamicTodo.GET("/activity", h.GetTodoActivity),
+		handler.Describe[*activity.GetTodoActivityQuery, *model.PaginatedResponse[activity.Activity]](
+			"GetTodoActivity", http.StatusOK))
+	api.Add(dynamicTodo.GET("/diff", h.GetTodoDiff),
+		handler.Describe[*activity.GetTodoDiffQuery, *activity.TodoDiff]("GetTodoDiff", http.StatusOK))
+	api.Add(dynamicTodo.GET("/changes-since", h.GetChangesSince),
+		handler.Describe[*activity.GetChangesSinceQuery, *activity.ChangeSummary]("GetChangesSince", http.StatusOK))
+	api.Add(dynamicTodo.GET("/export.md", h.ExportTodoMarkdown),
+		handler.DescribeBlob[*todo.ExportTodoMarkdownPayload]("ExportTodoMarkdown", http.StatusOK, todo.MarkdownContentType))
+	api.Add(dynamicTodo.POST("/complete-with-followup", h.CompleteWithFollowUp),
+		handler.Describe[*todo.CompleteWithFollowUpPayload, *todo.CompleteWithFollowUpResult](
+			"CompleteWithFollowUp", http.StatusCreated))
+	api.Add(dynamicTodo.POST("/promote", h.PromoteTodo),
+		handler.Describe[*todo.PromoteTodoPayload, *todo.Todo]("PromoteTodo", http.StatusOK))
+	api.Add(dynamicTodo.POST("/copy-fresh", h.CopyAsFresh),
+		handler.Describe[*todo.CopyFreshPayload, *todo.Todo]("CopyAsFresh", http.StatusCreated))
+	api.Add(dynamicTodo.POST("/bump", h.BumpTodo),
+		handler.Describe[*todo.BumpTodoPayload, *todo.Todo]("BumpTodo", http.StatusOK))
+	api.Add(dynamicTodo.PATCH("/position", h.SetPosition),
+		handler.Describe[*todo.SetPositionPayload, *todo.Todo]("SetPosition", http.StatusOK))
+	api.Add(dynamicTodo.POST("/restore", h.RestoreTodo),
+		handler.Describe[*todo.RestoreTodoPayload, *todo.Todo]("RestoreTodo", http.StatusOK))
+	// Reverts the change whose response carried the X-Undo-Token header
+	api.Add(dynamicTodo.POST("/undo", h.UndoTodo),
+		handler.Describe[*todo.UndoTodoPayload, *todo.Todo]("UndoTodo", http.StatusOK))
+	api.Add(dynamicTodo.POST("/share-link", h.CreateShareLink),
+		handler.Describe[*todo.CreateShareLinkPayload, *todo.ShareLinkToken]("CreateShareLink", http.StatusCreated))
+	api.Add(dynamicTodo.DELETE("/share-link", h.RevokeShareLink),
+		handler.DescribeNoContent[*todo.RevokeShareLinkPayload]("RevokeShareLink", http.StatusNoContent))
+	api.Add(dynamicTodo.GET("/reminders", h.GetTodoReminders),
+		handler.Describe[*todo.GetTodoRemindersPayload, []todo.Reminder]("GetTodoReminders", http.StatusOK))
+	api.Add(dynamicTodo.DELETE("/reminders/:reminderId", h.CancelTodoReminder),
+		handler.DescribeNoContent[*todo.CancelTodoReminderPayload]("CancelTodoReminder", http.StatusNoContent))
+
+	// Todo dependencies; a todo waiting on an open todo is blocked and left
+	// out of lists asking for excludeBlocked
+	todoDependencies := dynamicTodo.Group("/dependencies")
+	api.Add(todoDependencies.GET("", h.GetDependencies),
+		handler.Describe[*todo.GetDependenciesPayload, *todo.Dependencies]("GetDependencies", http.StatusOK))
+	api.Add(todoDependencies.POST("", h.AddDependency),
+		handler.Describe[*todo.AddDependencyPayload, *todo.Dependencies]("AddDependency", http.StatusCreated))
+	api.Add(todoDependencies.DELETE("/:otherId", h.RemoveDependency),
+		handler.DescribeNoContent[*todo.RemoveDependencyPayload]("RemoveDependency", http.StatusNoContent))
+
+	// Todo checklist; removing an item returns the todo so the new progress
+	// is visible without another fetch
+	todoChecklist := dynamicTodo.Group("/checklist")
+	api.Add(todoChecklist.POST("", h.AddChecklistItem),
+		handler.Describe[*todo.AddChecklistItemPayload, *todo.Todo]("AddChecklistItem", http.StatusCreated))
+	api.Add(todoChecklist.PATCH("/:itemId", h.UpdateChecklistItem),
+		handler.Describe[*todo.UpdateChecklistItemPayload, *todo.Todo]("UpdateChecklistItem", http.StatusOK))
+	api.Add(todoChecklist.DELETE("/:itemId", h.RemoveChecklistItem),
+		handler.Describe[*todo.DeleteChecklistItemPayload, *todo.Todo]("RemoveChecklistItem", http.StatusOK))
 
 	// Todo comments
 	todoComments := dynamicTodo.Group("/comments")
-	todoComments.POST("", ch.AddComment)
-	todoComments.GET("", ch.GetCommentsByTodoID)
+	commentAPI.Add(todoComments.POST("", ch.AddComment),
+		handler.Describe[*comment.AddCommentPayload, *comment.Comment]("AddComment", http.StatusCreated))
+	commentAPI.Add(todoComments.GET("", ch.GetCommentsByTodoID),
+		handler.Describe[*comment.GetCommentsByTodoIDPayload, []comment.Comment]("GetCommentsByTodoID", http.StatusOK))
 
 	// Todo attachments
 	todoAttachments := dynamicTodo.Group("/attachments")
-	todoAttachments.POST("", h.UploadTodoAttachment)
-	todoAttachments.DELETE("/:attachmentId", h.DeleteTodoAttachment)
-	todoAttachments.GET("/:attachmentId/download", h.GetAttachmentPresignedURL)
+	api.Add(todoAttachments.POST("", h.UploadTodoAttachment, uploads.LimitConcurrentUploads),
+		handler.Describe[*todo.UploadTodoAttachmentPayload, *todo.TodoAttachment]("UploadTodoAttachment", http.StatusCreated))
+	api.Add(todoAttachments.DELETE("/:attachmentId", h.DeleteTodoAttachment),
+		handler.DescribeNoContent[*todo.DeleteTodoAttachmentPayload]("DeleteTodoAttachment", http.StatusNoContent))
+	api.Add(todoAttachments.GET("/:attachmentId/download", h.GetAttachmentPresignedURL),
+		handler.Describe[*todo.GetAttachmentPresignedURLPayload, *todo.AttachmentURL](
+			"GetAttachmentPresignedURL", http.StatusOK))
 }
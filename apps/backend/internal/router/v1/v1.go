@@ -1,18 +1,68 @@
 package v1
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/sriniously/tasker/internal/handler"
 	"github.com/sriniously/tasker/internal/middleware"
 )
 
+// PublicRoutes are the routes taking no session token, relative to the API
+// prefix. Feeds and share links carry a token of their own, and the API
+// document is open so clients can be generated from it.
+var PublicRoutes = []string{
+	"GET /todos/feed/completed.atom",
+	"GET /shared/todos/:token",
+	"GET /openapi.json",
+}
+
 func RegisterV1Routes(router *echo.Group, handlers *handler.Handlers, middleware *middleware.Middlewares) {
+	// Register the API document, generated from the routes registered here
+	docs := handlers.OpenAPI
+	docs.Tag("OpenAPI").Add(router.GET("/openapi.json", docs.ServeSpec),
+		handler.DescribeRaw("ServeSpec", http.StatusOK, echo.MIMEApplicationJSON))
+	docs.SetPublicRoutes(PublicRoutes)
+
 	// Register todo routes
-	registerTodoRoutes(router, handlers.Todo, handlers.Comment, middleware.Auth)
+	registerTodoRoutes(router, handlers.Todo, handlers.Comment, middleware.Auth, middleware.Organization,
+		middleware.UploadLimit, middleware.InFlight, middleware.Idempotency, docs)
 
 	// Register category routes
-	registerCategoryRoutes(router, handlers.Category, middleware.Auth)
+	registerCategoryRoutes(router, handlers.Category, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register tag routes
+	registerTagRoutes(router, handlers.Tag, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register template routes
+	registerTemplateRoutes(router, handlers.Template, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register webhook routes
+	registerWebhookRoutes(router, handlers.Webhook, middleware.Auth, middleware.Idempotency, docs)
 
 	// Register comment routes
-	registerCommentRoutes(router, handlers.Comment, middleware.Auth)
+	registerCommentRoutes(router, handlers.Comment, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register organization routes
+	registerOrganizationRoutes(router, handlers.Organization, handlers.Todo, middleware.Auth, middleware.Organization,
+		middleware.Idempotency, docs)
+
+	// Register admin routes
+	registerAdminRoutes(router, handlers.Admin, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register current user routes
+	registerMeRoutes(router, handlers.Preference, handlers.Activity, handlers.Notification, handlers.Streak,
+		handlers.Account, handlers.Dashboard, middleware.Auth, middleware.Idempotency, docs)
+
+	// Register dashboard routes
+	registerDashboardRoutes(router, handlers.Dashboard, middleware.Auth, docs)
+}
+
+// RegisterStreamRoutes registers the endpoints that hold a connection open
+// for as long as the client listens, including long-polls. The group they go
+// on must not count toward the in-flight limit, or idle listeners would use
+// it up.
+func RegisterStreamRoutes(router *echo.Group, handlers *handler.Handlers, middleware *middleware.Middlewares) {
+	// Register real-time routes
+	registerRealtimeRoutes(router, handlers.Realtime, handlers.Todo, middleware.Auth, handlers.OpenAPI)
 }
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/handler"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/webhook"
+)
+
+func registerWebhookRoutes(r *echo.Group, h *handler.WebhookHandler, auth *middleware.AuthMiddleware,
+	idempotency *middleware.IdempotencyMiddleware,
+	docs *handler.OpenAPIHandler,
+) {
+	api := docs.Tag("Webhook")
+
+	// Webhook operations
+	webhooks := r.Group("/webhooks")
+	webhooks.Use(auth.RequireAuth, idempotency.Idempotent)
+
+	// Webhook collection operations
+	api.Add(webhooks.POST("", h.CreateWebhook),
+		handler.Describe[*webhook.CreateWebhookPayload, *webhook.WebhookWithSecret]("CreateWebhook", http.StatusCreated))
+	api.Add(webhooks.GET("", h.GetWebhooks),
+		handler.Describe[*webhook.GetWebhooksPayload, []webhook.Webhook]("GetWebhooks", http.StatusOK))
+
+	// Individual webhook operations
+	dynamicWebhook := webhooks.Group("/:id")
+	api.Add(dynamicWebhook.GET("", h.GetWebhookByID),
+		handler.Describe[*webhook.GetWebhookPayload, *webhook.Webhook]("GetWebhookByID", http.StatusOK))
+	api.Add(dynamicWebhook.PATCH("", h.UpdateWebhook),
+		handler.Describe[*webhook.UpdateWebhookPayload, *webhook.Webhook]("UpdateWebhook", http.StatusOK))
+	api.Add(dynamicWebhook.DELETE("", h.DeleteWebhook),
+		handler.DescribeNoContent[*webhook.DeleteWebhookPayload]("DeleteWebhook", http.StatusNoContent))
+	api.Add(dynamicWebhook.GET("/deliveries", h.GetDeliveries),
+		handler.Describe[*webhook.GetDeliveriesQuery, *model.PaginatedResponse[webhook.Delivery]](
+			"GetDeliveries", http.StatusOK))
+	api.Add(dynamicWebhook.POST("/test", h.TestWebhook),
+		handler.Describe[*webhook.TestWebhookPayload, *webhook.Delivery]("TestWebhook", http.StatusOK))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type AccountService struct {
+	server      *server.Server
+	accountRepo *repository.AccountRepository
+}
+
+func NewAccountService(server *server.Server, accountRepo *repository.AccountRepository) *AccountService {
+	return &AccountService{
+		server:      server,
+		accountRepo: accountRepo,
+	}
+}
+
+// DeleteAccount deletes the user's account. Its data is kept out of reach for
+// the retention window, during which an admin can recover it, and is purged
+// afterwards.
+func (s *AccountService) DeleteAccount(ctx echo.Context, userID string) (*account.Deletion, error) {
+	logger := middleware.GetLogger(ctx)
+
+	purgeAfter := time.Now().Add(s.server.Config.Auth.GetAccountRetention())
+	deletion, err := s.accountRepo.SoftDeleteAccount(ctx.Request().Context(), userID, userID, purgeAfter)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to delete account")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "account_deleted").
+		Str("user_id", userID).
+		Time("purge_after", deletion.PurgeAfter).
+		Msg("Account deleted pending purge")
+
+	return deletion, nil
+}
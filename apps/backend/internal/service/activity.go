@@ -0,0 +1,114 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/model/webhook"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type ActivityService struct {
+	server       *server.Server
+	activityRepo *repository.ActivityRepository
+}
+
+func NewActivityService(server *server.Server, activityRepo *repository.ActivityRepository) *ActivityService {
+	return &ActivityService{
+		server:       server,
+		activityRepo: activityRepo,
+	}
+}
+
+func (s *ActivityService) GetActivities(ctx echo.Context, userID string,
+	query *activity.GetActivitiesQuery,
+) (*model.PaginatedResponse[activity.Activity], error) {
+	logger := middleware.GetLogger(ctx)
+
+	result, err := s.activityRepo.GetActivities(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch activities")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ActivityRecorder appends entries to todo activity logs and signals each one
+// to waiting change feeds, live listeners and subscribed webhooks. It is shared
+// by every service that changes todos.
+type ActivityRecorder struct {
+	activityRepo *repository.ActivityRepository
+	notifier     changefeed.Notifier
+	events       changefeed.Broadcaster
+	webhooks     *WebhookService
+}
+
+func NewActivityRecorder(activityRepo *repository.ActivityRepository, notifier changefeed.Notifier,
+	events changefeed.Broadcaster, webhooks *WebhookService,
+) *ActivityRecorder {
+	return &ActivityRecorder{
+		activityRepo: activityRepo,
+		notifier:     notifier,
+		events:       events,
+		webhooks:     webhooks,
+	}
+}
+
+// Record appends an entry to the todo's activity log, made by actorID to a
+// todo of userID. Failures are logged rather than returned so history never
+// blocks the mutation itself.
+func (r *ActivityRecorder) Record(ctx echo.Context, userID, actorID string, todoID uuid.UUID,
+	action activity.Action, changes activity.Changes,
+) *activity.Activity {
+	logger := middleware.GetLogger(ctx)
+
+	entry, err := r.activityRepo.CreateActivity(ctx.Request().Context(), &activity.Activity{
+		TodoID:  todoID,
+		UserID:  userID,
+		ActorID: actorID,
+		Action:  action,
+		Changes: changes,
+	})
+	if err != nil {
+		logger.Error().Err(err).
+			Str("todo_id", todoID.String()).
+			Str("action", string(action)).
+			Msg("failed to record todo activity")
+		return nil
+	}
+
+	// Waiting change feed requests re-read the activity log when signalled
+	if r.notifier != nil {
+		if err := r.notifier.Publish(ctx.Request().Context(), userID); err != nil {
+			logger.Warn().Err(err).Msg("failed to signal todo change")
+		}
+	}
+
+	broadcastEvent(ctx, r.events, userID, changefeed.EventTodoPrefix+string(action), todoID, entry)
+
+	for _, event := range webhook.TodoEvents(entry) {
+		r.webhooks.Dispatch(ctx, userID, event, entry)
+	}
+
+	return entry
+}
+
+// RecordChanges records an entry for every todo a bulk update changed, under
+// the todo's owner. Todos the update left as they were get none.
+func (r *ActivityRecorder) RecordChanges(ctx echo.Context, actorID string, changes []todo.Change) {
+	for i := range changes {
+		diff := activity.Diff(activity.SnapshotTodo(&changes[i].Before), activity.SnapshotTodo(&changes[i].After))
+		if len(diff) == 0 {
+			continue
+		}
+
+		changed := &changes[i].After
+		r.Record(ctx, changed.UserID, actorID, changed.ID, activity.ActionFor(diff), diff)
+	}
+}
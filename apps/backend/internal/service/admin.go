@@ -0,0 +1,220 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/token"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/account"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type AdminService struct {
+	server      *server.Server
+	adminRepo   *repository.AdminRepository
+	accountRepo *repository.AccountRepository
+	recorder    *ActivityRecorder
+}
+
+func NewAdminService(server *server.Server, adminRepo *repository.AdminRepository,
+	accountRepo *repository.AccountRepository, recorder *ActivityRecorder,
+) *AdminService {
+	return &AdminService{
+		server:      server,
+		adminRepo:   adminRepo,
+		accountRepo: accountRepo,
+		recorder:    recorder,
+	}
+}
+
+func (s *AdminService) ImpersonateUser(ctx echo.Context, adminID string, targetUserID string) (*admin.ImpersonationToken, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if adminID == targetUserID {
+		err := errs.NewBadRequestError("Cannot impersonate yourself", false, nil, nil, nil)
+		logger.Warn().Msg("admin attempted to impersonate themselves")
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.server.Config.Auth.GetImpersonationTTL())
+
+	raw, err := token.Sign(s.server.Config.Auth.SecretKey, token.Claims{
+		Subject:   targetUserID,
+		Actor:     adminID,
+		Purpose:   token.PurposeImpersonation,
+		ID:        uuid.New().String(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to sign impersonation token")
+		return nil, err
+	}
+
+	requestID := middleware.GetRequestID(ctx)
+	err = s.adminRepo.CreateImpersonationAuditLog(ctx.Request().Context(), &admin.ImpersonationAuditLog{
+		ImpersonatorID: adminID,
+		TargetUserID:   targetUserID,
+		Action:         admin.ImpersonationActionStarted,
+		RequestID:      &requestID,
+		Allowed:        true,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to write impersonation audit log")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "impersonation_started").
+		Str("impersonator_id", adminID).
+		Str("target_user_id", targetUserID).
+		Time("expires_at", expiresAt).
+		Msg("Impersonation token issued")
+
+	return &admin.ImpersonationToken{
+		Token:        raw,
+		TargetUserID: targetUserID,
+		ExpiresAt:    expiresAt,
+		ReadOnly:     true,
+	}, nil
+}
+
+// ReassignUser hands a departing user's todos and categories, and optionally
+// comments, to another user. Todos move in batches, so a failure part way
+// leaves some moved; running it again finishes the job.
+func (s *AdminService) ReassignUser(ctx echo.Context, adminID string,
+	payload *admin.ReassignUserPayload,
+) (*admin.ReassignResult, error) {
+	logger := middleware.GetLogger(ctx).With().
+		Str("source_user_id", payload.UserID).
+		Str("target_user_id", payload.TargetUserID).
+		Logger()
+	reqCtx := ctx.Request().Context()
+
+	result := &admin.ReassignResult{
+		SourceUserID: payload.UserID,
+		TargetUserID: payload.TargetUserID,
+	}
+
+	categories, merged, err := s.adminRepo.ReassignCategories(reqCtx, payload.UserID, payload.TargetUserID,
+		*payload.CategoryConflict)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to reassign categories")
+		return nil, err
+	}
+	s.recorder.RecordChanges(ctx, adminID, merged)
+	result.CategoriesMoved = categories.Moved
+	result.CategoriesMerged = categories.Merged
+	result.CategoriesRenamed = categories.Renamed
+
+	moved, err := s.adminRepo.ReassignTodos(reqCtx, payload.UserID, payload.TargetUserID,
+		admin.ReassignBatchSize)
+	// Todos moved before a failure are recorded too, since they stay moved
+	for _, todoID := range moved {
+		s.recorder.Record(ctx, payload.TargetUserID, adminID, todoID, activity.ActionReassigned,
+			activity.ReassignChanges(payload.UserID, payload.TargetUserID))
+	}
+	result.TodosMoved = len(moved)
+	if err != nil {
+		logger.Error().Err(err).Int("todos_moved", result.TodosMoved).Msg("failed to reassign todos")
+		return nil, err
+	}
+
+	if *payload.IncludeComments {
+		result.CommentsMoved, err = s.adminRepo.ReassignComments(reqCtx, payload.UserID, payload.TargetUserID,
+			admin.ReassignBatchSize)
+		if err != nil {
+			logger.Error().Err(err).Int("comments_moved", result.CommentsMoved).Msg("failed to reassign comments")
+			return nil, err
+		}
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "user_reassigned").
+		Str("admin_id", adminID).
+		Str("source_user_id", payload.UserID).
+		Str("target_user_id", payload.TargetUserID).
+		Int("todos_moved", result.TodosMoved).
+		Int("categories_moved", result.CategoriesMoved).
+		Int("categories_merged", result.CategoriesMerged).
+		Int("categories_renamed", result.CategoriesRenamed).
+		Int("comments_moved", result.CommentsMoved).
+		Msg("User data reassigned successfully")
+
+	return result, nil
+}
+
+// GetEffectiveConfig returns the configuration the server is running with,
+// with credentials redacted
+func (s *AdminService) GetEffectiveConfig(ctx echo.Context, adminID string) *config.Config {
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "config_viewed").
+		Str("admin_id", adminID).
+		Msg("Effective config viewed")
+
+	return s.server.Config.Redacted()
+}
+
+// GetAttachmentAccessLogs pages through who was handed download links for
+// which attachments
+func (s *AdminService) GetAttachmentAccessLogs(ctx echo.Context, adminID string,
+	query *admin.GetAttachmentAccessLogsQuery,
+) (*model.PaginatedResponse[admin.AttachmentAccessLog], error) {
+	logger := middleware.GetLogger(ctx)
+
+	logs, err := s.adminRepo.GetAttachmentAccessLogs(ctx.Request().Context(), query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch attachment access logs")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "attachment_access_logs_viewed").
+		Str("admin_id", adminID).
+		Int("total", logs.Total).
+		Msg("Attachment access logs viewed")
+
+	return logs, nil
+}
+
+// RecoverAccount undoes the deletion of an account whose data hasn't been
+// purged yet, giving its owner back everything they had
+func (s *AdminService) RecoverAccount(ctx echo.Context, adminID string,
+	payload *admin.RecoverAccountPayload,
+) (*account.Deletion, error) {
+	logger := middleware.GetLogger(ctx)
+
+	deletion, err := s.accountRepo.RecoverAccount(ctx.Request().Context(), payload.UserID, time.Now())
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", payload.UserID).Msg("failed to recover account")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "account_recovered").
+		Str("admin_id", adminID).
+		Str("user_id", payload.UserID).
+		Time("deleted_at", deletion.CreatedAt).
+		Msg("Deleted account recovered")
+
+	return deletion, nil
+}
@@ -3,9 +3,11 @@ package service
 import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
 	"github.com/sriniously/tasker/internal/middleware"
 	"github.com/sriniously/tasker/internal/model"
 	"github.com/sriniously/tasker/internal/model/category"
+	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/repository"
 	"github.com/sriniously/tasker/internal/server"
 )
@@ -13,18 +15,26 @@ import (
 type CategoryService struct {
 	server       *server.Server
 	categoryRepo *repository.CategoryRepository
+	todoRepo     *repository.TodoRepository
+	events       changefeed.Broadcaster
+	recorder     *ActivityRecorder
 }
 
-func NewCategoryService(server *server.Server, categoryRepo *repository.CategoryRepository) *CategoryService {
+func NewCategoryService(server *server.Server, categoryRepo *repository.CategoryRepository,
+	todoRepo *repository.TodoRepository, events changefeed.Broadcaster, recorder *ActivityRecorder,
+) *CategoryService {
 	return &CategoryService{
 		server:       server,
 		categoryRepo: categoryRepo,
+		todoRepo:     todoRepo,
+		events:       events,
+		recorder:     recorder,
 	}
 }
 
 func (s *CategoryService) CreateCategory(ctx echo.Context, userID string,
 	payload *category.CreateCategoryPayload,
-) (*category.Category, error) {
+) (*category.CategoryWithWarnings, error) {
 	logger := middleware.GetLogger(ctx)
 
 	categoryItem, err := s.categoryRepo.CreateCategory(ctx.Request().Context(), userID, payload)
@@ -42,7 +52,9 @@ func (s *CategoryService) CreateCategory(ctx echo.Context, userID string,
 		Str("color", categoryItem.Color).
 		Msg("Category created successfully")
 
-	return categoryItem, nil
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCategoryCreated, categoryItem.ID, categoryItem)
+
+	return s.withWarnings(categoryItem), nil
 }
 
 func (s *CategoryService) GetCategories(ctx echo.Context, userID string,
@@ -73,7 +85,7 @@ func (s *CategoryService) GetCategoryByID(ctx echo.Context, userID string, categ
 
 func (s *CategoryService) UpdateCategory(ctx echo.Context, userID string, categoryID uuid.UUID,
 	payload *category.UpdateCategoryPayload,
-) (*category.Category, error) {
+) (*category.CategoryWithWarnings, error) {
 	logger := middleware.GetLogger(ctx)
 
 	categoryItem, err := s.categoryRepo.UpdateCategory(ctx.Request().Context(), userID, categoryID, payload)
@@ -90,24 +102,218 @@ func (s *CategoryService) UpdateCategory(ctx echo.Context, userID string, catego
 		Str("name", categoryItem.Name).
 		Msg("Category updated successfully")
 
-	return categoryItem, nil
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCategoryUpdated, categoryItem.ID, categoryItem)
+
+	return s.withWarnings(categoryItem), nil
+}
+
+// withWarnings attaches a low contrast warning when the contrast check is on
+func (s *CategoryService) withWarnings(categoryItem *category.Category) *category.CategoryWithWarnings {
+	warnings := []category.Warning{}
+
+	if cfg := s.server.Config.Category; cfg.IsContrastCheckEnabled() {
+		warnings = category.ContrastWarnings(categoryItem.Color, cfg.GetContrastBackground(),
+			cfg.GetMinContrastRatio())
+	}
+
+	return &category.CategoryWithWarnings{
+		Category: *categoryItem,
+		Warnings: warnings,
+	}
 }
 
 func (s *CategoryService) DeleteCategory(ctx echo.Context, userID string, categoryID uuid.UUID) error {
 	logger := middleware.GetLogger(ctx)
 
-	err := s.categoryRepo.DeleteCategory(ctx.Request().Context(), userID, categoryID)
+	orphaned, err := s.categoryRepo.DeleteCategory(ctx.Request().Context(), userID, categoryID)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to delete category")
 		return err
 	}
 
+	s.recorder.RecordChanges(ctx, userID, orphaned)
+
 	// Business event log
 	eventLogger := middleware.GetLogger(ctx)
 	eventLogger.Info().
 		Str("event", "category_deleted").
 		Str("category_id", categoryID.String()).
+		Int("uncategorized_count", len(orphaned)).
 		Msg("Category deleted successfully")
 
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCategoryDeleted, categoryID,
+		map[string]any{"id": categoryID, "uncategorizedCount": len(orphaned)})
+
 	return nil
 }
+
+func (s *CategoryService) ArchiveCategoryTodos(ctx echo.Context, userID string, categoryID uuid.UUID,
+	onlyCompleted bool,
+) (*category.ArchiveCategoryTodosResponse, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Validate category exists and belongs to user
+	_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, categoryID)
+	if err != nil {
+		logger.Error().Err(err).Msg("category validation failed")
+		return nil, err
+	}
+
+	archived, err := s.todoRepo.ArchiveCategoryTodos(ctx.Request().Context(), userID, categoryID, onlyCompleted)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to archive category todos")
+		return nil, err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, archived)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "category_todos_archived").
+		Str("category_id", categoryID.String()).
+		Bool("only_completed", onlyCompleted).
+		Int("archived_count", len(archived)).
+		Msg("Category todos archived successfully")
+
+	return &category.ArchiveCategoryTodosResponse{
+		CategoryID: categoryID,
+		Archived:   len(archived),
+	}, nil
+}
+
+// ReplaceTag renames or removes a tag across all todos in the category
+func (s *CategoryService) ReplaceTag(ctx echo.Context, userID string,
+	payload *category.ReplaceTagPayload,
+) (*category.ReplaceTagResponse, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Validate category exists and belongs to user
+	_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, payload.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("category validation failed")
+		return nil, err
+	}
+
+	// The replacement is stored, so it is cleaned like any other written tag
+	payload.NewTag = todo.NormalizeTag(payload.NewTag)
+
+	updated, err := s.todoRepo.ReplaceTagInCategory(ctx.Request().Context(), userID, payload.ID,
+		payload.OldTag, payload.NewTag)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to replace tag in category")
+		return nil, err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, updated)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "category_tag_replaced").
+		Str("category_id", payload.ID.String()).
+		Str("old_tag", payload.OldTag).
+		Str("new_tag", payload.NewTag).
+		Int("updated_count", len(updated)).
+		Msg("Category tag replaced successfully")
+
+	return &category.ReplaceTagResponse{
+		CategoryID: payload.ID,
+		Updated:    len(updated),
+	}, nil
+}
+
+// RestoreGrouping brings back a recently deleted category with the todos it
+// left uncategorized
+func (s *CategoryService) RestoreGrouping(ctx echo.Context, userID string,
+	categoryID uuid.UUID,
+) (*category.RestoreGroupingResponse, error) {
+	logger := middleware.GetLogger(ctx)
+
+	restored, changes, err := s.categoryRepo.RestoreGrouping(ctx.Request().Context(), userID, categoryID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to restore category grouping")
+		return nil, err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, changes)
+
+	// The rest only forgot the grouping, having been given another category
+	reattached := 0
+	for i := range changes {
+		if changes[i].Before.CategoryID == nil {
+			reattached++
+		}
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "category_grouping_restored").
+		Str("category_id", categoryID.String()).
+		Int("reattached_count", reattached).
+		Msg("Category grouping restored successfully")
+
+	return &category.RestoreGroupingResponse{
+		Category:   *restored,
+		Reattached: reattached,
+	}, nil
+}
+
+// MergeCategories consolidates the source category into the target, moving
+// its todos and deleting it
+func (s *CategoryService) MergeCategories(ctx echo.Context, userID string,
+	sourceID, targetID uuid.UUID,
+) (*category.MergeCategoriesResponse, error) {
+	logger := middleware.GetLogger(ctx)
+
+	moved, err := s.categoryRepo.MergeCategories(ctx.Request().Context(), userID, sourceID, targetID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to merge categories")
+		return nil, err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, moved)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "categories_merged").
+		Str("source_category_id", sourceID.String()).
+		Str("target_category_id", targetID.String()).
+		Int("moved_count", len(moved)).
+		Msg("Categories merged successfully")
+
+	return &category.MergeCategoriesResponse{
+		SourceID: sourceID,
+		TargetID: targetID,
+		Moved:    len(moved),
+	}, nil
+}
+
+// GetProgressTrend returns the category's cumulative completion per bucket
+func (s *CategoryService) GetProgressTrend(ctx echo.Context, userID string,
+	query *category.GetProgressTrendQuery,
+) (*category.ProgressTrend, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Validate category exists and belongs to user
+	_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, query.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("category validation failed")
+		return nil, err
+	}
+
+	points, err := s.todoRepo.GetCategoryProgressTrend(ctx.Request().Context(), userID, query.ID,
+		*query.From, *query.To, *query.Bucket)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch category progress trend")
+		return nil, err
+	}
+
+	return &category.ProgressTrend{
+		CategoryID: query.ID,
+		Bucket:     *query.Bucket,
+		Points:     points,
+	}, nil
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
 	"github.com/sriniously/tasker/internal/middleware"
 	"github.com/sriniously/tasker/internal/model/comment"
+	"github.com/sriniously/tasker/internal/model/webhook"
 	"github.com/sriniously/tasker/internal/repository"
 	"github.com/sriniously/tasker/internal/server"
 )
@@ -13,13 +15,19 @@ type CommentService struct {
 	server      *server.Server
 	commentRepo *repository.CommentRepository
 	todoRepo    *repository.TodoRepository
+	events      changefeed.Broadcaster
+	webhooks    *WebhookService
 }
 
-func NewCommentService(server *server.Server, commentRepo *repository.CommentRepository, todoRepo *repository.TodoRepository) *CommentService {
+func NewCommentService(server *server.Server, commentRepo *repository.CommentRepository, todoRepo *repository.TodoRepository,
+	events changefeed.Broadcaster, webhooks *WebhookService,
+) *CommentService {
 	return &CommentService{
 		server:      server,
 		commentRepo: commentRepo,
 		todoRepo:    todoRepo,
+		events:      events,
+		webhooks:    webhooks,
 	}
 }
 
@@ -49,6 +57,9 @@ func (s *CommentService) AddComment(ctx echo.Context, userID string, todoID uuid
 		Str("todo_id", todoID.String()).
 		Msg("Comment added successfully")
 
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCommentAdded, commentItem.ID, commentItem)
+	s.webhooks.Dispatch(ctx, userID, webhook.EventCommentAdded, commentItem)
+
 	return commentItem, nil
 }
 
@@ -94,6 +105,8 @@ func (s *CommentService) UpdateComment(ctx echo.Context, userID string, commentI
 		Str("comment_id", commentItem.ID.String()).
 		Msg("Comment updated successfully")
 
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCommentUpdated, commentItem.ID, commentItem)
+
 	return commentItem, nil
 }
 
@@ -101,7 +114,7 @@ func (s *CommentService) DeleteComment(ctx echo.Context, userID string, commentI
 	logger := middleware.GetLogger(ctx)
 
 	// Validate comment exists and belongs to user
-	_, err := s.commentRepo.GetCommentByID(ctx.Request().Context(), userID, commentID)
+	existing, err := s.commentRepo.GetCommentByID(ctx.Request().Context(), userID, commentID)
 	if err != nil {
 		logger.Error().Err(err).Msg("comment validation failed")
 		return err
@@ -120,5 +133,7 @@ func (s *CommentService) DeleteComment(ctx echo.Context, userID string, commentI
 		Str("comment_id", commentID.String()).
 		Msg("Comment deleted successfully")
 
+	broadcastEvent(ctx, s.events, userID, changefeed.EventCommentDeleted, commentID, existing)
+
 	return nil
 }
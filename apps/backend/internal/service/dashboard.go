@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type DashboardService struct {
+	server         *server.Server
+	todoRepo       *repository.TodoRepository
+	preferenceRepo *repository.PreferenceRepository
+	streakRepo     *repository.StreakRepository
+}
+
+func NewDashboardService(server *server.Server, todoRepo *repository.TodoRepository,
+	preferenceRepo *repository.PreferenceRepository, streakRepo *repository.StreakRepository,
+) *DashboardService {
+	return &DashboardService{
+		server:         server,
+		todoRepo:       todoRepo,
+		preferenceRepo: preferenceRepo,
+		streakRepo:     streakRepo,
+	}
+}
+
+// GetDashboard loads the dashboard sections concurrently. A section that
+// fails or takes longer than its configured timeout is left out and reported
+// in SectionErrors rather than failing or holding up the whole response.
+func (s *DashboardService) GetDashboard(ctx echo.Context, userID string) (*dashboard.Dashboard, error) {
+	logger := middleware.GetLogger(ctx)
+
+	loaders := []dashboard.Loader{
+		{
+			Section: dashboard.SectionStats,
+			Load: func(ctx context.Context) (any, error) {
+				return s.todoRepo.GetTodoStats(ctx, userID)
+			},
+		},
+		{
+			Section: dashboard.SectionOverdue,
+			Load: func(ctx context.Context) (any, error) {
+				prefs, err := s.preferenceRepo.GetPreferences(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+
+				buckets, err := s.todoRepo.GetOverdueBuckets(ctx, userID, prefs.Location().String(), prefs.DayStartHour)
+				if err != nil {
+					return nil, err
+				}
+
+				for i := range buckets.Buckets {
+					buckets.Buckets[i].TodoIDs = nil
+				}
+				return buckets, nil
+			},
+		},
+		{
+			Section: dashboard.SectionStreak,
+			Load: func(ctx context.Context) (any, error) {
+				return s.streakRepo.GetStreak(ctx, userID)
+			},
+		},
+	}
+
+	results := dashboard.Compose(ctx.Request().Context(), loaders, func(section dashboard.Section) time.Duration {
+		return s.server.Config.Dashboard.GetSectionTimeout(string(section))
+	})
+
+	result := &dashboard.Dashboard{}
+	for _, r := range results {
+		if r.Failure != nil {
+			logger.Warn().Err(r.Err).
+				Str("section", string(r.Section)).
+				Str("status", string(r.Failure.Status)).
+				Msg("dashboard section unavailable")
+
+			if result.SectionErrors == nil {
+				result.SectionErrors = make(map[dashboard.Section]dashboard.SectionError)
+			}
+			result.SectionErrors[r.Section] = *r.Failure
+			continue
+		}
+
+		switch value := r.Value.(type) {
+		case *todo.TodoStats:
+			result.Stats = value
+		case *todo.OverdueBuckets:
+			result.Overdue = value
+		case *streak.Streak:
+			result.Streak = value
+		}
+	}
+
+	return result, nil
+}
+
+// GetWeeklyReview assembles the weekly review for the week given in query,
+// or for the current one. Weeks run Monday to Sunday in the user's timezone,
+// and the current week is the one the user's day falls in.
+func (s *DashboardService) GetWeeklyReview(ctx echo.Context, userID string,
+	query *dashboard.GetWeeklyReviewQuery,
+) (*dashboard.WeeklyReview, error) {
+	logger := middleware.GetLogger(ctx)
+	reqCtx := ctx.Request().Context()
+
+	prefs, err := s.preferenceRepo.GetPreferences(reqCtx, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences for weekly review")
+		return nil, err
+	}
+	loc := prefs.Location()
+
+	day := prefs.DayClock(time.Now())
+	if query.Week != nil {
+		if day, err = dashboard.ParseReviewWeek(*query.Week, loc); err != nil {
+			return nil, err
+		}
+	}
+	start, end := dashboard.ReviewWeek(day, loc)
+	_, nextEnd := dashboard.ReviewWeek(end, loc)
+
+	// The filters' upper bounds are inclusive
+	lastOf := func(t time.Time) *time.Time {
+		last := t.Add(-time.Microsecond)
+		return &last
+	}
+	yes, no := true, false
+	dueDate := "due_date"
+
+	review := &dashboard.WeeklyReview{
+		WeekStart: start,
+		WeekEnd:   end,
+		Timezone:  loc.String(),
+	}
+
+	sections := []struct {
+		name    string
+		section *dashboard.ReviewSection
+		query   *todo.GetTodosQuery
+	}{
+		{
+			name:    "completed",
+			section: &review.Completed,
+			query:   &todo.GetTodosQuery{Completed: &yes, CompletedFrom: &start, CompletedTo: lastOf(end)},
+		},
+		{
+			name:    "overdue",
+			section: &review.Overdue,
+			query:   &todo.GetTodosQuery{Overdue: &yes, Open: &yes, Sort: &dueDate},
+		},
+		{
+			name:    "upcoming",
+			section: &review.Upcoming,
+			query:   &todo.GetTodosQuery{Open: &yes, DueFrom: &end, DueTo: lastOf(nextEnd), Sort: &dueDate},
+		},
+		{
+			name:    "no_next_action",
+			section: &review.NoNextAction,
+			query:   &todo.GetTodosQuery{Open: &yes, HasDueDate: &no, HasSubtasks: &no},
+		},
+	}
+
+	for _, sec := range sections {
+		page, limit := 1, dashboard.ReviewSectionLimit
+		sec.query.Page = &page
+		sec.query.Limit = &limit
+
+		result, err := s.todoRepo.GetTodos(reqCtx, userID, sec.query)
+		if err != nil {
+			logger.Error().Err(err).Str("section", sec.name).Msg("failed to fetch weekly review section")
+			return nil, err
+		}
+
+		*sec.section = dashboard.ReviewSection{Total: result.Total, Todos: result.Data}
+	}
+
+	return review, nil
+}
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/model/dashboard"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/service"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardService_GetWeeklyReview(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	dashboardService := service.NewDashboardService(testServer, repos.Todo, repos.Preference, repos.Streak)
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/weekly-review", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+	ctx := newContext().Request().Context()
+	userID := uuid.New().String()
+
+	create := func(t *testing.T, title string, due *time.Time, parentID *uuid.UUID) *todo.Todo {
+		t.Helper()
+		created, err := repos.Todo.CreateTodo(ctx, userID, &todo.CreateTodoPayload{
+			Title:        title,
+			DueDate:      due,
+			ParentTodoID: parentID,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	setStatus := func(t *testing.T, item *todo.Todo, status todo.Status) {
+		t.Helper()
+		_, err := repos.Todo.UpdateTodo(ctx, userID, &todo.UpdateTodoPayload{ID: item.ID, Status: &status})
+		require.NoError(t, err)
+	}
+	completeAt := func(t *testing.T, item *todo.Todo, at time.Time) {
+		t.Helper()
+		setStatus(t, item, todo.StatusCompleted)
+		_, err := testServer.DB.Pool.Exec(ctx, "UPDATE todos SET completed_at = $1 WHERE id = $2", at, item.ID)
+		require.NoError(t, err)
+	}
+	date := func(day int) *time.Time {
+		d := time.Date(2025, 3, day, 12, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	// The reviewed week runs from Monday March 3rd to Monday March 10th 2025
+	doneInWeek := create(t, "Done in the week", nil, nil)
+	completeAt(t, doneInWeek, *date(5))
+	doneOnLastEvening := create(t, "Done on Sunday evening", nil, nil)
+	completeAt(t, doneOnLastEvening, time.Date(2025, 3, 9, 23, 30, 0, 0, time.UTC))
+	doneBefore := create(t, "Done the week before", nil, nil)
+	completeAt(t, doneBefore, *date(1))
+	doneAfter := create(t, "Done the week after", nil, nil)
+	completeAt(t, doneAfter, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC))
+
+	dueNextWeek := create(t, "Due next week", date(12), nil)
+	dueLater := create(t, "Due the week after next", date(18), nil)
+	dueNextWeekDone := create(t, "Due next week but done", date(12), nil)
+	setStatus(t, dueNextWeekDone, todo.StatusCompleted)
+	dueNextWeekArchived := create(t, "Due next week but archived", date(13), nil)
+	setStatus(t, dueNextWeekArchived, todo.StatusArchived)
+
+	loose := create(t, "No next action", nil, nil)
+	withSubtask := create(t, "Broken down", nil, nil)
+	_ = create(t, "First step", nil, &withSubtask.ID)
+	archivedLoose := create(t, "Archived without a date", nil, nil)
+	setStatus(t, archivedLoose, todo.StatusArchived)
+
+	ids := func(section dashboard.ReviewSection) []uuid.UUID {
+		var found []uuid.UUID
+		for _, item := range section.Todos {
+			found = append(found, item.ID)
+		}
+		return found
+	}
+
+	week := "2025-W10"
+	review, err := dashboardService.GetWeeklyReview(newContext(), userID, &dashboard.GetWeeklyReviewQuery{Week: &week})
+	require.NoError(t, err)
+
+	t.Run("the week runs Monday to Monday in the user's timezone", func(t *testing.T) {
+		assert.Equal(t, "UTC", review.Timezone)
+		assert.True(t, review.WeekStart.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, review.WeekEnd.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("completed lists what was completed during the week", func(t *testing.T) {
+		assert.ElementsMatch(t, []uuid.UUID{doneInWeek.ID, doneOnLastEvening.ID}, ids(review.Completed))
+		assert.Equal(t, 2, review.Completed.Total)
+	})
+
+	t.Run("upcoming lists open todos due the week after", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{dueNextWeek.ID}, ids(review.Upcoming))
+	})
+
+	t.Run("overdue lists open todos past their due date", func(t *testing.T) {
+		assert.ElementsMatch(t, []uuid.UUID{dueNextWeek.ID, dueLater.ID}, ids(review.Overdue))
+	})
+
+	t.Run("no next action lists open todos without a due date or subtasks", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{loose.ID}, ids(review.NoNextAction))
+	})
+
+	t.Run("week boundaries follow the user's timezone", func(t *testing.T) {
+		timezone := "Pacific/Auckland"
+		_, err := repos.Preference.UpsertPreferences(ctx, userID, &preference.UpdatePreferencesPayload{Timezone: &timezone})
+		require.NoError(t, err)
+
+		// Sunday 23:30 UTC is already Monday of the next week in Auckland
+		review, err := dashboardService.GetWeeklyReview(newContext(), userID, &dashboard.GetWeeklyReviewQuery{Week: &week})
+		require.NoError(t, err)
+		assert.Equal(t, "Pacific/Auckland", review.Timezone)
+		assert.ElementsMatch(t, []uuid.UUID{doneInWeek.ID}, ids(review.Completed))
+	})
+}
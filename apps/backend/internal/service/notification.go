@@ -0,0 +1,106 @@
+package service
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/email"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/notification"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type NotificationService struct {
+	server         *server.Server
+	todoRepo       *repository.TodoRepository
+	preferenceRepo *repository.PreferenceRepository
+}
+
+func NewNotificationService(server *server.Server, todoRepo *repository.TodoRepository,
+	preferenceRepo *repository.PreferenceRepository,
+) *NotificationService {
+	return &NotificationService{
+		server:         server,
+		todoRepo:       todoRepo,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+// Preview renders the notification email the user would currently receive
+// from the cron jobs, using the same task builders and templates, without
+// sending it
+func (s *NotificationService) Preview(ctx echo.Context, userID string,
+	payload *notification.PreviewPayload,
+) (*notification.Preview, error) {
+	logger := middleware.GetLogger(ctx)
+
+	var (
+		msg *email.Message
+		err error
+	)
+	switch payload.Type {
+	case notification.PreviewTypeReminder:
+		msg, err = s.previewReminder(ctx, userID)
+	case notification.PreviewTypeDigest:
+		msg, err = s.previewDigest(ctx, userID)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("type", payload.Type).Msg("failed to render notification preview")
+		return nil, err
+	}
+
+	return &notification.Preview{
+		Type:    payload.Type,
+		Subject: msg.Subject,
+		HTML:    msg.HTML,
+		Text:    msg.Text,
+	}, nil
+}
+
+func (s *NotificationService) previewReminder(ctx echo.Context, userID string) (*email.Message, error) {
+	reqCtx := ctx.Request().Context()
+	cronCfg := s.server.Config.Cron
+
+	overdue, err := s.todoRepo.GetUserOverdueTodos(reqCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dueSoon, err := s.todoRepo.GetUserTodosDueInHours(reqCtx, userID, cronCfg.ReminderHours)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.preferenceRepo.GetPreferences(reqCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return job.RenderReminderPreview("", overdue, dueSoon, cronCfg.MaxTodosPerUserNotification, time.Now(), prefs)
+}
+
+func (s *NotificationService) previewDigest(ctx echo.Context, userID string) (*email.Message, error) {
+	reqCtx := ctx.Request().Context()
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+
+	stats, err := s.todoRepo.GetWeeklyStatsForUser(reqCtx, userID, weekAgo, now)
+	if err != nil {
+		return nil, err
+	}
+
+	completedTodos, err := s.todoRepo.GetCompletedTodosForUser(reqCtx, userID, weekAgo, now)
+	if err != nil {
+		return nil, err
+	}
+
+	overdueTodos, err := s.todoRepo.GetOverdueTodosForUser(reqCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	task := job.NewWeeklyReportEmailTask(stats, weekAgo, now, completedTodos, overdueTodos)
+	return job.RenderWeeklyReportEmail("", task)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/organization"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type OrganizationService struct {
+	server  *server.Server
+	orgRepo *repository.OrganizationRepository
+}
+
+func NewOrganizationService(server *server.Server, orgRepo *repository.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{
+		server:  server,
+		orgRepo: orgRepo,
+	}
+}
+
+func (s *OrganizationService) CreateOrganization(ctx echo.Context, userID string,
+	payload *organization.CreateOrganizationPayload,
+) (*organization.OrganizationWithRole, error) {
+	logger := middleware.GetLogger(ctx)
+
+	org, err := s.orgRepo.CreateOrganization(ctx.Request().Context(), userID, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create organization")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "organization_created").
+		Str("org_id", org.ID.String()).
+		Str("name", org.Name).
+		Msg("Organization created successfully")
+
+	return org, nil
+}
+
+func (s *OrganizationService) GetOrganizations(ctx echo.Context, userID string) ([]organization.OrganizationWithRole, error) {
+	logger := middleware.GetLogger(ctx)
+
+	orgs, err := s.orgRepo.GetOrganizationsForUser(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organizations")
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (s *OrganizationService) GetMembers(ctx echo.Context,
+	membership *organization.Membership,
+) ([]organization.Membership, error) {
+	logger := middleware.GetLogger(ctx)
+
+	members, err := s.orgRepo.GetMembers(ctx.Request().Context(), membership.OrgID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organization members")
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AddMember adds a user to the caller's organization or changes their role.
+// Only owners and admins may manage members, and an owner's role is fixed.
+func (s *OrganizationService) AddMember(ctx echo.Context, membership *organization.Membership,
+	payload *organization.AddMemberPayload,
+) (*organization.Membership, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if !membership.Role.CanManageMembers() {
+		return nil, errs.NewForbiddenError("Only organization owners and admins can manage members", false)
+	}
+
+	existing, err := s.orgRepo.GetMembership(ctx.Request().Context(), membership.OrgID, payload.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch existing membership")
+		return nil, err
+	}
+
+	if existing != nil && existing.Role == organization.RoleOwner {
+		return nil, errs.NewBadRequestError("The organization owner's role cannot be changed", false, nil, nil, nil)
+	}
+
+	member, err := s.orgRepo.AddMember(ctx.Request().Context(), membership.OrgID, payload.UserID, payload.Role)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to add organization member")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "organization_member_added").
+		Str("org_id", member.OrgID.String()).
+		Str("member_id", member.UserID).
+		Str("role", string(member.Role)).
+		Msg("Organization member added successfully")
+
+	return member, nil
+}
+
+// RemoveMember removes a user from the caller's organization. Members may
+// always leave on their own; removing anyone else takes an owner or admin.
+func (s *OrganizationService) RemoveMember(ctx echo.Context, membership *organization.Membership,
+	userID string,
+) error {
+	logger := middleware.GetLogger(ctx)
+
+	if userID != membership.UserID && !membership.Role.CanManageMembers() {
+		return errs.NewForbiddenError("Only organization owners and admins can manage members", false)
+	}
+
+	target, err := s.orgRepo.GetMembership(ctx.Request().Context(), membership.OrgID, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch membership to remove")
+		return err
+	}
+
+	if target != nil && target.Role == organization.RoleOwner {
+		return errs.NewBadRequestError("The organization owner cannot be removed", false, nil, nil, nil)
+	}
+
+	if err := s.orgRepo.RemoveMember(ctx.Request().Context(), membership.OrgID, userID); err != nil {
+		logger.Error().Err(err).Msg("failed to remove organization member")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "organization_member_removed").
+		Str("org_id", membership.OrgID.String()).
+		Str("member_id", userID).
+		Msg("Organization member removed successfully")
+
+	return nil
+}
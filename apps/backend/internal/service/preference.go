@@ -0,0 +1,55 @@
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/preference"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type PreferenceService struct {
+	server         *server.Server
+	preferenceRepo *repository.PreferenceRepository
+}
+
+func NewPreferenceService(server *server.Server, preferenceRepo *repository.PreferenceRepository) *PreferenceService {
+	return &PreferenceService{
+		server:         server,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+func (s *PreferenceService) GetPreferences(ctx echo.Context, userID string) (*preference.Preferences, error) {
+	logger := middleware.GetLogger(ctx)
+
+	prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences")
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (s *PreferenceService) UpdatePreferences(ctx echo.Context, userID string,
+	payload *preference.UpdatePreferencesPayload,
+) (*preference.Preferences, error) {
+	logger := middleware.GetLogger(ctx)
+
+	prefs, err := s.preferenceRepo.UpsertPreferences(ctx.Request().Context(), userID, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update preferences")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "preferences_updated").
+		Str("timezone", prefs.Timezone).
+		Int("day_start_hour", prefs.DayStartHour).
+		Msg("Preferences updated successfully")
+
+	return prefs, nil
+}
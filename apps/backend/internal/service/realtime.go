@@ -0,0 +1,70 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type RealtimeService struct {
+	server *server.Server
+	events changefeed.Broadcaster
+}
+
+func NewRealtimeService(server *server.Server, events changefeed.Broadcaster) *RealtimeService {
+	return &RealtimeService{
+		server: server,
+		events: events,
+	}
+}
+
+// Listen starts receiving the user's change events, until stop is called
+func (s *RealtimeService) Listen(ctx echo.Context, userID string) (<-chan changefeed.Event, func(), error) {
+	logger := middleware.GetLogger(ctx)
+
+	events, stop, err := s.events.Listen(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to listen for change events")
+		return nil, nil, err
+	}
+
+	return events, stop, nil
+}
+
+// Replay returns the user's kept events after lastEventID. complete is false
+// when that event is no longer kept and the client should refetch its state.
+func (s *RealtimeService) Replay(ctx echo.Context, userID string, lastEventID string) ([]changefeed.Event, bool, error) {
+	logger := middleware.GetLogger(ctx)
+
+	events, complete, err := s.events.Replay(ctx.Request().Context(), userID, lastEventID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to replay change events")
+		return nil, false, err
+	}
+
+	return events, complete, nil
+}
+
+// broadcastEvent pushes a change to the user's open real-time connections.
+// Delivery is best effort: a failure is logged and never fails the write.
+func broadcastEvent(ctx echo.Context, events changefeed.Broadcaster, userID string, eventType string,
+	entityID uuid.UUID, data any,
+) {
+	if events == nil {
+		return
+	}
+
+	logger := middleware.GetLogger(ctx)
+
+	event, err := changefeed.NewEvent(eventType, entityID.String(), data)
+	if err != nil {
+		logger.Warn().Err(err).Str("event_type", eventType).Msg("failed to encode change event")
+		return
+	}
+
+	if err := events.Broadcast(ctx.Request().Context(), userID, event); err != nil {
+		logger.Warn().Err(err).Str("event_type", eventType).Msg("failed to broadcast change event")
+	}
+}
@@ -4,17 +4,33 @@ import (
 	"fmt"
 
 	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
 	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/lib/transcription"
 	"github.com/sriniously/tasker/internal/repository"
 	"github.com/sriniously/tasker/internal/server"
 )
 
 type Services struct {
-	Auth     *AuthService
-	Job      *job.JobService
-	Todo     *TodoService
-	Comment  *CommentService
-	Category *CategoryService
+	AWS           *aws.AWS
+	Auth          *AuthService
+	Job           *job.JobService
+	Todo          *TodoService
+	Comment       *CommentService
+	Category      *CategoryService
+	Admin         *AdminService
+	Preference    *PreferenceService
+	Activity      *ActivityService
+	Organization  *OrganizationService
+	Notification  *NotificationService
+	Transcription *TranscriptionService
+	Streak        *StreakService
+	Dashboard     *DashboardService
+	Account       *AccountService
+	Tag           *TagService
+	Template      *TemplateService
+	Realtime      *RealtimeService
+	Webhook       *WebhookService
 }
 
 func NewServices(s *server.Server, repos *repository.Repositories) (*Services, error) {
@@ -27,11 +43,46 @@ func NewServices(s *server.Server, repos *repository.Repositories) (*Services, e
 		return nil, fmt.Errorf("failed to create AWS client: %w", err)
 	}
 
+	transcriptionProvider, err := transcription.NewProvider(s.Config.Transcription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcription provider: %w", err)
+	}
+
+	transcriptionService := NewTranscriptionService(s, repos.Todo, awsClient, transcriptionProvider)
+	s.Job.SetTranscriber(transcriptionService)
+
+	// Todo, comment and category changes are pushed to the user's open
+	// real-time connections on every instance
+	events := changefeed.NewRedisBroadcaster(s.Redis)
+
+	webhookService := NewWebhookService(s, repos.Webhook)
+	s.Job.SetWebhookDeliverer(webhookService)
+
+	// Bulk changes to todos made outside the todo service are recorded and
+	// signalled the same way its own changes are
+	notifier := changefeed.NewRedisNotifier(s.Redis)
+	recorder := NewActivityRecorder(repos.Activity, notifier, events, webhookService)
+
 	return &Services{
-		Job:      s.Job,
-		Auth:     authService,
-		Category: NewCategoryService(s, repos.Category),
-		Comment:  NewCommentService(s, repos.Comment, repos.Todo),
-		Todo:     NewTodoService(s, repos.Todo, repos.Category, awsClient),
+		AWS:           awsClient,
+		Job:           s.Job,
+		Auth:          authService,
+		Category:      NewCategoryService(s, repos.Category, repos.Todo, events, recorder),
+		Comment:       NewCommentService(s, repos.Comment, repos.Todo, events, webhookService),
+		Todo:          NewTodoService(s, repos.Todo, repos.Category, repos.Activity, repos.Preference,
+			repos.Snapshot, awsClient, notifier, events, webhookService),
+		Admin:         NewAdminService(s, repos.Admin, repos.Account, recorder),
+		Preference:    NewPreferenceService(s, repos.Preference),
+		Activity:      NewActivityService(s, repos.Activity),
+		Organization:  NewOrganizationService(s, repos.Organization),
+		Notification:  NewNotificationService(s, repos.Todo, repos.Preference),
+		Transcription: transcriptionService,
+		Streak:        NewStreakService(s, repos.Streak),
+		Dashboard:     NewDashboardService(s, repos.Todo, repos.Preference, repos.Streak),
+		Account:       NewAccountService(s, repos.Account),
+		Tag:           NewTagService(s, repos.Tag, recorder),
+		Template:      NewTemplateService(s, repos.Template, repos.Todo, repos.Category, repos.Activity),
+		Realtime:      NewRealtimeService(s, events),
+		Webhook:       webhookService,
 	}, nil
 }
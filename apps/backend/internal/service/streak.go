@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/streak"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type StreakService struct {
+	server     *server.Server
+	streakRepo *repository.StreakRepository
+}
+
+func NewStreakService(server *server.Server, streakRepo *repository.StreakRepository) *StreakService {
+	return &StreakService{
+		server:     server,
+		streakRepo: streakRepo,
+	}
+}
+
+// GetStreak returns the user's inbox zero streak as of the last day the
+// streak job judged
+func (s *StreakService) GetStreak(ctx echo.Context, userID string) (*streak.Streak, error) {
+	logger := middleware.GetLogger(ctx)
+
+	st, err := s.streakRepo.GetStreak(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch streak")
+		return nil, err
+	}
+
+	return st, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/tag"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type TagService struct {
+	server   *server.Server
+	tagRepo  *repository.TagRepository
+	recorder *ActivityRecorder
+}
+
+func NewTagService(server *server.Server, tagRepo *repository.TagRepository, recorder *ActivityRecorder) *TagService {
+	return &TagService{
+		server:   server,
+		tagRepo:  tagRepo,
+		recorder: recorder,
+	}
+}
+
+// GetTags lists the user's tags with how many todos carry each
+func (s *TagService) GetTags(ctx echo.Context, userID string, query *tag.GetTagsQuery) ([]tag.Tag, error) {
+	logger := middleware.GetLogger(ctx)
+
+	tags, err := s.tagRepo.GetTags(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch tags")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames the tag on every todo carrying it, merging it into
+// another tag that already has the new name
+func (s *TagService) RenameTag(ctx echo.Context, userID string, tagID uuid.UUID, name string,
+) (*tag.RenameTagResponse, error) {
+	logger := middleware.GetLogger(ctx)
+
+	renamed, changes, err := s.tagRepo.RenameTag(ctx.Request().Context(), userID, tagID, name)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to rename tag")
+		return nil, err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, changes)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "tag_renamed").
+		Str("tag_id", tagID.String()).
+		Str("surviving_tag_id", renamed.Tag.ID.String()).
+		Str("name", renamed.Tag.Name).
+		Bool("merged", renamed.Merged).
+		Int("updated_count", renamed.Updated).
+		Msg("Tag renamed successfully")
+
+	return renamed, nil
+}
+
+// DeleteTag removes the tag from every todo carrying it
+func (s *TagService) DeleteTag(ctx echo.Context, userID string, tagID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	updated, err := s.tagRepo.DeleteTag(ctx.Request().Context(), userID, tagID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to delete tag")
+		return err
+	}
+
+	s.recorder.RecordChanges(ctx, userID, updated)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "tag_deleted").
+		Str("tag_id", tagID.String()).
+		Int("updated_count", len(updated)).
+		Msg("Tag deleted successfully")
+
+	return nil
+}
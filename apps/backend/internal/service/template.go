@@ -0,0 +1,178 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/template"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+type TemplateService struct {
+	server       *server.Server
+	templateRepo *repository.TemplateRepository
+	todoRepo     *repository.TodoRepository
+	categoryRepo *repository.CategoryRepository
+	activityRepo *repository.ActivityRepository
+}
+
+func NewTemplateService(server *server.Server, templateRepo *repository.TemplateRepository,
+	todoRepo *repository.TodoRepository, categoryRepo *repository.CategoryRepository,
+	activityRepo *repository.ActivityRepository,
+) *TemplateService {
+	return &TemplateService{
+		server:       server,
+		templateRepo: templateRepo,
+		todoRepo:     todoRepo,
+		categoryRepo: categoryRepo,
+		activityRepo: activityRepo,
+	}
+}
+
+// CreateTemplate saves the todo, with its subtasks, metadata and checklists,
+// as a template
+func (s *TemplateService) CreateTemplate(ctx echo.Context, userID string,
+	payload *template.CreateTemplatePayload,
+) (*template.Template, error) {
+	logger := middleware.GetLogger(ctx)
+
+	root, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.TodoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
+		return nil, err
+	}
+
+	subtree, err := s.todoRepo.GetTodoSubtree(ctx.Request().Context(), userID, payload.TodoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo subtree")
+		return nil, err
+	}
+
+	created, err := s.templateRepo.CreateTemplate(ctx.Request().Context(), userID, payload.Name,
+		payload.Description, template.FromTodos(*root, subtree))
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create template")
+		return nil, err
+	}
+	created.Variables = created.Root.Variables()
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "template_created").
+		Str("template_id", created.ID.String()).
+		Str("source_todo_id", payload.TodoID.String()).
+		Int("todo_count", len(subtree)+1).
+		Msg("Template created successfully")
+
+	return created, nil
+}
+
+func (s *TemplateService) GetTemplates(ctx echo.Context, userID string,
+	query *template.GetTemplatesQuery,
+) ([]template.Template, error) {
+	logger := middleware.GetLogger(ctx)
+
+	templates, err := s.templateRepo.GetTemplates(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch templates")
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i].Variables = templates[i].Root.Variables()
+	}
+
+	return templates, nil
+}
+
+func (s *TemplateService) GetTemplateByID(ctx echo.Context, userID string, templateID uuid.UUID,
+) (*template.Template, error) {
+	logger := middleware.GetLogger(ctx)
+
+	found, err := s.templateRepo.GetTemplateByID(ctx.Request().Context(), userID, templateID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch template by ID")
+		return nil, err
+	}
+	found.Variables = found.Root.Variables()
+
+	return found, nil
+}
+
+func (s *TemplateService) DeleteTemplate(ctx echo.Context, userID string, templateID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	if err := s.templateRepo.DeleteTemplate(ctx.Request().Context(), userID, templateID); err != nil {
+		logger.Error().Err(err).Msg("failed to delete template")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "template_deleted").
+		Str("template_id", templateID.String()).
+		Msg("Template deleted successfully")
+
+	return nil
+}
+
+// InstantiateTemplate creates a new todo, with its subtasks, from the
+// template, returning the top todo
+func (s *TemplateService) InstantiateTemplate(ctx echo.Context, userID string,
+	payload *template.InstantiateTemplatePayload,
+) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if payload.CategoryID != nil {
+		if _, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID); err != nil {
+			logger.Error().Err(err).Msg("category validation failed")
+			return nil, err
+		}
+	}
+
+	found, err := s.templateRepo.GetTemplateByID(ctx.Request().Context(), userID, payload.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch template to instantiate")
+		return nil, err
+	}
+
+	draft, err := found.Root.Render(payload.Start, time.Now(), payload.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.templateRepo.CreateTodos(ctx.Request().Context(), userID, draft, payload.CategoryID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate template")
+		return nil, err
+	}
+
+	// History is best effort, as for any other created todo
+	if _, err := s.activityRepo.CreateActivity(ctx.Request().Context(), &activity.Activity{
+		TodoID:  created.ID,
+		UserID:  userID,
+		ActorID: userID,
+		Action:  activity.ActionCreated,
+		Changes: activity.Diff(nil, activity.SnapshotTodo(created)),
+	}); err != nil {
+		logger.Error().Err(err).Str("todo_id", created.ID.String()).Msg("failed to record todo activity")
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "template_instantiated").
+		Str("template_id", payload.ID.String()).
+		Str("todo_id", created.ID.String()).
+		Bool("due_dates_set", payload.Start != nil).
+		Msg("Template instantiated successfully")
+
+	return created, nil
+}
@@ -1,64 +1,107 @@
 package service
 
 import (
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sriniously/tasker/internal/errs"
 	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/lib/feed"
+	"github.com/sriniously/tasker/internal/lib/job"
+	"github.com/sriniously/tasker/internal/lib/token"
 	"github.com/sriniously/tasker/internal/middleware"
 	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/model/organization"
 	"github.com/sriniously/tasker/internal/model/todo"
 	"github.com/sriniously/tasker/internal/repository"
 	"github.com/sriniously/tasker/internal/server"
 )
 
 type TodoService struct {
-	server       *server.Server
-	todoRepo     *repository.TodoRepository
-	categoryRepo *repository.CategoryRepository
-	awsClient    *aws.AWS
+	server         *server.Server
+	todoRepo       *repository.TodoRepository
+	categoryRepo   *repository.CategoryRepository
+	activityRepo   *repository.ActivityRepository
+	preferenceRepo *repository.PreferenceRepository
+	snapshotRepo   *repository.SnapshotRepository
+	awsClient      *aws.AWS
+	notifier       changefeed.Notifier
+	recorder       *ActivityRecorder
 }
 
 func NewTodoService(server *server.Server, todoRepo *repository.TodoRepository,
-	categoryRepo *repository.CategoryRepository, awsClient *aws.AWS,
+	categoryRepo *repository.CategoryRepository, activityRepo *repository.ActivityRepository,
+	preferenceRepo *repository.PreferenceRepository, snapshotRepo *repository.SnapshotRepository,
+	awsClient *aws.AWS, notifier changefeed.Notifier, events changefeed.Broadcaster, webhooks *WebhookService,
 ) *TodoService {
 	return &TodoService{
-		server:       server,
-		todoRepo:     todoRepo,
-		categoryRepo: categoryRepo,
-		awsClient:    awsClient,
+		server:         server,
+		todoRepo:       todoRepo,
+		categoryRepo:   categoryRepo,
+		activityRepo:   activityRepo,
+		preferenceRepo: preferenceRepo,
+		snapshotRepo:   snapshotRepo,
+		awsClient:      awsClient,
+		notifier:       notifier,
+		recorder:       NewActivityRecorder(activityRepo, notifier, events, webhooks),
 	}
 }
 
-func (s *TodoService) CreateTodo(ctx echo.Context, userID string, payload *todo.CreateTodoPayload) (*todo.Todo, error) {
-	logger := middleware.GetLogger(ctx)
+// recordActivity appends an entry, made by the user themselves, to the todo's
+// activity log
+func (s *TodoService) recordActivity(ctx echo.Context, userID string, todoID uuid.UUID, action activity.Action,
+	changes activity.Changes,
+) *activity.Activity {
+	return s.recorder.Record(ctx, userID, userID, todoID, action, changes)
+}
 
-	// Validate parent todo exists and belongs to user (if provided)
-	if payload.ParentTodoID != nil {
-		parentTodo, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, *payload.ParentTodoID)
-		if err != nil {
-			logger.Error().Err(err).Msg("parent todo validation failed")
-			return nil, err
-		}
+// offerUndo hands the client a short-lived token in the undo header that
+// reverts the recorded change through POST /todos/:id/undo. Nothing is
+// offered when the change wasn't recorded.
+func (s *TodoService) offerUndo(ctx echo.Context, userID string, entry *activity.Activity) {
+	if entry == nil {
+		return
+	}
 
-		if !parentTodo.CanHaveChildren() {
-			err := errs.NewBadRequestError("Parent todo cannot have children (subtasks can't have subtasks)", false, nil, nil, nil)
-			logger.Warn().Msg("parent todo cannot have children")
-			return nil, err
-		}
+	now := time.Now()
+	raw, err := token.Sign(s.server.Config.Auth.SecretKey, token.Claims{
+		Subject:   userID,
+		Purpose:   token.PurposeUndo,
+		ID:        entry.ID.String(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.server.Config.Todo.GetUndoWindow()).Unix(),
+	})
+	if err != nil {
+		middleware.GetLogger(ctx).Warn().Err(err).Msg("failed to sign undo token")
+		return
 	}
 
-	// Validate category exists and belongs to user (if provided)
-	if payload.CategoryID != nil {
-		_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID)
-		if err != nil {
-			logger.Error().Err(err).Msg("category validation failed")
-			return nil, err
-		}
+	ctx.Response().Header().Set(activity.UndoTokenHeader, raw)
+}
+
+// recordUpdate records the fields that changed between two reads of a todo,
+// if any did
+func (s *TodoService) recordUpdate(ctx echo.Context, userID string, before, after *todo.Todo) {
+	if changes := activity.Diff(activity.SnapshotTodo(before), activity.SnapshotTodo(after)); len(changes) > 0 {
+		s.recordActivity(ctx, userID, after.ID, activity.ActionFor(changes), changes)
+	}
+}
+
+func (s *TodoService) CreateTodo(ctx echo.Context, userID string, payload *todo.CreateTodoPayload) (*todo.TodoWithWarnings, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if err := s.prepareCreateTodo(ctx, userID, payload); err != nil {
+		return nil, err
 	}
 
 	todoItem, err := s.todoRepo.CreateTodo(ctx.Request().Context(), userID, payload)
@@ -67,308 +110,2623 @@ func (s *TodoService) CreateTodo(ctx echo.Context, userID string, payload *todo.
 		return nil, err
 	}
 
-	// Business event log
-	eventLogger := middleware.GetLogger(ctx)
-	eventLogger.Info().
-		Str("event", "todo_created").
-		Str("todo_id", todoItem.ID.String()).
-		Str("title", todoItem.Title).
-		Str("category_id", func() string {
-			if todoItem.CategoryID != nil {
-				return todoItem.CategoryID.String()
-			}
-			return ""
-		}()).
-		Str("priority", string(todoItem.Priority)).
-		Msg("Todo created successfully")
+	s.logTodoCreated(ctx, todoItem)
 
-	return todoItem, nil
+	s.recordActivity(ctx, userID, todoItem.ID, activity.ActionCreated,
+		activity.Diff(nil, activity.SnapshotTodo(todoItem)))
+
+	return &todo.TodoWithWarnings{
+		Todo:     *todoItem,
+		Warnings: s.duplicateTitleWarnings(ctx, userID, todoItem),
+	}, nil
 }
 
-func (s *TodoService) GetTodoByID(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
+// ImportICS creates todos from the VTODO and VEVENT entries of an iCalendar
+// file, reading floating times in the user's timezone. A dry run parses the
+// file and reports what would be imported without saving anything.
+func (s *TodoService) ImportICS(ctx echo.Context, userID string, payload *todo.ImportICSPayload,
+	file *multipart.FileHeader,
+) (*todo.ImportResult, error) {
 	logger := middleware.GetLogger(ctx)
 
-	todoItem, err := s.todoRepo.GetTodoByID(ctx.Request().Context(), userID, todoID)
+	code := errs.CodeInvalidImport
+	if file.Size > todo.MaxImportFileSize {
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("Import files must be at most %d MB", todo.MaxImportFileSize>>20), true, &code, nil, nil)
+	}
+
+	src, err := file.Open()
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to fetch todo by ID")
+		logger.Error().Err(err).Msg("failed to open uploaded import file")
+		return nil, errs.NewBadRequestError("failed to open uploaded file", false, nil, nil, nil)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, todo.MaxImportFileSize))
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to read uploaded import file")
+		return nil, errs.NewBadRequestError("failed to read uploaded file", false, nil, nil, nil)
+	}
+
+	prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences for import")
 		return nil, err
 	}
 
-	return todoItem, nil
-}
+	imported, skipped, err := todo.ParseICS(data, prefs.Location())
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid iCalendar import")
+		return nil, err
+	}
 
-func (s *TodoService) GetTodos(ctx echo.Context, userID string, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
-	logger := middleware.GetLogger(ctx)
+	if maxTodos := s.server.Config.Todo.GetBulkMaxIDs(); len(imported) > maxTodos {
+		code := errs.CodeTooManyTodos
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("At most %d todos can be imported at once, got %d", maxTodos, len(imported)),
+			true, &code, nil, nil,
+		)
+	}
 
-	result, err := s.todoRepo.GetTodos(ctx.Request().Context(), userID, query)
+	result := &todo.ImportResult{
+		DryRun:  payload.DryRun,
+		Todos:   imported,
+		Skipped: skipped,
+	}
+	if result.Todos == nil {
+		result.Todos = []todo.ImportedTodo{}
+	}
+	if result.Skipped == nil {
+		result.Skipped = []todo.ImportSkip{}
+	}
+
+	// A dry run still checks the category but doesn't provision the inbox
+	target := &todo.CreateTodoPayload{CategoryID: payload.CategoryID}
+	if payload.DryRun {
+		if payload.CategoryID != nil {
+			if _, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID); err != nil {
+				logger.Error().Err(err).Msg("category validation failed")
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	if err := s.prepareCreateTodo(ctx, userID, target); err != nil {
+		return nil, err
+	}
+
+	created, err := s.todoRepo.ImportTodos(ctx.Request().Context(), userID, imported, target.CategoryID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to fetch todos")
+		logger.Error().Err(err).Msg("failed to import todos")
 		return nil, err
 	}
+	result.Imported = len(created)
+
+	for i := range created {
+		s.recordActivity(ctx, userID, created[i].ID, activity.ActionCreated,
+			activity.Diff(nil, activity.SnapshotTodo(&created[i])))
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_imported").
+		Str("format", "ics").
+		Int("imported", result.Imported).
+		Int("skipped", len(skipped)).
+		Msg("Todos imported successfully")
 
 	return result, nil
 }
 
-func (s *TodoService) UpdateTodo(ctx echo.Context, userID string, payload *todo.UpdateTodoPayload) (*todo.Todo, error) {
+// CompleteWithFollowUp marks a todo completed and creates the next todo in
+// the chain from payload, atomically.
+func (s *TodoService) CompleteWithFollowUp(ctx echo.Context, userID string,
+	payload *todo.CompleteWithFollowUpPayload,
+) (*todo.CompleteWithFollowUpResult, error) {
 	logger := middleware.GetLogger(ctx)
 
-	// Validate parent todo exists and belongs to user (if provided)
-	if payload.ParentTodoID != nil {
-		parentTodo, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, *payload.ParentTodoID)
-		if err != nil {
-			logger.Error().Err(err).Msg("parent todo validation failed")
-			return nil, err
-		}
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo to complete")
+		return nil, err
+	}
 
-		if parentTodo.ID == payload.ID {
-			err := errs.NewBadRequestError("Todo cannot be its own parent", false, nil, nil, nil)
-			logger.Warn().Msg("todo cannot be its own parent")
-			return nil, err
-		}
+	if err := s.checkStatusTransition(todo.StatusCompleted, *existing); err != nil {
+		logger.Warn().Err(err).Msg("todo cannot be completed from its current status")
+		return nil, err
+	}
 
-		if !parentTodo.CanHaveChildren() {
-			err := errs.NewBadRequestError("Parent todo cannot have children (subtasks can't have subtasks)", false, nil, nil, nil)
-			logger.Warn().Msg("parent todo cannot have children")
-			return nil, err
-		}
+	if err := s.prepareCreateTodo(ctx, userID, &payload.FollowUp); err != nil {
+		return nil, err
+	}
 
-		logger.Debug().Msg("parent todo validation passed")
+	completed, followUp, err := s.todoRepo.CompleteWithFollowUp(ctx.Request().Context(), userID, payload.ID,
+		&payload.FollowUp)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to complete todo with follow-up")
+		return nil, err
 	}
 
-	// Validate category exists and belongs to user (if provided)
-	if payload.CategoryID != nil {
-		_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID)
-		if err != nil {
-			logger.Error().Err(err).Msg("category validation failed")
-			return nil, err
-		}
+	if changes := activity.Diff(activity.SnapshotTodo(existing), activity.SnapshotTodo(completed)); len(changes) > 0 {
+		s.recordActivity(ctx, userID, completed.ID, activity.ActionFor(changes), changes)
+	}
+	s.recordActivity(ctx, userID, followUp.ID, activity.ActionCreated,
+		activity.Diff(nil, activity.SnapshotTodo(followUp)))
 
-		logger.Debug().Msg("category validation passed")
+	s.logTodoCreated(ctx, followUp)
+
+	return &todo.CompleteWithFollowUpResult{
+		Completed: *completed,
+		FollowUp: todo.TodoWithWarnings{
+			Todo:     *followUp,
+			Warnings: s.duplicateTitleWarnings(ctx, userID, followUp),
+		},
+	}, nil
+}
+
+// checkStatusTransition rejects moving any of the todos to status when the
+// configured state machine doesn't allow it
+func (s *TodoService) checkStatusTransition(status todo.Status, todos ...todo.Todo) error {
+	transitions := todo.DefaultStatusTransitions().WithOverrides(s.server.Config.Todo.GetStatusTransitions())
+	return transitions.Validate(status, todos...)
+}
+
+func (s *TodoService) PromoteToTopLevel(ctx echo.Context, userID string, todoID uuid.UUID,
+	keepChildren bool,
+) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo to promote")
+		return nil, err
 	}
 
-	updatedTodo, err := s.todoRepo.UpdateTodo(ctx.Request().Context(), userID, payload)
+	promoted, err := s.todoRepo.PromoteToTopLevel(ctx.Request().Context(), userID, todoID, keepChildren)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to update todo")
+		logger.Error().Err(err).Msg("failed to promote todo")
 		return nil, err
 	}
 
+	if changes := activity.Diff(activity.SnapshotTodo(existing), activity.SnapshotTodo(promoted)); len(changes) > 0 {
+		s.recordActivity(ctx, userID, promoted.ID, activity.ActionUpdated, changes)
+	}
+
 	// Business event log
 	eventLogger := middleware.GetLogger(ctx)
 	eventLogger.Info().
-		Str("event", "todo_updated").
-		Str("todo_id", updatedTodo.ID.String()).
-		Str("title", updatedTodo.Title).
-		Str("category_id", func() string {
-			if updatedTodo.CategoryID != nil {
-				return updatedTodo.CategoryID.String()
-			}
-			return ""
-		}()).
-		Str("priority", string(updatedTodo.Priority)).
-		Str("status", string(updatedTodo.Status)).
-		Msg("Todo updated successfully")
+		Str("event", "todo_promoted").
+		Str("todo_id", promoted.ID.String()).
+		Str("former_parent_todo_id", existing.ParentTodoID.String()).
+		Bool("keep_children", keepChildren).
+		Msg("Todo promoted to top level successfully")
 
-	return updatedTodo, nil
+	return promoted, nil
 }
 
-func (s *TodoService) DeleteTodo(ctx echo.Context, userID string, todoID uuid.UUID) error {
+// SetPosition moves the todo among its siblings
+func (s *TodoService) SetPosition(ctx echo.Context, userID string, payload *todo.SetPositionPayload,
+) (*todo.Todo, error) {
 	logger := middleware.GetLogger(ctx)
 
-	err := s.todoRepo.DeleteTodo(ctx.Request().Context(), userID, todoID)
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete todo")
-		return err
+		logger.Error().Err(err).Msg("todo validation failed for position")
+		return nil, err
+	}
+
+	moved, err := s.todoRepo.SetPosition(ctx.Request().Context(), userID, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to set todo position")
+		return nil, err
 	}
 
+	s.recordUpdate(ctx, userID, existing, moved)
+
 	// Business event log
 	eventLogger := middleware.GetLogger(ctx)
 	eventLogger.Info().
-		Str("event", "todo_deleted").
-		Str("todo_id", todoID.String()).
-		Msg("Todo deleted successfully")
+		Str("event", "todo_repositioned").
+		Str("todo_id", moved.ID.String()).
+		Int("sort_order", moved.SortOrder).
+		Msg("Todo position set successfully")
 
-	return nil
+	return moved, nil
 }
 
-func (s *TodoService) GetTodoStats(ctx echo.Context, userID string) (*todo.TodoStats, error) {
+// BumpTodo re-surfaces a todo in recency-sorted listings without changing it
+func (s *TodoService) BumpTodo(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
 	logger := middleware.GetLogger(ctx)
 
-	stats, err := s.todoRepo.GetTodoStats(ctx.Request().Context(), userID)
+	bumped, err := s.todoRepo.BumpTodo(ctx.Request().Context(), userID, todoID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to fetch todo statistics")
+		logger.Error().Err(err).Msg("failed to bump todo")
 		return nil, err
 	}
 
-	return stats, nil
+	s.recordActivity(ctx, userID, bumped.ID, activity.ActionBumped, activity.Changes{})
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_bumped").
+		Str("todo_id", bumped.ID.String()).
+		Msg("Todo bumped successfully")
+
+	return bumped, nil
 }
 
-func (s *TodoService) UploadTodoAttachment(
-	ctx echo.Context,
-	userID string,
-	todoID uuid.UUID,
-	file *multipart.FileHeader,
-) (*todo.TodoAttachment, error) {
+func (s *TodoService) AddChecklistItem(ctx echo.Context, userID string,
+	payload *todo.AddChecklistItemPayload,
+) (*todo.Todo, error) {
 	logger := middleware.GetLogger(ctx)
 
-	// Verify todo exists and belongs to user
-	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
 	if err != nil {
-		logger.Error().Err(err).Msg("todo validation failed")
+		logger.Error().Err(err).Msg("todo validation failed for checklist item add")
 		return nil, err
 	}
 
-	// Open uploaded file
-	src, err := file.Open()
+	updated, err := s.todoRepo.AddChecklistItem(ctx.Request().Context(), userID, payload.ID, payload.Text)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to open uploaded file")
-		return nil, errs.NewBadRequestError("failed to open uploaded file", false, nil, nil, nil)
+		logger.Error().Err(err).Msg("failed to add checklist item")
+		return nil, err
 	}
-	defer src.Close()
 
-	// Upload to S3
-	s3Key, err := s.awsClient.S3.UploadFile(
-		ctx.Request().Context(),
-		s.server.Config.AWS.UploadBucket,
-		"todos/attachments/"+file.Filename,
-		src,
-	)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to upload file to S3")
-		return nil, errors.Wrap(err, "failed to upload file")
-	}
+	s.recordUpdate(ctx, userID, existing, updated)
 
-	// Detect MIME type
-	src, err = file.Open()
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to reopen file for MIME detection")
-		return nil, errs.NewBadRequestError("failed to process file", false, nil, nil, nil)
-	}
-	defer src.Close()
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "checklist_item_added").
+		Str("todo_id", updated.ID.String()).
+		Int("checklist_total", updated.ChecklistTotal).
+		Msg("Checklist item added successfully")
 
-	buffer := make([]byte, 512)
-	_, err = src.Read(buffer)
+	return updated, nil
+}
+
+func (s *TodoService) UpdateChecklistItem(ctx echo.Context, userID string,
+	payload *todo.UpdateChecklistItemPayload,
+) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to read file for MIME detection")
-		return nil, errs.NewBadRequestError("failed to process file", false, nil, nil, nil)
+		logger.Error().Err(err).Msg("todo validation failed for checklist item update")
+		return nil, err
 	}
-	mimeType := http.DetectContentType(buffer)
 
-	// Create attachment record
-	attachment, err := s.todoRepo.UploadTodoAttachment(
-		ctx.Request().Context(),
-		todoID,
-		userID,
-		s3Key,
-		file.Filename,
-		file.Size,
-		mimeType,
-	)
+	updated, err := s.todoRepo.UpdateChecklistItem(ctx.Request().Context(), userID, payload.ID, payload.ItemID,
+		payload.Text, payload.Done)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create attachment record")
+		logger.Error().Err(err).Msg("failed to update checklist item")
 		return nil, err
 	}
 
-	logger.Info().
-		Str("attachment_id", attachment.ID.String()).
-		Str("s3_key", s3Key).
-		Msg("uploaded todo attachment")
+	s.recordUpdate(ctx, userID, existing, updated)
 
-	return attachment, nil
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "checklist_item_updated").
+		Str("todo_id", updated.ID.String()).
+		Str("item_id", payload.ItemID.String()).
+		Int("checklist_done", updated.ChecklistDone).
+		Int("checklist_total", updated.ChecklistTotal).
+		Msg("Checklist item updated successfully")
+
+	return updated, nil
 }
 
-func (s *TodoService) DeleteTodoAttachment(
-	ctx echo.Context,
-	userID string,
-	todoID uuid.UUID,
-	attachmentID uuid.UUID,
-) error {
+func (s *TodoService) RemoveChecklistItem(ctx echo.Context, userID string,
+	payload *todo.DeleteChecklistItemPayload,
+) (*todo.Todo, error) {
 	logger := middleware.GetLogger(ctx)
 
-	// Verify todo exists and belongs to user
-	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
 	if err != nil {
-		logger.Error().Err(err).Msg("todo validation failed")
-		return err
+		logger.Error().Err(err).Msg("todo validation failed for checklist item removal")
+		return nil, err
 	}
 
-	// Get attachment details for S3 deletion
-	attachment, err := s.todoRepo.GetTodoAttachment(
-		ctx.Request().Context(),
-		todoID,
-		attachmentID,
-	)
+	updated, err := s.todoRepo.RemoveChecklistItem(ctx.Request().Context(), userID, payload.ID, payload.ItemID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to get attachment details")
-		return err
+		logger.Error().Err(err).Msg("failed to remove checklist item")
+		return nil, err
+	}
+
+	s.recordUpdate(ctx, userID, existing, updated)
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "checklist_item_removed").
+		Str("todo_id", updated.ID.String()).
+		Str("item_id", payload.ItemID.String()).
+		Msg("Checklist item removed successfully")
+
+	return updated, nil
+}
+
+// CopyAsFresh copies a todo and its subtasks into another category with their
+// progress reset, returning the copy of the todo itself
+func (s *TodoService) CopyAsFresh(ctx echo.Context, userID string, payload *todo.CopyFreshPayload) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if _, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, payload.CategoryID); err != nil {
+		logger.Error().Err(err).Msg("target category validation failed")
+		return nil, err
+	}
+
+	var shift *time.Duration
+	if payload.ShiftDueDays != nil {
+		d := time.Duration(*payload.ShiftDueDays) * 24 * time.Hour
+		shift = &d
+	}
+
+	copied, err := s.todoRepo.CopyAsFresh(ctx.Request().Context(), userID, payload.ID, payload.CategoryID, shift)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to copy todo as fresh")
+		return nil, err
+	}
+
+	s.recordActivity(ctx, userID, copied.ID, activity.ActionCreated,
+		activity.Diff(nil, activity.SnapshotTodo(copied)))
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_copied_fresh").
+		Str("source_todo_id", payload.ID.String()).
+		Str("todo_id", copied.ID.String()).
+		Str("category_id", payload.CategoryID.String()).
+		Bool("due_dates_shifted", shift != nil).
+		Msg("Todo copied as fresh successfully")
+
+	return copied, nil
+}
+
+// CreateOrgTodo creates a todo shared with the caller's organization. Viewers
+// can read organization todos but not create them.
+func (s *TodoService) CreateOrgTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	payload *todo.CreateTodoPayload,
+) (*todo.TodoWithWarnings, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if !membership.Role.CanWrite() {
+		return nil, errs.NewForbiddenError("Viewers cannot create organization todos", false)
+	}
+
+	// Subtasks of an organization todo must live in the same organization
+	if payload.ParentTodoID != nil {
+		parentTodo, err := s.todoRepo.GetOrgTodoByID(ctx.Request().Context(), membership.OrgID, *payload.ParentTodoID)
+		if err != nil {
+			logger.Error().Err(err).Msg("parent todo validation failed")
+			return nil, err
+		}
+
+		if !parentTodo.CanHaveChildren() {
+			logger.Warn().Msg("parent todo cannot have children")
+			return nil, errs.NewBadRequestError("Parent todo cannot have children (subtasks can't have subtasks)", false, nil, nil, nil)
+		}
+
+		if err := s.checkChildLimit(ctx, parentTodo.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// Categories stay personal, so an organization todo is filed under one
+	// of its creator's categories and is never put in their Inbox
+	if payload.CategoryID != nil {
+		_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID)
+		if err != nil {
+			logger.Error().Err(err).Msg("category validation failed")
+			return nil, err
+		}
+	}
+
+	todoItem, err := s.todoRepo.CreateOrgTodo(ctx.Request().Context(), userID, membership.OrgID, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create organization todo")
+		return nil, err
+	}
+
+	s.logTodoCreated(ctx, todoItem)
+
+	s.recordActivity(ctx, userID, todoItem.ID, activity.ActionCreated,
+		activity.Diff(nil, activity.SnapshotTodo(todoItem)))
+
+	return &todo.TodoWithWarnings{
+		Todo:     *todoItem,
+		Warnings: s.duplicateTitleWarnings(ctx, userID, todoItem),
+	}, nil
+}
+
+// checkChildLimit rejects adding one more subtask under parentID once it has
+// the configured maximum, not counting the todos in excludeIDs
+func (s *TodoService) checkChildLimit(ctx echo.Context, parentID uuid.UUID, excludeIDs []uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	count, err := s.todoRepo.CountChildren(ctx.Request().Context(), parentID, excludeIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count subtasks of parent todo")
+		return err
+	}
+
+	if maxChildren := s.server.Config.Todo.GetMaxChildren(); count >= maxChildren {
+		logger.Warn().Int("max_children", maxChildren).Msg("parent todo has the maximum number of subtasks")
+		return todo.MaxChildrenExceededError(maxChildren)
+	}
+
+	return nil
+}
+
+// prepareCreateTodo validates the parent and category of a todo about to be
+// created and files it into the Inbox when it has no category.
+func (s *TodoService) prepareCreateTodo(ctx echo.Context, userID string, payload *todo.CreateTodoPayload) error {
+	logger := middleware.GetLogger(ctx)
+
+	// Validate parent todo exists and belongs to user (if provided)
+	if payload.ParentTodoID != nil {
+		parentTodo, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, *payload.ParentTodoID)
+		if err != nil {
+			logger.Error().Err(err).Msg("parent todo validation failed")
+			return err
+		}
+
+		if !parentTodo.CanHaveChildren() {
+			err := errs.NewBadRequestError("Parent todo cannot have children (subtasks can't have subtasks)", false, nil, nil, nil)
+			logger.Warn().Msg("parent todo cannot have children")
+			return err
+		}
+
+		if err := s.checkChildLimit(ctx, parentTodo.ID, nil); err != nil {
+			return err
+		}
+	}
+
+	// Validate category exists and belongs to user (if provided)
+	if payload.CategoryID != nil {
+		_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID)
+		if err != nil {
+			logger.Error().Err(err).Msg("category validation failed")
+			return err
+		}
+	} else if s.server.Config.Todo.IsInboxEnabled() {
+		inbox, err := s.categoryRepo.GetOrCreateInbox(ctx.Request().Context(), userID)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to provision inbox category")
+			return err
+		}
+		payload.CategoryID = &inbox.ID
+	}
+
+	return nil
+}
+
+// logTodoCreated emits the business event for a newly created todo
+func (s *TodoService) logTodoCreated(ctx echo.Context, todoItem *todo.Todo) {
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_created").
+		Str("todo_id", todoItem.ID.String()).
+		Str("title", todoItem.Title).
+		Str("category_id", func() string {
+			if todoItem.CategoryID != nil {
+				return todoItem.CategoryID.String()
+			}
+			return ""
+		}()).
+		Str("priority", string(todoItem.Priority)).
+		Msg("Todo created successfully")
+}
+
+// QuickAddTodo parses a free-form string into a todo and creates it. Relative
+// due dates are resolved in the user's timezone and a named category that
+// doesn't exist is reported as a warning rather than failing the request.
+func (s *TodoService) QuickAddTodo(ctx echo.Context, userID string,
+	payload *todo.QuickAddTodoPayload,
+) (*todo.QuickAddResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences for quick add")
+		return nil, err
+	}
+
+	parsed := todo.ParseQuickAdd(payload.Text, prefs.DayClock(time.Now()), prefs.Location())
+
+	createPayload := &todo.CreateTodoPayload{
+		Title:    parsed.Title,
+		Priority: parsed.Priority,
+		DueDate:  parsed.DueDate,
+	}
+	if parsed.DueDate != nil {
+		allDay := true
+		createPayload.AllDay = &allDay
+	}
+	if len(parsed.Tags) > 0 {
+		createPayload.Metadata = &todo.Metadata{Tags: parsed.Tags}
+	}
+
+	var warnings []todo.Warning
+	if parsed.Category != nil {
+		categoryItem, err := s.categoryRepo.GetCategoryByName(ctx.Request().Context(), userID, *parsed.Category)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to resolve quick add category")
+			return nil, err
+		}
+
+		if categoryItem != nil {
+			createPayload.CategoryID = &categoryItem.ID
+		} else {
+			warnings = append(warnings, todo.Warning{
+				Code:    todo.WarningCategoryNotFound,
+				Message: fmt.Sprintf("No category named %q was found", *parsed.Category),
+			})
+		}
+	}
+
+	if err := createPayload.Validate(); err != nil {
+		logger.Warn().Err(err).Msg("quick add produced an invalid todo")
+		return nil, errs.NewBadRequestError("Could not create a todo from the text: "+err.Error(), true, nil, nil, nil)
+	}
+
+	created, err := s.CreateTodo(ctx, userID, createPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Warnings = append(created.Warnings, warnings...)
+
+	return &todo.QuickAddResult{
+		Todo:           *created,
+		Interpretation: parsed,
+	}, nil
+}
+
+func (s *TodoService) GetTodoByID(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.PopulatedTodo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoItem, err := s.todoRepo.GetTodoByID(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo by ID")
+		return nil, err
+	}
+
+	return todoItem, nil
+}
+
+func (s *TodoService) GetTodos(ctx echo.Context, userID string, query *todo.GetTodosQuery) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	if query.Snapshot != nil {
+		return s.getTodosInSnapshot(ctx, userID, *query.Snapshot, query)
+	}
+
+	result, err := s.todoRepo.GetTodos(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TodoService) getTodosInSnapshot(ctx echo.Context, userID string, token string,
+	query *todo.GetTodosQuery,
+) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	snapshotID, err := s.snapshotRepo.SnapshotID(userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.todoRepo.GetTodosInSnapshot(ctx.Request().Context(), userID, snapshotID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos from snapshot")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateListSnapshot opens a short-lived consistent view of the user's todos
+// for paging through with the snapshot query parameter. Only the instance
+// that opened it can page through it.
+func (s *TodoService) CreateListSnapshot(ctx echo.Context, userID string) (*todo.ListSnapshot, error) {
+	logger := middleware.GetLogger(ctx)
+
+	snapshot, err := s.snapshotRepo.OpenSnapshot(ctx.Request().Context(), userID,
+		s.server.Config.Todo.GetSnapshotTTL())
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to open list snapshot")
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// DeleteListSnapshot releases the snapshot before it expires
+func (s *TodoService) DeleteListSnapshot(ctx echo.Context, userID string, token string) error {
+	s.snapshotRepo.CloseSnapshot(userID, token)
+	return nil
+}
+
+func (s *TodoService) GetChildren(ctx echo.Context, userID string,
+	query *todo.GetChildrenQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	result, err := s.todoRepo.GetChildren(ctx.Request().Context(), userID, query.ID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo children")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TodoService) GetOrgChildren(ctx echo.Context, membership *organization.Membership,
+	query *todo.GetChildrenQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	result, err := s.todoRepo.GetOrgChildren(ctx.Request().Context(), membership.OrgID, query.ID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organization todo children")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TodoService) GetOrgTodoByID(ctx echo.Context, membership *organization.Membership,
+	todoID uuid.UUID,
+) (*todo.PopulatedTodo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoItem, err := s.todoRepo.GetOrgTodoByID(ctx.Request().Context(), membership.OrgID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organization todo by ID")
+		return nil, err
+	}
+
+	return todoItem, nil
+}
+
+func (s *TodoService) GetOrgTodos(ctx echo.Context, membership *organization.Membership,
+	query *todo.GetTodosQuery,
+) (*model.PaginatedResponse[todo.PopulatedTodo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	result, err := s.todoRepo.GetOrgTodos(ctx.Request().Context(), membership.OrgID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organization todos")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CountTodos counts the todos GetTodos would list across every page
+func (s *TodoService) CountTodos(ctx echo.Context, userID string, query *todo.GetTodosQuery) (*todo.TodoCount, error) {
+	logger := middleware.GetLogger(ctx)
+
+	count, err := s.todoRepo.CountTodos(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count todos")
+		return nil, err
+	}
+
+	return &todo.TodoCount{Count: count}, nil
+}
+
+func (s *TodoService) CountOrgTodos(ctx echo.Context, membership *organization.Membership,
+	query *todo.GetTodosQuery,
+) (*todo.TodoCount, error) {
+	logger := middleware.GetLogger(ctx)
+
+	count, err := s.todoRepo.CountOrgTodos(ctx.Request().Context(), membership.OrgID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count organization todos")
+		return nil, err
+	}
+
+	return &todo.TodoCount{Count: count}, nil
+}
+
+func (s *TodoService) HasOverdue(ctx echo.Context, userID string) (*todo.OverdueIndicator, error) {
+	logger := middleware.GetLogger(ctx)
+
+	hasOverdue, err := s.todoRepo.HasOverdue(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to check for overdue todos")
+		return nil, err
+	}
+
+	return &todo.OverdueIndicator{HasOverdue: hasOverdue}, nil
+}
+
+// GetOverdueBuckets groups the user's overdue todos by how long ago they
+// were due, counting days in the user's timezone
+func (s *TodoService) GetOverdueBuckets(ctx echo.Context, userID string, includeIDs bool) (*todo.OverdueBuckets, error) {
+	logger := middleware.GetLogger(ctx)
+
+	prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences for overdue buckets")
+		return nil, err
+	}
+
+	buckets, err := s.todoRepo.GetOverdueBuckets(ctx.Request().Context(), userID, prefs.Location().String(),
+		prefs.DayStartHour)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch overdue buckets")
+		return nil, err
+	}
+
+	if !includeIDs {
+		for i := range buckets.Buckets {
+			buckets.Buckets[i].TodoIDs = nil
+		}
+	}
+
+	return buckets, nil
+}
+
+func (s *TodoService) GetDeferredTodos(ctx echo.Context, userID string) ([]todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todos, err := s.todoRepo.GetDeferredTodos(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch deferred todos")
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+func (s *TodoService) GetStaleTodos(ctx echo.Context, userID string, days int) ([]todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todos, err := s.todoRepo.GetStaleTodos(ctx.Request().Context(), userID, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch stale todos")
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+func (s *TodoService) GetIncompleteTodos(ctx echo.Context, userID string,
+	missing []todo.MissingField,
+) ([]todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todos, err := s.todoRepo.GetIncompleteTodos(ctx.Request().Context(), userID, missing)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch incomplete todos")
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// PreviewRecurrence lists the upcoming occurrences of a proposed rule. Without
+// an explicit start it counts from now in the user's timezone, so weekly rules
+// land on the user's weekdays.
+func (s *TodoService) PreviewRecurrence(ctx echo.Context, userID string,
+	payload *todo.RecurrencePreviewPayload,
+) (*todo.RecurrencePreview, error) {
+	logger := middleware.GetLogger(ctx)
+
+	from := time.Now()
+	if payload.From != nil {
+		from = *payload.From
+	} else {
+		prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch preferences for recurrence preview")
+			return nil, err
+		}
+		from = from.In(prefs.Location())
+	}
+
+	occurrences, err := todo.PreviewRecurrence(payload.Rule, from, *payload.Count)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid recurrence rule")
+		return nil, err
+	}
+
+	return &todo.RecurrencePreview{Occurrences: occurrences}, nil
+}
+
+// ValidateRecurrence checks a proposed rule without saving anything, so
+// clients can show the problems or a summary before the todo is submitted
+func (s *TodoService) ValidateRecurrence(ctx echo.Context,
+	payload *todo.ValidateRecurrencePayload,
+) (*todo.RecurrenceValidation, error) {
+	logger := middleware.GetLogger(ctx)
+
+	result := todo.CheckRecurrence(payload.Rule)
+	if !result.Valid {
+		logger.Debug().Interface("errors", result.Errors).Msg("recurrence rule failed validation")
+	}
+
+	return &result, nil
+}
+
+func (s *TodoService) UpdateTodo(ctx echo.Context, userID string, payload *todo.UpdateTodoPayload) (*todo.TodoWithWarnings, error) {
+	return s.updateTodo(ctx, userID, nil, payload)
+}
+
+// UpdateOrgTodo updates an organization todo, whoever created it. Viewers
+// can read organization todos but not change them.
+func (s *TodoService) UpdateOrgTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	payload *todo.UpdateTodoPayload,
+) (*todo.TodoWithWarnings, error) {
+	if !membership.Role.CanWrite() {
+		return nil, errs.NewForbiddenError("Viewers cannot change organization todos", false)
+	}
+	return s.updateTodo(ctx, userID, membership, payload)
+}
+
+// checkScopedTodo fetches a todo of the membership's organization or, without
+// one, of the user's personal todos
+func (s *TodoService) checkScopedTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	todoID uuid.UUID,
+) (*todo.Todo, error) {
+	if membership != nil {
+		return s.todoRepo.CheckOrgTodoExists(ctx.Request().Context(), membership.OrgID, todoID)
+	}
+	return s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+}
+
+func (s *TodoService) updateTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	payload *todo.UpdateTodoPayload,
+) (*todo.TodoWithWarnings, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// The parent must be in the same scope as the todo, so a todo never moves
+	// between an organization and someone's personal todos
+	if payload.ParentTodoID != nil {
+		parentTodo, err := s.checkScopedTodo(ctx, userID, membership, *payload.ParentTodoID)
+		if err != nil {
+			logger.Error().Err(err).Msg("parent todo validation failed")
+			return nil, err
+		}
+
+		if parentTodo.ID == payload.ID {
+			err := errs.NewBadRequestError("Todo cannot be its own parent", false, nil, nil, nil)
+			logger.Warn().Msg("todo cannot be its own parent")
+			return nil, err
+		}
+
+		if !parentTodo.CanHaveChildren() {
+			err := errs.NewBadRequestError("Parent todo cannot have children (subtasks can't have subtasks)", false, nil, nil, nil)
+			logger.Warn().Msg("parent todo cannot have children")
+			return nil, err
+		}
+
+		// A todo already under this parent keeps its place
+		if err := s.checkChildLimit(ctx, parentTodo.ID, []uuid.UUID{payload.ID}); err != nil {
+			return nil, err
+		}
+
+		logger.Debug().Msg("parent todo validation passed")
+	}
+
+	// Validate category exists and belongs to user (if provided)
+	if payload.CategoryID != nil {
+		_, err := s.categoryRepo.GetCategoryByID(ctx.Request().Context(), userID, *payload.CategoryID)
+		if err != nil {
+			logger.Error().Err(err).Msg("category validation failed")
+			return nil, err
+		}
+
+		logger.Debug().Msg("category validation passed")
+	}
+
+	existing, err := s.checkScopedTodo(ctx, userID, membership, payload.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo for update")
+		return nil, err
+	}
+
+	if payload.Status != nil {
+		if err := s.checkStatusTransition(*payload.Status, *existing); err != nil {
+			logger.Warn().Err(err).Msg("status transition not allowed")
+			return nil, err
+		}
+	}
+
+	var updatedTodo *todo.Todo
+	if membership != nil {
+		updatedTodo, err = s.todoRepo.UpdateOrgTodo(ctx.Request().Context(), membership.OrgID, payload)
+	} else {
+		updatedTodo, err = s.todoRepo.UpdateTodo(ctx.Request().Context(), userID, payload)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update todo")
+		return nil, err
+	}
+
+	// Undo reverts through the personal todo routes, so it's only offered
+	// for personal todos
+	if changes := activity.Diff(activity.SnapshotTodo(existing), activity.SnapshotTodo(updatedTodo)); len(changes) > 0 {
+		entry := s.recordActivity(ctx, userID, updatedTodo.ID, activity.ActionFor(changes), changes)
+		if membership == nil {
+			s.offerUndo(ctx, userID, entry)
+		}
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_updated").
+		Str("todo_id", updatedTodo.ID.String()).
+		Str("title", updatedTodo.Title).
+		Str("category_id", func() string {
+			if updatedTodo.CategoryID != nil {
+				return updatedTodo.CategoryID.String()
+			}
+			return ""
+		}()).
+		Str("priority", string(updatedTodo.Priority)).
+		Str("status", string(updatedTodo.Status)).
+		Msg("Todo updated successfully")
+
+	warnings := []todo.Warning{}
+	if payload.Title != nil || payload.CategoryID != nil {
+		warnings = s.duplicateTitleWarnings(ctx, userID, updatedTodo)
+	}
+
+	return &todo.TodoWithWarnings{
+		Todo:     *updatedTodo,
+		Warnings: warnings,
+	}, nil
+}
+
+// duplicateTitleWarnings flags another todo in the same category with a near
+// identical title. Lookup failures are logged and never fail the write.
+func (s *TodoService) duplicateTitleWarnings(ctx echo.Context, userID string, todoItem *todo.Todo) []todo.Warning {
+	warnings := []todo.Warning{}
+	if todoItem.CategoryID == nil {
+		return warnings
+	}
+
+	logger := middleware.GetLogger(ctx)
+
+	similar, err := s.todoRepo.FindSimilarTitleInCategory(ctx.Request().Context(), userID, *todoItem.CategoryID,
+		todoItem.Title, &todoItem.ID, s.server.Config.Todo.GetDuplicateTitleThreshold())
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to check for duplicate todo titles")
+		return warnings
+	}
+
+	if similar != nil {
+		warnings = append(warnings, todo.Warning{
+			Code:    todo.WarningDuplicateTitle,
+			Message: fmt.Sprintf("A similar todo %q already exists in this category", similar.Title),
+			TodoID:  &similar.ID,
+		})
+	}
+
+	return warnings
+}
+
+func (s *TodoService) DeleteTodo(ctx echo.Context, userID string, todoID uuid.UUID) error {
+	return s.deleteTodo(ctx, userID, nil, todoID)
+}
+
+// DeleteOrgTodo moves an organization todo to the trash, whoever created it.
+// Viewers can't delete organization todos.
+func (s *TodoService) DeleteOrgTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	todoID uuid.UUID,
+) error {
+	if !membership.Role.CanWrite() {
+		return errs.NewForbiddenError("Viewers cannot delete organization todos", false)
+	}
+	return s.deleteTodo(ctx, userID, membership, todoID)
+}
+
+func (s *TodoService) deleteTodo(ctx echo.Context, userID string, membership *organization.Membership,
+	todoID uuid.UUID,
+) error {
+	logger := middleware.GetLogger(ctx)
+
+	existing, err := s.checkScopedTodo(ctx, userID, membership, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo for delete")
+		return err
+	}
+
+	if membership != nil {
+		err = s.todoRepo.DeleteOrgTodo(ctx.Request().Context(), membership.OrgID, todoID)
+	} else {
+		err = s.todoRepo.DeleteTodo(ctx.Request().Context(), userID, todoID)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to delete todo")
+		return err
+	}
+
+	entry := s.recordActivity(ctx, userID, todoID, activity.ActionDeleted,
+		activity.Diff(activity.SnapshotTodo(existing), nil))
+	if membership == nil {
+		s.offerUndo(ctx, userID, entry)
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_deleted").
+		Str("todo_id", todoID.String()).
+		Msg("Todo deleted successfully")
+
+	return nil
+}
+
+func (s *TodoService) GetTrash(ctx echo.Context, userID string,
+	query *todo.GetTrashQuery,
+) (*model.PaginatedResponse[todo.Todo], error) {
+	logger := middleware.GetLogger(ctx)
+
+	result, err := s.todoRepo.GetTrash(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch trash")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TodoService) RestoreTodo(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	restored, err := s.todoRepo.RestoreTodo(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to restore todo")
+		return nil, err
+	}
+
+	s.recordActivity(ctx, userID, todoID, activity.ActionRestored,
+		activity.Diff(nil, activity.SnapshotTodo(restored)))
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_restored").
+		Str("todo_id", todoID.String()).
+		Msg("Todo restored from trash")
+
+	return restored, nil
+}
+
+// UndoTodo reverts the change an undo token was issued for: a deleted todo is
+// restored from the trash and an edit puts the changed fields back. Only the
+// todo's latest change can be undone, and only until the token expires.
+func (s *TodoService) UndoTodo(ctx echo.Context, userID string, payload *todo.UndoTodoPayload) (*todo.Todo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	invalidCode := errs.CodeInvalidUndoToken
+	claims, err := token.Verify(s.server.Config.Auth.SecretKey, payload.Token, token.PurposeUndo)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid undo token")
+		if errors.Is(err, token.ErrExpiredToken) {
+			code := errs.CodeUndoExpired
+			return nil, errs.NewBadRequestError("The undo window has passed", false, &code, nil, nil)
+		}
+		return nil, errs.NewBadRequestError("Invalid undo token", false, &invalidCode, nil, nil)
+	}
+
+	activityID, err := uuid.Parse(claims.ID)
+	if err != nil || claims.Subject != userID {
+		logger.Warn().Msg("undo token was not issued for this user")
+		return nil, errs.NewBadRequestError("Invalid undo token", false, &invalidCode, nil, nil)
+	}
+
+	entry, err := s.activityRepo.GetActivityByID(ctx.Request().Context(), userID, payload.ID, activityID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch activity to undo")
+		return nil, err
+	}
+
+	if !entry.Undoable() {
+		return nil, errs.NewBadRequestError("Invalid undo token", false, &invalidCode, nil, nil)
+	}
+
+	later, err := s.activityRepo.GetActivitiesAfter(ctx.Request().Context(), userID, payload.ID, entry.Seq)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch later todo activity")
+		return nil, err
+	}
+
+	if len(later) > 0 {
+		code := errs.CodeUndoSuperseded
+		return nil, errs.NewBadRequestError("The todo has changed since; only its latest change can be undone",
+			false, &code, nil, nil)
+	}
+
+	var undone *todo.Todo
+	if entry.Action == activity.ActionDeleted {
+		undone, err = s.RestoreTodo(ctx, userID, payload.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		existing, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, payload.ID)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch todo to undo")
+			return nil, err
+		}
+
+		// Bulk and background changes can leave the todo different from
+		// what the entry recorded without a later entry to show for it
+		if !entry.Changes.Applied(activity.SnapshotTodo(existing)) {
+			code := errs.CodeUndoSuperseded
+			return nil, errs.NewBadRequestError("The todo has changed since; only its latest change can be undone",
+				false, &code, nil, nil)
+		}
+
+		undone, err = s.todoRepo.RevertTodo(ctx.Request().Context(), userID, payload.ID, entry.Changes.Previous())
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to revert todo")
+			return nil, err
+		}
+
+		s.recordUpdate(ctx, userID, existing, undone)
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_undone").
+		Str("todo_id", payload.ID.String()).
+		Str("activity_id", entry.ID.String()).
+		Str("action", string(entry.Action)).
+		Msg("Todo change undone successfully")
+
+	return undone, nil
+}
+
+// PurgeTodo permanently deletes a todo in the trash and its subtasks. Their
+// stored attachments are removed once the rows are gone; an object that
+// can't be removed is logged and left behind.
+func (s *TodoService) PurgeTodo(ctx echo.Context, userID string, todoID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	keys, err := s.todoRepo.PurgeTodo(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to purge todo")
+		return err
+	}
+
+	for _, key := range keys {
+		err := s.awsClient.S3.DeleteObject(ctx.Request().Context(), s.server.Config.AWS.UploadBucket, key)
+		if err != nil {
+			logger.Error().Err(err).Str("s3_key", key).Msg("failed to delete attachment of purged todo from S3")
+		}
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_purged").
+		Str("todo_id", todoID.String()).
+		Int("attachment_count", len(keys)).
+		Msg("Todo purged from trash")
+
+	return nil
+}
+
+// bulkTodos dedupes a bulk request's todo IDs, refuses lists over the
+// configured cap and fetches the todos in batches, rejecting the request if
+// any of them isn't the user's. Checking up front means a missing todo is
+// reported before any batch is written.
+func (s *TodoService) bulkTodos(ctx echo.Context, userID string, todoIDs []uuid.UUID) ([]uuid.UUID, []todo.Todo, error) {
+	seen := make(map[uuid.UUID]bool, len(todoIDs))
+	unique := make([]uuid.UUID, 0, len(todoIDs))
+	for _, id := range todoIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if maxIDs := s.server.Config.Todo.GetBulkMaxIDs(); len(unique) > maxIDs {
+		code := errs.CodeTooManyTodos
+		return nil, nil, errs.NewBadRequestError(
+			fmt.Sprintf("At most %d todos can be changed at once, got %d", maxIDs, len(unique)),
+			true, &code,
+			[]errs.FieldError{{Field: "todoIds", Error: fmt.Sprintf("must not list more than %d todos", maxIDs)}},
+			nil,
+		)
+	}
+
+	existing := make([]todo.Todo, 0, len(unique))
+	for _, batch := range todo.BatchIDs(unique, s.server.Config.Todo.GetBulkBatchSize()) {
+		found, err := s.todoRepo.GetTodosByIDs(ctx.Request().Context(), userID, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		existing = append(existing, found...)
+	}
+
+	if len(existing) != len(unique) {
+		code := errs.CodeTodoNotFound
+		return nil, nil, errs.NewNotFoundError(
+			fmt.Sprintf("%d of %d todos not found", len(unique)-len(existing), len(unique)), true, &code)
+	}
+
+	return unique, existing, nil
+}
+
+// inBatches applies a bulk change to todoIDs one configured batch at a time,
+// each in its own transaction, so a long list never locks every todo at
+// once. Batches written before a failure stay written; the returned count
+// covers them.
+func (s *TodoService) inBatches(todoIDs []uuid.UUID, apply func(batch []uuid.UUID) (int, error)) (int, error) {
+	total := 0
+	for _, batch := range todo.BatchIDs(todoIDs, s.server.Config.Todo.GetBulkBatchSize()) {
+		n, err := apply(batch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// recordBulkActivity logs the change made to each of the given todos
+func (s *TodoService) recordBulkActivity(ctx echo.Context, userID string, byID map[uuid.UUID]todo.Todo,
+	batch []uuid.UUID, change func(t *todo.Todo),
+) {
+	for _, id := range batch {
+		item, ok := byID[id]
+		if !ok {
+			continue
+		}
+
+		before := activity.SnapshotTodo(&item)
+		change(&item)
+		if changes := activity.Diff(before, activity.SnapshotTodo(&item)); len(changes) > 0 {
+			s.recordActivity(ctx, userID, item.ID, activity.ActionFor(changes), changes)
+		}
+	}
+}
+
+func todosByID(todos []todo.Todo) map[uuid.UUID]todo.Todo {
+	byID := make(map[uuid.UUID]todo.Todo, len(todos))
+	for _, item := range todos {
+		byID[item.ID] = item
+	}
+	return byID
+}
+
+// BulkReparent moves the listed todos in a single transaction rather than in
+// batches, since the cycle and depth checks have to see the whole move
+func (s *TodoService) BulkReparent(ctx echo.Context, userID string,
+	payload *todo.BulkReparentPayload,
+) (*todo.BulkUpdateResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Fetched up front so each move can be recorded in the activity log
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, payload.TodoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk reparent")
+		return nil, err
+	}
+
+	updated, err := s.todoRepo.BulkReparent(ctx.Request().Context(), userID, todoIDs, payload.ParentTodoID,
+		s.server.Config.Todo.GetMaxChildren())
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to bulk reparent todos")
+		return nil, err
+	}
+
+	s.recordBulkActivity(ctx, userID, todosByID(existing), todoIDs, func(t *todo.Todo) {
+		t.ParentTodoID = payload.ParentTodoID
+	})
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_reparented").
+		Int("count", updated).
+		Str("parent_todo_id", func() string {
+			if payload.ParentTodoID != nil {
+				return payload.ParentTodoID.String()
+			}
+			return ""
+		}()).
+		Msg("Todos reparented successfully")
+
+	return &todo.BulkUpdateResult{Updated: updated}, nil
+}
+
+func (s *TodoService) BulkUpdateStatus(ctx echo.Context, userID string,
+	payload *todo.BulkUpdateStatusPayload,
+) (*todo.BulkUpdateResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, payload.TodoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk status update")
+		return nil, err
+	}
+
+	if err := s.checkStatusTransition(payload.Status, existing...); err != nil {
+		logger.Warn().Err(err).Msg("bulk status transition not allowed")
+		return nil, err
+	}
+
+	byID := todosByID(existing)
+	updated, err := s.inBatches(todoIDs, func(batch []uuid.UUID) (int, error) {
+		n, err := s.todoRepo.BulkUpdateStatus(ctx.Request().Context(), userID, batch, payload.Status)
+		if err != nil {
+			return 0, err
+		}
+		s.recordBulkActivity(ctx, userID, byID, batch, func(t *todo.Todo) {
+			t.Status = payload.Status
+		})
+		return n, nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Int("updated", updated).Msg("failed to bulk update todo status")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_status_updated").
+		Int("count", updated).
+		Str("status", string(payload.Status)).
+		Msg("Todo statuses updated successfully")
+
+	return &todo.BulkUpdateResult{Updated: updated}, nil
+}
+
+func (s *TodoService) BulkSetPriority(ctx echo.Context, userID string,
+	payload *todo.BulkSetPriorityPayload,
+) (*todo.BulkUpdateResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, payload.TodoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk priority update")
+		return nil, err
+	}
+
+	byID := todosByID(existing)
+	updated, err := s.inBatches(todoIDs, func(batch []uuid.UUID) (int, error) {
+		n, err := s.todoRepo.BulkSetPriority(ctx.Request().Context(), userID, batch, payload.Priority)
+		if err != nil {
+			return 0, err
+		}
+		s.recordBulkActivity(ctx, userID, byID, batch, func(t *todo.Todo) {
+			t.Priority = payload.Priority
+		})
+		return n, nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Int("updated", updated).Msg("failed to bulk update todo priority")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_priority_updated").
+		Int("count", updated).
+		Str("priority", string(payload.Priority)).
+		Msg("Todo priorities updated successfully")
+
+	return &todo.BulkUpdateResult{Updated: updated}, nil
+}
+
+// BulkReopen returns the listed todos to active, clearing their completion
+// time and optionally moving their due date
+func (s *TodoService) BulkReopen(ctx echo.Context, userID string,
+	payload *todo.BulkReopenPayload,
+) (*todo.BulkUpdateResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, payload.TodoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk reopen")
+		return nil, err
+	}
+
+	if err := s.checkStatusTransition(todo.StatusActive, existing...); err != nil {
+		logger.Warn().Err(err).Msg("bulk reopen transition not allowed")
+		return nil, err
+	}
+
+	byID := todosByID(existing)
+	updated, err := s.inBatches(todoIDs, func(batch []uuid.UUID) (int, error) {
+		n, err := s.todoRepo.BulkReopen(ctx.Request().Context(), userID, batch, payload.DueDate)
+		if err != nil {
+			return 0, err
+		}
+		s.recordBulkActivity(ctx, userID, byID, batch, func(t *todo.Todo) {
+			t.Status = todo.StatusActive
+			t.CompletedAt = nil
+			if payload.DueDate != nil {
+				t.DueDate = payload.DueDate
+			}
+		})
+		return n, nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Int("updated", updated).Msg("failed to bulk reopen todos")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_reopened").
+		Int("count", updated).
+		Bool("due_date_reset", payload.DueDate != nil).
+		Msg("Todos reopened successfully")
+
+	return &todo.BulkUpdateResult{Updated: updated}, nil
+}
+
+// BulkDelete moves the listed todos and their subtasks to the trash in a
+// single transaction rather than in batches, so a request either trashes
+// everything it can find or nothing. Missing todos are reported per ID.
+func (s *TodoService) BulkDelete(ctx echo.Context, userID string,
+	payload *todo.BulkDeletePayload,
+) (*todo.BulkDeleteResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	seen := make(map[uuid.UUID]bool, len(payload.TodoIDs))
+	todoIDs := make([]uuid.UUID, 0, len(payload.TodoIDs))
+	for _, id := range payload.TodoIDs {
+		if !seen[id] {
+			seen[id] = true
+			todoIDs = append(todoIDs, id)
+		}
+	}
+
+	if maxIDs := s.server.Config.Todo.GetBulkMaxIDs(); len(todoIDs) > maxIDs {
+		code := errs.CodeTooManyTodos
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("At most %d todos can be deleted at once, got %d", maxIDs, len(todoIDs)),
+			true, &code,
+			[]errs.FieldError{{Field: "todoIds", Error: fmt.Sprintf("must not list more than %d todos", maxIDs)}},
+			nil,
+		)
+	}
+
+	deleted, err := s.todoRepo.BulkDeleteTodos(ctx.Request().Context(), userID, todoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to bulk delete todos")
+		return nil, err
+	}
+
+	byID := todosByID(deleted)
+	result := &todo.BulkDeleteResult{Results: make([]todo.BulkDeleteItem, 0, len(todoIDs))}
+	for _, id := range todoIDs {
+		item, ok := byID[id]
+		if !ok {
+			result.NotFound++
+			result.Results = append(result.Results, todo.BulkDeleteItem{ID: id, Outcome: todo.BulkDeleteOutcomeNotFound})
+			continue
+		}
+
+		result.Deleted++
+		result.Results = append(result.Results, todo.BulkDeleteItem{ID: id, Outcome: todo.BulkDeleteOutcomeDeleted})
+		s.recordActivity(ctx, userID, id, activity.ActionDeleted,
+			activity.Diff(activity.SnapshotTodo(&item), nil))
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_deleted").
+		Int("count", result.Deleted).
+		Int("not_found", result.NotFound).
+		Msg("Todos deleted successfully")
+
+	return result, nil
+}
+
+// BulkArchive archives the listed todos, skipping ones already archived
+func (s *TodoService) BulkArchive(ctx echo.Context, userID string,
+	payload *todo.BulkArchivePayload,
+) (*todo.BulkArchiveResult, error) {
+	return s.bulkMoveStatus(ctx, userID, payload.TodoIDs, todo.StatusArchived)
+}
+
+// BulkUnarchive restores the listed archived todos to active, skipping ones
+// that aren't archived. Todos don't remember the status they were archived
+// from, so every restored todo comes back as active.
+func (s *TodoService) BulkUnarchive(ctx echo.Context, userID string,
+	payload *todo.BulkArchivePayload,
+) (*todo.BulkArchiveResult, error) {
+	return s.bulkMoveStatus(ctx, userID, payload.TodoIDs, todo.StatusActive)
+}
+
+func (s *TodoService) bulkMoveStatus(ctx echo.Context, userID string, todoIDs []uuid.UUID,
+	status todo.Status,
+) (*todo.BulkArchiveResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, todoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk archive")
+		return nil, err
+	}
+
+	// Only the todos that actually move are held to the transition rules
+	var moving []todo.Todo
+	for _, item := range existing {
+		if (status == todo.StatusArchived) != (item.Status == todo.StatusArchived) {
+			moving = append(moving, item)
+		}
+	}
+
+	if err := s.checkStatusTransition(status, moving...); err != nil {
+		logger.Warn().Err(err).Msg("bulk archive transition not allowed")
+		return nil, err
+	}
+
+	movingByID := todosByID(moving)
+	updated, err := s.inBatches(todoIDs, func(batch []uuid.UUID) (int, error) {
+		var n int
+		var err error
+		if status == todo.StatusArchived {
+			n, err = s.todoRepo.BulkArchive(ctx.Request().Context(), userID, batch)
+		} else {
+			n, err = s.todoRepo.BulkUnarchive(ctx.Request().Context(), userID, batch)
+		}
+		if err != nil {
+			return 0, err
+		}
+		s.recordBulkActivity(ctx, userID, movingByID, batch, func(t *todo.Todo) {
+			t.Status = status
+		})
+		return n, nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Int("updated", updated).Msg("failed to bulk archive todos")
+		return nil, err
+	}
+
+	event := "todos_archived"
+	if status != todo.StatusArchived {
+		event = "todos_unarchived"
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", event).
+		Int("count", updated).
+		Int("skipped", len(existing)-updated).
+		Msg("Todos archive state updated successfully")
+
+	return &todo.BulkArchiveResult{
+		Updated: updated,
+		Skipped: len(existing) - updated,
+	}, nil
+}
+
+// BulkByFilter applies a bulk operation to every todo matching the filter,
+// resolved on the server. The matches are capped at the bulk ID limit; a
+// filter matching more is rejected rather than applied to an arbitrary part.
+func (s *TodoService) BulkByFilter(ctx echo.Context, userID string,
+	payload *todo.BulkByFilterPayload,
+) (*todo.BulkByFilterResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	maxIDs := s.server.Config.Todo.GetBulkMaxIDs()
+	todoIDs, err := s.todoRepo.GetMatchingTodoIDs(ctx.Request().Context(), userID, payload.Filter.Query(), maxIDs+1)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to resolve todos matching bulk filter")
+		return nil, err
+	}
+
+	if len(todoIDs) > maxIDs {
+		code := errs.CodeTooManyTodos
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("More than %d todos match the filter, narrow it down", maxIDs),
+			true, &code,
+			[]errs.FieldError{{Field: "filter", Error: fmt.Sprintf("must not match more than %d todos", maxIDs)}},
+			nil,
+		)
+	}
+
+	result := &todo.BulkByFilterResult{Matched: len(todoIDs)}
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	switch payload.Operation {
+	case todo.BulkOperationComplete:
+		updated, err := s.BulkUpdateStatus(ctx, userID, &todo.BulkUpdateStatusPayload{
+			TodoIDs: todoIDs,
+			Status:  todo.StatusCompleted,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Updated = updated.Updated
+	case todo.BulkOperationArchive:
+		updated, err := s.BulkArchive(ctx, userID, &todo.BulkArchivePayload{TodoIDs: todoIDs})
+		if err != nil {
+			return nil, err
+		}
+		result.Updated = updated.Updated
+	case todo.BulkOperationTag:
+		updated, err := s.bulkAddTag(ctx, userID, todoIDs, *payload.Tag)
+		if err != nil {
+			return nil, err
+		}
+		result.Updated = updated
+	}
+
+	return result, nil
+}
+
+// bulkAddTag tags the listed todos in batches, skipping ones that already
+// carry the tag
+func (s *TodoService) bulkAddTag(ctx echo.Context, userID string, todoIDs []uuid.UUID, tag string) (int, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoIDs, existing, err := s.bulkTodos(ctx, userID, todoIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todos for bulk tag")
+		return 0, err
+	}
+
+	byID := todosByID(existing)
+	updated, err := s.inBatches(todoIDs, func(batch []uuid.UUID) (int, error) {
+		n, err := s.todoRepo.BulkAddTag(ctx.Request().Context(), userID, batch, tag)
+		if err != nil {
+			return 0, err
+		}
+		s.recordBulkActivity(ctx, userID, byID, batch, func(t *todo.Todo) {
+			metadata := todo.Metadata{}
+			if t.Metadata != nil {
+				metadata = *t.Metadata
+			}
+			metadata.Tags = todo.NormalizeTags(append(append([]string{}, metadata.Tags...), tag))
+			t.Metadata = &metadata
+		})
+		return n, nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Int("updated", updated).Msg("failed to bulk tag todos")
+		return 0, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todos_tagged").
+		Int("count", updated).
+		Str("tag", tag).
+		Msg("Todos tagged successfully")
+
+	return updated, nil
+}
+
+// GetTodoReminders lists the reminders the cron jobs have yet to send for
+// the todo, including ones the user cancelled
+func (s *TodoService) GetTodoReminders(ctx echo.Context, userID string, todoID uuid.UUID) ([]todo.Reminder, error) {
+	logger := middleware.GetLogger(ctx)
+
+	_, reminders, err := s.scheduledReminders(ctx, userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo reminders")
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// CancelTodoReminder stops one pending reminder from being sent. Moving the
+// todo's due date schedules it again.
+func (s *TodoService) CancelTodoReminder(ctx echo.Context, userID string, todoID uuid.UUID,
+	kind todo.ReminderKind,
+) error {
+	logger := middleware.GetLogger(ctx)
+
+	todoItem, reminders, err := s.scheduledReminders(ctx, userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo reminders")
+		return err
+	}
+
+	scheduled := false
+	for _, reminder := range reminders {
+		scheduled = scheduled || reminder.ID == kind
+	}
+	if !scheduled {
+		code := errs.CodeReminderNotFound
+		return errs.NewNotFoundError("reminder not scheduled", false, &code)
+	}
+
+	if err := s.todoRepo.CancelReminder(ctx.Request().Context(), todoID, kind, *todoItem.DueDate); err != nil {
+		logger.Error().Err(err).Msg("failed to cancel todo reminder")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_reminder_cancelled").
+		Str("todo_id", todoID.String()).
+		Str("reminder", string(kind)).
+		Msg("Todo reminder cancelled successfully")
+
+	return nil
+}
+
+func (s *TodoService) scheduledReminders(ctx echo.Context, userID string,
+	todoID uuid.UUID,
+) (*todo.PopulatedTodo, []todo.Reminder, error) {
+	reqCtx := ctx.Request().Context()
+
+	todoItem, err := s.todoRepo.GetTodoByID(reqCtx, userID, todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefs, err := s.preferenceRepo.GetPreferences(reqCtx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancelled, err := s.todoRepo.GetReminderCancellations(reqCtx, todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reminders := todo.ScheduledReminders(&todoItem.Todo, time.Now(), prefs.Location(), prefs.DayStartHour,
+		s.server.Config.Cron.ReminderHours, cancelled)
+
+	return todoItem, reminders, nil
+}
+
+// SnoozeOverdue pushes every overdue todo to the requested time, defaulting
+// to 9am tomorrow in the user's timezone
+func (s *TodoService) SnoozeOverdue(ctx echo.Context, userID string,
+	payload *todo.SnoozeOverduePayload,
+) (*todo.SnoozeOverdueResult, error) {
+	logger := middleware.GetLogger(ctx)
+
+	now := time.Now()
+	var dueDate time.Time
+	if payload.DueDate != nil {
+		dueDate = *payload.DueDate
+	} else {
+		prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch preferences for snoozing overdue todos")
+			return nil, err
+		}
+		dueDate = todo.DefaultSnoozeUntil(prefs.DayClock(now), prefs.Location())
+	}
+
+	// Snoozing into the past would leave the todos overdue
+	if !dueDate.After(now) {
+		return nil, errs.NewBadRequestError("dueDate must be in the future", true, nil,
+			[]errs.FieldError{{Field: "dueDate", Error: "must be in the future"}}, nil)
+	}
+
+	snoozed, err := s.todoRepo.SnoozeOverdue(ctx.Request().Context(), userID, dueDate)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to snooze overdue todos")
+		return nil, err
+	}
+
+	for i := range snoozed {
+		changed := snoozed[i].Todo
+		before := changed
+		before.DueDate = snoozed[i].PreviousDueDate
+		if changes := activity.Diff(activity.SnapshotTodo(&before), activity.SnapshotTodo(&changed)); len(changes) > 0 {
+			s.recordActivity(ctx, userID, changed.ID, activity.ActionFor(changes), changes)
+		}
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "overdue_todos_snoozed").
+		Int("count", len(snoozed)).
+		Time("due_date", dueDate).
+		Msg("Overdue todos snoozed successfully")
+
+	return &todo.SnoozeOverdueResult{
+		Updated: len(snoozed),
+		DueDate: dueDate,
+	}, nil
+}
+
+func (s *TodoService) GetTodoStats(ctx echo.Context, userID string) (*todo.TodoStats, error) {
+	logger := middleware.GetLogger(ctx)
+
+	stats, err := s.todoRepo.GetTodoStats(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo statistics")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetFilteredTodoStats breaks down the todos matching the list filters by
+// status
+func (s *TodoService) GetFilteredTodoStats(ctx echo.Context, userID string,
+	query *todo.GetTodosQuery,
+) (*todo.TodoStats, error) {
+	logger := middleware.GetLogger(ctx)
+
+	stats, err := s.todoRepo.GetFilteredTodoStats(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch filtered todo statistics")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetCycleTimeStats reports how long the user's completed todos took. When
+// excluding outliers, todos slower than the configured cap are left out.
+func (s *TodoService) GetCycleTimeStats(ctx echo.Context, userID string,
+	excludeOutliers bool,
+) (*todo.CycleTimeStats, error) {
+	logger := middleware.GetLogger(ctx)
+
+	var maxCycleTime *time.Duration
+	if excludeOutliers {
+		outlierCap := s.server.Config.Todo.GetCycleTimeOutlierCap()
+		maxCycleTime = &outlierCap
+	}
+
+	stats, err := s.todoRepo.GetCycleTimeStats(ctx.Request().Context(), userID, maxCycleTime)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch cycle time statistics")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *TodoService) UploadTodoAttachment(
+	ctx echo.Context,
+	userID string,
+	todoID uuid.UUID,
+	file *multipart.FileHeader,
+) (*todo.TodoAttachment, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Verify todo exists and belongs to user
+	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
+		return nil, err
+	}
+
+	// Open uploaded file
+	src, err := file.Open()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to open uploaded file")
+		return nil, errs.NewBadRequestError("failed to open uploaded file", false, nil, nil, nil)
+	}
+	defer src.Close()
+
+	// Upload to S3
+	s3Key, err := s.awsClient.S3.UploadFile(
+		ctx.Request().Context(),
+		s.server.Config.AWS.UploadBucket,
+		todo.AttachmentKey(file.Filename),
+		src,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to upload file to S3")
+		return nil, errors.Wrap(err, "failed to upload file")
+	}
+
+	// Detect MIME type
+	src, err = file.Open()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to reopen file for MIME detection")
+		return nil, errs.NewBadRequestError("failed to process file", false, nil, nil, nil)
+	}
+	defer src.Close()
+
+	buffer := make([]byte, 512)
+	_, err = src.Read(buffer)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to read file for MIME detection")
+		return nil, errs.NewBadRequestError("failed to process file", false, nil, nil, nil)
+	}
+	mimeType := http.DetectContentType(buffer)
+
+	// Create attachment record
+	attachment, err := s.todoRepo.UploadTodoAttachment(
+		ctx.Request().Context(),
+		todoID,
+		userID,
+		s3Key,
+		file.Filename,
+		file.Size,
+		mimeType,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create attachment record")
+		return nil, err
+	}
+
+	logger.Info().
+		Str("attachment_id", attachment.ID.String()).
+		Str("s3_key", s3Key).
+		Msg("uploaded todo attachment")
+
+	// Transcription runs in the background; the upload succeeds regardless
+	if s.server.Config.Transcription.IsEnabled() {
+		if task := job.NewTranscribeAttachmentTask(attachment); task != nil {
+			task.RequestID = middleware.GetRequestID(ctx)
+			if err := job.EnqueueTranscribeAttachment(s.server.Job.Client, task); err != nil {
+				logger.Warn().Err(err).
+					Str("attachment_id", attachment.ID.String()).
+					Msg("failed to enqueue attachment transcription")
+			}
+		}
+	}
+
+	return attachment, nil
+}
+
+func (s *TodoService) DeleteTodoAttachment(
+	ctx echo.Context,
+	userID string,
+	todoID uuid.UUID,
+	attachmentID uuid.UUID,
+) error {
+	logger := middleware.GetLogger(ctx)
+
+	// Verify todo exists and belongs to user
+	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
+		return err
+	}
+
+	// Get attachment details for S3 deletion
+	attachment, err := s.todoRepo.GetTodoAttachment(
+		ctx.Request().Context(),
+		todoID,
+		attachmentID,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get attachment details")
+		return err
+	}
+
+	// Delete attachment record
+	err = s.todoRepo.DeleteTodoAttachment(
+		ctx.Request().Context(),
+		todoID,
+		attachmentID,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to delete attachment record")
+		return err
+	}
+
+	// Delete from S3 asynchronously
+	go func() {
+		err := s.awsClient.S3.DeleteObject(
+			ctx.Request().Context(),
+			s.server.Config.AWS.UploadBucket,
+			attachment.DownloadKey,
+		)
+		if err != nil {
+			s.server.Logger.Error().
+				Err(err).
+				Str("s3_key", attachment.DownloadKey).
+				Msg("failed to delete attachment from S3")
+		}
+	}()
+
+	logger.Info().Msg("deleted todo attachment")
+
+	return nil
+}
+
+func (s *TodoService) GetAttachmentPresignedURL(
+	ctx echo.Context,
+	userID string,
+	todoID uuid.UUID,
+	attachmentID uuid.UUID,
+) (string, error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Verify todo exists and belongs to user
+	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
+		return "", err
+	}
+
+	// Get attachment details
+	attachment, err := s.todoRepo.GetTodoAttachment(
+		ctx.Request().Context(),
+		todoID,
+		attachmentID,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get attachment details")
+		return "", err
+	}
+
+	// Generate presigned URL
+	url, err := s.awsClient.S3.CreatePresignedUrl(
+		ctx.Request().Context(),
+		s.server.Config.AWS.UploadBucket,
+		attachment.DownloadKey,
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to generate presigned URL")
+		return "", err
+	}
+
+	// A failed audit write is logged but doesn't hold back the download
+	entry := &admin.AttachmentAccessLog{
+		UserID:       userID,
+		TodoID:       todoID,
+		AttachmentID: attachmentID,
+	}
+	if ip := ctx.RealIP(); ip != "" {
+		entry.IP = &ip
+	}
+	if requestID := middleware.GetRequestID(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+	err = s.todoRepo.RecordAttachmentAccess(ctx.Request().Context(), entry)
+	if err != nil {
+		logger.Error().Err(err).
+			Str("attachment_id", attachmentID.String()).
+			Msg("failed to record attachment access")
+	}
+
+	return url, nil
+}
+
+const (
+	completedFeedWindow = 30 * 24 * time.Hour
+	completedFeedLimit  = 50
+	completedFeedPath   = "/api/v1/todos/feed/completed.atom"
+)
+
+// CreateFeedToken issues a token that authenticates feed readers for the
+// user's completed todos feed until it is revoked. Feed readers poll for as
+// long as they're subscribed, so the token doesn't expire; issuing a new one
+// rotates it, revoking the previous token.
+func (s *TodoService) CreateFeedToken(ctx echo.Context, userID string) (*todo.FeedToken, error) {
+	logger := middleware.GetLogger(ctx)
+
+	link, err := s.todoRepo.CreateFeedLink(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create feed link")
+		return nil, err
+	}
+
+	raw, err := token.Sign(s.server.Config.Auth.SecretKey, token.Claims{
+		Subject:  userID,
+		Purpose:  token.PurposeFeed,
+		ID:       link.ID.String(),
+		IssuedAt: link.CreatedAt.Unix(),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to sign feed token")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_feed_token_created").
+		Str("feed_link_id", link.ID.String()).
+		Msg("Todo feed token created")
+
+	return &todo.FeedToken{
+		Token: raw,
+		URL:   completedFeedPath + "?token=" + url.QueryEscape(raw),
+	}, nil
+}
+
+// RevokeFeedToken disables the user's feed token so feed readers using it
+// are refused
+func (s *TodoService) RevokeFeedToken(ctx echo.Context, userID string) error {
+	logger := middleware.GetLogger(ctx)
+
+	revoked, err := s.todoRepo.RevokeFeedLinks(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to revoke feed token")
+		return err
 	}
 
-	// Delete attachment record
-	err = s.todoRepo.DeleteTodoAttachment(
-		ctx.Request().Context(),
-		todoID,
-		attachmentID,
-	)
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_feed_token_revoked").
+		Int("revoked", revoked).
+		Msg("Todo feed token revoked")
+
+	return nil
+}
+
+// GetCompletedFeed renders an Atom feed of the todos completed in the recent
+// window for the user identified by the feed token.
+func (s *TodoService) GetCompletedFeed(ctx echo.Context, rawToken string) ([]byte, error) {
+	logger := middleware.GetLogger(ctx)
+
+	unauthorized := errs.NewUnauthorizedError("Invalid feed token", false)
+
+	claims, err := token.Verify(s.server.Config.Auth.SecretKey, rawToken, token.PurposeFeed)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete attachment record")
+		logger.Warn().Err(err).Msg("invalid feed token")
+		return nil, unauthorized
+	}
+
+	linkID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("feed token without a link id")
+		return nil, unauthorized
+	}
+
+	link, err := s.todoRepo.GetFeedLink(ctx.Request().Context(), claims.Subject, linkID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch feed link")
+		return nil, err
+	}
+	if link == nil {
+		logger.Warn().Msg("revoked feed token")
+		return nil, unauthorized
+	}
+
+	now := time.Now()
+	todos, err := s.todoRepo.GetRecentlyCompletedTodos(ctx.Request().Context(), claims.Subject,
+		now.Add(-completedFeedWindow), completedFeedLimit)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch recently completed todos")
+		return nil, err
+	}
+
+	atom := &feed.Atom{
+		ID:      "urn:tasker:feed:completed:" + claims.Subject,
+		Title:   "Completed todos",
+		Updated: feed.Time(now),
+		Author:  &feed.AtomPerson{Name: "Tasker"},
+		Links: []feed.AtomLink{
+			{Href: completedFeedPath, Rel: "self", Type: feed.AtomContentType},
+		},
+		Entries: make([]feed.AtomEntry, 0, len(todos)),
+	}
+
+	if len(todos) > 0 {
+		atom.Updated = feed.Time(*todos[0].CompletedAt)
+	}
+
+	for _, item := range todos {
+		entry := feed.AtomEntry{
+			ID:        "urn:uuid:" + item.ID.String(),
+			Title:     item.Title,
+			Updated:   feed.Time(*item.CompletedAt),
+			Published: feed.Time(*item.CompletedAt),
+		}
+		if item.Description != nil && *item.Description != "" {
+			entry.Summary = &feed.AtomText{Type: "text", Body: *item.Description}
+		}
+		atom.Entries = append(atom.Entries, entry)
+	}
+
+	body, err := atom.Render()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to render completed todos feed")
+		return nil, err
+	}
+
+	return body, nil
+}
+
+const (
+	sharedTodoPath         = "/api/v1/shared/todos/"
+	sharedAttachmentURLTTL = 5 * time.Minute
+)
+
+// CreateShareLink issues a token that lets anyone holding it view the todo
+// read-only. Each todo has one live link; issuing a new one revokes the old.
+func (s *TodoService) CreateShareLink(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.ShareLinkToken, error) {
+	logger := middleware.GetLogger(ctx)
+
+	link, err := s.todoRepo.CreateShareLink(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create share link")
+		return nil, err
+	}
+
+	raw, err := token.Sign(s.server.Config.Auth.SecretKey, token.Claims{
+		Subject:  todoID.String(),
+		Purpose:  token.PurposeShare,
+		ID:       link.ID.String(),
+		IssuedAt: link.CreatedAt.Unix(),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to sign share token")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_share_link_created").
+		Str("todo_id", todoID.String()).
+		Str("share_link_id", link.ID.String()).
+		Msg("Todo share link created")
+
+	return &todo.ShareLinkToken{
+		Token:  raw,
+		URL:    sharedTodoPath + url.PathEscape(raw),
+		TodoID: todoID,
+	}, nil
+}
+
+// RevokeShareLink disables the todo's share link so its token stops working
+func (s *TodoService) RevokeShareLink(ctx echo.Context, userID string, todoID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	if _, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID); err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
 		return err
 	}
 
-	// Delete from S3 asynchronously
-	go func() {
-		err := s.awsClient.S3.DeleteObject(
+	revoked, err := s.todoRepo.RevokeShareLinks(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to revoke share link")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_share_link_revoked").
+		Str("todo_id", todoID.String()).
+		Int("revoked", revoked).
+		Msg("Todo share link revoked")
+
+	return nil
+}
+
+// GetDependencies lists the todos the todo waits on and the todos waiting
+// on it
+func (s *TodoService) GetDependencies(ctx echo.Context, userID string, todoID uuid.UUID) (*todo.Dependencies, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if _, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID); err != nil {
+		logger.Error().Err(err).Msg("todo validation failed")
+		return nil, err
+	}
+
+	dependencies, err := s.todoRepo.GetDependencies(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo dependencies")
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+// AddDependency links the todo to another as blocked by it or blocking it,
+// refusing links that would make todos wait on each other
+func (s *TodoService) AddDependency(ctx echo.Context, userID string,
+	payload *todo.AddDependencyPayload,
+) (*todo.Dependencies, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoID, blockedByID := payload.Link()
+	if err := s.todoRepo.AddDependency(ctx.Request().Context(), userID, todoID, blockedByID); err != nil {
+		logger.Error().Err(err).Msg("failed to add todo dependency")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_dependency_added").
+		Str("todo_id", todoID.String()).
+		Str("blocked_by_id", blockedByID.String()).
+		Msg("Todo dependency added")
+
+	return s.GetDependencies(ctx, userID, payload.ID)
+}
+
+// RemoveDependency unlinks the todo from another, whichever way round they
+// were linked
+func (s *TodoService) RemoveDependency(ctx echo.Context, userID string, todoID, otherID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	if err := s.todoRepo.RemoveDependency(ctx.Request().Context(), userID, todoID, otherID); err != nil {
+		logger.Error().Err(err).Msg("failed to remove todo dependency")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "todo_dependency_removed").
+		Str("todo_id", todoID.String()).
+		Str("other_todo_id", otherID.String()).
+		Msg("Todo dependency removed")
+
+	return nil
+}
+
+// feedWriteMargin is how long before the server's write timeout a waiting
+// change feed request gives up, leaving time to write the response
+const feedWriteMargin = 5 * time.Second
+
+// GetChangeFeed returns the user's activity since the token. When there is
+// none it waits up to the query's wait for a change to be signalled, as a
+// long-poll alternative to streaming. The returned token always moves
+// forward, even when nothing changed.
+func (s *TodoService) GetChangeFeed(ctx echo.Context, userID string,
+	query *activity.GetChangeFeedQuery,
+) (*activity.ChangeFeed, error) {
+	logger := middleware.GetLogger(ctx)
+	reqCtx := ctx.Request().Context()
+
+	// Without a token the feed starts from now
+	if query.Token == nil {
+		return &activity.ChangeFeed{
+			Changes: []activity.Activity{},
+			Token:   activity.ChangeToken{}.Advance(time.Now()).String(),
+		}, nil
+	}
+
+	wait := query.WaitFor
+	if limit := time.Duration(s.server.Config.Server.WriteTimeout)*time.Second - feedWriteMargin; limit > 0 {
+		wait = min(wait, limit)
+	}
+
+	// Subscribe before reading so a change made in between still wakes the
+	// wait. Without a subscription the wait runs out and the log is read
+	// again, so changes arrive late rather than not at all.
+	var signals <-chan struct{}
+	if s.notifier != nil && wait > 0 {
+		subscribed, unsubscribe, err := s.notifier.Subscribe(reqCtx, userID)
+		if err != nil {
+			logger.Warn().Err(err).Msg("failed to subscribe to todo changes")
+		} else {
+			defer unsubscribe()
+			signals = subscribed
+		}
+	}
+
+	changes, err := s.activityRepo.GetActivitiesAfterToken(reqCtx, userID, *query.Token, activity.MaxFeedChanges)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch change feed")
+		return nil, err
+	}
+
+	if len(changes) == 0 && wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-signals:
+		case <-timer.C:
+		case <-reqCtx.Done():
+			return nil, reqCtx.Err()
+		}
+
+		changes, err = s.activityRepo.GetActivitiesAfterToken(reqCtx, userID, *query.Token, activity.MaxFeedChanges)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch change feed")
+			return nil, err
+		}
+	}
+
+	next := query.Token.Advance(time.Now())
+	if len(changes) > 0 {
+		next = activity.TokenFor(&changes[len(changes)-1])
+	}
+
+	return &activity.ChangeFeed{
+		Changes: changes,
+		Token:   next.String(),
+	}, nil
+}
+
+// GetSharedTodo returns the read-only view of the todo behind a share token.
+// Bad, revoked and unknown tokens all look like a missing todo.
+func (s *TodoService) GetSharedTodo(ctx echo.Context, rawToken string) (*todo.SharedTodo, error) {
+	logger := middleware.GetLogger(ctx)
+
+	notFound := errs.NewNotFoundError("Shared todo not found", false, nil)
+
+	claims, err := token.Verify(s.server.Config.Auth.SecretKey, rawToken, token.PurposeShare)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid share token")
+		return nil, notFound
+	}
+
+	linkID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("share token without a link id")
+		return nil, notFound
+	}
+
+	todoID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		logger.Warn().Err(err).Msg("share token without a todo id")
+		return nil, notFound
+	}
+
+	item, err := s.todoRepo.GetSharedTodo(ctx.Request().Context(), linkID, todoID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("shared todo not found")
+		return nil, err
+	}
+
+	downloadURLs := make([]*string, len(item.Attachments))
+	for i, attachment := range item.Attachments {
+		downloadURL, err := s.awsClient.S3.CreatePresignedUrlWithExpiry(
 			ctx.Request().Context(),
 			s.server.Config.AWS.UploadBucket,
 			attachment.DownloadKey,
+			sharedAttachmentURLTTL,
 		)
 		if err != nil {
-			s.server.Logger.Error().
-				Err(err).
-				Str("s3_key", attachment.DownloadKey).
-				Msg("failed to delete attachment from S3")
+			logger.Error().Err(err).Msg("failed to generate presigned URL for shared attachment")
+			return nil, err
 		}
-	}()
+		downloadURLs[i] = &downloadURL
+	}
 
-	logger.Info().Msg("deleted todo attachment")
+	shared := todo.NewSharedTodo(*item)
+	for i := range shared.Attachments {
+		shared.Attachments[i].DownloadURL = downloadURLs[i]
+	}
 
-	return nil
+	return shared, nil
 }
 
-func (s *TodoService) GetAttachmentPresignedURL(
-	ctx echo.Context,
-	userID string,
-	todoID uuid.UUID,
-	attachmentID uuid.UUID,
-) (string, error) {
+// GetTodoActivity pages through the todo's history, newest first. History is
+// kept after a todo is deleted, so it is read without checking the todo.
+func (s *TodoService) GetTodoActivity(ctx echo.Context, userID string,
+	query *activity.GetTodoActivityQuery,
+) (*model.PaginatedResponse[activity.Activity], error) {
 	logger := middleware.GetLogger(ctx)
 
-	// Verify todo exists and belongs to user
-	_, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	result, err := s.activityRepo.GetActivities(ctx.Request().Context(), userID, query.Query())
 	if err != nil {
-		logger.Error().Err(err).Msg("todo validation failed")
-		return "", err
+		logger.Error().Err(err).Msg("failed to fetch todo activity")
+		return nil, err
 	}
 
-	// Get attachment details
-	attachment, err := s.todoRepo.GetTodoAttachment(
-		ctx.Request().Context(),
-		todoID,
-		attachmentID,
-	)
+	return result, nil
+}
+
+// GetTodoDiff reconstructs the todo as it was right after the given activity
+// entry and compares it field by field with its current state.
+func (s *TodoService) GetTodoDiff(ctx echo.Context, userID string, todoID uuid.UUID,
+	fromActivityID uuid.UUID,
+) (*activity.TodoDiff, error) {
+	logger := middleware.GetLogger(ctx)
+
+	current, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to get attachment details")
-		return "", err
+		logger.Error().Err(err).Msg("failed to fetch todo for diff")
+		return nil, err
 	}
 
-	// Generate presigned URL
-	url, err := s.awsClient.S3.CreatePresignedUrl(
-		ctx.Request().Context(),
-		s.server.Config.AWS.UploadBucket,
-		attachment.DownloadKey,
-	)
+	from, err := s.activityRepo.GetActivityByID(ctx.Request().Context(), userID, todoID, fromActivityID)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to generate presigned URL")
-		return "", err
+		logger.Warn().Err(err).Msg("activity for diff not found")
+		return nil, err
 	}
 
-	return url, nil
+	newer, err := s.activityRepo.GetActivitiesAfter(ctx.Request().Context(), userID, todoID, from.Seq)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch activities for diff")
+		return nil, err
+	}
+
+	now := activity.SnapshotTodo(current)
+	then := activity.Reconstruct(now, newer)
+
+	return &activity.TodoDiff{
+		TodoID:       todoID,
+		FromActivity: from.ID,
+		AsOf:         from.CreatedAt,
+		Then:         then,
+		Changes:      activity.FieldDiffs(then, now),
+	}, nil
+}
+
+// GetChangesSince summarises what happened to the todo after the given time,
+// for someone returning to it
+func (s *TodoService) GetChangesSince(ctx echo.Context, userID string, todoID uuid.UUID,
+	since time.Time,
+) (*activity.ChangeSummary, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoItem, err := s.todoRepo.GetTodoByID(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo for changes since")
+		return nil, err
+	}
+
+	return s.summarizeChanges(ctx, todoItem, since)
+}
+
+// GetOrgChangesSince summarises changes to an organization todo, made by any
+// member
+func (s *TodoService) GetOrgChangesSince(ctx echo.Context, membership *organization.Membership,
+	todoID uuid.UUID, since time.Time,
+) (*activity.ChangeSummary, error) {
+	logger := middleware.GetLogger(ctx)
+
+	todoItem, err := s.todoRepo.GetOrgTodoByID(ctx.Request().Context(), membership.OrgID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch organization todo for changes since")
+		return nil, err
+	}
+
+	return s.summarizeChanges(ctx, todoItem, since)
+}
+
+func (s *TodoService) summarizeChanges(ctx echo.Context, todoItem *todo.PopulatedTodo,
+	since time.Time,
+) (*activity.ChangeSummary, error) {
+	logger := middleware.GetLogger(ctx)
+
+	entries, err := s.activityRepo.GetActivitiesSince(ctx.Request().Context(), todoItem.ID, since)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch activities for changes since")
+		return nil, err
+	}
+
+	return activity.Summarize(todoItem, since, entries), nil
+}
+
+// ExportTodoMarkdown renders the todo and its subtasks as a Markdown checklist
+func (s *TodoService) ExportTodoMarkdown(ctx echo.Context, userID string, todoID uuid.UUID) ([]byte, error) {
+	logger := middleware.GetLogger(ctx)
+
+	root, err := s.todoRepo.CheckTodoExists(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch todo for markdown export")
+		return nil, err
+	}
+
+	descendants, err := s.todoRepo.GetTodoSubtree(ctx.Request().Context(), userID, todoID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch subtree for markdown export")
+		return nil, err
+	}
+
+	prefs, err := s.preferenceRepo.GetPreferences(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch preferences for markdown export")
+		return nil, err
+	}
+
+	return todo.RenderMarkdown(root, descendants, prefs.Location()), nil
 }
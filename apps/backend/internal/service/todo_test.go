@@ -0,0 +1,584 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/config"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/lib/changefeed"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model/activity"
+	"github.com/sriniously/tasker/internal/model/admin"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/service"
+	testing_pkg "github.com/sriniously/tasker/internal/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoService_CreateTodoInbox(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, nil, nil, nil)
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	t.Run("first uncategorized todo lands in a new inbox", func(t *testing.T) {
+		userID := uuid.New().String()
+
+		created, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Loose end"})
+		require.NoError(t, err)
+		require.NotNil(t, created.CategoryID)
+
+		inbox, err := repos.Category.GetCategoryByID(newContext().Request().Context(), userID, *created.CategoryID)
+		require.NoError(t, err)
+		assert.True(t, inbox.IsInbox)
+
+		again, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Another"})
+		require.NoError(t, err)
+		assert.Equal(t, inbox.ID, *again.CategoryID)
+	})
+
+	t.Run("inbox is skipped when disabled", func(t *testing.T) {
+		disabled := false
+		testServer.Config.Todo = &config.TodoConfig{InboxEnabled: &disabled}
+		defer func() { testServer.Config.Todo = nil }()
+
+		created, err := todoService.CreateTodo(newContext(), uuid.New().String(),
+			&todo.CreateTodoPayload{Title: "Uncategorized"})
+		require.NoError(t, err)
+		assert.Nil(t, created.CategoryID)
+	})
+}
+
+func TestTodoService_BulkInBatches(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, nil, nil, nil)
+
+	testServer.Config.Todo = &config.TodoConfig{BulkBatchSize: 2, BulkMaxIDs: 6}
+	defer func() { testServer.Config.Todo = nil }()
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/todos/bulk/priority", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	userID := uuid.New().String()
+	todoIDs := make([]uuid.UUID, 0, 5)
+	for range 5 {
+		created, err := repos.Todo.CreateTodo(newContext().Request().Context(), userID,
+			&todo.CreateTodoPayload{Title: "Batch me"})
+		require.NoError(t, err)
+		todoIDs = append(todoIDs, created.ID)
+	}
+
+	t.Run("a list longer than the batch size is applied in full", func(t *testing.T) {
+		result, err := todoService.BulkSetPriority(newContext(), userID, &todo.BulkSetPriorityPayload{
+			TodoIDs:  todoIDs,
+			Priority: todo.PriorityHigh,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Updated)
+
+		updated, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID, todoIDs)
+		require.NoError(t, err)
+		for _, item := range updated {
+			assert.Equal(t, todo.PriorityHigh, item.Priority)
+		}
+
+		archived, err := todoService.BulkArchive(newContext(), userID, &todo.BulkArchivePayload{TodoIDs: todoIDs})
+		require.NoError(t, err)
+		assert.Equal(t, 5, archived.Updated)
+	})
+
+	t.Run("a missing todo rejects the request before any batch is written", func(t *testing.T) {
+		_, err := todoService.BulkSetPriority(newContext(), userID, &todo.BulkSetPriorityPayload{
+			TodoIDs:  append(append([]uuid.UUID{}, todoIDs...), uuid.New()),
+			Priority: todo.PriorityLow,
+		})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTodoNotFound, httpErr.Code)
+
+		unchanged, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID, todoIDs)
+		require.NoError(t, err)
+		for _, item := range unchanged {
+			assert.Equal(t, todo.PriorityHigh, item.Priority)
+		}
+	})
+
+	t.Run("more todos than the cap are rejected", func(t *testing.T) {
+		tooMany := make([]uuid.UUID, 7)
+		for i := range tooMany {
+			tooMany[i] = uuid.New()
+		}
+
+		_, err := todoService.BulkUpdateStatus(newContext(), userID, &todo.BulkUpdateStatusPayload{
+			TodoIDs: tooMany,
+			Status:  todo.StatusCompleted,
+		})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, errs.CodeTooManyTodos, httpErr.Code)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "todoIds", httpErr.Errors[0].Field)
+	})
+}
+
+func TestTodoService_BulkDelete(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, nil, nil, nil)
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/todos/bulk", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+	ctx := newContext().Request().Context()
+
+	userID := uuid.New().String()
+	create := func(t *testing.T, owner string, parentID *uuid.UUID) *todo.Todo {
+		t.Helper()
+		created, err := repos.Todo.CreateTodo(ctx, owner, &todo.CreateTodoPayload{
+			Title:        "Clear me",
+			ParentTodoID: parentID,
+		})
+		require.NoError(t, err)
+		return created
+	}
+
+	parent := create(t, userID, nil)
+	child := create(t, userID, &parent.ID)
+	loose := create(t, userID, nil)
+	kept := create(t, userID, nil)
+	someoneElses := create(t, uuid.New().String(), nil)
+	missing := uuid.New()
+
+	result, err := todoService.BulkDelete(newContext(), userID, &todo.BulkDeletePayload{
+		TodoIDs: []uuid.UUID{child.ID, parent.ID, loose.ID, someoneElses.ID, missing, loose.ID},
+	})
+	require.NoError(t, err)
+
+	t.Run("each listed todo is reported once, in order", func(t *testing.T) {
+		assert.Equal(t, 3, result.Deleted)
+		assert.Equal(t, 2, result.NotFound)
+		assert.Equal(t, []todo.BulkDeleteItem{
+			{ID: child.ID, Outcome: todo.BulkDeleteOutcomeDeleted},
+			{ID: parent.ID, Outcome: todo.BulkDeleteOutcomeDeleted},
+			{ID: loose.ID, Outcome: todo.BulkDeleteOutcomeDeleted},
+			{ID: someoneElses.ID, Outcome: todo.BulkDeleteOutcomeNotFound},
+			{ID: missing, Outcome: todo.BulkDeleteOutcomeNotFound},
+		}, result.Results)
+	})
+
+	t.Run("deleted todos go to the trash together", func(t *testing.T) {
+		page, limit := 1, 20
+		trash, err := todoService.GetTrash(newContext(), userID, &todo.GetTrashQuery{Page: &page, Limit: &limit})
+		require.NoError(t, err)
+
+		var trashed []uuid.UUID
+		for _, item := range trash.Data {
+			trashed = append(trashed, item.ID)
+		}
+		// The subtask was deleted with its parent and is restored with it
+		assert.ElementsMatch(t, []uuid.UUID{parent.ID, loose.ID}, trashed)
+
+		_, err = repos.Todo.CheckTodoExists(ctx, userID, kept.ID)
+		require.NoError(t, err)
+		_, err = repos.Todo.CheckTodoExists(ctx, someoneElses.UserID, someoneElses.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("todos already in the trash are not found", func(t *testing.T) {
+		again, err := todoService.BulkDelete(newContext(), userID, &todo.BulkDeletePayload{
+			TodoIDs: []uuid.UUID{loose.ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, again.Deleted)
+		assert.Equal(t, 1, again.NotFound)
+	})
+}
+
+func TestTodoService_BulkByFilter(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, nil, nil, nil)
+
+	testServer.Config.Todo = &config.TodoConfig{BulkBatchSize: 2, BulkMaxIDs: 4}
+	defer func() { testServer.Config.Todo = nil }()
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos/bulk/by-filter", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	create := func(t *testing.T, userID string, title string, priority todo.Priority) *todo.Todo {
+		t.Helper()
+		created, err := repos.Todo.CreateTodo(newContext().Request().Context(), userID,
+			&todo.CreateTodoPayload{Title: title, Priority: &priority})
+		require.NoError(t, err)
+		return created
+	}
+
+	t.Run("only the matching todos are changed", func(t *testing.T) {
+		userID := uuid.New().String()
+		var matching []uuid.UUID
+		for range 3 {
+			matching = append(matching, create(t, userID, "Urgent", todo.PriorityHigh).ID)
+		}
+		other := create(t, userID, "Someday", todo.PriorityLow)
+		foreign := create(t, uuid.New().String(), "Urgent", todo.PriorityHigh)
+
+		high := todo.PriorityHigh
+		result, err := todoService.BulkByFilter(newContext(), userID, &todo.BulkByFilterPayload{
+			Filter:    todo.TodoFilter{Priority: &high},
+			Operation: todo.BulkOperationComplete,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Matched)
+		assert.Equal(t, 3, result.Updated)
+
+		completed, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID, matching)
+		require.NoError(t, err)
+		require.Len(t, completed, 3)
+		for _, item := range completed {
+			assert.Equal(t, todo.StatusCompleted, item.Status)
+		}
+
+		untouched, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID, []uuid.UUID{other.ID})
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, untouched[0].Status)
+
+		untouched, err = repos.Todo.GetTodosByIDs(newContext().Request().Context(), foreign.UserID,
+			[]uuid.UUID{foreign.ID})
+		require.NoError(t, err)
+		assert.Equal(t, todo.StatusDraft, untouched[0].Status)
+	})
+
+	t.Run("tagging skips todos that already carry the tag", func(t *testing.T) {
+		userID := uuid.New().String()
+		first := create(t, userID, "Call plumber", todo.PriorityMedium)
+		second := create(t, userID, "Call landlord", todo.PriorityMedium)
+		create(t, userID, "Water plants", todo.PriorityMedium)
+
+		search := "call"
+		payload := &todo.BulkByFilterPayload{
+			Filter:    todo.TodoFilter{Search: &search},
+			Operation: todo.BulkOperationTag,
+			Tag:       testing_pkg.Ptr("phone"),
+		}
+
+		result, err := todoService.BulkByFilter(newContext(), userID, payload)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Matched)
+		assert.Equal(t, 2, result.Updated)
+
+		payload.Tag = testing_pkg.Ptr("Phone")
+		result, err = todoService.BulkByFilter(newContext(), userID, payload)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Matched)
+		assert.Equal(t, 0, result.Updated)
+
+		tagged, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID,
+			[]uuid.UUID{first.ID, second.ID})
+		require.NoError(t, err)
+		for _, item := range tagged {
+			require.NotNil(t, item.Metadata)
+			assert.Equal(t, []string{"phone"}, item.Metadata.Tags)
+		}
+	})
+
+	t.Run("a filter matching more than the cap is rejected untouched", func(t *testing.T) {
+		userID := uuid.New().String()
+		var created []uuid.UUID
+		for range 5 {
+			created = append(created, create(t, userID, "Too many", todo.PriorityLow).ID)
+		}
+
+		_, err := todoService.BulkByFilter(newContext(), userID, &todo.BulkByFilterPayload{
+			Operation: todo.BulkOperationArchive,
+		})
+
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, errs.CodeTooManyTodos, httpErr.Code)
+		require.Len(t, httpErr.Errors, 1)
+		assert.Equal(t, "filter", httpErr.Errors[0].Field)
+
+		unchanged, err := repos.Todo.GetTodosByIDs(newContext().Request().Context(), userID, created)
+		require.NoError(t, err)
+		for _, item := range unchanged {
+			assert.Equal(t, todo.StatusDraft, item.Status)
+		}
+	})
+}
+
+func TestTodoService_GetChangeFeed(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, changefeed.NewMemoryNotifier(), nil, nil)
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/todos/changes", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	poll := func(t *testing.T, userID string, since string, wait time.Duration) *activity.ChangeFeed {
+		t.Helper()
+		waitFor := wait.String()
+		query := &activity.GetChangeFeedQuery{Since: &since, Wait: &waitFor}
+		require.NoError(t, query.Validate())
+
+		feed, err := todoService.GetChangeFeed(newContext(), userID, query)
+		require.NoError(t, err)
+		return feed
+	}
+
+	start := func(t *testing.T, userID string) string {
+		t.Helper()
+		feed, err := todoService.GetChangeFeed(newContext(), userID, &activity.GetChangeFeedQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, feed.Changes)
+		return feed.Token
+	}
+
+	t.Run("a change during the wait returns promptly", func(t *testing.T) {
+		userID := uuid.New().String()
+		since := start(t, userID)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Arrives mid-wait"})
+			assert.NoError(t, err)
+		}()
+
+		began := time.Now()
+		feed := poll(t, userID, since, 10*time.Second)
+
+		assert.Less(t, time.Since(began), 5*time.Second)
+		require.Len(t, feed.Changes, 1)
+		assert.Equal(t, activity.ActionCreated, feed.Changes[0].Action)
+		assert.NotEqual(t, since, feed.Token)
+
+		// The next poll picks up after the change
+		after := poll(t, userID, feed.Token, 0)
+		assert.Empty(t, after.Changes)
+	})
+
+	t.Run("changes already made return without waiting", func(t *testing.T) {
+		userID := uuid.New().String()
+		since := start(t, userID)
+		_, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Already there"})
+		require.NoError(t, err)
+
+		began := time.Now()
+		feed := poll(t, userID, since, 10*time.Second)
+
+		assert.Less(t, time.Since(began), 5*time.Second)
+		assert.Len(t, feed.Changes, 1)
+	})
+
+	t.Run("an idle wait returns empty at the timeout with an advanced token", func(t *testing.T) {
+		userID := uuid.New().String()
+		since := start(t, userID)
+
+		began := time.Now()
+		feed := poll(t, userID, since, 300*time.Millisecond)
+
+		assert.GreaterOrEqual(t, time.Since(began), 300*time.Millisecond)
+		assert.Empty(t, feed.Changes)
+
+		before, err := activity.ParseChangeToken(since)
+		require.NoError(t, err)
+		after, err := activity.ParseChangeToken(feed.Token)
+		require.NoError(t, err)
+		assert.True(t, after.At.After(before.At))
+	})
+
+	t.Run("other users' changes don't wake the wait", func(t *testing.T) {
+		userID := uuid.New().String()
+		since := start(t, userID)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, err := todoService.CreateTodo(newContext(), uuid.New().String(),
+				&todo.CreateTodoPayload{Title: "Someone else's"})
+			assert.NoError(t, err)
+		}()
+
+		began := time.Now()
+		feed := poll(t, userID, since, 500*time.Millisecond)
+
+		assert.GreaterOrEqual(t, time.Since(began), 500*time.Millisecond)
+		assert.Empty(t, feed.Changes)
+	})
+}
+
+func TestTodoService_GetAttachmentPresignedURL_RecordsAccess(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	// Presigning is computed locally, so no S3 endpoint needs to be reachable
+	awsClient := &aws.AWS{S3: aws.NewS3Client(testServer, awssdk.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		BaseEndpoint: awssdk.String("http://localhost:9000"),
+	})}
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, awsClient, nil, nil, nil)
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/todos/attachments/download", nil)
+		req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		c.Set(middleware.RequestIDKey, "req-123")
+		return c
+	}
+
+	userID := uuid.New().String()
+	created, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "With a file"})
+	require.NoError(t, err)
+
+	attachment, err := repos.Todo.UploadTodoAttachment(newContext().Request().Context(), created.ID, userID,
+		"report.pdf_1700000000", "report.pdf", 1024, "application/pdf")
+	require.NoError(t, err)
+
+	before := time.Now()
+	url, err := todoService.GetAttachmentPresignedURL(newContext(), userID, created.ID, attachment.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, url)
+
+	logs, err := repos.Admin.GetAttachmentAccessLogs(newContext().Request().Context(),
+		&admin.GetAttachmentAccessLogsQuery{AttachmentID: &attachment.ID})
+	require.NoError(t, err)
+	require.Len(t, logs.Data, 1)
+
+	entry := logs.Data[0]
+	assert.Equal(t, userID, entry.UserID)
+	assert.Equal(t, created.ID, entry.TodoID)
+	assert.Equal(t, attachment.ID, entry.AttachmentID)
+	require.NotNil(t, entry.IP)
+	assert.Equal(t, "203.0.113.7", *entry.IP)
+	require.NotNil(t, entry.RequestID)
+	assert.Equal(t, "req-123", *entry.RequestID)
+	assert.WithinDuration(t, before, entry.CreatedAt, time.Minute)
+}
+
+func TestTodoService_MaxChildren(t *testing.T) {
+	_, testServer, cleanup := testing_pkg.SetupTest(t)
+	defer cleanup()
+
+	repos := repository.NewRepositories(testServer)
+	todoService := service.NewTodoService(testServer, repos.Todo, repos.Category, repos.Activity,
+		repos.Preference, repos.Snapshot, nil, nil, nil, nil)
+
+	testServer.Config.Todo = &config.TodoConfig{MaxChildren: 2}
+	defer func() { testServer.Config.Todo = nil }()
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", nil)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	assertMaxChildren := func(t *testing.T, err error) {
+		t.Helper()
+		var httpErr *errs.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, errs.CodeMaxChildrenExceeded, httpErr.Code)
+	}
+
+	// fillParent creates a parent with as many subtasks as the limit allows
+	fillParent := func(t *testing.T, userID string) (*todo.TodoWithWarnings, []uuid.UUID) {
+		t.Helper()
+		parent, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Parent"})
+		require.NoError(t, err)
+
+		children := make([]uuid.UUID, 0, 2)
+		for range 2 {
+			child, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{
+				Title:        "Child",
+				ParentTodoID: &parent.ID,
+			})
+			require.NoError(t, err)
+			children = append(children, child.ID)
+		}
+		return parent, children
+	}
+
+	t.Run("creating past the limit is rejected", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent, _ := fillParent(t, userID)
+
+		_, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{
+			Title:        "One too many",
+			ParentTodoID: &parent.ID,
+		})
+		assertMaxChildren(t, err)
+	})
+
+	t.Run("reparenting onto a full parent is rejected", func(t *testing.T) {
+		userID := uuid.New().String()
+		parent, children := fillParent(t, userID)
+		loose, err := todoService.CreateTodo(newContext(), userID, &todo.CreateTodoPayload{Title: "Loose"})
+		require.NoError(t, err)
+
+		_, err = todoService.UpdateTodo(newContext(), userID, &todo.UpdateTodoPayload{
+			ID:           loose.ID,
+			ParentTodoID: &parent.ID,
+		})
+		assertMaxChildren(t, err)
+
+		_, err = todoService.BulkReparent(newContext(), userID, &todo.BulkReparentPayload{
+			TodoIDs:      []uuid.UUID{loose.ID},
+			ParentTodoID: &parent.ID,
+		})
+		assertMaxChildren(t, err)
+
+		// A subtask already under the parent doesn't count against it twice
+		_, err = todoService.UpdateTodo(newContext(), userID, &todo.UpdateTodoPayload{
+			ID:           children[0],
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = todoService.BulkReparent(newContext(), userID, &todo.BulkReparentPayload{
+			TodoIDs:      children,
+			ParentTodoID: &parent.ID,
+		})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sriniously/tasker/internal/lib/aws"
+	"github.com/sriniously/tasker/internal/lib/transcription"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// TranscriptionService turns audio attachments into searchable text. It runs
+// from the background job server rather than a request.
+type TranscriptionService struct {
+	server    *server.Server
+	todoRepo  *repository.TodoRepository
+	awsClient *aws.AWS
+	provider  transcription.Provider
+}
+
+func NewTranscriptionService(server *server.Server, todoRepo *repository.TodoRepository, awsClient *aws.AWS,
+	provider transcription.Provider,
+) *TranscriptionService {
+	return &TranscriptionService{
+		server:    server,
+		todoRepo:  todoRepo,
+		awsClient: awsClient,
+		provider:  provider,
+	}
+}
+
+// TranscribeAttachment downloads an audio attachment, transcribes it and
+// stores the transcript on the attachment
+func (s *TranscriptionService) TranscribeAttachment(ctx context.Context, todoID, attachmentID uuid.UUID) error {
+	attachment, err := s.todoRepo.GetTodoAttachment(ctx, todoID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if !attachment.IsAudio() {
+		return fmt.Errorf("attachment %s is not audio", attachmentID)
+	}
+
+	audio, err := s.awsClient.S3.GetObject(ctx, s.server.Config.AWS.UploadBucket, attachment.DownloadKey)
+	if err != nil {
+		return err
+	}
+	defer audio.Close()
+
+	transcript, err := s.provider.Transcribe(ctx, audio, *attachment.MimeType)
+	if err != nil {
+		return fmt.Errorf("failed to transcribe attachment %s: %w", attachmentID, err)
+	}
+
+	if _, err := s.todoRepo.SetAttachmentTranscript(ctx, todoID, attachmentID, transcript); err != nil {
+		return err
+	}
+
+	// Business event log
+	s.server.Logger.Info().
+		Str("event", "attachment_transcribed").
+		Str("todo_id", todoID.String()).
+		Str("attachment_id", attachmentID.String()).
+		Int("transcript_length", len(transcript)).
+		Msg("Attachment transcribed")
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/lib/job"
+	webhookLib "github.com/sriniously/tasker/internal/lib/webhook"
+	"github.com/sriniously/tasker/internal/middleware"
+	"github.com/sriniously/tasker/internal/model"
+	"github.com/sriniously/tasker/internal/model/webhook"
+	"github.com/sriniously/tasker/internal/repository"
+	"github.com/sriniously/tasker/internal/server"
+)
+
+// WebhookService manages the user's webhooks and sends them events. Events
+// are delivered from the background job server, which retries failures.
+type WebhookService struct {
+	server      *server.Server
+	webhookRepo *repository.WebhookRepository
+	client      *http.Client
+}
+
+func NewWebhookService(server *server.Server, webhookRepo *repository.WebhookRepository) *WebhookService {
+	cfg := server.Config.Webhook
+	return &WebhookService{
+		server:      server,
+		webhookRepo: webhookRepo,
+		client:      webhookLib.NewClient(cfg.GetTimeout(), cfg.IsPrivateNetworkAllowed()),
+	}
+}
+
+func (s *WebhookService) checkURL(raw string) error {
+	if err := webhookLib.CheckURL(raw, s.server.Config.Webhook.IsPrivateNetworkAllowed()); err != nil {
+		code := errs.CodeInvalidWebhookURL
+		return errs.NewBadRequestError(err.Error(), true, &code, nil, nil)
+	}
+	return nil
+}
+
+// CreateWebhook registers the endpoint, generating a signing secret when none
+// is given. The secret is returned this once.
+func (s *WebhookService) CreateWebhook(ctx echo.Context, userID string,
+	payload *webhook.CreateWebhookPayload,
+) (*webhook.WebhookWithSecret, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if err := s.checkURL(payload.URL); err != nil {
+		return nil, err
+	}
+
+	count, err := s.webhookRepo.CountWebhooks(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to count webhooks")
+		return nil, err
+	}
+
+	if count >= webhook.MaxWebhooksPerUser {
+		code := errs.CodeTooManyWebhooks
+		return nil, errs.NewBadRequestError(
+			fmt.Sprintf("At most %d webhooks can be registered", webhook.MaxWebhooksPerUser),
+			true, &code, nil, nil,
+		)
+	}
+
+	var secret string
+	if payload.Secret != nil {
+		secret = *payload.Secret
+	} else if secret, err = webhookLib.GenerateSecret(); err != nil {
+		logger.Error().Err(err).Msg("failed to generate webhook secret")
+		return nil, err
+	}
+
+	created, err := s.webhookRepo.CreateWebhook(ctx.Request().Context(), userID, payload, secret)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create webhook")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "webhook_created").
+		Str("webhook_id", created.ID.String()).
+		Strs("events", created.Events).
+		Msg("Webhook created successfully")
+
+	return &webhook.WebhookWithSecret{Webhook: *created, Secret: secret}, nil
+}
+
+func (s *WebhookService) GetWebhooks(ctx echo.Context, userID string) ([]webhook.Webhook, error) {
+	logger := middleware.GetLogger(ctx)
+
+	webhooks, err := s.webhookRepo.GetWebhooks(ctx.Request().Context(), userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch webhooks")
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) GetWebhookByID(ctx echo.Context, userID string, webhookID uuid.UUID,
+) (*webhook.Webhook, error) {
+	logger := middleware.GetLogger(ctx)
+
+	found, err := s.webhookRepo.GetWebhookByID(ctx.Request().Context(), userID, webhookID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch webhook by ID")
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (s *WebhookService) UpdateWebhook(ctx echo.Context, userID string,
+	payload *webhook.UpdateWebhookPayload,
+) (*webhook.Webhook, error) {
+	logger := middleware.GetLogger(ctx)
+
+	if payload.URL != nil {
+		if err := s.checkURL(*payload.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.webhookRepo.UpdateWebhook(ctx.Request().Context(), userID, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update webhook")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "webhook_updated").
+		Str("webhook_id", updated.ID.String()).
+		Bool("active", updated.Active).
+		Bool("secret_rotated", payload.Secret != nil).
+		Msg("Webhook updated successfully")
+
+	return updated, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx echo.Context, userID string, webhookID uuid.UUID) error {
+	logger := middleware.GetLogger(ctx)
+
+	if err := s.webhookRepo.DeleteWebhook(ctx.Request().Context(), userID, webhookID); err != nil {
+		logger.Error().Err(err).Msg("failed to delete webhook")
+		return err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "webhook_deleted").
+		Str("webhook_id", webhookID.String()).
+		Msg("Webhook deleted successfully")
+
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx echo.Context, userID string,
+	query *webhook.GetDeliveriesQuery,
+) (*model.PaginatedResponse[webhook.Delivery], error) {
+	logger := middleware.GetLogger(ctx)
+
+	// Validate webhook exists and belongs to user
+	if _, err := s.webhookRepo.GetWebhookByID(ctx.Request().Context(), userID, query.ID); err != nil {
+		logger.Error().Err(err).Msg("webhook validation failed")
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.GetDeliveries(ctx.Request().Context(), userID, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch webhook deliveries")
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// TestWebhook sends the webhook a ping right away, paused or not, and returns
+// the logged delivery. A failed ping isn't retried.
+func (s *WebhookService) TestWebhook(ctx echo.Context, userID string, webhookID uuid.UUID,
+) (*webhook.Delivery, error) {
+	logger := middleware.GetLogger(ctx)
+
+	hook, err := s.webhookRepo.GetWebhookByID(ctx.Request().Context(), userID, webhookID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to fetch webhook to test")
+		return nil, err
+	}
+
+	payload, err := newWebhookPayload(webhook.EventPing, map[string]uuid.UUID{"webhookId": hook.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.webhookRepo.CreateDelivery(ctx.Request().Context(), hook, payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to create webhook delivery")
+		return nil, err
+	}
+
+	attempt := s.post(ctx.Request().Context(), hook, delivery)
+	delivery, err = s.webhookRepo.RecordAttempt(ctx.Request().Context(), delivery.ID, attempt, true)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record webhook attempt")
+		return nil, err
+	}
+
+	// Business event log
+	eventLogger := middleware.GetLogger(ctx)
+	eventLogger.Info().
+		Str("event", "webhook_tested").
+		Str("webhook_id", hook.ID.String()).
+		Str("delivery_id", delivery.ID.String()).
+		Str("status", string(delivery.Status)).
+		Msg("Webhook tested successfully")
+
+	return delivery, nil
+}
+
+// Dispatch queues the event for each of the user's webhooks subscribed to it.
+// It's best effort: failures are logged and never fail the write that caused
+// the event.
+func (s *WebhookService) Dispatch(ctx echo.Context, userID string, event string, data any) {
+	if s == nil {
+		return
+	}
+
+	logger := middleware.GetLogger(ctx)
+
+	webhooks, err := s.webhookRepo.GetSubscribedWebhooks(ctx.Request().Context(), userID, event)
+	if err != nil {
+		logger.Warn().Err(err).Str("webhook_event", event).Msg("failed to fetch subscribed webhooks")
+		return
+	}
+
+	for i := range webhooks {
+		payload, err := newWebhookPayload(event, data)
+		if err != nil {
+			logger.Warn().Err(err).Str("webhook_event", event).Msg("failed to encode webhook payload")
+			return
+		}
+
+		delivery, err := s.webhookRepo.CreateDelivery(ctx.Request().Context(), &webhooks[i], payload)
+		if err != nil {
+			logger.Warn().Err(err).Str("webhook_id", webhooks[i].ID.String()).Msg("failed to create webhook delivery")
+			continue
+		}
+
+		task := &job.DeliverWebhookTask{DeliveryID: delivery.ID}
+		task.RequestID = middleware.GetRequestID(ctx)
+		if err := job.EnqueueDeliverWebhook(s.server.Job.Client, task,
+			s.server.Config.Webhook.GetMaxAttempts()); err != nil {
+			logger.Warn().Err(err).Str("delivery_id", delivery.ID.String()).Msg("failed to enqueue webhook delivery")
+		}
+	}
+}
+
+// DeliverWebhook posts a queued delivery and records the attempt. It fails
+// when the endpoint does, so the job server retries; final marks the last try.
+func (s *WebhookService) DeliverWebhook(ctx context.Context, deliveryID uuid.UUID, final bool) error {
+	delivery, hook, err := s.webhookRepo.GetDeliveryTarget(ctx, deliveryID)
+	if err != nil {
+		// The webhook, and its deliveries with it, was deleted since
+		var notFound *errs.HTTPError
+		if errors.As(err, &notFound) && notFound.Status == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if delivery.Status != webhook.DeliveryPending {
+		return nil
+	}
+
+	attempt := s.post(ctx, hook, delivery)
+	delivery, err = s.webhookRepo.RecordAttempt(ctx, deliveryID, attempt, final)
+	if err != nil {
+		return err
+	}
+
+	if !attempt.Succeeded() {
+		if attempt.Error != nil {
+			return fmt.Errorf("webhook delivery %s failed: %s", deliveryID, *attempt.Error)
+		}
+		return fmt.Errorf("webhook delivery %s failed with status %d", deliveryID, *attempt.StatusCode)
+	}
+
+	// Business event log
+	s.server.Logger.Info().
+		Str("event", "webhook_delivered").
+		Str("webhook_id", hook.ID.String()).
+		Str("delivery_id", deliveryID.String()).
+		Str("webhook_event", delivery.Event).
+		Int("attempts", delivery.Attempts).
+		Msg("Webhook delivered")
+
+	return nil
+}
+
+// post sends the delivery's payload to the webhook once, signed with its
+// secret, keeping the start of the response for the delivery log
+func (s *WebhookService) post(ctx context.Context, hook *webhook.Webhook, delivery *webhook.Delivery,
+) *webhook.Attempt {
+	failed := func(err error) *webhook.Attempt {
+		message := err.Error()
+		return &webhook.Attempt{Error: &message}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return failed(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookLib.UserAgent)
+	req.Header.Set(webhookLib.EventHeader, delivery.Event)
+	req.Header.Set(webhookLib.DeliveryHeader, delivery.ID.String())
+	req.Header.Set(webhookLib.SignatureHeader, webhookLib.Sign(hook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return failed(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhook.MaxResponseBodyLength))
+	if err != nil {
+		return failed(err)
+	}
+
+	status := resp.StatusCode
+	// Postgres text can't hold invalid UTF-8 or NUL bytes
+	text := strings.ReplaceAll(string(bytes.ToValidUTF8(body, nil)), "\x00", "")
+	return &webhook.Attempt{StatusCode: &status, Body: &text}
+}
+
+func newWebhookPayload(event string, data any) (*webhook.Payload, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s webhook data: %w", event, err)
+	}
+
+	return &webhook.Payload{
+		ID:        uuid.New(),
+		Event:     event,
+		CreatedAt: time.Now(),
+		Data:      encoded,
+	}, nil
+}
@@ -162,7 +162,7 @@ func HandleError(err error) error {
 		sqlErr := ConvertPgError(pgerr)
 
 		// Generate an appropriate error code and message
-		errorCode := generateErrorCode(sqlErr.TableName, sqlErr.Code)
+		errorCode := errs.Code(generateErrorCode(sqlErr.TableName, sqlErr.Code))
 		userMessage := formatUserFriendlyMessage(sqlErr)
 
 		switch sqlErr.Code {
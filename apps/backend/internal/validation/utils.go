@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -32,35 +33,43 @@ func BindAndValidate(c echo.Context, payload Validatable) error {
 		return errs.NewBadRequestError(message, false, nil, nil, nil)
 	}
 
-	if msg, fieldErrors := validateStruct(payload); fieldErrors != nil {
+	if err := payload.Validate(); err != nil {
+		// Payloads may return a fully formed HTTP error, e.g. with a specific code
+		var httpErr *errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
+
+		msg, fieldErrors := extractValidationErrors(err, payload)
 		return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil)
 	}
 
 	return nil
 }
 
-func validateStruct(v Validatable) (string, []errs.FieldError) {
-	if err := v.Validate(); err != nil {
-		return extractValidationErrors(err)
-	}
-	return "", nil
-}
-
-func extractValidationErrors(err error) (string, []errs.FieldError) {
+// extractValidationErrors lists every failing field, named as the client sent
+// it, along with the rule it broke
+func extractValidationErrors(err error, payload any) (string, []errs.FieldError) {
 	var fieldErrors []errs.FieldError
-	validationErrors, ok := err.(validator.ValidationErrors)
-	if !ok {
-		customValidationErrors := err.(CustomValidationErrors)
+
+	var customValidationErrors CustomValidationErrors
+	if errors.As(err, &customValidationErrors) {
 		for _, err := range customValidationErrors {
 			fieldErrors = append(fieldErrors, errs.FieldError{
 				Field: err.Field,
 				Error: err.Message,
 			})
 		}
+		return "Validation failed", fieldErrors
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return "Validation failed: " + err.Error(), nil
 	}
 
 	for _, err := range validationErrors {
-		field := strings.ToLower(err.Field())
+		field := fieldName(reflect.TypeOf(payload), err.StructNamespace())
 		var msg string
 
 		switch err.Tag() {
@@ -99,14 +108,76 @@ func extractValidationErrors(err error) (string, []errs.FieldError) {
 		}
 
 		fieldErrors = append(fieldErrors, errs.FieldError{
-			Field: strings.ToLower(err.Field()),
+			Field: field,
 			Error: msg,
+			Rule:  err.Tag(),
 		})
 	}
 
 	return "Validation failed", fieldErrors
 }
 
+// bindingTags are checked in order for the name a field is sent under
+var bindingTags = []string{"json", "query", "param", "form"}
+
+// fieldName turns a validator namespace such as "Payload.FollowUp.DueDate" or
+// "Payload.TodoIDs[2]" into the path the client used, e.g. "followUp.dueDate"
+// or "todoIds[2]". Fields without a binding tag are lowercased.
+func fieldName(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 1 {
+		// The first segment is the payload type itself
+		segments = segments[1:]
+	}
+
+	names := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+
+		var sf reflect.StructField
+		found := false
+		if t != nil && t.Kind() == reflect.Struct {
+			sf, found = t.FieldByName(name)
+		}
+		if !found {
+			names = append(names, strings.ToLower(name)+index)
+			t = nil
+			continue
+		}
+
+		names = append(names, taggedName(sf)+index)
+		t = sf.Type
+		if index != "" {
+			// Step into the element the index refers to
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+	}
+
+	return strings.Join(names, ".")
+}
+
+func taggedName(sf reflect.StructField) string {
+	for _, tag := range bindingTags {
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(sf.Name)
+}
+
 var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
 func IsValidUUID(uuid string) bool {
@@ -0,0 +1,72 @@
+package validation_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/sriniously/tasker/internal/errs"
+	"github.com/sriniously/tasker/internal/model/todo"
+	"github.com/sriniously/tasker/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nestedPayload struct {
+	Items []struct {
+		DueDate string `json:"dueDate" validate:"required"`
+	} `json:"items" validate:"dive"`
+	Limit int `query:"limit" validate:"max=10"`
+}
+
+func (p *nestedPayload) Validate() error {
+	return validator.New().Struct(p)
+}
+
+func bind(t *testing.T, target, body string, payload validation.Validatable) *errs.HTTPError {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err := validation.BindAndValidate(c, payload)
+
+	var httpErr *errs.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	return httpErr
+}
+
+func TestBindAndValidate_ListsEveryFailingField(t *testing.T) {
+	t.Run("each invalid field appears with its rule", func(t *testing.T) {
+		body := `{"title":"","description":"` + strings.Repeat("x", 1001) + `"}`
+		httpErr := bind(t, "/", body, &todo.CreateTodoPayload{})
+
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, "Validation failed", httpErr.Message)
+
+		rules := make(map[string]string, len(httpErr.Errors))
+		for _, fieldErr := range httpErr.Errors {
+			rules[fieldErr.Field] = fieldErr.Rule
+			assert.NotEmpty(t, fieldErr.Error)
+		}
+		assert.Equal(t, map[string]string{
+			"title":       "required",
+			"description": "max",
+		}, rules)
+	})
+
+	t.Run("fields are named as the client sent them", func(t *testing.T) {
+		httpErr := bind(t, "/", `{"items":[{"dueDate":"tomorrow"},{}]}`, &nestedPayload{Limit: 20})
+
+		require.Len(t, httpErr.Errors, 2)
+		assert.Equal(t, errs.FieldError{Field: "items[1].dueDate", Error: "is required", Rule: "required"},
+			httpErr.Errors[0])
+		assert.Equal(t, errs.FieldError{Field: "limit", Error: "must not exceed 10", Rule: "max"},
+			httpErr.Errors[1])
+	})
+}
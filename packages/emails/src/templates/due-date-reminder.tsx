@@ -61,6 +61,19 @@ export const DueDateReminderEmail = ({
               <Text className="text-gray-700 text-base">
                 Due Date: {dueDate}
               </Text>
+              {"{{if .OtherTodos}}"}
+              <Text className="text-gray-700 text-base">
+                Also due soon:{" "}
+                {
+                  '{{range $i, $title := .OtherTodos}}{{if $i}}, {{end}}"{{$title}}"{{end}}'
+                }
+              </Text>
+              {"{{end}}"}
+              {"{{if .MoreCount}}"}
+              <Text className="text-gray-700 text-base">
+                …and {"{{.MoreCount}}"} more
+              </Text>
+              {"{{end}}"}
             </Section>
 
             <Section>
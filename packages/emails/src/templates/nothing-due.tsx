@@ -0,0 +1,77 @@
+import {
+  Body,
+  Button,
+  Container,
+  Head,
+  Heading,
+  Hr,
+  Html,
+  Link,
+  Preview,
+  Section,
+  Text,
+  Tailwind,
+} from "@react-email/components";
+
+export const NothingDueEmail = () => {
+  return (
+    <Html>
+      <Head />
+      <Preview>You're all caught up</Preview>
+      <Tailwind>
+        <Body className="bg-gray-100 font-sans">
+          <Container className="bg-white p-8 rounded-lg shadow-sm my-10 mx-auto max-w-[600px]">
+            <Heading className="text-2xl font-bold text-gray-800 mt-4">
+              You're all caught up
+            </Heading>
+
+            <Section>
+              <Text className="text-gray-700 text-base">
+                Nothing is due or overdue right now.
+              </Text>
+              <Text className="text-gray-700 text-base">
+                We'll email you when a todo is coming up or slips past its due
+                date.
+              </Text>
+            </Section>
+
+            <Section className="my-8 text-center">
+              <Button
+                className="bg-orange-600 hover:bg-orange-700 text-white font-medium rounded-md px-6 py-3"
+                href={`/todos`}
+              >
+                View Todos
+              </Button>
+            </Section>
+
+            <Hr className="border-gray-200 my-6" />
+
+            <Section>
+              <Text className="text-gray-600 text-sm">
+                You can change how often you receive these emails in your{" "}
+                <Link
+                  href={`/settings/notifications`}
+                  className="text-orange-600 underline"
+                >
+                  notification settings
+                </Link>
+                .
+              </Text>
+            </Section>
+
+            <Section className="mt-8 text-center">
+              <Text className="text-gray-500 text-xs">
+                © {new Date().getFullYear()} Tasker. All rights reserved.
+              </Text>
+              <Text className="text-gray-500 text-xs">
+                123 Project Street, Suite 100, San Francisco, CA 94103
+              </Text>
+            </Section>
+          </Container>
+        </Body>
+      </Tailwind>
+    </Html>
+  );
+};
+
+export default NothingDueEmail;
@@ -57,6 +57,19 @@ export const OverdueNotificationEmail = ({
               <Text className="text-gray-700 text-base">
                 Was due: {dueDate}
               </Text>
+              {"{{if .OtherTodos}}"}
+              <Text className="text-gray-700 text-base">
+                Also overdue:{" "}
+                {
+                  '{{range $i, $title := .OtherTodos}}{{if $i}}, {{end}}"{{$title}}"{{end}}'
+                }
+              </Text>
+              {"{{end}}"}
+              {"{{if .MoreCount}}"}
+              <Text className="text-gray-700 text-base">
+                …and {"{{.MoreCount}}"} more
+              </Text>
+              {"{{end}}"}
             </Section>
 
             <Section>
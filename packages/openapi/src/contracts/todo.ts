@@ -41,6 +41,8 @@ export const todoContract = c.router(
         parentTodoId: z.string().uuid().optional(),
         dueFrom: z.string().datetime().optional(),
         dueTo: z.string().datetime().optional(),
+        createdFrom: z.string().datetime().optional(),
+        createdTo: z.string().datetime().optional(),
         overdue: z.boolean().optional(),
         completed: z.boolean().optional(),
       }),
@@ -45,6 +45,7 @@ export const ZTodoAttachment = z.object({
 export const ZPopulatedTodo = ZTodo.extend({
   category: ZTodoCategory.nullable(),
   children: z.array(ZTodo),
+  childCount: z.number(),
   comments: z.array(ZTodoComment),
   attachments: z.array(ZTodoAttachment),
 });